@@ -0,0 +1,97 @@
+// loadgen 压测 /api/v1/asr/process：未指定 -target 时在进程内启动完整服务（沙箱执行器 + 模拟大模型），
+// 输出 p50/p99 延迟与 goroutine、内存峰值，可选写出 CPU/堆/goroutine profile
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/loadtest"
+)
+
+func main() {
+	target := flag.String("target", "", "压测的服务地址，如 http://127.0.0.1:8080；为空时启动进程内服务")
+	concurrency := flag.Int("c", 50, "并发数")
+	requests := flag.Int("n", 2000, "总请求数")
+	text := flag.String("text", "", "请求文本，为空时使用默认的「创建文档并发送链接」")
+	llmLatency := flag.Duration("llm-latency", 200*time.Millisecond, "进程内模拟大模型每次调用的延迟")
+	cpuProfile := flag.String("cpuprofile", "", "写出 CPU profile 的文件")
+	memProfile := flag.String("memprofile", "", "写出堆 profile 的文件")
+	goroutineProfile := flag.String("goroutineprofile", "", "压测结束前写出 goroutine profile 的文件")
+	verbose := flag.Bool("v", false, "输出服务请求日志")
+	flag.Parse()
+
+	baseURL := *target
+	if baseURL == "" {
+		if !*verbose {
+			log.SetOutput(io.Discard)
+			gin.SetMode(gin.ReleaseMode)
+		}
+		llm := httptest.NewServer(&loadtest.LLMSimulator{Latency: *llmLatency})
+		defer llm.Close()
+		handler, err := loadtest.NewServer(llm.URL)
+		if err != nil {
+			fatalf("assemble server: %v", err)
+		}
+		srv := httptest.NewServer(handler)
+		defer srv.Close()
+		baseURL = srv.URL
+	}
+
+	if *cpuProfile != "" {
+		f := mustCreate(*cpuProfile)
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fatalf("start cpu profile: %v", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *goroutineProfile != "" {
+		// 压测进行到一半时采集，反映稳态下的 goroutine 分布
+		go func() {
+			time.Sleep(2 * time.Second)
+			writeProfile("goroutine", *goroutineProfile)
+		}()
+	}
+	report := loadtest.Run(ctx, loadtest.Options{BaseURL: baseURL, Concurrency: *concurrency, Requests: *requests, Text: *text})
+	fmt.Println(report)
+
+	if *memProfile != "" {
+		runtime.GC()
+		writeProfile("heap", *memProfile)
+	}
+}
+
+func writeProfile(name, path string) {
+	f := mustCreate(path)
+	defer f.Close()
+	if err := pprof.Lookup(name).WriteTo(f, 0); err != nil {
+		fatalf("write %s profile: %v", name, err)
+	}
+}
+
+func mustCreate(path string) *os.File {
+	f, err := os.Create(path)
+	if err != nil {
+		fatalf("create %s: %v", path, err)
+	}
+	return f
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -1,25 +1,26 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"sayso-agent/config"
-	"sayso-agent/internal/client/feishu"
-	"sayso-agent/internal/client/llm"
-	"sayso-agent/internal/client/slack"
-	"sayso-agent/internal/handler"
-	"sayso-agent/internal/service"
-	"sayso-agent/internal/service/executor"
-	servicellm "sayso-agent/internal/service/llm"
+	"sayso-agent/internal/app"
 )
 
 func main() {
-	// 按环境加载配置（APP_ENV=local|dev|prod）
-	cfg, err := config.Load()
+	var configFiles configFlag
+	flag.Var(&configFiles, "config", "配置文件，可重复指定，后面的覆盖前面的（默认按 APP_ENV 加载 config/base.yaml、config/<env>.yaml、config/override.yaml）")
+	flag.Parse()
+
+	// 按环境加载配置（APP_ENV=local|dev|prod），或加载 --config 指定的文件
+	cfg, err := config.Load(configFiles...)
 	if err != nil {
 		log.Fatalf("load config: %v", err)
 	}
@@ -30,49 +31,25 @@ func main() {
 	}
 	gin.SetMode(ginMode)
 
-	// 构建 LLM 客户端
-	llmClient := llm.NewClient(llm.Config{
-		APIKey:  cfg.LLM.APIKey,
-		BaseURL: cfg.LLM.BaseURL,
-		Model:   cfg.LLM.Model,
-	})
+	// SIGINT/SIGTERM 时停止后台任务，等待进行中的请求完成后退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// 构建飞书客户端
-	feishuCfg := feishu.Config{
-		AppID:     cfg.Feishu.AppID,
-		AppSecret: cfg.Feishu.AppSecret,
-		BotToken:  cfg.Feishu.BotToken,
-		Domain:    cfg.Feishu.Domain,
-		Enabled:   cfg.Feishu.Enabled,
+	a, err := app.New(ctx, cfg, app.Options{})
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
-	feishuClient := feishu.NewClient(feishuCfg)
-
-	// 构建 Slack 客户端
-	slackCfg := slack.Config{
-		BotToken: cfg.Slack.BotToken,
-		Enabled:  cfg.Slack.Enabled,
+	if err := a.Run(ctx); err != nil {
+		log.Fatalf("%v", err)
 	}
-	slackClient := slack.NewClient(slackCfg)
+}
 
-	// 服务层
-	llmSvc := servicellm.NewService(llmClient)
-	folderMatcher := servicellm.NewFolderMatcher(llmClient)
-	exec := executor.NewExecutor(feishuClient, slackClient, feishuCfg, slackCfg, folderMatcher)
-	asrSvc := service.NewASRService(llmSvc, exec)
+// configFlag 可重复的 --config 参数
+type configFlag []config.Layer
 
-	// 路由
-	r := handler.Router(asrSvc)
-	addr := fmt.Sprintf(":%d", cfg.Server.Port)
-	log.Printf("server starting at %s (env=%s)", addr, getEnv())
-	if err := http.ListenAndServe(addr, r); err != nil {
-		log.Fatalf("serve: %v", err)
-	}
-}
+func (f *configFlag) String() string { return fmt.Sprint(*f) }
 
-func getEnv() string {
-	env := os.Getenv("APP_ENV")
-	if env == "" {
-		return "local"
-	}
-	return env
+func (f *configFlag) Set(path string) error {
+	*f = append(*f, config.Layer{Path: path})
+	return nil
 }
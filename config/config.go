@@ -1,24 +1,298 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config 应用总配置，按环境加载
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	LLM      LLMConfig      `yaml:"llm"`
-	Feishu   FeishuConfig   `yaml:"feishu"`
-	Slack    SlackConfig    `yaml:"slack"`
-	Log      LogConfig      `yaml:"log"`
+	Server    ServerConfig    `yaml:"server"`
+	HTTP      HTTPConfig      `yaml:"http"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Secrets   SecretsConfig   `yaml:"secrets"`
+	Storage   StorageConfig   `yaml:"storage"`
+	LLM       LLMConfig       `yaml:"llm"`
+	Feishu    FeishuConfig    `yaml:"feishu"`
+	Slack     SlackConfig     `yaml:"slack"`
+	Discord   DiscordConfig   `yaml:"discord"`
+	SMS       SMSConfig       `yaml:"sms"`
+	TTS       TTSConfig       `yaml:"tts"`
+	KB        KBConfig        `yaml:"knowledge_base"`
+	Vector    VectorConfig    `yaml:"vector_store"`
+	Log       LogConfig       `yaml:"log"`
+	Aliases   []AliasConfig   `yaml:"aliases"`
+	Alert     AlertConfig     `yaml:"alert"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	// CostReport 成本报表
+	CostReport CostReportConfig `yaml:"cost_report"`
+	Email      EmailConfig      `yaml:"email"`
+	Session    SessionConfig    `yaml:"session"`
+	Limits     LimitsConfig     `yaml:"limits"`
+	Skills     SkillsConfig     `yaml:"skills"`
+	// Sandbox 沙箱模式：动作只记录日志并返回模拟结果，不调用飞书、Slack 等外部 API（演示、预发、Prompt 评测）
+	Sandbox bool `yaml:"sandbox"`
+	// Hooks 执行器内置钩子
+	Hooks HooksConfig `yaml:"hooks"`
+	// Plugins 外部插件（HTTP sidecar 提供的自定义技能）
+	Plugins PluginsConfig `yaml:"plugins"`
+	// Scripts 租户脚本（Starlark），按租户注册为技能
+	Scripts ScriptsConfig `yaml:"scripts"`
+	// FolderRules 文档归档规则，可通过 /api/v1/folder-rules 在运行时增改
+	FolderRules []FolderRuleConfig `yaml:"folder_rules"`
+	// Warmup 启动预热
+	Warmup WarmupConfig `yaml:"warmup"`
+	// Maintenance 后台维护任务（缓存刷新、索引重建）
+	Maintenance MaintenanceConfig `yaml:"maintenance"`
+	// Flags 功能灰度：功能名 -> 开放范围；未列出的功能全量开启
+	Flags map[string]FlagConfig `yaml:"flags"`
+	// SLO 各阶段的延迟目标与降级
+	SLO SLOConfig `yaml:"slo"`
+	// Identities 跨平台身份映射（同一个人的飞书、Slack、Discord 账号与邮箱、手机号），可通过 /api/v1/identities 在运行时增改
+	Identities []IdentityConfig `yaml:"identities"`
+}
+
+// FlagConfig 功能开放范围：tenants 总是开启，exclude_tenants 总是关闭，其余租户按哈希取 percent（0~100）比例开启；
+// 技能的功能名为 skill.<技能名>（含插件与脚本技能），行为开关有 fast_path、heuristics
+type FlagConfig struct {
+	Percent        int      `yaml:"percent"`
+	Tenants        []string `yaml:"tenants"`
+	ExcludeTenants []string `yaml:"exclude_tenants"`
+}
+
+// WarmupConfig 启动后在后台换取 token、加载目录树与频道列表、查询常用联系人；interval_minutes 大于 0 时定期重新预热
+// （应小于 feishu.cache_ttl_seconds，使缓存始终有效）；contacts 为常用联系人姓名
+type WarmupConfig struct {
+	Enabled         bool     `yaml:"enabled"`
+	IntervalMinutes int      `yaml:"interval_minutes"`
+	Contacts        []string `yaml:"contacts"`
+}
+
+// MaintenanceConfig 后台维护：按间隔刷新目录树、常用联系人（warmup.contacts）与 Slack 频道列表缓存，间隔为 0 时不刷新；
+// 知识库与目录向量索引的重建间隔见 knowledge_base.refresh_minutes、vector_store.folder_index.refresh_minutes。
+// jitter 为间隔的随机抖动比例（0-1），避免多副本同时请求外部 API
+type MaintenanceConfig struct {
+	Jitter            float64 `yaml:"jitter"`
+	FolderTreeMinutes int     `yaml:"folder_tree_minutes"`
+	ContactsMinutes   int     `yaml:"contacts_minutes"`
+	ChannelsMinutes   int     `yaml:"channels_minutes"`
+	DirectoryMinutes  int     `yaml:"directory_minutes"`
+	// LeaderElection 多副本选主：向量存储为共享后端（sql、qdrant）时索引只由主副本重建
+	LeaderElection LeaderElectionConfig `yaml:"leader_election"`
+}
+
+// LeaderElectionConfig 基于数据库租约的选主；driver 为 database/sql 驱动名（须编译进二进制），dialect 为 postgres 或 sqlite
+type LeaderElectionConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Driver  string `yaml:"driver"`
+	DSN     string `yaml:"dsn"`
+	Dialect string `yaml:"dialect"`
+	// Table 租约表名，为空时使用 sayso_leases；TTLSeconds 租约有效期，0 为默认 30
+	Table      string `yaml:"table"`
+	TTLSeconds int    `yaml:"ttl_seconds"`
+}
+
+// HooksConfig 执行器内置钩子：action_log 记录每个动作的耗时与结果；verify_results 执行后核验文档、协作者与消息确实生效；
+// deny_actions 拒绝执行的动作类型（技能开关之外的兜底）；recipient_policy 消息接收方的允许/禁止名单
+type HooksConfig struct {
+	ActionLog       bool                  `yaml:"action_log"`
+	VerifyResults   bool                  `yaml:"verify_results"`
+	DenyActions     []string              `yaml:"deny_actions"`
+	RecipientPolicy RecipientPolicyConfig `yaml:"recipient_policy"`
+	// DraftReview 生成内容发出前先把预览卡片发给请求人审阅
+	DraftReview DraftReviewConfig `yaml:"draft_review"`
+	// PlatformFallback 消息指定的平台未启用时，改从能找到接收方的已启用平台发送，都找不到时请用户确认改用的平台
+	PlatformFallback bool `yaml:"platform_fallback"`
+}
+
+// DraftReviewConfig 草稿审阅：撰写阶段生成的正文不少于 min_chars 字（0 为默认 200）时，先把带「发送」「修改」按钮的预览卡片
+// 私信给请求人，批准后才发送/创建；verification_token 为飞书卡片回调（/feishu/card）的 Verification Token，可写为密钥引用
+type DraftReviewConfig struct {
+	Enabled           bool   `yaml:"enabled"`
+	MinChars          int    `yaml:"min_chars"`
+	VerificationToken string `yaml:"verification_token"`
+}
+
+// RecipientPolicyConfig 消息接收方策略：allowed_chats 非空时其他群聊须确认后发送，confirm_chats 始终须确认，
+// blocked_users、blocked_domains 禁止发送；名单可写 ID 或名字
+type RecipientPolicyConfig struct {
+	AllowedChats   []string `yaml:"allowed_chats"`
+	ConfirmChats   []string `yaml:"confirm_chats"`
+	BlockedUsers   []string `yaml:"blocked_users"`
+	BlockedDomains []string `yaml:"blocked_domains"`
+}
+
+// Empty 未配置任何名单
+func (c RecipientPolicyConfig) Empty() bool {
+	return len(c.AllowedChats)+len(c.ConfirmChats)+len(c.BlockedUsers)+len(c.BlockedDomains) == 0
+}
+
+// PluginsConfig 外部插件；health_check_seconds 为健康检查与技能清单刷新间隔
+type PluginsConfig struct {
+	HealthCheckSeconds int            `yaml:"health_check_seconds"`
+	Endpoints          []PluginConfig `yaml:"endpoints"`
+}
+
+// PluginConfig 一个插件 sidecar；token 可用 secret 引用，timeout_seconds 为单次执行超时，0 为默认 30 秒
+type PluginConfig struct {
+	Name           string `yaml:"name"`
+	URL            string `yaml:"url"`
+	Token          string `yaml:"token"`
+	TimeoutSeconds int    `yaml:"timeout_seconds"`
+}
+
+// ScriptsConfig 租户脚本；max_steps、timeout_seconds、max_calls 为单次执行的解释器步数、超时与宿主函数调用次数，0 使用默认值
+type ScriptsConfig struct {
+	MaxSteps       int            `yaml:"max_steps"`
+	TimeoutSeconds int            `yaml:"timeout_seconds"`
+	MaxCalls       int            `yaml:"max_calls"`
+	Items          []ScriptConfig `yaml:"items"`
+}
+
+// ScriptConfig 一个租户脚本；tenant_id 为空时属于默认租户，源码写在 source 或 file（相对工作目录）中
+type ScriptConfig struct {
+	TenantID    string            `yaml:"tenant_id"`
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Params      map[string]string `yaml:"params"` // 参数名 -> 说明
+	Source      string            `yaml:"source"`
+	File        string            `yaml:"file"`
 }
 
 type ServerConfig struct {
-	Port int    `yaml:"port"`
-	Mode string `yaml:"mode"` // debug, release
+	Port      int    `yaml:"port"`
+	Mode      string `yaml:"mode"`        // debug, release
+	MaxBodyMB int    `yaml:"max_body_mb"` // 请求体大小上限（含 base64 附件，gzip 请求按解压后计），0 表示不限制
+	Gzip      bool   `yaml:"gzip"`        // 客户端接受时压缩响应
+	// OnDisconnect 同步处理请求的客户端断开时：cancel 取消规划与执行（不再消耗 token）；background 继续在后台完成，
+	// 结果写入任务记录
+	OnDisconnect string `yaml:"on_disconnect"`
+	// AdminUI 在 /admin 提供内嵌的运维页面（最近任务、计划依赖图、重试/确认/取消）
+	AdminUI bool `yaml:"admin_ui"`
+}
+
+// CORSConfig 浏览器跨域访问；AllowedOrigins 为空时不允许跨域
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"` // 如 https://dash.example.com、https://*.example.com，"*" 表示任意来源
+	AllowedHeaders   []string `yaml:"allowed_headers"` // 为空时允许 Content-Type、Authorization、Content-Encoding
+	ExposedHeaders   []string `yaml:"exposed_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	MaxAgeSeconds    int      `yaml:"max_age_seconds"` // 预检结果缓存时间
+}
+
+// AuthConfig API 认证；oidc.issuer 为空时不认证
+type AuthConfig struct {
+	OIDC OIDCConfig `yaml:"oidc"`
+}
+
+// OIDCConfig 校验 OIDC 签发方签发的 JWT，并把声明映射为 user_id、租户与用户名
+type OIDCConfig struct {
+	Issuer      string `yaml:"issuer"`
+	Audience    string `yaml:"audience"`     // 本服务的 client_id，配置 issuer 时必填
+	JWKSURL     string `yaml:"jwks_url"`     // 为空时从 issuer 的 discovery 文档获取
+	UserClaim   string `yaml:"user_claim"`   // 默认 sub；用户 ID 需与飞书 open_id 一致时可指向自定义声明
+	TenantClaim string `yaml:"tenant_claim"` // 为空时不从令牌取租户
+	NameClaim   string `yaml:"name_claim"`   // 默认 name
+	RoleClaim   string `yaml:"role_claim"`   // 角色声明（字符串或数组），默认 roles；取值 caller | operator | admin
+	// RoleMapping 把声明值（如 IdP 用户组）映射为角色，如 {"sayso-admins": "admin"}；未映射且不是角色名的值按 caller 处理
+	RoleMapping map[string]string `yaml:"role_mapping"`
+}
+
+// SecretsConfig 密钥源：llm.api_key、feishu.app_secret 等敏感配置可写为引用，启动时解析，如
+// vault://secret/data/sayso#feishu_app_secret、aws-sm://sayso/prod#llm_api_key、env-file://LLM_API_KEY
+type SecretsConfig struct {
+	Vault   VaultConfig `yaml:"vault"`
+	AWS     AWSConfig   `yaml:"aws"`
+	EnvFile string      `yaml:"env_file"` // KEY=VALUE 格式的密钥文件，如挂载的 /run/secrets/sayso.env
+	// RefreshMinutes 定期重新读取飞书应用密钥与大模型 API 密钥，轮换后无需重启；0 表示只在启动时读取
+	RefreshMinutes int `yaml:"refresh_minutes"`
+}
+
+// VaultConfig HashiCorp Vault；address 为空时不启用，token 建议用环境变量 VAULT_TOKEN
+type VaultConfig struct {
+	Address   string `yaml:"address"`
+	Token     string `yaml:"token"`
+	Namespace string `yaml:"namespace"`
+}
+
+// AWSConfig AWS Secrets Manager；region 为空时不启用，凭证取自 AWS_ACCESS_KEY_ID 等环境变量
+type AWSConfig struct {
+	Region   string `yaml:"region"`
+	Endpoint string `yaml:"endpoint"` // VPC 终端节点，为空时使用公网地址
+}
+
+// StorageConfig 任务记录中用户内容（原文、回复、附件）的保护
+type StorageConfig struct {
+	// RetainTranscripts 是否保留用户原文；关闭后记录中不含原文，会话上下文只能参考此前的回复与创建的资源
+	RetainTranscripts bool             `yaml:"retain_transcripts"`
+	Encryption        EncryptionConfig `yaml:"encryption"`
+
+	// Blob 对象存储：导出的文件、合成的语音、图表另存一份，回复中给出带签名的临时链接
+	Blob BlobConfig `yaml:"blob"`
+}
+
+// BlobConfig 对象存储；backend 为 local | s3 | oss，为空时不保存产物；url_ttl_hours 为链接有效期，默认 168（S3 最长 168）
+type BlobConfig struct {
+	Backend     string          `yaml:"backend"`
+	URLTTLHours int             `yaml:"url_ttl_hours"`
+	Local       LocalBlobConfig `yaml:"local"`
+	S3          S3BlobConfig    `yaml:"s3"`
+	OSS         OSSBlobConfig   `yaml:"oss"`
+}
+
+// LocalBlobConfig 本地磁盘（单副本部署）：链接指向本服务的 /blobs/ 路由，base_url 为服务的外部访问地址，secret 为链接签名密钥
+type LocalBlobConfig struct {
+	Dir     string `yaml:"dir"`
+	BaseURL string `yaml:"base_url"`
+	Secret  string `yaml:"secret"`
+}
+
+// S3BlobConfig S3 或兼容 S3 的存储（MinIO 等填 endpoint 并开启 path_style）；凭证为空时使用 AWS_ACCESS_KEY_ID 等环境变量
+type S3BlobConfig struct {
+	Bucket          string `yaml:"bucket"`
+	Region          string `yaml:"region"`
+	Endpoint        string `yaml:"endpoint"`
+	PathStyle       bool   `yaml:"path_style"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+}
+
+// OSSBlobConfig 阿里云 OSS，endpoint 如 oss-cn-hangzhou.aliyuncs.com；凭证为空时使用 OSS_ACCESS_KEY_ID、OSS_ACCESS_KEY_SECRET 环境变量
+type OSSBlobConfig struct {
+	Bucket          string `yaml:"bucket"`
+	Endpoint        string `yaml:"endpoint"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	AccessKeySecret string `yaml:"access_key_secret"`
+}
+
+// EncryptionConfig 静态加密（AES-GCM）；keys 为空时不加密
+type EncryptionConfig struct {
+	// Keys 首个为当前加密密钥，其余只用于解密旧数据；轮换时把新密钥加在首位
+	Keys []EncryptionKeyConfig `yaml:"keys"`
+	KMS  AWSConfig             `yaml:"kms"` // 解密 kms_wrapped 使用的 AWS KMS，凭证同 secrets.aws
+}
+
+// EncryptionKeyConfig 数据密钥，key 与 kms_wrapped 二选一
+type EncryptionKeyConfig struct {
+	ID         string `yaml:"id"`
+	Key        string `yaml:"key"`         // base64 编码的 32 字节密钥，建议写为密钥引用（见 secrets）
+	KMSWrapped string `yaml:"kms_wrapped"` // KMS 加密后的数据密钥（base64 的 CiphertextBlob），启动时调用 KMS 解密
+}
+
+// HTTPConfig 出站 HTTP 连接池，由大模型、飞书、Slack 客户端共享；0 表示使用默认值
+type HTTPConfig struct {
+	MaxIdleConns           int  `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost    int  `yaml:"max_idle_conns_per_host"`
+	MaxConnsPerHost        int  `yaml:"max_conns_per_host"` // 0 表示不限制
+	IdleConnTimeoutSeconds int  `yaml:"idle_conn_timeout_seconds"`
+	DialTimeoutSeconds     int  `yaml:"dial_timeout_seconds"`
+	TLSTimeoutSeconds      int  `yaml:"tls_timeout_seconds"`
+	DisableHTTP2           bool `yaml:"disable_http2"`
 }
 
 type LLMConfig struct {
@@ -26,44 +300,435 @@ type LLMConfig struct {
 	APIKey   string `yaml:"api_key"`
 	BaseURL  string `yaml:"base_url"`
 	Model    string `yaml:"model"`
+	// VisionModel 识别图片附件使用的多模态模型，为空时使用 Model
+	VisionModel string `yaml:"vision_model"`
+	// EmbeddingModel 知识库检索的文本向量模型，为空时使用 text-embedding-3-small
+	EmbeddingModel string `yaml:"embedding_model"`
+	// TranscribeModel 转写语音消息（飞书机器人收到的录音）的模型，为空时使用 whisper-1
+	TranscribeModel string `yaml:"transcribe_model"`
+	// FastPath 可走快速路径的内置技能：与规划并行做一次单次提取，计划只有一个任务时直接采用，为空时不启用
+	FastPath []string `yaml:"fast_path"`
+	// Shadow 影子规划：候选模型/Prompt 与生产规划并行运行，只记录计划差异
+	Shadow ShadowConfig `yaml:"shadow"`
+	// Heuristics 规则预解析："发消息给X说Y"、"创建文档《X》"等简单指令完全命中时不调用大模型
+	Heuristics bool `yaml:"heuristics"`
+	// Pricing 每千 token 的价格（美元），用于统计接口估算成本
+	Pricing PricingConfig `yaml:"pricing"`
+	// Overrides 请求级参数覆盖（请求体的 llm 字段）的允许范围
+	Overrides LLMOverridesConfig `yaml:"overrides"`
+}
+
+// LLMOverridesConfig 请求可覆盖的大模型参数：models 为可指定的模型（为空时不允许指定模型），temperature 须在
+// [min_temperature, max_temperature] 内，max_tokens 不超过上限（0 为不限制）；未开启时带 llm 字段的请求返回 400
+type LLMOverridesConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Models         []string `yaml:"models"`
+	MinTemperature float64  `yaml:"min_temperature"`
+	MaxTemperature float64  `yaml:"max_temperature"`
+	MaxTokens      int      `yaml:"max_tokens"`
+}
+
+// PricingConfig 大模型价格：input_per_1k 为输入（prompt）、output_per_1k 为输出（completion）每千 token 的美元价格
+type PricingConfig struct {
+	InputPer1K  float64 `yaml:"input_per_1k"`
+	OutputPer1K float64 `yaml:"output_per_1k"`
+}
+
+// ShadowConfig 影子规划：model、base_url、api_key 为候选模型（为空时沿用生产配置），prompt_file 为候选规划 Prompt 模板
+// （为空时沿用生产模板）；percent 为参与的请求比例，影子计划不执行，差异以 "shadow plan:" 开头的 JSON 行写入日志
+type ShadowConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	Model          string `yaml:"model"`
+	BaseURL        string `yaml:"base_url"`
+	APIKey         string `yaml:"api_key"`
+	PromptFile     string `yaml:"prompt_file"`
+	Percent        int    `yaml:"percent"`
+	TimeoutSeconds int    `yaml:"timeout_seconds"`
 }
 
 type FeishuConfig struct {
 	AppID     string `yaml:"app_id"`
 	AppSecret string `yaml:"app_secret"`
 	BotToken  string `yaml:"bot_token"` // 机器人 token（可选）
-	Domain    string `yaml:"domain"`    // 飞书域名，如 example.feishu.cn，用于生成文档链接
+	Domain    string `yaml:"domain"`    // 飞书域名，如 example.feishu.cn（Lark 为 example.larksuite.com），用于生成文档链接
+	Region    string `yaml:"region"`    // feishu | lark | auto（默认，按 domain 或应用凭证自动判断）
 	Enabled   bool   `yaml:"enabled"`
+	// 私有化部署（KA）：api_base 为网关上的开放接口前缀，设置后忽略 region；auth_path 默认 /auth/v3/tenant_access_token/internal；
+	// doc_url_template 为文档链接格式，支持 {{domain}} {{path}} {{token}}；headers 为网关要求的附加请求头（值可写为密钥引用）
+	APIBase        string            `yaml:"api_base"`
+	AuthPath       string            `yaml:"auth_path"`
+	DocURLTemplate string            `yaml:"doc_url_template"`
+	Headers        map[string]string `yaml:"headers"`
+	// TitleTemplate 新建文档的标题模板，支持 {{title}} {{date}} {{time}} {{author}}
+	TitleTemplate string `yaml:"title_template"`
+	// DuplicatePolicy 目标目录中已有近期同名文档时的处理：ask | reuse | append | new，默认 ask
+	DuplicatePolicy string `yaml:"duplicate_policy"`
+	// ClarifyCollaborators 建文档时有协作者找不到对应用户，先请用户确认再创建
+	ClarifyCollaborators bool `yaml:"clarify_collaborators"`
+	// StrictRecipients 消息只发给请求 contacts 中的联系人、联系人分组或通讯录中姓名完全一致的用户
+	StrictRecipients bool `yaml:"strict_recipients"`
+	// 单条消息超过 max_message_chars（0 为默认 10000）时的处理：split 分条 | truncate 截断 | doc 转为文档，默认 split
+	MaxMessageChars int    `yaml:"max_message_chars"`
+	MessageOverflow string `yaml:"message_overflow"`
+	// Tables 可查询的电子表格/多维表格，只读
+	Tables []TableConfig `yaml:"tables"`
+	// ChartFont 表格问答图表的 TrueType 字体文件（.ttf，需含中文字形，如 Noto Sans SC）；为空时使用内置英文字体，图中以序号标注类目
+	ChartFont string `yaml:"chart_font"`
+	// FolderTemplates 目录结构模板，模板名 -> 目录路径（用 / 表示下级，如 "设计/原型"）
+	FolderTemplates map[string][]string `yaml:"folder_templates"`
+	// CacheTTLSeconds 目录树、按姓名查询用户的结果缓存时间，0 为默认 600
+	CacheTTLSeconds int `yaml:"cache_ttl_seconds"`
+	// Marketplace 商店应用：一次部署服务多个安装了应用的飞书企业
+	Marketplace FeishuMarketplaceConfig `yaml:"marketplace"`
+	// Bot 机器人：把收到的消息（含图片、文件、语音）当作请求处理，结果回复到原消息
+	Bot FeishuBotConfig `yaml:"bot"`
+	// Helpdesk 服务台凭证，供 feishu_create_ticket 提交 IT 工单，未配置时不提供该技能
+	Helpdesk FeishuHelpdeskConfig `yaml:"helpdesk"`
+}
+
+// FeishuHelpdeskConfig 服务台 API 凭证，在服务台后台「设置 - API 设置」中获取（token 可写为密钥引用）
+type FeishuHelpdeskConfig struct {
+	ID    string `yaml:"id"`
+	Token string `yaml:"token"`
+}
+
+// FeishuMarketplaceConfig 商店应用的事件订阅凭证；tenants 为 tenant_key -> 本服务租户，未列出的企业以 tenant_key 作为租户
+type FeishuMarketplaceConfig struct {
+	Enabled           bool              `yaml:"enabled"`
+	VerificationToken string            `yaml:"verification_token"`
+	EncryptKey        string            `yaml:"encrypt_key"`
+	Tenants           map[string]string `yaml:"tenants"`
+}
+
+// FeishuBotConfig 机器人消息（im.message.receive_v1）的事件订阅；商店应用沿用 marketplace 的凭证，
+// 此处的 verification_token、encrypt_key 只用于自建应用
+type FeishuBotConfig struct {
+	Enabled           bool   `yaml:"enabled"`
+	VerificationToken string `yaml:"verification_token"`
+	EncryptKey        string `yaml:"encrypt_key"`
+	TenantID          string `yaml:"tenant_id"` // 消息所属租户（自建应用），为空时使用默认租户
+	// MaxAttachmentMB 单个附件（图片、文件、语音）的下载上限，0 为 20MB
+	MaxAttachmentMB int `yaml:"max_attachment_mb"`
+}
+
+// TableConfig 可查询的数据表，如 "销售台账" → 表格链接
+type TableConfig struct {
+	Name        string `yaml:"name"`
+	URL         string `yaml:"url"`         // 电子表格链接可带 ?sheet=，多维表格链接可带 ?table=
+	Description string `yaml:"description"` // 表格内容说明，如各列含义、金额单位
 }
 
 type SlackConfig struct {
+	BotToken string `yaml:"bot_token"` // 默认工作区
+	Enabled  bool   `yaml:"enabled"`
+	// Workspaces 其他工作区，目标可写为 "#general@emea" 指定工作区
+	Workspaces []SlackWorkspaceConfig `yaml:"workspaces"`
+	// 单条消息超过 max_message_chars（0 为默认 40000）时的处理：split | truncate | doc（写入飞书文档），默认 split
+	MaxMessageChars int    `yaml:"max_message_chars"`
+	MessageOverflow string `yaml:"message_overflow"`
+	// OAuth 通过 OAuth 安装到新工作区，client_id 为空时不启用
+	OAuth SlackOAuthConfig `yaml:"oauth"`
+}
+
+// DiscordConfig Discord Bot；guild 为默认服务器（名称或 ID），"#general" 等频道名在其中查找，
+// 目标也可写为 "#general@服务器名"
+type DiscordConfig struct {
 	BotToken string `yaml:"bot_token"`
 	Enabled  bool   `yaml:"enabled"`
+	Guild    string `yaml:"guild"`
+	// 单条消息超过 max_message_chars（0 为默认 2000）时的处理：split | truncate | doc（写入飞书文档），默认 split
+	MaxMessageChars int    `yaml:"max_message_chars"`
+	MessageOverflow string `yaml:"message_overflow"`
+}
+
+// SMSConfig 短信/WhatsApp（Twilio 兼容接口）；号码只取自请求 contacts 中的 phone 或用户说出的号码
+type SMSConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	AccountSID   string `yaml:"account_sid"`
+	AuthToken    string `yaml:"auth_token"`
+	From         string `yaml:"from"`          // 短信发送号码
+	WhatsAppFrom string `yaml:"whatsapp_from"` // WhatsApp Business 发送号码，为空时不支持 WhatsApp
+	// DefaultCountryCode 不带 "+" 的号码补上的国家码，如 "+86"
+	DefaultCountryCode string `yaml:"default_country_code"`
+	// AllowedPrefixes 非空时只允许发往这些前缀的号码
+	AllowedPrefixes []string `yaml:"allowed_prefixes"`
+	// MaxRecipients 单次接收人上限（0 为默认 5），MaxChars 内容字符上限（0 为默认 500）
+	MaxRecipients int `yaml:"max_recipients"`
+	MaxChars      int `yaml:"max_chars"`
+	// SkipConfirm 不再请用户确认接收人与内容，默认每次发送前确认
+	SkipConfirm bool `yaml:"skip_confirm"`
+	// APIBase 为空时使用 Twilio，兼容其接口的服务商可改为其地址
+	APIBase string `yaml:"api_base"`
+}
+
+// TTSConfig 语音合成（OpenAI 兼容的 /audio/speech 接口），启用后 send_message 可发送语音消息（message_type: voice）；
+// base_url、api_key 为空时沿用 llm 配置
+type TTSConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	BaseURL string `yaml:"base_url"`
+	APIKey  string `yaml:"api_key"`
+	Model   string `yaml:"model"`
+	Voice   string `yaml:"voice"`
+}
+
+// KBConfig 知识库检索（ask_kb）：sources 为要索引的飞书知识空间、知识库节点、云空间目录或文档链接，为空时不启用
+type KBConfig struct {
+	Sources []KBSourceConfig `yaml:"sources"`
+	// ChunkChars 每个片段的字符数上限，0 为默认 600；TopK 每次检索的片段数，0 为默认 5
+	ChunkChars int `yaml:"chunk_chars"`
+	TopK       int `yaml:"top_k"`
+	// MinScore 片段与问题的最低相似度（0-1），低于该值的片段不用于回答
+	MinScore float64 `yaml:"min_score"`
+	// RefreshMinutes 重建索引的间隔，0 表示只在启动时建一次
+	RefreshMinutes int `yaml:"refresh_minutes"`
+}
+
+// VectorConfig 向量存储（知识库片段、目录名称的向量索引）：backend 为 memory（默认，进程内）、sql（pgvector / sqlite-vec）或 qdrant
+type VectorConfig struct {
+	Backend string             `yaml:"backend"`
+	SQL     VectorSQLConfig    `yaml:"sql"`
+	Qdrant  VectorQdrantConfig `yaml:"qdrant"`
+	// FolderIndex 目录名称向量索引：目录较多时先按相似度筛出候选目录，再由大模型选择归档目录
+	FolderIndex FolderIndexConfig `yaml:"folder_index"`
+}
+
+// VectorSQLConfig SQL 向量存储；driver 为 database/sql 驱动名（须编译进二进制，如 pgx、sqlite3），dialect 为 pgvector 或 sqlite-vec
+type VectorSQLConfig struct {
+	Driver  string `yaml:"driver"`
+	DSN     string `yaml:"dsn"`
+	Dialect string `yaml:"dialect"`
+	// Table 表名，为空时使用 sayso_vectors
+	Table string `yaml:"table"`
+}
+
+// VectorQdrantConfig Qdrant 向量存储；prefix 为 collection 名前缀，为空时使用 sayso_
+type VectorQdrantConfig struct {
+	URL    string `yaml:"url"`
+	APIKey string `yaml:"api_key"`
+	Prefix string `yaml:"prefix"`
+}
+
+// FolderIndexConfig 目录索引后台任务，refresh_minutes 为重建间隔（0 为默认 60）
+type FolderIndexConfig struct {
+	Enabled        bool `yaml:"enabled"`
+	RefreshMinutes int  `yaml:"refresh_minutes"`
+}
+
+// KBSourceConfig 知识库来源，如 "行政制度" → 知识空间链接
+type KBSourceConfig struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// SlackOAuthConfig Slack App 凭证与回调地址；redirect_url 须与 App 配置一致，scopes 为 Bot 权限；
+// state_secret 为签名安装 state 的专用密钥，不可与 client_secret 相同
+type SlackOAuthConfig struct {
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"`
+	Scopes       []string `yaml:"scopes"`
+	StateSecret  string   `yaml:"state_secret"`
+}
+
+// SlackWorkspaceConfig 一个 Slack 工作区；tenants 中的租户默认使用该工作区
+type SlackWorkspaceConfig struct {
+	Name     string   `yaml:"name"`
+	TeamID   string   `yaml:"team_id"`
+	BotToken string   `yaml:"bot_token"`
+	Tenants  []string `yaml:"tenants"`
+}
+
+// AliasConfig 联系人分组/别名，如 "核心成员" → 飞书 ou_a、Slack U123，可跨平台
+type AliasConfig struct {
+	Name    string              `yaml:"name"`
+	Members []AliasMemberConfig `yaml:"members"`
+	// Tenants 可使用该分组的租户，为空时所有租户可用；成员 id 可写另一分组名
+	Tenants []string `yaml:"tenants"`
+}
+
+type AliasMemberConfig struct {
+	Platform string `yaml:"platform"` // feishu, slack, discord
+	ID       string `yaml:"id"`       // 飞书 open_id/chat_id、Slack user/channel ID 或 Discord 用户 ID/#频道
+
+	// 可选，"发给产品部除了实习生"按条件排除成员时使用，未填写时从身份映射（identities）中查找
+	Name           string `yaml:"name"`
+	Title          string `yaml:"title"`           // 职位
+	EmploymentType string `yaml:"employment_type"` // 人员类型：正式、实习生、外包、劳务、顾问
+}
+
+// IdentityConfig 一个人在各平台上的账号
+type IdentityConfig struct {
+	TenantID      string   `yaml:"tenant_id"` // 为空表示默认租户
+	Name          string   `yaml:"name"`
+	Aliases       []string `yaml:"aliases"`
+	FeishuOpenID  string   `yaml:"feishu_open_id"`
+	SlackUserID   string   `yaml:"slack_user_id"`
+	DiscordUserID string   `yaml:"discord_user_id"`
+	Email         string   `yaml:"email"`
+	Phone         string   `yaml:"phone"`
+
+	Title          string `yaml:"title"`           // 职位
+	EmploymentType string `yaml:"employment_type"` // 人员类型：正式、实习生、外包、劳务、顾问
+}
+
+// AlertConfig 运维告警频道，定时任务失败等系统级问题会发到这里
+type AlertConfig struct {
+	Platform string `yaml:"platform"` // feishu, slack；为空则只写日志
+	Target   string `yaml:"target"`   // 飞书 chat_id 或 Slack 频道 ID
+	// Panics 恢复的 panic（含调用栈、请求 ID 与任务 ID）也发到运维频道
+	Panics bool `yaml:"panics"`
+	// SentryDSN 恢复的 panic 上报到 Sentry，为空表示不上报；可写为密钥引用
+	SentryDSN string `yaml:"sentry_dsn"`
+	// DedupMinutes 相同告警的合并时间，0 为 30 分钟；MaxPerHour 每小时最多发送的告警数，0 不限制
+	DedupMinutes int `yaml:"dedup_minutes"`
+	MaxPerHour   int `yaml:"max_per_hour"`
+	// ErrorRate 最近 error_window_minutes 内（至少 min_samples 个任务）任务失败率达到此值（0-1）时告警，0 不检查
+	ErrorRate          float64 `yaml:"error_rate"`
+	ErrorWindowMinutes int     `yaml:"error_window_minutes"`
+	MinSamples         int     `yaml:"min_samples"`
+	// LLMFailures 大模型连续失败（熔断）的请求数达到此值时告警，0 不检查
+	LLMFailures int `yaml:"llm_failures"`
+	// DailyTokens 每日大模型 token 预算，用量达到 token_warn_percent%（默认 80）时告警，0 不检查
+	DailyTokens      int `yaml:"daily_tokens"`
+	TokenWarnPercent int `yaml:"token_warn_percent"`
+	// JobFailures 定时工作流、维护任务连续失败达到此次数时告警，0 为每次失败都告警
+	JobFailures int `yaml:"job_failures"`
+}
+
+// SchedulerConfig 定时工作流调度
+type SchedulerConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// CostReportConfig 成本报表：每月 1 日把上个月按租户汇总的成本发到 alert 运维频道
+type CostReportConfig struct {
+	Monthly bool `yaml:"monthly"`
+}
+
+// EmailConfig 入站邮件触发（邮件服务商 webhook 推送到 /api/v1/inbound/email）
+type EmailConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Secret         string   `yaml:"secret"`          // webhook 共享密钥，请求头 X-Inbound-Secret 需一致
+	AllowedDomains []string `yaml:"allowed_domains"` // 允许的发件人域名，启用时必填
+	TenantID       string   `yaml:"tenant_id"`       // 邮件请求归属的租户
+}
+
+// FolderRuleConfig 文档归档规则：标题包含任一关键词的文档存入指定目录，先于大模型目录匹配生效
+type FolderRuleConfig struct {
+	TenantID    string   `yaml:"tenant_id"` // 为空表示默认租户
+	Name        string   `yaml:"name"`
+	Keywords    []string `yaml:"keywords"`
+	FolderName  string   `yaml:"folder_name"`
+	FolderToken string   `yaml:"folder_token"`
+}
+
+// SessionConfig 会话上下文：规划时附带同一会话最近几轮交互，用于理解"他""这个文档"等指代
+type SessionConfig struct {
+	HistorySize   int `yaml:"history_size"`   // 附带的最近交互轮数，0 表示不附带
+	WindowMinutes int `yaml:"window_minutes"` // 只取该时间内的交互
+}
+
+// LimitsConfig 安全上限，0 表示不限制
+type LimitsConfig struct {
+	MaxTasks         int    `yaml:"max_tasks"`          // 单次请求最多执行的动作数
+	MaxRecipients    int    `yaml:"max_recipients"`     // 单个动作最多的接收方
+	MaxDocs          int    `yaml:"max_docs"`           // 单次请求最多新建的文档数
+	ActionsPerMinute int    `yaml:"actions_per_minute"` // 每个用户每分钟最多执行的动作数
+	OnExceed         string `yaml:"on_exceed"`          // 计划超出上限时：confirm（默认，请用户确认）| reject
+	// DeadlineMS 单个请求的总时限（毫秒），0 表示不限制，请求可用 deadline_ms 覆盖；
+	// DeadlineShares 时限在规划、参数提取、执行三个阶段间的份额，前一阶段用剩的时间顺延给后续阶段
+	DeadlineMS     int                  `yaml:"deadline_ms"`
+	DeadlineShares DeadlineSharesConfig `yaml:"deadline_shares"`
+}
+
+// DeadlineSharesConfig 各阶段的时间份额（相对权重），全为 0 时为默认 30/30/40
+type DeadlineSharesConfig struct {
+	Planning   int `yaml:"planning"`
+	Extraction int `yaml:"extraction"`
+	Execution  int `yaml:"execution"`
+}
+
+// SLOConfig 延迟目标：规划、参数提取、执行与合计的耗时目标（毫秒，0 不统计），objective 为应达标的请求比例，
+// 短、长两个窗口的达标率与预算消耗速率见 GET /api/v1/slo。degrade_burn_rate 大于 0 时，规划或参数提取在两个窗口的
+// 消耗速率都达到此值即降级：换用 fallback_model，相同输入复用 plan_cache_minutes 内缓存的计划，响应中标记 degraded
+type SLOConfig struct {
+	Enabled            bool    `yaml:"enabled"`
+	Objective          float64 `yaml:"objective"`
+	PlanningMS         int     `yaml:"planning_ms"`
+	ExtractionMS       int     `yaml:"extraction_ms"`
+	ExecutionMS        int     `yaml:"execution_ms"`
+	TotalMS            int     `yaml:"total_ms"`
+	ShortWindowMinutes int     `yaml:"short_window_minutes"`
+	LongWindowMinutes  int     `yaml:"long_window_minutes"`
+	DegradeBurnRate    float64 `yaml:"degrade_burn_rate"`
+	FallbackModel      string  `yaml:"fallback_model"`
+	PlanCacheMinutes   int     `yaml:"plan_cache_minutes"`
+}
+
+// SkillsConfig 技能开关：本环境禁用的技能，及按租户额外禁用或重新启用的技能
+type SkillsConfig struct {
+	Disabled []string                      `yaml:"disabled"`
+	Tenants  map[string]TenantSkillsConfig `yaml:"tenants"`
+}
+
+type TenantSkillsConfig struct {
+	Disabled []string `yaml:"disabled"`
+	Enabled  []string `yaml:"enabled"`
 }
 
 type LogConfig struct {
 	Level  string `yaml:"level"`  // debug, info, warn, error
 	Format string `yaml:"format"` // json, text
+	// SlowMs 慢请求阈值（毫秒），超过时总是记录访问日志并附带阶段耗时，默认 3000
+	SlowMs int `yaml:"slow_ms"`
+	// SampleRate 成功且不慢的请求的访问日志采样比例（0~1），0 表示只记录慢请求与失败请求
+	SampleRate float64 `yaml:"sample_rate"`
 }
 
-// Load 根据环境变量 APP_ENV 加载对应配置文件
-// 支持: local, dev, prod，默认 local
-func Load() (*Config, error) {
-	env := os.Getenv("APP_ENV")
-	if env == "" {
-		env = "local"
+// Load 加载配置：layers 为空时按 APP_ENV（local | dev | prod，默认 local）加载 CONFIG_DIR（默认 config）下的
+// base.yaml、<env>.yaml 与 override.yaml，后面的层覆盖前面的层。值中的 ${VAR}、${VAR:-默认值} 会展开为环境变量。
+// 未知字段、取值错误与启用功能缺少的必填项会一并报告
+func Load(layers ...Layer) (*Config, error) {
+	if len(layers) == 0 {
+		dir := os.Getenv("CONFIG_DIR")
+		if dir == "" {
+			dir = "config"
+		}
+		layers = DefaultFiles(dir, Env())
 	}
-	path := fmt.Sprintf("config/%s.yaml", env)
-	data, err := os.ReadFile(path)
+	root, used, unknown, err := readLayers(layers)
 	if err != nil {
-		return nil, fmt.Errorf("read config %s: %w", path, err)
+		return nil, err
+	}
+	return parse(strings.Join(used, " + "), root, unknown)
+}
+
+// Env 当前环境：APP_ENV，默认 local
+func Env() string {
+	if env := os.Getenv("APP_ENV"); env != "" {
+		return env
 	}
+	return "local"
+}
+
+// parse 解码合并后的配置并校验；未知字段、类型错误与校验问题合并返回
+func parse(path string, root *yaml.Node, p problems) (*Config, error) {
 	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("parse config: %w", err)
+	if err := root.Decode(&cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("parse config %s: %w", path, err)
+		}
+		// TypeError 时其余字段仍已解码，继续校验以便一次列出所有问题
+		p = append(p, typeErr.Errors...)
 	}
 	// 允许环境变量覆盖敏感配置
 	overrideFromEnv(&cfg)
+	applyDefaults(&cfg)
+	validate(&cfg, &p)
+	if len(p) > 0 {
+		return nil, &ValidationError{Path: path, Problems: p}
+	}
 	return &cfg, nil
 }
 
@@ -71,16 +736,58 @@ func overrideFromEnv(c *Config) {
 	if v := os.Getenv("LLM_API_KEY"); v != "" {
 		c.LLM.APIKey = v
 	}
+	if v := os.Getenv("BLOB_SIGNING_SECRET"); v != "" {
+		c.Storage.Blob.Local.Secret = v
+	}
 	if v := os.Getenv("FEISHU_APP_ID"); v != "" {
 		c.Feishu.AppID = v
 	}
 	if v := os.Getenv("FEISHU_APP_SECRET"); v != "" {
 		c.Feishu.AppSecret = v
 	}
+	if v := os.Getenv("FEISHU_HELPDESK_TOKEN"); v != "" {
+		c.Feishu.Helpdesk.Token = v
+	}
 	if v := os.Getenv("FEISHU_DOMAIN"); v != "" {
 		c.Feishu.Domain = v
 	}
+	if v := os.Getenv("FEISHU_API_BASE"); v != "" {
+		c.Feishu.APIBase = v
+	}
 	if v := os.Getenv("SLACK_BOT_TOKEN"); v != "" {
 		c.Slack.BotToken = v
 	}
+	if v := os.Getenv("SLACK_OAUTH_STATE_SECRET"); v != "" {
+		c.Slack.OAuth.StateSecret = v
+	}
+	if v := os.Getenv("DISCORD_BOT_TOKEN"); v != "" {
+		c.Discord.BotToken = v
+	}
+	if v := os.Getenv("SMS_AUTH_TOKEN"); v != "" {
+		c.SMS.AuthToken = v
+	}
+	if v := os.Getenv("VECTOR_SQL_DSN"); v != "" {
+		c.Vector.SQL.DSN = v
+	}
+	if v := os.Getenv("QDRANT_API_KEY"); v != "" {
+		c.Vector.Qdrant.APIKey = v
+	}
+	if v := os.Getenv("LEADER_ELECTION_DSN"); v != "" {
+		c.Maintenance.LeaderElection.DSN = v
+	}
+	if v := os.Getenv("FEISHU_BOT_VERIFICATION_TOKEN"); v != "" {
+		c.Feishu.Bot.VerificationToken = v
+	}
+	if v := os.Getenv("FEISHU_CARD_VERIFICATION_TOKEN"); v != "" {
+		c.Hooks.DraftReview.VerificationToken = v
+	}
+	if v := os.Getenv("INBOUND_EMAIL_SECRET"); v != "" {
+		c.Email.Secret = v
+	}
+	if v := os.Getenv("VAULT_ADDR"); v != "" {
+		c.Secrets.Vault.Address = v
+	}
+	if v := os.Getenv("VAULT_TOKEN"); v != "" {
+		c.Secrets.Vault.Token = v
+	}
 }
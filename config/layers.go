@@ -0,0 +1,151 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFiles 默认配置层：dir/base.yaml（可选）→ dir/<env>.yaml → dir/override.yaml（可选，本机覆盖，不提交）
+func DefaultFiles(dir, env string) []Layer {
+	return []Layer{
+		{Path: dir + "/base.yaml", Optional: true},
+		{Path: fmt.Sprintf("%s/%s.yaml", dir, env)},
+		{Path: dir + "/override.yaml", Optional: true},
+	}
+}
+
+// Layer 一层配置文件；Optional 的文件不存在时跳过
+type Layer struct {
+	Path     string
+	Optional bool
+}
+
+// readLayers 依次读取各层并合并：映射按键递归合并，标量与列表由后面的层整体替换；
+// 返回实际读取的文件及各层中的未知字段
+func readLayers(layers []Layer) (*yaml.Node, []string, problems, error) {
+	var merged *yaml.Node
+	var used []string
+	var unknown problems
+	for _, l := range layers {
+		data, err := os.ReadFile(l.Path)
+		if errors.Is(err, os.ErrNotExist) && l.Optional {
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("read config %s: %w", l.Path, err)
+		}
+		var doc yaml.Node
+		if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				used = append(used, l.Path)
+				continue
+			}
+			return nil, nil, nil, fmt.Errorf("parse config %s: %w", l.Path, err)
+		}
+		root := doc.Content[0]
+		if root.Kind != yaml.MappingNode {
+			return nil, nil, nil, fmt.Errorf("parse config %s: top level must be a mapping", l.Path)
+		}
+		expandEnv(root)
+		unknownFields(root, reflect.TypeOf(Config{}), "", l.Path, &unknown)
+		merged = mergeNodes(merged, root)
+		used = append(used, l.Path)
+	}
+	if merged == nil {
+		merged = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	return merged, used, unknown, nil
+}
+
+// unknownFields 按结构体的 yaml 标签检查未知字段（通常是拼写错误）
+func unknownFields(n *yaml.Node, t reflect.Type, path, file string, p *problems) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			field := strings.TrimPrefix(path+"."+key.Value, ".")
+			ft, ok := yamlField(t, key.Value)
+			if !ok {
+				*p = append(*p, fmt.Sprintf("%s line %d: unknown field %s", file, key.Line, field))
+				continue
+			}
+			unknownFields(n.Content[i+1], ft, field, file, p)
+		}
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			unknownFields(n.Content[i+1], t.Elem(), path+"."+n.Content[i].Value, file, p)
+		}
+	case n.Kind == yaml.SequenceNode && t.Kind() == reflect.Slice:
+		for i, c := range n.Content {
+			unknownFields(c, t.Elem(), fmt.Sprintf("%s[%d]", path, i), file, p)
+		}
+	}
+}
+
+func yamlField(t reflect.Type, name string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tag, _, _ := strings.Cut(f.Tag.Get("yaml"), ","); tag == name {
+			return f.Type, true
+		}
+	}
+	return nil, false
+}
+
+// mergeNodes 把 overlay 合并进 base
+func mergeNodes(base, overlay *yaml.Node) *yaml.Node {
+	if base == nil || base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return overlay
+	}
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		found := false
+		for j := 0; j+1 < len(base.Content); j += 2 {
+			if base.Content[j].Value == key.Value {
+				base.Content[j+1] = mergeNodes(base.Content[j+1], value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			base.Content = append(base.Content, key, value)
+		}
+	}
+	return base
+}
+
+// envPattern 匹配 ${VAR} 与 ${VAR:-默认值}；不处理 $VAR，避免误改密钥中的 $
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv 展开标量值中的环境变量；变量未设置或为空时使用默认值
+func expandEnv(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode {
+		orig := n.Value
+		n.Value = envPattern.ReplaceAllStringFunc(n.Value, func(m string) string {
+			sub := envPattern.FindStringSubmatch(m)
+			if v := os.Getenv(sub[1]); v != "" {
+				return v
+			}
+			return sub[2]
+		})
+		// 未加引号的值按展开后的内容重新推断类型，如 port: ${PORT:-8080} 解析为整数
+		if n.Value != orig && n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			n.Tag = ""
+		}
+		return
+	}
+	for _, c := range n.Content {
+		expandEnv(c)
+	}
+}
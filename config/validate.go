@@ -0,0 +1,530 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError 配置校验发现的全部问题
+type ValidationError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid config %s (%d problems):\n  - %s", e.Path, len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// problems 收集校验问题，字段用 YAML 路径表示，如 feishu.app_secret
+type problems []string
+
+func (p *problems) add(field, format string, args ...any) {
+	*p = append(*p, field+": "+fmt.Sprintf(format, args...))
+}
+
+func (p *problems) oneOf(field, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	p.add(field, "must be one of %s, got %q", strings.Join(allowed, " | "), value)
+}
+
+func (p *problems) nonNegative(field string, v int) {
+	if v < 0 {
+		p.add(field, "must not be negative, got %d", v)
+	}
+}
+
+// applyDefaults 为可选字段填默认值
+func applyDefaults(c *Config) {
+	if c.Server.Mode == "" {
+		c.Server.Mode = "release"
+	}
+	if c.LLM.Provider == "" {
+		c.LLM.Provider = "openai"
+	}
+	if c.Feishu.DuplicatePolicy == "" {
+		c.Feishu.DuplicatePolicy = "ask"
+	}
+	if c.Feishu.MessageOverflow == "" {
+		c.Feishu.MessageOverflow = "split"
+	}
+	if c.Slack.MessageOverflow == "" {
+		c.Slack.MessageOverflow = "split"
+	}
+	if c.Discord.MessageOverflow == "" {
+		c.Discord.MessageOverflow = "split"
+	}
+	if c.LLM.Shadow.TimeoutSeconds == 0 {
+		c.LLM.Shadow.TimeoutSeconds = 30
+	}
+	if c.Limits.OnExceed == "" {
+		c.Limits.OnExceed = "confirm"
+	}
+	if c.Server.OnDisconnect == "" {
+		c.Server.OnDisconnect = "cancel"
+	}
+	if c.Log.Level == "" {
+		c.Log.Level = "info"
+	}
+	if c.Log.Format == "" {
+		c.Log.Format = "json"
+	}
+	if c.Log.SlowMs == 0 {
+		c.Log.SlowMs = 3000
+	}
+}
+
+// validate 检查取值范围与启用功能所需的字段，一次返回所有问题
+func validate(c *Config, p *problems) {
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		p.add("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	p.oneOf("server.mode", c.Server.Mode, "debug", "release", "test")
+	p.nonNegative("server.max_body_mb", c.Server.MaxBodyMB)
+	p.nonNegative("cors.max_age_seconds", c.CORS.MaxAgeSeconds)
+	if c.CORS.AllowCredentials {
+		for _, o := range c.CORS.AllowedOrigins {
+			if o == "*" {
+				p.add("cors.allow_credentials", `cannot be used with allowed_origins "*"; list the trusted origins instead`)
+			}
+		}
+	}
+
+	for _, f := range []struct {
+		name  string
+		value int
+	}{
+		{"http.max_idle_conns", c.HTTP.MaxIdleConns},
+		{"http.max_idle_conns_per_host", c.HTTP.MaxIdleConnsPerHost},
+		{"http.max_conns_per_host", c.HTTP.MaxConnsPerHost},
+		{"http.idle_conn_timeout_seconds", c.HTTP.IdleConnTimeoutSeconds},
+		{"http.dial_timeout_seconds", c.HTTP.DialTimeoutSeconds},
+		{"http.tls_timeout_seconds", c.HTTP.TLSTimeoutSeconds},
+		{"secrets.refresh_minutes", c.Secrets.RefreshMinutes},
+		{"session.history_size", c.Session.HistorySize},
+		{"session.window_minutes", c.Session.WindowMinutes},
+		{"limits.max_tasks", c.Limits.MaxTasks},
+		{"limits.max_recipients", c.Limits.MaxRecipients},
+		{"limits.max_docs", c.Limits.MaxDocs},
+		{"limits.actions_per_minute", c.Limits.ActionsPerMinute},
+		{"limits.deadline_ms", c.Limits.DeadlineMS},
+		{"limits.deadline_shares.planning", c.Limits.DeadlineShares.Planning},
+		{"limits.deadline_shares.extraction", c.Limits.DeadlineShares.Extraction},
+		{"limits.deadline_shares.execution", c.Limits.DeadlineShares.Execution},
+		{"slo.planning_ms", c.SLO.PlanningMS},
+		{"slo.extraction_ms", c.SLO.ExtractionMS},
+		{"slo.execution_ms", c.SLO.ExecutionMS},
+		{"slo.total_ms", c.SLO.TotalMS},
+		{"slo.short_window_minutes", c.SLO.ShortWindowMinutes},
+		{"slo.long_window_minutes", c.SLO.LongWindowMinutes},
+		{"slo.plan_cache_minutes", c.SLO.PlanCacheMinutes},
+	} {
+		p.nonNegative(f.name, f.value)
+	}
+
+	if oidc := c.Auth.OIDC; oidc.Issuer != "" {
+		if !strings.HasPrefix(oidc.Issuer, "https://") && !strings.HasPrefix(oidc.Issuer, "http://") {
+			p.add("auth.oidc.issuer", "must be an http(s) URL, got %q", oidc.Issuer)
+		}
+		if oidc.Audience == "" {
+			p.add("auth.oidc.audience", "required when issuer is set, otherwise tokens issued to other clients are accepted")
+		}
+		for value, role := range oidc.RoleMapping {
+			p.oneOf("auth.oidc.role_mapping."+value, role, "caller", "operator", "admin")
+		}
+	}
+
+	if c.LLM.APIKey == "" {
+		p.add("llm.api_key", "required (or set LLM_API_KEY)")
+	}
+	if c.LLM.BaseURL == "" {
+		p.add("llm.base_url", "required")
+	}
+	if c.LLM.Model == "" {
+		p.add("llm.model", "required")
+	}
+	if sh := c.LLM.Shadow; sh.Enabled {
+		if sh.Model == "" && sh.PromptFile == "" {
+			p.add("llm.shadow", "model or prompt_file required when shadow is enabled")
+		}
+		if sh.Percent < 0 || sh.Percent > 100 {
+			p.add("llm.shadow.percent", "must be between 0 and 100, got %d", sh.Percent)
+		}
+		p.nonNegative("llm.shadow.timeout_seconds", sh.TimeoutSeconds)
+	}
+
+	if c.Feishu.Enabled {
+		if c.Feishu.AppID == "" {
+			p.add("feishu.app_id", "required when feishu is enabled (or set FEISHU_APP_ID)")
+		}
+		if c.Feishu.AppSecret == "" {
+			p.add("feishu.app_secret", "required when feishu is enabled (or set FEISHU_APP_SECRET)")
+		}
+	}
+	p.oneOf("feishu.region", c.Feishu.Region, "auto", "feishu", "lark")
+	if c.Feishu.Marketplace.Enabled {
+		if c.Feishu.Marketplace.VerificationToken == "" {
+			p.add("feishu.marketplace.verification_token", "required when feishu.marketplace is enabled")
+		}
+		if c.Feishu.AuthPath != "" {
+			p.add("feishu.auth_path", "not supported for marketplace apps")
+		}
+	}
+	if c.Feishu.Bot.Enabled {
+		if !c.Feishu.Enabled {
+			p.add("feishu.bot.enabled", "requires feishu.enabled")
+		}
+		if !c.Feishu.Marketplace.Enabled && c.Feishu.Bot.VerificationToken == "" {
+			p.add("feishu.bot.verification_token", "required when feishu.bot is enabled (or set FEISHU_BOT_VERIFICATION_TOKEN)")
+		}
+	}
+	p.nonNegative("feishu.bot.max_attachment_mb", c.Feishu.Bot.MaxAttachmentMB)
+	if (c.Feishu.Helpdesk.ID == "") != (c.Feishu.Helpdesk.Token == "") {
+		p.add("feishu.helpdesk", "id and token must be set together (token may come from FEISHU_HELPDESK_TOKEN)")
+	}
+	if c.Feishu.APIBase != "" && !strings.HasPrefix(c.Feishu.APIBase, "https://") && !strings.HasPrefix(c.Feishu.APIBase, "http://") {
+		p.add("feishu.api_base", "must be an http(s) URL, got %q", c.Feishu.APIBase)
+	}
+	if c.Feishu.AuthPath != "" && !strings.HasPrefix(c.Feishu.AuthPath, "/") {
+		p.add("feishu.auth_path", "must start with /, got %q", c.Feishu.AuthPath)
+	}
+	if t := c.Feishu.DocURLTemplate; t != "" && !strings.Contains(t, "{{token}}") {
+		p.add("feishu.doc_url_template", "must contain {{token}}")
+	}
+	p.oneOf("feishu.duplicate_policy", c.Feishu.DuplicatePolicy, "ask", "reuse", "append", "new")
+	p.nonNegative("feishu.max_message_chars", c.Feishu.MaxMessageChars)
+	p.nonNegative("feishu.cache_ttl_seconds", c.Feishu.CacheTTLSeconds)
+	p.oneOf("feishu.message_overflow", c.Feishu.MessageOverflow, "split", "truncate", "doc")
+	for i, t := range c.Feishu.Tables {
+		if t.Name == "" || t.URL == "" {
+			p.add(fmt.Sprintf("feishu.tables[%d]", i), "name and url are required")
+		}
+	}
+	for name, paths := range c.Feishu.FolderTemplates {
+		if len(paths) == 0 {
+			p.add("feishu.folder_templates."+name, "must list at least one folder")
+		}
+		for _, path := range paths {
+			if strings.Count(strings.Trim(path, "/"), "/") >= 3 {
+				p.add("feishu.folder_templates."+name, "%q is deeper than 3 levels", path)
+			}
+		}
+	}
+	if c.Slack.Enabled && c.Slack.BotToken == "" && c.Slack.OAuth.ClientID == "" {
+		p.add("slack.bot_token", "required when slack is enabled (or set SLACK_BOT_TOKEN, or configure slack.oauth)")
+	}
+	if o := c.Slack.OAuth; o.ClientID != "" {
+		if o.ClientSecret == "" {
+			p.add("slack.oauth.client_secret", "required when slack.oauth.client_id is set")
+		}
+		if o.StateSecret == "" {
+			p.add("slack.oauth.state_secret", "required when slack.oauth.client_id is set (or set SLACK_OAUTH_STATE_SECRET)")
+		} else if o.StateSecret == o.ClientSecret {
+			p.add("slack.oauth.state_secret", "must differ from slack.oauth.client_secret")
+		}
+		if !strings.HasPrefix(o.RedirectURL, "https://") && !strings.HasPrefix(o.RedirectURL, "http://") {
+			p.add("slack.oauth.redirect_url", "must be an http(s) URL, got %q", o.RedirectURL)
+		}
+		if len(o.Scopes) == 0 {
+			p.add("slack.oauth.scopes", "required when slack.oauth.client_id is set")
+		}
+	}
+	if c.Discord.Enabled && c.Discord.BotToken == "" {
+		p.add("discord.bot_token", "required when discord is enabled (or set DISCORD_BOT_TOKEN)")
+	}
+	if c.SMS.Enabled {
+		if c.SMS.AccountSID == "" {
+			p.add("sms.account_sid", "required when sms is enabled")
+		}
+		if c.SMS.AuthToken == "" {
+			p.add("sms.auth_token", "required when sms is enabled (or set SMS_AUTH_TOKEN)")
+		}
+		if c.SMS.From == "" && c.SMS.WhatsAppFrom == "" {
+			p.add("sms.from", "from or whatsapp_from is required when sms is enabled")
+		}
+	}
+	if c.TTS.Enabled && (c.TTS.Model == "" || c.TTS.Voice == "") {
+		p.add("tts", "model and voice are required when tts is enabled")
+	}
+	for i, src := range c.KB.Sources {
+		if !strings.HasPrefix(src.URL, "https://") && !strings.HasPrefix(src.URL, "http://") {
+			p.add(fmt.Sprintf("knowledge_base.sources[%d].url", i), "must be a feishu wiki, folder or docx URL, got %q", src.URL)
+		}
+	}
+	p.nonNegative("knowledge_base.chunk_chars", c.KB.ChunkChars)
+	p.nonNegative("knowledge_base.top_k", c.KB.TopK)
+	p.nonNegative("knowledge_base.refresh_minutes", c.KB.RefreshMinutes)
+	if c.KB.MinScore < 0 || c.KB.MinScore > 1 {
+		p.add("knowledge_base.min_score", "must be between 0 and 1, got %v", c.KB.MinScore)
+	}
+	if o := c.LLM.Overrides; o.Enabled {
+		if o.MinTemperature < 0 || o.MaxTemperature > 2 || o.MinTemperature > o.MaxTemperature {
+			p.add("llm.overrides", "temperature range must satisfy 0 <= min_temperature <= max_temperature <= 2, got [%v, %v]", o.MinTemperature, o.MaxTemperature)
+		}
+		p.nonNegative("llm.overrides.max_tokens", o.MaxTokens)
+	}
+	switch c.Vector.Backend {
+	case "", "memory":
+	case "sql":
+		if c.Vector.SQL.Driver == "" || c.Vector.SQL.DSN == "" {
+			p.add("vector_store.sql", "driver and dsn are required when backend is sql (or set VECTOR_SQL_DSN)")
+		}
+		p.oneOf("vector_store.sql.dialect", c.Vector.SQL.Dialect, "pgvector", "sqlite-vec")
+	case "qdrant":
+		if c.Vector.Qdrant.URL == "" {
+			p.add("vector_store.qdrant.url", "required when backend is qdrant")
+		}
+	default:
+		p.add("vector_store.backend", "must be one of memory | sql | qdrant, got %q", c.Vector.Backend)
+	}
+	p.nonNegative("vector_store.folder_index.refresh_minutes", c.Vector.FolderIndex.RefreshMinutes)
+	p.nonNegative("sms.max_recipients", c.SMS.MaxRecipients)
+	p.nonNegative("sms.max_chars", c.SMS.MaxChars)
+	for i, prefix := range c.SMS.AllowedPrefixes {
+		if !strings.HasPrefix(prefix, "+") {
+			p.add(fmt.Sprintf("sms.allowed_prefixes[%d]", i), "must start with +, got %q", prefix)
+		}
+	}
+	for i, t := range c.Hooks.DenyActions {
+		if strings.TrimSpace(t) == "" {
+			p.add(fmt.Sprintf("hooks.deny_actions[%d]", i), "must not be empty")
+		}
+	}
+	for _, d := range c.Hooks.RecipientPolicy.BlockedDomains {
+		if d = strings.TrimPrefix(strings.TrimSpace(d), "@"); d == "" || strings.ContainsAny(d, "@/ ") {
+			p.add("hooks.recipient_policy.blocked_domains", "invalid domain %q", d)
+		}
+	}
+	if dr := c.Hooks.DraftReview; dr.Enabled {
+		p.nonNegative("hooks.draft_review.min_chars", dr.MinChars)
+		// 回调只凭 Verification Token 校验来源，未配置时任何人都可伪造「发送」
+		if c.Feishu.Enabled && dr.VerificationToken == "" {
+			p.add("hooks.draft_review.verification_token", "required when draft_review and feishu are enabled (or set FEISHU_CARD_VERIFICATION_TOKEN)")
+		}
+	}
+	if c.LLM.Pricing.InputPer1K < 0 || c.LLM.Pricing.OutputPer1K < 0 {
+		p.add("llm.pricing", "prices must not be negative")
+	}
+	p.nonNegative("plugins.health_check_seconds", c.Plugins.HealthCheckSeconds)
+	plugins := make(map[string]bool)
+	for i, pl := range c.Plugins.Endpoints {
+		field := fmt.Sprintf("plugins.endpoints[%d]", i)
+		switch {
+		case pl.Name == "":
+			p.add(field+".name", "required")
+		case plugins[pl.Name]:
+			p.add(field+".name", "duplicate plugin %q", pl.Name)
+		}
+		plugins[pl.Name] = true
+		if !strings.HasPrefix(pl.URL, "https://") && !strings.HasPrefix(pl.URL, "http://") {
+			p.add(field+".url", "must be an http(s) URL, got %q", pl.URL)
+		}
+		p.nonNegative(field+".timeout_seconds", pl.TimeoutSeconds)
+	}
+	p.nonNegative("warmup.interval_minutes", c.Warmup.IntervalMinutes)
+	if c.Maintenance.Jitter < 0 || c.Maintenance.Jitter > 1 {
+		p.add("maintenance.jitter", "must be between 0 and 1, got %v", c.Maintenance.Jitter)
+	}
+	p.nonNegative("maintenance.folder_tree_minutes", c.Maintenance.FolderTreeMinutes)
+	p.nonNegative("maintenance.contacts_minutes", c.Maintenance.ContactsMinutes)
+	p.nonNegative("maintenance.directory_minutes", c.Maintenance.DirectoryMinutes)
+	p.nonNegative("maintenance.channels_minutes", c.Maintenance.ChannelsMinutes)
+	if le := c.Maintenance.LeaderElection; le.Enabled {
+		if le.Driver == "" || le.DSN == "" {
+			p.add("maintenance.leader_election", "driver and dsn are required when enabled (or set LEADER_ELECTION_DSN)")
+		}
+		p.oneOf("maintenance.leader_election.dialect", le.Dialect, "postgres", "sqlite")
+		p.nonNegative("maintenance.leader_election.ttl_seconds", le.TTLSeconds)
+	}
+	for name, f := range c.Flags {
+		if f.Percent < 0 || f.Percent > 100 {
+			p.add("flags."+name+".percent", "must be between 0 and 100, got %d", f.Percent)
+		}
+	}
+	p.nonNegative("scripts.max_steps", c.Scripts.MaxSteps)
+	p.nonNegative("scripts.timeout_seconds", c.Scripts.TimeoutSeconds)
+	p.nonNegative("scripts.max_calls", c.Scripts.MaxCalls)
+	for i, sc := range c.Scripts.Items {
+		field := fmt.Sprintf("scripts.items[%d]", i)
+		if sc.Name == "" || sc.Description == "" {
+			p.add(field, "name and description are required")
+		}
+		if (sc.Source == "") == (sc.File == "") {
+			p.add(field, "exactly one of source and file is required")
+		}
+	}
+	p.nonNegative("slack.max_message_chars", c.Slack.MaxMessageChars)
+	p.oneOf("slack.message_overflow", c.Slack.MessageOverflow, "split", "truncate", "doc")
+	if c.Slack.MessageOverflow == "doc" && !c.Feishu.Enabled {
+		p.add("slack.message_overflow", "doc requires feishu to be enabled")
+	}
+	p.nonNegative("discord.max_message_chars", c.Discord.MaxMessageChars)
+	p.oneOf("discord.message_overflow", c.Discord.MessageOverflow, "split", "truncate", "doc")
+	if c.Discord.MessageOverflow == "doc" && !c.Feishu.Enabled {
+		p.add("discord.message_overflow", "doc requires feishu to be enabled")
+	}
+	workspaces := make(map[string]bool)
+	for i, w := range c.Slack.Workspaces {
+		field := fmt.Sprintf("slack.workspaces[%d]", i)
+		switch {
+		case w.Name == "":
+			p.add(field+".name", "required")
+		case workspaces[strings.ToLower(w.Name)]:
+			p.add(field+".name", "duplicate workspace %q", w.Name)
+		}
+		workspaces[strings.ToLower(w.Name)] = true
+		if w.BotToken == "" {
+			p.add(field+".bot_token", "required")
+		}
+	}
+
+	aliasNames := make(map[string]bool, len(c.Aliases))
+	for _, a := range c.Aliases {
+		aliasNames[a.Name] = true
+	}
+	for i, a := range c.Aliases {
+		field := fmt.Sprintf("aliases[%d]", i)
+		if a.Name == "" {
+			p.add(field+".name", "required")
+		}
+		for j, m := range a.Members {
+			// 嵌套分组的成员只写分组名
+			if !(m.Platform == "" && aliasNames[m.ID]) {
+				p.oneOf(fmt.Sprintf("%s.members[%d].platform", field, j), m.Platform, "feishu", "slack", "discord")
+			}
+			if m.ID == "" {
+				p.add(fmt.Sprintf("%s.members[%d].id", field, j), "required")
+			}
+		}
+	}
+
+	if c.Alert.Platform != "" {
+		p.oneOf("alert.platform", c.Alert.Platform, "feishu", "slack")
+		if c.Alert.Target == "" {
+			p.add("alert.target", "required when alert.platform is set")
+		}
+		if (c.Alert.Platform == "feishu" && !c.Feishu.Enabled) || (c.Alert.Platform == "slack" && !c.Slack.Enabled) {
+			p.add("alert.platform", "%s is not enabled", c.Alert.Platform)
+		}
+	}
+
+	if c.Alert.Panics && c.Alert.Platform == "" {
+		p.add("alert.panics", "requires alert.platform and alert.target")
+	}
+	p.nonNegative("alert.dedup_minutes", c.Alert.DedupMinutes)
+	p.nonNegative("alert.max_per_hour", c.Alert.MaxPerHour)
+	p.nonNegative("alert.error_window_minutes", c.Alert.ErrorWindowMinutes)
+	p.nonNegative("alert.min_samples", c.Alert.MinSamples)
+	p.nonNegative("alert.llm_failures", c.Alert.LLMFailures)
+	p.nonNegative("alert.daily_tokens", c.Alert.DailyTokens)
+	p.nonNegative("alert.job_failures", c.Alert.JobFailures)
+	if c.Alert.ErrorRate < 0 || c.Alert.ErrorRate > 1 {
+		p.add("alert.error_rate", "must be between 0 and 1, got %v", c.Alert.ErrorRate)
+	}
+	if c.Alert.TokenWarnPercent < 0 || c.Alert.TokenWarnPercent > 100 {
+		p.add("alert.token_warn_percent", "must be between 0 and 100, got %d", c.Alert.TokenWarnPercent)
+	}
+	if c.CostReport.Monthly && c.Alert.Platform == "" {
+		p.add("cost_report.monthly", "requires alert.platform and alert.target")
+	}
+
+	if c.Email.Enabled && c.Email.Secret == "" {
+		p.add("email.secret", "required when email is enabled (or set INBOUND_EMAIL_SECRET)")
+	}
+	if c.Email.Enabled && len(c.Email.AllowedDomains) == 0 {
+		p.add("email.allowed_domains", "required when email is enabled")
+	}
+
+	for i, r := range c.FolderRules {
+		field := fmt.Sprintf("folder_rules[%d]", i)
+		if r.Name == "" {
+			p.add(field+".name", "required")
+		}
+		if len(r.Keywords) == 0 {
+			p.add(field+".keywords", "at least one keyword is required")
+		}
+		if r.FolderName == "" && r.FolderToken == "" {
+			p.add(field, "folder_name or folder_token is required")
+		}
+	}
+	people := make(map[string]bool)
+	for i, u := range c.Identities {
+		field := fmt.Sprintf("identities[%d]", i)
+		if u.Name == "" {
+			p.add(field+".name", "required")
+		} else if key := u.TenantID + "/" + u.Name; people[key] {
+			p.add(field+".name", "duplicate identity %q", u.Name)
+		} else {
+			people[key] = true
+		}
+		if u.FeishuOpenID == "" && u.SlackUserID == "" && u.DiscordUserID == "" && u.Email == "" && u.Phone == "" {
+			p.add(field, "at least one of feishu_open_id, slack_user_id, discord_user_id, email, phone is required")
+		}
+	}
+
+	p.oneOf("limits.on_exceed", c.Limits.OnExceed, "confirm", "reject")
+	if c.SLO.Objective < 0 || c.SLO.Objective >= 1 {
+		p.add("slo.objective", "must be in [0, 1), got %v", c.SLO.Objective)
+	}
+	if c.SLO.DegradeBurnRate < 0 {
+		p.add("slo.degrade_burn_rate", "must not be negative, got %v", c.SLO.DegradeBurnRate)
+	}
+	if c.SLO.ShortWindowMinutes > 0 && c.SLO.LongWindowMinutes > 0 && c.SLO.LongWindowMinutes < c.SLO.ShortWindowMinutes {
+		p.add("slo.long_window_minutes", "must not be shorter than short_window_minutes")
+	}
+
+	ids := make(map[string]bool)
+	wrapped := false
+	switch blob := c.Storage.Blob; blob.Backend {
+	case "":
+	case "local":
+		if blob.Local.Dir == "" || blob.Local.BaseURL == "" {
+			p.add("storage.blob.local", "dir and base_url required")
+		}
+		if blob.Local.Secret == "" {
+			p.add("storage.blob.local.secret", "required (or set BLOB_SIGNING_SECRET)")
+		}
+	case "s3":
+		if blob.S3.Bucket == "" || blob.S3.Region == "" {
+			p.add("storage.blob.s3", "bucket and region required")
+		}
+		if blob.URLTTLHours > 168 {
+			p.add("storage.blob.url_ttl_hours", "must not exceed 168 for s3, got %d", blob.URLTTLHours)
+		}
+	case "oss":
+		if blob.OSS.Bucket == "" || blob.OSS.Endpoint == "" {
+			p.add("storage.blob.oss", "bucket and endpoint required")
+		}
+	default:
+		p.oneOf("storage.blob.backend", blob.Backend, "local", "s3", "oss")
+	}
+	p.nonNegative("storage.blob.url_ttl_hours", c.Storage.Blob.URLTTLHours)
+	for i, k := range c.Storage.Encryption.Keys {
+		field := fmt.Sprintf("storage.encryption.keys[%d]", i)
+		switch {
+		case k.ID == "" || strings.Contains(k.ID, ":"):
+			p.add(field+".id", "required and must not contain ':'")
+		case ids[k.ID]:
+			p.add(field+".id", "duplicate id %q", k.ID)
+		}
+		ids[k.ID] = true
+		if (k.Key == "") == (k.KMSWrapped == "") {
+			p.add(field, "exactly one of key or kms_wrapped is required")
+		}
+		wrapped = wrapped || k.KMSWrapped != ""
+	}
+	if wrapped && c.Storage.Encryption.KMS.Region == "" {
+		p.add("storage.encryption.kms.region", "required when keys use kms_wrapped")
+	}
+
+	p.oneOf("server.on_disconnect", c.Server.OnDisconnect, "cancel", "background")
+	p.oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error")
+	p.oneOf("log.format", c.Log.Format, "json", "text")
+	p.nonNegative("log.slow_ms", c.Log.SlowMs)
+	if c.Log.SampleRate < 0 || c.Log.SampleRate > 1 {
+		p.add("log.sample_rate", "must be between 0 and 1, got %g", c.Log.SampleRate)
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// problemsFor 校验配置，返回指定字段的问题
+func problemsFor(c *Config, field string) []string {
+	var p problems
+	validate(c, &p)
+	var out []string
+	for _, msg := range p {
+		if strings.HasPrefix(msg, field+":") {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+func TestValidateCORSCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		cors    CORSConfig
+		wantErr bool
+	}{
+		{name: "wildcard with credentials", cors: CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, wantErr: true},
+		{name: "wildcard without credentials", cors: CORSConfig{AllowedOrigins: []string{"*"}}},
+		{name: "listed origins with credentials", cors: CORSConfig{AllowedOrigins: []string{"https://dash.example.com"}, AllowCredentials: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := problemsFor(&Config{CORS: tt.cors}, "cors.allow_credentials")
+			if (len(got) > 0) != tt.wantErr {
+				t.Fatalf("problems = %v, wantErr %v", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateOIDCAudienceRequired(t *testing.T) {
+	tests := []struct {
+		name    string
+		oidc    OIDCConfig
+		wantErr bool
+	}{
+		{name: "issuer without audience", oidc: OIDCConfig{Issuer: "https://idp.example.com"}, wantErr: true},
+		{name: "issuer with audience", oidc: OIDCConfig{Issuer: "https://idp.example.com", Audience: "sayso-agent"}},
+		{name: "auth disabled", oidc: OIDCConfig{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := problemsFor(&Config{Auth: AuthConfig{OIDC: tt.oidc}}, "auth.oidc.audience")
+			if (len(got) > 0) != tt.wantErr {
+				t.Fatalf("problems = %v, wantErr %v", got, tt.wantErr)
+			}
+		})
+	}
+}
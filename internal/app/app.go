@@ -0,0 +1,888 @@
+// Package app 按配置组装完整服务（客户端、存储、服务层、路由与后台任务），供 cmd/server、压测与测试复用
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"sayso-agent/config"
+	"sayso-agent/internal/auth"
+	"sayso-agent/internal/client/discord"
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/client/llm"
+	"sayso-agent/internal/client/slack"
+	"sayso-agent/internal/client/sms"
+	"sayso-agent/internal/client/transport"
+	"sayso-agent/internal/client/tts"
+	"sayso-agent/internal/flags"
+	"sayso-agent/internal/handler"
+	"sayso-agent/internal/middleware"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/panics"
+	"sayso-agent/internal/plugin"
+	"sayso-agent/internal/script"
+	"sayso-agent/internal/service"
+	"sayso-agent/internal/service/alert"
+	"sayso-agent/internal/service/analytics"
+	"sayso-agent/internal/service/chart"
+	"sayso-agent/internal/service/chat"
+	"sayso-agent/internal/service/deadline"
+	"sayso-agent/internal/service/directory"
+	"sayso-agent/internal/service/executor"
+	"sayso-agent/internal/service/kb"
+	servicellm "sayso-agent/internal/service/llm"
+	"sayso-agent/internal/service/maintenance"
+	"sayso-agent/internal/service/schedule"
+	"sayso-agent/internal/service/slo"
+	"sayso-agent/internal/store"
+)
+
+// shutdownTimeout Run 退出时等待进行中请求完成的时间
+const shutdownTimeout = 30 * time.Second
+
+// Options 替换按配置创建的默认依赖，零值字段使用默认实现（测试、模拟器或其他组装方式）
+type Options struct {
+	// Transport 出站 HTTP（大模型、飞书、Slack、Discord、插件、密钥源），默认为按 http 配置创建的连接池
+	Transport http.RoundTripper
+	// Tasks 任务记录存储，默认为进程内存储（按 storage.encryption 加密）
+	Tasks store.TaskStore
+	// Workflows 工作流存储，默认为进程内存储
+	Workflows store.WorkflowStore
+	// Planner 大模型理解与规划，默认为 llm.Service
+	Planner service.Planner
+	// Executor 动作执行，默认为按配置注册钩子的 executor.Executor
+	Executor service.ActionExecutor
+}
+
+// App 组装好的服务
+type App struct {
+	Config  *config.Config
+	Handler http.Handler
+	ASR     *service.ASRService
+	Tasks   store.TaskStore
+
+	// starters 后台任务（定时工作流、成本报表、预热、插件健康检查、密钥轮换），Start 时依次调用，
+	// 须立即返回，长时间运行的循环自行启动 goroutine；ctx 在 Shutdown 时取消
+	starters []func(ctx context.Context)
+
+	mu     sync.Mutex
+	server *http.Server
+	cancel context.CancelFunc
+}
+
+// New 按配置组装服务；配置中的密钥引用会被解析为实际值（cfg 被修改）。
+// 只做组装与启动前的必要调用（如商店应用的 app_ticket），后台任务在 Start/Run 时启动
+func New(ctx context.Context, cfg *config.Config, opts Options) (*App, error) {
+	a := &App{Config: cfg}
+
+	// 出站连接池，各 API 客户端共享；按请求统计外部 API 调用数
+	httpTransport := opts.Transport
+	if httpTransport == nil {
+		httpTransport = transport.Counting(transport.New(transport.Config{
+			MaxIdleConns:        cfg.HTTP.MaxIdleConns,
+			MaxIdleConnsPerHost: cfg.HTTP.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     cfg.HTTP.MaxConnsPerHost,
+			IdleConnTimeout:     time.Duration(cfg.HTTP.IdleConnTimeoutSeconds) * time.Second,
+			DialTimeout:         time.Duration(cfg.HTTP.DialTimeoutSeconds) * time.Second,
+			TLSHandshakeTimeout: time.Duration(cfg.HTTP.TLSTimeoutSeconds) * time.Second,
+			DisableHTTP2:        cfg.HTTP.DisableHTTP2,
+		}))
+	}
+
+	// 解析配置中的密钥引用（Vault、AWS Secrets Manager、env 文件）
+	secretMgr := newSecretManager(cfg.Secrets, httpTransport)
+	llmKeyRef, feishuSecretRef := cfg.LLM.APIKey, cfg.Feishu.AppSecret
+	shadowKeyRef := cfg.LLM.Shadow.APIKey
+	if err := resolveSecrets(ctx, secretMgr, cfg); err != nil {
+		return nil, fmt.Errorf("load secrets: %w", err)
+	}
+
+	// 构建 LLM 客户端
+	llmClient := llm.NewClient(llm.Config{
+		APIKey:         cfg.LLM.APIKey,
+		BaseURL:        cfg.LLM.BaseURL,
+		Model:          cfg.LLM.Model,
+		VisionModel:    cfg.LLM.VisionModel,
+		EmbeddingModel: cfg.LLM.EmbeddingModel,
+		Transport:      httpTransport,
+		// 语音转写与对话共用 base_url，服务商需兼容 /audio/transcriptions
+		TranscribeModel: cfg.LLM.TranscribeModel,
+	})
+
+	// 构建飞书客户端
+	feishuCfg := feishu.Config{
+		AppID:                cfg.Feishu.AppID,
+		AppSecret:            cfg.Feishu.AppSecret,
+		BotToken:             cfg.Feishu.BotToken,
+		Domain:               cfg.Feishu.Domain,
+		Region:               cfg.Feishu.Region,
+		APIBase:              cfg.Feishu.APIBase,
+		AuthPath:             cfg.Feishu.AuthPath,
+		DocURLTemplate:       cfg.Feishu.DocURLTemplate,
+		Headers:              cfg.Feishu.Headers,
+		Enabled:              cfg.Feishu.Enabled,
+		TitleTemplate:        cfg.Feishu.TitleTemplate,
+		DuplicatePolicy:      cfg.Feishu.DuplicatePolicy,
+		ClarifyCollaborators: cfg.Feishu.ClarifyCollaborators,
+		StrictRecipients:     cfg.Feishu.StrictRecipients,
+		Marketplace:          cfg.Feishu.Marketplace.Enabled,
+		CacheTTL:             time.Duration(cfg.Feishu.CacheTTLSeconds) * time.Second,
+		MaxMessageChars:      cfg.Feishu.MaxMessageChars,
+		MessageOverflow:      cfg.Feishu.MessageOverflow,
+		FolderTemplates:      cfg.Feishu.FolderTemplates,
+		HelpdeskID:           cfg.Feishu.Helpdesk.ID,
+		HelpdeskToken:        cfg.Feishu.Helpdesk.Token,
+		Transport:            httpTransport,
+	}
+	for _, t := range cfg.Feishu.Tables {
+		feishuCfg.Tables = append(feishuCfg.Tables, feishu.TableSource{Name: t.Name, URL: t.URL, Description: t.Description})
+	}
+	feishuClient := feishu.NewClient(feishuCfg)
+
+	// 密钥轮换：定期重新读取，变化后替换客户端中的密钥
+	refresh := time.Duration(cfg.Secrets.RefreshMinutes) * time.Minute
+	a.onStart(func(ctx context.Context) {
+		secretMgr.Watch(ctx, llmKeyRef, refresh, llmClient.SetAPIKey)
+		secretMgr.Watch(ctx, feishuSecretRef, refresh, feishuClient.SetAppSecret)
+	})
+	// 商店应用：加载已安装的企业，并请飞书重新推送 app_ticket（重启后内存中没有 ticket）
+	feishuTenants := store.NewMemoryFeishuTenantStore()
+	if feishuClient.Marketplace() {
+		tenants, err := feishuTenants.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load feishu tenants: %w", err)
+		}
+		for _, t := range tenants {
+			if t.Active {
+				feishuClient.AddTenant(t.TenantID, t.TenantKey)
+			}
+		}
+		if err := feishuClient.ResendAppTicket(ctx); err != nil {
+			log.Printf("feishu resend app ticket: %v", err)
+		}
+	}
+
+	// 构建 Slack 客户端
+	slackCfg := slack.Config{
+		BotToken:        cfg.Slack.BotToken,
+		Enabled:         cfg.Slack.Enabled,
+		MaxMessageChars: cfg.Slack.MaxMessageChars,
+		MessageOverflow: cfg.Slack.MessageOverflow,
+		ClientID:        cfg.Slack.OAuth.ClientID,
+		ClientSecret:    cfg.Slack.OAuth.ClientSecret,
+		Scopes:          cfg.Slack.OAuth.Scopes,
+		Transport:       httpTransport,
+	}
+	for _, w := range cfg.Slack.Workspaces {
+		slackCfg.Workspaces = append(slackCfg.Workspaces, slack.Workspace{Name: w.Name, TeamID: w.TeamID, BotToken: w.BotToken, Tenants: w.Tenants})
+	}
+	slackClient := slack.NewClient(slackCfg)
+	// 任务记录与 Slack 安装记录的加密密钥
+	cipher, err := newCipher(ctx, cfg.Storage.Encryption, secretMgr, httpTransport)
+	if err != nil {
+		return nil, fmt.Errorf("load storage encryption keys: %w", err)
+	}
+	// OAuth 安装的工作区，bot token 加密存储
+	slackInstalls := store.NewSealedSlackInstallStore(store.NewMemorySlackInstallStore(), cipher)
+	installs, err := slackInstalls.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load slack installations: %w", err)
+	}
+	for _, in := range installs {
+		slackClient.AddWorkspace(slack.InstalledWorkspace(in))
+	}
+
+	// 构建 Discord 客户端
+	discordCfg := discord.Config{
+		BotToken:        cfg.Discord.BotToken,
+		Enabled:         cfg.Discord.Enabled,
+		Guild:           cfg.Discord.Guild,
+		MaxMessageChars: cfg.Discord.MaxMessageChars,
+		MessageOverflow: cfg.Discord.MessageOverflow,
+		Transport:       httpTransport,
+	}
+	discordClient := discord.NewClient(discordCfg)
+
+	// 构建短信客户端
+	smsCfg := sms.Config{
+		Enabled:            cfg.SMS.Enabled,
+		AccountSID:         cfg.SMS.AccountSID,
+		AuthToken:          cfg.SMS.AuthToken,
+		From:               cfg.SMS.From,
+		WhatsAppFrom:       cfg.SMS.WhatsAppFrom,
+		DefaultCountryCode: cfg.SMS.DefaultCountryCode,
+		AllowedPrefixes:    cfg.SMS.AllowedPrefixes,
+		MaxRecipients:      cfg.SMS.MaxRecipients,
+		MaxChars:           cfg.SMS.MaxChars,
+		SkipConfirm:        cfg.SMS.SkipConfirm,
+		APIBase:            cfg.SMS.APIBase,
+		Transport:          httpTransport,
+	}
+	smsClient := sms.NewClient(smsCfg)
+
+	// 语音合成，base_url、api_key 为空时沿用大模型配置
+	var speaker executor.Speaker
+	if cfg.TTS.Enabled {
+		ttsCfg := tts.Config{APIKey: cfg.TTS.APIKey, BaseURL: cfg.TTS.BaseURL, Model: cfg.TTS.Model, Voice: cfg.TTS.Voice, Transport: httpTransport}
+		if ttsCfg.APIKey == "" {
+			ttsCfg.APIKey = cfg.LLM.APIKey
+		}
+		if ttsCfg.BaseURL == "" {
+			ttsCfg.BaseURL = cfg.LLM.BaseURL
+		}
+		speaker = tts.NewClient(ttsCfg)
+	}
+
+	// 向量存储：知识库片段与目录名称的向量索引
+	vectors, err := newVectorStore(ctx, cfg.Vector, httpTransport)
+	if err != nil {
+		return nil, fmt.Errorf("vector_store: %w", err)
+	}
+	// 对象存储：导出文件、语音、图表等产物
+	blobs, err := newBlobStore(cfg.Storage.Blob, httpTransport)
+	if err != nil {
+		return nil, fmt.Errorf("storage.blob: %w", err)
+	}
+	var artifacts executor.ArtifactStore
+	if blobs != nil {
+		artifacts = store.NewArtifacts(blobs, time.Duration(cfg.Storage.Blob.URLTTLHours)*time.Hour)
+	}
+	charts, err := chart.NewRenderer(cfg.Feishu.ChartFont)
+	if err != nil {
+		return nil, fmt.Errorf("feishu.chart_font: %w", err)
+	}
+	// 共享后端中的索引只需一个副本重建
+	sharedVectors := cfg.Vector.Backend == "sql" || cfg.Vector.Backend == "qdrant"
+
+	// 运维告警与 panic 上报：相同告警去重合并并限频，失败率、大模型持续失败、token 预算与后台任务连续失败由 monitor 发现
+	notifier := alert.NewNotifier(alert.Config{
+		Platform:   cfg.Alert.Platform,
+		Target:     cfg.Alert.Target,
+		Dedup:      time.Duration(cfg.Alert.DedupMinutes) * time.Minute,
+		MaxPerHour: cfg.Alert.MaxPerHour,
+	}, feishuClient, slackClient)
+	monitor := alert.NewMonitor(alert.MonitorConfig{
+		ErrorRate:        cfg.Alert.ErrorRate,
+		ErrorWindow:      time.Duration(cfg.Alert.ErrorWindowMinutes) * time.Minute,
+		MinSamples:       cfg.Alert.MinSamples,
+		LLMFailures:      cfg.Alert.LLMFailures,
+		DailyTokens:      cfg.Alert.DailyTokens,
+		TokenWarnPercent: cfg.Alert.TokenWarnPercent,
+		JobFailures:      cfg.Alert.JobFailures,
+	}, notifier)
+	var panicNotifier panics.Notifier
+	if cfg.Alert.Panics {
+		panicNotifier = notifier
+	}
+	panicReporter, err := panics.NewReporter(panicNotifier, cfg.Alert.SentryDSN, httpTransport)
+	if err != nil {
+		return nil, fmt.Errorf("alert.sentry_dsn: %w", err)
+	}
+
+	// 后台维护：缓存刷新与索引重建，多副本时经数据库租约选主
+	leases, err := newLeaseStore(ctx, cfg.Maintenance.LeaderElection)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance.leader_election: %w", err)
+	}
+	daemon := maintenance.New(maintenance.Config{
+		Jitter:   cfg.Maintenance.Jitter,
+		LeaseTTL: time.Duration(cfg.Maintenance.LeaderElection.TTLSeconds) * time.Second,
+		OnRun: func(ctx context.Context, job string, err error) {
+			monitor.Job(ctx, fmt.Sprintf("维护任务 %s ", job), err)
+		},
+	}, leases)
+
+	// 知识库：启动后在后台建立索引，按 refresh_minutes 重建
+	var knowledge executor.KnowledgeBase
+	if len(cfg.KB.Sources) > 0 {
+		kbCfg := kb.Config{
+			ChunkChars: cfg.KB.ChunkChars,
+			TopK:       cfg.KB.TopK,
+			MinScore:   cfg.KB.MinScore,
+		}
+		for _, src := range cfg.KB.Sources {
+			kbCfg.Sources = append(kbCfg.Sources, kb.Source{Name: src.Name, URL: src.URL})
+		}
+		kbase := kb.New(kbCfg, feishuClient, llmClient, vectors)
+		daemon.Add(maintenance.Job{
+			Name:       "kb_index",
+			Interval:   time.Duration(cfg.KB.RefreshMinutes) * time.Minute,
+			LeaderOnly: sharedVectors,
+			Run:        kbase.Reindex,
+		})
+		knowledge = kbase
+	}
+
+	// 联系人分组
+	var aliases []model.Alias
+	var aliasNames []string
+	for _, al := range cfg.Aliases {
+		alias := model.Alias{Name: al.Name, Tenants: al.Tenants}
+		for _, m := range al.Members {
+			alias.Members = append(alias.Members, model.AliasMember{Platform: m.Platform, ID: m.ID, Name: m.Name, Title: m.Title, EmploymentType: m.EmploymentType})
+		}
+		aliases = append(aliases, alias)
+		aliasNames = append(aliasNames, al.Name)
+	}
+
+	// 文档归档规则
+	var folderRules []model.FolderRule
+	for _, r := range cfg.FolderRules {
+		tenant := r.TenantID
+		if tenant == "" {
+			tenant = model.DefaultTenant
+		}
+		folderRules = append(folderRules, model.FolderRule{
+			TenantID:    tenant,
+			Name:        r.Name,
+			Keywords:    r.Keywords,
+			FolderName:  r.FolderName,
+			FolderToken: r.FolderToken,
+		})
+	}
+
+	// 跨平台身份映射
+	var identities []model.UserIdentity
+	for _, id := range cfg.Identities {
+		tenant := id.TenantID
+		if tenant == "" {
+			tenant = model.DefaultTenant
+		}
+		identities = append(identities, model.UserIdentity{
+			TenantID:      tenant,
+			Name:          id.Name,
+			Aliases:       id.Aliases,
+			FeishuOpenID:  id.FeishuOpenID,
+			SlackUserID:   id.SlackUserID,
+			DiscordUserID: id.DiscordUserID,
+			Email:         id.Email,
+			Phone:         id.Phone,
+			Source:        model.IdentitySourceManual,
+
+			Title:          id.Title,
+			EmploymentType: id.EmploymentType,
+		})
+	}
+
+	// 技能开关
+	skills := servicellm.SkillPolicy{Disabled: skillTypes(cfg.Skills.Disabled), Tenants: map[string]servicellm.TenantSkills{}, Flags: featureFlags(cfg.Flags)}
+	for tenant, t := range cfg.Skills.Tenants {
+		skills.Tenants[tenant] = servicellm.TenantSkills{Disabled: skillTypes(t.Disabled), Enabled: skillTypes(t.Enabled)}
+	}
+	if !cfg.SMS.Enabled && !cfg.Sandbox {
+		// 未配置短信服务时不让规划器选用 send_sms
+		skills.Disabled = append(skills.Disabled, servicellm.SkillSendSMS)
+	}
+	if len(cfg.KB.Sources) == 0 && !cfg.Sandbox {
+		// 未配置知识库来源时不让规划器选用 ask_kb
+		skills.Disabled = append(skills.Disabled, servicellm.SkillAskKB)
+	}
+	if cfg.Feishu.Helpdesk.ID == "" && !cfg.Sandbox {
+		// 未配置服务台时不让规划器选用 create_ticket
+		skills.Disabled = append(skills.Disabled, servicellm.SkillCreateTicket)
+	}
+
+	// 存储
+	workflowStore := opts.Workflows
+	if workflowStore == nil {
+		workflowStore = store.NewMemoryWorkflowStore()
+	}
+	taskStore := opts.Tasks
+	if taskStore == nil {
+		taskStore = store.NewSealedTaskStore(store.NewMemoryTaskStore(0), cipher, cfg.Storage.RetainTranscripts)
+	}
+	folderRuleStore := store.NewMemoryFolderRuleStore(folderRules)
+	identityStore := store.NewMemoryIdentityStore(identities)
+
+	// 外部插件：未配置时注册表为空，不提供任何技能
+	var pluginCfgs []plugin.Config
+	for _, p := range cfg.Plugins.Endpoints {
+		pluginCfgs = append(pluginCfgs, plugin.Config{
+			Name:    p.Name,
+			URL:     p.URL,
+			Token:   p.Token,
+			Timeout: time.Duration(p.TimeoutSeconds) * time.Second,
+		})
+	}
+	plugins := plugin.NewRegistry(pluginCfgs, servicellm.BuiltinSkills(), httpTransport)
+	if len(pluginCfgs) > 0 {
+		a.onStart(func(ctx context.Context) {
+			plugins.Start(ctx, time.Duration(cfg.Plugins.HealthCheckSeconds)*time.Second)
+		})
+	}
+
+	// 租户脚本
+	var scriptCfgs []script.Config
+	for _, sc := range cfg.Scripts.Items {
+		src := sc.Source
+		if sc.File != "" {
+			b, err := os.ReadFile(sc.File)
+			if err != nil {
+				return nil, fmt.Errorf("load script %s: %w", sc.Name, err)
+			}
+			src = string(b)
+		}
+		scriptCfgs = append(scriptCfgs, script.Config{TenantID: sc.TenantID, Name: sc.Name, Description: sc.Description, Params: sc.Params, Source: src})
+	}
+	scripts, err := script.NewEngine(scriptCfgs, servicellm.BuiltinSkills(), script.Limits{
+		MaxSteps: uint64(cfg.Scripts.MaxSteps),
+		Timeout:  time.Duration(cfg.Scripts.TimeoutSeconds) * time.Second,
+		MaxCalls: cfg.Scripts.MaxCalls,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("compile scripts: %w", err)
+	}
+
+	// 影子规划：候选模型与生产模型共用密钥时随生产密钥轮换
+	shadow, err := newShadow(cfg.LLM, httpTransport)
+	if err != nil {
+		return nil, fmt.Errorf("load shadow planner: %w", err)
+	}
+	if shadow != nil && shadow.Client != nil {
+		if shadowKeyRef == "" {
+			shadowKeyRef = llmKeyRef
+		}
+		a.onStart(func(ctx context.Context) {
+			secretMgr.Watch(ctx, shadowKeyRef, refresh, shadow.Client.SetAPIKey)
+		})
+	}
+
+	// 延迟目标：按任务处理的阶段耗时统计，消耗过快时规划降级
+	var tracker *slo.Tracker
+	var degrade *servicellm.Degradation
+	if cfg.SLO.Enabled {
+		tracker = slo.NewTracker(slo.Config{
+			Objective:       cfg.SLO.Objective,
+			Planning:        time.Duration(cfg.SLO.PlanningMS) * time.Millisecond,
+			Extraction:      time.Duration(cfg.SLO.ExtractionMS) * time.Millisecond,
+			Execution:       time.Duration(cfg.SLO.ExecutionMS) * time.Millisecond,
+			Total:           time.Duration(cfg.SLO.TotalMS) * time.Millisecond,
+			ShortWindow:     time.Duration(cfg.SLO.ShortWindowMinutes) * time.Minute,
+			LongWindow:      time.Duration(cfg.SLO.LongWindowMinutes) * time.Minute,
+			DegradeBurnRate: cfg.SLO.DegradeBurnRate,
+		})
+		degrade = &servicellm.Degradation{
+			Guard:         tracker,
+			FallbackModel: cfg.SLO.FallbackModel,
+			PlanCacheTTL:  time.Duration(cfg.SLO.PlanCacheMinutes) * time.Minute,
+		}
+	}
+
+	// 服务层
+	planner := opts.Planner
+	if planner == nil {
+		planner = servicellm.NewService(servicellm.Deps{
+			Client:     llmClient,
+			Aliases:    aliasNames,
+			Workflows:  workflowStore,
+			Skills:     skills,
+			Plugins:    plugins,
+			Scripts:    scripts,
+			FastPath:   skillTypes(cfg.LLM.FastPath),
+			Heuristics: cfg.LLM.Heuristics,
+			Shadow:     shadow,
+			Panics:     panicReporter,
+			Degrade:    degrade,
+		})
+	}
+	exec := opts.Executor
+	if exec == nil {
+		// 目录索引：后台定期把飞书目录名称向量化，目录较多时由向量相似度筛选候选目录
+		var folderVectors store.VectorStore
+		if cfg.Vector.FolderIndex.Enabled {
+			folderVectors = vectors
+		}
+		folderMatcher := servicellm.NewFolderMatcher(llmClient, folderVectors)
+		if folderVectors != nil && cfg.Feishu.Enabled && !cfg.Sandbox {
+			refresh := cfg.Vector.FolderIndex.RefreshMinutes
+			if refresh <= 0 {
+				refresh = 60
+			}
+			daemon.Add(maintenance.Job{
+				Name:       "folder_index",
+				Interval:   time.Duration(refresh) * time.Minute,
+				LeaderOnly: sharedVectors,
+				Run:        func(ctx context.Context) error { return indexFolders(ctx, feishuClient, folderMatcher) },
+			})
+		}
+		if m := cfg.Maintenance.DirectoryMinutes; m > 0 && (cfg.Feishu.Enabled || cfg.Slack.Enabled) && !cfg.Sandbox {
+			// 通讯录同步：身份映射在进程内，每个副本各自同步
+			var syncFeishu *feishu.Client
+			var syncSlack *slack.Client
+			if cfg.Feishu.Enabled {
+				syncFeishu = feishuClient
+			}
+			if cfg.Slack.Enabled {
+				syncSlack = slackClient
+			}
+			syncer := directory.NewSyncer(syncFeishu, syncSlack, identityStore)
+			daemon.Add(maintenance.Job{Name: "directory_sync", Interval: time.Duration(m) * time.Minute, Run: syncer.Run})
+		}
+		e := newExecutor(cfg, executor.Deps{
+			Feishu:        feishuClient,
+			FeishuCfg:     feishuCfg,
+			Slack:         slackClient,
+			SlackCfg:      slackCfg,
+			Discord:       discordClient,
+			DiscordCfg:    discordCfg,
+			SMS:           smsClient,
+			SMSCfg:        smsCfg,
+			FolderMatcher: folderMatcher,
+			FolderRules:   folderRuleStore,
+			Summarizer:    servicellm.NewMinutesSummarizer(llmClient),
+			Titler:        servicellm.NewTitler(llmClient),
+			Analyst:       servicellm.NewTableAnalyst(llmClient),
+			Speaker:       speaker,
+			KB:            knowledge,
+			Aliases:       aliases,
+			Plugins:       plugins,
+			Scripts:       scripts,
+			Artifacts:     artifacts,
+			Charts:        charts,
+			Sandbox:       cfg.Sandbox,
+		}, identityStore)
+		addCacheJobs(daemon, e, cfg)
+		if cfg.Warmup.Enabled {
+			a.onStart(func(ctx context.Context) { go warmup(ctx, e, cfg.Warmup) })
+		}
+		exec = e
+	}
+	asrSvc := service.NewASRService(planner, exec, taskStore, service.SessionConfig{
+		HistorySize: cfg.Session.HistorySize,
+		Window:      time.Duration(cfg.Session.WindowMinutes) * time.Minute,
+	}, service.Limits{
+		MaxTasks:         cfg.Limits.MaxTasks,
+		MaxDocs:          cfg.Limits.MaxDocs,
+		ActionsPerMinute: cfg.Limits.ActionsPerMinute,
+		RejectOversized:  cfg.Limits.OnExceed == "reject",
+		Deadline:         time.Duration(cfg.Limits.DeadlineMS) * time.Millisecond,
+		DeadlineShares:   deadline.Shares{cfg.Limits.DeadlineShares.Planning, cfg.Limits.DeadlineShares.Extraction, cfg.Limits.DeadlineShares.Execution},
+		LLM: service.LLMBounds{
+			Enabled:        cfg.LLM.Overrides.Enabled,
+			Models:         cfg.LLM.Overrides.Models,
+			MinTemperature: cfg.LLM.Overrides.MinTemperature,
+			MaxTemperature: cfg.LLM.Overrides.MaxTemperature,
+			MaxTokens:      cfg.LLM.Overrides.MaxTokens,
+		},
+	})
+	asrSvc.Observe(monitor)
+	if tracker != nil {
+		asrSvc.Observe(tracker)
+	}
+	a.ASR, a.Tasks = asrSvc, taskStore
+
+	// 维护任务全部注册后再启动
+	a.onStart(func(ctx context.Context) { go daemon.Run(ctx) })
+
+	// 定时工作流
+	if cfg.Scheduler.Enabled {
+		a.onStart(func(ctx context.Context) {
+			go schedule.NewScheduler(workflowStore, asrSvc, monitor, leases, panicReporter).Run(ctx)
+		})
+	}
+
+	analyzer := analytics.New(taskStore, analytics.Pricing{InputPer1K: cfg.LLM.Pricing.InputPer1K, OutputPer1K: cfg.LLM.Pricing.OutputPer1K})
+	if cfg.CostReport.Monthly {
+		a.onStart(func(ctx context.Context) { go analyzer.RunMonthly(ctx, notifier.Post) })
+	}
+
+	// 路由
+	routerOpts := handler.Options{
+		ASR:          asrSvc,
+		Workflows:    workflowStore,
+		Tasks:        taskStore,
+		FolderRules:  folderRuleStore,
+		Identities:   identityStore,
+		Analytics:    analyzer,
+		Plugins:      plugins,
+		Maintenance:  daemon,
+		MaxBodyBytes: int64(cfg.Server.MaxBodyMB) << 20,
+		Gzip:         cfg.Server.Gzip,
+		Panics:       panicReporter,
+		AccessLog: middleware.LoggerConfig{
+			Format:     cfg.Log.Format,
+			Slow:       time.Duration(cfg.Log.SlowMs) * time.Millisecond,
+			SampleRate: cfg.Log.SampleRate,
+		},
+	}
+	// 客户端断开后转为后台任务
+	routerOpts.ContinueOnDisconnect = cfg.Server.OnDisconnect == "background"
+	routerOpts.AdminUI = cfg.Server.AdminUI
+	routerOpts.SLO = tracker
+	if local, ok := blobs.(*store.LocalBlobStore); ok {
+		routerOpts.Blobs = local
+	}
+	if cfg.Auth.OIDC.Issuer != "" {
+		roleMapping := make(map[string]auth.Role)
+		for value, role := range cfg.Auth.OIDC.RoleMapping {
+			roleMapping[value] = auth.Role(role)
+		}
+		routerOpts.Auth = auth.NewOIDCVerifier(auth.OIDCConfig{
+			Issuer:      cfg.Auth.OIDC.Issuer,
+			Audience:    cfg.Auth.OIDC.Audience,
+			JWKSURL:     cfg.Auth.OIDC.JWKSURL,
+			UserClaim:   cfg.Auth.OIDC.UserClaim,
+			TenantClaim: cfg.Auth.OIDC.TenantClaim,
+			NameClaim:   cfg.Auth.OIDC.NameClaim,
+			RoleClaim:   cfg.Auth.OIDC.RoleClaim,
+			RoleMapping: roleMapping,
+			Transport:   httpTransport,
+		})
+	}
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		routerOpts.CORS = &middleware.CORSConfig{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			ExposedHeaders:   cfg.CORS.ExposedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAgeSeconds,
+		}
+	}
+	if slackClient.OAuthEnabled() {
+		routerOpts.SlackOAuth = &handler.SlackOAuthConfig{
+			Client:      slackClient,
+			Installs:    slackInstalls,
+			RedirectURL: cfg.Slack.OAuth.RedirectURL,
+			StateSecret: cfg.Slack.OAuth.StateSecret,
+		}
+	}
+	if feishuClient.Marketplace() || cfg.Feishu.Bot.Enabled {
+		events := handler.FeishuEventConfig{
+			Client:            feishuClient,
+			Tenants:           feishuTenants,
+			VerificationToken: cfg.Feishu.Marketplace.VerificationToken,
+			EncryptKey:        cfg.Feishu.Marketplace.EncryptKey,
+			TenantIDs:         cfg.Feishu.Marketplace.Tenants,
+			Marketplace:       feishuClient.Marketplace(),
+		}
+		if !events.Marketplace {
+			events.VerificationToken, events.EncryptKey = cfg.Feishu.Bot.VerificationToken, cfg.Feishu.Bot.EncryptKey
+		}
+		if cfg.Feishu.Bot.Enabled {
+			events.Bot = chat.NewFeishu(chat.FeishuConfig{
+				TenantID:           cfg.Feishu.Bot.TenantID,
+				MaxAttachmentBytes: int64(cfg.Feishu.Bot.MaxAttachmentMB) << 20,
+			}, feishuClient, llmClient)
+		}
+		routerOpts.FeishuEvents = &events
+	}
+	if cfg.Hooks.DraftReview.Enabled && cfg.Feishu.Enabled {
+		routerOpts.FeishuCard = &handler.FeishuCardConfig{VerificationToken: cfg.Hooks.DraftReview.VerificationToken}
+	}
+	if cfg.Email.Enabled {
+		routerOpts.Email = &handler.EmailConfig{
+			Secret:         cfg.Email.Secret,
+			AllowedDomains: cfg.Email.AllowedDomains,
+			TenantID:       cfg.Email.TenantID,
+		}
+	}
+	a.Handler = handler.Router(routerOpts)
+	return a, nil
+}
+
+// newExecutor 创建执行器并按配置注册内置钩子
+func newExecutor(cfg *config.Config, deps executor.Deps, identities store.IdentityStore) *executor.Executor {
+	exec := executor.NewExecutor(deps)
+	// 日志钩子先注册，被策略拒绝的动作也会记录
+	if cfg.Hooks.ActionLog {
+		exec.Use(executor.ActionLogHook())
+	}
+	if cfg.Hooks.VerifyResults {
+		exec.Use(exec.VerifyHook())
+	}
+	if len(cfg.Hooks.DenyActions) > 0 {
+		exec.Use(executor.DenyActionsHook(cfg.Hooks.DenyActions))
+	}
+	// 先改投平台，接收方策略按实际发送的平台检查
+	if cfg.Hooks.PlatformFallback {
+		exec.Use(exec.PlatformFallbackHook(identities))
+	}
+	// 按条件排除分组成员时先列出最终接收方请用户确认
+	if len(deps.Aliases) > 0 {
+		exec.Use(exec.GroupExclusionHook(identities))
+	}
+	// 接收方上限按展开分组、排除成员后的实际人数检查
+	if cfg.Limits.MaxRecipients > 0 {
+		exec.Use(exec.RecipientLimitHook(cfg.Limits.MaxRecipients, cfg.Limits.OnExceed == "reject"))
+	}
+	if rp := cfg.Hooks.RecipientPolicy; !rp.Empty() {
+		exec.Use(exec.RecipientPolicyHook(executor.RecipientPolicy{
+			AllowedChats:   rp.AllowedChats,
+			ConfirmChats:   rp.ConfirmChats,
+			BlockedUsers:   rp.BlockedUsers,
+			BlockedDomains: rp.BlockedDomains,
+		}))
+	}
+	if dr := cfg.Hooks.DraftReview; dr.Enabled {
+		exec.Use(exec.DraftReviewHook(executor.DraftReview{MinChars: dr.MinChars}))
+	}
+	return exec
+}
+
+// onStart 注册后台任务
+func (a *App) onStart(start func(ctx context.Context)) {
+	a.starters = append(a.starters, start)
+}
+
+// Start 启动后台任务，不监听端口（测试中直接使用 Handler 时调用）；Shutdown 时停止
+func (a *App) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	a.mu.Lock()
+	a.cancel = cancel
+	a.mu.Unlock()
+	for _, start := range a.starters {
+		start(ctx)
+	}
+}
+
+// Run 启动后台任务并在 server.port 上提供服务，直到 ctx 取消（优雅退出）或监听失败
+func (a *App) Run(ctx context.Context) error {
+	a.Start(ctx)
+	addr := fmt.Sprintf(":%d", a.Config.Server.Port)
+	srv := &http.Server{Addr: addr, Handler: a.Handler}
+	a.mu.Lock()
+	a.server = srv
+	a.mu.Unlock()
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	log.Printf("server starting at %s (env=%s)", addr, config.Env())
+	select {
+	case err := <-errc:
+		a.Shutdown(context.Background())
+		return fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
+	log.Printf("server shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return a.Shutdown(shutdownCtx)
+}
+
+// Shutdown 停止后台任务，等待进行中的请求完成（至 ctx 截止）后关闭监听
+func (a *App) Shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	cancel, srv := a.cancel, a.server
+	a.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if srv == nil {
+		return nil
+	}
+	if err := srv.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func skillTypes(names []string) []servicellm.SkillType {
+	types := make([]servicellm.SkillType, 0, len(names))
+	for _, n := range names {
+		types = append(types, servicellm.SkillType(n))
+	}
+	return types
+}
+
+// newShadow 影子规划配置；未启用时返回 nil，只换 Prompt 时候选模型为 nil（使用生产模型）
+func newShadow(cfg config.LLMConfig, rt http.RoundTripper) (*servicellm.Shadow, error) {
+	sc := cfg.Shadow
+	if !sc.Enabled {
+		return nil, nil
+	}
+	shadow := &servicellm.Shadow{Percent: sc.Percent, Timeout: time.Duration(sc.TimeoutSeconds) * time.Second}
+	if sc.PromptFile != "" {
+		b, err := os.ReadFile(sc.PromptFile)
+		if err != nil {
+			return nil, fmt.Errorf("read prompt file: %w", err)
+		}
+		shadow.Prompt = string(b)
+	}
+	if sc.Model != "" || sc.BaseURL != "" || sc.APIKey != "" {
+		c := llm.Config{APIKey: sc.APIKey, BaseURL: sc.BaseURL, Model: sc.Model, Transport: rt}
+		if c.APIKey == "" {
+			c.APIKey = cfg.APIKey
+		}
+		if c.BaseURL == "" {
+			c.BaseURL = cfg.BaseURL
+		}
+		if c.Model == "" {
+			c.Model = cfg.Model
+		}
+		shadow.Client = llm.NewClient(c)
+	}
+	return shadow, nil
+}
+
+// featureFlags 功能灰度配置
+func featureFlags(cfgs map[string]config.FlagConfig) *flags.Set {
+	set := make(map[string]flags.Flag, len(cfgs))
+	for name, f := range cfgs {
+		set[name] = flags.Flag{Percent: f.Percent, Tenants: f.Tenants, ExcludeTenants: f.ExcludeTenants}
+	}
+	return flags.New(set)
+}
+
+// indexFolders 重建目录名称向量索引；只索引默认租户的云空间
+func indexFolders(ctx context.Context, client *feishu.Client, matcher *servicellm.FolderMatcher) error {
+	token, err := client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("get token: %w", err)
+	}
+	folders, err := client.GetFolderTree(ctx, token, 2)
+	if err != nil {
+		return fmt.Errorf("list folders: %w", err)
+	}
+	return matcher.IndexFolders(ctx, folders)
+}
+
+// addCacheJobs 注册目录树、常用联系人与 Slack 频道列表的缓存刷新任务，间隔为 0 的不注册
+func addCacheJobs(daemon *maintenance.Daemon, e *executor.Executor, cfg *config.Config) {
+	if cfg.Sandbox {
+		return
+	}
+	m := cfg.Maintenance
+	if m.FolderTreeMinutes > 0 && cfg.Feishu.Enabled {
+		daemon.Add(maintenance.Job{Name: "folder_trees", Interval: time.Duration(m.FolderTreeMinutes) * time.Minute, Run: e.RefreshFolderTrees})
+	}
+	if m.ContactsMinutes > 0 && cfg.Feishu.Enabled && len(cfg.Warmup.Contacts) > 0 {
+		daemon.Add(maintenance.Job{Name: "contacts", Interval: time.Duration(m.ContactsMinutes) * time.Minute, Run: func(ctx context.Context) error {
+			return e.RefreshContacts(ctx, cfg.Warmup.Contacts)
+		}})
+	}
+	if m.ChannelsMinutes > 0 && cfg.Slack.Enabled {
+		daemon.Add(maintenance.Job{Name: "slack_channels", Interval: time.Duration(m.ChannelsMinutes) * time.Minute, Run: e.RefreshChannels})
+	}
+}
+
+// warmupTimeout 单次预热的超时
+const warmupTimeout = 2 * time.Minute
+
+// warmup 启动后立即预热一次，interval_minutes 大于 0 时定期重新预热，直到 ctx 取消
+func warmup(ctx context.Context, exec *executor.Executor, cfg config.WarmupConfig) {
+	run := func() {
+		ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+		defer cancel()
+		exec.Warmup(ctx, cfg.Contacts)
+	}
+	run()
+	if cfg.IntervalMinutes <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(cfg.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run()
+		}
+	}
+}
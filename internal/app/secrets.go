@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"sayso-agent/config"
+	"sayso-agent/internal/secrets"
+)
+
+// newSecretManager 按配置注册密钥源；未配置任何密钥源时配置值原样使用
+func newSecretManager(cfg config.SecretsConfig, rt http.RoundTripper) *secrets.Manager {
+	providers := map[string]secrets.Provider{}
+	if cfg.Vault.Address != "" {
+		providers["vault"] = secrets.NewVaultProvider(secrets.VaultConfig{
+			Address:   cfg.Vault.Address,
+			Token:     cfg.Vault.Token,
+			Namespace: cfg.Vault.Namespace,
+			Transport: rt,
+		})
+	}
+	if cfg.AWS.Region != "" {
+		providers["aws-sm"] = secrets.NewAWSProvider(secrets.AWSConfig{
+			Region:          cfg.AWS.Region,
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Endpoint:        cfg.AWS.Endpoint,
+			Transport:       rt,
+		})
+	}
+	if cfg.EnvFile != "" {
+		providers["env-file"] = secrets.NewEnvFileProvider(cfg.EnvFile)
+	}
+	return secrets.NewManager(providers)
+}
+
+// resolveSecrets 把配置中的密钥引用替换为实际值
+func resolveSecrets(ctx context.Context, m *secrets.Manager, cfg *config.Config) error {
+	for _, field := range []*string{
+		&cfg.LLM.APIKey,
+		&cfg.LLM.Shadow.APIKey,
+		&cfg.Feishu.AppID,
+		&cfg.Feishu.AppSecret,
+		&cfg.Feishu.BotToken,
+		&cfg.Feishu.Marketplace.VerificationToken,
+		&cfg.Feishu.Marketplace.EncryptKey,
+		&cfg.Feishu.Bot.VerificationToken,
+		&cfg.Feishu.Bot.EncryptKey,
+		&cfg.Feishu.Helpdesk.Token,
+		&cfg.Slack.BotToken,
+		&cfg.Slack.OAuth.ClientSecret,
+		&cfg.Slack.OAuth.StateSecret,
+		&cfg.Discord.BotToken,
+		&cfg.SMS.AuthToken,
+		&cfg.TTS.APIKey,
+		&cfg.Vector.SQL.DSN,
+		&cfg.Vector.Qdrant.APIKey,
+		&cfg.Storage.Blob.Local.Secret,
+		&cfg.Storage.Blob.S3.SecretAccessKey,
+		&cfg.Storage.Blob.OSS.AccessKeySecret,
+		&cfg.Maintenance.LeaderElection.DSN,
+		&cfg.Hooks.DraftReview.VerificationToken,
+		&cfg.Email.Secret,
+		&cfg.Alert.SentryDSN,
+	} {
+		v, err := m.Resolve(ctx, *field)
+		if err != nil {
+			return err
+		}
+		*field = v
+	}
+	for i := range cfg.Slack.Workspaces {
+		v, err := m.Resolve(ctx, cfg.Slack.Workspaces[i].BotToken)
+		if err != nil {
+			return err
+		}
+		cfg.Slack.Workspaces[i].BotToken = v
+	}
+	for i := range cfg.Plugins.Endpoints {
+		v, err := m.Resolve(ctx, cfg.Plugins.Endpoints[i].Token)
+		if err != nil {
+			return err
+		}
+		cfg.Plugins.Endpoints[i].Token = v
+	}
+	for name, value := range cfg.Feishu.Headers {
+		v, err := m.Resolve(ctx, value)
+		if err != nil {
+			return err
+		}
+		cfg.Feishu.Headers[name] = v
+	}
+	return nil
+}
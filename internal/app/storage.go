@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+
+	"sayso-agent/config"
+	"sayso-agent/internal/secrets"
+	"sayso-agent/internal/store"
+)
+
+// newCipher 按配置准备数据密钥：key 经密钥引用解析后 base64 解码，kms_wrapped 调用 AWS KMS 解密；未配置密钥时返回 nil
+func newCipher(ctx context.Context, cfg config.EncryptionConfig, secretMgr *secrets.Manager, rt http.RoundTripper) (*store.Cipher, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, nil
+	}
+	var kms *secrets.AWSKMS
+	var keys []store.CipherKey
+	for _, k := range cfg.Keys {
+		var key []byte
+		switch {
+		case k.KMSWrapped != "":
+			blob, err := base64.StdEncoding.DecodeString(k.KMSWrapped)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: decode kms_wrapped: %w", k.ID, err)
+			}
+			if kms == nil {
+				kms = secrets.NewAWSKMS(secrets.AWSConfig{
+					Region:          cfg.KMS.Region,
+					AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+					SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+					SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+					Endpoint:        cfg.KMS.Endpoint,
+					Transport:       rt,
+				})
+			}
+			if key, err = kms.Decrypt(ctx, blob); err != nil {
+				return nil, fmt.Errorf("key %s: %w", k.ID, err)
+			}
+		case k.Key != "":
+			encoded, err := secretMgr.Resolve(ctx, k.Key)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", k.ID, err)
+			}
+			if key, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+				return nil, fmt.Errorf("key %s: decode key: %w", k.ID, err)
+			}
+		default:
+			return nil, fmt.Errorf("key %s: key or kms_wrapped required", k.ID)
+		}
+		keys = append(keys, store.CipherKey{ID: k.ID, Key: key})
+	}
+	return store.NewCipher(keys)
+}
+
+// newVectorStore 按配置创建向量存储；sql 后端的驱动须已编译进二进制（在 main 包中以空白导入注册）
+func newVectorStore(ctx context.Context, cfg config.VectorConfig, rt http.RoundTripper) (store.VectorStore, error) {
+	switch cfg.Backend {
+	case "sql":
+		db, err := sql.Open(cfg.SQL.Driver, cfg.SQL.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("open %s (is the driver linked into the binary?): %w", cfg.SQL.Driver, err)
+		}
+		vs, err := store.NewSQLVectorStore(ctx, db, cfg.SQL.Dialect, cfg.SQL.Table)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return vs, nil
+	case "qdrant":
+		return store.NewQdrantVectorStore(cfg.Qdrant.URL, cfg.Qdrant.APIKey, cfg.Qdrant.Prefix, rt), nil
+	default:
+		return store.NewMemoryVectorStore(), nil
+	}
+}
+
+// newBlobStore 按配置创建对象存储；未配置时返回 nil（不保存产物）
+func newBlobStore(cfg config.BlobConfig, rt http.RoundTripper) (store.BlobStore, error) {
+	switch cfg.Backend {
+	case "local":
+		return store.NewLocalBlobStore(cfg.Local.Dir, cfg.Local.BaseURL, []byte(cfg.Local.Secret))
+	case "s3":
+		s3 := store.S3Config{
+			Bucket:          cfg.S3.Bucket,
+			Region:          cfg.S3.Region,
+			Endpoint:        cfg.S3.Endpoint,
+			PathStyle:       cfg.S3.PathStyle,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			Transport:       rt,
+		}
+		if s3.AccessKeyID == "" {
+			s3.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+			s3.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+			s3.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+		return store.NewS3BlobStore(s3)
+	case "oss":
+		oss := store.OSSConfig{
+			Bucket:          cfg.OSS.Bucket,
+			Endpoint:        cfg.OSS.Endpoint,
+			AccessKeyID:     cfg.OSS.AccessKeyID,
+			AccessKeySecret: cfg.OSS.AccessKeySecret,
+			Transport:       rt,
+		}
+		if oss.AccessKeyID == "" {
+			oss.AccessKeyID = os.Getenv("OSS_ACCESS_KEY_ID")
+			oss.AccessKeySecret = os.Getenv("OSS_ACCESS_KEY_SECRET")
+		}
+		return store.NewOSSBlobStore(oss)
+	default:
+		return nil, nil
+	}
+}
+
+// newLeaseStore 按配置创建选主用的租约存储；未开启选主时返回 nil（单副本，始终为主）
+func newLeaseStore(ctx context.Context, cfg config.LeaderElectionConfig) (store.LeaseStore, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	db, err := sql.Open(cfg.Driver, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("open %s (is the driver linked into the binary?): %w", cfg.Driver, err)
+	}
+	leases, err := store.NewSQLLeaseStore(ctx, db, cfg.Dialect, cfg.Table)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return leases, nil
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testAudience = "sayso-agent"
+)
+
+// jwksStub 以 JWKS 接口返回 keys，记下请求次数
+type jwksStub struct {
+	mu    sync.Mutex
+	keys  map[string]*rsa.PrivateKey
+	calls int
+}
+
+func (s *jwksStub) RoundTrip(r *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, k := range s.keys {
+		set.Keys = append(set.Keys, jwk{
+			Kid: kid, Kty: "RSA", Use: "sig",
+			N: base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		})
+	}
+	body, _ := json.Marshal(set)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(string(body))), Header: make(http.Header), Request: r}, nil
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+// encodeJWT 按 header 与 claims 拼出待签名内容，sign 返回签名
+func encodeJWT(header, claims map[string]any, sign func(signed []byte) []byte) string {
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signed)))
+}
+
+func rs256(key *rsa.PrivateKey) func([]byte) []byte {
+	return func(signed []byte) []byte {
+		digest := sha256.Sum256(signed)
+		sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		return sig
+	}
+}
+
+func validClaims(now time.Time) map[string]any {
+	return map[string]any{"iss": testIssuer, "aud": testAudience, "sub": "ou_alice", "exp": now.Add(time.Hour).Unix()}
+}
+
+func TestVerifyRejectsForgedAndInvalidTokens(t *testing.T) {
+	key := newRSAKey(t)
+	stub := &jwksStub{keys: map[string]*rsa.PrivateKey{"k1": key}}
+	v := NewOIDCVerifier(OIDCConfig{Issuer: testIssuer, Audience: testAudience, JWKSURL: "https://idp.example.com/jwks", Transport: stub})
+	now := time.Now()
+	rsHeader := map[string]any{"alg": "RS256", "kid": "k1"}
+	with := func(k string, val any) map[string]any {
+		c := validClaims(now)
+		c[k] = val
+		return c
+	}
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	pubDER, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error // nil 表示应通过
+	}{
+		{name: "valid", token: encodeJWT(rsHeader, validClaims(now), rs256(key))},
+		{name: "audience in list", token: encodeJWT(rsHeader, with("aud", []any{"other", testAudience}), rs256(key))},
+		{name: "alg none", token: encodeJWT(map[string]any{"alg": "none", "kid": "k1"}, validClaims(now), func([]byte) []byte { return nil }), wantErr: ErrInvalidToken},
+		{name: "hs256 keyed with public key", token: encodeJWT(map[string]any{"alg": "HS256", "kid": "k1"}, validClaims(now), func(signed []byte) []byte {
+			m := hmac.New(sha256.New, pubDER)
+			m.Write(signed)
+			return m.Sum(nil)
+		}), wantErr: ErrInvalidToken},
+		{name: "es256 against rsa key", token: encodeJWT(map[string]any{"alg": "ES256", "kid": "k1"}, validClaims(now), func(signed []byte) []byte {
+			digest := sha256.Sum256(signed)
+			r, s, _ := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+			return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}), wantErr: ErrInvalidToken},
+		{name: "signed by other key", token: encodeJWT(rsHeader, validClaims(now), rs256(newRSAKey(t))), wantErr: ErrInvalidToken},
+		{name: "wrong audience", token: encodeJWT(rsHeader, with("aud", "other-client"), rs256(key)), wantErr: ErrInvalidToken},
+		{name: "missing audience", token: encodeJWT(rsHeader, with("aud", nil), rs256(key)), wantErr: ErrInvalidToken},
+		{name: "wrong issuer", token: encodeJWT(rsHeader, with("iss", "https://evil.example.com"), rs256(key)), wantErr: ErrInvalidToken},
+		{name: "expired", token: encodeJWT(rsHeader, with("exp", now.Add(-time.Hour).Unix()), rs256(key)), wantErr: ErrTokenExpired},
+		{name: "not yet valid", token: encodeJWT(rsHeader, with("nbf", now.Add(time.Hour).Unix()), rs256(key)), wantErr: ErrInvalidToken},
+		{name: "missing exp", token: encodeJWT(rsHeader, with("exp", nil), rs256(key)), wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := v.Verify(context.Background(), tt.token)
+			if tt.wantErr == nil {
+				if err != nil || id.UserID != "ou_alice" {
+					t.Fatalf("Verify = %+v, %v; want ou_alice", id, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyRequiresAudience(t *testing.T) {
+	key := newRSAKey(t)
+	stub := &jwksStub{keys: map[string]*rsa.PrivateKey{"k1": key}}
+	v := NewOIDCVerifier(OIDCConfig{Issuer: testIssuer, JWKSURL: "https://idp.example.com/jwks", Transport: stub})
+	token := encodeJWT(map[string]any{"alg": "RS256", "kid": "k1"}, validClaims(time.Now()), rs256(key))
+	if _, err := v.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Verify without configured audience err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifyRefreshesUnknownKid(t *testing.T) {
+	old, rotated := newRSAKey(t), newRSAKey(t)
+	stub := &jwksStub{keys: map[string]*rsa.PrivateKey{"k1": old}}
+	v := NewOIDCVerifier(OIDCConfig{Issuer: testIssuer, Audience: testAudience, JWKSURL: "https://idp.example.com/jwks", Transport: stub})
+	ctx := context.Background()
+	now := time.Now()
+
+	if _, err := v.Verify(ctx, encodeJWT(map[string]any{"alg": "RS256", "kid": "k1"}, validClaims(now), rs256(old))); err != nil {
+		t.Fatalf("Verify with initial key: %v", err)
+	}
+	stub.mu.Lock()
+	stub.keys = map[string]*rsa.PrivateKey{"k2": rotated}
+	stub.mu.Unlock()
+	rotatedToken := encodeJWT(map[string]any{"alg": "RS256", "kid": "k2"}, validClaims(now), rs256(rotated))
+
+	// 刚刷新过，未知 kid 不会立即再拉 JWKS
+	if _, err := v.Verify(ctx, rotatedToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Verify within min refresh err = %v, want ErrInvalidToken", err)
+	}
+	if stub.calls != 1 {
+		t.Fatalf("jwks fetched %d times, want 1", stub.calls)
+	}
+
+	v.mu.Lock()
+	v.refreshedAt = now.Add(-2 * jwksMinRefresh)
+	v.mu.Unlock()
+	if _, err := v.Verify(ctx, rotatedToken); err != nil {
+		t.Fatalf("Verify after rotation: %v", err)
+	}
+	if stub.calls != 2 {
+		t.Fatalf("jwks fetched %d times, want 2", stub.calls)
+	}
+}
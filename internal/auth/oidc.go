@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JWKS 缓存：定期刷新以获取轮换后的密钥；遇到未知 kid 时立即刷新，但两次刷新至少间隔 jwksMinRefresh
+const (
+	jwksRefreshInterval = time.Hour
+	jwksMinRefresh      = time.Minute
+)
+
+// OIDCConfig OIDC 令牌校验配置
+type OIDCConfig struct {
+	Issuer      string          // 签发方，需与令牌 iss 一致
+	Audience    string          // 需包含在令牌 aud 中，必填
+	JWKSURL     string          // 为空时从 Issuer 的 /.well-known/openid-configuration 获取
+	UserClaim   string          // 映射为 user_id 的声明，默认 sub
+	TenantClaim string          // 映射为租户的声明，为空时不从令牌取租户
+	NameClaim   string          // 映射为用户名（context.user_name）的声明，默认 name
+	RoleClaim   string          // 角色声明（字符串或数组），默认 roles
+	RoleMapping map[string]Role // 声明值到角色的映射，如 {"sayso-admins": admin}
+	Transport   http.RoundTripper
+}
+
+// Identity 令牌中解析出的调用方身份
+type Identity struct {
+	UserID   string
+	TenantID string
+	Name     string
+	Role     Role
+	Claims   map[string]any
+}
+
+// OIDCVerifier 按 OIDC 签发方的 JWKS 校验 JWT
+type OIDCVerifier struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu          sync.Mutex
+	jwksURL     string
+	keys        map[string]crypto.PublicKey
+	refreshedAt time.Time
+}
+
+// NewOIDCVerifier 创建校验器；密钥在首次校验时获取
+func NewOIDCVerifier(cfg OIDCConfig) *OIDCVerifier {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+	if cfg.NameClaim == "" {
+		cfg.NameClaim = "name"
+	}
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = "roles"
+	}
+	return &OIDCVerifier{
+		cfg:     cfg,
+		client:  &http.Client{Transport: cfg.Transport, Timeout: 10 * time.Second},
+		jwksURL: cfg.JWKSURL,
+	}
+}
+
+// Verify 校验签名、签发方、受众与有效期，并按配置映射身份
+func (v *OIDCVerifier) Verify(ctx context.Context, token string) (Identity, error) {
+	header, claims, signed, sig, err := parseJWT(token)
+	if err != nil {
+		return Identity{}, err
+	}
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return Identity{}, err
+	}
+	if err := verifySignature(header.Alg, key, signed, sig); err != nil {
+		return Identity{}, err
+	}
+	if err := validateClaims(claims, v.cfg.Issuer, v.cfg.Audience, time.Now()); err != nil {
+		return Identity{}, err
+	}
+	id := Identity{Claims: claims, Role: roleFromClaim(claims[v.cfg.RoleClaim], v.cfg.RoleMapping)}
+	id.UserID, _ = claims[v.cfg.UserClaim].(string)
+	id.Name, _ = claims[v.cfg.NameClaim].(string)
+	if v.cfg.TenantClaim != "" {
+		id.TenantID, _ = claims[v.cfg.TenantClaim].(string)
+	}
+	if id.UserID == "" {
+		return Identity{}, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, v.cfg.UserClaim)
+	}
+	return id, nil
+}
+
+// key 按 kid 取公钥；缓存过期或 kid 未知时刷新 JWKS
+func (v *OIDCVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	since := time.Since(v.refreshedAt)
+	_, known := v.keys[kid]
+	if v.keys == nil || since > jwksRefreshInterval || (!known && since > jwksMinRefresh) {
+		if err := v.refresh(ctx); err != nil && v.keys == nil {
+			return nil, err
+		}
+	}
+	if kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			return k, nil
+		}
+	}
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key id %q", ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+// refresh 重新获取 JWKS；失败时保留旧密钥
+func (v *OIDCVerifier) refresh(ctx context.Context) error {
+	v.refreshedAt = time.Now()
+	if v.jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, strings.TrimRight(v.cfg.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return fmt.Errorf("oidc discovery: %w", err)
+		}
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("oidc discovery: missing jwks_uri")
+		}
+		v.jwksURL = discovery.JWKSURI
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := v.getJSON(ctx, v.jwksURL, &set); err != nil {
+		return fmt.Errorf("oidc jwks: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		if pub, err := k.publicKey(); err == nil {
+			keys[k.Kid] = pub
+		}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("oidc jwks: no usable signing keys")
+	}
+	v.keys = keys
+	return nil
+}
+
+func (v *OIDCVerifier) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// jwk JSON Web Key（只处理 RSA 与 EC 公钥）
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("ec point not on curve")
+		}
+		return pub, nil
+	default:
+		return nil, fmt.Errorf("unsupported kty %q", k.Kty)
+	}
+}
@@ -0,0 +1,44 @@
+package auth
+
+// Role 调用方角色，权限依次递增
+type Role string
+
+const (
+	RoleCaller   Role = "caller"   // 发起请求，只能查看、确认自己的任务
+	RoleOperator Role = "operator" // 可查看、取消、重试他人的任务
+	RoleAdmin    Role = "admin"    // 可修改运行时配置（归档规则等），可确认他人待确认的动作
+)
+
+var roleRank = map[Role]int{RoleCaller: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// Allows 角色是否具备 required 的权限
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] >= roleRank[required]
+}
+
+// roleFromClaim 从角色声明（字符串或字符串数组）中取权限最高的角色；mapping 把声明值（如用户组名）映射为角色，
+// 未命中映射时声明值本身为角色名才生效，都不匹配时为 caller
+func roleFromClaim(claim any, mapping map[string]Role) Role {
+	var values []string
+	switch v := claim.(type) {
+	case string:
+		values = []string{v}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	role := RoleCaller
+	for _, v := range values {
+		r, ok := mapping[v]
+		if !ok {
+			r = Role(v)
+		}
+		if roleRank[r] > roleRank[role] {
+			role = r
+		}
+	}
+	return role
+}
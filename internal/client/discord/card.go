@@ -0,0 +1,66 @@
+package discord
+
+import (
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// 卡片颜色（与飞书标题栏颜色名对应），未指定时使用 Discord 品牌色
+var embedColors = map[string]int{
+	"blue":   0x3370FF,
+	"green":  0x34C724,
+	"orange": 0xFF8800,
+	"red":    0xF54A45,
+}
+
+const defaultEmbedColor = 0x5865F2
+
+// 按钮行最多 5 个按钮
+const maxButtonsPerRow = 5
+
+// RenderCard 将通用卡片渲染为一个 Embed 与链接按钮：正文合并为 description，字段并排显示，
+// 只渲染第一张带 URL 的图片（Embed 只支持一张大图）
+func RenderCard(card model.Card) ([]Embed, []Component) {
+	embed := Embed{Title: card.Title, Color: defaultEmbedColor}
+	if card.Icon != "" && card.Title != "" {
+		embed.Title = card.Icon + " " + card.Title
+	}
+	if c, ok := embedColors[card.Color]; ok {
+		embed.Color = c
+	}
+	var texts []string
+	for _, s := range card.Sections {
+		if s.Text != "" {
+			texts = append(texts, s.Text)
+		}
+		for _, f := range s.Fields {
+			embed.Fields = append(embed.Fields, EmbedField{Name: f.Name, Value: f.Value, Inline: true})
+		}
+		if s.Image != nil && s.Image.URL != "" && embed.Image == nil {
+			embed.Image = &EmbedImage{URL: s.Image.URL}
+		}
+	}
+	embed.Description = strings.Join(texts, "\n\n")
+
+	var rows []Component
+	var links []model.CardButton
+	for _, b := range card.Buttons {
+		// 回调按钮不渲染
+		if b.URL != "" {
+			links = append(links, b)
+		}
+	}
+	for i, b := range links {
+		if i%maxButtonsPerRow == 0 {
+			rows = append(rows, Component{Type: 1})
+		}
+		row := &rows[len(rows)-1]
+		row.Components = append(row.Components, Component{Type: 2, Style: 5, Label: b.Text, URL: b.URL})
+	}
+	if len(links) == 1 {
+		// 只有一个链接时标题也可点击
+		embed.URL = links[0].URL
+	}
+	return []Embed{embed}, rows
+}
@@ -0,0 +1,184 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Config Discord 客户端配置
+type Config struct {
+	BotToken string
+	Enabled  bool
+	// Guild 默认服务器（名称或 ID），"#general" 等不带服务器的频道名在此服务器中查找；
+	// Bot 只加入了一个服务器时可留空
+	Guild string
+	// MaxMessageChars 单条消息的字符上限，0 使用默认 2000（Embed 消息另限 4096）；
+	// MessageOverflow 超出时的处理：split（默认）| truncate | doc（需启用飞书）
+	MaxMessageChars int
+	MessageOverflow string
+	// APIBase 为空时使用 https://discord.com/api/v10，测试时可指向模拟服务
+	APIBase string
+	// Transport 共享的出站连接池，为 nil 时使用 http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// Client Discord API 客户端（Bot Token 鉴权）
+type Client struct {
+	cfg    Config
+	client *http.Client
+	base   string
+	guilds *guildCache
+}
+
+// NewClient 创建 Discord 客户端
+func NewClient(cfg Config) *Client {
+	base := strings.TrimRight(cfg.APIBase, "/")
+	if base == "" {
+		base = discordAPIBase
+	}
+	return &Client{
+		cfg:    cfg,
+		client: &http.Client{Transport: cfg.Transport},
+		base:   base,
+		guilds: &guildCache{channels: make(map[string]channelList)},
+	}
+}
+
+const discordAPIBase = "https://discord.com/api/v10"
+
+// Message 待发送的消息：Content 为正文，Embeds 为卡片，Components 为链接按钮等组件
+type Message struct {
+	Content    string      `json:"content,omitempty"`
+	Embeds     []Embed     `json:"embeds,omitempty"`
+	Components []Component `json:"components,omitempty"`
+	// Flags 4 为 SUPPRESS_EMBEDS，不展开链接预览
+	Flags int `json:"flags,omitempty"`
+}
+
+// Embed Discord 嵌入卡片
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Image       *EmbedImage  `json:"image,omitempty"`
+}
+
+// EmbedField 嵌入卡片中的字段，Inline 为 true 时并排显示
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// EmbedImage 嵌入卡片中的图片，须为可公开访问的 URL
+type EmbedImage struct {
+	URL string `json:"url"`
+}
+
+// Component 消息组件：Type 1 为按钮行（ActionRow），Type 2 为按钮；Style 5 为链接按钮
+type Component struct {
+	Type       int         `json:"type"`
+	Style      int         `json:"style,omitempty"`
+	Label      string      `json:"label,omitempty"`
+	URL        string      `json:"url,omitempty"`
+	Components []Component `json:"components,omitempty"`
+}
+
+// flagSuppressEmbeds 不展开消息中链接的预览
+const flagSuppressEmbeds = 1 << 2
+
+// SuppressEmbeds 关闭链接预览（Bot 自己附带的 Embeds 不受影响）
+func (m *Message) SuppressEmbeds() {
+	m.Flags |= flagSuppressEmbeds
+}
+
+// SendMessageResult 发送消息结果
+type SendMessageResult struct {
+	ID        string
+	ChannelID string
+}
+
+// SendMessage 发送纯文本消息到频道
+func (c *Client) SendMessage(ctx context.Context, channelID, text string) error {
+	_, err := c.PostMessage(ctx, channelID, Message{Content: text})
+	return err
+}
+
+// PostMessage 发送消息到频道或私聊（POST /channels/{id}/messages）
+func (c *Client) PostMessage(ctx context.Context, channelID string, msg Message) (SendMessageResult, error) {
+	var result struct {
+		ID        string `json:"id"`
+		ChannelID string `json:"channel_id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/channels/"+channelID+"/messages", msg, &result); err != nil {
+		return SendMessageResult{}, fmt.Errorf("discord send message: %w", err)
+	}
+	return SendMessageResult{ID: result.ID, ChannelID: result.ChannelID}, nil
+}
+
+// OpenDM 打开与用户的私聊（POST /users/@me/channels），返回私聊频道 ID；
+// 用户须与 Bot 同在一个服务器且未关闭私信
+func (c *Client) OpenDM(ctx context.Context, userID string) (string, error) {
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/users/@me/channels", map[string]string{"recipient_id": userID}, &result); err != nil {
+		return "", fmt.Errorf("discord open dm: %w", err)
+	}
+	return result.ID, nil
+}
+
+// DeleteMessage 删除 Bot 发出的消息（DELETE /channels/{id}/messages/{message_id}）
+func (c *Client) DeleteMessage(ctx context.Context, channelID, messageID string) error {
+	if err := c.do(ctx, http.MethodDelete, "/channels/"+channelID+"/messages/"+messageID, nil, nil); err != nil {
+		return fmt.Errorf("discord delete message: %w", err)
+	}
+	return nil
+}
+
+// do 调用 Discord REST 接口，body 非 nil 时以 JSON 发送，out 非 nil 时解析响应
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bot "+c.cfg.BotToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(b, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("status %d: %s (code %d)", resp.StatusCode, apiErr.Message, apiErr.Code)
+		}
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	if out == nil || len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
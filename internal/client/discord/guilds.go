@@ -0,0 +1,160 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// guildListTTL 服务器与频道列表的缓存时间
+const guildListTTL = 10 * time.Minute
+
+// 可发送消息的频道类型：文字频道与公告频道
+const (
+	channelTypeText         = 0
+	channelTypeAnnouncement = 5
+)
+
+// guildCache Bot 所在服务器（名称小写 -> ID）及各服务器的频道名（小写）-> 频道 ID
+type guildCache struct {
+	mu       sync.Mutex
+	guilds   map[string]string
+	loadedAt time.Time
+	channels map[string]channelList
+}
+
+type channelList struct {
+	ids      map[string]string
+	loadedAt time.Time
+}
+
+// ParseTarget 拆分 "目标@服务器" 形式的目标，如 "#general@My Server" → ("#general", "My Server")；
+// 提及格式 "<#123>"、"<@123>" 还原为 ID
+func ParseTarget(target string) (id, guild string) {
+	id = strings.TrimSpace(target)
+	if i := strings.LastIndex(id, "@"); i > 0 && !strings.HasPrefix(id, "<@") {
+		id, guild = strings.TrimSpace(id[:i]), strings.TrimSpace(id[i+1:])
+	}
+	if strings.HasPrefix(id, "<") && strings.HasSuffix(id, ">") {
+		id = strings.TrimLeft(id[1:len(id)-1], "#@!")
+	}
+	return id, guild
+}
+
+// IsSnowflake 是否为 Discord ID（纯数字）
+func IsSnowflake(s string) bool {
+	if len(s) < 15 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ChannelID 把 "#general" 解析为频道 ID：guild 为空时使用默认服务器；已是 ID 时原样返回
+func (c *Client) ChannelID(ctx context.Context, channel, guild string) (string, error) {
+	name, ok := strings.CutPrefix(channel, "#")
+	if !ok {
+		return channel, nil
+	}
+	guildID, err := c.GuildID(ctx, guild)
+	if err != nil {
+		return "", err
+	}
+	ids, err := c.Channels(ctx, guildID)
+	if err != nil {
+		return "", err
+	}
+	id, ok := ids[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("discord channel %s not found or not visible to bot", channel)
+	}
+	return id, nil
+}
+
+// GuildID 按名称（不区分大小写）或 ID 查找 Bot 所在的服务器；name 为空时使用配置的默认服务器，
+// 未配置且 Bot 只在一个服务器中时使用该服务器
+func (c *Client) GuildID(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		name = c.cfg.Guild
+	}
+	if IsSnowflake(name) {
+		return name, nil
+	}
+	guilds, err := c.Guilds(ctx)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		if len(guilds) == 1 {
+			for _, id := range guilds {
+				return id, nil
+			}
+		}
+		return "", fmt.Errorf("discord guild is ambiguous: bot is in %d guilds, set discord.guild or write the target as \"#channel@guild\"", len(guilds))
+	}
+	id, ok := guilds[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("discord guild %q not found or bot not a member", name)
+	}
+	return id, nil
+}
+
+// Guilds Bot 所在的服务器名（小写）-> ID，缓存 10 分钟
+func (c *Client) Guilds(ctx context.Context) (map[string]string, error) {
+	c.guilds.mu.Lock()
+	guilds, loadedAt := c.guilds.guilds, c.guilds.loadedAt
+	c.guilds.mu.Unlock()
+	if guilds != nil && time.Since(loadedAt) < guildListTTL {
+		return guilds, nil
+	}
+	var list []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/users/@me/guilds", nil, &list); err != nil {
+		return nil, fmt.Errorf("discord list guilds: %w", err)
+	}
+	guilds = make(map[string]string, len(list))
+	for _, g := range list {
+		guilds[strings.ToLower(g.Name)] = g.ID
+	}
+	c.guilds.mu.Lock()
+	c.guilds.guilds, c.guilds.loadedAt = guilds, time.Now()
+	c.guilds.mu.Unlock()
+	return guilds, nil
+}
+
+// Channels 服务器中可发消息的频道名（小写）-> 频道 ID，缓存 10 分钟
+func (c *Client) Channels(ctx context.Context, guildID string) (map[string]string, error) {
+	c.guilds.mu.Lock()
+	list, ok := c.guilds.channels[guildID]
+	c.guilds.mu.Unlock()
+	if ok && time.Since(list.loadedAt) < guildListTTL {
+		return list.ids, nil
+	}
+	var channels []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Type int    `json:"type"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/guilds/"+guildID+"/channels", nil, &channels); err != nil {
+		return nil, fmt.Errorf("discord list channels: %w", err)
+	}
+	ids := make(map[string]string, len(channels))
+	for _, ch := range channels {
+		if ch.Type == channelTypeText || ch.Type == channelTypeAnnouncement {
+			ids[strings.ToLower(ch.Name)] = ch.ID
+		}
+	}
+	c.guilds.mu.Lock()
+	c.guilds.channels[guildID] = channelList{ids: ids, loadedAt: time.Now()}
+	c.guilds.mu.Unlock()
+	return ids, nil
+}
@@ -0,0 +1,172 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ApprovalInstance 审批实例
+type ApprovalInstance struct {
+	Code         string
+	ApprovalName string
+	Title        string
+	Status       string // PENDING | APPROVED | REJECTED | CANCELED | DELETED
+	StartTime    time.Time
+	URL          string
+}
+
+// ApprovalTask 审批实例中的审批任务（节点）
+type ApprovalTask struct {
+	NodeName string `json:"node_name"`
+	OpenID   string `json:"open_id"`
+	UserID   string `json:"user_id"`
+	Status   string `json:"status"` // PENDING | APPROVED | REJECTED | TRANSFERRED | DONE
+}
+
+// queryApprovalInstancesResp 查询实例列表响应
+type queryApprovalInstancesResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		InstanceList []struct {
+			Instance struct {
+				Code      string `json:"code"`
+				Title     string `json:"title"`
+				Status    string `json:"status"`
+				StartTime string `json:"start_time"` // 毫秒时间戳
+				Link      struct {
+					PCLink string `json:"pc_link"`
+				} `json:"link"`
+			} `json:"instance"`
+			Approval struct {
+				Name string `json:"name"`
+			} `json:"approval"`
+		} `json:"instance_list"`
+	} `json:"data"`
+}
+
+// QueryApprovalInstances 查询用户发起的审批实例，按发起时间筛选；userIDType 为 open_id 或 user_id
+// API: POST /open-apis/approval/v4/instances/query
+func (c *Client) QueryApprovalInstances(ctx context.Context, token, userID, userIDType string, since time.Time, limit int) ([]ApprovalInstance, error) {
+	url := fmt.Sprintf("%s/approval/v4/instances/query?page_size=%d&user_id_type=%s", c.apiBase(), limit, userIDType)
+	data, _ := json.Marshal(map[string]string{
+		"user_id":                  userID,
+		"instance_start_time_from": strconv.FormatInt(since.UnixMilli(), 10),
+		"instance_start_time_to":   strconv.FormatInt(time.Now().UnixMilli(), 10),
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu query approval instances")
+	if err != nil {
+		return nil, err
+	}
+	var result queryApprovalInstancesResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("feishu query approval instances parse response: %w, body: %.500s", err, string(b))
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("feishu query approval instances: code=%d msg=%s", result.Code, result.Msg)
+	}
+	var instances []ApprovalInstance
+	for _, item := range result.Data.InstanceList {
+		inst := ApprovalInstance{
+			Code:         item.Instance.Code,
+			ApprovalName: item.Approval.Name,
+			Title:        item.Instance.Title,
+			Status:       item.Instance.Status,
+			URL:          item.Instance.Link.PCLink,
+		}
+		if ms, err := strconv.ParseInt(item.Instance.StartTime, 10, 64); err == nil {
+			inst.StartTime = time.UnixMilli(ms)
+		}
+		instances = append(instances, inst)
+	}
+	return instances, nil
+}
+
+// approvalInstanceResp 审批实例详情响应
+type approvalInstanceResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		TaskList []ApprovalTask `json:"task_list"`
+	} `json:"data"`
+}
+
+// GetApprovalTasks 获取审批实例的审批任务列表，用于判断当前进行到哪个节点
+// API: GET /open-apis/approval/v4/instances/:instance_id
+func (c *Client) GetApprovalTasks(ctx context.Context, token, instanceCode string) ([]ApprovalTask, error) {
+	url := fmt.Sprintf("%s/approval/v4/instances/%s?user_id_type=open_id", c.apiBase(), instanceCode)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get approval instance")
+	if err != nil {
+		return nil, err
+	}
+	var result approvalInstanceResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("feishu get approval instance parse response: %w, body: %.500s", err, string(b))
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("feishu get approval instance: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.TaskList, nil
+}
+
+// getUserResp 获取单个用户信息响应
+type getUserResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		User struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	} `json:"data"`
+}
+
+// GetUserName 根据 open_id 获取用户名
+// API: GET /open-apis/contact/v3/users/:user_id?user_id_type=open_id
+func (c *Client) GetUserName(ctx context.Context, token, openID string) (string, error) {
+	url := fmt.Sprintf("%s/contact/v3/users/%s?user_id_type=open_id", c.apiBase(), openID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get user")
+	if err != nil {
+		return "", err
+	}
+	var result getUserResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu get user parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu get user: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.User.Name, nil
+}
@@ -0,0 +1,65 @@
+package feishu
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL 目录树、用户查询结果的默认缓存时间
+const defaultCacheTTL = 10 * time.Minute
+
+// ttlCache 带过期时间的查询结果缓存，键前缀为飞书企业（tenant_key，自建应用为空）
+type ttlCache struct {
+	mu    sync.Mutex
+	items map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value    any
+	expireAt time.Time
+}
+
+func newTTLCache() *ttlCache {
+	return &ttlCache{items: make(map[string]cacheEntry)}
+}
+
+func (c *ttlCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok || time.Now().After(e.expireAt) {
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (c *ttlCache) set(key string, value any, ttl time.Duration) {
+	c.mu.Lock()
+	c.items[key] = cacheEntry{value: value, expireAt: time.Now().Add(ttl)}
+	c.mu.Unlock()
+}
+
+// dropPrefix 删除某一类缓存（如新建文件夹后的目录树）
+func (c *ttlCache) dropPrefix(prefix string) {
+	c.mu.Lock()
+	for k := range c.items {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.items, k)
+		}
+	}
+	c.mu.Unlock()
+}
+
+// cacheKey 缓存键：类别|企业|参数
+func cacheKey(ctx context.Context, kind, arg string) string {
+	return kind + "|" + tenantKeyFrom(ctx) + "|" + arg
+}
+
+func (c *Client) cacheTTL() time.Duration {
+	if c.cfg.CacheTTL > 0 {
+		return c.cfg.CacheTTL
+	}
+	return defaultCacheTTL
+}
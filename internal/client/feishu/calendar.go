@@ -0,0 +1,209 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// TimeRange 时间段 [Start, End)
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// CalendarEvent 要创建的日程
+type CalendarEvent struct {
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+// primaryCalendarResp 主日历响应
+type primaryCalendarResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Calendars []struct {
+			Calendar struct {
+				CalendarID string `json:"calendar_id"`
+			} `json:"calendar"`
+		} `json:"calendars"`
+	} `json:"data"`
+}
+
+// PrimaryCalendarID 获取应用（机器人）的主日历 ID，日程以应用身份创建
+// API: POST /open-apis/calendar/v4/calendars/primary
+func (c *Client) PrimaryCalendarID(ctx context.Context, token string) (string, error) {
+	url := c.apiBase() + "/calendar/v4/calendars/primary"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu primary calendar")
+	if err != nil {
+		return "", err
+	}
+	var result primaryCalendarResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu primary calendar parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu primary calendar: code=%d msg=%s", result.Code, result.Msg)
+	}
+	if len(result.Data.Calendars) == 0 || result.Data.Calendars[0].Calendar.CalendarID == "" {
+		return "", fmt.Errorf("feishu primary calendar: empty calendar id")
+	}
+	return result.Data.Calendars[0].Calendar.CalendarID, nil
+}
+
+// freeBusyResp 忙闲查询响应
+type freeBusyResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		FreebusyList []struct {
+			StartTime string `json:"start_time"` // RFC 3339
+			EndTime   string `json:"end_time"`
+		} `json:"freebusy_list"`
+	} `json:"data"`
+}
+
+// FreeBusy 查询用户在时间范围内的忙碌时段；userIDType 为 open_id 或 user_id
+// API: POST /open-apis/calendar/v4/freebusy/list
+func (c *Client) FreeBusy(ctx context.Context, token, userID, userIDType string, from, to time.Time) ([]TimeRange, error) {
+	url := fmt.Sprintf("%s/calendar/v4/freebusy/list?user_id_type=%s", c.apiBase(), userIDType)
+	data, _ := json.Marshal(map[string]string{
+		"time_min": from.Format(time.RFC3339),
+		"time_max": to.Format(time.RFC3339),
+		"user_id":  userID,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu freebusy")
+	if err != nil {
+		return nil, err
+	}
+	var result freeBusyResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("feishu freebusy parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("feishu freebusy: code=%d msg=%s", result.Code, result.Msg)
+	}
+	var busy []TimeRange
+	for _, fb := range result.Data.FreebusyList {
+		start, err1 := time.Parse(time.RFC3339, fb.StartTime)
+		end, err2 := time.Parse(time.RFC3339, fb.EndTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		busy = append(busy, TimeRange{Start: start, End: end})
+	}
+	return busy, nil
+}
+
+// createEventResp 创建日程响应
+type createEventResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Event struct {
+			EventID string `json:"event_id"`
+			AppLink string `json:"app_link"`
+		} `json:"event"`
+	} `json:"data"`
+}
+
+// CreateEvent 在日历上创建日程（附带视频会议），返回日程 ID 与日程链接
+// API: POST /open-apis/calendar/v4/calendars/:calendar_id/events
+func (c *Client) CreateEvent(ctx context.Context, token, calendarID string, event CalendarEvent) (string, string, error) {
+	url := fmt.Sprintf("%s/calendar/v4/calendars/%s/events", c.apiBase(), calendarID)
+	data, _ := json.Marshal(map[string]any{
+		"summary":          event.Summary,
+		"description":      event.Description,
+		"start_time":       map[string]string{"timestamp": strconv.FormatInt(event.Start.Unix(), 10)},
+		"end_time":         map[string]string{"timestamp": strconv.FormatInt(event.End.Unix(), 10)},
+		"vchat":            map[string]string{"vc_type": "vc"},
+		"attendee_ability": "can_modify_event",
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu create event")
+	if err != nil {
+		return "", "", err
+	}
+	var result createEventResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", "", fmt.Errorf("feishu create event parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", "", fmt.Errorf("feishu create event: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.Event.EventID, result.Data.Event.AppLink, nil
+}
+
+// AddEventAttendees 为日程添加参与人并发送通知；userIDType 为 open_id 或 user_id
+// API: POST /open-apis/calendar/v4/calendars/:calendar_id/events/:event_id/attendees
+func (c *Client) AddEventAttendees(ctx context.Context, token, calendarID, eventID, userIDType string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	url := fmt.Sprintf("%s/calendar/v4/calendars/%s/events/%s/attendees?user_id_type=%s", c.apiBase(), calendarID, eventID, userIDType)
+	attendees := make([]map[string]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		attendees = append(attendees, map[string]string{"type": "user", "user_id": id})
+	}
+	data, _ := json.Marshal(map[string]any{"attendees": attendees, "need_notification": true})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu add event attendees")
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("feishu add event attendees parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu add event attendees: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return nil
+}
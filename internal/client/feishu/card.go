@@ -0,0 +1,79 @@
+package feishu
+
+import (
+	"encoding/json"
+
+	"sayso-agent/internal/model"
+)
+
+// RenderCard 将通用卡片渲染为飞书交互式卡片（msg_type=interactive）的 content
+func RenderCard(card model.Card) string {
+	elements := []any{}
+	for _, s := range card.Sections {
+		if s.Text != "" {
+			tag := "plain_text"
+			if s.Markdown {
+				tag = "lark_md"
+			}
+			elements = append(elements, map[string]any{
+				"tag":  "div",
+				"text": map[string]any{"tag": tag, "content": s.Text},
+			})
+		}
+		if len(s.Fields) > 0 {
+			var fields []any
+			for _, f := range s.Fields {
+				fields = append(fields, map[string]any{
+					"is_short": true,
+					"text":     map[string]any{"tag": "lark_md", "content": "**" + f.Name + "**\n" + f.Value},
+				})
+			}
+			elements = append(elements, map[string]any{"tag": "div", "fields": fields})
+		}
+		if s.Image != nil && s.Image.Key != "" {
+			elements = append(elements, map[string]any{
+				"tag":     "img",
+				"img_key": s.Image.Key,
+				"alt":     map[string]any{"tag": "plain_text", "content": s.Image.Alt},
+			})
+		}
+	}
+	if len(card.Buttons) > 0 {
+		var actions []any
+		for _, b := range card.Buttons {
+			typ := "default"
+			if b.Primary {
+				typ = "primary"
+			}
+			button := map[string]any{
+				"tag":  "button",
+				"text": map[string]any{"tag": "plain_text", "content": b.Text},
+				"type": typ,
+			}
+			if len(b.Value) > 0 {
+				button["value"] = b.Value
+			} else {
+				button["url"] = b.URL
+			}
+			actions = append(actions, button)
+		}
+		elements = append(elements, map[string]any{"tag": "action", "actions": actions})
+	}
+
+	title := card.Title
+	if card.Icon != "" {
+		title = card.Icon + " " + title
+	}
+	header := map[string]any{
+		"title": map[string]any{"tag": "plain_text", "content": title},
+	}
+	if card.Color != "" {
+		header["template"] = card.Color
+	}
+	b, _ := json.Marshal(map[string]any{
+		"config":   map[string]any{"wide_screen_mode": true},
+		"header":   header,
+		"elements": elements,
+	})
+	return string(b)
+}
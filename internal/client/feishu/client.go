@@ -8,6 +8,11 @@ import (
 	"io"
 	"net/http"
 	"sayso-agent/internal/model"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Config 飞书客户端配置
@@ -15,25 +20,76 @@ type Config struct {
 	AppID     string
 	AppSecret string
 	BotToken  string
-	Domain    string // 飞书域名，如 example.feishu.cn，用于生成文档链接
-	Enabled   bool
+	Domain    string // 飞书域名，如 example.feishu.cn（Lark 为 example.larksuite.com），用于生成文档链接
+	// Region 开放平台区域：feishu | lark | auto（默认，按 Domain 或应用凭证自动判断）
+	Region string
+	// 私有化部署：APIBase 为网关上的开放接口前缀（如 https://open.feishu.example.com/open-apis），设置后忽略 Region；
+	// AuthPath 为获取 tenant_access_token 的路径，默认 /auth/v3/tenant_access_token/internal；
+	// DocURLTemplate 为文档链接格式，支持 {{domain}} {{path}} {{token}}，默认 https://{{domain}}/{{path}}/{{token}}；
+	// Headers 为网关要求的附加请求头，随每个请求发送
+	APIBase        string
+	AuthPath       string
+	DocURLTemplate string
+	Headers        map[string]string
+	Enabled        bool
+	// TitleTemplate 新建文档的标题模板，如 "{{date}} {{title}} - {{author}}"，为空时直接使用标题
+	TitleTemplate string
+	// DuplicatePolicy 目标目录中已有近期同名文档时的处理：ask（默认）| reuse | append | new
+	DuplicatePolicy string
+	// ClarifyCollaborators 建文档时有协作者找不到对应用户，先请用户确认再创建（否则直接创建并在结果中列出）
+	ClarifyCollaborators bool
+	// StrictRecipients 严格模式：消息只发给请求 Contacts 中的联系人、联系人分组或通讯录中姓名完全一致的用户，
+	// 拒绝其他目标（含大模型给出的不在 Contacts 中的 ID）
+	StrictRecipients bool
+	// Marketplace 商店应用：按请求租户对应的飞书企业（tenant_key）换取 tenant_access_token，见 marketplace.go
+	Marketplace bool
+	// MaxMessageChars 单条消息的字符上限，0 使用默认 10000；MessageOverflow 超出时的处理：split（默认）| truncate | doc
+	MaxMessageChars int
+	MessageOverflow string
+	// CacheTTL 目录树、按姓名查询用户的结果缓存时间，0 使用默认 10 分钟；tenant_access_token 按有效期缓存
+	CacheTTL time.Duration
+	// Tables 可供 query_table 查询的数据表
+	Tables []TableSource
+	// FolderTemplates 目录结构模板：模板名 -> 目录路径（如 "设计/原型"），供 feishu_create_folder_tree 使用
+	FolderTemplates map[string][]string
+	// HelpdeskID、HelpdeskToken 服务台凭证，供 feishu_create_ticket 使用，为空时不支持建工单
+	HelpdeskID    string
+	HelpdeskToken string
+	// Transport 共享的出站连接池，为 nil 时使用 http.DefaultTransport
+	Transport http.RoundTripper
 }
 
 // Client 飞书 API 客户端（含机器人/应用能力）
 type Client struct {
 	cfg    Config
 	client *http.Client
+
+	mu        sync.RWMutex
+	appSecret string
+	base      string // 开放接口地址前缀，为空表示区域待探测
+
+	market marketplace
+	cache  *ttlCache
 }
 
 // NewClient 创建飞书客户端
 func NewClient(cfg Config) *Client {
 	return &Client{
-		cfg:    cfg,
-		client: &http.Client{},
+		cfg:       cfg,
+		client:    &http.Client{Transport: withHeaders(cfg.Transport, cfg.Headers)},
+		appSecret: cfg.AppSecret,
+		base:      initBase(cfg),
+		market:    marketplace{tenants: make(map[string]string)},
+		cache:     newTTLCache(),
 	}
 }
 
-const feishuAPIBase = "https://open.feishu.cn/open-apis"
+// SetAppSecret 替换应用密钥（密钥轮换），下次获取 tenant_access_token 时生效
+func (c *Client) SetAppSecret(secret string) {
+	c.mu.Lock()
+	c.appSecret = secret
+	c.mu.Unlock()
+}
 
 // checkHTTPStatus 读取 body 并检查 HTTP 状态码；非 2xx 时直接返回错误（不解析 JSON），
 // 避免网关/404 返回纯文本（如 "404 page not found"）时出现 "invalid character 'p' after top-level value"。
@@ -58,35 +114,73 @@ type tenantAccessTokenResp struct {
 	Expire            int    `json:"expire"`
 }
 
-// GetTenantAccessToken 获取 tenant_access_token（应用维度）
+// GetTenantAccessToken 获取 tenant_access_token（应用维度）；区域为 auto 且尚未确定时先试飞书，
+// 失败再试 Lark，以换取成功的一方作为之后所有接口的域名。商店应用换取 ctx 中飞书企业的 token（见 WithTenant）
 func (c *Client) GetTenantAccessToken(ctx context.Context) (string, error) {
-	url := feishuAPIBase + "/auth/v3/tenant_access_token/internal"
+	key := cacheKey(ctx, "token", "")
+	if v, ok := c.cache.get(key); ok {
+		return v.(string), nil
+	}
+	token, expire, err := c.fetchTenantAccessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	// 提前 5 分钟过期，避免使用即将失效的 token
+	if ttl := time.Duration(expire)*time.Second - 5*time.Minute; ttl > 0 {
+		c.cache.set(key, token, ttl)
+	}
+	return token, nil
+}
+
+func (c *Client) fetchTenantAccessToken(ctx context.Context) (string, int, error) {
+	if c.cfg.Marketplace {
+		return c.marketplaceTenantToken(ctx)
+	}
+	if base, ok := c.resolvedBase(); ok {
+		return c.tenantAccessToken(ctx, base)
+	}
+	token, expire, err := c.tenantAccessToken(ctx, feishuAPIBase)
+	if err == nil {
+		c.setBase(feishuAPIBase)
+		return token, expire, nil
+	}
+	if larkToken, larkExpire, larkErr := c.tenantAccessToken(ctx, larkAPIBase); larkErr == nil {
+		c.setBase(larkAPIBase)
+		return larkToken, larkExpire, nil
+	}
+	return "", 0, err
+}
+
+func (c *Client) tenantAccessToken(ctx context.Context, base string) (string, int, error) {
+	url := base + c.authPath()
+	c.mu.RLock()
 	body := map[string]string{
 		"app_id":     c.cfg.AppID,
-		"app_secret": c.cfg.AppSecret,
+		"app_secret": c.appSecret,
 	}
+	c.mu.RUnlock()
 	data, _ := json.Marshal(body)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	b, err := c.checkHTTPStatus(resp, "feishu auth")
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	var result tenantAccessTokenResp
 	if err := json.Unmarshal(b, &result); err != nil {
-		return "", fmt.Errorf("feishu auth parse response: %w, body: %s", err, string(b))
+		return "", 0, fmt.Errorf("feishu auth parse response: %w, body: %s", err, string(b))
 	}
 	if result.Code != 0 {
-		return "", fmt.Errorf("feishu auth: code=%d msg=%s", result.Code, result.Msg)
+		return "", 0, fmt.Errorf("feishu auth: code=%d msg=%s", result.Code, result.Msg)
 	}
-	return result.TenantAccessToken, nil
+	return result.TenantAccessToken, result.Expire, nil
 }
 
 // docx v1 创建文档接口响应：https://open.feishu.cn/document/server-docs/docs/docs/docx-v1/document/create
@@ -106,7 +200,7 @@ type docxCreateDocumentResp struct {
 // CreateDoc 创建云文档（docx v1：POST /open-apis/docx/v1/documents）
 // 请求体仅 folder_token、title；返回新文档的 document_id，后续写入正文需调 docx 文档内容接口。
 func (c *Client) CreateDoc(ctx context.Context, token, folderToken, title, content string) (string, error) {
-	url := feishuAPIBase + "/docx/v1/documents"
+	url := c.apiBase() + "/docx/v1/documents"
 	reqBody := map[string]string{
 		"folder_token": folderToken,
 		"title":        title,
@@ -152,7 +246,7 @@ type driveCreateFolderResp struct {
 // API: POST /open-apis/drive/v1/folder/create_folder
 // 请求体：name（文件夹名称）、folder_token（父文件夹 token，不传则在根目录下创建需按文档确认是否必填）
 func (c *Client) CreateFolder(ctx context.Context, accessToken, parentFolderToken, name string) (string, error) {
-	url := feishuAPIBase + "/drive/v1/files/create_folder"
+	url := c.apiBase() + "/drive/v1/files/create_folder"
 	reqBody := map[string]string{
 		"name":         name,
 		"folder_token": parentFolderToken,
@@ -179,6 +273,7 @@ func (c *Client) CreateFolder(ctx context.Context, accessToken, parentFolderToke
 	if result.Code != 0 {
 		return "", fmt.Errorf("feishu create folder: code=%d msg=%s body=%s", result.Code, result.Msg, string(b))
 	}
+	c.cache.dropPrefix(cacheKey(ctx, "folders", ""))
 	return result.Data.Token, nil
 }
 
@@ -206,7 +301,7 @@ type addPermissionMemberResp struct {
 // API: POST /open-apis/drive/v1/permissions/{token}/members?type={type}
 // docType: docx, sheet, bitable, file 等
 func (c *Client) AddCollaborator(ctx context.Context, accessToken, docToken, docType string, collaborator Collaborator) error {
-	url := fmt.Sprintf("%s/drive/v1/permissions/%s/members?type=%s&need_notification=true", feishuAPIBase, docToken, docType)
+	url := fmt.Sprintf("%s/drive/v1/permissions/%s/members?type=%s&need_notification=true", c.apiBase(), docToken, docType)
 	reqBody := map[string]string{
 		"member_type": collaborator.MemberType,
 		"member_id":   collaborator.MemberID,
@@ -261,7 +356,7 @@ type searchUserResp struct {
 // API: POST /open-apis/directory/v1/employee/search
 // 文档: https://open.feishu.cn/document/directory-v1/employee/search
 func (c *Client) SearchUser(ctx context.Context, accessToken, query string) ([]UserInfo, error) {
-	url := feishuAPIBase + "/directory/v1/employees/search?page_size=20"
+	url := c.apiBase() + "/directory/v1/employees/search?page_size=20"
 	reqBody := map[string]string{
 		"query": query,
 	}
@@ -301,8 +396,17 @@ func (c *Client) SearchUser(ctx context.Context, accessToken, query string) ([]U
 	return users, nil
 }
 
-// SearchUserByName 根据名字搜索用户，返回最匹配的一个
+// SearchUserByName 根据名字搜索用户，返回最匹配的一个；找到的结果按 CacheTTL 缓存
 func (c *Client) SearchUserByName(ctx context.Context, accessToken, name string) (*UserInfo, error) {
+	if v, ok := c.cache.get(cacheKey(ctx, "user", name)); ok {
+		u := v.(UserInfo)
+		return &u, nil
+	}
+	return c.RefreshUserByName(ctx, accessToken, name)
+}
+
+// RefreshUserByName 跳过缓存重新查询用户并更新缓存（后台维护任务定期调用）
+func (c *Client) RefreshUserByName(ctx context.Context, accessToken, name string) (*UserInfo, error) {
 	users, err := c.SearchUser(ctx, accessToken, name)
 	if err != nil {
 		return nil, err
@@ -310,22 +414,26 @@ func (c *Client) SearchUserByName(ctx context.Context, accessToken, name string)
 	if len(users) == 0 {
 		return nil, fmt.Errorf("user not found: %s", name)
 	}
-	// 优先返回名字完全匹配的
+	// 优先返回名字完全匹配的，否则返回第一个结果
+	user := users[0]
 	for _, u := range users {
 		if u.Name == name {
-			return &u, nil
+			user = u
+			break
 		}
 	}
-	// 否则返回第一个结果
-	return &users[0], nil
+	c.cache.set(cacheKey(ctx, "user", name), user, c.cacheTTL())
+	return &user, nil
 }
 
 // FolderInfo 文件夹/文件信息
 type FolderInfo struct {
-	Token       string `json:"token"`
-	Name        string `json:"name"`
-	Type        string `json:"type"`         // folder, docx, sheet, bitable, etc.
-	ParentToken string `json:"parent_token"` // 父目录 token
+	Token       string    `json:"token"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`         // folder, docx, sheet, bitable, etc.
+	ParentToken string    `json:"parent_token"` // 父目录 token
+	URL         string    `json:"url,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
 }
 
 // rootFolderMetaResp 根目录元信息响应
@@ -342,7 +450,7 @@ type rootFolderMetaResp struct {
 // GetRootFolderToken 获取用户云空间根目录 token
 // API: GET /open-apis/drive/explorer/v2/root_folder/meta
 func (c *Client) GetRootFolderToken(ctx context.Context, token string) (string, error) {
-	url := feishuAPIBase + "/drive/explorer/v2/root_folder/meta"
+	url := c.apiBase() + "/drive/explorer/v2/root_folder/meta"
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return "", err
@@ -376,6 +484,8 @@ type listFilesResp struct {
 			Name        string `json:"name"`
 			Type        string `json:"type"`
 			ParentToken string `json:"parent_token"`
+			URL         string `json:"url"`
+			CreatedTime string `json:"created_time"` // 秒级时间戳
 		} `json:"files"`
 		NextPageToken string `json:"next_page_token"`
 		HasMore       bool   `json:"has_more"`
@@ -385,7 +495,7 @@ type listFilesResp struct {
 // ListFolderChildren 列出指定目录下的子文件/文件夹
 // API: GET /open-apis/drive/v1/files?folder_token=xxx
 func (c *Client) ListFolderChildren(ctx context.Context, token, folderToken string) ([]FolderInfo, error) {
-	url := feishuAPIBase + "/drive/v1/files?folder_token=" + folderToken
+	url := c.apiBase() + "/drive/v1/files?folder_token=" + folderToken
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -408,18 +518,40 @@ func (c *Client) ListFolderChildren(ctx context.Context, token, folderToken stri
 	}
 	var folders []FolderInfo
 	for _, f := range result.Data.Files {
-		folders = append(folders, FolderInfo{
+		info := FolderInfo{
 			Token:       f.Token,
 			Name:        f.Name,
 			Type:        f.Type,
 			ParentToken: f.ParentToken,
-		})
+			URL:         f.URL,
+		}
+		if sec, err := strconv.ParseInt(f.CreatedTime, 10, 64); err == nil {
+			info.CreatedAt = time.Unix(sec, 0)
+		}
+		folders = append(folders, info)
 	}
 	return folders, nil
 }
 
-// GetFolderTree 递归获取目录树（只返回 folder 类型，限制深度）
+// GetFolderTree 递归获取目录树（只返回 folder 类型，限制深度），结果按 CacheTTL 缓存，新建文件夹后失效
 func (c *Client) GetFolderTree(ctx context.Context, token string, maxDepth int) ([]FolderInfo, error) {
+	if v, ok := c.cache.get(cacheKey(ctx, "folders", strconv.Itoa(maxDepth))); ok {
+		return v.([]FolderInfo), nil
+	}
+	return c.RefreshFolderTree(ctx, token, maxDepth)
+}
+
+// RefreshFolderTree 跳过缓存重新获取目录树并更新缓存（后台维护任务定期调用），请求期间其他调用仍读旧缓存
+func (c *Client) RefreshFolderTree(ctx context.Context, token string, maxDepth int) ([]FolderInfo, error) {
+	folders, err := c.folderTree(ctx, token, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.set(cacheKey(ctx, "folders", strconv.Itoa(maxDepth)), folders, c.cacheTTL())
+	return folders, nil
+}
+
+func (c *Client) folderTree(ctx context.Context, token string, maxDepth int) ([]FolderInfo, error) {
 	rootToken, err := c.GetRootFolderToken(ctx, token)
 	if err != nil {
 		return nil, err
@@ -453,6 +585,35 @@ func (c *Client) collectFolders(ctx context.Context, token, folderToken string,
 	}
 }
 
+// FindFilesByName 在应用云空间中按名称查找文件（不含文件夹），名称包含 name 即命中，限制目录深度
+func (c *Client) FindFilesByName(ctx context.Context, token, name string, maxDepth int) ([]FolderInfo, error) {
+	rootToken, err := c.GetRootFolderToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	var found []FolderInfo
+	c.collectFiles(ctx, token, rootToken, name, 1, maxDepth, &found)
+	return found, nil
+}
+
+// collectFiles 递归收集名称匹配的文件
+func (c *Client) collectFiles(ctx context.Context, token, folderToken, name string, depth, maxDepth int, result *[]FolderInfo) {
+	if depth > maxDepth {
+		return
+	}
+	children, err := c.ListFolderChildren(ctx, token, folderToken)
+	if err != nil {
+		return
+	}
+	for _, child := range children {
+		if child.Type == "folder" {
+			c.collectFiles(ctx, token, child.Token, name, depth+1, maxDepth, result)
+		} else if strings.Contains(child.Name, name) {
+			*result = append(*result, child)
+		}
+	}
+}
+
 // 发送消息接口响应：https://open.feishu.cn/document/server-docs/docs/im-v1/message/create
 type sendMessageResp struct {
 	Code int    `json:"code"`
@@ -479,14 +640,14 @@ type sendMessageResp struct {
 }
 
 // SendIM 发送私聊消息（通过机器人或应用）
-// 若 content 中含 http/https 链接，会以 post 富文本发送，使链接可点击；否则以 text 发送
+// 若 content 中含 http/https 链接，会以 post 富文本发送，使每个链接都可点击；否则以 text 发送
 func (c *Client) SendIM(ctx context.Context, token, receiveIDType, receiveID, content string) error {
-	url := feishuAPIBase + "/im/v1/messages"
+	url := c.apiBase() + "/im/v1/messages"
 	params := "?receive_id_type=" + receiveIDType
 	var contentStr string
-	if linkURL := extractFirstURL(content); linkURL != "" {
+	if HasURL(content) {
 		// 使用 post 富文本，链接可点击
-		contentStr = buildPostContentWithLink(content, linkURL)
+		contentStr = BuildPostContent("", content, "")
 		reqBody := map[string]any{
 			"receive_id": receiveID,
 			"msg_type":   "post",
@@ -532,67 +693,6 @@ func (c *Client) sendIMRequest(ctx context.Context, token, fullURL string, data
 	return nil
 }
 
-// extractFirstURL 从文本中提取第一个 http(s) URL
-func extractFirstURL(s string) string {
-	const https = "https://"
-	const http = "http://"
-	rest := s
-	for {
-		i1 := bytes.Index([]byte(rest), []byte(https))
-		i2 := bytes.Index([]byte(rest), []byte(http))
-		start := -1
-		if i1 >= 0 && (i2 < 0 || i1 <= i2) {
-			start = i1
-		} else if i2 >= 0 {
-			start = i2
-		}
-		if start < 0 {
-			return ""
-		}
-		idx := start
-		if idx+len(https) <= len(rest) && rest[idx:idx+len(https)] == https {
-			idx += len(https)
-		} else if idx+len(http) <= len(rest) && rest[idx:idx+len(http)] == http {
-			idx += len(http)
-		} else {
-			rest = rest[start+1:]
-			continue
-		}
-		for idx < len(rest) && isURLChar(rest[idx]) {
-			idx++
-		}
-		return rest[start:idx]
-	}
-}
-
-func isURLChar(b byte) bool {
-	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9') ||
-		b == '.' || b == '-' || b == '_' || b == '~' || b == ':' || b == '/' || b == '?' || b == '#' || b == '[' || b == ']' || b == '@' || b == '!'
-}
-
-// buildPostContentWithLink 构建飞书 post 富文本 content（zh_cn），一段：正文 + 可点击链接 + 链接后文字
-// 飞书 post 格式：{"zh_cn":{"content":[[{"tag":"text","text":"..."},{"tag":"a","text":"显示文字","href":"url"},{"tag":"text","text":"..."}]]}}
-func buildPostContentWithLink(fullText, linkURL string) string {
-	idx := bytes.Index([]byte(fullText), []byte(linkURL))
-	textBefore := fullText
-	textAfter := ""
-	if idx >= 0 {
-		textBefore = fullText[:idx]
-		if idx+len(linkURL) <= len(fullText) {
-			textAfter = fullText[idx+len(linkURL):]
-		}
-	}
-	paragraph := []any{
-		map[string]string{"tag": "text", "text": textBefore},
-		map[string]string{"tag": "a", "text": linkURL, "href": linkURL},
-		map[string]string{"tag": "text", "text": textAfter},
-	}
-	zhCN := map[string]any{"content": [][]any{paragraph}}
-	root := map[string]any{"zh_cn": zhCN}
-	b, _ := json.Marshal(root)
-	return string(b)
-}
-
 // SendMessageRequest 统一发送消息请求参数
 type SendMessageRequest struct {
 	ReceiveID     string // 接收者 ID
@@ -604,12 +704,13 @@ type SendMessageRequest struct {
 // SendMessageResult 发送消息结果
 type SendMessageResult struct {
 	MessageID string
+	ChatID    string
 	Error     error
 }
 
 // SendMessage 发送消息（统一入口，支持私聊和群聊）
 func (c *Client) SendMessage(ctx context.Context, token string, req SendMessageRequest) SendMessageResult {
-	url := feishuAPIBase + "/im/v1/messages?receive_id_type=" + req.ReceiveIDType
+	url := c.apiBase() + "/im/v1/messages?receive_id_type=" + req.ReceiveIDType
 	reqBody := map[string]any{
 		"receive_id": req.ReceiveID,
 		"msg_type":   req.MsgType,
@@ -637,11 +738,12 @@ func (c *Client) SendMessage(ctx context.Context, token string, req SendMessageR
 	if result.Code != 0 {
 		return SendMessageResult{Error: fmt.Errorf("feishu send message: code=%d msg=%s body=%s", result.Code, result.Msg, string(b))}
 	}
-	msgID := ""
+	var out SendMessageResult
 	if result.Data != nil {
-		msgID = result.Data.MessageID
+		out.MessageID = result.Data.MessageID
+		out.ChatID = result.Data.ChatID
 	}
-	return SendMessageResult{MessageID: msgID}
+	return out
 }
 
 // BuildTextContent 构建纯文本消息内容
@@ -650,19 +752,38 @@ func BuildTextContent(text string) string {
 	return string(content)
 }
 
-// BuildPostContent 构建富文本消息内容（带可点击链接）
+// BuildFileContent 构建文件消息内容，fileKey 由 UploadIMFile 返回
+func BuildFileContent(fileKey string) string {
+	content, _ := json.Marshal(map[string]string{"file_key": fileKey})
+	return string(content)
+}
+
+// BuildAudioContent 构建语音消息内容（msg_type 为 audio），fileKey 由 UploadIMAudio 返回
+func BuildAudioContent(fileKey string) string {
+	return BuildFileContent(fileKey)
+}
+
+// BuildImageContent 构建图片消息内容，imageKey 由 UploadIMImage 返回
+func BuildImageContent(imageKey string) string {
+	content, _ := json.Marshal(map[string]string{"image_key": imageKey})
+	return string(content)
+}
+
+// BuildPostContent 构建富文本消息内容：正文每行一段，链接逐个渲染为可点击的链接，**粗体**、*斜体* 转为对应样式；
+// linkURL 不在正文中时附在最后一段末尾
 func BuildPostContent(title, text, linkURL string) string {
-	var paragraph []any
-	if text != "" {
-		paragraph = append(paragraph, map[string]string{"tag": "text", "text": text})
-	}
-	if linkURL != "" {
-		paragraph = append(paragraph, map[string]string{"tag": "a", "text": linkURL, "href": linkURL})
+	paragraphs := postParagraphs(text)
+	if linkURL != "" && !slices.Contains(postLinks(paragraphs), linkURL) {
+		link := map[string]any{"tag": "a", "text": linkURL, "href": linkURL}
+		if len(paragraphs) == 0 {
+			paragraphs = append(paragraphs, nil)
+		}
+		paragraphs[len(paragraphs)-1] = append(paragraphs[len(paragraphs)-1], link)
 	}
 	post := map[string]any{
 		"zh_cn": map[string]any{
 			"title":   title,
-			"content": [][]any{paragraph},
+			"content": paragraphs,
 		},
 	}
 	b, _ := json.Marshal(post)
@@ -671,53 +792,5 @@ func BuildPostContent(title, text, linkURL string) string {
 
 // BuildInteractiveCard 构建交互式卡片消息内容（链接卡片）
 func BuildInteractiveCard(title, text, linkURL, description string) string {
-	elements := []any{}
-	if text != "" {
-		elements = append(elements, map[string]any{
-			"tag": "div",
-			"text": map[string]any{
-				"tag":     "plain_text",
-				"content": text,
-			},
-		})
-	}
-	if description != "" {
-		elements = append(elements, map[string]any{
-			"tag": "div",
-			"text": map[string]any{
-				"tag":     "plain_text",
-				"content": description,
-			},
-		})
-	}
-	if linkURL != "" {
-		elements = append(elements, map[string]any{
-			"tag": "action",
-			"actions": []any{
-				map[string]any{
-					"tag": "button",
-					"text": map[string]any{
-						"tag":     "plain_text",
-						"content": "查看链接",
-					},
-					"type": "primary",
-					"url":  linkURL,
-				},
-			},
-		})
-	}
-	card := map[string]any{
-		"config": map[string]any{
-			"wide_screen_mode": true,
-		},
-		"header": map[string]any{
-			"title": map[string]any{
-				"tag":     "plain_text",
-				"content": title,
-			},
-		},
-		"elements": elements,
-	}
-	b, _ := json.Marshal(card)
-	return string(b)
+	return RenderCard(model.LinkCard(title, text, linkURL, description))
 }
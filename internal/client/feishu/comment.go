@@ -0,0 +1,60 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// createCommentResp 创建评论响应
+type createCommentResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		CommentID string `json:"comment_id"`
+	} `json:"data"`
+}
+
+// CreateComment 在云文档上添加全文评论，返回评论 ID
+// 开放平台只支持创建全文评论，划词评论需由调用方在内容中引用原文
+// API: POST /open-apis/drive/v1/files/:file_token/comments?file_type=docx
+func (c *Client) CreateComment(ctx context.Context, token, fileToken, fileType, content string) (string, error) {
+	url := fmt.Sprintf("%s/drive/v1/files/%s/comments?file_type=%s", c.apiBase(), fileToken, fileType)
+	body := map[string]any{
+		"reply_list": map[string]any{
+			"replies": []any{map[string]any{
+				"content": map[string]any{
+					"elements": []any{map[string]any{
+						"type":     "text_run",
+						"text_run": map[string]string{"text": content},
+					}},
+				},
+			}},
+		},
+	}
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu create comment")
+	if err != nil {
+		return "", err
+	}
+	var result createCommentResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu create comment parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu create comment: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.CommentID, nil
+}
@@ -0,0 +1,145 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Employee 通讯录中的一名员工
+type Employee struct {
+	OpenID   string
+	Name     string
+	EnName   string
+	Nickname string
+	Email    string // 企业邮箱优先
+	Mobile   string
+	Resigned bool // 已离职或已冻结
+	Title    string
+	Type     int // 人员类型：1 正式、2 实习、3 外包、4 劳务、5 顾问
+}
+
+// listDepartmentsResp 子部门列表响应
+type listDepartmentsResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Items []struct {
+			OpenDepartmentID string `json:"open_department_id"`
+		} `json:"items"`
+		PageToken string `json:"page_token"`
+		HasMore   bool   `json:"has_more"`
+	} `json:"data"`
+}
+
+// listUsersResp 部门直属员工列表响应
+type listUsersResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Items []struct {
+			OpenID          string `json:"open_id"`
+			Name            string `json:"name"`
+			EnName          string `json:"en_name"`
+			Nickname        string `json:"nickname"`
+			Email           string `json:"email"`
+			EnterpriseEmail string `json:"enterprise_email"`
+			Mobile          string `json:"mobile"`
+			JobTitle        string `json:"job_title"`
+			EmployeeType    int    `json:"employee_type"`
+			Status          struct {
+				IsResigned bool `json:"is_resigned"`
+				IsFrozen   bool `json:"is_frozen"`
+			} `json:"status"`
+		} `json:"items"`
+		PageToken string `json:"page_token"`
+		HasMore   bool   `json:"has_more"`
+	} `json:"data"`
+}
+
+// ListEmployees 拉取应用通讯录权限范围内的全部员工：先列出根部门下的所有子部门，再逐个部门分页拉取直属员工，按 open_id 去重
+// API: GET /open-apis/contact/v3/departments/0/children、GET /open-apis/contact/v3/users/find_by_department
+func (c *Client) ListEmployees(ctx context.Context, token string) ([]Employee, error) {
+	departments := []string{"0"}
+	q := url.Values{"department_id_type": {"open_department_id"}, "fetch_child": {"true"}, "page_size": {"50"}}
+	for {
+		var result listDepartmentsResp
+		if err := c.getJSON(ctx, token, "/contact/v3/departments/0/children?"+q.Encode(), "feishu list departments", &result); err != nil {
+			return nil, err
+		}
+		if result.Code != 0 {
+			return nil, fmt.Errorf("feishu list departments: code=%d msg=%s", result.Code, result.Msg)
+		}
+		for _, d := range result.Data.Items {
+			departments = append(departments, d.OpenDepartmentID)
+		}
+		if !result.Data.HasMore || result.Data.PageToken == "" {
+			break
+		}
+		q.Set("page_token", result.Data.PageToken)
+	}
+
+	seen := make(map[string]bool)
+	var employees []Employee
+	for _, dept := range departments {
+		q := url.Values{"department_id": {dept}, "department_id_type": {"open_department_id"}, "user_id_type": {"open_id"}, "page_size": {"50"}}
+		for {
+			var result listUsersResp
+			if err := c.getJSON(ctx, token, "/contact/v3/users/find_by_department?"+q.Encode(), "feishu list users", &result); err != nil {
+				return nil, err
+			}
+			if result.Code != 0 {
+				return nil, fmt.Errorf("feishu list users (department %s): code=%d msg=%s", dept, result.Code, result.Msg)
+			}
+			for _, u := range result.Data.Items {
+				if u.OpenID == "" || seen[u.OpenID] {
+					continue
+				}
+				seen[u.OpenID] = true
+				email := u.EnterpriseEmail
+				if email == "" {
+					email = u.Email
+				}
+				employees = append(employees, Employee{
+					OpenID:   u.OpenID,
+					Name:     u.Name,
+					EnName:   u.EnName,
+					Nickname: u.Nickname,
+					Email:    email,
+					Mobile:   u.Mobile,
+					Resigned: u.Status.IsResigned || u.Status.IsFrozen,
+					Title:    u.JobTitle,
+					Type:     u.EmployeeType,
+				})
+			}
+			if !result.Data.HasMore || result.Data.PageToken == "" {
+				break
+			}
+			q.Set("page_token", result.Data.PageToken)
+		}
+	}
+	return employees, nil
+}
+
+// getJSON GET 请求开放接口并解析 JSON 响应
+func (c *Client) getJSON(ctx context.Context, token, path, apiName string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiBase()+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, apiName)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("%s parse response: %w, body: %.500s", apiName, err, string(b))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package feishu
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// docURLTypes 链接路径段到云文档类型（drive 权限等接口的 type 参数）
+var docURLTypes = map[string]string{
+	"docx":      "docx",
+	"docs":      "doc",
+	"sheets":    "sheet",
+	"base":      "bitable",
+	"file":      "file",
+	"wiki":      "wiki",
+	"folder":    "folder",
+	"mindnotes": "mindnote",
+}
+
+// DocPath 云文档类型（云空间文件列表、搜索结果中的 type）对应的链接路径段，为 docURLTypes 的反查：
+// sheet → sheets、bitable → base、doc → docs、mindnote → mindnotes，文件夹为 drive/folder；未知类型原样返回
+func DocPath(docType string) string {
+	if docType == "folder" {
+		return "drive/folder"
+	}
+	for path, t := range docURLTypes {
+		if t == docType {
+			return path
+		}
+	}
+	return docType
+}
+
+// ParseDocURL 从云文档链接中解析 token 与类型，如 https://xxx.feishu.cn/docx/AbCd → (AbCd, docx)；
+// 传入的不是链接时视为 docx 的 token
+func ParseDocURL(docURL string) (token, docType string, err error) {
+	s := strings.TrimSpace(docURL)
+	if s == "" {
+		return "", "", fmt.Errorf("empty doc url")
+	}
+	if !strings.Contains(s, "/") {
+		return s, "docx", nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", "", fmt.Errorf("parse doc url: %w", err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if t, ok := docURLTypes[parts[i]]; ok && parts[i+1] != "" {
+			return parts[i+1], t, nil
+		}
+	}
+	return "", "", fmt.Errorf("not a feishu doc url: %s", docURL)
+}
@@ -0,0 +1,33 @@
+package feishu
+
+import "testing"
+
+func TestDocPath(t *testing.T) {
+	c := NewClient(Config{Domain: "acme.feishu.cn"})
+	tests := []struct {
+		docType string
+		want    string
+	}{
+		{"docx", "https://acme.feishu.cn/docx/tok"},
+		{"doc", "https://acme.feishu.cn/docs/tok"},
+		{"sheet", "https://acme.feishu.cn/sheets/tok"},
+		{"bitable", "https://acme.feishu.cn/base/tok"},
+		{"mindnote", "https://acme.feishu.cn/mindnotes/tok"},
+		{"file", "https://acme.feishu.cn/file/tok"},
+		{"wiki", "https://acme.feishu.cn/wiki/tok"},
+		{"folder", "https://acme.feishu.cn/drive/folder/tok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.docType, func(t *testing.T) {
+			got := c.DocURL(DocPath(tt.docType), "tok")
+			if got != tt.want {
+				t.Fatalf("DocURL(DocPath(%q)) = %q, want %q", tt.docType, got, tt.want)
+			}
+			// 生成的链接能解析回同一类型
+			token, docType, err := ParseDocURL(got)
+			if err != nil || token != "tok" || docType != tt.docType {
+				t.Errorf("ParseDocURL(%q) = %q, %q, %v", got, token, docType, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DocBlock 写入文档的一个块（简化的 docx block）
+type DocBlock struct {
+	Kind string // text | heading1 | heading2 | bullet | todo
+	Text string
+}
+
+// docx block_type 取值：https://open.feishu.cn/document/server-docs/docs/docs/docx-v1/data-structure/block
+var docBlockTypes = map[string]int{
+	"text":     2,
+	"heading1": 3,
+	"heading2": 4,
+	"bullet":   12,
+	"todo":     17,
+}
+
+// TextToBlocks 将纯文本按行拆为段落块，空行忽略
+func TextToBlocks(content string) []DocBlock {
+	var blocks []DocBlock
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		blocks = append(blocks, DocBlock{Kind: "text", Text: line})
+	}
+	return blocks
+}
+
+// createBlockChildrenResp 创建子块响应
+type createBlockChildrenResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+// AppendDocBlocks 向文档末尾追加块
+// API: POST /open-apis/docx/v1/documents/:document_id/blocks/:block_id/children（block_id 取 document_id 即根块）
+func (c *Client) AppendDocBlocks(ctx context.Context, token, documentID string, blocks []DocBlock) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	var children []map[string]any
+	for _, b := range blocks {
+		blockType, ok := docBlockTypes[b.Kind]
+		if !ok {
+			blockType, b.Kind = docBlockTypes["text"], "text"
+		}
+		body := map[string]any{
+			"elements": []any{map[string]any{"text_run": map[string]string{"content": b.Text}}},
+		}
+		if b.Kind == "todo" {
+			body["style"] = map[string]any{"done": false}
+		}
+		children = append(children, map[string]any{"block_type": blockType, b.Kind: body})
+	}
+	url := fmt.Sprintf("%s/docx/v1/documents/%s/blocks/%s/children", c.apiBase(), documentID, documentID)
+	data, _ := json.Marshal(map[string]any{"children": children, "index": -1})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu append doc blocks")
+	if err != nil {
+		return err
+	}
+	var result createBlockChildrenResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("feishu append doc blocks parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu append doc blocks: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+// rawContentResp 文档纯文本内容响应
+type rawContentResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Content string `json:"content"`
+	} `json:"data"`
+}
+
+// GetDocRawContent 获取文档纯文本内容
+// API: GET /open-apis/docx/v1/documents/:document_id/raw_content
+func (c *Client) GetDocRawContent(ctx context.Context, token, documentID string) (string, error) {
+	url := fmt.Sprintf("%s/docx/v1/documents/%s/raw_content", c.apiBase(), documentID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get doc raw content")
+	if err != nil {
+		return "", err
+	}
+	var result rawContentResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu get doc raw content parse response: %w, body: %.500s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu get doc raw content: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.Content, nil
+}
+
+// RenameDoc 修改文档标题（根块即标题块）
+// API: PATCH /open-apis/docx/v1/documents/:document_id/blocks/:block_id（block_id 取 document_id）
+func (c *Client) RenameDoc(ctx context.Context, token, documentID, title string) error {
+	url := fmt.Sprintf("%s/docx/v1/documents/%s/blocks/%s", c.apiBase(), documentID, documentID)
+	body := map[string]any{
+		"update_text_elements": map[string]any{
+			"elements": []map[string]any{{"text_run": map[string]string{"content": title}}},
+		},
+	}
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu rename doc")
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("feishu rename doc parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu rename doc: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 导出任务轮询间隔与最长等待时间
+const (
+	exportPollInterval = 2 * time.Second
+	exportTimeout      = 2 * time.Minute
+)
+
+// ExportedFile 导出并下载后的文件
+type ExportedFile struct {
+	Name string
+	Ext  string // pdf | docx | xlsx
+	Data []byte
+}
+
+// exportTaskResult 导出任务状态
+type exportTaskResult struct {
+	FileExtension string `json:"file_extension"`
+	FileName      string `json:"file_name"`
+	FileToken     string `json:"file_token"`
+	FileSize      int64  `json:"file_size"`
+	JobErrorMsg   string `json:"job_error_msg"`
+	JobStatus     int    `json:"job_status"` // 0 成功，1 初始化，2 处理中，其余为失败
+}
+
+// ExportFile 导出云文档为 pdf/docx 等格式并下载：创建导出任务 -> 轮询结果 -> 下载文件
+func (c *Client) ExportFile(ctx context.Context, token, fileToken, docType, ext string) (ExportedFile, error) {
+	ticket, err := c.createExportTask(ctx, token, fileToken, docType, ext)
+	if err != nil {
+		return ExportedFile{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+	for {
+		task, err := c.getExportTask(ctx, token, ticket, fileToken)
+		if err != nil {
+			return ExportedFile{}, err
+		}
+		switch task.JobStatus {
+		case 0:
+			data, err := c.downloadExportFile(ctx, token, task.FileToken)
+			if err != nil {
+				return ExportedFile{}, err
+			}
+			return ExportedFile{Name: task.FileName, Ext: task.FileExtension, Data: data}, nil
+		case 1, 2:
+		default:
+			return ExportedFile{}, fmt.Errorf("feishu export task: status=%d msg=%s", task.JobStatus, task.JobErrorMsg)
+		}
+		select {
+		case <-ctx.Done():
+			return ExportedFile{}, fmt.Errorf("feishu export task %s: %w", ticket, ctx.Err())
+		case <-time.After(exportPollInterval):
+		}
+	}
+}
+
+// createExportTask 创建导出任务，返回 ticket
+// API: POST /open-apis/drive/v1/export_tasks
+func (c *Client) createExportTask(ctx context.Context, token, fileToken, docType, ext string) (string, error) {
+	data, _ := json.Marshal(map[string]string{
+		"file_extension": ext,
+		"token":          fileToken,
+		"type":           docType,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiBase()+"/drive/v1/export_tasks", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu create export task")
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Ticket string `json:"ticket"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu create export task parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu create export task: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.Ticket, nil
+}
+
+// getExportTask 查询导出任务结果
+// API: GET /open-apis/drive/v1/export_tasks/:ticket?token=xxx
+func (c *Client) getExportTask(ctx context.Context, token, ticket, fileToken string) (exportTaskResult, error) {
+	url := fmt.Sprintf("%s/drive/v1/export_tasks/%s?token=%s", c.apiBase(), ticket, fileToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return exportTaskResult{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return exportTaskResult{}, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get export task")
+	if err != nil {
+		return exportTaskResult{}, err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Result exportTaskResult `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return exportTaskResult{}, fmt.Errorf("feishu get export task parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return exportTaskResult{}, fmt.Errorf("feishu get export task: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.Result, nil
+}
+
+// downloadExportFile 下载导出文件
+// API: GET /open-apis/drive/v1/export_tasks/file/:file_token/download
+func (c *Client) downloadExportFile(ctx context.Context, token, fileToken string) ([]byte, error) {
+	url := fmt.Sprintf("%s/drive/v1/export_tasks/file/%s/download", c.apiBase(), fileToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return c.checkHTTPStatus(resp, "feishu download export file")
+}
+
+// imFileTypes 文件扩展名到 IM 上传接口 file_type 的映射，未列出的按 stream 上传
+var imFileTypes = map[string]string{
+	"pdf":  "pdf",
+	"doc":  "doc",
+	"docx": "doc",
+	"xls":  "xls",
+	"xlsx": "xls",
+	"ppt":  "ppt",
+	"pptx": "ppt",
+	"mp4":  "mp4",
+	"opus": "opus",
+}
+
+// UploadIMFile 上传文件用于消息发送，返回 file_key
+// API: POST /open-apis/im/v1/files（multipart/form-data）
+func (c *Client) UploadIMFile(ctx context.Context, token, fileName, ext string, data []byte) (string, error) {
+	fileType, ok := imFileTypes[ext]
+	if !ok {
+		fileType = "stream"
+	}
+	return c.uploadIMFile(ctx, token, fileName, fileType, 0, data)
+}
+
+// UploadIMAudio 上传 Opus 语音用于语音消息，durationMS 为时长（毫秒，0 时客户端不显示时长），返回 file_key
+func (c *Client) UploadIMAudio(ctx context.Context, token, fileName string, data []byte, durationMS int) (string, error) {
+	return c.uploadIMFile(ctx, token, fileName, "opus", durationMS, data)
+}
+
+func (c *Client) uploadIMFile(ctx context.Context, token, fileName, fileType string, durationMS int, data []byte) (string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	_ = w.WriteField("file_type", fileType)
+	_ = w.WriteField("file_name", fileName)
+	if durationMS > 0 {
+		_ = w.WriteField("duration", strconv.Itoa(durationMS))
+	}
+	part, err := w.CreateFormFile("file", fileName)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiBase()+"/im/v1/files", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu upload im file")
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			FileKey string `json:"file_key"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu upload im file parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu upload im file: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.FileKey, nil
+}
+
+// UploadIMImage 上传图片用于消息发送，返回 image_key
+// API: POST /open-apis/im/v1/images（multipart/form-data，image_type=message）
+func (c *Client) UploadIMImage(ctx context.Context, token, fileName string, data []byte) (string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	_ = w.WriteField("image_type", "message")
+	part, err := w.CreateFormFile("image", fileName)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiBase()+"/im/v1/images", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu upload im image")
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			ImageKey string `json:"image_key"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu upload im image parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu upload im image: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.ImageKey, nil
+}
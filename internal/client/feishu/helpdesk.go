@@ -0,0 +1,152 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// 服务台（Helpdesk）接口除 tenant_access_token 外，还需 X-Lark-Helpdesk-Authorization 头：
+// base64(helpdesk_id:helpdesk_token)，两者在服务台后台「设置 - API 设置」中获取
+
+// HelpdeskCategory 服务台工单分类
+type HelpdeskCategory struct {
+	ID       string             `json:"category_id"`
+	Name     string             `json:"name"`
+	Children []HelpdeskCategory `json:"children"`
+}
+
+// Ticket 服务台工单
+type Ticket struct {
+	ID     string `json:"ticket_id"`
+	ChatID string `json:"chat_id"`
+	Status int    `json:"status"`
+}
+
+// HelpdeskEnabled 是否配置了服务台凭证
+func (c *Client) HelpdeskEnabled() bool {
+	return c.cfg.HelpdeskID != "" && c.cfg.HelpdeskToken != ""
+}
+
+// helpdeskRequest 发送服务台请求并检查返回码，data 非 nil 时解析 data 字段
+func (c *Client) helpdeskRequest(ctx context.Context, token, method, path string, body any, data any, apiName string) error {
+	var reader io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.apiBase()+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Lark-Helpdesk-Authorization", base64.StdEncoding.EncodeToString([]byte(c.cfg.HelpdeskID+":"+c.cfg.HelpdeskToken)))
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, apiName)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Code int             `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("%s parse response: %w, body: %s", apiName, err, string(b))
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("%s: code=%d msg=%s", apiName, result.Code, result.Msg)
+	}
+	if data != nil && len(result.Data) > 0 {
+		if err := json.Unmarshal(result.Data, data); err != nil {
+			return fmt.Errorf("%s parse data: %w", apiName, err)
+		}
+	}
+	return nil
+}
+
+// StartHelpdeskService 以用户身份创建服务台会话（人工服务），服务台随之生成工单，返回会话 chat_id；
+// customizedInfo 为展示给客服的问题描述
+// API: POST /open-apis/helpdesk/v1/start_service
+func (c *Client) StartHelpdeskService(ctx context.Context, token, openID, customizedInfo string) (string, error) {
+	var data struct {
+		ChatID string `json:"chat_id"`
+	}
+	body := map[string]any{"human_service": true, "open_id": openID, "customized_info": customizedInfo}
+	if err := c.helpdeskRequest(ctx, token, http.MethodPost, "/helpdesk/v1/start_service", body, &data, "feishu helpdesk start service"); err != nil {
+		return "", err
+	}
+	return data.ChatID, nil
+}
+
+// FindTicketByChat 按服务台会话查找工单：列出用户的工单，返回 chat_id 相同的一条
+// API: GET /open-apis/helpdesk/v1/tickets
+func (c *Client) FindTicketByChat(ctx context.Context, token, openID, chatID string) (Ticket, error) {
+	var data struct {
+		Tickets []Ticket `json:"tickets"`
+	}
+	path := "/helpdesk/v1/tickets?page=1&page_size=20&guest_id=" + url.QueryEscape(openID)
+	if err := c.helpdeskRequest(ctx, token, http.MethodGet, path, nil, &data, "feishu helpdesk list tickets"); err != nil {
+		return Ticket{}, err
+	}
+	for _, t := range data.Tickets {
+		if t.ChatID == chatID {
+			return t, nil
+		}
+	}
+	return Ticket{}, fmt.Errorf("feishu helpdesk list tickets: no ticket for chat %s", chatID)
+}
+
+// ListHelpdeskCategories 获取服务台的工单分类（含子分类），结果按 CacheTTL 缓存
+// API: GET /open-apis/helpdesk/v1/categories
+func (c *Client) ListHelpdeskCategories(ctx context.Context, token string) ([]HelpdeskCategory, error) {
+	key := cacheKey(ctx, "helpdesk_categories", c.cfg.HelpdeskID)
+	if v, ok := c.cache.get(key); ok {
+		return v.([]HelpdeskCategory), nil
+	}
+	var data struct {
+		Categories []HelpdeskCategory `json:"categories"`
+	}
+	if err := c.helpdeskRequest(ctx, token, http.MethodGet, "/helpdesk/v1/categories", nil, &data, "feishu helpdesk list categories"); err != nil {
+		return nil, err
+	}
+	c.cache.set(key, data.Categories, c.cacheTTL())
+	return data.Categories, nil
+}
+
+// TagTicket 给工单打标签（如问题分类），便于客服筛选
+// API: PUT /open-apis/helpdesk/v1/tickets/:ticket_id
+func (c *Client) TagTicket(ctx context.Context, token, ticketID string, tags []string) error {
+	path := "/helpdesk/v1/tickets/" + url.PathEscape(ticketID)
+	return c.helpdeskRequest(ctx, token, http.MethodPut, path, map[string]any{"tag_names": tags}, nil, "feishu helpdesk update ticket")
+}
+
+// SendTicketMessage 以服务台身份向工单会话发送消息，msgType 为 text 或 post，content 为对应的消息内容 JSON
+// API: POST /open-apis/helpdesk/v1/tickets/:ticket_id/messages
+func (c *Client) SendTicketMessage(ctx context.Context, token, ticketID, msgType, content string) error {
+	path := "/helpdesk/v1/tickets/" + url.PathEscape(ticketID) + "/messages"
+	body := map[string]any{"msg_type": msgType, "content": json.RawMessage(content)}
+	return c.helpdeskRequest(ctx, token, http.MethodPost, path, body, nil, "feishu helpdesk send ticket message")
+}
+
+// BuildTicketPostContent 构建工单消息：标题、问题描述与截图（imageKeys 由 UploadIMImage 返回）
+func BuildTicketPostContent(title, text string, imageKeys []string) string {
+	var content [][]any
+	if text != "" {
+		content = append(content, []any{map[string]string{"tag": "text", "text": text}})
+	}
+	for _, key := range imageKeys {
+		content = append(content, []any{map[string]string{"tag": "img", "image_key": key}})
+	}
+	b, _ := json.Marshal(map[string]any{"zh_cn": map[string]any{"title": title, "content": content}})
+	return string(b)
+}
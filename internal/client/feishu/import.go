@@ -0,0 +1,218 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 导入任务轮询间隔与最长等待时间
+const (
+	importPollInterval = 2 * time.Second
+	importTimeout      = 2 * time.Minute
+)
+
+// UploadFileMaxSize upload_all 单次上传的最大文件大小（20MB），超出需分片上传
+const UploadFileMaxSize = 20 << 20
+
+// ImportedDoc 导入生成的在线文档
+type ImportedDoc struct {
+	Token string
+	Type  string // docx | sheet | bitable
+	URL   string
+}
+
+// UploadFile 上传文件到云空间目录，返回文件 token
+// API: POST /open-apis/drive/v1/files/upload_all（multipart/form-data）
+func (c *Client) UploadFile(ctx context.Context, token, folderToken, fileName string, data []byte) (string, error) {
+	fields := map[string]string{
+		"file_name":   fileName,
+		"parent_type": "explorer",
+		"parent_node": folderToken,
+		"size":        strconv.Itoa(len(data)),
+	}
+	return c.uploadAll(ctx, token, "/drive/v1/files/upload_all", "feishu upload file", fields, fileName, data)
+}
+
+// uploadImportMedia 上传待导入的文件素材，返回文件 token
+// API: POST /open-apis/drive/v1/medias/upload_all，parent_type=ccm_import_open
+func (c *Client) uploadImportMedia(ctx context.Context, token, fileName, ext, docType string, data []byte) (string, error) {
+	extra, _ := json.Marshal(map[string]string{"obj_type": docType, "file_extension": ext})
+	fields := map[string]string{
+		"file_name":   fileName,
+		"parent_type": "ccm_import_open",
+		"size":        strconv.Itoa(len(data)),
+		"extra":       string(extra),
+	}
+	return c.uploadAll(ctx, token, "/drive/v1/medias/upload_all", "feishu upload import media", fields, fileName, data)
+}
+
+func (c *Client) uploadAll(ctx context.Context, token, path, apiName string, fields map[string]string, fileName string, data []byte) (string, error) {
+	if len(data) > UploadFileMaxSize {
+		return "", fmt.Errorf("%s: file too large (%d bytes)", apiName, len(data))
+	}
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range fields {
+		_ = w.WriteField(k, v)
+	}
+	part, err := w.CreateFormFile("file", fileName)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiBase()+path, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, apiName)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			FileToken string `json:"file_token"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("%s parse response: %w, body: %s", apiName, err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("%s: code=%d msg=%s", apiName, result.Code, result.Msg)
+	}
+	return result.Data.FileToken, nil
+}
+
+// ImportFile 将本地文件导入为在线文档：上传素材 -> 创建导入任务 -> 轮询结果
+// ext 为源文件扩展名（docx、xlsx、csv、md 等），docType 为目标类型 docx | sheet | bitable
+func (c *Client) ImportFile(ctx context.Context, token, folderToken, fileName, ext, docType string, data []byte) (ImportedDoc, error) {
+	fileToken, err := c.uploadImportMedia(ctx, token, fileName, ext, docType, data)
+	if err != nil {
+		return ImportedDoc{}, err
+	}
+	ticket, err := c.createImportTask(ctx, token, folderToken, fileToken, fileName, ext, docType)
+	if err != nil {
+		return ImportedDoc{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, importTimeout)
+	defer cancel()
+	for {
+		task, err := c.getImportTask(ctx, token, ticket)
+		if err != nil {
+			return ImportedDoc{}, err
+		}
+		switch task.JobStatus {
+		case 0:
+			return ImportedDoc{Token: task.Token, Type: task.Type, URL: task.URL}, nil
+		case 1, 2:
+		default:
+			return ImportedDoc{}, fmt.Errorf("feishu import task: status=%d msg=%s", task.JobStatus, task.JobErrorMsg)
+		}
+		select {
+		case <-ctx.Done():
+			return ImportedDoc{}, fmt.Errorf("feishu import task %s: %w", ticket, ctx.Err())
+		case <-time.After(importPollInterval):
+		}
+	}
+}
+
+// importTaskResult 导入任务状态
+type importTaskResult struct {
+	Type        string `json:"type"`
+	JobStatus   int    `json:"job_status"` // 0 成功，1 初始化，2 处理中，其余为失败
+	JobErrorMsg string `json:"job_error_msg"`
+	Token       string `json:"token"`
+	URL         string `json:"url"`
+}
+
+// createImportTask 创建导入任务，返回 ticket
+// API: POST /open-apis/drive/v1/import_tasks
+func (c *Client) createImportTask(ctx context.Context, token, folderToken, fileToken, fileName, ext, docType string) (string, error) {
+	reqBody := map[string]any{
+		"file_extension": ext,
+		"file_token":     fileToken,
+		"type":           docType,
+		"file_name":      fileName,
+		"point":          map[string]any{"mount_type": 1, "mount_key": folderToken},
+	}
+	data, _ := json.Marshal(reqBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiBase()+"/drive/v1/import_tasks", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu create import task")
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Ticket string `json:"ticket"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu create import task parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu create import task: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.Ticket, nil
+}
+
+// getImportTask 查询导入任务结果
+// API: GET /open-apis/drive/v1/import_tasks/:ticket
+func (c *Client) getImportTask(ctx context.Context, token, ticket string) (importTaskResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiBase()+"/drive/v1/import_tasks/"+ticket, nil)
+	if err != nil {
+		return importTaskResult{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return importTaskResult{}, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get import task")
+	if err != nil {
+		return importTaskResult{}, err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Result importTaskResult `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return importTaskResult{}, fmt.Errorf("feishu get import task parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return importTaskResult{}, fmt.Errorf("feishu get import task: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.Result, nil
+}
@@ -0,0 +1,53 @@
+package feishu
+
+import (
+	"regexp"
+	"strings"
+)
+
+// urlRE 文本中的 http(s) 链接：只含链接允许的 ASCII 字符（含 %XX 编码），遇到空白、中文与全角标点即结束，
+// 如 "文档：https://example.com/docx/abc，请查收" 中的链接不含 "，请查收"
+var urlRE = regexp.MustCompile(`(?i)https?://[A-Za-z0-9\-._~:/?#\[\]@!$&'()*+,;=%]+`)
+
+// urlSpans 文本中全部链接的字节区间；去掉链接末尾的英文标点与未配对的右括号，如 "(见 https://a.com/x)." 中的 ")."
+func urlSpans(s string) [][2]int {
+	var spans [][2]int
+	for _, m := range urlRE.FindAllStringIndex(s, -1) {
+		u := trimURLEnd(s[m[0]:m[1]])
+		// 只有协议、没有主机的不算链接
+		if len(u) <= strings.Index(u, "://")+len("://") {
+			continue
+		}
+		spans = append(spans, [2]int{m[0], m[0] + len(u)})
+	}
+	return spans
+}
+
+func trimURLEnd(u string) string {
+	for u != "" {
+		switch last := u[len(u)-1]; {
+		case strings.IndexByte(".,;:!?'*", last) >= 0:
+		case last == ')' && strings.Count(u, "(") < strings.Count(u, ")"):
+		case last == ']' && strings.Count(u, "[") < strings.Count(u, "]"):
+		default:
+			return u
+		}
+		u = u[:len(u)-1]
+	}
+	return u
+}
+
+// FindURLs 按出现顺序返回文本中的全部 http(s) 链接
+func FindURLs(s string) []string {
+	spans := urlSpans(s)
+	urls := make([]string, len(spans))
+	for i, sp := range spans {
+		urls[i] = s[sp[0]:sp[1]]
+	}
+	return urls
+}
+
+// HasURL 文本中是否含 http(s) 链接
+func HasURL(s string) bool {
+	return len(urlSpans(s)) > 0
+}
@@ -0,0 +1,195 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 商店应用（ISV）：同一个应用安装到多个飞书企业，每个企业以 tenant_key 区分。
+// 鉴权流程：飞书每小时推送 app_ticket 事件 → 用 app_id/app_secret/app_ticket 换取 app_access_token
+// → 用 app_access_token + tenant_key 换取该企业的 tenant_access_token
+
+// marketplace 商店应用的鉴权状态与已安装企业
+type marketplace struct {
+	mu       sync.RWMutex
+	ticket   string
+	appToken string
+	expireAt time.Time
+	tenants  map[string]string // 本服务租户 -> tenant_key
+}
+
+type tenantKeyCtx struct{}
+
+// WithTenant 将租户对应的飞书企业（tenant_key）放入 ctx，之后的 GetTenantAccessToken 换取该企业的 token；
+// 非商店应用或租户未安装时原样返回
+func (c *Client) WithTenant(ctx context.Context, tenant string) context.Context {
+	if c == nil || !c.cfg.Marketplace {
+		return ctx
+	}
+	key, ok := c.TenantKey(tenant)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantKeyCtx{}, key)
+}
+
+func tenantKeyFrom(ctx context.Context) string {
+	key, _ := ctx.Value(tenantKeyCtx{}).(string)
+	return key
+}
+
+// Marketplace 是否为商店应用
+func (c *Client) Marketplace() bool {
+	return c.cfg.Marketplace
+}
+
+// AddTenant 记录企业安装，tenant 为本服务中的租户
+func (c *Client) AddTenant(tenant, tenantKey string) {
+	c.market.mu.Lock()
+	c.market.tenants[tenant] = tenantKey
+	c.market.mu.Unlock()
+}
+
+// RemoveTenant 企业卸载或停用应用后移除
+func (c *Client) RemoveTenant(tenantKey string) {
+	c.market.mu.Lock()
+	for tenant, key := range c.market.tenants {
+		if key == tenantKey {
+			delete(c.market.tenants, tenant)
+		}
+	}
+	c.market.mu.Unlock()
+}
+
+// TenantKey 租户对应的飞书企业
+func (c *Client) TenantKey(tenant string) (string, bool) {
+	c.market.mu.RLock()
+	defer c.market.mu.RUnlock()
+	key, ok := c.market.tenants[tenant]
+	return key, ok
+}
+
+// Tenants 已安装应用的本服务租户
+func (c *Client) Tenants() []string {
+	c.market.mu.RLock()
+	defer c.market.mu.RUnlock()
+	tenants := make([]string, 0, len(c.market.tenants))
+	for tenant := range c.market.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// SetAppTicket 保存飞书推送的 app_ticket（事件 app_ticket），已缓存的 app_access_token 仍可继续使用
+func (c *Client) SetAppTicket(ticket string) {
+	c.market.mu.Lock()
+	c.market.ticket = ticket
+	c.market.mu.Unlock()
+}
+
+// ResendAppTicket 请飞书立即重新推送 app_ticket（启动时尚未收到 ticket 时调用）
+// API: POST /open-apis/auth/v3/app_ticket/resend
+func (c *Client) ResendAppTicket(ctx context.Context) error {
+	c.mu.RLock()
+	body := map[string]string{"app_id": c.cfg.AppID, "app_secret": c.appSecret}
+	c.mu.RUnlock()
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := c.authCall(ctx, "/auth/v3/app_ticket/resend", body, "feishu resend app ticket", &result); err != nil {
+		return err
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu resend app ticket: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+// appAccessToken 商店应用的 app_access_token，缓存至过期前 5 分钟
+// API: POST /open-apis/auth/v3/app_access_token
+func (c *Client) appAccessToken(ctx context.Context) (string, error) {
+	c.market.mu.RLock()
+	token, expireAt, ticket := c.market.appToken, c.market.expireAt, c.market.ticket
+	c.market.mu.RUnlock()
+	if token != "" && time.Now().Before(expireAt) {
+		return token, nil
+	}
+	if ticket == "" {
+		if err := c.ResendAppTicket(ctx); err != nil {
+			return "", fmt.Errorf("feishu app_ticket not received yet, resend: %w", err)
+		}
+		return "", fmt.Errorf("feishu app_ticket not received yet, requested resend")
+	}
+	c.mu.RLock()
+	body := map[string]string{"app_id": c.cfg.AppID, "app_secret": c.appSecret, "app_ticket": ticket}
+	c.mu.RUnlock()
+	var result struct {
+		Code           int    `json:"code"`
+		Msg            string `json:"msg"`
+		AppAccessToken string `json:"app_access_token"`
+		Expire         int    `json:"expire"`
+	}
+	if err := c.authCall(ctx, "/auth/v3/app_access_token", body, "feishu app access token", &result); err != nil {
+		return "", err
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu app access token: code=%d msg=%s", result.Code, result.Msg)
+	}
+	c.market.mu.Lock()
+	c.market.appToken = result.AppAccessToken
+	c.market.expireAt = time.Now().Add(time.Duration(result.Expire)*time.Second - 5*time.Minute)
+	c.market.mu.Unlock()
+	return result.AppAccessToken, nil
+}
+
+// marketplaceTenantToken 换取 ctx 中飞书企业的 tenant_access_token
+// API: POST /open-apis/auth/v3/tenant_access_token
+func (c *Client) marketplaceTenantToken(ctx context.Context) (string, int, error) {
+	key := tenantKeyFrom(ctx)
+	if key == "" {
+		return "", 0, fmt.Errorf("feishu marketplace app: tenant has not installed the app")
+	}
+	appToken, err := c.appAccessToken(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	var result tenantAccessTokenResp
+	body := map[string]string{"app_access_token": appToken, "tenant_key": key}
+	if err := c.authCall(ctx, "/auth/v3/tenant_access_token", body, "feishu auth", &result); err != nil {
+		return "", 0, err
+	}
+	if result.Code != 0 {
+		return "", 0, fmt.Errorf("feishu auth: tenant_key=%s code=%d msg=%s", key, result.Code, result.Msg)
+	}
+	return result.TenantAccessToken, result.Expire, nil
+}
+
+// authCall 调用鉴权类接口（无需 token）
+func (c *Client) authCall(ctx context.Context, path string, body any, apiName string, out any) error {
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiBase()+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, apiName)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("%s parse response: %w, body: %s", apiName, err, string(b))
+	}
+	return nil
+}
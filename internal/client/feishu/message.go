@@ -0,0 +1,188 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// getMessageResp 获取消息响应
+type getMessageResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Items []struct {
+			MessageID string `json:"message_id"`
+			ChatID    string `json:"chat_id"`
+			Deleted   bool   `json:"deleted"`
+		} `json:"items"`
+	} `json:"data"`
+}
+
+// GetMessage 查询消息是否存在，返回所在会话 ID；消息不存在或已撤回时返回错误
+// API: GET /open-apis/im/v1/messages/:message_id
+func (c *Client) GetMessage(ctx context.Context, token, messageID string) (string, error) {
+	url := fmt.Sprintf("%s/im/v1/messages/%s", c.apiBase(), messageID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get message")
+	if err != nil {
+		return "", err
+	}
+	var result getMessageResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu get message parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu get message: code=%d msg=%s", result.Code, result.Msg)
+	}
+	if len(result.Data.Items) == 0 || result.Data.Items[0].Deleted {
+		return "", fmt.Errorf("feishu get message: %s not found or recalled", messageID)
+	}
+	return result.Data.Items[0].ChatID, nil
+}
+
+// RecallMessage 撤回机器人发出的消息（发送后 24 小时内）；已撤回视为成功
+// API: DELETE /open-apis/im/v1/messages/:message_id
+func (c *Client) RecallMessage(ctx context.Context, token, messageID string) error {
+	url := fmt.Sprintf("%s/im/v1/messages/%s", c.apiBase(), messageID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu recall message")
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("feishu recall message parse response: %w, body: %s", err, string(b))
+	}
+	// 230011: 消息已撤回
+	if result.Code != 0 && result.Code != 230011 {
+		return fmt.Errorf("feishu recall message: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+// MessageResource 消息中的图片、文件、语音等资源
+type MessageResource struct {
+	Name     string // 响应 Content-Disposition 中的文件名，没有时为空
+	MimeType string
+	Data     []byte
+}
+
+// GetMessageResource 下载消息中的资源，resourceType 为 image（图片）或 file（文件、语音、视频）；
+// 超过 maxBytes（大于 0 时）返回错误
+// API: GET /open-apis/im/v1/messages/:message_id/resources/:file_key?type=
+func (c *Client) GetMessageResource(ctx context.Context, token, messageID, fileKey, resourceType string, maxBytes int64) (MessageResource, error) {
+	url := fmt.Sprintf("%s/im/v1/messages/%s/resources/%s?type=%s", c.apiBase(), messageID, fileKey, resourceType)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return MessageResource{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return MessageResource{}, err
+	}
+	if maxBytes > 0 {
+		// 多读一个字节以判断是否超限
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(resp.Body, maxBytes+1), resp.Body}
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get message resource")
+	if err != nil {
+		return MessageResource{}, err
+	}
+	if maxBytes > 0 && int64(len(b)) > maxBytes {
+		return MessageResource{}, fmt.Errorf("feishu get message resource: %s exceeds %d bytes", fileKey, maxBytes)
+	}
+	res := MessageResource{MimeType: resp.Header.Get("Content-Type"), Data: b}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		res.Name = params["filename"]
+	}
+	return res, nil
+}
+
+// ReplyMessage 回复指定消息，msgType 与 content 同 SendMessage；inThread 为 true 时以话题形式回复
+// API: POST /open-apis/im/v1/messages/:message_id/reply
+func (c *Client) ReplyMessage(ctx context.Context, token, messageID, msgType, content string, inThread bool) (string, error) {
+	url := fmt.Sprintf("%s/im/v1/messages/%s/reply", c.apiBase(), messageID)
+	data, _ := json.Marshal(map[string]any{"msg_type": msgType, "content": content, "reply_in_thread": inThread})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu reply message")
+	if err != nil {
+		return "", err
+	}
+	var result sendMessageResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu reply message parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu reply message: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.MessageID, nil
+}
+
+// GetBotOpenID 获取机器人自身的 open_id，用于识别消息中 @机器人 的提及
+// API: GET /open-apis/bot/v3/info
+func (c *Client) GetBotOpenID(ctx context.Context, token string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiBase()+"/bot/v3/info", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get bot info")
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Bot  struct {
+			OpenID string `json:"open_id"`
+		} `json:"bot"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu get bot info parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu get bot info: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Bot.OpenID, nil
+}
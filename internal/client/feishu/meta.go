@@ -0,0 +1,187 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sayso-agent/internal/model"
+)
+
+// DocMeta 云文档元数据
+type DocMeta struct {
+	Token      string
+	Type       string // docx | doc | sheet | bitable | file | wiki 等
+	Title      string
+	OwnerID    string // 所有者 open_id
+	URL        string
+	CreatedAt  time.Time
+	ModifiedAt time.Time
+	ModifierID string // 最后编辑人 open_id
+}
+
+type docMetaResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Metas []struct {
+			DocToken         string `json:"doc_token"`
+			DocType          string `json:"doc_type"`
+			Title            string `json:"title"`
+			OwnerID          string `json:"owner_id"`
+			CreateTime       string `json:"create_time"`
+			LatestModifyUser string `json:"latest_modify_user"`
+			LatestModifyTime string `json:"latest_modify_time"`
+			URL              string `json:"url"`
+		} `json:"metas"`
+		FailedList []struct {
+			Token string `json:"token"`
+			Code  int    `json:"code"`
+		} `json:"failed_list"`
+	} `json:"data"`
+}
+
+// GetDocMeta 获取云文档的标题、所有者、更新时间等元数据
+// API: POST /open-apis/drive/v1/metas/batch_query?user_id_type=open_id
+func (c *Client) GetDocMeta(ctx context.Context, token, docToken, docType string) (DocMeta, error) {
+	url := c.apiBase() + "/drive/v1/metas/batch_query?user_id_type=open_id"
+	body := map[string]any{
+		"request_docs": []map[string]string{{"doc_token": docToken, "doc_type": docType}},
+		"with_url":     true,
+	}
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return DocMeta{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return DocMeta{}, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get doc meta")
+	if err != nil {
+		return DocMeta{}, err
+	}
+	var result docMetaResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return DocMeta{}, fmt.Errorf("feishu get doc meta parse response: %w, body: %.500s", err, string(b))
+	}
+	if result.Code != 0 {
+		return DocMeta{}, fmt.Errorf("feishu get doc meta: code=%d msg=%s", result.Code, result.Msg)
+	}
+	if len(result.Data.Metas) == 0 {
+		if len(result.Data.FailedList) > 0 {
+			return DocMeta{}, fmt.Errorf("feishu get doc meta: %s code=%d", docToken, result.Data.FailedList[0].Code)
+		}
+		return DocMeta{}, fmt.Errorf("feishu get doc meta: %s not found", docToken)
+	}
+	m := result.Data.Metas[0]
+	meta := DocMeta{Token: m.DocToken, Type: m.DocType, Title: m.Title, OwnerID: m.OwnerID, URL: m.URL, ModifierID: m.LatestModifyUser}
+	meta.CreatedAt = unixTime(m.CreateTime)
+	meta.ModifiedAt = unixTime(m.LatestModifyTime)
+	return meta, nil
+}
+
+// unixTime 解析秒级时间戳字符串，无效时为零值
+func unixTime(s string) time.Time {
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil && sec > 0 {
+		return time.Unix(sec, 0)
+	}
+	return time.Time{}
+}
+
+// DocStats 云文档的访问统计
+type DocStats struct {
+	UV        int // 浏览人数
+	PV        int // 浏览次数
+	LikeCount int
+}
+
+// GetDocStatistics 获取云文档的浏览人数、浏览次数与点赞数
+// API: GET /open-apis/drive/v1/files/:file_token/statistics?file_type=
+func (c *Client) GetDocStatistics(ctx context.Context, token, docToken, docType string) (DocStats, error) {
+	url := fmt.Sprintf("%s/drive/v1/files/%s/statistics?file_type=%s", c.apiBase(), docToken, docType)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return DocStats{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return DocStats{}, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get doc statistics")
+	if err != nil {
+		return DocStats{}, err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			Statistics struct {
+				UV        int `json:"uv"`
+				PV        int `json:"pv"`
+				LikeCount int `json:"like_count"`
+			} `json:"statistics"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return DocStats{}, fmt.Errorf("feishu get doc statistics parse response: %w, body: %.500s", err, string(b))
+	}
+	if result.Code != 0 {
+		return DocStats{}, fmt.Errorf("feishu get doc statistics: code=%d msg=%s", result.Code, result.Msg)
+	}
+	s := result.Data.Statistics
+	return DocStats{UV: s.UV, PV: s.PV, LikeCount: s.LikeCount}, nil
+}
+
+// docIcons 分享卡片标题前按文档类型显示的图标
+var docIcons = map[string]string{
+	"docx":     "📄",
+	"doc":      "📄",
+	"sheet":    "📊",
+	"bitable":  "🗂️",
+	"mindnote": "🧠",
+	"file":     "📎",
+	"wiki":     "📚",
+	"slides":   "📽️",
+	"minutes":  "🎙️",
+	"folder":   "📁",
+	"shortcut": "🔗",
+}
+
+// BuildDocShareCard 构建云文档分享卡片：图标 + 文档标题、附言、所有者、创建与更新时间、浏览人数、打开按钮；
+// stats 为 nil 时不显示浏览人数
+func BuildDocShareCard(meta DocMeta, ownerName, text string, stats *DocStats) string {
+	card := model.Card{Title: meta.Title, Icon: docIcons[meta.Type], Color: "blue"}
+	if card.Title == "" {
+		card.Title = "未命名文档"
+	}
+	if text != "" {
+		card.Sections = append(card.Sections, model.CardSection{Text: text})
+	}
+	var fields []model.CardField
+	if ownerName != "" {
+		fields = append(fields, model.CardField{Name: "所有者", Value: ownerName})
+	}
+	if !meta.CreatedAt.IsZero() {
+		fields = append(fields, model.CardField{Name: "创建时间", Value: meta.CreatedAt.Format("2006-01-02 15:04")})
+	}
+	if !meta.ModifiedAt.IsZero() {
+		fields = append(fields, model.CardField{Name: "最近更新", Value: meta.ModifiedAt.Format("2006-01-02 15:04")})
+	}
+	if stats != nil && stats.UV > 0 {
+		fields = append(fields, model.CardField{Name: "浏览", Value: fmt.Sprintf("%d 人 / %d 次", stats.UV, stats.PV)})
+	}
+	if len(fields) > 0 {
+		card.Sections = append(card.Sections, model.CardSection{Fields: fields})
+	}
+	card.Buttons = []model.CardButton{{Text: "打开文档", URL: meta.URL, Primary: true}}
+	return RenderCard(card)
+}
@@ -0,0 +1,115 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ParseMinuteToken 从妙记链接中提取 minute_token，如 https://xxx.feishu.cn/minutes/obcnxxxx → obcnxxxx；
+// 传入的已是 token 时原样返回
+func ParseMinuteToken(minuteURL string) (string, error) {
+	s := strings.TrimSpace(minuteURL)
+	if s == "" {
+		return "", fmt.Errorf("empty minute url")
+	}
+	if !strings.Contains(s, "/") {
+		return s, nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("parse minute url: %w", err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, p := range parts {
+		if p == "minutes" && i+1 < len(parts) && parts[i+1] != "" {
+			return parts[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("not a minutes url: %s", minuteURL)
+}
+
+// minuteMetaResp 妙记信息响应
+type minuteMetaResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Minute struct {
+			Token      string `json:"token"`
+			Title      string `json:"title"`
+			OwnerID    string `json:"owner_id"`
+			CreateTime string `json:"create_time"`
+			URL        string `json:"url"`
+		} `json:"minute"`
+	} `json:"data"`
+}
+
+// MinuteInfo 妙记基本信息
+type MinuteInfo struct {
+	Token   string
+	Title   string
+	OwnerID string
+	URL     string
+}
+
+// GetMinute 获取妙记信息
+// API: GET /open-apis/minutes/v1/minutes/:minute_token
+func (c *Client) GetMinute(ctx context.Context, token, minuteToken string) (MinuteInfo, error) {
+	reqURL := fmt.Sprintf("%s/minutes/v1/minutes/%s", c.apiBase(), minuteToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return MinuteInfo{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return MinuteInfo{}, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get minute")
+	if err != nil {
+		return MinuteInfo{}, err
+	}
+	var result minuteMetaResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return MinuteInfo{}, fmt.Errorf("feishu get minute parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return MinuteInfo{}, fmt.Errorf("feishu get minute: code=%d msg=%s", result.Code, result.Msg)
+	}
+	m := result.Data.Minute
+	return MinuteInfo{Token: m.Token, Title: m.Title, OwnerID: m.OwnerID, URL: m.URL}, nil
+}
+
+// GetMinuteTranscript 导出妙记文字记录（纯文本，含说话人）
+// API: GET /open-apis/minutes/v1/minutes/:minute_token/transcript?need_speaker=true&file_format=txt
+// 成功时响应体直接是文件内容；失败时为 JSON 错误
+func (c *Client) GetMinuteTranscript(ctx context.Context, token, minuteToken string) (string, error) {
+	reqURL := fmt.Sprintf("%s/minutes/v1/minutes/%s/transcript?need_speaker=true&need_timestamp=false&file_format=txt", c.apiBase(), minuteToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	contentType := resp.Header.Get("Content-Type")
+	b, err := c.checkHTTPStatus(resp, "feishu get minute transcript")
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(contentType, "application/json") {
+		var result struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if err := json.Unmarshal(b, &result); err == nil && result.Code != 0 {
+			return "", fmt.Errorf("feishu get minute transcript: code=%d msg=%s", result.Code, result.Msg)
+		}
+	}
+	return string(b), nil
+}
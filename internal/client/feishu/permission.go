@@ -0,0 +1,238 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// PermissionMember 云文档协作者
+type PermissionMember struct {
+	MemberType string `json:"member_type"` // openid, userid, email, openchat, opendepartmentid 等
+	MemberID   string `json:"member_id"`
+	Perm       string `json:"perm"` // view, edit, full_access
+	Type       string `json:"type"` // user, chat, department 等
+	Name       string `json:"name"`
+}
+
+// listPermissionMembersResp 协作者列表响应
+type listPermissionMembersResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Items []PermissionMember `json:"items"`
+	} `json:"data"`
+}
+
+// ListPermissionMembers 获取云文档协作者
+// API: GET /open-apis/drive/v1/permissions/:token/members?type=docx&fields=*
+func (c *Client) ListPermissionMembers(ctx context.Context, accessToken, docToken, docType string) ([]PermissionMember, error) {
+	url := fmt.Sprintf("%s/drive/v1/permissions/%s/members?type=%s&fields=*", c.apiBase(), docToken, docType)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu list permission members")
+	if err != nil {
+		return nil, err
+	}
+	var result listPermissionMembersResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("feishu list permission members parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("feishu list permission members: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.Items, nil
+}
+
+// CheckMemberPermission 判断当前身份（应用或用户令牌）对云文档/文件夹是否有 action 权限（view | edit | share | comment 等）
+// API: GET /open-apis/drive/v1/permissions/:token/members/auth?type=folder&action=edit
+func (c *Client) CheckMemberPermission(ctx context.Context, accessToken, token, fileType, action string) (bool, error) {
+	url := fmt.Sprintf("%s/drive/v1/permissions/%s/members/auth?type=%s&action=%s", c.apiBase(), token, fileType, action)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu check permission")
+	if err != nil {
+		return false, err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			AuthResult bool `json:"auth_result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return false, fmt.Errorf("feishu check permission parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return false, fmt.Errorf("feishu check permission: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.AuthResult, nil
+}
+
+// RemovePermissionMember 移除云文档协作者
+// API: DELETE /open-apis/drive/v1/permissions/:token/members/:member_id?type=docx&member_type=openid
+func (c *Client) RemovePermissionMember(ctx context.Context, accessToken, docToken, docType string, member PermissionMember) error {
+	url := fmt.Sprintf("%s/drive/v1/permissions/%s/members/%s?type=%s&member_type=%s", c.apiBase(), docToken, member.MemberID, docType, member.MemberType)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu remove permission member")
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("feishu remove permission member parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu remove permission member: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+// PublicPermission 云文档公共设置（链接分享、对外访问等）
+type PublicPermission struct {
+	ExternalAccessEntity     string `json:"external_access_entity,omitempty"` // open | closed | allow_shared_partner_tenant
+	SecurityEntity           string `json:"security_entity,omitempty"`        // anyone_can_view | anyone_can_edit | only_full_access
+	CommentEntity            string `json:"comment_entity,omitempty"`         // anyone_can_view | anyone_can_edit
+	ShareEntity              string `json:"share_entity,omitempty"`           // anyone | same_tenant
+	ManageCollaboratorEntity string `json:"manage_collaborator_entity,omitempty"`
+	LinkShareEntity          string `json:"link_share_entity,omitempty"` // tenant_readable | tenant_editable | anyone_readable | anyone_editable | closed
+}
+
+// publicPermissionResp 公共设置响应
+type publicPermissionResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		PermissionPublic PublicPermission `json:"permission_public"`
+	} `json:"data"`
+}
+
+// GetPublicPermission 获取云文档公共设置
+// API: GET /open-apis/drive/v2/permissions/:token/public?type=docx
+func (c *Client) GetPublicPermission(ctx context.Context, accessToken, docToken, docType string) (PublicPermission, error) {
+	url := fmt.Sprintf("%s/drive/v2/permissions/%s/public?type=%s", c.apiBase(), docToken, docType)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return PublicPermission{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return PublicPermission{}, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu get public permission")
+	if err != nil {
+		return PublicPermission{}, err
+	}
+	var result publicPermissionResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return PublicPermission{}, fmt.Errorf("feishu get public permission parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return PublicPermission{}, fmt.Errorf("feishu get public permission: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.PermissionPublic, nil
+}
+
+// UpdatePublicPermission 更新云文档公共设置，只提交非空字段
+// API: PATCH /open-apis/drive/v2/permissions/:token/public?type=docx
+func (c *Client) UpdatePublicPermission(ctx context.Context, accessToken, docToken, docType string, update PublicPermission) error {
+	url := fmt.Sprintf("%s/drive/v2/permissions/%s/public?type=%s", c.apiBase(), docToken, docType)
+	data, _ := json.Marshal(update)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu update public permission")
+	if err != nil {
+		return err
+	}
+	var result publicPermissionResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("feishu update public permission parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu update public permission: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+// TransferOwnerOptions 转移所有者时对原所有者的处理
+type TransferOwnerOptions struct {
+	RemoveOldOwner bool   // 是否移除原所有者的权限
+	OldOwnerPerm   string // 保留原所有者时的权限：view | edit | full_access，默认 full_access
+}
+
+// TransferOwner 转移云文档所有者，newOwner 只需 MemberType 与 MemberID
+// API: POST /open-apis/drive/v1/permissions/:token/members/transfer_owner?type=docx&need_notification=true
+func (c *Client) TransferOwner(ctx context.Context, accessToken, docToken, docType string, newOwner PermissionMember, opts TransferOwnerOptions) error {
+	oldOwnerPerm := opts.OldOwnerPerm
+	if oldOwnerPerm == "" {
+		oldOwnerPerm = "full_access"
+	}
+	url := fmt.Sprintf("%s/drive/v1/permissions/%s/members/transfer_owner?type=%s&need_notification=true&remove_old_owner=%t&old_owner_perm=%s",
+		c.apiBase(), docToken, docType, opts.RemoveOldOwner, oldOwnerPerm)
+	data, _ := json.Marshal(map[string]string{
+		"member_type": newOwner.MemberType,
+		"member_id":   newOwner.MemberID,
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu transfer owner")
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("feishu transfer owner parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("feishu transfer owner: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return nil
+}
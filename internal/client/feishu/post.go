@@ -0,0 +1,181 @@
+package feishu
+
+import (
+	"regexp"
+	"strings"
+)
+
+// post 富文本：大模型与用户给出的正文常带 Markdown 习惯写法，发送前转换为飞书 post 的段落与节点：
+// 每行一段，链接与 [文字](链接) 为 a 节点，**粗体**、*斜体*（***两者***）为带 style 的 text 节点；
+// 不成对或两侧是空格的标记（如 "3 * 4"）原样保留，链接中的 * 不作为标记
+
+// mdLinkRE Markdown 链接 [文字](链接)
+var mdLinkRE = regexp.MustCompile(`\[([^\[\]\n]+)\]\((https?://[^\s()]+)\)`)
+
+// postLink 一行中的链接
+type postLink struct {
+	start, end int
+	text, href string
+}
+
+// postParagraphs 把正文转换为 post 的段落；首尾空行去掉，中间的空行保留为空段
+func postParagraphs(text string) [][]any {
+	text = strings.Trim(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	paragraphs := make([][]any, 0, len(lines))
+	for _, line := range lines {
+		nodes := postLine(line)
+		if len(nodes) == 0 {
+			nodes = []any{map[string]any{"tag": "text", "text": ""}}
+		}
+		paragraphs = append(paragraphs, nodes)
+	}
+	return paragraphs
+}
+
+// postLine 把一行转换为 post 节点
+func postLine(line string) []any {
+	links := lineLinks(line)
+	markers := styleMarkers(line, links)
+	var nodes []any
+	var bold, italic bool
+	var buf strings.Builder
+	flush := func() {
+		if buf.Len() > 0 {
+			nodes = append(nodes, styled(map[string]any{"tag": "text", "text": buf.String()}, bold, italic))
+			buf.Reset()
+		}
+	}
+	for i, li := 0, 0; i < len(line); {
+		if li < len(links) && i == links[li].start {
+			flush()
+			l := links[li]
+			nodes = append(nodes, styled(map[string]any{"tag": "a", "text": l.text, "href": l.href}, bold, italic))
+			i, li = l.end, li+1
+			continue
+		}
+		if n, ok := markers[i]; ok {
+			flush()
+			if n == 2 {
+				bold = !bold
+			} else {
+				italic = !italic
+			}
+			i += n
+			continue
+		}
+		buf.WriteByte(line[i])
+		i++
+	}
+	flush()
+	return nodes
+}
+
+func styled(node map[string]any, bold, italic bool) map[string]any {
+	var style []string
+	if bold {
+		style = append(style, "bold")
+	}
+	if italic {
+		style = append(style, "italic")
+	}
+	if len(style) > 0 {
+		node["style"] = style
+	}
+	return node
+}
+
+// lineLinks 一行中的 Markdown 链接与裸链接，按位置排序且互不重叠
+func lineLinks(line string) []postLink {
+	var links []postLink
+	for _, m := range mdLinkRE.FindAllStringSubmatchIndex(line, -1) {
+		links = append(links, postLink{start: m[0], end: m[1], text: line[m[2]:m[3]], href: line[m[4]:m[5]]})
+	}
+	var out []postLink
+	next := 0
+	for _, sp := range urlSpans(line) {
+		// 跳过 Markdown 链接内部的链接
+		for next < len(links) && links[next].end <= sp[0] {
+			out = append(out, links[next])
+			next++
+		}
+		if next < len(links) && sp[0] >= links[next].start {
+			continue
+		}
+		u := line[sp[0]:sp[1]]
+		out = append(out, postLink{start: sp[0], end: sp[1], text: u, href: u})
+	}
+	return append(out, links[next:]...)
+}
+
+// styleMarkers 成对的样式标记：位置 -> 标记长度（2 为粗体，1 为斜体）。
+// 开始标记后面、结束标记前面须紧跟非空白字符，链接内的 * 不计
+func styleMarkers(line string, links []postLink) map[int]int {
+	type run struct{ pos, n int }
+	var runs []run
+	li := 0
+	for i := 0; i < len(line); {
+		if li < len(links) && i == links[li].start {
+			i, li = links[li].end, li+1
+			continue
+		}
+		if line[i] != '*' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(line) && line[j] == '*' {
+			j++
+		}
+		switch j - i {
+		case 1, 2:
+			runs = append(runs, run{i, j - i})
+		case 3: // ***粗斜体***：拆成粗体与斜体两个标记
+			runs = append(runs, run{i, 2}, run{i + 2, 1})
+		}
+		i = j
+	}
+
+	markers := make(map[int]int)
+	open := map[int]int{1: -1, 2: -1} // 标记长度 -> 待配对的开始标记位置
+	for _, r := range runs {
+		end := r.pos + r.n
+		// 与 *** 拆出的标记相邻时，以整段 *** 两侧的字符判断
+		for end < len(line) && line[end] == '*' {
+			end++
+		}
+		start := r.pos
+		for start > 0 && line[start-1] == '*' {
+			start--
+		}
+		canOpen := end < len(line) && !isSpace(line[end])
+		canClose := start > 0 && !isSpace(line[start-1])
+		if o := open[r.n]; o >= 0 && canClose {
+			markers[o], markers[r.pos] = r.n, r.n
+			open[r.n] = -1
+		} else if canOpen {
+			open[r.n] = r.pos
+		}
+	}
+	return markers
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// postLinks 段落中全部 a 节点的链接
+func postLinks(paragraphs [][]any) []string {
+	var hrefs []string
+	for _, p := range paragraphs {
+		for _, n := range p {
+			if node, ok := n.(map[string]any); ok && node["tag"] == "a" {
+				hrefs = append(hrefs, node["href"].(string))
+			}
+		}
+	}
+	return hrefs
+}
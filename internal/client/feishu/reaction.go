@@ -0,0 +1,50 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// addReactionResp 添加表情回复响应
+type addReactionResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		ReactionID string `json:"reaction_id"`
+	} `json:"data"`
+}
+
+// AddReaction 给消息添加表情回复，emojiType 为飞书表情类型（如 THUMBSUP），返回 reaction_id
+// API: POST /open-apis/im/v1/messages/:message_id/reactions
+func (c *Client) AddReaction(ctx context.Context, token, messageID, emojiType string) (string, error) {
+	url := fmt.Sprintf("%s/im/v1/messages/%s/reactions", c.apiBase(), messageID)
+	body := map[string]any{
+		"reaction_type": map[string]string{"emoji_type": emojiType},
+	}
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu add reaction")
+	if err != nil {
+		return "", err
+	}
+	var result addReactionResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("feishu add reaction parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("feishu add reaction: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.ReactionID, nil
+}
@@ -0,0 +1,117 @@
+package feishu
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 开放平台区域：飞书（中国）与 Lark（国际）的接口域名与文档域名不同
+const (
+	RegionAuto   = "auto" // 默认：按 Domain 判断，无法判断时用应用凭证分别试换 tenant_access_token
+	RegionFeishu = "feishu"
+	RegionLark   = "lark"
+)
+
+const (
+	feishuAPIBase = "https://open.feishu.cn/open-apis"
+	larkAPIBase   = "https://open.larksuite.com/open-apis"
+)
+
+// initBase 按配置确定接口域名；返回空串表示需在首次鉴权时探测
+func initBase(cfg Config) string {
+	if cfg.APIBase != "" {
+		return strings.TrimRight(cfg.APIBase, "/")
+	}
+	switch cfg.Region {
+	case RegionFeishu:
+		return feishuAPIBase
+	case RegionLark:
+		return larkAPIBase
+	}
+	domain := strings.ToLower(cfg.Domain)
+	switch {
+	case strings.HasSuffix(domain, "larksuite.com"):
+		return larkAPIBase
+	case strings.HasSuffix(domain, "feishu.cn"):
+		return feishuAPIBase
+	}
+	return ""
+}
+
+// apiBase 开放接口地址前缀；区域尚未探测出时按飞书处理
+func (c *Client) apiBase() string {
+	if base, ok := c.resolvedBase(); ok {
+		return base
+	}
+	return feishuAPIBase
+}
+
+func (c *Client) resolvedBase() (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.base, c.base != ""
+}
+
+func (c *Client) setBase(base string) {
+	c.mu.Lock()
+	c.base = base
+	c.mu.Unlock()
+}
+
+func (c *Client) authPath() string {
+	if c.cfg.AuthPath != "" {
+		return c.cfg.AuthPath
+	}
+	return "/auth/v3/tenant_access_token/internal"
+}
+
+// Region 当前使用的区域（探测前或私有化部署时为 RegionAuto）
+func (c *Client) Region() string {
+	switch base, _ := c.resolvedBase(); base {
+	case feishuAPIBase:
+		return RegionFeishu
+	case larkAPIBase:
+		return RegionLark
+	}
+	return RegionAuto
+}
+
+// DocURL 生成云文档链接，如 https://example.feishu.cn/docx/AbCd；path 为链接路径段（docx、sheets、drive/folder 等）。
+// 按 DocURLTemplate 生成；未配置模板与 Domain 时返回空串
+func (c *Client) DocURL(path, token string) string {
+	if token == "" {
+		return ""
+	}
+	if c.cfg.DocURLTemplate != "" {
+		return strings.NewReplacer("{{domain}}", c.cfg.Domain, "{{path}}", path, "{{token}}", token).Replace(c.cfg.DocURLTemplate)
+	}
+	if c.cfg.Domain == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/%s/%s", c.cfg.Domain, path, token)
+}
+
+// headerTransport 为每个请求加上私有化网关要求的请求头
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func withHeaders(base http.RoundTripper, headers map[string]string) http.RoundTripper {
+	if len(headers) == 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &headerTransport{base: base, headers: headers}
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
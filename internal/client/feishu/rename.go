@@ -0,0 +1,55 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// RenameFile 修改云文档名称：docx 改标题块，电子表格与多维表格改表格名称；
+// 开放接口不支持修改文件夹、上传文件等其他类型的名称
+func (c *Client) RenameFile(ctx context.Context, token, fileToken, fileType, name string) error {
+	switch fileType {
+	case "docx":
+		return c.RenameDoc(ctx, token, fileToken, name)
+	case "sheet":
+		// API: PATCH /open-apis/sheets/v3/spreadsheets/:spreadsheet_token
+		return c.renameCall(ctx, token, http.MethodPatch, fmt.Sprintf("%s/sheets/v3/spreadsheets/%s", c.apiBase(), fileToken), map[string]string{"title": name}, "feishu rename sheet")
+	case "bitable":
+		// API: PUT /open-apis/bitable/v1/apps/:app_token
+		return c.renameCall(ctx, token, http.MethodPut, fmt.Sprintf("%s/bitable/v1/apps/%s", c.apiBase(), fileToken), map[string]string{"name": name}, "feishu rename bitable")
+	default:
+		return fmt.Errorf("feishu rename: %s is not supported by the open api", fileType)
+	}
+}
+
+func (c *Client) renameCall(ctx context.Context, token, method, url string, body any, apiName string) error {
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, apiName)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("%s parse response: %w, body: %s", apiName, err, string(b))
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("%s: code=%d msg=%s", apiName, result.Code, result.Msg)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SystemStatus 租户配置的系统状态（如请假、出差、会议中），由管理员在后台创建
+type SystemStatus struct {
+	ID    string `json:"system_status_id"`
+	Title string `json:"title"`
+}
+
+// listSystemStatusesResp 系统状态列表响应
+type listSystemStatusesResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Items []SystemStatus `json:"items"`
+	} `json:"data"`
+}
+
+// ListSystemStatuses 获取租户的系统状态列表
+// API: GET /open-apis/personal_settings/v1/system_statuses
+func (c *Client) ListSystemStatuses(ctx context.Context, token string) ([]SystemStatus, error) {
+	url := c.apiBase() + "/personal_settings/v1/system_statuses?page_size=50"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu list system statuses")
+	if err != nil {
+		return nil, err
+	}
+	var result listSystemStatusesResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("feishu list system statuses parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("feishu list system statuses: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.Items, nil
+}
+
+// systemStatusResultResp 批量开启/关闭系统状态响应
+type systemStatusResultResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		ResultList []struct {
+			UserID string `json:"user_id"`
+			Result string `json:"result"` // success_show | success_user_close_syn | success_user_in_higher_priority_system_status | fail
+		} `json:"result_list"`
+	} `json:"data"`
+}
+
+// OpenSystemStatus 为用户开启系统状态，到 endTime 自动失效
+// API: POST /open-apis/personal_settings/v1/system_statuses/:system_status_id/batch_open
+func (c *Client) OpenSystemStatus(ctx context.Context, token, statusID, openID string, endTime time.Time) error {
+	url := fmt.Sprintf("%s/personal_settings/v1/system_statuses/%s/batch_open?user_id_type=open_id", c.apiBase(), statusID)
+	data, _ := json.Marshal(map[string]any{
+		"user_list": []map[string]string{{"user_id": openID, "end_time": strconv.FormatInt(endTime.Unix(), 10)}},
+	})
+	return c.systemStatusRequest(ctx, token, url, data, "feishu open system status")
+}
+
+// CloseSystemStatus 关闭用户的系统状态
+// API: POST /open-apis/personal_settings/v1/system_statuses/:system_status_id/batch_close
+func (c *Client) CloseSystemStatus(ctx context.Context, token, statusID, openID string) error {
+	url := fmt.Sprintf("%s/personal_settings/v1/system_statuses/%s/batch_close?user_id_type=open_id", c.apiBase(), statusID)
+	data, _ := json.Marshal(map[string]any{"user_list": []string{openID}})
+	return c.systemStatusRequest(ctx, token, url, data, "feishu close system status")
+}
+
+func (c *Client) systemStatusRequest(ctx context.Context, token, url string, data []byte, apiName string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, apiName)
+	if err != nil {
+		return err
+	}
+	var result systemStatusResultResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("%s parse response: %w, body: %s", apiName, err, string(b))
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("%s: code=%d msg=%s", apiName, result.Code, result.Msg)
+	}
+	for _, r := range result.Data.ResultList {
+		if r.Result == "fail" {
+			return fmt.Errorf("%s: failed for %s", apiName, r.UserID)
+		}
+	}
+	return nil
+}
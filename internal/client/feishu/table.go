@@ -0,0 +1,215 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// TableSource 配置的可查询数据表，如 "销售台账" → 电子表格或多维表格链接
+type TableSource struct {
+	Name        string
+	URL         string // 电子表格链接可带 ?sheet=xxx，多维表格链接可带 ?table=tblxxx
+	Description string // 表格内容说明，帮助大模型理解各列含义
+}
+
+// ParseTableURL 解析电子表格/多维表格链接，返回 token、类型（sheet | bitable）及链接中指定的工作表或数据表 ID
+func ParseTableURL(tableURL string) (token, tableType, subID string, err error) {
+	token, tableType, err = ParseDocURL(tableURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	if tableType != "sheet" && tableType != "bitable" {
+		return "", "", "", fmt.Errorf("not a sheet or bitable url: %s", tableURL)
+	}
+	if u, err := url.Parse(tableURL); err == nil {
+		if tableType == "sheet" {
+			subID = u.Query().Get("sheet")
+		} else {
+			subID = u.Query().Get("table")
+		}
+	}
+	return token, tableType, subID, nil
+}
+
+// SheetInfo 电子表格中的工作表
+type SheetInfo struct {
+	SheetID string `json:"sheet_id"`
+	Title   string `json:"title"`
+}
+
+// listSheetsResp 工作表列表响应
+type listSheetsResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Sheets []SheetInfo `json:"sheets"`
+	} `json:"data"`
+}
+
+// ListSheets 获取电子表格的工作表列表
+// API: GET /open-apis/sheets/v3/spreadsheets/:spreadsheet_token/sheets/query
+func (c *Client) ListSheets(ctx context.Context, token, spreadsheetToken string) ([]SheetInfo, error) {
+	url := fmt.Sprintf("%s/sheets/v3/spreadsheets/%s/sheets/query", c.apiBase(), spreadsheetToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu list sheets")
+	if err != nil {
+		return nil, err
+	}
+	var result listSheetsResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("feishu list sheets parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("feishu list sheets: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.Sheets, nil
+}
+
+// sheetValuesResp 读取单个范围响应
+type sheetValuesResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		ValueRange struct {
+			Values [][]any `json:"values"`
+		} `json:"valueRange"`
+	} `json:"data"`
+}
+
+// ReadSheetValues 读取工作表范围内的单元格，rng 形如 "sheetId" 或 "sheetId!A1:H500"，公式返回计算结果
+// API: GET /open-apis/sheets/v2/spreadsheets/:spreadsheet_token/values/:range?valueRenderOption=ToString
+func (c *Client) ReadSheetValues(ctx context.Context, token, spreadsheetToken, rng string) ([][]any, error) {
+	u := fmt.Sprintf("%s/sheets/v2/spreadsheets/%s/values/%s?valueRenderOption=ToString&dateTimeRenderOption=FormattedString",
+		c.apiBase(), spreadsheetToken, url.PathEscape(rng))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu read sheet values")
+	if err != nil {
+		return nil, err
+	}
+	var result sheetValuesResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("feishu read sheet values parse response: %w, body: %.500s", err, string(b))
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("feishu read sheet values: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.ValueRange.Values, nil
+}
+
+// BitableTable 多维表格中的数据表
+type BitableTable struct {
+	TableID string `json:"table_id"`
+	Name    string `json:"name"`
+}
+
+// listBitableTablesResp 数据表列表响应
+type listBitableTablesResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Items []BitableTable `json:"items"`
+	} `json:"data"`
+}
+
+// ListBitableTables 获取多维表格的数据表列表
+// API: GET /open-apis/bitable/v1/apps/:app_token/tables
+func (c *Client) ListBitableTables(ctx context.Context, token, appToken string) ([]BitableTable, error) {
+	url := fmt.Sprintf("%s/bitable/v1/apps/%s/tables?page_size=100", c.apiBase(), appToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu list bitable tables")
+	if err != nil {
+		return nil, err
+	}
+	var result listBitableTablesResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("feishu list bitable tables parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("feishu list bitable tables: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return result.Data.Items, nil
+}
+
+// listBitableRecordsResp 记录列表响应
+type listBitableRecordsResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Items []struct {
+			Fields map[string]any `json:"fields"`
+		} `json:"items"`
+		PageToken string `json:"page_token"`
+		HasMore   bool   `json:"has_more"`
+	} `json:"data"`
+}
+
+// ListBitableRecords 读取数据表记录（每条为 字段名 → 值），最多 limit 条
+// API: GET /open-apis/bitable/v1/apps/:app_token/tables/:table_id/records
+func (c *Client) ListBitableRecords(ctx context.Context, token, appToken, tableID string, limit int) ([]map[string]any, error) {
+	var records []map[string]any
+	pageToken := ""
+	for len(records) < limit {
+		u := fmt.Sprintf("%s/bitable/v1/apps/%s/tables/%s/records?page_size=500&automatic_fields=true", c.apiBase(), appToken, tableID)
+		if pageToken != "" {
+			u += "&page_token=" + url.QueryEscape(pageToken)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		b, err := c.checkHTTPStatus(resp, "feishu list bitable records")
+		if err != nil {
+			return nil, err
+		}
+		var result listBitableRecordsResp
+		if err := json.Unmarshal(b, &result); err != nil {
+			return nil, fmt.Errorf("feishu list bitable records parse response: %w, body: %.500s", err, string(b))
+		}
+		if result.Code != 0 {
+			return nil, fmt.Errorf("feishu list bitable records: code=%d msg=%s", result.Code, result.Msg)
+		}
+		for _, item := range result.Data.Items {
+			records = append(records, item.Fields)
+		}
+		if !result.Data.HasMore || result.Data.PageToken == "" {
+			break
+		}
+		pageToken = result.Data.PageToken
+	}
+	if len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
@@ -0,0 +1,151 @@
+package feishu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// createTaskResp 创建任务响应：https://open.feishu.cn/document/task-v2/task/create
+type createTaskResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Task struct {
+			GUID    string `json:"guid"`
+			URL     string `json:"url"`
+			Summary string `json:"summary"`
+		} `json:"task"`
+	} `json:"data"`
+}
+
+// TaskInfo 已创建的飞书任务
+type TaskInfo struct {
+	GUID string
+	URL  string
+}
+
+// CreateTask 创建飞书任务
+// API: POST /open-apis/task/v2/tasks
+func (c *Client) CreateTask(ctx context.Context, token, summary, description string) (TaskInfo, error) {
+	url := c.apiBase() + "/task/v2/tasks"
+	reqBody := map[string]any{"summary": summary}
+	if description != "" {
+		reqBody["description"] = description
+	}
+	data, _ := json.Marshal(reqBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return TaskInfo{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return TaskInfo{}, err
+	}
+	b, err := c.checkHTTPStatus(resp, "feishu create task")
+	if err != nil {
+		return TaskInfo{}, err
+	}
+	var result createTaskResp
+	if err := json.Unmarshal(b, &result); err != nil {
+		return TaskInfo{}, fmt.Errorf("feishu create task parse response: %w, body: %s", err, string(b))
+	}
+	if result.Code != 0 {
+		return TaskInfo{}, fmt.Errorf("feishu create task: code=%d msg=%s", result.Code, result.Msg)
+	}
+	return TaskInfo{GUID: result.Data.Task.GUID, URL: result.Data.Task.URL}, nil
+}
+
+// TaskItem 任务列表中的任务
+type TaskItem struct {
+	GUID      string
+	Summary   string
+	URL       string
+	Due       time.Time // 零值表示未设置截止时间
+	Completed bool
+	MemberIDs []string // 负责人与关注人的 ID
+}
+
+// listTasksResp 任务列表响应：https://open.feishu.cn/document/task-v2/task/list
+type listTasksResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Items []struct {
+			GUID    string `json:"guid"`
+			Summary string `json:"summary"`
+			URL     string `json:"url"`
+			Due     struct {
+				Timestamp string `json:"timestamp"` // 毫秒时间戳
+			} `json:"due"`
+			CompletedAt string `json:"completed_at"` // 未完成为 "0"
+			Members     []struct {
+				ID string `json:"id"`
+			} `json:"members"`
+		} `json:"items"`
+		PageToken string `json:"page_token"`
+		HasMore   bool   `json:"has_more"`
+	} `json:"data"`
+}
+
+// ListTasks 列出当前凭证可见的未完成任务，最多 limit 条
+// API: GET /open-apis/task/v2/tasks?completed=false
+func (c *Client) ListTasks(ctx context.Context, token string, limit int) ([]TaskItem, error) {
+	var tasks []TaskItem
+	pageToken := ""
+	for len(tasks) < limit {
+		url := c.apiBase() + "/task/v2/tasks?completed=false&page_size=100&user_id_type=open_id"
+		if pageToken != "" {
+			url += "&page_token=" + pageToken
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		b, err := c.checkHTTPStatus(resp, "feishu list tasks")
+		if err != nil {
+			return nil, err
+		}
+		var result listTasksResp
+		if err := json.Unmarshal(b, &result); err != nil {
+			return nil, fmt.Errorf("feishu list tasks parse response: %w, body: %.500s", err, string(b))
+		}
+		if result.Code != 0 {
+			return nil, fmt.Errorf("feishu list tasks: code=%d msg=%s", result.Code, result.Msg)
+		}
+		for _, item := range result.Data.Items {
+			task := TaskItem{
+				GUID:      item.GUID,
+				Summary:   item.Summary,
+				URL:       item.URL,
+				Completed: item.CompletedAt != "" && item.CompletedAt != "0",
+			}
+			if ms, err := strconv.ParseInt(item.Due.Timestamp, 10, 64); err == nil && ms > 0 {
+				task.Due = time.UnixMilli(ms)
+			}
+			for _, m := range item.Members {
+				task.MemberIDs = append(task.MemberIDs, m.ID)
+			}
+			tasks = append(tasks, task)
+		}
+		if !result.Data.HasMore || result.Data.PageToken == "" {
+			break
+		}
+		pageToken = result.Data.PageToken
+	}
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
@@ -0,0 +1,92 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// WikiNode 知识库节点，ObjToken/ObjType 为节点对应的云文档
+type WikiNode struct {
+	SpaceID   string `json:"space_id"`
+	NodeToken string `json:"node_token"`
+	ObjToken  string `json:"obj_token"`
+	ObjType   string `json:"obj_type"` // docx | doc | sheet | bitable | file 等
+	Title     string `json:"title"`
+	HasChild  bool   `json:"has_child"`
+}
+
+// GetWikiNode 获取知识库节点信息（含节点对应的云文档 token 与类型）
+// API: GET /open-apis/wiki/v2/spaces/get_node?token=xxx
+func (c *Client) GetWikiNode(ctx context.Context, token, nodeToken string) (WikiNode, error) {
+	var data struct {
+		Node WikiNode `json:"node"`
+	}
+	if err := c.wikiGet(ctx, token, "/wiki/v2/spaces/get_node?token="+url.QueryEscape(nodeToken), &data, "feishu get wiki node"); err != nil {
+		return WikiNode{}, err
+	}
+	return data.Node, nil
+}
+
+// ListWikiNodes 列出知识空间中某节点的子节点，parentNodeToken 为空时列出顶层节点
+// API: GET /open-apis/wiki/v2/spaces/:space_id/nodes
+func (c *Client) ListWikiNodes(ctx context.Context, token, spaceID, parentNodeToken string) ([]WikiNode, error) {
+	var nodes []WikiNode
+	pageToken := ""
+	for {
+		q := url.Values{"page_size": {"50"}}
+		if parentNodeToken != "" {
+			q.Set("parent_node_token", parentNodeToken)
+		}
+		if pageToken != "" {
+			q.Set("page_token", pageToken)
+		}
+		var data struct {
+			Items     []WikiNode `json:"items"`
+			PageToken string     `json:"page_token"`
+			HasMore   bool       `json:"has_more"`
+		}
+		path := fmt.Sprintf("/wiki/v2/spaces/%s/nodes?%s", url.PathEscape(spaceID), q.Encode())
+		if err := c.wikiGet(ctx, token, path, &data, "feishu list wiki nodes"); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, data.Items...)
+		if !data.HasMore || data.PageToken == "" {
+			return nodes, nil
+		}
+		pageToken = data.PageToken
+	}
+}
+
+func (c *Client) wikiGet(ctx context.Context, token, path string, data any, apiName string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiBase()+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	b, err := c.checkHTTPStatus(resp, apiName)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Code int             `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return fmt.Errorf("%s parse response: %w, body: %s", apiName, err, string(b))
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("%s: code=%d msg=%s", apiName, result.Code, result.Msg)
+	}
+	if err := json.Unmarshal(result.Data, data); err != nil {
+		return fmt.Errorf("%s parse data: %w", apiName, err)
+	}
+	return nil
+}
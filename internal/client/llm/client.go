@@ -6,34 +6,56 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"sync"
 )
 
 // Config LLM 客户端配置
 type Config struct {
-	APIKey  string
-	BaseURL string
-	Model   string
+	APIKey      string
+	BaseURL     string
+	Model       string
+	VisionModel string // 识图使用的模型，为空时使用 Model
+	// EmbeddingModel 文本向量化使用的模型，为空时使用 text-embedding-3-small
+	EmbeddingModel string
+	// TranscribeModel 语音转写使用的模型，为空时使用 whisper-1
+	TranscribeModel string
+	// Transport 共享的出站连接池，为 nil 时使用 http.DefaultTransport
+	Transport http.RoundTripper
 }
 
 // Client 大模型客户端（OpenAI 兼容接口）
 type Client struct {
 	cfg    Config
 	client *http.Client
+
+	mu     sync.RWMutex
+	apiKey string
 }
 
 // NewClient 创建 LLM 客户端
 func NewClient(cfg Config) *Client {
 	return &Client{
 		cfg:    cfg,
-		client: &http.Client{},
+		client: &http.Client{Transport: cfg.Transport},
+		apiKey: cfg.APIKey,
 	}
 }
 
+// SetAPIKey 替换 API 密钥（密钥轮换），后续请求生效
+func (c *Client) SetAPIKey(key string) {
+	c.mu.Lock()
+	c.apiKey = key
+	c.mu.Unlock()
+}
+
 // ChatRequest 聊天请求（OpenAI 兼容）
 type ChatRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
 }
 
 type Message struct {
@@ -46,18 +68,83 @@ type ChatResponse struct {
 	Choices []struct {
 		Message Message `json:"message"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
 }
 
-// Chat 发送对话请求，返回大模型回复文本
+// Chat 发送对话请求，返回大模型回复文本；ctx 中挂有 Options 时按其覆盖模型、温度与 token 上限
 func (c *Client) Chat(ctx context.Context, systemPrompt, userContent string) (string, error) {
-	url := c.cfg.BaseURL + "/chat/completions"
-	reqBody := ChatRequest{
-		Model: c.cfg.Model,
+	opts := OptionsFrom(ctx)
+	model := c.cfg.Model
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	return c.chat(ctx, ChatRequest{
+		Model: model,
 		Messages: []Message{
 			{Role: "system", Content: systemPrompt},
 			{Role: "user", Content: userContent},
 		},
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+	})
+}
+
+// Image 识图输入：URL 为 http(s) 链接或 data URL（data:image/png;base64,...）
+type Image struct {
+	URL string
+}
+
+// visionRequest 多模态聊天请求，user 消息的 content 为文本与图片片段
+type visionRequest struct {
+	Model       string          `json:"model"`
+	Messages    []visionMessage `json:"messages"`
+	Temperature *float64        `json:"temperature,omitempty"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+}
+
+type visionMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type contentPart struct {
+	Type     string    `json:"type"` // text | image_url
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+// ChatWithImages 发送带图片的对话请求，使用 VisionModel（不受 Options.Model 影响）
+func (c *Client) ChatWithImages(ctx context.Context, systemPrompt, userText string, images []Image) (string, error) {
+	model := c.cfg.VisionModel
+	if model == "" {
+		model = c.cfg.Model
 	}
+	parts := []contentPart{{Type: "text", Text: userText}}
+	for _, img := range images {
+		parts = append(parts, contentPart{Type: "image_url", ImageURL: &imageURL{URL: img.URL}})
+	}
+	opts := OptionsFrom(ctx)
+	return c.chat(ctx, visionRequest{
+		Model: model,
+		Messages: []visionMessage{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: parts},
+		},
+		Temperature: opts.Temperature,
+		MaxTokens:   opts.MaxTokens,
+	})
+}
+
+// chat 调用 /chat/completions，返回首个回复文本
+func (c *Client) chat(ctx context.Context, reqBody any) (string, error) {
+	url := c.cfg.BaseURL + "/chat/completions"
 	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("marshal request: %w", err)
@@ -67,7 +154,9 @@ func (c *Client) Chat(ctx context.Context, systemPrompt, userContent string) (st
 		return "", fmt.Errorf("new request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	c.mu.RLock()
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	c.mu.RUnlock()
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("do request: %w", err)
@@ -84,8 +173,121 @@ func (c *Client) Chat(ctx context.Context, systemPrompt, userContent string) (st
 	if err := json.Unmarshal(data, &chatResp); err != nil {
 		return "", fmt.Errorf("unmarshal response: %w", err)
 	}
+	UsageFrom(ctx).add(chatResp.Usage.PromptTokens, chatResp.Usage.CompletionTokens)
 	if len(chatResp.Choices) == 0 {
 		return "", fmt.Errorf("empty choices")
 	}
 	return chatResp.Choices[0].Message.Content, nil
 }
+
+// defaultEmbeddingModel 未配置 EmbeddingModel 时使用的向量模型
+const defaultEmbeddingModel = "text-embedding-3-small"
+
+// Embed 把一组文本转为向量（POST /embeddings），返回的向量与 texts 一一对应
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	model := c.cfg.EmbeddingModel
+	if model == "" {
+		model = defaultEmbeddingModel
+	}
+	body, err := json.Marshal(map[string]any{"model": model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.mu.RLock()
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	c.mu.RUnlock()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("llm api error: %s %s", resp.Status, string(data))
+	}
+	var embedResp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage struct {
+			PromptTokens int `json:"prompt_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &embedResp); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	UsageFrom(ctx).add(embedResp.Usage.PromptTokens, 0)
+	vectors := make([][]float32, len(texts))
+	for _, d := range embedResp.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("missing embedding for input %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+// defaultTranscribeModel 未配置 TranscribeModel 时使用的语音转写模型
+const defaultTranscribeModel = "whisper-1"
+
+// Transcribe 把音频转写为文本（POST /audio/transcriptions），fileName 的扩展名用于判断音频格式
+func (c *Client) Transcribe(ctx context.Context, fileName string, audio []byte) (string, error) {
+	model := c.cfg.TranscribeModel
+	if model == "" {
+		model = defaultTranscribeModel
+	}
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	_ = w.WriteField("model", model)
+	_ = w.WriteField("response_format", "json")
+	part, err := w.CreateFormFile("file", fileName)
+	if err != nil {
+		return "", fmt.Errorf("new form file: %w", err)
+	}
+	if _, err := part.Write(audio); err != nil {
+		return "", fmt.Errorf("write form file: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("close form: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	c.mu.RLock()
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	c.mu.RUnlock()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("llm api error: %s %s", resp.Status, string(data))
+	}
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("unmarshal response: %w", err)
+	}
+	return result.Text, nil
+}
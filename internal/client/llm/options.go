@@ -0,0 +1,26 @@
+package llm
+
+import "context"
+
+// Options 单次请求的大模型参数覆盖，经 ctx 作用于该请求内的全部对话调用（规划、参数提取、正文生成等），不影响向量化
+type Options struct {
+	// Model 对话模型，为空时使用配置的模型；识图仍使用 VisionModel
+	Model string
+	// Temperature 采样温度，nil 时使用服务端默认值
+	Temperature *float64
+	// MaxTokens 单次回复的 token 上限，0 表示不限制
+	MaxTokens int
+}
+
+type optionsKey struct{}
+
+// WithOptions 在 ctx 中挂上参数覆盖；o 为零值时清除上层挂的覆盖
+func WithOptions(ctx context.Context, o Options) context.Context {
+	return context.WithValue(ctx, optionsKey{}, o)
+}
+
+// OptionsFrom 读取 ctx 中的参数覆盖，没有时返回零值
+func OptionsFrom(ctx context.Context) Options {
+	o, _ := ctx.Value(optionsKey{}).(Options)
+	return o
+}
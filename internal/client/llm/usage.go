@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// Usage 一次请求内大模型调用的累计用量；请求的各阶段（规划、参数提取、识图等）并发调用时共用
+type Usage struct {
+	mu               sync.Mutex
+	calls            int
+	promptTokens     int
+	completionTokens int
+}
+
+// UsageTotals 用量快照
+type UsageTotals struct {
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+}
+
+type usageKey struct{}
+
+// WithUsage 在 ctx 中挂上用量计数，之后经该 ctx 的调用都累计到返回的 Usage；已挂有计数时沿用
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	if u := UsageFrom(ctx); u != nil {
+		return ctx, u
+	}
+	u := &Usage{}
+	return context.WithValue(ctx, usageKey{}, u), u
+}
+
+// UsageFrom 读取 ctx 中的用量计数，没有时返回 nil
+func UsageFrom(ctx context.Context) *Usage {
+	u, _ := ctx.Value(usageKey{}).(*Usage)
+	return u
+}
+
+func (u *Usage) add(prompt, completion int) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	u.calls++
+	u.promptTokens += prompt
+	u.completionTokens += completion
+	u.mu.Unlock()
+}
+
+// Totals 当前累计的用量；Usage 为 nil 时返回零值
+func (u *Usage) Totals() UsageTotals {
+	if u == nil {
+		return UsageTotals{}
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return UsageTotals{Calls: u.calls, PromptTokens: u.promptTokens, CompletionTokens: u.completionTokens}
+}
@@ -0,0 +1,71 @@
+package slack
+
+import (
+	"fmt"
+
+	"sayso-agent/internal/model"
+)
+
+// RenderBlocks 将通用卡片渲染为 Block Kit blocks
+func RenderBlocks(card model.Card) []Block {
+	var blocks []Block
+
+	title := card.Title
+	if card.Icon != "" && title != "" {
+		title = card.Icon + " " + title
+	}
+	if title != "" {
+		blocks = append(blocks, Block{
+			Type: "header",
+			Text: &Text{Type: "plain_text", Text: title},
+		})
+	}
+
+	for _, s := range card.Sections {
+		if s.Text != "" {
+			blocks = append(blocks, Block{
+				Type: "section",
+				Text: &Text{Type: "mrkdwn", Text: s.Text},
+			})
+		}
+		if len(s.Fields) > 0 {
+			var fields []Text
+			for _, f := range s.Fields {
+				fields = append(fields, Text{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%s", f.Name, f.Value)})
+			}
+			blocks = append(blocks, Block{Type: "section", Fields: fields})
+		}
+		if s.Image != nil && s.Image.URL != "" {
+			alt := s.Image.Alt
+			if alt == "" {
+				alt = "image"
+			}
+			blocks = append(blocks, Block{Type: "image", ImageURL: s.Image.URL, AltText: alt})
+		}
+	}
+
+	if len(card.Buttons) > 0 {
+		var elements []Element
+		for i, b := range card.Buttons {
+			if b.URL == "" {
+				// 回调按钮不渲染
+				continue
+			}
+			actionID := "link_button"
+			if i > 0 {
+				actionID = fmt.Sprintf("link_button_%d", i)
+			}
+			elements = append(elements, Element{
+				Type:     "button",
+				Text:     &Text{Type: "plain_text", Text: b.Text},
+				URL:      b.URL,
+				ActionID: actionID,
+			})
+		}
+		if len(elements) > 0 {
+			blocks = append(blocks, Block{Type: "actions", Elements: elements})
+		}
+	}
+
+	return blocks
+}
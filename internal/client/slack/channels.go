@@ -0,0 +1,125 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// channelListTTL 频道列表的缓存时间
+const channelListTTL = 10 * time.Minute
+
+// channelCache 各工作区的频道名 -> 频道 ID，所有派生客户端共享，按 Bot Token 区分工作区
+type channelCache struct {
+	mu    sync.Mutex
+	lists map[string]channelList
+}
+
+type channelList struct {
+	ids      map[string]string
+	loadedAt time.Time
+}
+
+// ChannelID 把 "#general" 解析为频道 ID（文件上传等接口只接受 ID）；已是 ID 时原样返回
+func (c *Client) ChannelID(ctx context.Context, channel string) (string, error) {
+	name, ok := strings.CutPrefix(channel, "#")
+	if !ok {
+		return channel, nil
+	}
+	ids, err := c.Channels(ctx)
+	if err != nil {
+		return "", err
+	}
+	id, ok := ids[strings.ToLower(name)]
+	if !ok {
+		return "", fmt.Errorf("slack channel %s not found or bot not a member", channel)
+	}
+	return id, nil
+}
+
+// Channels 当前工作区的频道名（小写）-> 频道 ID，缓存 10 分钟
+func (c *Client) Channels(ctx context.Context) (map[string]string, error) {
+	c.channels.mu.Lock()
+	list, ok := c.channels.lists[c.token]
+	c.channels.mu.Unlock()
+	if ok && time.Since(list.loadedAt) < channelListTTL {
+		return list.ids, nil
+	}
+	return c.RefreshChannels(ctx)
+}
+
+// RefreshChannels 跳过缓存重新拉取频道列表并更新缓存（后台维护任务定期调用）
+func (c *Client) RefreshChannels(ctx context.Context) (map[string]string, error) {
+	ids, err := c.listChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.channels.mu.Lock()
+	c.channels.lists[c.token] = channelList{ids: ids, loadedAt: time.Now()}
+	c.channels.mu.Unlock()
+	return ids, nil
+}
+
+// listChannels 分页拉取公开与私有频道（conversations.list）
+func (c *Client) listChannels(ctx context.Context) (map[string]string, error) {
+	ids := make(map[string]string)
+	cursor := ""
+	for {
+		form := url.Values{"types": {"public_channel,private_channel"}, "exclude_archived": {"true"}, "limit": {"1000"}}
+		if cursor != "" {
+			form.Set("cursor", cursor)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBase+"/conversations.list", strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		var result struct {
+			OK       bool   `json:"ok"`
+			Error    string `json:"error"`
+			Channels []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"channels"`
+			ResponseMetadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		_ = json.Unmarshal(b, &result)
+		if !result.OK {
+			return nil, fmt.Errorf("slack list channels: %s", result.Error)
+		}
+		for _, ch := range result.Channels {
+			ids[strings.ToLower(ch.Name)] = ch.ID
+		}
+		cursor = result.ResponseMetadata.NextCursor
+		if cursor == "" {
+			return ids, nil
+		}
+	}
+}
+
+// WorkspaceClients 默认工作区（配置了 Bot Token 时）及所有其他工作区的客户端，用于预热等按工作区遍历的场景
+func (c *Client) WorkspaceClients() []*Client {
+	var clients []*Client
+	if c.cfg.BotToken != "" {
+		clients = append(clients, c.with(c.cfg.BotToken))
+	}
+	for _, w := range c.workspaces() {
+		clients = append(clients, c.with(w.BotToken))
+	}
+	return clients
+}
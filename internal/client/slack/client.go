@@ -7,25 +7,48 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"sayso-agent/internal/model"
 )
 
 // Config Slack 客户端配置
 type Config struct {
-	BotToken string
+	BotToken string // 默认工作区的 Bot Token
 	Enabled  bool
+	// Workspaces 其他工作区（Enterprise Grid 或多个独立工作区），目标可用 "#general@emea" 指定工作区
+	Workspaces []Workspace
+	// MaxMessageChars 单条消息的字符上限，0 使用默认 40000（Block Kit 消息另限 3000）；
+	// MessageOverflow 超出时的处理：split（默认）| truncate | doc（需启用飞书）
+	MaxMessageChars int
+	MessageOverflow string
+	// ClientID、ClientSecret Slack App 凭证，用于 OAuth 安装到新工作区（Scopes 为 Bot 权限）
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// Transport 共享的出站连接池，为 nil 时使用 http.DefaultTransport
+	Transport http.RoundTripper
 }
 
 // Client Slack API 客户端
 type Client struct {
-	cfg    Config
-	client *http.Client
+	cfg       Config
+	client    *http.Client
+	token     string     // 当前工作区的 Bot Token
+	installed *installed // OAuth 安装的工作区，所有派生客户端共享
+	channels  *channelCache
 }
 
-// NewClient 创建 Slack 客户端
+// NewClient 创建 Slack 客户端，默认使用 cfg.BotToken 所在的工作区
 func NewClient(cfg Config) *Client {
 	return &Client{
-		cfg:    cfg,
-		client: &http.Client{},
+		cfg:       cfg,
+		client:    &http.Client{Transport: cfg.Transport},
+		token:     cfg.BotToken,
+		installed: &installed{},
+		channels:  &channelCache{lists: make(map[string]channelList)},
 	}
 }
 
@@ -45,6 +68,11 @@ type Block struct {
 	Accessory *Accessory `json:"accessory,omitempty"`
 	// 用于 actions block
 	Elements []Element `json:"elements,omitempty"`
+	// 用于 section 中并排显示的字段
+	Fields []Text `json:"fields,omitempty"`
+	// 用于 image block
+	ImageURL string `json:"image_url,omitempty"`
+	AltText  string `json:"alt_text,omitempty"`
 }
 
 // Text Slack 文本对象
@@ -76,15 +104,39 @@ type SendMessageResult struct {
 	Error     error
 }
 
+// Message 待发送的消息；UnfurlLinks、UnfurlMedia 控制链接与媒体预览，nil 时使用 Slack 默认行为
+type Message struct {
+	Text        string
+	Blocks      []Block
+	UnfurlLinks *bool
+	UnfurlMedia *bool
+	// ThreadTS 回复到该 thread（根消息 ts），为空时发到频道
+	ThreadTS string
+}
+
 // SendMessageWithBlocks 发送消息，支持 Block Kit
 func (c *Client) SendMessageWithBlocks(ctx context.Context, channel, text string, blocks []Block) (SendMessageResult, error) {
+	return c.PostMessage(ctx, channel, Message{Text: text, Blocks: blocks})
+}
+
+// PostMessage 发送消息（chat.postMessage），可控制链接预览
+func (c *Client) PostMessage(ctx context.Context, channel string, msg Message) (SendMessageResult, error) {
 	url := slackAPIBase + "/chat.postMessage"
 	reqBody := map[string]any{
 		"channel": channel,
-		"text":    text,
+		"text":    msg.Text,
+	}
+	if len(msg.Blocks) > 0 {
+		reqBody["blocks"] = msg.Blocks
 	}
-	if len(blocks) > 0 {
-		reqBody["blocks"] = blocks
+	if msg.UnfurlLinks != nil {
+		reqBody["unfurl_links"] = *msg.UnfurlLinks
+	}
+	if msg.UnfurlMedia != nil {
+		reqBody["unfurl_media"] = *msg.UnfurlMedia
+	}
+	if msg.ThreadTS != "" {
+		reqBody["thread_ts"] = msg.ThreadTS
 	}
 	data, _ := json.Marshal(reqBody)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
@@ -92,7 +144,7 @@ func (c *Client) SendMessageWithBlocks(ctx context.Context, channel, text string
 		return SendMessageResult{Error: err}, err
 	}
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	req.Header.Set("Authorization", "Bearer "+c.cfg.BotToken)
+	req.Header.Set("Authorization", "Bearer "+c.token)
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return SendMessageResult{Error: err}, err
@@ -126,7 +178,7 @@ func (c *Client) OpenConversation(ctx context.Context, userID string) (string, e
 		return "", err
 	}
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	req.Header.Set("Authorization", "Bearer "+c.cfg.BotToken)
+	req.Header.Set("Authorization", "Bearer "+c.token)
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return "", err
@@ -147,48 +199,179 @@ func (c *Client) OpenConversation(ctx context.Context, userID string) (string, e
 	return result.Channel.ID, nil
 }
 
+// AddReaction 给消息添加表情回应（reactions.add），name 为不带冒号的表情名，如 thumbsup；已回应过视为成功
+func (c *Client) AddReaction(ctx context.Context, channel, timestamp, name string) error {
+	url := slackAPIBase + "/reactions.add"
+	reqBody := map[string]string{
+		"channel":   channel,
+		"timestamp": timestamp,
+		"name":      name,
+	}
+	data, _ := json.Marshal(reqBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	_ = json.Unmarshal(b, &result)
+	if !result.OK && result.Error != "already_reacted" {
+		return fmt.Errorf("slack add reaction: %s", result.Error)
+	}
+	return nil
+}
+
+// DeleteMessage 删除机器人发出的消息（chat.delete）；消息已不存在视为成功
+func (c *Client) DeleteMessage(ctx context.Context, channel, timestamp string) error {
+	data, _ := json.Marshal(map[string]string{"channel": channel, "ts": timestamp})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBase+"/chat.delete", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	_ = json.Unmarshal(b, &result)
+	if !result.OK && result.Error != "message_not_found" {
+		return fmt.Errorf("slack delete message: %s", result.Error)
+	}
+	return nil
+}
+
+// GetMessage 确认频道中存在 ts 对应的消息（conversations.history，latest=ts inclusive）
+func (c *Client) GetMessage(ctx context.Context, channel, timestamp string) error {
+	form := url.Values{"channel": {channel}, "latest": {timestamp}, "inclusive": {"true"}, "limit": {"1"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBase+"/conversations.history", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	var result struct {
+		OK       bool   `json:"ok"`
+		Error    string `json:"error"`
+		Messages []struct {
+			Ts string `json:"ts"`
+		} `json:"messages"`
+	}
+	_ = json.Unmarshal(b, &result)
+	if !result.OK {
+		return fmt.Errorf("slack get message: %s", result.Error)
+	}
+	if len(result.Messages) == 0 || result.Messages[0].Ts != timestamp {
+		return fmt.Errorf("slack get message: %s not found", timestamp)
+	}
+	return nil
+}
+
 // BuildRichTextBlocks 构建富文本 blocks（带链接）
 func BuildRichTextBlocks(title, text, linkURL, description string) []Block {
-	var blocks []Block
-
-	// 标题（如果有）
-	if title != "" {
-		blocks = append(blocks, Block{
-			Type: "header",
-			Text: &Text{Type: "plain_text", Text: title},
-		})
-	}
-
-	// 正文
-	if text != "" {
-		blocks = append(blocks, Block{
-			Type: "section",
-			Text: &Text{Type: "mrkdwn", Text: text},
-		})
-	}
-
-	// 描述
-	if description != "" {
-		blocks = append(blocks, Block{
-			Type: "section",
-			Text: &Text{Type: "mrkdwn", Text: description},
-		})
-	}
-
-	// 链接按钮
-	if linkURL != "" {
-		blocks = append(blocks, Block{
-			Type: "actions",
-			Elements: []Element{
-				{
-					Type:     "button",
-					Text:     &Text{Type: "plain_text", Text: "查看链接"},
-					URL:      linkURL,
-					ActionID: "link_button",
-				},
-			},
-		})
-	}
-
-	return blocks
+	return RenderBlocks(model.LinkCard(title, text, linkURL, description))
+}
+
+// UploadFile 上传文件并分享到频道（files.getUploadURLExternal -> 上传内容 -> files.completeUploadExternal）
+// channelID 须为频道/私聊 ID，返回文件 ID
+func (c *Client) UploadFile(ctx context.Context, channelID, fileName, title string, data []byte) (string, error) {
+	// 1. 获取上传地址
+	form := url.Values{"filename": {fileName}, "length": {strconv.Itoa(len(data))}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBase+"/files.getUploadURLExternal", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("slack get upload url: %w", err)
+	}
+	var upload struct {
+		OK        bool   `json:"ok"`
+		Error     string `json:"error"`
+		UploadURL string `json:"upload_url"`
+		FileID    string `json:"file_id"`
+	}
+	if err := json.Unmarshal(b, &upload); err != nil {
+		return "", fmt.Errorf("slack get upload url: decode response: %w", err)
+	}
+	if !upload.OK {
+		return "", fmt.Errorf("slack get upload url: %s", upload.Error)
+	}
+
+	// 2. 上传文件内容
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, upload.UploadURL, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err = c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("slack upload file: http status %d", resp.StatusCode)
+	}
+
+	// 3. 完成上传并分享到频道
+	reqBody := map[string]any{
+		"files":      []map[string]string{{"id": upload.FileID, "title": title}},
+		"channel_id": channelID,
+	}
+	payload, _ := json.Marshal(reqBody)
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBase+"/files.completeUploadExternal", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err = c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("slack complete upload: %w", err)
+	}
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("slack complete upload: decode response: %w", err)
+	}
+	if !result.OK {
+		return "", fmt.Errorf("slack complete upload: %s", result.Error)
+	}
+	return upload.FileID, nil
 }
@@ -0,0 +1,72 @@
+package slack
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// stubTransport 按请求路径返回固定响应
+type stubTransport map[string]string
+
+func (s stubTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, ok := s[r.URL.Path]
+	if !ok {
+		body = `{"ok":true}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    r,
+	}, nil
+}
+
+func TestUploadFileMalformedResponse(t *testing.T) {
+	okUpload := `{"ok":true,"upload_url":"https://files.slack.com/upload/v1/abc","file_id":"F1"}`
+	tests := []struct {
+		name    string
+		stub    stubTransport
+		wantErr string
+	}{
+		{
+			name:    "get upload url not json",
+			stub:    stubTransport{"/api/files.getUploadURLExternal": "<html>bad gateway</html>"},
+			wantErr: "slack get upload url: decode response",
+		},
+		{
+			name: "complete upload not json",
+			stub: stubTransport{
+				"/api/files.getUploadURLExternal":   okUpload,
+				"/api/files.completeUploadExternal": "<html>bad gateway</html>",
+			},
+			wantErr: "slack complete upload: decode response",
+		},
+		{
+			name:    "get upload url not ok",
+			stub:    stubTransport{"/api/files.getUploadURLExternal": `{"ok":false,"error":"invalid_auth"}`},
+			wantErr: "slack get upload url: invalid_auth",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(Config{BotToken: "xoxb-test", Transport: tt.stub})
+			_, err := c.UploadFile(context.Background(), "C1", "a.pdf", "a", []byte("data"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUploadFileOK(t *testing.T) {
+	c := NewClient(Config{BotToken: "xoxb-test", Transport: stubTransport{
+		"/api/files.getUploadURLExternal": `{"ok":true,"upload_url":"https://files.slack.com/upload/v1/abc","file_id":"F1"}`,
+	}})
+	id, err := c.UploadFile(context.Background(), "C1", "a.pdf", "a", []byte("data"))
+	if err != nil || id != "F1" {
+		t.Fatalf("UploadFile = %q, %v, want F1", id, err)
+	}
+}
@@ -0,0 +1,99 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// slackAuthorizeURL Slack OAuth v2 授权页
+const slackAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+
+// OAuthEnabled 是否配置了 OAuth 安装所需的 App 凭证
+func (c *Client) OAuthEnabled() bool {
+	return c.cfg.ClientID != "" && c.cfg.ClientSecret != ""
+}
+
+// InstallURL 安装到工作区的授权链接；state 原样回传给 redirectURL，用于防 CSRF 与携带租户
+func (c *Client) InstallURL(state, redirectURL string) string {
+	q := url.Values{
+		"client_id":    {c.cfg.ClientID},
+		"scope":        {strings.Join(c.cfg.Scopes, ",")},
+		"redirect_uri": {redirectURL},
+		"state":        {state},
+	}
+	return slackAuthorizeURL + "?" + q.Encode()
+}
+
+// OAuthAccess oauth.v2.access 返回的安装信息
+type OAuthAccess struct {
+	AccessToken  string
+	BotUserID    string
+	Scope        string
+	TeamID       string
+	TeamName     string
+	EnterpriseID string
+	InstallerID  string // 授权安装的 Slack 用户
+}
+
+// ExchangeCode 用授权回调中的 code 换取 Bot Token（oauth.v2.access），redirectURL 须与授权时一致
+func (c *Client) ExchangeCode(ctx context.Context, code, redirectURL string) (OAuthAccess, error) {
+	form := url.Values{
+		"client_id":     {c.cfg.ClientID},
+		"client_secret": {c.cfg.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBase+"/oauth.v2.access", strings.NewReader(form.Encode()))
+	if err != nil {
+		return OAuthAccess{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return OAuthAccess{}, err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	var result struct {
+		OK          bool   `json:"ok"`
+		Error       string `json:"error"`
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		Scope       string `json:"scope"`
+		BotUserID   string `json:"bot_user_id"`
+		Team        struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"team"`
+		Enterprise *struct {
+			ID string `json:"id"`
+		} `json:"enterprise"`
+		AuthedUser struct {
+			ID string `json:"id"`
+		} `json:"authed_user"`
+	}
+	_ = json.Unmarshal(b, &result)
+	if !result.OK {
+		return OAuthAccess{}, fmt.Errorf("slack oauth access: %s", result.Error)
+	}
+	if result.TokenType != "bot" || result.AccessToken == "" {
+		return OAuthAccess{}, fmt.Errorf("slack oauth access: no bot token granted")
+	}
+	access := OAuthAccess{
+		AccessToken: result.AccessToken,
+		BotUserID:   result.BotUserID,
+		Scope:       result.Scope,
+		TeamID:      result.Team.ID,
+		TeamName:    result.Team.Name,
+		InstallerID: result.AuthedUser.ID,
+	}
+	if result.Enterprise != nil {
+		access.EnterpriseID = result.Enterprise.ID
+	}
+	return access, nil
+}
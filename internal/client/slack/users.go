@@ -0,0 +1,95 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// User 工作区成员
+type User struct {
+	ID          string
+	Name        string // 用户名（@ 后的名字）
+	RealName    string
+	DisplayName string
+	Email       string // 需要 users:read.email 权限
+	Phone       string
+	Title       string
+	Deleted     bool
+	UpdatedAt   time.Time
+}
+
+// ListUsers 分页拉取工作区全部成员（users.list），跳过机器人与 Slackbot
+func (c *Client) ListUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	cursor := ""
+	for {
+		form := url.Values{"limit": {"200"}}
+		if cursor != "" {
+			form.Set("cursor", cursor)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBase+"/users.list", strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		var result struct {
+			OK      bool   `json:"ok"`
+			Error   string `json:"error"`
+			Members []struct {
+				ID        string `json:"id"`
+				Name      string `json:"name"`
+				Deleted   bool   `json:"deleted"`
+				IsBot     bool   `json:"is_bot"`
+				IsAppUser bool   `json:"is_app_user"`
+				Updated   int64  `json:"updated"`
+				Profile   struct {
+					RealName    string `json:"real_name"`
+					DisplayName string `json:"display_name"`
+					Email       string `json:"email"`
+					Phone       string `json:"phone"`
+					Title       string `json:"title"`
+				} `json:"profile"`
+			} `json:"members"`
+			ResponseMetadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		_ = json.Unmarshal(b, &result)
+		if !result.OK {
+			return nil, fmt.Errorf("slack list users: %s", result.Error)
+		}
+		for _, m := range result.Members {
+			if m.IsBot || m.IsAppUser || m.ID == "USLACKBOT" {
+				continue
+			}
+			users = append(users, User{
+				ID:          m.ID,
+				Name:        m.Name,
+				RealName:    m.Profile.RealName,
+				DisplayName: m.Profile.DisplayName,
+				Email:       m.Profile.Email,
+				Phone:       m.Profile.Phone,
+				Title:       m.Profile.Title,
+				Deleted:     m.Deleted,
+				UpdatedAt:   time.Unix(m.Updated, 0),
+			})
+		}
+		cursor = result.ResponseMetadata.NextCursor
+		if cursor == "" {
+			return users, nil
+		}
+	}
+}
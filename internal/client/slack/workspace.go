@@ -0,0 +1,86 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"sayso-agent/internal/model"
+)
+
+// Workspace 一个 Slack 工作区及其 Bot Token
+type Workspace struct {
+	Name     string // 目标中使用的名称，如 "#general@emea" 中的 emea
+	TeamID   string // 工作区 team_id（T 开头），请求可用 context.slack_team_id 指定
+	BotToken string
+	// Tenants 以该工作区为默认工作区的租户；请求未指定工作区时按租户选择，都不匹配时用 Config.BotToken
+	Tenants []string
+}
+
+// ParseTarget 拆分带工作区的目标："#general@emea" → ("#general", "emea")；不带 @ 时 workspace 为空
+func ParseTarget(target string) (id, workspace string) {
+	if i := strings.LastIndex(target, "@"); i > 0 {
+		return target[:i], target[i+1:]
+	}
+	return target, ""
+}
+
+// In 切换到指定工作区（名称或 team_id，不区分大小写）；workspace 为空时返回当前客户端
+func (c *Client) In(workspace string) (*Client, error) {
+	if workspace == "" {
+		return c, nil
+	}
+	for _, w := range c.workspaces() {
+		if strings.EqualFold(w.Name, workspace) || strings.EqualFold(w.TeamID, workspace) {
+			return c.with(w.BotToken), nil
+		}
+	}
+	return nil, fmt.Errorf("slack workspace %q not configured", workspace)
+}
+
+// ForTenant 租户的默认工作区；没有专门配置时返回默认客户端
+func (c *Client) ForTenant(tenant string) *Client {
+	for _, w := range c.workspaces() {
+		for _, t := range w.Tenants {
+			if t == tenant {
+				return c.with(w.BotToken)
+			}
+		}
+	}
+	return c
+}
+
+func (c *Client) with(token string) *Client {
+	return &Client{cfg: c.cfg, client: c.client, token: token, installed: c.installed, channels: c.channels}
+}
+
+// InstalledWorkspace OAuth 安装记录对应的工作区：名称与 team_id 均可用于 "#general@工作区"，发起安装的租户默认使用该工作区
+func InstalledWorkspace(in model.SlackInstallation) Workspace {
+	return Workspace{Name: in.TeamName, TeamID: in.TeamID, BotToken: in.BotToken, Tenants: []string{in.TenantID}}
+}
+
+// installed OAuth 安装的工作区，运行时增加
+type installed struct {
+	mu   sync.RWMutex
+	list []Workspace
+}
+
+// AddWorkspace 加入（或按 team_id 替换）通过 OAuth 安装的工作区，之后可像配置中的工作区一样使用
+func (c *Client) AddWorkspace(w Workspace) {
+	c.installed.mu.Lock()
+	defer c.installed.mu.Unlock()
+	for i, old := range c.installed.list {
+		if old.TeamID == w.TeamID {
+			c.installed.list[i] = w
+			return
+		}
+	}
+	c.installed.list = append(c.installed.list, w)
+}
+
+// workspaces 配置中的工作区在前，OAuth 安装的在后
+func (c *Client) workspaces() []Workspace {
+	c.installed.mu.RLock()
+	defer c.installed.mu.RUnlock()
+	return append(append([]Workspace(nil), c.cfg.Workspaces...), c.installed.list...)
+}
@@ -0,0 +1,138 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Config 短信/WhatsApp 客户端配置，接口与 Twilio Messages API 兼容
+type Config struct {
+	Enabled    bool
+	AccountSID string
+	AuthToken  string
+	// From 短信发送号码；WhatsAppFrom 为 WhatsApp Business 发送号码，为空时不支持 WhatsApp
+	From         string
+	WhatsAppFrom string
+	// DefaultCountryCode 不带 "+" 的号码补上的国家码，如 "+86"
+	DefaultCountryCode string
+	// AllowedPrefixes 非空时只允许发往这些前缀的号码（如 "+86"），防止误发到境外高价号码
+	AllowedPrefixes []string
+	// MaxRecipients 单次动作的接收人上限，0 使用默认 5；MaxChars 单条内容的字符上限，0 使用默认 500
+	MaxRecipients int
+	MaxChars      int
+	// SkipConfirm 为 true 时不再请用户确认接收人与内容（默认每次发送前确认）
+	SkipConfirm bool
+	// APIBase 为空时使用 https://api.twilio.com，兼容 Twilio 接口的其他服务商可改为其地址
+	APIBase string
+	// Transport 共享的出站连接池，为 nil 时使用 http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// 发送渠道
+const (
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+)
+
+// Client 短信/WhatsApp API 客户端
+type Client struct {
+	cfg    Config
+	client *http.Client
+	base   string
+}
+
+// NewClient 创建短信客户端
+func NewClient(cfg Config) *Client {
+	base := strings.TrimRight(cfg.APIBase, "/")
+	if base == "" {
+		base = twilioAPIBase
+	}
+	return &Client{cfg: cfg, client: &http.Client{Transport: cfg.Transport}, base: base}
+}
+
+const twilioAPIBase = "https://api.twilio.com"
+
+// SendResult 发送结果，SID 为服务商的消息 ID
+type SendResult struct {
+	SID    string
+	Status string
+}
+
+// Send 发送一条短信或 WhatsApp 消息（POST /2010-04-01/Accounts/{sid}/Messages.json），to 为 E.164 号码
+func (c *Client) Send(ctx context.Context, channel, to, body string) (SendResult, error) {
+	from := c.cfg.From
+	if channel == ChannelWhatsApp {
+		if c.cfg.WhatsAppFrom == "" {
+			return SendResult{}, fmt.Errorf("sms: whatsapp sender not configured")
+		}
+		from, to = "whatsapp:"+c.cfg.WhatsAppFrom, "whatsapp:"+to
+	}
+	form := url.Values{"To": {to}, "From": {from}, "Body": {body}}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", c.base, url.PathEscape(c.cfg.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return SendResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.cfg.AccountSID, c.cfg.AuthToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return SendResult{}, err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	var result struct {
+		SID     string `json:"sid"`
+		Status  string `json:"status"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(b, &result)
+	if resp.StatusCode >= 300 {
+		if result.Message != "" {
+			return SendResult{}, fmt.Errorf("sms send: %s (code %d)", result.Message, result.Code)
+		}
+		return SendResult{}, fmt.Errorf("sms send: status %d", resp.StatusCode)
+	}
+	return SendResult{SID: result.SID, Status: result.Status}, nil
+}
+
+// NormalizePhone 去掉空格、横线与括号，不带 "+" 的号码补上 defaultCountryCode；不像电话号码时返回 false
+func NormalizePhone(s, defaultCountryCode string) (string, bool) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(s) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+	phone := b.String()
+	if !strings.HasPrefix(phone, "+") {
+		if defaultCountryCode == "" {
+			return "", false
+		}
+		phone = "+" + strings.TrimPrefix(defaultCountryCode, "+") + strings.TrimLeft(phone, "0")
+	}
+	if n := len(phone) - 1; n < 7 || n > 15 {
+		return "", false
+	}
+	return phone, true
+}
+
+// MaskPhone 隐去号码中间几位，用于确认提示与日志，如 +8613812345678 → +86138****5678
+func MaskPhone(phone string) string {
+	if len(phone) < 9 {
+		return phone
+	}
+	return phone[:len(phone)-8] + "****" + phone[len(phone)-4:]
+}
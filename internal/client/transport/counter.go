@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+// Counter 一次请求内发出的外部 API 调用数（飞书、Slack、大模型等），用于成本统计
+type Counter struct {
+	n atomic.Int64
+}
+
+type counterKey struct{}
+
+// WithCounter 在 ctx 中挂上调用计数，之后经该 ctx 发出的请求都累计到返回的 Counter；已挂有计数时沿用
+func WithCounter(ctx context.Context) (context.Context, *Counter) {
+	if c := CounterFrom(ctx); c != nil {
+		return ctx, c
+	}
+	c := &Counter{}
+	return context.WithValue(ctx, counterKey{}, c), c
+}
+
+// CounterFrom 读取 ctx 中的调用计数，没有时返回 nil
+func CounterFrom(ctx context.Context) *Counter {
+	c, _ := ctx.Value(counterKey{}).(*Counter)
+	return c
+}
+
+// Count 已发出的调用数；Counter 为 nil 时为 0
+func (c *Counter) Count() int {
+	if c == nil {
+		return 0
+	}
+	return int(c.n.Load())
+}
+
+// Counting 包装 rt：请求的 ctx 挂有 Counter 时计数（含失败的请求）
+func Counting(rt http.RoundTripper) http.RoundTripper {
+	return countingTransport{rt}
+}
+
+type countingTransport struct {
+	rt http.RoundTripper
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if c := CounterFrom(req.Context()); c != nil {
+		c.n.Add(1)
+	}
+	return t.rt.RoundTrip(req)
+}
@@ -0,0 +1,62 @@
+package transport
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// Config 出站 HTTP 连接池参数，0 值使用默认值
+type Config struct {
+	MaxIdleConns        int           // 所有主机的空闲连接总数上限
+	MaxIdleConnsPerHost int           // 单个主机的空闲连接上限，批量发送时需大于并发数以免反复建连
+	MaxConnsPerHost     int           // 单个主机的连接总数上限，0 表示不限制
+	IdleConnTimeout     time.Duration // 空闲连接保留时间
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+	KeepAlive           time.Duration // TCP keep-alive 探测间隔
+	DisableHTTP2        bool
+}
+
+const (
+	defaultMaxIdleConns        = 200
+	defaultMaxIdleConnsPerHost = 50
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 10 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+)
+
+// New 创建可在各 API 客户端间共享的 Transport；默认启用 HTTP/2（飞书、Slack、大模型接口均支持）
+func New(cfg Config) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   orDefault(cfg.DialTimeout, defaultDialTimeout),
+		KeepAlive: orDefault(cfg.KeepAlive, defaultKeepAlive),
+	}
+	maxIdle := cfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultMaxIdleConns
+	}
+	maxIdlePerHost := cfg.MaxIdleConnsPerHost
+	if maxIdlePerHost <= 0 {
+		maxIdlePerHost = defaultMaxIdleConnsPerHost
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+		MaxIdleConns:          maxIdle,
+		MaxIdleConnsPerHost:   maxIdlePerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       orDefault(cfg.IdleConnTimeout, defaultIdleConnTimeout),
+		TLSHandshakeTimeout:   orDefault(cfg.TLSHandshakeTimeout, defaultTLSHandshakeTimeout),
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,84 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Config 语音合成客户端配置（OpenAI 兼容的 /audio/speech 接口）
+type Config struct {
+	APIKey  string
+	BaseURL string
+	Model   string
+	Voice   string
+	// Transport 共享的出站连接池，为 nil 时使用 http.DefaultTransport
+	Transport http.RoundTripper
+}
+
+// Client 语音合成客户端
+type Client struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewClient 创建语音合成客户端
+func NewClient(cfg Config) *Client {
+	return &Client{cfg: cfg, client: &http.Client{Transport: cfg.Transport}}
+}
+
+// Audio 合成的音频：Ogg 封装的 Opus，Duration 为时长（毫秒），无法解析时为 0
+type Audio struct {
+	Data     []byte
+	Duration int
+}
+
+// Synthesize 把文本合成为 Opus 语音（POST {base_url}/audio/speech）
+func (c *Client) Synthesize(ctx context.Context, text string) (Audio, error) {
+	reqBody, _ := json.Marshal(map[string]string{
+		"model":           c.cfg.Model,
+		"voice":           c.cfg.Voice,
+		"input":           text,
+		"response_format": "opus",
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.cfg.BaseURL, "/")+"/audio/speech", bytes.NewReader(reqBody))
+	if err != nil {
+		return Audio{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Audio{}, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Audio{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Audio{}, fmt.Errorf("tts api error: %d %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return Audio{Data: b, Duration: OggDuration(b)}, nil
+}
+
+// opusSampleRate Ogg Opus 的 granule position 固定按 48kHz 计数
+const opusSampleRate = 48000
+
+// OggDuration 从最后一个 Ogg 页的 granule position 计算 Opus 音频时长（毫秒），格式不符时返回 0
+func OggDuration(data []byte) int {
+	i := bytes.LastIndex(data, []byte("OggS"))
+	if i < 0 || len(data) < i+14 {
+		return 0
+	}
+	granule := binary.LittleEndian.Uint64(data[i+6 : i+14])
+	if granule == 0 || granule == ^uint64(0) {
+		return 0
+	}
+	return int(granule * 1000 / opusSampleRate)
+}
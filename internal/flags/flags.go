@@ -0,0 +1,66 @@
+package flags
+
+import (
+	"hash/fnv"
+	"slices"
+	"sort"
+)
+
+// 功能开关与灰度：新技能、新行为按租户名单或租户比例逐步开放。
+// 未配置的功能视为全量开启，开关只用于收窄范围；技能的开关名为 "skill.<技能名>"
+
+// Flag 单个功能的开放范围：Tenants 中的租户总是开启，ExcludeTenants 中的租户总是关闭，
+// 其余租户按 (功能名, 租户) 的哈希落在 Percent（0~100）内时开启，同一租户的结果稳定
+type Flag struct {
+	Percent        int
+	Tenants        []string
+	ExcludeTenants []string
+}
+
+// Set 一组功能开关，创建后只读
+type Set struct {
+	flags map[string]Flag
+}
+
+// New 创建功能开关
+func New(flags map[string]Flag) *Set {
+	return &Set{flags: flags}
+}
+
+// Enabled 功能对租户是否开启；Set 为 nil 或功能未配置时为 true
+func (s *Set) Enabled(name, tenant string) bool {
+	if s == nil {
+		return true
+	}
+	f, ok := s.flags[name]
+	if !ok {
+		return true
+	}
+	if slices.Contains(f.ExcludeTenants, tenant) {
+		return false
+	}
+	if slices.Contains(f.Tenants, tenant) {
+		return true
+	}
+	return bucket(name, tenant) < f.Percent
+}
+
+// Names 已配置的功能名
+func (s *Set) Names() []string {
+	if s == nil {
+		return nil
+	}
+	names := make([]string, 0, len(s.flags))
+	for name := range s.flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// bucket 租户在该功能下的分桶（0~99）；按功能名区分，避免同一批租户总是最先拿到所有新功能
+func bucket(name, tenant string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name + "/" + tenant))
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed admin/index.html
+var adminHTML []byte
+
+// adminPage 内嵌的运维页面：最近任务、计划依赖图、动作结果与重试/确认/取消
+// GET /admin
+func adminPage(c *gin.Context) {
+	c.Header("Cache-Control", "no-cache")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", adminHTML)
+}
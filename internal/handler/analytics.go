@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/service/analytics"
+)
+
+// AnalyticsHandler 运营统计接口，供运维看板使用
+type AnalyticsHandler struct {
+	analyzer *analytics.Analyzer
+}
+
+// NewAnalyticsHandler 创建统计处理器
+func NewAnalyticsHandler(analyzer *analytics.Analyzer) *AnalyticsHandler {
+	return &AnalyticsHandler{analyzer: analyzer}
+}
+
+// analyticsMaxDays 统计范围上限
+const analyticsMaxDays = 90
+
+// query 统计范围：最近 days 天（默认 7）；tenant_id=all 且令牌未限定租户时统计全部租户
+func (h *AnalyticsHandler) query(c *gin.Context) (analytics.Query, bool) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days <= 0 || days > analyticsMaxDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and " + strconv.Itoa(analyticsMaxDays)})
+		return analytics.Query{}, false
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	q := analytics.Query{TenantID: tenantOf(c), Since: today.AddDate(0, 0, 1-days)}
+	if _, scoped := tokenTenant(c); !scoped && c.Query("tenant_id") == "all" {
+		q.TenantID = ""
+	}
+	return q, true
+}
+
+// Actions 按天、按类型统计执行的动作
+// GET /api/v1/analytics/actions?days=7&tenant_id=
+func (h *AnalyticsHandler) Actions(c *gin.Context) {
+	q, ok := h.query(c)
+	if !ok {
+		return
+	}
+	days, err := h.analyzer.ActionsByDay(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"days": days})
+}
+
+// Outcomes 成功率、平均计划规模与最常见的失败原因
+// GET /api/v1/analytics/outcomes?days=7&tenant_id=
+func (h *AnalyticsHandler) Outcomes(c *gin.Context) {
+	q, ok := h.query(c)
+	if !ok {
+		return
+	}
+	out, err := h.analyzer.Outcomes(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// Cost 按租户（group_by=user 时按用户）统计外部调用、大模型用量与估算成本
+// GET /api/v1/analytics/cost?days=7&tenant_id=all&group_by=tenant
+func (h *AnalyticsHandler) Cost(c *gin.Context) {
+	q, ok := h.query(c)
+	if !ok {
+		return
+	}
+	byUser, ok := groupByUser(c)
+	if !ok {
+		return
+	}
+	lines, err := h.analyzer.Costs(c.Request.Context(), q, byUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"lines": lines})
+}
+
+// CostReport 某月的成本报表，format=csv 时以 CSV 下载，默认上个月
+// GET /api/v1/reports/cost?month=2026-09&group_by=tenant&format=json&tenant_id=all
+func (h *AnalyticsHandler) CostReport(c *gin.Context) {
+	month := c.DefaultQuery("month", time.Now().AddDate(0, -1, 0).Format("2006-01"))
+	if _, _, err := analytics.MonthRange(month); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	byUser, ok := groupByUser(c)
+	if !ok {
+		return
+	}
+	tenantID := tenantOf(c)
+	if _, scoped := tokenTenant(c); !scoped && c.Query("tenant_id") == "all" {
+		tenantID = ""
+	}
+	report, err := h.analyzer.CostReport(c.Request.Context(), tenantID, month, byUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, report)
+	case "csv":
+		c.Header("Content-Disposition", `attachment; filename="cost-`+month+`.csv"`)
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		if err := report.WriteCSV(c.Writer); err != nil {
+			c.Error(err)
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of json | csv"})
+	}
+}
+
+// groupByUser 解析 group_by（tenant | user，默认 tenant）
+func groupByUser(c *gin.Context) (bool, bool) {
+	switch c.DefaultQuery("group_by", "tenant") {
+	case "tenant":
+		return false, true
+	case "user":
+		return true, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be one of tenant | user"})
+	return false, false
+}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -8,27 +11,56 @@ import (
 	"sayso-agent/internal/service"
 )
 
+// statusClientClosed 客户端在响应前断开连接（沿用 nginx 的 499）
+const statusClientClosed = 499
+
 // ASRHandler 处理 ASR 相关 HTTP 请求
 type ASRHandler struct {
 	asrService *service.ASRService
+	// background 客户端断开后继续在后台完成任务，而不是取消规划与执行
+	background bool
 }
 
-// NewASRHandler 创建 ASR 处理器
-func NewASRHandler(svc *service.ASRService) *ASRHandler {
-	return &ASRHandler{asrService: svc}
+// NewASRHandler 创建 ASR 处理器；background 为 true 时客户端断开不取消处理，结果写入任务记录
+func NewASRHandler(svc *service.ASRService, background bool) *ASRHandler {
+	return &ASRHandler{asrService: svc, background: background}
 }
 
 // Process 接收内部传入的 ASR 文本并处理
 // POST /api/v1/asr/process
 func (h *ASRHandler) Process(c *gin.Context) {
 	var req model.ASRRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+	if !bindJSON(c, &req) {
+		return
+	}
+	applyIdentity(c, &req)
+	ctx := c.Request.Context()
+	if h.background {
+		ctx = context.WithoutCancel(ctx)
+	}
+	resp, err := h.asrService.Process(ctx, req)
+	if c.Request.Context().Err() != nil {
+		// 客户端已断开，响应无法送达；后台模式下任务已完成，结果可通过 GET /api/v1/tasks/:id 查询
+		if h.background {
+			log.Printf("client disconnected, task %s finished in background: success=%v", resp.TaskID, resp.Success)
+		}
+		c.AbortWithStatus(statusClientClosed)
 		return
 	}
-	resp, err := h.asrService.Process(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, model.ErrRateLimited):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, model.ErrInvalidParams) && resp.TaskID == "":
+			// 请求本身不合法（如 llm 参数超出允许范围），未创建任务
+			status = http.StatusBadRequest
+		case errors.Is(err, model.ErrLimitExceeded), errors.Is(err, model.ErrRecipientNotAllowed):
+			status = http.StatusUnprocessableEntity
+		case resp.TimedOutPhase != "":
+			status = http.StatusGatewayTimeout
+		}
+		c.JSON(status, gin.H{
 			"task_id": resp.TaskID,
 			"error":   err.Error(),
 			"result":  resp,
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindJSON 解析 JSON 请求体，失败时写出错误响应并返回 false：
+// 非 JSON 的 Content-Type 返回 415，超过 BodyLimit 返回 413，其余解析错误返回 400
+func bindJSON(c *gin.Context, obj any) bool {
+	if ct := c.GetHeader("Content-Type"); ct != "" {
+		if mediaType, _, err := mime.ParseMediaType(ct); err != nil || mediaType != "application/json" {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "unsupported content type: " + ct})
+			return false
+		}
+	}
+	if err := c.ShouldBindJSON(obj); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return false
+	}
+	return true
+}
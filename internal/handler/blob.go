@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/store"
+)
+
+// blobDownload 本地对象存储的签名链接下载；签名不符或过期返回 403
+// GET /blobs/*key?expires=&sig=
+func blobDownload(blobs *store.LocalBlobStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimPrefix(c.Param("key"), "/")
+		file, contentType, err := blobs.Open(key, c.Query("expires"), c.Query("sig"))
+		switch {
+		case errors.Is(err, store.ErrInvalidSignature):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if contentType != "" {
+			c.Header("Content-Type", contentType)
+		}
+		c.File(file)
+	}
+}
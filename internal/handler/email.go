@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/panics"
+	"sayso-agent/internal/service"
+	"sayso-agent/internal/trace"
+)
+
+// emailBodyLimit 邮件正文送入大模型的最大字符数
+const emailBodyLimit = 8000
+
+// emailProcessTimeout 单封邮件的处理超时
+const emailProcessTimeout = 5 * time.Minute
+
+// EmailConfig 入站邮件处理配置
+type EmailConfig struct {
+	Secret         string
+	AllowedDomains []string
+	TenantID       string
+}
+
+// InboundEmail 邮件服务商 webhook 推送的邮件（各服务商字段需在网关侧转换为此格式）
+type InboundEmail struct {
+	From    string `json:"from" binding:"required"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+}
+
+// EmailHandler 将入站邮件转换为处理请求，如转发给 agent@company 触发「整理归档并通知负责人」
+type EmailHandler struct {
+	asrService *service.ASRService
+	cfg        EmailConfig
+	panics     *panics.Reporter
+}
+
+// NewEmailHandler 创建入站邮件处理器；reporter 上报异步处理中的 panic
+func NewEmailHandler(svc *service.ASRService, cfg EmailConfig, reporter *panics.Reporter) *EmailHandler {
+	return &EmailHandler{asrService: svc, cfg: cfg, panics: reporter}
+}
+
+// Receive 接收入站邮件，异步处理并立即返回 202；未配置密钥时一律拒绝
+// POST /api/v1/inbound/email
+func (h *EmailHandler) Receive(c *gin.Context) {
+	if h.cfg.Secret == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Inbound-Secret")), []byte(h.cfg.Secret)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid inbound secret"})
+		return
+	}
+	var email InboundEmail
+	if !bindJSON(c, &email) {
+		return
+	}
+	sender, err := mail.ParseAddress(email.From)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from address: " + err.Error()})
+		return
+	}
+	if !h.allowed(sender.Address) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "sender not allowed"})
+		return
+	}
+
+	req := h.toRequest(email, sender)
+	requestID := trace.From(c.Request.Context()).RequestID()
+	go func() {
+		// 沿用请求 ID，异步处理中的 panic 可与访问日志关联
+		ctx, _ := trace.With(context.Background(), requestID)
+		ctx, cancel := context.WithTimeout(ctx, emailProcessTimeout)
+		defer cancel()
+		defer h.panics.Recover(ctx, "inbound email")
+		resp, err := h.asrService.ProcessFrom(ctx, req, model.TaskSourceEmail)
+		if err != nil {
+			log.Printf("inbound email from %s: task %s failed: %v", sender.Address, resp.TaskID, err)
+		}
+	}()
+	c.JSON(http.StatusAccepted, gin.H{"accepted": true})
+}
+
+// allowed 检查发件人域名是否在白名单内；白名单为空时拒绝全部发件人
+func (h *EmailHandler) allowed(address string) bool {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(address[at+1:])
+	for _, d := range h.cfg.AllowedDomains {
+		if strings.ToLower(d) == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// toRequest 将邮件转换为处理请求：主题与正文作为输入文本，发件人作为联系人（可作为「通知负责人」的目标）
+func (h *EmailHandler) toRequest(email InboundEmail, sender *mail.Address) model.ASRRequest {
+	body := email.Text
+	if r := []rune(body); len(r) > emailBodyLimit {
+		body = string(r[:emailBodyLimit]) + "…"
+	}
+	name := sender.Name
+	if name == "" {
+		name = sender.Address
+	}
+	text := fmt.Sprintf("收到一封邮件，请按邮件内容处理。\n发件人：%s <%s>\n主题：%s\n正文：\n%s", name, sender.Address, email.Subject, body)
+	return model.ASRRequest{
+		Text:     text,
+		UserID:   sender.Address,
+		TenantID: h.cfg.TenantID,
+		Context: map[string]string{
+			"email_from":    sender.Address,
+			"email_subject": email.Subject,
+		},
+		Contacts: []model.Contact{{Name: name, Email: sender.Address}},
+	}
+}
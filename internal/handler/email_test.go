@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEmailReceiveRejects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const body = `{"from":"张三 <zhangsan@example.com>","subject":"周报","text":"请归档"}`
+	tests := []struct {
+		name   string
+		cfg    EmailConfig
+		secret string
+		want   int
+	}{
+		{name: "no secret configured", cfg: EmailConfig{AllowedDomains: []string{"example.com"}}, secret: "", want: http.StatusUnauthorized},
+		{name: "no secret configured with header", cfg: EmailConfig{AllowedDomains: []string{"example.com"}}, secret: "anything", want: http.StatusUnauthorized},
+		{name: "wrong secret", cfg: EmailConfig{Secret: "s3cret", AllowedDomains: []string{"example.com"}}, secret: "guess", want: http.StatusUnauthorized},
+		{name: "empty allowlist", cfg: EmailConfig{Secret: "s3cret"}, secret: "s3cret", want: http.StatusForbidden},
+		{name: "sender domain not allowed", cfg: EmailConfig{Secret: "s3cret", AllowedDomains: []string{"acme.com"}}, secret: "s3cret", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.POST("/inbound/email", NewEmailHandler(nil, tt.cfg, nil).Receive)
+			req := httptest.NewRequest(http.MethodPost, "/inbound/email", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.secret != "" {
+				req.Header.Set("X-Inbound-Secret", tt.secret)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestEmailAllowedDomains(t *testing.T) {
+	h := NewEmailHandler(nil, EmailConfig{Secret: "s", AllowedDomains: []string{"Example.com"}}, nil)
+	for addr, want := range map[string]bool{
+		"a@example.com":      true,
+		"a@EXAMPLE.COM":      true,
+		"a@evil.com":         false,
+		"a@example.com.evil": false,
+		"no-at-sign":         false,
+	} {
+		if got := h.allowed(addr); got != want {
+			t.Errorf("allowed(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/service"
+)
+
+// FeishuCardConfig 飞书卡片回调配置
+type FeishuCardConfig struct {
+	// VerificationToken 卡片回调的 Verification Token，用于校验回调来源；回调不可配置加密
+	VerificationToken string
+}
+
+// DraftReviewer 处理草稿预览卡片的按钮（由 service.ASRService 实现）
+type DraftReviewer interface {
+	ReviewDraft(ctx context.Context, taskID, operator, decision string) (model.ASRResponse, error)
+}
+
+// FeishuCardHandler 接收飞书卡片按钮回调，目前用于草稿预览的「发送」「修改」
+type FeishuCardHandler struct {
+	cfg    FeishuCardConfig
+	drafts DraftReviewer
+}
+
+// NewFeishuCardHandler 创建飞书卡片回调处理器
+func NewFeishuCardHandler(cfg FeishuCardConfig, drafts DraftReviewer) *FeishuCardHandler {
+	return &FeishuCardHandler{cfg: cfg, drafts: drafts}
+}
+
+// cardAction 按钮回调内容
+type cardAction struct {
+	Value map[string]string `json:"value"`
+	Tag   string            `json:"tag"`
+}
+
+// feishuCardCallback 回调请求体，兼容旧版卡片请求网址（open_id + action）与事件订阅的 card.action.trigger（schema 2.0）
+type feishuCardCallback struct {
+	Challenge string     `json:"challenge"`
+	Token     string     `json:"token"`
+	Type      string     `json:"type"`
+	OpenID    string     `json:"open_id"`
+	Action    cardAction `json:"action"`
+	Header    struct {
+		EventType string `json:"event_type"`
+		Token     string `json:"token"`
+	} `json:"header"`
+	Event struct {
+		Operator struct {
+			OpenID string `json:"open_id"`
+		} `json:"operator"`
+		Action cardAction `json:"action"`
+	} `json:"event"`
+}
+
+// Receive 卡片回调，返回 toast 提示处理结果
+// POST /feishu/card
+func (h *FeishuCardHandler) Receive(c *gin.Context) {
+	var cb feishuCardCallback
+	if err := json.NewDecoder(c.Request.Body).Decode(&cb); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid callback: " + err.Error()})
+		return
+	}
+	token := cb.Token
+	if token == "" {
+		token = cb.Header.Token
+	}
+	if h.cfg.VerificationToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.VerificationToken)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid verification token"})
+		return
+	}
+	if cb.Type == "url_verification" {
+		c.JSON(http.StatusOK, gin.H{"challenge": cb.Challenge})
+		return
+	}
+	operator, action := cb.OpenID, cb.Action
+	if cb.Header.EventType == "card.action.trigger" {
+		operator, action = cb.Event.Operator.OpenID, cb.Event.Action
+	}
+	taskID, decision := action.Value["task_id"], action.Value["decision"]
+	if taskID == "" || decision == "" {
+		// 不是本服务发出的回调按钮
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	resp, err := h.drafts.ReviewDraft(c.Request.Context(), taskID, operator, decision)
+	if err != nil {
+		log.Printf("feishu card: task %s %s by %s: %v", taskID, decision, operator, err)
+		c.JSON(http.StatusOK, cardToast("error", cardErrorMessage(err)))
+		return
+	}
+	c.JSON(http.StatusOK, cardToast("info", resp.Message))
+}
+
+// cardToast 回调响应中的 toast 提示
+func cardToast(typ, content string) gin.H {
+	return gin.H{"toast": gin.H{"type": typ, "content": content}}
+}
+
+// cardErrorMessage 回调失败时展示给用户的说明
+func cardErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, service.ErrNotAwaitingConfirmation):
+		return "草稿已处理或已过期"
+	case errors.Is(err, service.ErrNotTaskRequester):
+		return "只有发起人可以操作"
+	}
+	return "处理失败：" + err.Error()
+}
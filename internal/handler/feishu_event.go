@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/panics"
+	"sayso-agent/internal/service"
+	"sayso-agent/internal/service/chat"
+	"sayso-agent/internal/store"
+	"sayso-agent/internal/trace"
+)
+
+// feishuMessageTimeout 单条机器人消息（含附件下载、语音转写）的处理超时
+const feishuMessageTimeout = 5 * time.Minute
+
+// feishuEventDedupWindow 飞书未及时收到响应会重推事件，此时间内相同 event_id 的消息只处理一次
+const feishuEventDedupWindow = 10 * time.Minute
+
+// FeishuEventConfig 飞书事件订阅（商店应用）配置
+type FeishuEventConfig struct {
+	Client  *feishu.Client
+	Tenants store.FeishuTenantStore
+	// VerificationToken 事件订阅的 Verification Token，用于校验事件来源
+	VerificationToken string
+	// EncryptKey 事件订阅的 Encrypt Key，为空表示事件不加密
+	EncryptKey string
+	// TenantIDs tenant_key -> 本服务租户，未列出的企业以 tenant_key 作为租户
+	TenantIDs map[string]string
+	// Marketplace 商店应用：消息按 tenant_key 归入租户
+	Marketplace bool
+	// Bot 机器人收到的消息（im.message.receive_v1）转换为处理请求，nil 表示忽略消息事件
+	Bot *chat.Feishu
+}
+
+// FeishuEventHandler 接收飞书事件：app_ticket 用于换取 app_access_token，
+// app_open / app_uninstalled / app_status_change 维护安装了应用的企业；
+// 启用机器人时，收到的消息（含图片、文件、语音）异步处理后回复到原消息
+type FeishuEventHandler struct {
+	cfg        FeishuEventConfig
+	asrService *service.ASRService
+	panics     *panics.Reporter
+
+	mu   sync.Mutex
+	seen map[string]time.Time // event_id -> 收到时间
+}
+
+// NewFeishuEventHandler 创建飞书事件处理器；reporter 上报异步处理消息中的 panic
+func NewFeishuEventHandler(cfg FeishuEventConfig, svc *service.ASRService, reporter *panics.Reporter) *FeishuEventHandler {
+	return &FeishuEventHandler{cfg: cfg, asrService: svc, panics: reporter, seen: make(map[string]time.Time)}
+}
+
+// feishuEvent 事件请求体，兼容 1.0（type + event.type）与 2.0（schema + header.event_type）两种格式
+type feishuEvent struct {
+	Encrypt   string `json:"encrypt"`
+	Challenge string `json:"challenge"`
+	Token     string `json:"token"`
+	Type      string `json:"type"`
+	Header    struct {
+		EventID   string `json:"event_id"`
+		EventType string `json:"event_type"`
+		Token     string `json:"token"`
+		TenantKey string `json:"tenant_key"`
+	} `json:"header"`
+	Event struct {
+		Type      string `json:"type"`
+		AppTicket string `json:"app_ticket"`
+		TenantKey string `json:"tenant_key"`
+		Status    string `json:"status"` // app_status_change：start_by_tenant | stop_by_tenant | stop_by_platform
+		Installer struct {
+			OpenID string `json:"open_id"`
+		} `json:"installer"`
+		// Sender、Message 为 im.message.receive_v1 的发送人与消息
+		Sender struct {
+			SenderID struct {
+				OpenID string `json:"open_id"`
+			} `json:"sender_id"`
+			SenderType string `json:"sender_type"`
+		} `json:"sender"`
+		Message chat.FeishuMessage `json:"message"`
+	} `json:"event"`
+}
+
+// Receive 事件回调
+// POST /feishu/events
+func (h *FeishuEventHandler) Receive(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "read body: " + err.Error()})
+		return
+	}
+	if h.cfg.EncryptKey != "" && c.GetHeader("X-Lark-Signature") != "" && !h.validSignature(c, body) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+		return
+	}
+	var ev feishuEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event: " + err.Error()})
+		return
+	}
+	if ev.Encrypt != "" {
+		plain, err := h.decrypt(ev.Encrypt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "decrypt event: " + err.Error()})
+			return
+		}
+		ev = feishuEvent{}
+		if err := json.Unmarshal(plain, &ev); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event: " + err.Error()})
+			return
+		}
+	}
+	token := ev.Token
+	if token == "" {
+		token = ev.Header.Token
+	}
+	if h.cfg.VerificationToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.cfg.VerificationToken)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid verification token"})
+		return
+	}
+	if ev.Type == "url_verification" {
+		c.JSON(http.StatusOK, gin.H{"challenge": ev.Challenge})
+		return
+	}
+	if ev.Header.EventType == "im.message.receive_v1" {
+		h.receiveMessage(c, ev)
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	if err := h.handle(c, ev); err != nil {
+		log.Printf("feishu event %s: %v", ev.Event.Type, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// handle 处理商店应用相关事件，其他事件忽略
+func (h *FeishuEventHandler) handle(c *gin.Context, ev feishuEvent) error {
+	ctx := c.Request.Context()
+	tenantKey := ev.Event.TenantKey
+	if tenantKey == "" {
+		tenantKey = ev.Header.TenantKey
+	}
+	switch ev.Event.Type {
+	case "app_ticket":
+		h.cfg.Client.SetAppTicket(ev.Event.AppTicket)
+	case "app_open":
+		t := model.FeishuTenant{TenantKey: tenantKey, TenantID: h.tenantID(tenantKey), Installer: ev.Event.Installer.OpenID, Active: true, InstalledAt: time.Now()}
+		if err := h.cfg.Tenants.Save(ctx, t); err != nil {
+			return fmt.Errorf("save tenant %s: %w", tenantKey, err)
+		}
+		h.cfg.Client.AddTenant(t.TenantID, tenantKey)
+		log.Printf("feishu app installed by tenant_key %s (tenant %s)", tenantKey, t.TenantID)
+	case "app_uninstalled":
+		if err := h.cfg.Tenants.Delete(ctx, tenantKey); err != nil {
+			return fmt.Errorf("delete tenant %s: %w", tenantKey, err)
+		}
+		h.cfg.Client.RemoveTenant(tenantKey)
+		log.Printf("feishu app uninstalled by tenant_key %s", tenantKey)
+	case "app_status_change":
+		t, err := h.cfg.Tenants.Get(ctx, tenantKey)
+		if err != nil {
+			// 安装发生在本服务上线前：补记录
+			t = model.FeishuTenant{TenantKey: tenantKey, TenantID: h.tenantID(tenantKey), InstalledAt: time.Now()}
+		}
+		t.Active = ev.Event.Status == "start_by_tenant"
+		if err := h.cfg.Tenants.Save(ctx, t); err != nil {
+			return fmt.Errorf("save tenant %s: %w", tenantKey, err)
+		}
+		if t.Active {
+			h.cfg.Client.AddTenant(t.TenantID, tenantKey)
+		} else {
+			h.cfg.Client.RemoveTenant(tenantKey)
+		}
+		log.Printf("feishu app status of tenant_key %s: %s", tenantKey, ev.Event.Status)
+	}
+	return nil
+}
+
+// receiveMessage 异步处理机器人收到的用户消息并立即返回，处理结果回复到原消息
+func (h *FeishuEventHandler) receiveMessage(c *gin.Context, ev feishuEvent) {
+	if h.cfg.Bot == nil || ev.Event.Sender.SenderType != "user" || h.duplicate(ev.Header.EventID) {
+		return
+	}
+	msg := ev.Event.Message
+	msg.SenderOpenID = ev.Event.Sender.SenderID.OpenID
+	if h.cfg.Marketplace {
+		msg.TenantID = h.tenantID(ev.Header.TenantKey)
+	}
+	requestID := trace.From(c.Request.Context()).RequestID()
+	go func() {
+		ctx, _ := trace.With(context.Background(), requestID)
+		ctx, cancel := context.WithTimeout(ctx, feishuMessageTimeout)
+		defer cancel()
+		defer h.panics.Recover(ctx, "feishu message")
+		req, err := h.cfg.Bot.Request(ctx, msg)
+		var resp model.ASRResponse
+		if err != nil {
+			log.Printf("feishu message %s from %s: %v", msg.MessageID, msg.SenderOpenID, err)
+			resp.Message = "消息处理失败：" + err.Error()
+		} else if resp, err = h.asrService.ProcessFrom(ctx, req, model.TaskSourceChat); err != nil {
+			log.Printf("feishu message %s from %s: task %s failed: %v", msg.MessageID, msg.SenderOpenID, resp.TaskID, err)
+		}
+		if err := h.cfg.Bot.Reply(ctx, msg, resp); err != nil {
+			log.Printf("feishu message %s: reply: %v", msg.MessageID, err)
+		}
+	}()
+}
+
+// duplicate 事件是否已处理过（飞书重推）；顺带清理过期记录
+func (h *FeishuEventHandler) duplicate(eventID string) bool {
+	if eventID == "" {
+		return false
+	}
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, t := range h.seen {
+		if now.Sub(t) > feishuEventDedupWindow {
+			delete(h.seen, id)
+		}
+	}
+	if _, ok := h.seen[eventID]; ok {
+		return true
+	}
+	h.seen[eventID] = now
+	return false
+}
+
+// List 安装了应用的飞书企业
+// GET /api/v1/feishu/tenants
+func (h *FeishuEventHandler) List(c *gin.Context) {
+	list, err := h.cfg.Tenants.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tenants": list})
+}
+
+func (h *FeishuEventHandler) tenantID(tenantKey string) string {
+	if id := h.cfg.TenantIDs[tenantKey]; id != "" {
+		return id
+	}
+	return tenantKey
+}
+
+// validSignature 校验 X-Lark-Signature：sha256(timestamp + nonce + encrypt_key + body)
+func (h *FeishuEventHandler) validSignature(c *gin.Context, body []byte) bool {
+	sum := sha256.Sum256([]byte(c.GetHeader("X-Lark-Request-Timestamp") + c.GetHeader("X-Lark-Request-Nonce") + h.cfg.EncryptKey + string(body)))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(c.GetHeader("X-Lark-Signature"))) == 1
+}
+
+// decrypt 解密事件：AES-256-CBC，密钥为 sha256(encrypt_key)，密文前 16 字节为 IV
+func (h *FeishuEventHandler) decrypt(encrypted string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2*aes.BlockSize || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("bad ciphertext length %d", len(data))
+	}
+	key := sha256.Sum256([]byte(h.cfg.EncryptKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	plain := make([]byte, len(data)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).CryptBlocks(plain, data[aes.BlockSize:])
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > aes.BlockSize {
+		return nil, fmt.Errorf("bad padding")
+	}
+	return plain[:len(plain)-pad], nil
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/store"
+)
+
+// FolderRuleHandler 管理租户的文档归档规则
+type FolderRuleHandler struct {
+	store store.FolderRuleStore
+}
+
+// NewFolderRuleHandler 创建归档规则处理器
+func NewFolderRuleHandler(s store.FolderRuleStore) *FolderRuleHandler {
+	return &FolderRuleHandler{store: s}
+}
+
+// Save 新增或覆盖同名规则
+// POST /api/v1/folder-rules
+func (h *FolderRuleHandler) Save(c *gin.Context) {
+	var rule model.FolderRule
+	if !bindJSON(c, &rule) {
+		return
+	}
+	if rule.FolderName == "" && rule.FolderToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "folder_name or folder_token is required"})
+		return
+	}
+	if _, ok := tokenTenant(c); ok || rule.TenantID == "" {
+		rule.TenantID = tenantOf(c)
+	}
+	if err := h.store.Save(c.Request.Context(), rule); err != nil {
+		writeStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// List 列出租户的规则（按匹配顺序）
+// GET /api/v1/folder-rules
+func (h *FolderRuleHandler) List(c *gin.Context) {
+	rules, err := h.store.List(c.Request.Context(), tenantOf(c))
+	if err != nil {
+		writeStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// Delete 删除规则
+// DELETE /api/v1/folder-rules/:name
+func (h *FolderRuleHandler) Delete(c *gin.Context) {
+	if err := h.store.Delete(c.Request.Context(), tenantOf(c), c.Param("name")); err != nil {
+		writeStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": c.Param("name")})
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/middleware"
+	"sayso-agent/internal/model"
+)
+
+// applyIdentity 已认证时以令牌中的身份为准：覆盖请求体中的 user_id、租户，并补充 context.user_name
+func applyIdentity(c *gin.Context, req *model.ASRRequest) {
+	id, ok := middleware.IdentityOf(c)
+	if !ok {
+		return
+	}
+	req.UserID = id.UserID
+	if id.TenantID != "" {
+		req.TenantID = id.TenantID
+	}
+	if id.Name != "" {
+		if req.Context == nil {
+			req.Context = make(map[string]string)
+		}
+		req.Context["user_name"] = id.Name
+	}
+}
+
+// tokenTenant 令牌中带有的租户
+func tokenTenant(c *gin.Context) (string, bool) {
+	id, ok := middleware.IdentityOf(c)
+	if !ok || id.TenantID == "" {
+		return "", false
+	}
+	return id.TenantID, true
+}
@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/service/maintenance"
+)
+
+// maintenanceStatus 后台维护任务状态：本副本是否为主、各任务的上次运行时间、耗时、错误与下次运行时间
+// GET /api/v1/maintenance
+func maintenanceStatus(d *maintenance.Daemon) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, d.Status())
+	}
+}
@@ -2,23 +2,171 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/auth"
 	"sayso-agent/internal/middleware"
+	"sayso-agent/internal/panics"
+	"sayso-agent/internal/plugin"
 	"sayso-agent/internal/service"
+	"sayso-agent/internal/service/analytics"
+	"sayso-agent/internal/service/maintenance"
+	"sayso-agent/internal/service/slo"
+	"sayso-agent/internal/store"
 )
 
+// Options 路由依赖；可选功能为 nil 时不注册对应路由
+type Options struct {
+	ASR         *service.ASRService
+	Workflows   store.WorkflowStore
+	Tasks       store.TaskStore
+	FolderRules store.FolderRuleStore
+	Identities  store.IdentityStore
+	Email       *EmailConfig // 入站邮件，nil 表示未启用
+	// SlackOAuth Slack OAuth 安装，nil 表示未启用
+	SlackOAuth *SlackOAuthConfig
+	// FeishuEvents 飞书事件订阅（商店应用的 app_ticket 与安装事件、机器人收到的消息），nil 表示未启用
+	FeishuEvents *FeishuEventConfig
+	// FeishuCard 飞书卡片回调（草稿预览的「发送」「修改」按钮），nil 表示未启用
+	FeishuCard *FeishuCardConfig
+	// Analytics 运营统计，nil 表示不注册统计接口
+	Analytics *analytics.Analyzer
+	// Plugins 外部插件，健康状态附在 /health 中；nil 表示不展示
+	Plugins *plugin.Registry
+	// Maintenance 后台维护任务，nil 表示不注册状态接口
+	Maintenance *maintenance.Daemon
+	// MaxBodyBytes 请求体大小上限（gzip 请求按解压后计），0 表示不限制
+	MaxBodyBytes int64
+	// Gzip 客户端接受时压缩响应
+	Gzip bool
+	// CORS 浏览器跨域访问，nil 表示不允许跨域
+	CORS *middleware.CORSConfig
+	// Auth 校验 Bearer 令牌（如 OIDC 签发的 JWT），nil 表示不认证；入站邮件使用自己的密钥，不经过此校验
+	Auth middleware.TokenVerifier
+	// AccessLog 访问日志的格式、慢请求阈值与采样比例
+	AccessLog middleware.LoggerConfig
+	// ContinueOnDisconnect 同步处理接口的客户端断开后继续在后台完成任务（结果写入任务记录），否则取消处理
+	ContinueOnDisconnect bool
+	// AdminUI 在 /admin 提供内嵌的运维页面（任务、计划依赖图、重试/确认/取消）
+	AdminUI bool
+	// Panics panic 上报，恢复次数附在 /health 中；nil 表示只写日志
+	Panics *panics.Reporter
+	// SLO 延迟目标跟踪，nil 表示不注册状态接口
+	SLO *slo.Tracker
+	// Blobs 本地对象存储，在 /blobs/ 下按签名链接提供下载；nil 表示不注册
+	Blobs *store.LocalBlobStore
+}
+
 // Router 注册路由与中间件
-func Router(svc *service.ASRService) *gin.Engine {
+func Router(opts Options) *gin.Engine {
 	r := gin.New()
-	r.Use(middleware.Recovery(), middleware.Logger())
+	if opts.CORS != nil {
+		// 预检请求在其他中间件之前直接响应
+		r.Use(middleware.CORS(*opts.CORS))
+	}
+	if opts.Gzip {
+		r.Use(middleware.Gzip())
+	}
+	// 访问日志在 Recovery 之外，panic 的请求也记录 500 与阶段耗时
+	r.Use(middleware.Logger(opts.AccessLog), middleware.Recovery(opts.Panics), middleware.BodyLimit(opts.MaxBodyBytes))
 
-	asrHandler := NewASRHandler(svc)
+	asrHandler := NewASRHandler(opts.ASR, opts.ContinueOnDisconnect)
+	workflowHandler := NewWorkflowHandler(opts.Workflows)
+	taskHandler := NewTaskHandler(opts.ASR, opts.Tasks)
+	folderRuleHandler := NewFolderRuleHandler(opts.FolderRules)
+	identityHandler := NewUserIdentityHandler(opts.Identities)
 	v1 := r.Group("/api/v1")
+	api := v1.Group("")
+	if opts.Auth != nil {
+		api.Use(middleware.Auth(opts.Auth))
+	}
 	{
-		v1.POST("/asr/process", asrHandler.Process)
+		api.POST("/asr/process", asrHandler.Process)
+
+		// 工作流为租户级配置，定时运行时以 schedule.user_id 的身份执行，修改需 admin 角色
+		api.POST("/workflows", middleware.RequireRole(auth.RoleAdmin), workflowHandler.Save)
+		api.GET("/workflows", workflowHandler.List)
+		api.GET("/workflows/:name", workflowHandler.Get)
+		api.DELETE("/workflows/:name", middleware.RequireRole(auth.RoleAdmin), workflowHandler.Delete)
+
+		// 归档规则为租户级运行时配置，修改需 admin 角色
+		api.POST("/folder-rules", middleware.RequireRole(auth.RoleAdmin), folderRuleHandler.Save)
+		api.GET("/folder-rules", folderRuleHandler.List)
+		api.DELETE("/folder-rules/:name", middleware.RequireRole(auth.RoleAdmin), folderRuleHandler.Delete)
+
+		// 身份映射决定消息发往谁的哪个账号，修改需 admin 角色
+		api.POST("/identities", middleware.RequireRole(auth.RoleAdmin), identityHandler.Save)
+		api.GET("/identities", identityHandler.List)
+		api.GET("/identities/lookup", identityHandler.Lookup)
+		api.DELETE("/identities/:name", middleware.RequireRole(auth.RoleAdmin), identityHandler.Delete)
+
+		// 列表含其他用户的任务，需 operator 角色
+		api.GET("/tasks", middleware.RequireRole(auth.RoleOperator), taskHandler.List)
+		api.GET("/tasks/:id", taskHandler.Get)
+		api.POST("/tasks/:id/confirm", taskHandler.Confirm)
+		api.POST("/tasks/:id/cancel", taskHandler.Cancel)
+		api.POST("/tasks/:id/retry", taskHandler.Retry)
+		api.POST("/tasks/:id/replay", taskHandler.Replay)
+		api.POST("/tasks/:id/feedback", taskHandler.Feedback)
+		// 导出的评测用例含其他用户的输入，需 operator 角色
+		api.GET("/feedback/export", middleware.RequireRole(auth.RoleOperator), taskHandler.ExportFeedback)
+	}
+	if opts.Analytics != nil {
+		// 统计含全部用户的数据，需 operator 角色
+		analyticsHandler := NewAnalyticsHandler(opts.Analytics)
+		ops := api.Group("/analytics", middleware.RequireRole(auth.RoleOperator))
+		ops.GET("/actions", analyticsHandler.Actions)
+		ops.GET("/outcomes", analyticsHandler.Outcomes)
+		ops.GET("/cost", analyticsHandler.Cost)
+		// 成本报表用于结算，需 admin 角色
+		api.GET("/reports/cost", middleware.RequireRole(auth.RoleAdmin), analyticsHandler.CostReport)
+	}
+	if opts.Maintenance != nil {
+		// 运维查看缓存刷新与索引重建情况，需 operator 角色
+		api.GET("/maintenance", middleware.RequireRole(auth.RoleOperator), maintenanceStatus(opts.Maintenance))
+	}
+	if opts.SLO != nil {
+		// 各阶段延迟目标的达标率与预算消耗速率，需 operator 角色
+		api.GET("/slo", middleware.RequireRole(auth.RoleOperator), sloStatus(opts.SLO))
+	}
+	if opts.Email != nil {
+		v1.POST("/inbound/email", NewEmailHandler(opts.ASR, *opts.Email, opts.Panics).Receive)
+	}
+	if opts.SlackOAuth != nil {
+		slackOAuth := NewSlackOAuthHandler(*opts.SlackOAuth)
+		// 安装链接会绑定租户的默认工作区，需 admin 角色；回调由 Slack 重定向，凭签名的 state 校验
+		api.GET("/slack/oauth/start", middleware.RequireRole(auth.RoleAdmin), slackOAuth.Start)
+		r.GET("/slack/oauth/callback", slackOAuth.Callback)
+	}
+	if opts.FeishuEvents != nil {
+		feishuEvents := NewFeishuEventHandler(*opts.FeishuEvents, opts.ASR, opts.Panics)
+		// 事件由飞书推送，凭 Verification Token / 签名校验
+		r.POST("/feishu/events", feishuEvents.Receive)
+		api.GET("/feishu/tenants", middleware.RequireRole(auth.RoleAdmin), feishuEvents.List)
+	}
+	if opts.FeishuCard != nil {
+		// 回调由飞书推送，凭 Verification Token 校验，操作人须为任务请求人
+		r.POST("/feishu/card", NewFeishuCardHandler(*opts.FeishuCard, opts.ASR).Receive)
+	}
+
+	if opts.Blobs != nil {
+		// 导出文件、语音、图表的下载链接，凭链接中的签名与过期时间校验
+		r.GET("/blobs/*key", blobDownload(opts.Blobs))
 	}
 
+	if opts.AdminUI {
+		// 页面本身不含数据，通过 /api/v1 接口（携带页面中填写的令牌）读取任务
+		r.GET("/admin", adminPage)
+	}
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
+		resp := gin.H{"status": "ok"}
+		if opts.Panics != nil {
+			resp["panics"] = opts.Panics.Count()
+		}
+		if opts.Plugins != nil {
+			if st := opts.Plugins.Status(); len(st) > 0 {
+				resp["plugins"] = st
+			}
+		}
+		c.JSON(200, resp)
 	})
 	return r
 }
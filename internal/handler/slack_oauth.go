@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/client/slack"
+	"sayso-agent/internal/middleware"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/store"
+)
+
+// slackStateTTL 安装链接的有效期
+const slackStateTTL = 10 * time.Minute
+
+// slackNonceCookie 发起安装的浏览器持有的随机数，回调时须与 state 中的一致，防止把他人发起的安装链接用于自己的浏览器
+const (
+	slackNonceCookie     = "slack_oauth_nonce"
+	slackNonceCookiePath = "/slack/oauth/callback"
+)
+
+// SlackOAuthConfig Slack OAuth 安装配置
+type SlackOAuthConfig struct {
+	Client   *slack.Client
+	Installs store.SlackInstallStore
+	// RedirectURL 授权回调地址，须与 Slack App 中配置的一致，如 https://agent.example.com/slack/oauth/callback
+	RedirectURL string
+	// StateSecret 签名 state 的专用密钥（slack.oauth.state_secret），不可复用 client_secret
+	StateSecret string
+}
+
+// SlackOAuthHandler 把 Bot 安装到新的 Slack 工作区：发起授权、处理回调并保存 Bot Token
+type SlackOAuthHandler struct {
+	cfg SlackOAuthConfig
+}
+
+// NewSlackOAuthHandler 创建 Slack OAuth 处理器
+func NewSlackOAuthHandler(cfg SlackOAuthConfig) *SlackOAuthHandler {
+	return &SlackOAuthHandler{cfg: cfg}
+}
+
+// Start 生成安装链接，由管理后台在同一浏览器中打开；随机数写入 HttpOnly cookie 并签入 state，
+// 安装完成后该工作区成为发起租户的默认工作区
+// GET /api/v1/slack/oauth/start
+func (h *SlackOAuthHandler) Start(c *gin.Context) {
+	var user string
+	if id, ok := middleware.IdentityOf(c); ok {
+		user = id.UserID
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "generate nonce failed"})
+		return
+	}
+	nonce := hex.EncodeToString(b)
+	h.setNonceCookie(c, nonce, int(slackStateTTL/time.Second))
+	state := h.signState(tenantOf(c), user, nonce, time.Now().Add(slackStateTTL))
+	c.JSON(http.StatusOK, gin.H{"url": h.cfg.Client.InstallURL(state, h.cfg.RedirectURL)})
+}
+
+// Callback Slack 授权后的回调：校验 state，用 code 换取 Bot Token 并保存
+// GET /slack/oauth/callback
+func (h *SlackOAuthHandler) Callback(c *gin.Context) {
+	if e := c.Query("error"); e != "" {
+		c.String(http.StatusBadRequest, "Slack 授权未完成：%s", e)
+		return
+	}
+	nonce, _ := c.Cookie(slackNonceCookie)
+	tenant, user, err := h.verifyState(c.Query("state"), nonce, time.Now())
+	if err != nil {
+		c.String(http.StatusBadRequest, "安装链接无效或已过期，请在发起安装的浏览器中重新发起：%v", err)
+		return
+	}
+	// 随机数只能使用一次
+	h.setNonceCookie(c, "", -1)
+	code := c.Query("code")
+	if code == "" {
+		c.String(http.StatusBadRequest, "缺少授权 code")
+		return
+	}
+	access, err := h.cfg.Client.ExchangeCode(c.Request.Context(), code, h.cfg.RedirectURL)
+	if err != nil {
+		log.Printf("slack oauth exchange: %v", err)
+		c.String(http.StatusBadGateway, "换取 Slack Bot Token 失败：%v", err)
+		return
+	}
+	in := model.SlackInstallation{
+		TeamID:       access.TeamID,
+		TeamName:     access.TeamName,
+		EnterpriseID: access.EnterpriseID,
+		BotUserID:    access.BotUserID,
+		BotToken:     access.AccessToken,
+		Scope:        access.Scope,
+		TenantID:     tenant,
+		InstalledBy:  user,
+		InstalledAt:  time.Now(),
+	}
+	if in.InstalledBy == "" {
+		in.InstalledBy = access.InstallerID
+	}
+	if err := h.cfg.Installs.Save(c.Request.Context(), in); err != nil {
+		c.String(http.StatusInternalServerError, "保存安装记录失败：%v", err)
+		return
+	}
+	h.cfg.Client.AddWorkspace(slack.InstalledWorkspace(in))
+	log.Printf("slack installed to team %s (%s) for tenant %s", in.TeamID, in.TeamName, tenant)
+	c.String(http.StatusOK, "已安装到 Slack 工作区「%s」，可以关闭此页面", in.TeamName)
+}
+
+// setNonceCookie 写入（maxAge 小于 0 时清除）随机数 cookie；只发往回调地址，回调地址为 https 时加 Secure。
+// 从 Slack 跳转回来是顶层 GET 导航，SameSite=Lax 下会带上 cookie
+func (h *SlackOAuthHandler) setNonceCookie(c *gin.Context, nonce string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(slackNonceCookie, nonce, maxAge, slackNonceCookiePath, "", strings.HasPrefix(h.cfg.RedirectURL, "https://"), true)
+}
+
+// signState state 格式：base64(tenant|user|过期时间戳|随机数).hex(HMAC)
+func (h *SlackOAuthHandler) signState(tenant, user, nonce string, expires time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(tenant + "|" + user + "|" + strconv.FormatInt(expires.Unix(), 10) + "|" + nonce))
+	return payload + "." + h.stateMAC(payload)
+}
+
+// verifyState 校验 state 签名、有效期与浏览器持有的随机数，返回发起安装的租户与用户
+func (h *SlackOAuthHandler) verifyState(state, nonce string, now time.Time) (tenant, user string, err error) {
+	payload, mac, ok := strings.Cut(state, ".")
+	if !ok || !hmac.Equal([]byte(mac), []byte(h.stateMAC(payload))) {
+		return "", "", fmt.Errorf("bad signature")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", "", fmt.Errorf("bad payload")
+	}
+	parts := strings.Split(string(b), "|")
+	if len(parts) != 4 {
+		return "", "", fmt.Errorf("bad payload")
+	}
+	exp, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || now.Unix() > exp {
+		return "", "", fmt.Errorf("expired")
+	}
+	if nonce == "" || !hmac.Equal([]byte(nonce), []byte(parts[3])) {
+		return "", "", fmt.Errorf("nonce mismatch")
+	}
+	return parts[0], parts[1], nil
+}
+
+func (h *SlackOAuthHandler) stateMAC(payload string) string {
+	m := hmac.New(sha256.New, []byte(h.cfg.StateSecret))
+	m.Write([]byte(payload))
+	return hex.EncodeToString(m.Sum(nil))
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/client/slack"
+	"sayso-agent/internal/store"
+)
+
+func newOAuthTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewSlackOAuthHandler(SlackOAuthConfig{
+		Client:      slack.NewClient(slack.Config{ClientID: "cid", ClientSecret: "csecret", Scopes: []string{"chat:write"}}),
+		Installs:    store.NewMemorySlackInstallStore(),
+		RedirectURL: "https://agent.example.com/slack/oauth/callback",
+		StateSecret: "state-secret",
+	})
+	r := gin.New()
+	r.GET("/start", h.Start)
+	r.GET("/slack/oauth/callback", h.Callback)
+	return r
+}
+
+// startInstall 发起安装，返回 state 与写入的随机数 cookie
+func startInstall(t *testing.T, r *gin.Engine) (string, *http.Cookie) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/start?tenant_id=acme", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("start status = %d", w.Code)
+	}
+	var cookie *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == slackNonceCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil || cookie.Value == "" {
+		t.Fatal("start did not set nonce cookie")
+	}
+	if !cookie.HttpOnly || !cookie.Secure || cookie.Path != slackNonceCookiePath || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie = %+v, want HttpOnly, Secure, SameSite=Lax, path %s", cookie, slackNonceCookiePath)
+	}
+	var resp struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(resp.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query().Get("state"), cookie
+}
+
+func TestSlackOAuthCallbackNonce(t *testing.T) {
+	r := newOAuthTestRouter()
+	state, cookie := startInstall(t, r)
+	_, other := startInstall(t, r)
+
+	tests := []struct {
+		name     string
+		cookie   *http.Cookie
+		wantCode int
+		wantBody string
+	}{
+		// 随机数一致时通过 state 校验，因缺少 code 在换取 token 之前返回
+		{name: "same browser", cookie: cookie, wantCode: http.StatusBadRequest, wantBody: "缺少授权 code"},
+		{name: "no cookie", wantCode: http.StatusBadRequest, wantBody: "nonce mismatch"},
+		{name: "another install's cookie", cookie: other, wantCode: http.StatusBadRequest, wantBody: "nonce mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/slack/oauth/callback?state="+url.QueryEscape(state), nil)
+			if tt.cookie != nil {
+				req.AddCookie(&http.Cookie{Name: tt.cookie.Name, Value: tt.cookie.Value})
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.wantCode || !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("callback = %d %q, want %d containing %q", w.Code, w.Body.String(), tt.wantCode, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSlackOAuthVerifyState(t *testing.T) {
+	h := NewSlackOAuthHandler(SlackOAuthConfig{StateSecret: "state-secret"})
+	now := time.Now()
+	state := h.signState("acme", "u1", "n1", now.Add(slackStateTTL))
+
+	if tenant, user, err := h.verifyState(state, "n1", now); err != nil || tenant != "acme" || user != "u1" {
+		t.Fatalf("verifyState = %q, %q, %v", tenant, user, err)
+	}
+	if _, _, err := h.verifyState(state, "n1", now.Add(slackStateTTL+time.Minute)); err == nil {
+		t.Error("expired state accepted")
+	}
+	other := NewSlackOAuthHandler(SlackOAuthConfig{StateSecret: "another-secret"})
+	if _, _, err := other.verifyState(state, "n1", now); err == nil {
+		t.Error("state signed with another secret accepted")
+	}
+	payload, mac, _ := strings.Cut(state, ".")
+	if _, _, err := h.verifyState(payload+"x."+mac, "n1", now); err == nil {
+		t.Error("tampered state accepted")
+	}
+}
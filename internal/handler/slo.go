@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/service/slo"
+)
+
+// sloStatus 延迟目标状态：各阶段在短、长窗口的请求数、超时数、达标率与预算消耗速率，以及当前是否降级
+// GET /api/v1/slo
+func sloStatus(t *slo.Tracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, t.Status())
+	}
+}
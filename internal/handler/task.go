@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/auth"
+	"sayso-agent/internal/middleware"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/service"
+	"sayso-agent/internal/store"
+)
+
+// TaskHandler 查询任务记录，确认/取消待确认的任务，重试失败的任务，重放历史任务
+type TaskHandler struct {
+	asrService *service.ASRService
+	tasks      store.TaskStore
+}
+
+// NewTaskHandler 创建任务处理器
+func NewTaskHandler(svc *service.ASRService, tasks store.TaskStore) *TaskHandler {
+	return &TaskHandler{asrService: svc, tasks: tasks}
+}
+
+// taskListMax 任务列表单次返回的记录数上限
+const taskListMax = 200
+
+// List 最近的任务记录（新的在前），可按状态、来源、用户筛选；tenant_id=all 且令牌未限定租户时列出全部租户
+// GET /api/v1/tasks?tenant_id=&status=&source=&user_id=&limit=50
+func (h *TaskHandler) List(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > taskListMax {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(taskListMax)})
+		return
+	}
+	filter := store.TaskFilter{
+		TenantID: tenantOf(c),
+		UserID:   c.Query("user_id"),
+		Source:   c.Query("source"),
+		Status:   c.Query("status"),
+		Limit:    limit,
+	}
+	if _, scoped := tokenTenant(c); !scoped && c.Query("tenant_id") == "all" {
+		filter.TenantID = ""
+	}
+	list, err := h.tasks.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tasks": list})
+}
+
+// Get 获取任务记录；他人的任务需 operator 角色
+// GET /api/v1/tasks/:id
+func (h *TaskHandler) Get(c *gin.Context) {
+	rec, ok := h.authorize(c, auth.RoleOperator)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, rec)
+}
+
+// Confirm 确认待确认的动作并继续执行；确认他人的动作需 admin 角色
+// POST /api/v1/tasks/:id/confirm
+func (h *TaskHandler) Confirm(c *gin.Context) {
+	if _, ok := h.authorize(c, auth.RoleAdmin); !ok {
+		return
+	}
+	resp, err := h.asrService.Confirm(c.Request.Context(), c.Param("id"))
+	h.writeResult(c, resp, err)
+}
+
+// Cancel 取消待确认的任务；他人的任务需 operator 角色
+// POST /api/v1/tasks/:id/cancel
+func (h *TaskHandler) Cancel(c *gin.Context) {
+	if _, ok := h.authorize(c, auth.RoleOperator); !ok {
+		return
+	}
+	resp, err := h.asrService.Cancel(c.Request.Context(), c.Param("id"))
+	h.writeResult(c, resp, err)
+}
+
+// Retry 重新执行失败任务中未完成的动作；他人的任务需 operator 角色
+// POST /api/v1/tasks/:id/retry
+func (h *TaskHandler) Retry(c *gin.Context) {
+	if _, ok := h.authorize(c, auth.RoleOperator); !ok {
+		return
+	}
+	resp, err := h.asrService.Retry(c.Request.Context(), c.Param("id"))
+	h.writeResult(c, resp, err)
+}
+
+// Replay 按任务的计划重新执行（不调用大模型），可替换接收方、标题或按 task_id 覆盖参数，结果记为新任务；
+// 请求体可省略；他人的任务需 operator 角色
+// POST /api/v1/tasks/:id/replay
+func (h *TaskHandler) Replay(c *gin.Context) {
+	if _, ok := h.authorize(c, auth.RoleOperator); !ok {
+		return
+	}
+	var req service.ReplayOverrides
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+	resp, err := h.asrService.Replay(c.Request.Context(), c.Param("id"), req)
+	h.writeResult(c, resp, err)
+}
+
+// feedbackRequest 任务反馈请求体
+type feedbackRequest struct {
+	Rating     string `json:"rating" binding:"required,oneof=up down"`
+	Correction string `json:"correction"`
+}
+
+// Feedback 对任务结果点赞/点踩，可附正确做法的说明；再次提交会覆盖之前的反馈；他人的任务需 operator 角色
+// POST /api/v1/tasks/:id/feedback
+func (h *TaskHandler) Feedback(c *gin.Context) {
+	rec, ok := h.authorize(c, auth.RoleOperator)
+	if !ok {
+		return
+	}
+	var req feedbackRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	fb := model.TaskFeedback{Rating: req.Rating, Correction: strings.TrimSpace(req.Correction), UserID: rec.UserID, CreatedAt: time.Now()}
+	if id, ok := middleware.IdentityOf(c); ok {
+		fb.UserID = id.UserID
+	}
+	// 只写反馈：任务可能仍在执行或等待确认，不能用读到的旧记录覆盖状态与结果
+	if err := h.tasks.SetFeedback(c.Request.Context(), rec.ID, fb); err != nil {
+		writeStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, fb)
+}
+
+// feedbackExportMax 反馈导出单次返回的记录数上限
+const feedbackExportMax = 5000
+
+// ExportFeedback 导出带反馈的任务作为 Prompt 评测用例（每行一个 JSON）；rating 默认为 down（计划出错的任务），
+// any 导出全部反馈
+// GET /api/v1/feedback/export?tenant_id=&rating=down&limit=500
+func (h *TaskHandler) ExportFeedback(c *gin.Context) {
+	rating := c.DefaultQuery("rating", model.FeedbackDown)
+	if rating != model.FeedbackUp && rating != model.FeedbackDown && rating != "any" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rating must be one of up | down | any"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "500"))
+	if err != nil || limit <= 0 || limit > feedbackExportMax {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(feedbackExportMax)})
+		return
+	}
+	list, err := h.tasks.List(c.Request.Context(), store.TaskFilter{TenantID: tenantOf(c), Feedback: rating, Limit: limit})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="feedback.jsonl"`)
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	enc.SetEscapeHTML(false)
+	for _, rec := range list {
+		if err := enc.Encode(model.NewFeedbackCase(rec)); err != nil {
+			return
+		}
+	}
+}
+
+// authorize 读取任务并检查调用方权限：令牌限定租户时只能访问本租户的任务（其他租户的任务按不存在处理，
+// 任务 ID 可被猜到）；本人的任务总是可以操作，他人的任务需具备 role；失败时已写出响应
+func (h *TaskHandler) authorize(c *gin.Context, role auth.Role) (model.TaskRecord, bool) {
+	rec, err := h.tasks.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeStoreError(c, err)
+		return rec, false
+	}
+	tenant := rec.TenantID
+	if tenant == "" {
+		tenant = model.DefaultTenant
+	}
+	if t, scoped := tokenTenant(c); scoped && t != tenant {
+		c.JSON(http.StatusNotFound, gin.H{"error": store.ErrNotFound.Error()})
+		return model.TaskRecord{}, false
+	}
+	if !middleware.CallerCan(c, rec.UserID, role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "requires role " + string(role) + " for tasks of other users"})
+		return rec, false
+	}
+	return rec, true
+}
+
+func (h *TaskHandler) writeResult(c *gin.Context, resp model.ASRResponse, err error) {
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, resp)
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotAwaitingConfirmation), errors.Is(err, service.ErrNotRetryable), errors.Is(err, service.ErrNotReplayable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, model.ErrInvalidParams) && resp.TaskID == "":
+		// 请求本身不合法（如重放的替换参数无效），未创建任务
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "result": resp})
+	}
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/auth"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/store"
+)
+
+// tokenVerifier 测试用：令牌即身份，格式为 user|tenant|role
+type tokenVerifier struct{}
+
+func (tokenVerifier) Verify(_ context.Context, token string) (auth.Identity, error) {
+	parts := strings.SplitN(token, "|", 3)
+	return auth.Identity{UserID: parts[0], TenantID: parts[1], Role: auth.Role(parts[2])}, nil
+}
+
+func serve(t *testing.T, r http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTaskAuthorizeTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tasks := store.NewMemoryTaskStore(10)
+	ctx := context.Background()
+	for _, rec := range []model.TaskRecord{
+		{ID: "1", TenantID: "acme", UserID: "ou_alice"},
+		{ID: "2", TenantID: "", UserID: "ou_alice"}, // 默认租户
+	} {
+		if err := tasks.Save(ctx, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := Router(Options{Tasks: tasks, Workflows: store.NewMemoryWorkflowStore(), Auth: tokenVerifier{}})
+
+	tests := []struct {
+		name  string
+		id    string
+		token string
+		want  int
+	}{
+		{name: "owner in tenant", id: "1", token: "ou_alice|acme|caller", want: http.StatusOK},
+		{name: "operator in tenant", id: "1", token: "ou_bob|acme|operator", want: http.StatusOK},
+		{name: "caller in tenant", id: "1", token: "ou_bob|acme|caller", want: http.StatusForbidden},
+		{name: "admin of other tenant", id: "1", token: "ou_bob|globex|admin", want: http.StatusNotFound},
+		{name: "same user id in other tenant", id: "1", token: "ou_alice|globex|caller", want: http.StatusNotFound},
+		{name: "unscoped operator", id: "1", token: "ou_bob||operator", want: http.StatusOK},
+		{name: "default tenant", id: "2", token: "ou_alice|" + model.DefaultTenant + "|caller", want: http.StatusOK},
+		{name: "default tenant from other tenant", id: "2", token: "ou_alice|acme|admin", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := serve(t, r, http.MethodGet, "/api/v1/tasks/"+tt.id, tt.token, ""); w.Code != tt.want {
+				t.Errorf("GET task %s = %d, want %d: %s", tt.id, w.Code, tt.want, w.Body)
+			}
+		})
+	}
+	// 其他租户的任务在确认、取消等操作前即被拒绝
+	for _, op := range []string{"confirm", "cancel", "retry", "replay"} {
+		if w := serve(t, r, http.MethodPost, "/api/v1/tasks/1/"+op, "ou_bob|globex|admin", ""); w.Code != http.StatusNotFound {
+			t.Errorf("POST %s = %d, want 404", op, w.Code)
+		}
+	}
+}
+
+func TestWorkflowRoutesRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	workflows := store.NewMemoryWorkflowStore()
+	r := Router(Options{Tasks: store.NewMemoryTaskStore(10), Workflows: workflows, Auth: tokenVerifier{}})
+	const body = `{"name":"上线流程","triggers":["上线流程"],"tasks":[{"id":"task_1","skill":"create_doc","input":"创建上线记录"}]}`
+
+	if w := serve(t, r, http.MethodPost, "/api/v1/workflows", "ou_bob|acme|operator", body); w.Code != http.StatusForbidden {
+		t.Fatalf("operator save = %d, want 403", w.Code)
+	}
+	if w := serve(t, r, http.MethodPost, "/api/v1/workflows", "ou_admin|acme|admin", body); w.Code != http.StatusOK {
+		t.Fatalf("admin save = %d: %s", w.Code, w.Body)
+	}
+	// 令牌限定租户时请求体与 query 中的租户无效
+	if w := serve(t, r, http.MethodGet, "/api/v1/workflows/上线流程?tenant_id=acme", "ou_eve|globex|caller", ""); w.Code != http.StatusNotFound {
+		t.Errorf("other tenant get = %d, want 404", w.Code)
+	}
+	if w := serve(t, r, http.MethodGet, "/api/v1/workflows/上线流程", "ou_bob|acme|caller", ""); w.Code != http.StatusOK {
+		t.Errorf("same tenant get = %d, want 200", w.Code)
+	}
+	if w := serve(t, r, http.MethodDelete, "/api/v1/workflows/上线流程", "ou_bob|acme|operator", ""); w.Code != http.StatusForbidden {
+		t.Errorf("operator delete = %d, want 403", w.Code)
+	}
+}
+
+// racingTasks 读取记录后模拟执行器并发把任务改为成功
+type racingTasks struct {
+	store.TaskStore
+}
+
+func (s racingTasks) Get(ctx context.Context, id string) (model.TaskRecord, error) {
+	rec, err := s.TaskStore.Get(ctx, id)
+	if err == nil {
+		_, err = s.TaskStore.Transition(ctx, id, model.TaskStatusRunning, model.TaskStatusSucceeded)
+	}
+	return rec, err
+}
+
+func TestFeedbackKeepsConcurrentStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tasks := store.NewMemoryTaskStore(10)
+	ctx := context.Background()
+	if err := tasks.Save(ctx, model.TaskRecord{ID: "1", TenantID: "acme", UserID: "ou_alice", Status: model.TaskStatusRunning}); err != nil {
+		t.Fatal(err)
+	}
+	r := Router(Options{Tasks: racingTasks{tasks}, Workflows: store.NewMemoryWorkflowStore(), Auth: tokenVerifier{}})
+
+	w := serve(t, r, http.MethodPost, "/api/v1/tasks/1/feedback", "ou_alice|acme|caller", `{"rating":"down","correction":"应该发给产品组"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST feedback = %d: %s", w.Code, w.Body)
+	}
+	rec, _ := tasks.Get(ctx, "1")
+	if rec.Status != model.TaskStatusSucceeded {
+		t.Errorf("status = %s, feedback overwrote the concurrent update", rec.Status)
+	}
+	if rec.Feedback == nil || rec.Feedback.Rating != model.FeedbackDown || rec.Feedback.Correction != "应该发给产品组" || rec.Feedback.UserID != "ou_alice" {
+		t.Errorf("feedback = %+v", rec.Feedback)
+	}
+	if w := serve(t, r, http.MethodPost, "/api/v1/tasks/missing/feedback", "ou_alice|acme|caller", `{"rating":"up"}`); w.Code != http.StatusNotFound {
+		t.Errorf("POST feedback for missing task = %d, want 404", w.Code)
+	}
+}
+
+func TestExportFeedbackLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := Router(Options{Tasks: store.NewMemoryTaskStore(10), Workflows: store.NewMemoryWorkflowStore(), Auth: tokenVerifier{}})
+	for limit, want := range map[string]int{
+		"":     http.StatusOK,
+		"10":   http.StatusOK,
+		"5000": http.StatusOK,
+		"0":    http.StatusBadRequest,
+		"-1":   http.StatusBadRequest,
+		"5001": http.StatusBadRequest,
+		"lots": http.StatusBadRequest,
+		"1e9":  http.StatusBadRequest,
+	} {
+		path := "/api/v1/feedback/export"
+		if limit != "" {
+			path += "?limit=" + limit
+		}
+		if w := serve(t, r, http.MethodGet, path, "ou_bob|acme|operator", ""); w.Code != want {
+			t.Errorf("GET %s = %d, want %d: %s", path, w.Code, want, w.Body)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/store"
+)
+
+// UserIdentityHandler 管理租户的跨平台身份映射
+type UserIdentityHandler struct {
+	store store.IdentityStore
+}
+
+// NewUserIdentityHandler 创建身份映射处理器
+func NewUserIdentityHandler(s store.IdentityStore) *UserIdentityHandler {
+	return &UserIdentityHandler{store: s}
+}
+
+// Save 新增或覆盖同名映射，来源记为 manual
+// POST /api/v1/identities
+func (h *UserIdentityHandler) Save(c *gin.Context) {
+	var u model.UserIdentity
+	if !bindJSON(c, &u) {
+		return
+	}
+	if u.FeishuOpenID == "" && u.SlackUserID == "" && u.DiscordUserID == "" && u.Email == "" && u.Phone == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one of feishu_open_id, slack_user_id, discord_user_id, email, phone is required"})
+		return
+	}
+	if _, ok := tokenTenant(c); ok || u.TenantID == "" {
+		u.TenantID = tenantOf(c)
+	}
+	u.Source, u.UpdatedAt = model.IdentitySourceManual, time.Now()
+	if err := h.store.Save(c.Request.Context(), u); err != nil {
+		writeStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// List 列出租户的映射
+// GET /api/v1/identities
+func (h *UserIdentityHandler) List(c *gin.Context) {
+	list, err := h.store.List(c.Request.Context(), tenantOf(c))
+	if err != nil {
+		writeStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"identities": list})
+}
+
+// Lookup 按姓名、称呼、平台 ID、邮箱或手机号查找
+// GET /api/v1/identities/lookup?key=
+func (h *UserIdentityHandler) Lookup(c *gin.Context) {
+	u, err := h.store.Lookup(c.Request.Context(), tenantOf(c), c.Query("key"))
+	if err != nil {
+		writeStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// Delete 删除映射
+// DELETE /api/v1/identities/:name
+func (h *UserIdentityHandler) Delete(c *gin.Context) {
+	if err := h.store.Delete(c.Request.Context(), tenantOf(c), c.Param("name")); err != nil {
+		writeStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": c.Param("name")})
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/model"
+	servicellm "sayso-agent/internal/service/llm"
+	"sayso-agent/internal/service/schedule"
+	"sayso-agent/internal/store"
+)
+
+// WorkflowHandler 管理用户保存的工作流（宏）
+type WorkflowHandler struct {
+	store store.WorkflowStore
+}
+
+// NewWorkflowHandler 创建工作流处理器
+func NewWorkflowHandler(s store.WorkflowStore) *WorkflowHandler {
+	return &WorkflowHandler{store: s}
+}
+
+// tenantOf 读取请求所属租户：令牌中带租户时以令牌为准，否则为 query 参数 tenant_id，为空时使用默认租户
+func tenantOf(c *gin.Context) string {
+	if t, ok := tokenTenant(c); ok {
+		return t
+	}
+	if t := c.Query("tenant_id"); t != "" {
+		return t
+	}
+	return model.DefaultTenant
+}
+
+// Save 新增或覆盖工作流
+// POST /api/v1/workflows
+func (h *WorkflowHandler) Save(c *gin.Context) {
+	var wf model.Workflow
+	if !bindJSON(c, &wf) {
+		return
+	}
+	if _, ok := tokenTenant(c); ok || wf.TenantID == "" {
+		wf.TenantID = tenantOf(c)
+	}
+	if err := servicellm.ValidateWorkflow(wf); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if wf.Schedule != nil {
+		if _, err := schedule.ParseCron(wf.Schedule.Cron); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	saved, err := h.store.Save(c.Request.Context(), wf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// List 列出租户下的工作流
+// GET /api/v1/workflows?tenant_id=xxx
+func (h *WorkflowHandler) List(c *gin.Context) {
+	list, err := h.store.List(c.Request.Context(), tenantOf(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"workflows": list})
+}
+
+// Get 获取单个工作流
+// GET /api/v1/workflows/:name?tenant_id=xxx
+func (h *WorkflowHandler) Get(c *gin.Context) {
+	wf, err := h.store.Get(c.Request.Context(), tenantOf(c), c.Param("name"))
+	if err != nil {
+		writeStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, wf)
+}
+
+// Delete 删除工作流
+// DELETE /api/v1/workflows/:name?tenant_id=xxx
+func (h *WorkflowHandler) Delete(c *gin.Context) {
+	if err := h.store.Delete(c.Request.Context(), tenantOf(c), c.Param("name")); err != nil {
+		writeStoreError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": c.Param("name")})
+}
+
+// writeStoreError 将存储层错误映射为 HTTP 响应
+func writeStoreError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,98 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.ReleaseMode)
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func newTestServer(tb testing.TB, llmLatency time.Duration) string {
+	llm := httptest.NewServer(&LLMSimulator{Latency: llmLatency})
+	handler, err := NewServer(llm.URL)
+	if err != nil {
+		tb.Fatalf("NewServer: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	tb.Cleanup(func() {
+		srv.Close()
+		llm.Close()
+	})
+	return srv.URL
+}
+
+func TestRun(t *testing.T) {
+	report := Run(context.Background(), Options{BaseURL: newTestServer(t, 0), Concurrency: 4, Requests: 20})
+	if report.Requests != 20 || report.Errors != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.P50 <= 0 || report.P99 < report.P50 || report.Max < report.P99 {
+		t.Fatalf("latencies p50=%v p99=%v max=%v", report.P50, report.P99, report.Max)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var sorted []time.Duration
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i))
+	}
+	if got := percentile(sorted, 0.5); got != 50 {
+		t.Errorf("p50 = %v, want 50", got)
+	}
+	if got := percentile(sorted, 0.99); got != 99 {
+		t.Errorf("p99 = %v, want 99", got)
+	}
+	if got := percentile(nil, 0.99); got != 0 {
+		t.Errorf("empty p99 = %v, want 0", got)
+	}
+}
+
+// BenchmarkProcess 并发调用完整链路（模拟大模型无延迟，沙箱执行器），衡量服务自身的开销
+func BenchmarkProcess(b *testing.B) {
+	url := newTestServer(b, 0) + "/api/v1/asr/process"
+	body := []byte(`{"text":"创建周报，完了后把链接发给张三","user_id":"ou_bench"}`)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				b.Errorf("status %d", resp.StatusCode)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkRun 以固定并发跑一轮压测并报告分位延迟
+func BenchmarkRun(b *testing.B) {
+	baseURL := newTestServer(b, 5*time.Millisecond)
+	for i := 0; i < b.N; i++ {
+		report := Run(context.Background(), Options{BaseURL: baseURL, Concurrency: 32, Requests: 500})
+		if report.Errors > 0 {
+			b.Fatalf("%d errors, first: %s", report.Errors, report.FirstError)
+		}
+		b.ReportMetric(float64(report.P50.Microseconds()), "p50-µs")
+		b.ReportMetric(float64(report.P99.Microseconds()), "p99-µs")
+		b.ReportMetric(float64(report.PeakGoroutines), "peak-goroutines")
+	}
+}
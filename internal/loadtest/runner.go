@@ -0,0 +1,184 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options 压测参数
+type Options struct {
+	BaseURL     string // 服务地址，如 http://127.0.0.1:8080
+	Concurrency int    // 并发请求数
+	Requests    int    // 总请求数
+	Text        string // 请求文本
+}
+
+// Report 压测结果；Goroutines/Heap 为压测进程内的采样，服务在同一进程内时可反映服务端开销
+type Report struct {
+	Requests       int
+	Errors         int
+	Duration       time.Duration
+	P50, P99, Max  time.Duration
+	PeakGoroutines int
+	PeakHeapInuse  uint64
+	TotalAlloc     uint64 // 压测期间累计分配的字节数
+	FirstError     string
+}
+
+// String 可读的压测报告
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "requests: %d, errors: %d, duration: %v, throughput: %.1f req/s\n", r.Requests, r.Errors, r.Duration.Round(time.Millisecond), r.Throughput())
+	fmt.Fprintf(&b, "latency: p50=%v p99=%v max=%v\n", r.P50.Round(time.Microsecond), r.P99.Round(time.Microsecond), r.Max.Round(time.Microsecond))
+	fmt.Fprintf(&b, "goroutines: peak=%d, heap in use: peak=%.1f MiB, allocated: %.1f MiB", r.PeakGoroutines, mib(r.PeakHeapInuse), mib(r.TotalAlloc))
+	if r.FirstError != "" {
+		fmt.Fprintf(&b, "\nfirst error: %s", r.FirstError)
+	}
+	return b.String()
+}
+
+// Throughput 每秒完成的请求数
+func (r Report) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Duration.Seconds()
+}
+
+func mib(n uint64) float64 { return float64(n) / (1 << 20) }
+
+// Run 以 Concurrency 个并发持续调用 /api/v1/asr/process，直到发出 Requests 个请求或 ctx 结束
+func Run(ctx context.Context, opts Options) Report {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.Text == "" {
+		opts.Text = "创建周报，完了后把链接发给张三"
+	}
+	client := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: opts.Concurrency}}
+	url := strings.TrimRight(opts.BaseURL, "/") + "/api/v1/asr/process"
+
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	stop := make(chan struct{})
+	peaks := make(chan Report, 1)
+	go sampleRuntime(stop, peaks)
+
+	var (
+		next      atomic.Int64
+		mu        sync.Mutex
+		latencies = make([]time.Duration, 0, opts.Requests)
+		report    Report
+		wg        sync.WaitGroup
+	)
+	start := time.Now()
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				n := int(next.Add(1))
+				if n > opts.Requests {
+					return
+				}
+				body, _ := json.Marshal(map[string]any{"text": opts.Text, "user_id": fmt.Sprintf("ou_loadtest_%d_%d", worker, n)})
+				began := time.Now()
+				err := post(ctx, client, url, body)
+				elapsed := time.Since(began)
+				mu.Lock()
+				latencies = append(latencies, elapsed)
+				if err != nil {
+					report.Errors++
+					if report.FirstError == "" {
+						report.FirstError = err.Error()
+					}
+				}
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	report.Duration = time.Since(start)
+	close(stop)
+	peak := <-peaks
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	report.Requests = len(latencies)
+	report.PeakGoroutines, report.PeakHeapInuse = peak.PeakGoroutines, peak.PeakHeapInuse
+	report.TotalAlloc = after.TotalAlloc - before.TotalAlloc
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.P50, report.P99 = percentile(latencies, 0.50), percentile(latencies, 0.99)
+	if len(latencies) > 0 {
+		report.Max = latencies[len(latencies)-1]
+	}
+	return report
+}
+
+// post 发送一次请求；非 200 响应视为失败
+func post(ctx context.Context, client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, data)
+	}
+	return nil
+}
+
+// sampleRuntime 每 50ms 采样一次 goroutine 数与堆内存，stop 关闭后返回峰值
+func sampleRuntime(stop <-chan struct{}, out chan<- Report) {
+	var peak Report
+	var m runtime.MemStats
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if n := runtime.NumGoroutine(); n > peak.PeakGoroutines {
+			peak.PeakGoroutines = n
+		}
+		runtime.ReadMemStats(&m)
+		if m.HeapInuse > peak.PeakHeapInuse {
+			peak.PeakHeapInuse = m.HeapInuse
+		}
+		select {
+		case <-stop:
+			out <- peak
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// percentile 已排序延迟的 p 分位（最近秩法）
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(float64(len(sorted))*p)) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
@@ -0,0 +1,26 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+
+	"sayso-agent/config"
+	"sayso-agent/internal/app"
+	"sayso-agent/internal/client/transport"
+)
+
+// NewServer 按生产方式组装完整服务（路由、中间件、编排、执行器），大模型指向 llmURL，
+// 执行器以沙箱模式运行，不访问飞书、Slack
+func NewServer(llmURL string) (http.Handler, error) {
+	cfg := &config.Config{
+		LLM:     config.LLMConfig{BaseURL: llmURL, Model: "simulator"},
+		Feishu:  config.FeishuConfig{Enabled: true, Domain: "loadtest.feishu.cn"},
+		Slack:   config.SlackConfig{Enabled: true},
+		Sandbox: true,
+	}
+	a, err := app.New(context.Background(), cfg, app.Options{Transport: transport.New(transport.Config{})})
+	if err != nil {
+		return nil, err
+	}
+	return a.Handler, nil
+}
@@ -0,0 +1,59 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	clientllm "sayso-agent/internal/client/llm"
+)
+
+// 模拟大模型的固定回复：规划为「创建文档 → 把链接发给联系人」，覆盖依赖调度与占位符替换
+const (
+	simPlan = `{"summary":"创建周报并发送链接","tasks":[` +
+		`{"id":"task_1","skill":"create_doc","platform":"feishu","input":"创建周报文档","depends_on":[]},` +
+		`{"id":"task_2","skill":"send_message","platform":"feishu","input":"把文档链接发给张三（需要{{doc_url}}）","depends_on":["task_1"]}]}`
+	simCreateDoc   = `{"type":"feishu_create_doc","params":{"title":"周报","content":"本周完成了压测工具","folder_name":""}}`
+	simSendMessage = `{"type":"send_message","params":{"platform":"feishu","message_type":"link_card","content":{"text":"周报","url":"{{doc_url}}"},"target_type":"user","targets":["ou_loadtest"]}}`
+)
+
+// LLMSimulator OpenAI 兼容的 /chat/completions 模拟服务：按系统 Prompt 识别规划或技能调用，
+// 等待 Latency 后返回固定结果，用于在不访问真实大模型的情况下压测整条链路
+type LLMSimulator struct {
+	Latency time.Duration
+}
+
+func (s *LLMSimulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req clientllm.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Messages) == 0 {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if s.Latency > 0 {
+		select {
+		case <-time.After(s.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	content := simReply(req.Messages[0].Content)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"choices": []map[string]any{{"message": clientllm.Message{Role: "assistant", Content: content}}},
+	})
+}
+
+// simReply 按系统 Prompt 中的特征选择回复
+func simReply(system string) string {
+	switch {
+	case strings.Contains(system, "识别所有要执行的任务"):
+		return simPlan
+	case strings.Contains(system, `"type":"feishu_create_doc"`):
+		return simCreateDoc
+	case strings.Contains(system, `"type":"send_message"`):
+		return simSendMessage
+	default:
+		return "{}"
+	}
+}
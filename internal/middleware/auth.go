@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/auth"
+)
+
+// TokenVerifier 校验 Bearer 令牌并返回调用方身份（auth.OIDCVerifier 等实现）
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (auth.Identity, error)
+}
+
+const identityKey = "identity"
+
+// Auth 要求请求携带有效的 Authorization: Bearer <JWT>，校验通过后把身份存入上下文
+func Auth(v TokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.Header("WWW-Authenticate", `Bearer`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer token"})
+			return
+		}
+		id, err := v.Verify(c.Request.Context(), strings.TrimSpace(token))
+		if err != nil {
+			_ = c.Error(err)
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			msg := "invalid token"
+			if errors.Is(err, auth.ErrTokenExpired) {
+				msg = "token expired"
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": msg})
+			return
+		}
+		c.Set(identityKey, id)
+		c.Next()
+	}
+}
+
+// IdentityOf 读取 Auth 校验得到的身份；未启用认证时 ok 为 false
+func IdentityOf(c *gin.Context) (auth.Identity, bool) {
+	v, ok := c.Get(identityKey)
+	if !ok {
+		return auth.Identity{}, false
+	}
+	id, ok := v.(auth.Identity)
+	return id, ok
+}
+
+// RequireRole 要求已认证的调用方具备 role 权限，否则返回 403；未启用认证时不限制
+func RequireRole(role auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if id, ok := IdentityOf(c); ok && !id.Role.Allows(role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "requires role " + string(role)})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CallerCan 调用方能否以 role 权限操作 owner 的资源：本人总是可以，他人需具备 role；未启用认证时不限制
+func CallerCan(c *gin.Context, owner string, role auth.Role) bool {
+	id, ok := IdentityOf(c)
+	if !ok {
+		return true
+	}
+	return (owner != "" && owner == id.UserID) || id.Role.Allows(role)
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit 限制请求体大小（gzip 请求按解压后的大小计），并解码 gzip 请求体；
+// Content-Length 已超限时直接返回 413，其余在读取超限时由 handler 返回 413；不支持的 Content-Encoding 返回 415
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		encoding := strings.ToLower(strings.TrimSpace(c.Request.Header.Get("Content-Encoding")))
+		switch encoding {
+		case "", "identity":
+			if maxBytes > 0 && c.Request.ContentLength > maxBytes {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+				return
+			}
+		case "gzip":
+			zr, err := gzip.NewReader(c.Request.Body)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid gzip body: " + err.Error()})
+				return
+			}
+			defer zr.Close()
+			c.Request.Body = zr
+			c.Request.Header.Del("Content-Encoding")
+			c.Request.ContentLength = -1
+		default:
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "unsupported content encoding: " + encoding})
+			return
+		}
+		if maxBytes > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+		c.Next()
+	}
+}
+
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(io.Discard) }}
+
+// Gzip 客户端接受 gzip 时压缩响应；事件流（text/event-stream）不压缩，以便逐条推送。
+// 需注册在 Recovery 之外，panic 恢复后写出的错误响应同样会被压缩并正确结束
+func Gzip() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.Request.Header.Get("Accept-Encoding"), "gzip") || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		w := &gzipResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Header("Vary", "Accept-Encoding")
+		c.Next()
+		w.close()
+	}
+}
+
+// gzipResponseWriter 首次写入时根据响应类型决定是否压缩
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	zw      *gzip.Writer
+	decided bool
+}
+
+// decide 在响应头发出前调用；gin 的 WriteHeader 只记录状态码，响应头在首次写入或 WriteHeaderNow 时才发出
+func (w *gzipResponseWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	h := w.Header()
+	if status := w.Status(); status == http.StatusNoContent || status == http.StatusNotModified {
+		return
+	}
+	if h.Get("Content-Encoding") != "" || strings.HasPrefix(h.Get("Content-Type"), "text/event-stream") {
+		return
+	}
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	w.zw = gzipWriters.Get().(*gzip.Writer)
+	w.zw.Reset(w.ResponseWriter)
+}
+
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	w.decide()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	w.decide()
+	if w.zw == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.zw.Write(b)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipResponseWriter) Flush() {
+	w.decide()
+	if w.zw != nil {
+		_ = w.zw.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *gzipResponseWriter) close() {
+	if w.zw == nil {
+		return
+	}
+	_ = w.zw.Close()
+	gzipWriters.Put(w.zw)
+	w.zw = nil
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	AllowedOrigins   []string // 允许的来源，如 https://dash.example.com；"*" 表示任意来源，"https://*.example.com" 匹配子域名
+	AllowedHeaders   []string // 允许的请求头，为空时使用 Content-Type、Authorization 等常用头
+	ExposedHeaders   []string // 浏览器可读取的响应头
+	AllowCredentials bool     // 允许携带 Cookie/Authorization；开启时回显具体来源而不是 "*"，不能与 "*" 同时使用
+	MaxAge           int      // 预检结果缓存秒数
+}
+
+var defaultCORSHeaders = []string{"Content-Type", "Authorization", "Content-Encoding"}
+
+const corsMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+
+// CORS 为允许的来源添加跨域响应头，并直接响应预检请求（204）；不允许的来源不加头，由浏览器拦截
+func CORS(cfg CORSConfig) gin.HandlerFunc {
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCORSHeaders
+	}
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	anyOrigin := false
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			anyOrigin = true
+		}
+	}
+	// "*" 与凭据同时配置时不回显来源、不允许凭据，否则任意网站都能带着用户凭据调用接口（配置校验也会拒绝）
+	credentials := cfg.AllowCredentials && !anyOrigin
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+		if !anyOrigin && !originAllowed(origin, cfg.AllowedOrigins) {
+			c.Next()
+			return
+		}
+		if anyOrigin {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if credentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if exposeHeaders != "" {
+			h.Set("Access-Control-Expose-Headers", exposeHeaders)
+		}
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", corsMethods)
+			h.Set("Access-Control-Allow-Headers", allowHeaders)
+			if cfg.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// originAllowed 精确匹配，或 "scheme://*.domain" 匹配其子域名
+func originAllowed(origin string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(a, origin) {
+			return true
+		}
+		if scheme, domain, ok := strings.Cut(a, "://*."); ok {
+			prefix := scheme + "://"
+			if strings.HasPrefix(origin, prefix) && strings.HasSuffix(strings.ToLower(origin), "."+strings.ToLower(domain)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORSNeverReflectsArbitraryOriginWithCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name      string
+		cfg       CORSConfig
+		origin    string
+		wantAllow string
+		wantCreds string
+	}{
+		{name: "wildcard with credentials", cfg: CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}, origin: "https://evil.example", wantAllow: "*"},
+		{name: "wildcard", cfg: CORSConfig{AllowedOrigins: []string{"*"}}, origin: "https://evil.example", wantAllow: "*"},
+		{name: "listed origin with credentials", cfg: CORSConfig{AllowedOrigins: []string{"https://dash.example.com"}, AllowCredentials: true}, origin: "https://dash.example.com", wantAllow: "https://dash.example.com", wantCreds: "true"},
+		{name: "unlisted origin", cfg: CORSConfig{AllowedOrigins: []string{"https://dash.example.com"}, AllowCredentials: true}, origin: "https://evil.example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(CORS(tt.cfg))
+			r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllow {
+				t.Fatalf("Allow-Origin = %q, want %q", got, tt.wantAllow)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCreds {
+				t.Fatalf("Allow-Credentials = %q, want %q", got, tt.wantCreds)
+			}
+		})
+	}
+}
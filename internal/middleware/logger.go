@@ -1,23 +1,93 @@
 package middleware
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
+	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/trace"
 )
 
-// Logger 请求日志中间件
-func Logger() gin.HandlerFunc {
+// LoggerConfig 访问日志配置
+type LoggerConfig struct {
+	Format     string        // json | text
+	Slow       time.Duration // 超过该耗时的请求总是记录并附带阶段耗时，0 表示不按耗时判断
+	SampleRate float64       // 成功且不慢的请求的采样比例（0~1），0 表示只记录慢请求与失败请求
+}
+
+// accessEntry JSON 格式的一条访问日志
+type accessEntry struct {
+	Time      string       `json:"time"`
+	RequestID string       `json:"request_id"`
+	TaskID    string       `json:"task_id,omitempty"`
+	Method    string       `json:"method"`
+	Path      string       `json:"path"`
+	Status    int          `json:"status"`
+	LatencyMs int64        `json:"latency_ms"`
+	ClientIP  string       `json:"client_ip"`
+	Slow      bool         `json:"slow,omitempty"`
+	Errors    string       `json:"errors,omitempty"`
+	Phases    []trace.Span `json:"phases,omitempty"`
+}
+
+// Logger 访问日志中间件：成功且不慢的请求按比例采样，慢请求与失败请求总是记录并附带
+// 规划、参数提取与各动作的耗时明细
+func Logger(cfg LoggerConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
-		clientIP := c.ClientIP()
-		method := c.Request.Method
+		ctx, tr := trace.With(c.Request.Context(), c.GetHeader("X-Request-ID"))
+		c.Request = c.Request.WithContext(ctx)
+		c.Header("X-Request-ID", tr.RequestID())
 		c.Next()
+
 		latency := time.Since(start)
 		status := c.Writer.Status()
-		log.Printf("[%s] %d | %13v | %15s | %s %s",
-			method, status, latency, clientIP, path, c.Errors.String())
+		slow := cfg.Slow > 0 && latency >= cfg.Slow
+		failed := status >= 400 || len(c.Errors) > 0
+		if !slow && !failed && (cfg.SampleRate <= 0 || rand.Float64() >= cfg.SampleRate) {
+			return
+		}
+		entry := accessEntry{
+			Time:      start.Format(time.RFC3339),
+			RequestID: tr.RequestID(),
+			TaskID:    tr.TaskID(),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Status:    status,
+			LatencyMs: latency.Milliseconds(),
+			ClientIP:  c.ClientIP(),
+			Slow:      slow,
+			Errors:    strings.TrimSpace(c.Errors.String()),
+		}
+		if slow || failed {
+			entry.Phases = tr.Spans()
+		}
+		if cfg.Format == "json" {
+			b, _ := json.Marshal(entry)
+			log.Print(string(b))
+			return
+		}
+		log.Printf("[%s] %d | %13v | %15s | %s %s | %s%s",
+			entry.Method, status, latency, entry.ClientIP, entry.Path, entry.Errors, entry.RequestID, phasesText(entry.Phases))
+	}
+}
+
+// phasesText 文本格式的阶段耗时，如 " | llm.planning=1.2s action.t1.feishu_create_doc=800ms(error)"
+func phasesText(spans []trace.Span) string {
+	if len(spans) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(spans))
+	for _, s := range spans {
+		part := fmt.Sprintf("%s=%v", s.Name, s.Duration.Round(time.Millisecond))
+		if s.Error != "" {
+			part += "(error)"
+		}
+		parts = append(parts, part)
 	}
+	return " | " + strings.Join(parts, " ")
 }
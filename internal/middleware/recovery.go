@@ -1,18 +1,19 @@
 package middleware
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"sayso-agent/internal/panics"
 )
 
-// Recovery 恢复 panic 的中间件
-func Recovery() gin.HandlerFunc {
+// Recovery 恢复 panic 的中间件：记录调用栈（标注请求 ID 与任务 ID）并经 reporter 上报，返回 500；
+// reporter 为 nil 时只写日志
+func Recovery(reporter *panics.Reporter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("panic recovered: %v", err)
+				reporter.Capture(c.Request.Context(), "http "+c.Request.Method+" "+c.FullPath(), err)
 				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 					"error": "internal server error",
 				})
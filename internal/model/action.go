@@ -2,9 +2,31 @@ package model
 
 // Action type constants
 const (
-	ActionTypeSendMessage  = "send_message"
-	ActionTypeCreateDoc    = "feishu_create_doc"
-	ActionTypeCreateFolder = "feishu_create_folder"
+	ActionTypeSendMessage   = "send_message"
+	ActionTypeCreateDoc     = "feishu_create_doc"
+	ActionTypeCreateFolder  = "feishu_create_folder"
+	ActionTypeFolderTree    = "feishu_create_folder_tree"
+	ActionTypeMinutesNotes  = "feishu_minutes_notes"
+	ActionTypeReviewPerms   = "review_doc_permissions"
+	ActionTypeTransferOwner = "feishu_transfer_owner"
+	ActionTypeCommentDoc    = "feishu_comment_doc"
+	ActionTypeExportDoc     = "export_doc"
+	ActionTypeImportFile    = "import_file"
+	ActionTypeQueryStatus   = "query_status"
+	ActionTypeQueryTable    = "feishu_query_table"
+	ActionTypeQueryApproval = "feishu_query_approval"
+	ActionTypeQueryTasks    = "feishu_query_tasks"
+	ActionTypeScheduleMeet  = "feishu_schedule_meeting"
+	ActionTypeSetStatus     = "set_status"
+	ActionTypeAddReaction   = "add_reaction"
+	ActionTypeRenameFile    = "feishu_rename_file"
+	ActionTypeBulkCreateDoc = "feishu_bulk_create_doc"
+	ActionTypeSendSMS       = "send_sms"
+	ActionTypeCreateTicket  = "feishu_create_ticket"
+	ActionTypeAskKB         = "ask_kb"
+	// 以下两种由纠正流程生成（撤回发错的消息、修改文档标题），不提供给大模型
+	ActionTypeRecallMessage = "recall_message"
+	ActionTypeRenameDoc     = "feishu_rename_doc"
 )
 
 // LLMActionOutput 大模型返回的结构化动作（由本服务解析后调用外部 API）
@@ -16,10 +38,18 @@ type LLMActionOutput struct {
 	Actions []ActionSpec `json:"actions"`
 	// Reply 给用户的自然语言回复（可选）
 	Reply string `json:"reply,omitempty"`
+	// Workflow 命中的已保存工作流名称（可选）
+	Workflow string `json:"workflow,omitempty"`
+	// Degraded 耗时超出延迟目标时降级处理（备用模型、缓存的计划）
+	Degraded bool `json:"degraded,omitempty"`
 }
 
 // ActionSpec 单条动作规格：调哪个 API、参数、发给谁
 type ActionSpec struct {
+	// TaskID 来源任务 ID（如 task_1），用于 {{task_1.doc_url}} 形式的占位符
+	TaskID string `json:"task_id,omitempty"`
+	// Group 循环任务展开后所属的原任务 ID（如 task_2_1 属于 task_2）
+	Group string `json:"group,omitempty"`
 	// Type 动作类型: feishu_create_doc, feishu_send_im, slack_send_message, etc.
 	Type string `json:"type"`
 	// Params 调用该 API 所需的参数（由 executor 按 type 解析）
@@ -28,4 +58,35 @@ type ActionSpec struct {
 	TargetUserID string `json:"target_user_id,omitempty"`
 	// TargetChatID 目标群/会话 ID（可选）
 	TargetChatID string `json:"target_chat_id,omitempty"`
+	// Confirmed 用户已确认执行；只由确认流程设置，不从大模型输出解析
+	Confirmed bool `json:"-"`
+}
+
+// ActionTypePluginPrefix 外部插件技能的动作类型前缀，如 plugin.create_jira_issue
+const ActionTypePluginPrefix = "plugin."
+
+// ActionTypeScriptPrefix 租户脚本的动作类型前缀，如 script.notify_oncall
+const ActionTypeScriptPrefix = "script."
+
+// ParamContentByPlatform send_message 正文按各平台分别转义后的版本（平台 -> content），由占位符替换写入，发送时按实际平台取用
+const ParamContentByPlatform = "content_by_platform"
+
+// PluginSkill 外部插件或租户脚本注册的技能
+type PluginSkill struct {
+	Name        string         // 技能名，规划时作为 skill 使用
+	Description string         // 写入规划 Prompt 的技能说明
+	Params      map[string]any // 参数的 JSON Schema，用于生成参数提取 Prompt
+	Plugin      string         // 所属插件，租户脚本为空
+}
+
+// StringList 参数中的字符串列表（JSON 数组解析后为 []any），忽略非字符串项
+func StringList(v any) []string {
+	list, _ := v.([]any)
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
 }
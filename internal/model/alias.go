@@ -0,0 +1,33 @@
+package model
+
+// Alias 联系人分组/别名：一个名字对应多个平台上的接收人；成员 ID 为另一分组名时展开为该分组的成员
+type Alias struct {
+	Name    string        `json:"name"`
+	Members []AliasMember `json:"members"`
+
+	// Tenants 可使用该分组的租户，为空时所有租户可用
+	Tenants []string `json:"tenants,omitempty"`
+}
+
+// VisibleTo 租户能否使用该分组
+func (a Alias) VisibleTo(tenant string) bool {
+	if len(a.Tenants) == 0 {
+		return true
+	}
+	for _, t := range a.Tenants {
+		if t == tenant {
+			return true
+		}
+	}
+	return false
+}
+
+// AliasMember 别名成员
+type AliasMember struct {
+	Platform string `json:"platform"` // feishu | slack
+	ID       string `json:"id"`       // 飞书 open_id/chat_id 或 Slack user/channel ID
+	// Name、Title、EmploymentType 可选的成员信息，按条件排除成员时使用；未填写时从身份映射中查找
+	Name           string `json:"name,omitempty"`
+	Title          string `json:"title,omitempty"`
+	EmploymentType string `json:"employment_type,omitempty"`
+}
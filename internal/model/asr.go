@@ -11,11 +11,89 @@ type ASRRequest struct {
 	//   feishu_open_id: 飞书接收人 open_id（优先于 UserID 用于 feishu_send_im）
 	//   feishu_user_id: 飞书 user_id（若用 user_id 维度发私聊）
 	//   slack_channel: Slack 频道 ID（用于 slack_send_message 未指定 channel 时的默认值）
+	//   slack_team_id: 发 Slack 时默认使用的工作区（team_id 或配置的名称），目标带 "@工作区" 时以目标为准
+	//   slack_thread_ts: 请求来自 slack_channel 中的 thread 时为根消息 ts，发往该频道的消息回复到 thread 中
+	//   feishu_chat_id / feishu_root_id: 请求来自的飞书会话与话题根消息，发往该会话的消息回复到话题中
+	//   sandbox: "true" 时以沙箱模式执行，动作只返回模拟结果
+	//   locale: 回复语言，如 "zh-CN"（默认）、"en-US"
 	//   其他: 会话 ID、租户等
 	Context map[string]string `json:"context,omitempty"`
 	// Contacts 已知联系人列表，用于 LLM 将用户提到的名字映射为飞书 ID
 	// 示例: [{"name": "张三", "open_id": "ou_xxx"}, {"name": "李四", "open_id": "ou_yyy"}]
 	Contacts []Contact `json:"contacts,omitempty"`
+	// TenantID 租户标识，用于隔离工作流等租户级数据；为空时归入 DefaultTenant
+	TenantID string `json:"tenant_id,omitempty"`
+	// Attachments 随请求上传的文件（如"把这个文件存到项目目录"）
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// DeadlineMS 整体处理时限（毫秒），覆盖配置的 limits.deadline_ms；超时时响应的 timed_out_phase 标明超时阶段
+	DeadlineMS int `json:"deadline_ms,omitempty"`
+	// LLM 本次请求的大模型参数覆盖（模型、温度、token 上限），须在配置的 llm.overrides 范围内
+	LLM *LLMOptions `json:"llm,omitempty"`
+	// History 同一会话最近的交互（从早到晚），由服务端根据任务记录填充
+	History []Exchange `json:"-"`
+}
+
+// SessionID 会话标识：Context["session_id"]；来自话题（飞书话题、Slack thread）时为话题，同一话题中的多个用户共享会话；缺省为 UserID
+func (r ASRRequest) SessionID() string {
+	if id := r.Context["session_id"]; id != "" {
+		return id
+	}
+	if id := r.ThreadID(); id != "" {
+		return id
+	}
+	return r.UserID
+}
+
+// ThreadID 请求所在的话题："feishu:<根消息 ID>" 或 "slack:<频道>:<thread_ts>"，不在话题中时为空
+func (r ASRRequest) ThreadID() string {
+	if id := r.Context["feishu_root_id"]; id != "" {
+		return "feishu:" + id
+	}
+	if ts := r.Context["slack_thread_ts"]; ts != "" && r.Context["slack_channel"] != "" {
+		return "slack:" + r.Context["slack_channel"] + ":" + ts
+	}
+	return ""
+}
+
+// Locale 回复语言：Context["locale"]，缺省为 zh-CN
+func (r ASRRequest) Locale() string {
+	if l := r.Context["locale"]; l != "" {
+		return l
+	}
+	return "zh-CN"
+}
+
+// LLMOptions 请求级大模型参数，如低温度的确定性提取，或为起草长文档指定更强的模型
+type LLMOptions struct {
+	Model       string   `json:"model,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+}
+
+// Exchange 一轮交互：用户输入、回复及创建/发送的资源
+type Exchange struct {
+	UserText  string   `json:"user_text"`
+	Reply     string   `json:"reply,omitempty"`
+	Resources []string `json:"resources,omitempty"` // 如「周报」https://xxx.feishu.cn/docx/xxx
+}
+
+// Attachment 随请求上传的文件；Data 为文件内容（JSON 中为 base64），或用 URL 指向可下载地址
+type Attachment struct {
+	Name     string `json:"name"`
+	MimeType string `json:"mime_type,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+}
+
+// DefaultTenant 未指定租户时使用的租户标识
+const DefaultTenant = "default"
+
+// Tenant 返回请求所属租户
+func (r ASRRequest) Tenant() string {
+	if r.TenantID == "" {
+		return DefaultTenant
+	}
+	return r.TenantID
 }
 
 // Contact 联系人信息
@@ -24,6 +102,7 @@ type Contact struct {
 	OpenID string `json:"open_id,omitempty"` // 飞书 open_id
 	UserID string `json:"user_id,omitempty"` // 飞书 user_id
 	Email  string `json:"email,omitempty"`   // 邮箱
+	Phone  string `json:"phone,omitempty"`   // 手机号，短信/WhatsApp 只发往此处给出的号码
 }
 
 // ASRResponse 处理结果响应
@@ -36,6 +115,14 @@ type ASRResponse struct {
 	Message string `json:"message,omitempty"`
 	// Actions 已执行的动作摘要（如：已创建飞书文档、已发送私聊）
 	Actions []ActionSummary `json:"actions,omitempty"`
+	// NeedConfirmation 有动作等待用户确认，Message 为确认提示；确认后按 TaskID 继续执行
+	NeedConfirmation bool `json:"need_confirmation,omitempty"`
+	// Sandbox 以沙箱模式执行，动作结果（链接、消息 ID 等）均为模拟
+	Sandbox bool `json:"sandbox,omitempty"`
+	// TimedOutPhase 超出请求时限的阶段：planning | extraction | execution
+	TimedOutPhase string `json:"timed_out_phase,omitempty"`
+	// Degraded 为守住延迟目标使用了降级手段（备用模型、缓存的计划），结果质量可能略低
+	Degraded bool `json:"degraded,omitempty"`
 }
 
 // ActionSummary 已执行动作的简要信息
@@ -45,4 +132,12 @@ type ActionSummary struct {
 	ID     string `json:"id,omitempty"`   // 资源 ID
 	URL    string `json:"url,omitempty"`  // 资源访问链接
 	Note   string `json:"note,omitempty"` // 备注信息，如存放目录
+	// Outputs 动作输出变量（如 doc_url、message_id、chat_id），自动暴露为 {{task_N.key}} 占位符供后续任务引用
+	Outputs map[string]string `json:"outputs,omitempty"`
+	// Unverified 已执行但未能确认完全生效的原因（如协作者未添加成功、发出的消息查不到）；已确认或未核验时为空
+	Unverified string `json:"unverified,omitempty"`
+	// Group 循环任务展开后所属的原任务 ID（如 task_2），循环汇总也以此归组
+	Group string `json:"group,omitempty"`
+	// Error 循环任务中单项失败的原因；单项失败时记下并继续其余项
+	Error string `json:"error,omitempty"`
 }
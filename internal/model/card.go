@@ -0,0 +1,54 @@
+package model
+
+// Card 与平台无关的卡片消息，由各平台客户端渲染为飞书交互式卡片或 Slack Block Kit
+type Card struct {
+	Title    string
+	Icon     string // 标题前的图标，如 📄
+	Color    string // 标题栏颜色（仅飞书）：blue | green | orange | red 等，为空时使用默认
+	Sections []CardSection
+	Buttons  []CardButton
+}
+
+// CardSection 卡片中的一段：正文、并排字段或图片，可同时出现，按此顺序渲染
+type CardSection struct {
+	Text     string // 正文，飞书按纯文本、Slack 按 mrkdwn 渲染
+	Markdown bool   // 正文为 Markdown（飞书按 lark_md 渲染）
+	Fields   []CardField
+	Image    *CardImage
+}
+
+// CardField 并排显示的字段，如「所有者：张三」
+type CardField struct {
+	Name  string
+	Value string
+}
+
+// CardImage 卡片图片：飞书使用上传后的 image_key，Slack 使用可公开访问的 URL；缺少对应平台的地址时不渲染
+type CardImage struct {
+	Key string
+	URL string
+	Alt string
+}
+
+// CardButton 链接按钮；Value 非空时为回调按钮（点击后把 Value 回传到卡片回调地址，目前只在飞书渲染）
+type CardButton struct {
+	Text    string
+	URL     string
+	Primary bool
+	Value   map[string]string
+}
+
+// LinkCard 链接卡片：标题、正文、说明与「查看链接」按钮
+func LinkCard(title, text, linkURL, description string) Card {
+	card := Card{Title: title}
+	if text != "" {
+		card.Sections = append(card.Sections, CardSection{Text: text})
+	}
+	if description != "" {
+		card.Sections = append(card.Sections, CardSection{Text: description})
+	}
+	if linkURL != "" {
+		card.Buttons = append(card.Buttons, CardButton{Text: "查看链接", URL: linkURL, Primary: true})
+	}
+	return card
+}
@@ -6,6 +6,18 @@ var (
 	ErrLLMUnavailable   = errors.New("llm service unavailable")
 	ErrFeishuDisabled   = errors.New("feishu integration disabled")
 	ErrSlackDisabled    = errors.New("slack integration disabled")
+	ErrDiscordDisabled  = errors.New("discord integration disabled")
+	ErrSMSDisabled      = errors.New("sms integration disabled")
 	ErrActionNotSupport = errors.New("action type not supported")
 	ErrInvalidParams    = errors.New("invalid action params")
+	// ErrConfirmationRequired 动作需用户确认后才能执行（如转移文档所有者），执行器返回时附带待确认说明
+	ErrConfirmationRequired = errors.New("action requires confirmation")
+	// ErrLimitExceeded 计划超出配置的安全上限（动作数、接收方数、新建文档数）且配置为直接拒绝
+	ErrLimitExceeded = errors.New("plan exceeds safety limits")
+	// ErrRecipientNotAllowed 严格模式下消息目标不在请求的联系人中，也无法在通讯录中按姓名精确找到
+	ErrRecipientNotAllowed = errors.New("recipient not allowed")
+	// ErrRateLimited 用户每分钟执行的动作数超出配额
+	ErrRateLimited = errors.New("action quota exceeded")
+	// ErrTaskPanic 任务处理中发生 panic，已恢复并记为该任务失败
+	ErrTaskPanic = errors.New("task panicked")
 )
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// 反馈评价
+const (
+	FeedbackUp   = "up"
+	FeedbackDown = "down"
+)
+
+// TaskFeedback 用户对一次任务结果的反馈；Correction 为用户说明的正确做法，如"应该发给产品组，不是张三"
+type TaskFeedback struct {
+	Rating     string    `json:"rating"` // up | down
+	Correction string    `json:"correction,omitempty"`
+	UserID     string    `json:"user_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// FeedbackCase 导出的评测用例：用户输入、当时的计划与执行结果，以及用户反馈
+type FeedbackCase struct {
+	TaskID     string          `json:"task_id"`
+	TenantID   string          `json:"tenant_id"`
+	Text       string          `json:"text"`
+	History    []Exchange      `json:"history,omitempty"`
+	Workflow   string          `json:"workflow,omitempty"`
+	Intent     string          `json:"intent,omitempty"`
+	Plan       []ActionSpec    `json:"plan,omitempty"`
+	Actions    []ActionSummary `json:"actions,omitempty"`
+	Status     string          `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	Rating     string          `json:"rating"`
+	Correction string          `json:"correction,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// NewFeedbackCase 由带反馈的任务记录生成评测用例
+func NewFeedbackCase(rec TaskRecord) FeedbackCase {
+	c := FeedbackCase{
+		TaskID:    rec.ID,
+		TenantID:  rec.TenantID,
+		Text:      rec.Text,
+		Workflow:  rec.Workflow,
+		Intent:    rec.Intent,
+		Plan:      rec.Plan,
+		Actions:   rec.Actions,
+		Status:    rec.Status,
+		Error:     rec.Error,
+		CreatedAt: rec.CreatedAt,
+	}
+	if rec.Request != nil {
+		c.History = rec.Request.History
+	}
+	if rec.Feedback != nil {
+		c.Rating = rec.Feedback.Rating
+		c.Correction = rec.Feedback.Correction
+	}
+	return c
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// FeishuTenant 安装了商店应用的飞书企业
+type FeishuTenant struct {
+	TenantKey   string    `json:"tenant_key"`
+	TenantID    string    `json:"tenant_id"` // 对应的本服务租户
+	Installer   string    `json:"installer,omitempty"`
+	Active      bool      `json:"active"` // 企业停用应用后为 false
+	InstalledAt time.Time `json:"installed_at"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// FolderRule 文档归档规则：标题包含任一关键词时存入指定目录，先于大模型目录匹配生效
+type FolderRule struct {
+	TenantID    string   `json:"tenant_id"`
+	Name        string   `json:"name" binding:"required"` // 规则名称，租户内唯一
+	Keywords    []string `json:"keywords" binding:"required,min=1"`
+	FolderName  string   `json:"folder_name,omitempty"`  // 目标目录名称，按名称在云空间中查找
+	FolderToken string   `json:"folder_token,omitempty"` // 目标目录 token，优先于 FolderName
+}
@@ -0,0 +1,13 @@
+package model
+
+// KBAnswer 知识库问答结果，Sources 为回答引用的文档
+type KBAnswer struct {
+	Text    string
+	Sources []KBSource
+}
+
+// KBSource 回答引用的文档
+type KBSource struct {
+	Title string
+	URL   string
+}
@@ -7,6 +7,10 @@ type SendMessageParams struct {
 	Content     MessageContent `json:"content"`
 	TargetType  string         `json:"target_type"` // user | chat | batch
 	Targets     []string       `json:"targets"`
+	// Unfurl 是否展开链接预览（仅 Slack），nil 表示按消息类型决定
+	Unfurl *bool `json:"unfurl,omitempty"`
+	// Exclude 展开联系人分组时排除的成员：人员类型（实习生、外包）、职位中的词、姓名或 ID，如"发给产品部除了实习生"
+	Exclude []string `json:"exclude,omitempty"`
 }
 
 // MessageContent 统一消息内容结构
@@ -23,6 +27,7 @@ type SendResult struct {
 	Success  bool   `json:"success"`
 	Error    string `json:"error,omitempty"`
 	MsgID    string `json:"msg_id,omitempty"`
+	ChatID   string `json:"chat_id,omitempty"` // 消息实际落地的会话 ID
 }
 
 // ParseSendMessageParams 从 ActionSpec.Params 解析发送消息参数
@@ -39,6 +44,10 @@ func ParseSendMessageParams(params map[string]any) SendMessageParams {
 		result.TargetType = targetType
 	}
 
+	if unfurl, ok := params["unfurl"].(bool); ok {
+		result.Unfurl = &unfurl
+	}
+
 	// 解析 targets 数组
 	if targets, ok := params["targets"].([]any); ok {
 		for _, t := range targets {
@@ -48,6 +57,14 @@ func ParseSendMessageParams(params map[string]any) SendMessageParams {
 		}
 	}
 
+	if exclude, ok := params["exclude"].([]any); ok {
+		for _, x := range exclude {
+			if s, ok := x.(string); ok && s != "" {
+				result.Exclude = append(result.Exclude, s)
+			}
+		}
+	}
+
 	// 解析 content 对象
 	if content, ok := params["content"].(map[string]any); ok {
 		if text, ok := content["text"].(string); ok {
@@ -0,0 +1,16 @@
+package model
+
+// MeetingNotes 由会议文字记录整理出的结构化纪要
+type MeetingNotes struct {
+	Title     string       `json:"title"`
+	Summary   string       `json:"summary"`
+	Topics    []string     `json:"topics"`
+	Decisions []string     `json:"decisions"`
+	Todos     []ActionItem `json:"todos"`
+}
+
+// ActionItem 会议待办
+type ActionItem struct {
+	Owner   string `json:"owner,omitempty"`
+	Content string `json:"content"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// SlackInstallation 通过 OAuth 安装到的 Slack 工作区
+type SlackInstallation struct {
+	TeamID       string    `json:"team_id"`
+	TeamName     string    `json:"team_name"`
+	EnterpriseID string    `json:"enterprise_id,omitempty"`
+	BotUserID    string    `json:"bot_user_id"`
+	BotToken     string    `json:"-"` // 不随接口返回
+	Scope        string    `json:"scope"`
+	TenantID     string    `json:"tenant_id"` // 发起安装的租户，该租户默认使用此工作区
+	InstalledBy  string    `json:"installed_by,omitempty"`
+	InstalledAt  time.Time `json:"installed_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+// TableAnswer 表格数据问答结果
+type TableAnswer struct {
+	Text  string `json:"answer"`
+	Chart *Chart `json:"chart,omitempty"` // 结果为一组可比较的数值时给出，用于绘图
+}
+
+// Chart 图表数据，Labels 与 Values 一一对应
+type Chart struct {
+	Kind   string    `json:"kind"` // bar | line
+	Title  string    `json:"title"`
+	Labels []string  `json:"labels"`
+	Values []float64 `json:"values"`
+	Unit   string    `json:"unit,omitempty"`
+}
@@ -0,0 +1,69 @@
+package model
+
+import "time"
+
+// 任务来源
+const (
+	TaskSourceASR        = "asr"        // 语音/文本请求
+	TaskSourceSchedule   = "schedule"   // 定时触发的工作流
+	TaskSourceEmail      = "email"      // 入站邮件
+	TaskSourceCorrection = "correction" // 更正上一个任务（"不对，我是说……"）
+	TaskSourceChat       = "chat"       // 飞书机器人收到的消息
+	TaskSourceReplay     = "replay"     // 按历史任务的计划重新执行
+)
+
+// 任务状态
+const (
+	TaskStatusRunning   = "running"
+	TaskStatusSucceeded = "succeeded"
+	TaskStatusFailed    = "failed"
+	// TaskStatusAwaitingConfirmation 执行到需确认的动作时暂停，确认后继续执行剩余动作
+	TaskStatusAwaitingConfirmation = "awaiting_confirmation"
+)
+
+// TaskRecord 一次请求（或一次定时运行）的执行记录
+type TaskRecord struct {
+	ID        string          `json:"id"`
+	TenantID  string          `json:"tenant_id"`
+	UserID    string          `json:"user_id,omitempty"`
+	SessionID string          `json:"session_id,omitempty"`
+	Source    string          `json:"source"`
+	Text      string          `json:"text,omitempty"`
+	Workflow  string          `json:"workflow,omitempty"` // 命中或定时运行的工作流名称
+	Status    string          `json:"status"`
+	Message   string          `json:"message,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Actions   []ActionSummary `json:"actions,omitempty"`
+	// Intent、Plan 大模型给出的意图与完整动作列表（执行前），用于反馈导出与排查
+	Intent string       `json:"intent,omitempty"`
+	Plan   []ActionSpec `json:"plan,omitempty"`
+	// Feedback 用户对结果的反馈
+	Feedback *TaskFeedback `json:"feedback,omitempty"`
+	// CorrectionOf 本任务更正的原任务 ID
+	CorrectionOf string `json:"correction_of,omitempty"`
+	// ReplayOf 本任务重放的原任务 ID
+	ReplayOf string `json:"replay_of,omitempty"`
+	// LLMUsage 处理本任务（含确认、重试后继续执行）累计的大模型用量
+	LLMUsage *LLMUsage `json:"llm_usage,omitempty"`
+	// APICalls 处理本任务发出的外部 API 调用数（飞书、Slack、大模型等）
+	APICalls int `json:"api_calls,omitempty"`
+	// 以下字段用于暂停后继续执行：原始请求、尚未执行的动作（首个为待确认动作）及已登记的占位符
+	Request      *ASRRequest       `json:"request,omitempty"`
+	Pending      []ActionSpec      `json:"pending,omitempty"`
+	Placeholders map[string]string `json:"placeholders,omitempty"`
+	Reply        string            `json:"reply,omitempty"`
+	LimitCheck   string            `json:"limit_check,omitempty"` // 计划超出安全上限时的确认状态：awaiting | confirmed
+	CreatedAt    time.Time         `json:"created_at"`
+	FinishedAt   time.Time         `json:"finished_at,omitempty"`
+
+	// Sealed 加密存储时记录中的用户内容（原文、回复、计划、动作结果、占位符、原始请求等）整体加密后存于此字段，
+	// 读取时解密还原；未加密的记录为空
+	Sealed string `json:"sealed,omitempty"`
+}
+
+// LLMUsage 大模型调用次数与 token 数
+type LLMUsage struct {
+	Calls            int `json:"calls"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 身份来源
+const (
+	IdentitySourceManual = "manual" // 配置文件或接口手工维护
+	IdentitySourceFeishu = "feishu" // 飞书通讯录同步
+	IdentitySourceSlack  = "slack"  // Slack 成员列表同步
+)
+
+// 人员类型
+const (
+	EmploymentRegular    = "正式"
+	EmploymentIntern     = "实习生"
+	EmploymentOutsourced = "外包"
+	EmploymentLabor      = "劳务"
+	EmploymentConsultant = "顾问"
+)
+
+// employmentSynonyms 人员类型的常见说法
+var employmentSynonyms = map[string]string{
+	"正式员工": EmploymentRegular, "全职": EmploymentRegular, "regular": EmploymentRegular, "full-time": EmploymentRegular,
+	"实习": EmploymentIntern, "intern": EmploymentIntern, "interns": EmploymentIntern,
+	"外包人员": EmploymentOutsourced, "外包同学": EmploymentOutsourced, "contractor": EmploymentOutsourced, "contractors": EmploymentOutsourced,
+	"劳务派遣": EmploymentLabor, "派遣": EmploymentLabor,
+	"consultant": EmploymentConsultant, "consultants": EmploymentConsultant,
+}
+
+// NormalizeEmploymentType 人员类型的常见说法换成统一名称，不认识的原样返回
+func NormalizeEmploymentType(s string) string {
+	s = strings.TrimSpace(s)
+	if v, ok := employmentSynonyms[strings.ToLower(s)]; ok {
+		return v
+	}
+	return s
+}
+
+// UserIdentity 同一个人在各平台上的账号，用于"发给张三"按可用的平台投递、平台未启用时安全改投
+type UserIdentity struct {
+	TenantID string `json:"tenant_id"`
+	// Name 姓名，租户内唯一
+	Name string `json:"name" binding:"required"`
+	// Aliases 其他称呼，如英文名、昵称
+	Aliases       []string  `json:"aliases,omitempty"`
+	FeishuOpenID  string    `json:"feishu_open_id,omitempty"`
+	SlackUserID   string    `json:"slack_user_id,omitempty"` // 可带 "@工作区" 后缀
+	DiscordUserID string    `json:"discord_user_id,omitempty"`
+	Email         string    `json:"email,omitempty"`
+	Phone         string    `json:"phone,omitempty"`
+	Source        string    `json:"source,omitempty"` // manual | feishu | slack
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// Title、EmploymentType 职位与人员类型（正式、实习生、外包等），飞书通讯录同步时更新
+	Title          string `json:"title,omitempty"`
+	EmploymentType string `json:"employment_type,omitempty"`
+}
+
+// PlatformID 在平台上的接收方 ID，没有时为空
+func (u UserIdentity) PlatformID(platform string) string {
+	switch platform {
+	case "feishu":
+		return u.FeishuOpenID
+	case "slack":
+		return u.SlackUserID
+	case "discord":
+		return u.DiscordUserID
+	}
+	return ""
+}
+
+// Matches 姓名、称呼、任一平台 ID、邮箱或手机号与 key 一致（不区分大小写）
+func (u UserIdentity) Matches(key string) bool {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return false
+	}
+	for _, v := range append([]string{u.Name, u.FeishuOpenID, u.SlackUserID, u.DiscordUserID, u.Email, u.Phone}, u.Aliases...) {
+		if v != "" && strings.EqualFold(v, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Workflow 用户保存的可复用工作流（宏）：命中触发词时直接使用预定义任务，跳过任务规划
+type Workflow struct {
+	TenantID  string            `json:"tenant_id"`
+	Name      string            `json:"name" binding:"required"`
+	Triggers  []string          `json:"triggers" binding:"required"` // 触发词，用户输入包含任一即命中
+	Variables []string          `json:"variables,omitempty"`         // 每次调用时从输入中提取的变量，任务 input 中以 {{变量名}} 引用
+	Tasks     []WorkflowTask    `json:"tasks" binding:"required"`
+	Schedule  *WorkflowSchedule `json:"schedule,omitempty"` // 可选：定时运行，无需语音触发
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// WorkflowTask 工作流中的单个任务，字段含义同规划阶段的任务
+type WorkflowTask struct {
+	ID        string   `json:"id"`
+	Skill     string   `json:"skill"`
+	Platform  string   `json:"platform,omitempty"`
+	Input     string   `json:"input"`
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// WorkflowSchedule 工作流定时运行配置
+type WorkflowSchedule struct {
+	Cron   string            `json:"cron"`              // 5 段 cron 表达式（分 时 日 月 周），按服务器本地时区
+	UserID string            `json:"user_id,omitempty"` // 以该用户身份运行（作为默认接收人等）
+	Vars   map[string]string `json:"vars,omitempty"`    // 定时运行时使用的变量值
+}
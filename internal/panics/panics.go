@@ -0,0 +1,109 @@
+// Package panics 捕获 panic 的遥测：记录完整调用栈并标注请求 ID 与任务 ID，累计次数，
+// 可选地发到运维告警频道或 Sentry
+package panics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+
+	"sayso-agent/internal/trace"
+)
+
+// stackAlertLimit 发到运维频道的调用栈最大字节数，完整调用栈见日志与 Sentry
+const stackAlertLimit = 3000
+
+// Notifier 运维告警频道（alert.Notifier）
+type Notifier interface {
+	Notify(ctx context.Context, title, detail string)
+}
+
+// Report 一次 panic
+type Report struct {
+	Where     string // 发生位置，如 "http POST /api/v1/asr/process"、"inbound email"
+	Value     string // panic 的值
+	Stack     string
+	RequestID string
+	TaskID    string
+}
+
+// Reporter 记录并上报 panic；nil 时只写日志
+type Reporter struct {
+	notifier Notifier
+	sentry   *sentryClient
+	count    atomic.Int64
+}
+
+// NewReporter 创建 panic 上报器；notifier 为 nil 时不发运维告警，sentryDSN 为空时不上报 Sentry
+func NewReporter(notifier Notifier, sentryDSN string, rt http.RoundTripper) (*Reporter, error) {
+	r := &Reporter{notifier: notifier}
+	if sentryDSN != "" {
+		s, err := newSentryClient(sentryDSN, rt)
+		if err != nil {
+			return nil, err
+		}
+		r.sentry = s
+	}
+	return r, nil
+}
+
+// Recover 恢复当前 goroutine 的 panic 并上报，须直接 defer 调用：defer reporter.Recover(ctx, "inbound email")
+func (r *Reporter) Recover(ctx context.Context, where string) {
+	if v := recover(); v != nil {
+		r.Capture(ctx, where, v)
+	}
+}
+
+// Capture 上报已恢复的 panic 值 v；调用栈取自当前 goroutine，须在 defer 的恢复函数中调用
+func (r *Reporter) Capture(ctx context.Context, where string, v any) Report {
+	tr := trace.From(ctx)
+	report := Report{
+		Where:     where,
+		Value:     fmt.Sprint(v),
+		Stack:     string(debug.Stack()),
+		RequestID: tr.RequestID(),
+		TaskID:    tr.TaskID(),
+	}
+	log.Printf("panic recovered in %s (request=%s task=%s): %s\n%s", where, report.RequestID, report.TaskID, report.Value, report.Stack)
+	if r == nil {
+		return report
+	}
+	r.count.Add(1)
+	// 上报不能依赖可能已取消的请求 ctx
+	go r.send(context.WithoutCancel(ctx), report)
+	return report
+}
+
+// Count 进程启动以来恢复的 panic 次数
+func (r *Reporter) Count() int64 {
+	if r == nil {
+		return 0
+	}
+	return r.count.Load()
+}
+
+func (r *Reporter) send(ctx context.Context, report Report) {
+	if r.notifier != nil {
+		stack := report.Stack
+		if len(stack) > stackAlertLimit {
+			stack = stack[:stackAlertLimit] + "\n…"
+		}
+		r.notifier.Notify(ctx, "panic: "+report.Where,
+			fmt.Sprintf("请求 %s 任务 %s\n%s\n%s", orDash(report.RequestID), orDash(report.TaskID), report.Value, stack))
+	}
+	if r.sentry != nil {
+		if err := r.sentry.capture(ctx, report); err != nil {
+			log.Printf("panic: send to sentry failed: %v", err)
+		}
+	}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,84 @@
+package panics
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// sentryClient 通过 Sentry store 接口上报事件（不引入 SDK）
+type sentryClient struct {
+	storeURL string
+	auth     string
+	http     *http.Client
+}
+
+// newSentryClient 解析 DSN：https://<public_key>@<host>/<project_id>
+func newSentryClient(dsn string, rt http.RoundTripper) (*sentryClient, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid sentry dsn")
+	}
+	path := strings.Trim(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	project := path[i+1:]
+	if project == "" {
+		return nil, fmt.Errorf("invalid sentry dsn: missing project id")
+	}
+	prefix := ""
+	if i >= 0 {
+		prefix = "/" + path[:i]
+	}
+	return &sentryClient{
+		storeURL: fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, project),
+		auth:     "Sentry sentry_version=7, sentry_client=sayso-agent/1.0, sentry_key=" + u.User.Username(),
+		http:     &http.Client{Transport: rt, Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (s *sentryClient) capture(ctx context.Context, report Report) error {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	event := map[string]any{
+		"event_id":  hex.EncodeToString(id),
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"level":     "fatal",
+		"platform":  "go",
+		"logger":    "panics",
+		"message":   fmt.Sprintf("panic in %s: %s", report.Where, report.Value),
+		"tags": map[string]string{
+			"where":      report.Where,
+			"request_id": report.RequestID,
+			"task_id":    report.TaskID,
+		},
+		"extra": map[string]string{"stack": report.Stack},
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sentry store: status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+// Package plugin 外部插件：以 HTTP sidecar 形式部署的自定义技能，无需修改本服务代码。
+//
+// 插件需提供三个接口：
+//
+//	GET  /manifest  返回技能清单 {"skills":[{"name":"create_jira_issue","description":"...","params":{JSON Schema}}]}
+//	GET  /healthz   2xx 表示健康
+//	POST /execute   请求 {"skill","params","request":{"user_id","tenant_id","text","context"}}，
+//	                响应 {"target","id","url","note","outputs":{}}，失败时返回非 2xx 或 {"error":"..."}
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"sayso-agent/internal/model"
+)
+
+// Config 一个插件 sidecar
+type Config struct {
+	Name  string
+	URL   string // 插件服务地址，如 http://jira-plugin:8080
+	Token string // 可选，调用插件时放在 Authorization: Bearer
+	// Timeout 单次执行超时，0 为默认 30 秒
+	Timeout time.Duration
+}
+
+// Status 插件健康状态
+type Status struct {
+	Name      string    `json:"name"`
+	Healthy   bool      `json:"healthy"`
+	Skills    []string  `json:"skills,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// defaultTimeout 插件执行的默认超时
+const defaultTimeout = 30 * time.Second
+
+// skillNameRE 插件技能名：小写字母开头，只含小写字母、数字、下划线
+var skillNameRE = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Registry 管理外部插件：拉取技能清单、定期健康检查、代理执行
+type Registry struct {
+	plugins  []*plugin
+	client   *http.Client
+	reserved map[string]bool // 内置技能名，插件不能覆盖
+}
+
+type plugin struct {
+	cfg Config
+
+	mu      sync.RWMutex
+	skills  []model.PluginSkill
+	healthy bool
+	err     string
+	checked time.Time
+}
+
+// NewRegistry 创建插件注册表；reserved 为内置技能名，与之同名的插件技能会被忽略；transport 为 nil 时使用 http.DefaultTransport
+func NewRegistry(cfgs []Config, reserved []string, transport http.RoundTripper) *Registry {
+	r := &Registry{client: &http.Client{Transport: transport}, reserved: make(map[string]bool, len(reserved))}
+	for _, name := range reserved {
+		r.reserved[name] = true
+	}
+	for _, c := range cfgs {
+		c.URL = strings.TrimRight(c.URL, "/")
+		if c.Timeout <= 0 {
+			c.Timeout = defaultTimeout
+		}
+		r.plugins = append(r.plugins, &plugin{cfg: c})
+	}
+	return r
+}
+
+// Start 立即检查一次所有插件，之后每 interval 重新检查（健康检查并刷新技能清单），直到 ctx 结束
+func (r *Registry) Start(ctx context.Context, interval time.Duration) {
+	r.refresh(ctx)
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.refresh(ctx)
+			}
+		}
+	}()
+}
+
+// refresh 检查所有插件的健康状态，健康时重新拉取技能清单
+func (r *Registry) refresh(ctx context.Context) {
+	for _, p := range r.plugins {
+		skills, err := r.check(ctx, p)
+		p.mu.Lock()
+		wasHealthy, first := p.healthy, p.checked.IsZero()
+		p.healthy = err == nil
+		p.checked = time.Now()
+		p.err = ""
+		if err != nil {
+			p.err = err.Error()
+		} else {
+			p.skills = skills
+		}
+		p.mu.Unlock()
+		if err != nil && (wasHealthy || first) {
+			log.Printf("plugin %s unhealthy: %v", p.cfg.Name, err)
+		}
+		if err == nil && !wasHealthy {
+			log.Printf("plugin %s healthy, %d skills", p.cfg.Name, len(skills))
+		}
+	}
+}
+
+// check 健康检查并拉取技能清单
+func (r *Registry) check(ctx context.Context, p *plugin) ([]model.PluginSkill, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if _, err := r.call(ctx, p, http.MethodGet, "/healthz", nil); err != nil {
+		return nil, fmt.Errorf("health check: %w", err)
+	}
+	b, err := r.call(ctx, p, http.MethodGet, "/manifest", nil)
+	if err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	var manifest struct {
+		Skills []struct {
+			Name        string         `json:"name"`
+			Description string         `json:"description"`
+			Params      map[string]any `json:"params"`
+		} `json:"skills"`
+	}
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	var skills []model.PluginSkill
+	for _, s := range manifest.Skills {
+		switch {
+		case !skillNameRE.MatchString(s.Name):
+			log.Printf("plugin %s: invalid skill name %q, ignored", p.cfg.Name, s.Name)
+		case r.reserved[s.Name]:
+			log.Printf("plugin %s: skill %s conflicts with a built-in skill, ignored", p.cfg.Name, s.Name)
+		case r.owner(s.Name, p) != nil:
+			log.Printf("plugin %s: skill %s already registered by another plugin, ignored", p.cfg.Name, s.Name)
+		default:
+			skills = append(skills, model.PluginSkill{Name: s.Name, Description: s.Description, Params: s.Params, Plugin: p.cfg.Name})
+		}
+	}
+	return skills, nil
+}
+
+// owner 注册了技能 name 的其他插件（排除 self）
+func (r *Registry) owner(name string, self *plugin) *plugin {
+	for _, p := range r.plugins {
+		if p == self {
+			continue
+		}
+		p.mu.RLock()
+		for _, s := range p.skills {
+			if s.Name == name {
+				p.mu.RUnlock()
+				return p
+			}
+		}
+		p.mu.RUnlock()
+	}
+	return nil
+}
+
+// Skills 健康插件提供的技能
+func (r *Registry) Skills() []model.PluginSkill {
+	var skills []model.PluginSkill
+	for _, p := range r.plugins {
+		p.mu.RLock()
+		if p.healthy {
+			skills = append(skills, p.skills...)
+		}
+		p.mu.RUnlock()
+	}
+	return skills
+}
+
+// Status 各插件的健康状态
+func (r *Registry) Status() []Status {
+	list := make([]Status, 0, len(r.plugins))
+	for _, p := range r.plugins {
+		p.mu.RLock()
+		st := Status{Name: p.cfg.Name, Healthy: p.healthy, Error: p.err, CheckedAt: p.checked}
+		for _, s := range p.skills {
+			st.Skills = append(st.Skills, s.Name)
+		}
+		p.mu.RUnlock()
+		list = append(list, st)
+	}
+	return list
+}
+
+// Execute 将插件技能动作（type 为 plugin.<技能名>）转发给对应插件执行
+func (r *Registry) Execute(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	name := strings.TrimPrefix(spec.Type, model.ActionTypePluginPrefix)
+	p := r.owner(name, nil)
+	if p == nil {
+		return model.ActionSummary{}, fmt.Errorf("%w: %s", model.ErrActionNotSupport, spec.Type)
+	}
+	p.mu.RLock()
+	healthy := p.healthy
+	p.mu.RUnlock()
+	if !healthy {
+		return model.ActionSummary{}, fmt.Errorf("plugin %s is unavailable", p.cfg.Name)
+	}
+
+	body := map[string]any{"skill": name, "params": spec.Params}
+	if req != nil {
+		body["request"] = map[string]any{
+			"user_id":   req.UserID,
+			"tenant_id": req.Tenant(),
+			"text":      req.Text,
+			"context":   req.Context,
+		}
+	}
+	data, _ := json.Marshal(body)
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+	b, err := r.call(ctx, p, http.MethodPost, "/execute", data)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("plugin %s: %w", p.cfg.Name, err)
+	}
+	var result struct {
+		Target  string            `json:"target"`
+		ID      string            `json:"id"`
+		URL     string            `json:"url"`
+		Note    string            `json:"note"`
+		Outputs map[string]string `json:"outputs"`
+		Error   string            `json:"error"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return model.ActionSummary{}, fmt.Errorf("plugin %s parse response: %w, body: %.500s", p.cfg.Name, err, string(b))
+	}
+	if result.Error != "" {
+		return model.ActionSummary{}, fmt.Errorf("plugin %s: %s", p.cfg.Name, result.Error)
+	}
+	summary := model.ActionSummary{Type: name, Target: result.Target, ID: result.ID, URL: result.URL, Note: result.Note, Outputs: result.Outputs}
+	if summary.URL != "" {
+		if summary.Outputs == nil {
+			summary.Outputs = map[string]string{}
+		}
+		if _, ok := summary.Outputs["url"]; !ok {
+			summary.Outputs["url"] = summary.URL
+		}
+	}
+	return summary, nil
+}
+
+// call 调用插件接口，非 2xx 时返回错误（优先使用响应中的 error 字段）
+func (r *Registry) call(ctx context.Context, p *plugin, method, path string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.cfg.URL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.cfg.Token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(b, &e) == nil && e.Error != "" {
+			return nil, fmt.Errorf("status %d: %s", resp.StatusCode, e.Error)
+		}
+		return nil, fmt.Errorf("status %d: %.200s", resp.StatusCode, string(b))
+	}
+	return b, nil
+}
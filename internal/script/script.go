@@ -0,0 +1,325 @@
+// Package script 租户脚本：按租户注册的 Starlark 小脚本，接收大模型提取的参数，
+// 只能调用受限的宿主函数（发消息、建文档），用于编排内置技能之外的自定义逻辑。
+//
+// 脚本须定义 main(params)，返回 None、字符串（作为备注）或 dict（作为输出变量，url 同时作为结果链接）：
+//
+//	def main(params):
+//	    doc = create_doc(title = "故障记录 " + params["service"], content = params["summary"])
+//	    send_message(target = "oc_xxx", text = "已记录：" + doc["url"], target_type = "chat")
+//	    return {"url": doc["url"]}
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+
+	"sayso-agent/internal/model"
+)
+
+// Config 一个租户脚本
+type Config struct {
+	TenantID    string
+	Name        string            // 技能名，规划时作为 skill 使用
+	Description string            // 写入规划 Prompt 的技能说明
+	Params      map[string]string // 参数名 -> 说明，大模型按此提取参数
+	Source      string            // Starlark 源码
+}
+
+// Limits 单次执行的资源上限，0 使用默认值
+type Limits struct {
+	MaxSteps uint64        // 解释器执行步数，默认 100000
+	Timeout  time.Duration // 执行超时（含宿主函数调用），默认 10 秒
+	MaxCalls int           // 宿主函数调用次数，默认 10
+}
+
+// Host 执行宿主函数对应的动作（由执行器提供，经过钩子与沙箱）
+type Host func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error)
+
+// skillNameRE 脚本名：小写字母开头，只含小写字母、数字、下划线
+var skillNameRE = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Engine 编译并执行租户脚本
+type Engine struct {
+	scripts map[string]map[string]*compiled // tenant -> name -> 脚本
+	limits  Limits
+}
+
+type compiled struct {
+	cfg  Config
+	prog *starlark.Program
+}
+
+// NewEngine 编译所有脚本；reserved 为内置技能名，脚本不能同名；脚本名不合法、重复或有语法错误时返回错误
+func NewEngine(cfgs []Config, reserved []string, limits Limits) (*Engine, error) {
+	if limits.MaxSteps == 0 {
+		limits.MaxSteps = 100000
+	}
+	if limits.Timeout <= 0 {
+		limits.Timeout = 10 * time.Second
+	}
+	if limits.MaxCalls <= 0 {
+		limits.MaxCalls = 10
+	}
+	e := &Engine{scripts: make(map[string]map[string]*compiled), limits: limits}
+	for _, c := range cfgs {
+		if c.TenantID == "" {
+			c.TenantID = model.DefaultTenant
+		}
+		if !skillNameRE.MatchString(c.Name) {
+			return nil, fmt.Errorf("script %q: invalid name", c.Name)
+		}
+		for _, r := range reserved {
+			if c.Name == r {
+				return nil, fmt.Errorf("script %s: conflicts with a built-in skill", c.Name)
+			}
+		}
+		_, prog, err := starlark.SourceProgram(c.Name+".star", c.Source, predeclared.Has)
+		if err != nil {
+			return nil, fmt.Errorf("script %s: %w", c.Name, err)
+		}
+		if e.scripts[c.TenantID] == nil {
+			e.scripts[c.TenantID] = make(map[string]*compiled)
+		}
+		if e.scripts[c.TenantID][c.Name] != nil {
+			return nil, fmt.Errorf("script %s: duplicate for tenant %s", c.Name, c.TenantID)
+		}
+		e.scripts[c.TenantID][c.Name] = &compiled{cfg: c, prog: prog}
+	}
+	return e, nil
+}
+
+// predeclared 脚本可见的全局名；宿主函数在每次执行时绑定到具体请求
+var predeclared = starlark.StringDict{
+	"json":         json.Module,
+	"send_message": starlark.None,
+	"create_doc":   starlark.None,
+}
+
+// Skills 租户可用的脚本技能
+func (e *Engine) Skills(tenant string) []model.PluginSkill {
+	var skills []model.PluginSkill
+	for _, s := range e.scripts[tenant] {
+		props := make(map[string]any, len(s.cfg.Params))
+		for name, desc := range s.cfg.Params {
+			props[name] = map[string]any{"type": "string", "description": desc}
+		}
+		skills = append(skills, model.PluginSkill{
+			Name:        s.cfg.Name,
+			Description: s.cfg.Description,
+			Params:      map[string]any{"type": "object", "properties": props},
+		})
+	}
+	sort.Slice(skills, func(i, j int) bool { return skills[i].Name < skills[j].Name })
+	return skills
+}
+
+// Run 执行脚本动作（type 为 script.<脚本名>），宿主函数通过 host 执行
+func (e *Engine) Run(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, host func(context.Context, model.ActionSpec, *model.ASRRequest) (model.ActionSummary, error)) (model.ActionSummary, error) {
+	name := strings.TrimPrefix(spec.Type, model.ActionTypeScriptPrefix)
+	tenant := model.DefaultTenant
+	if req != nil {
+		tenant = req.Tenant()
+	}
+	s := e.scripts[tenant][name]
+	if s == nil {
+		return model.ActionSummary{}, fmt.Errorf("%w: %s", model.ErrActionNotSupport, spec.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.limits.Timeout)
+	defer cancel()
+	thread := &starlark.Thread{
+		Name:  "script " + name,
+		Print: func(_ *starlark.Thread, msg string) { log.Printf("script %s: %s", name, msg) },
+	}
+	thread.SetMaxExecutionSteps(e.limits.MaxSteps)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(ctx.Err().Error())
+		case <-done:
+		}
+	}()
+
+	calls := &hostCalls{ctx: ctx, spec: spec, req: req, host: Host(host), max: e.limits.MaxCalls}
+	globals, err := s.prog.Init(thread, calls.bind())
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("script %s: %w", name, err)
+	}
+	main, ok := globals["main"].(starlark.Callable)
+	if !ok {
+		return model.ActionSummary{}, fmt.Errorf("script %s: main(params) is not defined", name)
+	}
+	params, err := toStarlark(spec.Params)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("script %s params: %w", name, err)
+	}
+	ret, err := starlark.Call(thread, main, starlark.Tuple{params}, nil)
+	if calls.confirm != nil {
+		// 确认后整个脚本以 Confirmed 重新执行
+		confirm := *calls.confirm
+		confirm.Note = fmt.Sprintf("脚本 %s 需要确认：%s", name, confirm.Note)
+		return confirm, model.ErrConfirmationRequired
+	}
+	if err != nil {
+		var evalErr *starlark.EvalError
+		if errors.As(err, &evalErr) {
+			return model.ActionSummary{}, fmt.Errorf("script %s: %s", name, evalErr.Backtrace())
+		}
+		return model.ActionSummary{}, fmt.Errorf("script %s: %w", name, err)
+	}
+
+	summary := model.ActionSummary{Type: name, Target: s.cfg.Description, Note: fmt.Sprintf("脚本调用 %d 次宿主函数", calls.n)}
+	switch v := ret.(type) {
+	case starlark.NoneType:
+	case starlark.String:
+		summary.Note = string(v)
+	case *starlark.Dict:
+		summary.Outputs = make(map[string]string, v.Len())
+		for _, item := range v.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				continue
+			}
+			value, ok := starlark.AsString(item[1])
+			if !ok {
+				value = item[1].String()
+			}
+			summary.Outputs[key] = value
+		}
+		summary.URL = summary.Outputs["url"]
+	default:
+		return model.ActionSummary{}, fmt.Errorf("script %s: main must return None, a string or a dict, got %s", name, ret.Type())
+	}
+	return summary, nil
+}
+
+// hostCalls 一次执行中的宿主函数，限制调用次数
+type hostCalls struct {
+	ctx  context.Context
+	spec model.ActionSpec
+	req  *model.ASRRequest
+	host Host
+	max  int
+	n    int
+
+	confirm *model.ActionSummary // 宿主动作要求确认时的摘要，脚本中止后原样交给用户确认
+}
+
+func (h *hostCalls) bind() starlark.StringDict {
+	return starlark.StringDict{
+		"json":         json.Module,
+		"send_message": starlark.NewBuiltin("send_message", h.sendMessage),
+		"create_doc":   starlark.NewBuiltin("create_doc", h.createDoc),
+	}
+}
+
+// sendMessage send_message(target, text, platform="feishu", target_type="user")，返回 {"message_id", "chat_id"}
+func (h *hostCalls) sendMessage(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var target, text string
+	platform, targetType := "feishu", "user"
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "target", &target, "text", &text, "platform?", &platform, "target_type?", &targetType); err != nil {
+		return nil, err
+	}
+	return h.call(model.ActionTypeSendMessage, map[string]any{
+		"platform":     platform,
+		"message_type": "text",
+		"content":      map[string]any{"text": text},
+		"target_type":  targetType,
+		"targets":      []any{target},
+	})
+}
+
+// createDoc create_doc(title, content="", folder="")，返回 {"id", "url"}
+func (h *hostCalls) createDoc(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var title, content, folder string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "title", &title, "content?", &content, "folder?", &folder); err != nil {
+		return nil, err
+	}
+	// 脚本每次运行都新建文档，不因同名询问用户
+	params := map[string]any{"title": title, "content": content, "on_duplicate": "new"}
+	if folder != "" {
+		params["folder_name"] = folder
+	}
+	return h.call(model.ActionTypeCreateDoc, params)
+}
+
+// call 执行宿主动作；确认状态沿用脚本动作本身，收件人上限、草稿审阅等钩子照常要求确认
+// 错误信息由解释器加上函数名（Error in send_message: ...）
+func (h *hostCalls) call(actionType string, params map[string]any) (starlark.Value, error) {
+	if h.n >= h.max {
+		return nil, fmt.Errorf("too many host calls (max %d)", h.max)
+	}
+	h.n++
+	summary, err := h.host(h.ctx, model.ActionSpec{TaskID: h.spec.TaskID, Type: actionType, Params: params, Confirmed: h.spec.Confirmed}, h.req)
+	if errors.Is(err, model.ErrConfirmationRequired) {
+		h.confirm = &summary
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := starlark.NewDict(len(summary.Outputs) + 2)
+	for k, v := range summary.Outputs {
+		_ = result.SetKey(starlark.String(k), starlark.String(v))
+	}
+	if summary.ID != "" {
+		_ = result.SetKey(starlark.String("id"), starlark.String(summary.ID))
+	}
+	if summary.URL != "" {
+		_ = result.SetKey(starlark.String("url"), starlark.String(summary.URL))
+	}
+	return result, nil
+}
+
+// toStarlark 把 JSON 解码得到的值转为 Starlark 值
+func toStarlark(v any) (starlark.Value, error) {
+	switch x := v.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(x), nil
+	case string:
+		return starlark.String(x), nil
+	case float64:
+		if x == float64(int64(x)) {
+			return starlark.MakeInt64(int64(x)), nil
+		}
+		return starlark.Float(x), nil
+	case int:
+		return starlark.MakeInt(x), nil
+	case []any:
+		list := make([]starlark.Value, 0, len(x))
+		for _, item := range x {
+			sv, err := toStarlark(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, sv)
+		}
+		return starlark.NewList(list), nil
+	case map[string]any:
+		d := starlark.NewDict(len(x))
+		for k, item := range x {
+			sv, err := toStarlark(item)
+			if err != nil {
+				return nil, err
+			}
+			if err := d.SetKey(starlark.String(k), sv); err != nil {
+				return nil, err
+			}
+		}
+		return d, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %T", v)
+	}
+}
@@ -0,0 +1,57 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"sayso-agent/internal/model"
+)
+
+const notifySource = `
+def main(params):
+    doc = create_doc(title = "故障记录")
+    send_message(target = params["to"], text = "已记录：" + doc["url"])
+`
+
+func TestHostCallsKeepConfirmation(t *testing.T) {
+	engine, err := NewEngine([]Config{{Name: "notify", Source: notifySource}}, nil, Limits{})
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	var calls []model.ActionSpec
+	host := func(_ context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+		calls = append(calls, spec)
+		if spec.Type == model.ActionTypeSendMessage && !spec.Confirmed {
+			return model.ActionSummary{Type: "feishu_message", Note: "「全员」共 200 人，确认发送吗？"}, model.ErrConfirmationRequired
+		}
+		return model.ActionSummary{ID: "doxcn1", URL: "https://example.feishu.cn/docx/doxcn1"}, nil
+	}
+	spec := model.ActionSpec{TaskID: "t1", Type: model.ActionTypeScriptPrefix + "notify", Params: map[string]any{"to": "全员"}}
+
+	summary, err := engine.Run(context.Background(), spec, nil, host)
+	if !errors.Is(err, model.ErrConfirmationRequired) {
+		t.Fatalf("Run unconfirmed err = %v, want ErrConfirmationRequired", err)
+	}
+	if !strings.Contains(summary.Note, "共 200 人") {
+		t.Fatalf("confirm note = %q, want the hook's prompt", summary.Note)
+	}
+	for _, c := range calls {
+		if c.Confirmed {
+			t.Fatalf("host call %s confirmed before the user confirmed", c.Type)
+		}
+	}
+	if calls[0].Params["on_duplicate"] != "new" {
+		t.Fatalf("create_doc on_duplicate = %v, want new", calls[0].Params["on_duplicate"])
+	}
+
+	calls = nil
+	spec.Confirmed = true
+	if _, err := engine.Run(context.Background(), spec, nil, host); err != nil {
+		t.Fatalf("Run confirmed: %v", err)
+	}
+	if len(calls) != 2 || !calls[1].Confirmed {
+		t.Fatalf("confirmed host calls = %+v, want send_message confirmed", calls)
+	}
+}
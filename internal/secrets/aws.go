@@ -0,0 +1,139 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSConfig AWS Secrets Manager 配置；凭证为空时由调用方从 AWS_ACCESS_KEY_ID 等环境变量填入
+type AWSConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // 临时凭证（STS）时需要
+	Endpoint        string // 为空时使用 https://secretsmanager.<region>.amazonaws.com，VPC 终端节点时填写
+	Transport       http.RoundTripper
+}
+
+// AWSProvider 从 AWS Secrets Manager 读取密钥；引用为 "<secret-id>[#<json-key>]"，如 aws-sm://sayso/prod#llm_api_key，
+// 带 #key 时 SecretString 按 JSON 对象解析取该字段
+type AWSProvider struct {
+	cfg    AWSConfig
+	client *http.Client
+}
+
+// NewAWSProvider 创建 AWS Secrets Manager 密钥源
+func NewAWSProvider(cfg AWSConfig) *AWSProvider {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", cfg.Region)
+	}
+	return &AWSProvider{cfg: cfg, client: &http.Client{Transport: cfg.Transport, Timeout: 10 * time.Second}}
+}
+
+func (p *AWSProvider) Get(ctx context.Context, ref string) (string, error) {
+	secretID, key := splitKey(ref)
+	body, _ := json.Marshal(map[string]string{"SecretId": secretID})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signV4(req, body, p.cfg, "secretsmanager", time.Now().UTC())
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("aws get secret %s: %w", secretID, err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("aws get secret %s: %w", secretID, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("aws get secret %s: http status %d, body: %s", secretID, resp.StatusCode, string(b))
+	}
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return "", fmt.Errorf("aws get secret %s: parse response: %w", secretID, err)
+	}
+	if key == "" {
+		return result.SecretString, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(result.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("aws get secret %s: secret is not a json object: %w", secretID, err)
+	}
+	value, ok := fields[key].(string)
+	if !ok {
+		return "", fmt.Errorf("aws get secret %s: key %s not found", secretID, key)
+	}
+	return value, nil
+}
+
+// signV4 按 AWS Signature Version 4 签名请求
+func signV4(req *http.Request, body []byte, cfg AWSConfig, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", cfg.SessionToken)
+	}
+	req.Header.Set("Host", req.URL.Host)
+
+	var names []string
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method, path, canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(), signedHeaders, hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	scope := date + "/" + cfg.Region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+cfg.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, cfg.Region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(q url.Values) string {
+	// url.Values.Encode 按键排序；SigV4 要求空格编码为 %20
+	return strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,45 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvFileProvider 从 KEY=VALUE 格式的文件读取密钥（如 Kubernetes/Docker 挂载的 secret 文件），
+// 每次读取都重新加载文件，挂载内容更新后即可轮换；引用为键名，如 env-file://FEISHU_APP_SECRET
+type EnvFileProvider struct {
+	Path string
+}
+
+// NewEnvFileProvider 创建 env 文件密钥源
+func NewEnvFileProvider(path string) *EnvFileProvider {
+	return &EnvFileProvider{Path: path}
+}
+
+func (p *EnvFileProvider) Get(_ context.Context, ref string) (string, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", fmt.Errorf("read env file: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok || strings.TrimSpace(key) != ref {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("env file %s: key %s not found", p.Path, ref)
+}
@@ -0,0 +1,56 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// AWSKMS 调用 AWS KMS 解密被主密钥加密（wrap）的数据密钥
+type AWSKMS struct {
+	cfg    AWSConfig
+	client *http.Client
+}
+
+// NewAWSKMS 创建 KMS 客户端；cfg.Endpoint 为空时使用 https://kms.<region>.amazonaws.com
+func NewAWSKMS(cfg AWSConfig) *AWSKMS {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com", cfg.Region)
+	}
+	return &AWSKMS{cfg: cfg, client: &http.Client{Transport: cfg.Transport, Timeout: 10 * time.Second}}
+}
+
+// Decrypt 解密 KMS Encrypt/GenerateDataKey 返回的 CiphertextBlob
+func (k *AWSKMS) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	body, _ := json.Marshal(map[string][]byte{"CiphertextBlob": blob})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService.Decrypt")
+	signV4(req, body, k.cfg, "kms", time.Now().UTC())
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt: %w", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kms decrypt: http status %d, body: %s", resp.StatusCode, string(b))
+	}
+	var result struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, fmt.Errorf("kms decrypt: parse response: %w", err)
+	}
+	return result.Plaintext, nil
+}
@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider 按引用读取密钥；ref 为去掉 scheme 的部分，如 "secret/data/sayso#feishu_app_secret"
+type Provider interface {
+	Get(ctx context.Context, ref string) (string, error)
+}
+
+// Manager 解析配置中的密钥引用并定期刷新；引用格式为 "<scheme>://<ref>"，如
+// vault://secret/data/sayso#feishu_app_secret、aws-sm://sayso/prod#llm_api_key、env-file://LLM_API_KEY
+type Manager struct {
+	providers map[string]Provider
+
+	mu     sync.Mutex
+	values map[string]string // 引用 → 最近一次读取的值
+}
+
+// NewManager 创建密钥管理器，providers 的键为引用 scheme
+func NewManager(providers map[string]Provider) *Manager {
+	return &Manager{providers: providers, values: make(map[string]string)}
+}
+
+// IsRef 配置值是否为密钥引用（已注册 scheme 的 "<scheme>://..."）
+func (m *Manager) IsRef(value string) bool {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return false
+	}
+	_, ok = m.providers[scheme]
+	return ok
+}
+
+// Resolve 配置值为密钥引用时返回读取到的密钥，否则原样返回
+func (m *Manager) Resolve(ctx context.Context, value string) (string, error) {
+	if !m.IsRef(value) {
+		return value, nil
+	}
+	scheme, ref, _ := strings.Cut(value, "://")
+	secret, err := m.providers[scheme].Get(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("resolve secret %s: %w", value, err)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("resolve secret %s: empty value", value)
+	}
+	m.mu.Lock()
+	m.values[value] = secret
+	m.mu.Unlock()
+	return secret, nil
+}
+
+// Watch 每隔 interval 重新读取引用，值变化（密钥轮换）时调用 onChange；value 不是引用时不做任何事。
+// 读取失败只记日志并继续使用旧值
+func (m *Manager) Watch(ctx context.Context, value string, interval time.Duration, onChange func(secret string)) {
+	if !m.IsRef(value) || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			m.mu.Lock()
+			old := m.values[value]
+			m.mu.Unlock()
+			secret, err := m.Resolve(ctx, value)
+			if err != nil {
+				log.Printf("[secrets] refresh failed: %v", err)
+				continue
+			}
+			if secret != old {
+				log.Printf("[secrets] %s rotated", value)
+				onChange(secret)
+			}
+		}
+	}()
+}
+
+// splitKey 拆分 "path#key"；没有 #key 时 key 为空
+func splitKey(ref string) (path, key string) {
+	path, key, _ = strings.Cut(ref, "#")
+	return path, key
+}
@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultConfig HashiCorp Vault 配置
+type VaultConfig struct {
+	Address   string // 如 https://vault.example.com:8200
+	Token     string
+	Namespace string // Vault 企业版命名空间，可为空
+	Transport http.RoundTripper
+}
+
+// VaultProvider 从 Vault KV 引擎读取密钥；引用为 "<path>#<key>"，如 vault://secret/data/sayso#feishu_app_secret，
+// KV v2 路径需包含 data/
+type VaultProvider struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+// NewVaultProvider 创建 Vault 密钥源
+func NewVaultProvider(cfg VaultConfig) *VaultProvider {
+	return &VaultProvider{cfg: cfg, client: &http.Client{Transport: cfg.Transport, Timeout: 10 * time.Second}}
+}
+
+func (p *VaultProvider) Get(ctx context.Context, ref string) (string, error) {
+	path, key := splitKey(ref)
+	if key == "" {
+		return "", fmt.Errorf("vault ref %q: missing #key", ref)
+	}
+	url := strings.TrimRight(p.cfg.Address, "/") + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.cfg.Token)
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault read %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("vault read %s: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault read %s: http status %d", path, resp.StatusCode)
+	}
+	var result struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("vault read %s: parse response: %w", path, err)
+	}
+	data := result.Data
+	// KV v2 的值在 data.data 中
+	if inner, ok := data["data"].(map[string]any); ok {
+		data = inner
+	}
+	value, ok := data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("vault read %s: key %s not found", path, key)
+	}
+	return value, nil
+}
@@ -0,0 +1,173 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	clientllm "sayso-agent/internal/client/llm"
+	"sayso-agent/internal/model"
+)
+
+// 未配置时的默认阈值
+const (
+	defaultErrorWindow      = 10 * time.Minute
+	defaultMinSamples       = 20
+	defaultTokenWarnPercent = 80
+)
+
+// MonitorConfig 系统性故障的告警阈值；失败率、大模型失败次数、token 预算为 0 时不检查
+type MonitorConfig struct {
+	// ErrorRate 窗口内任务失败率（0-1）达到此值时告警
+	ErrorRate float64
+	// ErrorWindow 统计失败率的滑动窗口，0 为 10 分钟
+	ErrorWindow time.Duration
+	// MinSamples 窗口内任务数达到此值才计算失败率，0 为 20
+	MinSamples int
+	// LLMFailures 大模型连续调用失败（规划未产出任何动作）达到此次数时告警，视为熔断
+	LLMFailures int
+	// DailyTokens 每日大模型 token 预算，用量达到 TokenWarnPercent% 时告警
+	DailyTokens int
+	// TokenWarnPercent 预算预警比例，0 为 80
+	TokenWarnPercent int
+	// JobFailures 定时工作流、后台维护任务连续失败达到此次数时告警，0 为每次失败都告警
+	JobFailures int
+}
+
+// Monitor 从任务结果与后台任务运行结果中发现系统性故障（失败率升高、大模型持续失败、token 预算将尽、定时任务反复失败），
+// 通过 Notifier 发到运维频道；Notifier 负责去重与限频
+type Monitor struct {
+	cfg      MonitorConfig
+	notifier *Notifier
+
+	mu          sync.Mutex
+	outcomes    []outcome      // 窗口内已结束任务的结果
+	llmFailures int            // 大模型连续失败次数
+	day         string         // tokens 所属日期
+	tokens      int            // 当日 token 用量
+	warnedDay   string         // 已发过预算告警的日期
+	jobFailures map[string]int // 任务名 -> 连续失败次数
+}
+
+type outcome struct {
+	at     time.Time
+	failed bool
+}
+
+// NewMonitor 创建故障监测
+func NewMonitor(cfg MonitorConfig, notifier *Notifier) *Monitor {
+	if cfg.ErrorWindow <= 0 {
+		cfg.ErrorWindow = defaultErrorWindow
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = defaultMinSamples
+	}
+	if cfg.TokenWarnPercent <= 0 {
+		cfg.TokenWarnPercent = defaultTokenWarnPercent
+	}
+	return &Monitor{cfg: cfg, notifier: notifier, jobFailures: make(map[string]int)}
+}
+
+// ObserveTask 记录一次任务处理结果（含确认、重试后的继续执行），token 用量取 ctx 中本次处理的用量；
+// 待确认的任务只计 token，不计入失败率
+func (m *Monitor) ObserveTask(ctx context.Context, rec model.TaskRecord) {
+	now := time.Now()
+	tokens := clientllm.UsageFrom(ctx).Totals()
+	var alerts []func()
+	m.mu.Lock()
+	finished := rec.Status == model.TaskStatusSucceeded || rec.Status == model.TaskStatusFailed
+	if m.cfg.ErrorRate > 0 && finished {
+		if rate, n, ok := m.errorRate(now, rec.Status == model.TaskStatusFailed); ok {
+			alerts = append(alerts, func() {
+				m.notifier.Notify(ctx, "任务失败率升高", fmt.Sprintf("最近 %s 内 %d 个任务失败率 %.0f%%（阈值 %.0f%%）\n最近失败: %s %s",
+					m.cfg.ErrorWindow, n, rate*100, m.cfg.ErrorRate*100, rec.ID, rec.Error))
+			})
+		}
+	}
+	if m.cfg.LLMFailures > 0 && finished {
+		if llmFailed(rec) {
+			m.llmFailures++
+		} else {
+			m.llmFailures = 0
+		}
+		if n := m.llmFailures; n >= m.cfg.LLMFailures {
+			alerts = append(alerts, func() {
+				m.notifier.Notify(ctx, "大模型连续调用失败", fmt.Sprintf("已连续 %d 个请求规划失败，新请求可能全部失败\n最近错误: %s", n, rec.Error))
+			})
+		}
+	}
+	if m.cfg.DailyTokens > 0 {
+		if used, ok := m.addTokens(now, tokens.PromptTokens+tokens.CompletionTokens); ok {
+			alerts = append(alerts, func() {
+				m.notifier.Notify(ctx, "大模型 token 预算即将用尽", fmt.Sprintf("今日已用 %d / %d token（%d%%）", used, m.cfg.DailyTokens, used*100/m.cfg.DailyTokens))
+			})
+		}
+	}
+	m.mu.Unlock()
+	for _, alert := range alerts {
+		alert()
+	}
+}
+
+// errorRate 记入一次结果并返回窗口内的失败率；任务数不足或未达阈值时 ok 为 false
+func (m *Monitor) errorRate(now time.Time, failed bool) (float64, int, bool) {
+	recent := m.outcomes[:0]
+	for _, o := range m.outcomes {
+		if now.Sub(o.at) < m.cfg.ErrorWindow {
+			recent = append(recent, o)
+		}
+	}
+	m.outcomes = append(recent, outcome{at: now, failed: failed})
+	if len(m.outcomes) < m.cfg.MinSamples {
+		return 0, 0, false
+	}
+	n := 0
+	for _, o := range m.outcomes {
+		if o.failed {
+			n++
+		}
+	}
+	rate := float64(n) / float64(len(m.outcomes))
+	return rate, len(m.outcomes), rate >= m.cfg.ErrorRate
+}
+
+// addTokens 累加当日用量；首次达到预警比例时返回 true（每天一次）
+func (m *Monitor) addTokens(now time.Time, tokens int) (int, bool) {
+	day := now.Format("2006-01-02")
+	if day != m.day {
+		m.day, m.tokens = day, 0
+	}
+	m.tokens += tokens
+	if m.warnedDay == day || m.tokens*100 < m.cfg.DailyTokens*m.cfg.TokenWarnPercent {
+		return m.tokens, false
+	}
+	m.warnedDay = day
+	return m.tokens, true
+}
+
+// llmFailed 任务在大模型阶段失败（见 ASRService.Process 的结果说明）；超时的结果说明不同，不计入，由失败率反映
+func llmFailed(rec model.TaskRecord) bool {
+	return rec.Status == model.TaskStatusFailed && len(rec.Plan) == 0 && strings.HasPrefix(rec.Message, "大模型处理失败")
+}
+
+// Job 记录一次后台任务（定时工作流、维护任务）的运行结果，连续失败达到阈值后每次失败都告警（由 Notifier 合并）
+func (m *Monitor) Job(ctx context.Context, name string, err error) {
+	m.mu.Lock()
+	if err == nil {
+		delete(m.jobFailures, name)
+		m.mu.Unlock()
+		return
+	}
+	m.jobFailures[name]++
+	n := m.jobFailures[name]
+	m.mu.Unlock()
+	threshold := m.cfg.JobFailures
+	if threshold <= 0 {
+		threshold = 1
+	}
+	if n >= threshold {
+		m.notifier.Notify(ctx, fmt.Sprintf("%s连续失败", name), fmt.Sprintf("已连续失败 %d 次\n原因: %v", n, err))
+	}
+}
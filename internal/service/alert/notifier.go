@@ -0,0 +1,120 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/client/slack"
+)
+
+// defaultDedup 未配置时相同告警的合并时间
+const defaultDedup = 30 * time.Minute
+
+// Config 运维告警频道配置
+type Config struct {
+	Platform string // feishu | slack，为空表示不发送告警
+	Target   string // 飞书 chat_id / Slack 频道 ID
+	// Dedup 相同标题的告警在此时间内只发一次，期间的重复次数附在下一次告警中；0 为默认 30 分钟
+	Dedup time.Duration
+	// MaxPerHour 每小时最多发送的告警数，超出的只写日志；0 表示不限制
+	MaxPerHour int
+}
+
+// Notifier 向运维频道发送告警；未配置时只写日志。相同告警去重合并，并限制发送频率，避免故障期间刷屏
+type Notifier struct {
+	cfg    Config
+	feishu *feishu.Client
+	slack  *slack.Client
+
+	mu         sync.Mutex
+	last       map[string]time.Time // 标题 -> 上次发送时间
+	suppressed map[string]int       // 标题 -> 上次发送后被合并的次数
+	sent       []time.Time          // 最近一小时的发送时间
+}
+
+// NewNotifier 创建告警通知器
+func NewNotifier(cfg Config, feishuClient *feishu.Client, slackClient *slack.Client) *Notifier {
+	if cfg.Dedup <= 0 {
+		cfg.Dedup = defaultDedup
+	}
+	return &Notifier{cfg: cfg, feishu: feishuClient, slack: slackClient, last: make(map[string]time.Time), suppressed: make(map[string]int)}
+}
+
+// Notify 发送告警；发送失败只记录日志，不影响调用方
+func (n *Notifier) Notify(ctx context.Context, title, detail string) {
+	log.Printf("alert: %s: %s", title, detail)
+	if n == nil || n.cfg.Platform == "" || n.cfg.Target == "" {
+		return
+	}
+	repeats, ok := n.admit(title, time.Now())
+	if !ok {
+		return
+	}
+	text := fmt.Sprintf("[sayso-agent 告警] %s\n%s", title, detail)
+	if repeats > 0 {
+		text += fmt.Sprintf("\n（上次告警后又出现 %d 次，已合并）", repeats)
+	}
+	if err := n.send(ctx, text); err != nil {
+		log.Printf("alert: send to %s failed: %v", n.cfg.Platform, err)
+	}
+}
+
+// admit 判断告警能否发送：相同标题在去重时间内、或本小时已达上限时不发送；
+// 可以发送时返回上次发送后被合并的次数
+func (n *Notifier) admit(title string, now time.Time) (int, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if last, ok := n.last[title]; ok && now.Sub(last) < n.cfg.Dedup {
+		n.suppressed[title]++
+		return 0, false
+	}
+	recent := n.sent[:0]
+	for _, t := range n.sent {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	n.sent = recent
+	if n.cfg.MaxPerHour > 0 && len(n.sent) >= n.cfg.MaxPerHour {
+		log.Printf("alert: rate limited, %q not sent", title)
+		n.suppressed[title]++
+		return 0, false
+	}
+	n.sent = append(n.sent, now)
+	n.last[title] = now
+	repeats := n.suppressed[title]
+	delete(n.suppressed, title)
+	return repeats, true
+}
+
+// Post 向运维频道发送普通通知（如月度成本报表），不加告警前缀；未配置频道时返回错误
+func (n *Notifier) Post(ctx context.Context, text string) error {
+	if n == nil || n.cfg.Platform == "" || n.cfg.Target == "" {
+		return fmt.Errorf("alert channel not configured")
+	}
+	return n.send(ctx, text)
+}
+
+func (n *Notifier) send(ctx context.Context, text string) error {
+	switch n.cfg.Platform {
+	case "feishu":
+		token, err := n.feishu.GetTenantAccessToken(ctx)
+		if err != nil {
+			return err
+		}
+		return n.feishu.SendMessage(ctx, token, feishu.SendMessageRequest{
+			ReceiveID:     n.cfg.Target,
+			ReceiveIDType: "chat_id",
+			MsgType:       "text",
+			Content:       feishu.BuildTextContent(text),
+		}).Error
+	case "slack":
+		return n.slack.SendMessage(ctx, n.cfg.Target, text)
+	default:
+		return fmt.Errorf("unsupported alert platform: %s", n.cfg.Platform)
+	}
+}
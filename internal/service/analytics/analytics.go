@@ -0,0 +1,278 @@
+package analytics
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/store"
+)
+
+// 运营统计：直接由任务记录汇总动作量、成功率、常见错误、计划规模与大模型成本，
+// 运维看板不需要另外采集日志
+
+// Pricing 大模型每千 token 的价格（美元），用于估算成本
+type Pricing struct {
+	InputPer1K  float64
+	OutputPer1K float64
+}
+
+// Cost 按价格估算用量的成本
+func (p Pricing) Cost(u model.LLMUsage) float64 {
+	return float64(u.PromptTokens)/1000*p.InputPer1K + float64(u.CompletionTokens)/1000*p.OutputPer1K
+}
+
+// Analyzer 任务记录统计
+type Analyzer struct {
+	tasks   store.TaskStore
+	pricing Pricing
+}
+
+// New 创建统计服务
+func New(tasks store.TaskStore, pricing Pricing) *Analyzer {
+	return &Analyzer{tasks: tasks, pricing: pricing}
+}
+
+// Query 统计范围；TenantID 为空时统计全部租户，Until 为零值时到当前
+type Query struct {
+	TenantID string
+	Since    time.Time
+	Until    time.Time
+}
+
+// Records 读取统计范围内的任务记录（按创建时间倒序）
+func (a *Analyzer) Records(ctx context.Context, q Query) ([]model.TaskRecord, error) {
+	list, err := a.tasks.List(ctx, store.TaskFilter{TenantID: q.TenantID, Since: q.Since})
+	if err != nil {
+		return nil, err
+	}
+	if q.Until.IsZero() {
+		return list, nil
+	}
+	out := list[:0]
+	for _, rec := range list {
+		if rec.CreatedAt.Before(q.Until) {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+// DayActions 某天执行成功的动作数，按动作摘要类型（feishu_doc、slack_message 等）统计
+type DayActions struct {
+	Date   string         `json:"date"`
+	Total  int            `json:"total"`
+	ByType map[string]int `json:"by_type"`
+}
+
+// ActionsByDay 按天统计执行的动作，日期从早到晚
+func (a *Analyzer) ActionsByDay(ctx context.Context, q Query) ([]DayActions, error) {
+	list, err := a.Records(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	days := make(map[string]*DayActions)
+	for _, rec := range list {
+		date := rec.CreatedAt.Format("2006-01-02")
+		d, ok := days[date]
+		if !ok {
+			d = &DayActions{Date: date, ByType: make(map[string]int)}
+			days[date] = d
+		}
+		for _, act := range rec.Actions {
+			d.Total++
+			d.ByType[act.Type]++
+		}
+	}
+	out := make([]DayActions, 0, len(days))
+	for _, d := range days {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	return out, nil
+}
+
+// Outcomes 任务结果统计
+type Outcomes struct {
+	Tasks     int `json:"tasks"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Awaiting  int `json:"awaiting_confirmation"`
+	// SuccessRate 成功任务占已结束（成功或失败）任务的比例
+	SuccessRate float64 `json:"success_rate"`
+	// AvgPlanSize 有计划的任务平均包含的动作数
+	AvgPlanSize float64      `json:"avg_plan_size"`
+	TopErrors   []ErrorCount `json:"top_errors,omitempty"`
+}
+
+// ErrorCount 一类错误的次数与一条示例
+type ErrorCount struct {
+	Code    string `json:"code"`
+	Count   int    `json:"count"`
+	Example string `json:"example"`
+}
+
+// topErrors 结果中列出的错误类别数
+const topErrors = 10
+
+// Outcomes 统计成功率、平均计划规模与最常见的失败原因
+func (a *Analyzer) Outcomes(ctx context.Context, q Query) (Outcomes, error) {
+	list, err := a.Records(ctx, q)
+	if err != nil {
+		return Outcomes{}, err
+	}
+	var out Outcomes
+	var planned, planActions int
+	errs := make(map[string]*ErrorCount)
+	for _, rec := range list {
+		out.Tasks++
+		switch rec.Status {
+		case model.TaskStatusSucceeded:
+			out.Succeeded++
+		case model.TaskStatusFailed:
+			out.Failed++
+			code := ErrorCode(rec.Error)
+			if e, ok := errs[code]; ok {
+				e.Count++
+			} else {
+				errs[code] = &ErrorCount{Code: code, Count: 1, Example: rec.Error}
+			}
+		case model.TaskStatusAwaitingConfirmation:
+			out.Awaiting++
+		}
+		if len(rec.Plan) > 0 {
+			planned++
+			planActions += len(rec.Plan)
+		}
+	}
+	if finished := out.Succeeded + out.Failed; finished > 0 {
+		out.SuccessRate = float64(out.Succeeded) / float64(finished)
+	}
+	if planned > 0 {
+		out.AvgPlanSize = float64(planActions) / float64(planned)
+	}
+	for _, e := range errs {
+		out.TopErrors = append(out.TopErrors, *e)
+	}
+	sort.Slice(out.TopErrors, func(i, j int) bool {
+		if out.TopErrors[i].Count != out.TopErrors[j].Count {
+			return out.TopErrors[i].Count > out.TopErrors[j].Count
+		}
+		return out.TopErrors[i].Code < out.TopErrors[j].Code
+	})
+	if len(out.TopErrors) > topErrors {
+		out.TopErrors = out.TopErrors[:topErrors]
+	}
+	return out, nil
+}
+
+// CostLine 一个租户（或租户下一个用户）的用量与估算成本
+type CostLine struct {
+	TenantID         string  `json:"tenant_id"`
+	UserID           string  `json:"user_id,omitempty"`
+	Tasks            int     `json:"tasks"`
+	Actions          int     `json:"actions"`
+	APICalls         int     `json:"api_calls"`
+	LLMCalls         int     `json:"llm_calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// Costs 按租户（byUser 为 true 时按租户下的用户）汇总动作数、外部调用数、大模型用量与估算成本，成本从高到低
+func (a *Analyzer) Costs(ctx context.Context, q Query, byUser bool) ([]CostLine, error) {
+	list, err := a.Records(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	lines := make(map[[2]string]*CostLine)
+	for _, rec := range list {
+		key := [2]string{rec.TenantID}
+		if byUser {
+			key[1] = rec.UserID
+		}
+		l, ok := lines[key]
+		if !ok {
+			l = &CostLine{TenantID: key[0], UserID: key[1]}
+			lines[key] = l
+		}
+		l.Tasks++
+		l.Actions += len(rec.Actions)
+		l.APICalls += rec.APICalls
+		if u := rec.LLMUsage; u != nil {
+			l.LLMCalls += u.Calls
+			l.PromptTokens += u.PromptTokens
+			l.CompletionTokens += u.CompletionTokens
+		}
+	}
+	out := make([]CostLine, 0, len(lines))
+	for _, l := range lines {
+		l.CostUSD = a.pricing.Cost(model.LLMUsage{PromptTokens: l.PromptTokens, CompletionTokens: l.CompletionTokens})
+		out = append(out, *l)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].CostUSD != out[j].CostUSD {
+			return out[i].CostUSD > out[j].CostUSD
+		}
+		if out[i].TenantID != out[j].TenantID {
+			return out[i].TenantID < out[j].TenantID
+		}
+		return out[i].UserID < out[j].UserID
+	})
+	return out, nil
+}
+
+// errorCodes 已知错误与统计用的错误码
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{model.ErrRateLimited, "rate_limited"},
+	{model.ErrLimitExceeded, "limit_exceeded"},
+	{model.ErrRecipientNotAllowed, "recipient_not_allowed"},
+	{model.ErrInvalidParams, "invalid_params"},
+	{model.ErrActionNotSupport, "action_not_supported"},
+	{model.ErrFeishuDisabled, "feishu_disabled"},
+	{model.ErrSlackDisabled, "slack_disabled"},
+	{model.ErrDiscordDisabled, "discord_disabled"},
+	{model.ErrSMSDisabled, "sms_disabled"},
+	{model.ErrLLMUnavailable, "llm_unavailable"},
+}
+
+var (
+	reFeishuCode = regexp.MustCompile(`code=(\d+)`)
+	reLLMStatus  = regexp.MustCompile(`llm api error: (\d{3})`)
+	reSlackError = regexp.MustCompile(`^slack [^:]+: ([a-z_]+)$`)
+)
+
+// ErrorCode 把任务记录中的错误信息归类为错误码：已知错误、飞书接口错误码（feishu_<code>）、
+// 大模型接口状态码（llm_<status>）、Slack 错误名（slack_<error>）、超时（timeout），其余为 other
+func ErrorCode(msg string) string {
+	for _, e := range errorCodes {
+		if strings.Contains(msg, e.err.Error()) {
+			return e.code
+		}
+	}
+	if m := reFeishuCode.FindStringSubmatch(msg); m != nil {
+		return "feishu_" + m[1]
+	}
+	if m := reLLMStatus.FindStringSubmatch(msg); m != nil {
+		return "llm_" + m[1]
+	}
+	// 错误信息外层有动作前缀，取最内层的 Slack 错误
+	if i := strings.LastIndex(msg, "slack "); i >= 0 {
+		if m := reSlackError.FindStringSubmatch(msg[i:]); m != nil {
+			return "slack_" + m[1]
+		}
+	}
+	if strings.Contains(msg, "phase exceeded its") || strings.Contains(msg, context.DeadlineExceeded.Error()) {
+		return "timeout"
+	}
+	if msg == "" {
+		return "unknown"
+	}
+	return "other"
+}
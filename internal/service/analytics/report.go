@@ -0,0 +1,111 @@
+package analytics
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CostReport 一个统计周期的成本报表
+type CostReport struct {
+	Period string     `json:"period"` // 如 "2026-09"
+	Since  time.Time  `json:"since"`
+	Until  time.Time  `json:"until"`
+	Lines  []CostLine `json:"lines"`
+	Total  CostLine   `json:"total"`
+}
+
+// MonthRange 某月的起止时间（本地时区），month 形如 "2026-09"
+func MonthRange(month string) (since, until time.Time, err error) {
+	t, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("month must be YYYY-MM: %w", err)
+	}
+	return t, t.AddDate(0, 1, 0), nil
+}
+
+// CostReport 生成 month 的成本报表；TenantID 为空时包含全部租户
+func (a *Analyzer) CostReport(ctx context.Context, tenantID, month string, byUser bool) (CostReport, error) {
+	since, until, err := MonthRange(month)
+	if err != nil {
+		return CostReport{}, err
+	}
+	lines, err := a.Costs(ctx, Query{TenantID: tenantID, Since: since, Until: until}, byUser)
+	if err != nil {
+		return CostReport{}, err
+	}
+	r := CostReport{Period: month, Since: since, Until: until, Lines: lines}
+	for _, l := range lines {
+		r.Total.Tasks += l.Tasks
+		r.Total.Actions += l.Actions
+		r.Total.APICalls += l.APICalls
+		r.Total.LLMCalls += l.LLMCalls
+		r.Total.PromptTokens += l.PromptTokens
+		r.Total.CompletionTokens += l.CompletionTokens
+		r.Total.CostUSD += l.CostUSD
+	}
+	return r, nil
+}
+
+// WriteCSV 以 CSV 写出报表明细，首行为表头
+func (r CostReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"period", "tenant_id", "user_id", "tasks", "actions", "api_calls", "llm_calls", "prompt_tokens", "completion_tokens", "cost_usd"})
+	for _, l := range r.Lines {
+		_ = cw.Write([]string{
+			r.Period, l.TenantID, l.UserID,
+			strconv.Itoa(l.Tasks), strconv.Itoa(l.Actions), strconv.Itoa(l.APICalls), strconv.Itoa(l.LLMCalls),
+			strconv.Itoa(l.PromptTokens), strconv.Itoa(l.CompletionTokens), strconv.FormatFloat(l.CostUSD, 'f', 4, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// reportTopLines 文字报表中列出的租户数
+const reportTopLines = 10
+
+// Text 适合发到运维群的文字摘要：合计与成本最高的租户
+func (r CostReport) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s 成本报表：任务 %d，动作 %d，外部调用 %d，大模型调用 %d（%d / %d tokens），估算 $%.2f",
+		r.Period, r.Total.Tasks, r.Total.Actions, r.Total.APICalls, r.Total.LLMCalls, r.Total.PromptTokens, r.Total.CompletionTokens, r.Total.CostUSD)
+	for i, l := range r.Lines {
+		if i == reportTopLines {
+			fmt.Fprintf(&b, "\n…另有 %d 个租户", len(r.Lines)-reportTopLines)
+			break
+		}
+		fmt.Fprintf(&b, "\n%d. %s：任务 %d，动作 %d，外部调用 %d，$%.2f", i+1, l.TenantID, l.Tasks, l.Actions, l.APICalls, l.CostUSD)
+	}
+	return b.String()
+}
+
+// RunMonthly 每月 1 日 09:00 生成上个月的成本报表，经 post 发到运维群；ctx 取消时退出
+func (a *Analyzer) RunMonthly(ctx context.Context, post func(ctx context.Context, text string) error) {
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), 1, 9, 0, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 1, 0)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+		month := next.AddDate(0, -1, 0).Format("2006-01")
+		report, err := a.CostReport(ctx, "", month, false)
+		if err != nil {
+			log.Printf("cost report %s: %v", month, err)
+			continue
+		}
+		if err := post(ctx, report.Text()); err != nil {
+			log.Printf("post cost report %s: %v", month, err)
+		}
+	}
+}
@@ -2,154 +2,463 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	clientllm "sayso-agent/internal/client/llm"
+	"sayso-agent/internal/client/transport"
 	"sayso-agent/internal/model"
+	"sayso-agent/internal/service/deadline"
 	"sayso-agent/internal/service/executor"
 	servicellm "sayso-agent/internal/service/llm"
+	"sayso-agent/internal/store"
+	"sayso-agent/internal/trace"
 )
 
 // ASRService 编排：接收 ASR 文本 -> 调大模型 -> 执行动作（飞书/Slack 等）
 type ASRService struct {
-	llm      *servicellm.Service
-	executor *executor.Executor
+	llm      Planner
+	executor ActionExecutor
+	tasks    store.TaskStore
+	session  SessionConfig
+	limits   Limits
+	quota    *actionQuota
+	// observers 任务处理结束时通知，如运维告警统计失败率
+	observers []TaskObserver
 }
 
-// NewASRService 创建 ASR 编排服务
-func NewASRService(llm *servicellm.Service, exec *executor.Executor) *ASRService {
+// TaskObserver 任务处理结束（成功、失败或暂停待确认）时的观察者（如 alert.Monitor）
+type TaskObserver interface {
+	ObserveTask(ctx context.Context, rec model.TaskRecord)
+}
+
+// Planner 大模型理解与规划（由 llm.Service 实现）
+type Planner interface {
+	Process(ctx context.Context, req model.ASRRequest) (*model.LLMActionOutput, error)
+	ProcessWorkflow(ctx context.Context, wf *model.Workflow, vars map[string]string, req model.ASRRequest) (*model.LLMActionOutput, error)
+	Correct(ctx context.Context, original string, plan []model.ActionSpec, correction string) ([]servicellm.CorrectedAction, error)
+	Redraft(ctx context.Context, spec model.ActionSpec, revision string) (model.ActionSpec, error)
+}
+
+// ActionExecutor 动作执行（由 executor.Executor 实现）
+type ActionExecutor interface {
+	Execute(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error)
+	Intercept(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, run executor.Handler) (model.ActionSummary, error)
+	Sandboxed(req *model.ASRRequest) bool
+}
+
+// SessionConfig 会话上下文：规划时附带同一会话最近 HistorySize 轮交互（Window 内），HistorySize 为 0 时不附带
+type SessionConfig struct {
+	HistorySize int
+	Window      time.Duration
+}
+
+// NewASRService 创建 ASR 编排服务；每次处理都会写入 tasks 作为运行记录，会话上下文也从 tasks 读取；
+// limits 为计划规模与每分钟动作数的安全上限
+func NewASRService(llm Planner, exec ActionExecutor, tasks store.TaskStore, session SessionConfig, limits Limits) *ASRService {
 	return &ASRService{
 		llm:      llm,
 		executor: exec,
+		tasks:    tasks,
+		session:  session,
+		limits:   limits,
+		quota:    newActionQuota(limits.ActionsPerMinute),
 	}
 }
 
+// Observe 注册任务结束时的观察者，须在开始处理请求前调用
+func (s *ASRService) Observe(o TaskObserver) {
+	s.observers = append(s.observers, o)
+}
+
 // 占位符：大模型在生成时不知道前序动作结果，用 {{doc_url}} 等占位，执行时用真实值替换
-// 支持: doc_url, doc_id, folder_url, folder_id, last_url, last_note
-var placeholderRE = regexp.MustCompile(`\{\{(\w+)\}\}`)
+// 每个动作的 Outputs 同时注册为 {{key}}（后写覆盖）与 {{task_N.key}}（精确引用某个任务），
+// 另有 last_url、last_note 指向最近一次的链接与备注
+var placeholderRE = regexp.MustCompile(`\{\{([\w.]+)\}\}`)
 
 // Process 处理内部传入的 ASR 文本，完成大模型理解与外部动作执行
 func (s *ASRService) Process(ctx context.Context, req model.ASRRequest) (model.ASRResponse, error) {
-	taskID := strconv.FormatInt(time.Now().UnixNano(), 10)
+	return s.ProcessFrom(ctx, req, model.TaskSourceASR)
+}
+
+// ProcessFrom 同 Process，source 标记请求来源（邮件等其他触发渠道），写入任务记录
+func (s *ASRService) ProcessFrom(ctx context.Context, req model.ASRRequest, source string) (model.ASRResponse, error) {
+	// 本次请求的大模型用量与外部调用数，结束时记入任务记录
+	ctx = withMeters(ctx)
+	// 「确认」「取消」「重试」等简短回复作用于该用户最近的任务，不再走大模型
+	if resp, ok, err := s.followUp(ctx, req); ok {
+		return resp, err
+	}
+	if err := s.limits.LLM.check(req.LLM); err != nil {
+		return model.ASRResponse{Message: err.Error()}, err
+	}
+	ctx = withLLMOptions(ctx, req.LLM)
+
+	req.History = s.loadHistory(ctx, req)
+	rec := s.startTask(ctx, req, source)
+	trace.From(ctx).SetTask(rec.ID)
 	resp := model.ASRResponse{
-		TaskID:  taskID,
+		TaskID:  rec.ID,
 		Success: false,
 	}
 
+	// 请求时限只约束处理过程，任务记录仍用原 ctx 保存
+	pctx, cancel := deadline.Start(ctx, s.deadlineFor(req), s.limits.DeadlineShares)
+	defer cancel()
+
 	// 1. 大模型理解文本，从自然语言中提取平台、目标、消息内容等
-	llmOut, err := s.llm.Process(ctx, req.Text)
+	llmOut, err := s.llm.Process(pctx, req)
 	if err != nil {
 		resp.Message = fmt.Sprintf("大模型处理失败: %v", err)
+		timedOut(&resp, err)
+		s.finishTask(ctx, rec, resp, err)
 		return resp, err
 	}
+	rec.Workflow = llmOut.Workflow
+	resp.Degraded = llmOut.Degraded
 
-	// 2. 逐条执行动作；用前序动作结果替换 {{doc_url}} 等占位符（大模型不知道真实 URL）
-	placeholders := make(map[string]string)
-	var summaries []model.ActionSummary
-	for _, spec := range llmOut.Actions {
-		spec := applyPlaceholders(spec, placeholders)
-		summary, err := s.executor.Execute(ctx, spec, &req)
-		if err != nil {
+	// 2. 执行动作
+	execCtx, cancelExec := deadline.Enter(pctx, deadline.Execution)
+	defer cancelExec()
+	resp, err = s.execute(execCtx, &rec, resp, llmOut, &req)
+	if err = deadline.Check(execCtx, err); err != nil {
+		timedOut(&resp, err)
+	}
+	s.finishTask(ctx, rec, resp, err)
+	return resp, err
+}
+
+// deadlineFor 请求的总时限：请求中的 deadline_ms 优先，其次为配置的默认值
+func (s *ASRService) deadlineFor(req model.ASRRequest) time.Duration {
+	if req.DeadlineMS > 0 {
+		return time.Duration(req.DeadlineMS) * time.Millisecond
+	}
+	return s.limits.Deadline
+}
+
+// timedOut 某阶段超出时限时，在响应中标明阶段
+func timedOut(resp *model.ASRResponse, err error) {
+	var exceeded *deadline.ExceededError
+	if !errors.As(err, &exceeded) {
+		return
+	}
+	resp.TimedOutPhase = exceeded.Phase.String()
+	resp.Message = fmt.Sprintf("处理超时：%s阶段超出时限（%s）", exceeded.Phase.Label(), exceeded.Budget.Round(time.Millisecond))
+}
+
+// RunWorkflow 直接运行已保存的工作流（定时触发等无语音输入的场景），变量取 vars
+func (s *ASRService) RunWorkflow(ctx context.Context, wf model.Workflow, userID string, vars map[string]string) (model.ASRResponse, error) {
+	req := model.ASRRequest{
+		Text:     fmt.Sprintf("运行工作流「%s」", wf.Name),
+		UserID:   userID,
+		TenantID: wf.TenantID,
+	}
+	ctx = withMeters(ctx)
+	rec := s.startTask(ctx, req, model.TaskSourceSchedule)
+	rec.Workflow = wf.Name
+	resp := model.ASRResponse{TaskID: rec.ID}
+
+	llmOut, err := s.llm.ProcessWorkflow(ctx, &wf, vars, req)
+	if err != nil {
+		resp.Message = fmt.Sprintf("工作流规划失败: %v", err)
+		s.finishTask(ctx, rec, resp, err)
+		return resp, err
+	}
+	resp, err = s.execute(ctx, &rec, resp, llmOut, &req)
+	s.finishTask(ctx, rec, resp, err)
+	return resp, err
+}
+
+// historyTextLimit 历史交互中用户输入保留的最大字符数（邮件等长文本只保留开头）
+const historyTextLimit = 200
+
+// loadHistory 读取同一会话最近的交互，按时间从早到晚排列
+func (s *ASRService) loadHistory(ctx context.Context, req model.ASRRequest) []model.Exchange {
+	session := req.SessionID()
+	if s.tasks == nil || s.session.HistorySize <= 0 || session == "" {
+		return nil
+	}
+	list, err := s.tasks.List(ctx, store.TaskFilter{TenantID: req.Tenant(), Session: session, Limit: s.session.HistorySize})
+	if err != nil {
+		log.Printf("load session history %s: %v", session, err)
+		return nil
+	}
+	var history []model.Exchange
+	for i := len(list) - 1; i >= 0; i-- {
+		rec := list[i]
+		if s.session.Window > 0 && time.Since(rec.CreatedAt) > s.session.Window {
+			continue
+		}
+		text := rec.Text
+		if r := []rune(text); len(r) > historyTextLimit {
+			text = string(r[:historyTextLimit]) + "…"
+		}
+		// 话题中有多位成员时标明发言人
+		if req.ThreadID() != "" && rec.UserID != req.UserID {
+			text = fmt.Sprintf("（%s）%s", speakerOf(rec), text)
+		}
+		ex := model.Exchange{UserText: text, Reply: rec.Message}
+		for _, a := range rec.Actions {
+			res := strings.TrimSpace(fmt.Sprintf("%s「%s」%s", a.Type, a.Target, a.URL))
+			// 记下发出的消息，供「给刚才那条消息点个赞」等引用
+			if id := a.Outputs["message_id"]; id != "" {
+				res += fmt.Sprintf(" message_id=%s chat_id=%s", id, a.Outputs["chat_id"])
+			}
+			ex.Resources = append(ex.Resources, res)
+		}
+		history = append(history, ex)
+	}
+	return history
+}
+
+// speakerOf 任务请求人的名称：Context["user_name"]，缺省为用户 ID
+func speakerOf(rec model.TaskRecord) string {
+	if rec.Request != nil && rec.Request.Context["user_name"] != "" {
+		return rec.Request.Context["user_name"]
+	}
+	return rec.UserID
+}
+
+// execute 将大模型输出的动作写入任务记录后逐条执行
+func (s *ASRService) execute(ctx context.Context, rec *model.TaskRecord, resp model.ASRResponse, llmOut *model.LLMActionOutput, req *model.ASRRequest) (model.ASRResponse, error) {
+	rec.Request = req
+	rec.Intent = llmOut.Intent
+	rec.Plan = llmOut.Actions
+	rec.Pending = llmOut.Actions
+	rec.Placeholders = make(map[string]string)
+	rec.Reply = llmOut.Reply
+	return s.resume(ctx, rec, resp, req)
+}
+
+// resume 逐条执行 rec.Pending；用前序动作结果替换 {{doc_url}} 等占位符（大模型不知道真实 URL）
+// 遇到需确认的动作时暂停，剩余动作与占位符留在任务记录中，待 Confirm 后继续
+func (s *ASRService) resume(ctx context.Context, rec *model.TaskRecord, resp model.ASRResponse, req *model.ASRRequest) (model.ASRResponse, error) {
+	resp.Sandbox = s.executor.Sandboxed(req)
+	// 钩子经 trace 取得任务 ID（如草稿预览卡片的回调按钮）；定时任务等没有 trace 的入口在此补上
+	if trace.From(ctx) == nil {
+		ctx, _ = trace.With(ctx, "")
+	}
+	trace.From(ctx).SetTask(rec.ID)
+	if paused, err := s.checkPlan(rec, &resp); paused || err != nil {
+		resp.Actions = rec.Actions
+		return resp, err
+	}
+	for len(rec.Pending) > 0 {
+		// 客户端断开或超出时限时不再执行剩余动作；剩余动作留在任务记录中，可重试继续
+		if err := ctx.Err(); err != nil {
+			resp.Message = fmt.Sprintf("请求已取消，剩余 %d 个动作未执行，可重试继续", len(rec.Pending))
+			resp.Actions = rec.Actions
+			return resp, fmt.Errorf("%d actions not executed: %w", len(rec.Pending), err)
+		}
+		spec := applyPlaceholders(rec.Pending[0], rec.Placeholders)
+		if spec.TaskID == "" {
+			spec.TaskID = fmt.Sprintf("task_%d", len(rec.Actions)+1)
+		}
+		if !s.quota.allow(rec.TenantID+"/"+rec.UserID, time.Now()) {
+			resp.Message = fmt.Sprintf("操作太频繁：每分钟最多执行 %d 个动作，请稍后重试", s.limits.ActionsPerMinute)
+			resp.Actions = rec.Actions
+			return resp, model.ErrRateLimited
+		}
+		summary, err := s.runAction(ctx, spec, req, rec.ID)
+		if errors.Is(err, model.ErrConfirmationRequired) {
+			resp.NeedConfirmation = true
+			resp.Message = summary.Note
+			resp.Actions = rec.Actions
+			return resp, nil
+		}
+		// 循环任务的单项失败不中断其余项，记入汇总
+		if err != nil && (spec.Group == "" || errors.Is(err, model.ErrRateLimited) || ctx.Err() != nil) {
 			resp.Message = fmt.Sprintf("执行动作 %s 失败: %v", spec.Type, err)
-			resp.Actions = summaries
+			resp.Actions = rec.Actions
 			return resp, err
 		}
-		summaries = append(summaries, summary)
-		updatePlaceholders(placeholders, spec.Type, summary)
+		if err != nil {
+			summary = model.ActionSummary{Type: spec.Type, Target: spec.TaskID, Error: err.Error()}
+		}
+		summary.Group = spec.Group
+		rec.Pending = rec.Pending[1:]
+		rec.Actions = append(rec.Actions, summary)
+		if err == nil {
+			registerOutputs(rec.Placeholders, spec.TaskID, summary)
+			if spec.Group != "" {
+				registerOutputs(rec.Placeholders, spec.Group, summary)
+			}
+		}
+		if spec.Group == "" || (len(rec.Pending) > 0 && rec.Pending[0].Group == spec.Group) {
+			continue
+		}
+		total := foreachSummary(spec.Group, rec.Actions)
+		rec.Actions = append(rec.Actions, total)
+		if total.Outputs["succeeded"] == "0" {
+			resp.Message = fmt.Sprintf("循环任务 %s 全部失败：%s", spec.Group, total.Note)
+			resp.Actions = rec.Actions
+			return resp, fmt.Errorf("foreach %s: all %s items failed", spec.Group, total.Outputs["total"])
+		}
 	}
 
 	resp.Success = true
-	resp.Actions = summaries
-	if llmOut.Reply != "" {
-		resp.Message = llmOut.Reply
-	} else {
-		resp.Message = "处理完成"
+	resp.Actions = rec.Actions
+	resp.Message = replyMessage(rec.Reply, rec.Actions, req.Locale())
+	if note := unverifiedNote(rec.Actions, req.Locale()); note != "" {
+		resp.Message += "\n" + note
+	}
+	// 大模型给出的回复不含执行结果，循环任务有失败项时另行说明
+	for _, a := range rec.Actions {
+		if rec.Reply != "" && a.Type == "foreach" && a.Outputs["failed"] != "0" {
+			resp.Message += "\n" + a.Note
+		}
 	}
 	return resp, nil
 }
 
-// applyPlaceholders 将 spec 中 Params 里的字符串值中的 {{key}} 替换为 placeholders[key]
-func applyPlaceholders(spec model.ActionSpec, placeholders map[string]string) model.ActionSpec {
-	if len(placeholders) == 0 {
-		return spec
+// runAction 执行单条动作；任务状态查询等依赖服务自身数据的动作在此处理，其余交给执行器
+func (s *ASRService) runAction(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, taskID string) (model.ActionSummary, error) {
+	switch spec.Type {
+	case model.ActionTypeQueryStatus:
+		return s.executor.Intercept(ctx, spec, req, func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+			return s.queryStatus(ctx, spec, req, taskID)
+		})
+	default:
+		return s.executor.Execute(ctx, spec, req)
+	}
+}
+
+// replyMessage 回复文本：大模型给出的回复优先，其次为查询类动作的结果（Outputs["answer"]），
+// 否则按请求语言由已执行的动作组成回复
+func replyMessage(reply string, actions []model.ActionSummary, locale string) string {
+	if reply != "" {
+		return reply
+	}
+	var answers []string
+	for _, a := range actions {
+		if answer := a.Outputs["answer"]; answer != "" {
+			answers = append(answers, answer)
+		}
 	}
-	out := spec
-	if spec.Params != nil {
-		out.Params = replacePlaceholdersInMap(spec.Params, placeholders)
+	if len(answers) > 0 {
+		return strings.Join(answers, "\n")
 	}
-	return out
+	return renderSummary(actions, locale)
 }
 
-// replacePlaceholdersInMap 递归替换 map 中所有字符串值的占位符
-func replacePlaceholdersInMap(m map[string]any, placeholders map[string]string) map[string]any {
-	result := make(map[string]any)
-	for k, v := range m {
-		result[k] = replacePlaceholdersInValue(v, placeholders)
+// startTask 创建并保存运行中的任务记录
+func (s *ASRService) startTask(ctx context.Context, req model.ASRRequest, source string) model.TaskRecord {
+	rec := model.TaskRecord{
+		ID:        strconv.FormatInt(time.Now().UnixNano(), 10),
+		TenantID:  req.Tenant(),
+		UserID:    req.UserID,
+		SessionID: req.SessionID(),
+		Source:    source,
+		Text:      req.Text,
+		Status:    model.TaskStatusRunning,
+		CreatedAt: time.Now(),
 	}
-	return result
+	s.saveTask(ctx, rec)
+	return rec
 }
 
-// replacePlaceholdersInValue 递归替换任意值中的占位符
-func replacePlaceholdersInValue(v any, placeholders map[string]string) any {
-	switch val := v.(type) {
-	case string:
-		return replacePlaceholdersInString(val, placeholders)
-	case map[string]any:
-		return replacePlaceholdersInMap(val, placeholders)
-	case map[string]string:
-		result := make(map[string]any)
-		for k, s := range val {
-			result[k] = replacePlaceholdersInString(s, placeholders)
-		}
-		return result
-	case []any:
-		result := make([]any, len(val))
-		for i, item := range val {
-			result[i] = replacePlaceholdersInValue(item, placeholders)
-		}
-		return result
-	default:
-		return v
+// finishTask 记录任务结果
+func (s *ASRService) finishTask(ctx context.Context, rec model.TaskRecord, resp model.ASRResponse, err error) {
+	rec.Status = model.TaskStatusSucceeded
+	switch {
+	case err != nil:
+		rec.Status = model.TaskStatusFailed
+		rec.Error = err.Error()
+	case resp.NeedConfirmation:
+		rec.Status = model.TaskStatusAwaitingConfirmation
+	}
+	rec.Message = resp.Message
+	rec.Actions = resp.Actions
+	rec.FinishedAt = time.Now()
+	recordUsage(ctx, &rec)
+	s.saveTask(ctx, rec)
+	for _, o := range s.observers {
+		o.ObserveTask(ctx, rec)
 	}
 }
 
-func replacePlaceholdersInString(s string, placeholders map[string]string) string {
-	return placeholderRE.ReplaceAllStringFunc(s, func(match string) string {
-		key := strings.TrimSuffix(strings.TrimPrefix(match, "{{"), "}}")
-		if v, ok := placeholders[key]; ok {
-			return v
-		}
-		return match
-	})
+// withMeters 挂上大模型用量与外部调用计数；已挂有时沿用（如「确认」经 ProcessFrom 进入 continueTask）
+func withMeters(ctx context.Context) context.Context {
+	ctx, _ = clientllm.WithUsage(ctx)
+	ctx, _ = transport.WithCounter(ctx)
+	return ctx
 }
 
-// updatePlaceholders 根据刚执行完的动作类型与结果，更新占位符供后续动作使用
-func updatePlaceholders(m map[string]string, actionType string, summary model.ActionSummary) {
-	switch actionType {
-	case "feishu_create_doc":
-		if summary.URL != "" {
-			m["doc_url"] = summary.URL
-			m["last_url"] = summary.URL
-		}
-		if summary.ID != "" {
-			m["doc_id"] = summary.ID
-		}
-		if summary.Note != "" {
-			m["last_note"] = summary.Note
-		}
-	case "feishu_create_folder":
-		if summary.URL != "" {
-			m["folder_url"] = summary.URL
-			m["last_url"] = summary.URL
+// recordUsage 把本次处理的用量累加到任务记录（确认、重试后继续执行的用量计入同一任务）
+func recordUsage(ctx context.Context, rec *model.TaskRecord) {
+	rec.APICalls += transport.CounterFrom(ctx).Count()
+	u := clientllm.UsageFrom(ctx).Totals()
+	if u.Calls == 0 {
+		return
+	}
+	if rec.LLMUsage == nil {
+		rec.LLMUsage = &model.LLMUsage{}
+	}
+	rec.LLMUsage.Calls += u.Calls
+	rec.LLMUsage.PromptTokens += u.PromptTokens
+	rec.LLMUsage.CompletionTokens += u.CompletionTokens
+}
+
+func (s *ASRService) saveTask(ctx context.Context, rec model.TaskRecord) {
+	if s.tasks == nil {
+		return
+	}
+	// 客户端断开后请求 ctx 已取消，任务记录仍需保存
+	if err := s.tasks.Save(context.WithoutCancel(ctx), rec); err != nil {
+		log.Printf("save task %s: %v", rec.ID, err)
+	}
+}
+
+// foreachSummary 汇总循环任务 group 各项的执行结果：成功数、失败数与失败原因
+func foreachSummary(group string, actions []model.ActionSummary) model.ActionSummary {
+	var total, succeeded int
+	var failed []string
+	for _, a := range actions {
+		if a.Group != group || a.Type == "foreach" {
+			continue
 		}
-		if summary.ID != "" {
-			m["folder_id"] = summary.ID
+		total++
+		if a.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s（%s）", a.Target, a.Error))
+		} else {
+			succeeded++
 		}
-		if summary.Note != "" {
-			m["last_note"] = summary.Note
+	}
+	summary := model.ActionSummary{
+		Type:   "foreach",
+		Target: group,
+		Group:  group,
+		Note:   fmt.Sprintf("成功 %d/%d", succeeded, total),
+		Outputs: map[string]string{
+			"total":     strconv.Itoa(total),
+			"succeeded": strconv.Itoa(succeeded),
+			"failed":    strconv.Itoa(len(failed)),
+		},
+	}
+	if len(failed) > 0 {
+		summary.Note += "，失败：" + strings.Join(failed, "；")
+	}
+	return summary
+}
+
+// registerOutputs 将刚执行完的动作输出登记为占位符供后续动作使用
+func registerOutputs(m map[string]string, taskID string, summary model.ActionSummary) {
+	for key, value := range summary.Outputs {
+		if value == "" {
+			continue
 		}
+		m[key] = value
+		m[taskID+"."+key] = value
+	}
+	if summary.URL != "" {
+		m["last_url"] = summary.URL
+	}
+	if summary.Note != "" {
+		m["last_note"] = summary.Note
 	}
 }
@@ -0,0 +1,195 @@
+// Package chart 将数值结果绘制为 PNG 图表（基于 go-chart）
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	gochart "github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+
+	"sayso-agent/internal/model"
+)
+
+// 画布尺寸（像素）
+const (
+	width  = 800
+	height = 480
+)
+
+var colorSeries = drawing.Color{R: 0x33, G: 0x70, B: 0xff, A: 0xff}
+
+// MaxPoints 单张图表最多绘制的数据点
+const MaxPoints = 50
+
+// Renderer 图表绘制器。go-chart 内置的 Roboto 字体没有中文字形，配置了含中文的 TrueType 字体时
+// x 轴直接标注类目名称，否则标注类目序号，名称由调用方随文字说明给出（见 Legend）
+type Renderer struct {
+	font *truetype.Font
+}
+
+// NewRenderer 创建绘制器；fontPath 为 TrueType 字体文件（.ttf，如 Noto Sans SC），为空时使用内置字体
+func NewRenderer(fontPath string) (*Renderer, error) {
+	if fontPath == "" {
+		return &Renderer{}, nil
+	}
+	data, err := os.ReadFile(fontPath)
+	if err != nil {
+		return nil, fmt.Errorf("read chart font: %w", err)
+	}
+	font, err := truetype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse chart font %s: %w", fontPath, err)
+	}
+	return &Renderer{font: font}, nil
+}
+
+// NamesLabels 图中是否直接标注类目名称；为 false 时标注序号
+func (r *Renderer) NamesLabels() bool {
+	return r != nil && r.font != nil
+}
+
+// Render 绘制柱状图（默认）或折线图；只有一个数据点的折线图按柱状图绘制
+func (r *Renderer) Render(c model.Chart) ([]byte, error) {
+	n := len(c.Values)
+	if n == 0 {
+		return nil, fmt.Errorf("chart has no values")
+	}
+	if n > MaxPoints {
+		return nil, fmt.Errorf("chart has %d values, at most %d", n, MaxPoints)
+	}
+	for _, v := range c.Values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("chart has invalid value %v", v)
+		}
+	}
+
+	var font *truetype.Font
+	if r != nil {
+		font = r.font
+	}
+	labels := r.labels(c)
+	lo, hi, step := axisRange(c.Values)
+	yAxis := gochart.YAxis{
+		Range:          &gochart.ContinuousRange{Min: lo, Max: hi},
+		Ticks:          yTicks(lo, hi, step),
+		ValueFormatter: func(v interface{}) string { return formatValue(v.(float64)) },
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if c.Kind == "line" && n > 1 {
+		xs := make([]float64, n)
+		ticks := make([]gochart.Tick, n)
+		for i := range xs {
+			xs[i] = float64(i)
+			ticks[i] = gochart.Tick{Value: float64(i), Label: labels[i]}
+		}
+		graph := gochart.Chart{
+			Width:  width,
+			Height: height,
+			Font:   font,
+			XAxis:  gochart.XAxis{Ticks: ticks},
+			YAxis:  yAxis,
+			Series: []gochart.Series{gochart.ContinuousSeries{
+				XValues: xs,
+				YValues: c.Values,
+				Style:   gochart.Style{StrokeColor: colorSeries, StrokeWidth: 2, DotColor: colorSeries, DotWidth: 3},
+			}},
+		}
+		err = graph.Render(gochart.PNG, &buf)
+	} else {
+		bars := make([]gochart.Value, n)
+		for i, v := range c.Values {
+			bars[i] = gochart.Value{Label: labels[i], Value: v, Style: gochart.Style{FillColor: colorSeries, StrokeColor: colorSeries}}
+		}
+		// 柱宽与间距按类目数铺满画布（扣除 y 轴刻度宽度）
+		slot := (width - 100) / n
+		graph := gochart.BarChart{
+			Width:        width,
+			Height:       height,
+			Font:         font,
+			YAxis:        yAxis,
+			BarWidth:     max(slot*3/5, 2),
+			BarSpacing:   max(slot*2/5, 1),
+			UseBaseValue: true,
+			Bars:         bars,
+		}
+		err = graph.Render(gochart.PNG, &buf)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("render chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Legend 图中序号与类目名称的对照，如 "1 华东　2 华南"；图中已标注类目名称时为空
+func (r *Renderer) Legend(c model.Chart) string {
+	if r.NamesLabels() {
+		return ""
+	}
+	var b strings.Builder
+	for i, l := range c.Labels {
+		if i > 0 {
+			b.WriteString("　")
+		}
+		fmt.Fprintf(&b, "%d %s", i+1, l)
+	}
+	return b.String()
+}
+
+// labels x 轴标注：有字体且名称齐全时为类目名称，否则为序号
+func (r *Renderer) labels(c model.Chart) []string {
+	labels := make([]string, len(c.Values))
+	for i := range labels {
+		labels[i] = strconv.Itoa(i + 1)
+		if r.NamesLabels() && len(c.Labels) == len(c.Values) && c.Labels[i] != "" {
+			labels[i] = c.Labels[i]
+		}
+	}
+	return labels
+}
+
+// axisRange 计算包含 0 的 y 轴范围与刻度间隔（1/2/5 × 10^n，约 5 格）
+func axisRange(values []float64) (lo, hi, step float64) {
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	raw := (hi - lo) / 5
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step = 10 * mag
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*mag {
+			step = m * mag
+			break
+		}
+	}
+	return math.Floor(lo/step) * step, math.Ceil(hi/step) * step, step
+}
+
+// yTicks y 轴刻度
+func yTicks(lo, hi, step float64) []gochart.Tick {
+	var ticks []gochart.Tick
+	for v := lo; v <= hi+step/2; v += step {
+		ticks = append(ticks, gochart.Tick{Value: v, Label: formatValue(v)})
+	}
+	return ticks
+}
+
+// formatValue 数值标注：最多保留两位小数
+func formatValue(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
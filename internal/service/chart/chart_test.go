@@ -0,0 +1,93 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+
+	"sayso-agent/internal/model"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		chart   model.Chart
+		wantErr bool
+	}{
+		{name: "bar", chart: model.Chart{Labels: []string{"华东", "华南", "华北"}, Values: []float64{120, 80.5, 42}}},
+		{name: "line", chart: model.Chart{Kind: "line", Labels: []string{"1月", "2月", "3月"}, Values: []float64{3, 5, 4}}},
+		{name: "negative", chart: model.Chart{Labels: []string{"a", "b"}, Values: []float64{-20, 35}}},
+		{name: "single line point", chart: model.Chart{Kind: "line", Labels: []string{"a"}, Values: []float64{7}}},
+		{name: "all zero", chart: model.Chart{Labels: []string{"a", "b"}, Values: []float64{0, 0}}},
+		{name: "max points", chart: model.Chart{Values: make([]float64, MaxPoints)}},
+		{name: "empty", chart: model.Chart{}, wantErr: true},
+		{name: "too many", chart: model.Chart{Values: make([]float64, MaxPoints+1)}, wantErr: true},
+		{name: "nan", chart: model.Chart{Values: []float64{1, math.NaN()}}, wantErr: true},
+	}
+	var r *Renderer // nil 使用内置字体
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := r.Render(tt.chart)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Render succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("decode png: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != width || b.Dy() != height {
+				t.Errorf("size = %v, want %dx%d", b, width, height)
+			}
+		})
+	}
+}
+
+func TestRendererFont(t *testing.T) {
+	c := model.Chart{Labels: []string{"East", "South"}, Values: []float64{1, 2}}
+
+	var builtin *Renderer
+	if got := builtin.Legend(c); got != "1 East　2 South" {
+		t.Errorf("builtin Legend = %q", got)
+	}
+	if got := builtin.labels(c); got[0] != "1" || got[1] != "2" {
+		t.Errorf("builtin labels = %v, want indices", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "font.ttf")
+	if err := os.WriteFile(path, goregular.TTF, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRenderer(path)
+	if err != nil {
+		t.Fatalf("NewRenderer: %v", err)
+	}
+	if got := r.Legend(c); got != "" {
+		t.Errorf("Legend with font = %q, want empty", got)
+	}
+	if got := r.labels(c); got[0] != "East" || got[1] != "South" {
+		t.Errorf("labels with font = %v, want names", got)
+	}
+	if _, err := r.Render(c); err != nil {
+		t.Errorf("Render with font: %v", err)
+	}
+
+	if _, err := NewRenderer(filepath.Join(t.TempDir(), "missing.ttf")); err == nil {
+		t.Error("NewRenderer with missing file succeeded")
+	}
+	bad := filepath.Join(t.TempDir(), "bad.ttf")
+	os.WriteFile(bad, []byte("not a font"), 0o600)
+	if _, err := NewRenderer(bad); err == nil {
+		t.Error("NewRenderer with invalid font succeeded")
+	}
+}
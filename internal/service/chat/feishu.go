@@ -0,0 +1,264 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// defaultMaxAttachmentBytes 未配置时单个附件的下载上限
+const defaultMaxAttachmentBytes = 20 << 20
+
+// voiceCommandRunes 转写结果不超过此字数时视为语音指令，否则视为录音内容（整理成纪要）
+const voiceCommandRunes = 200
+
+// Transcriber 语音转写（由 llm.Client 实现）
+type Transcriber interface {
+	Transcribe(ctx context.Context, fileName string, audio []byte) (string, error)
+}
+
+// FeishuConfig 飞书机器人消息配置
+type FeishuConfig struct {
+	// TenantID 消息所属租户，为空时使用默认租户
+	TenantID string
+	// MaxAttachmentBytes 单个附件（图片、文件、语音）的下载上限，0 使用 20MB
+	MaxAttachmentBytes int64
+}
+
+// FeishuMessage 机器人收到的消息（im.message.receive_v1 的 message 与 sender）
+type FeishuMessage struct {
+	MessageID   string `json:"message_id"`
+	RootID      string `json:"root_id"` // 话题（回复链）的根消息，不在话题中时为空
+	ChatID      string `json:"chat_id"`
+	ChatType    string `json:"chat_type"` // p2p | group
+	MessageType string `json:"message_type"`
+	Content     string `json:"content"`
+	// Mentions 消息中 @ 的用户与机器人
+	Mentions []FeishuMention `json:"mentions"`
+	// SenderOpenID 发送人 open_id
+	SenderOpenID string `json:"-"`
+	// TenantID 商店应用中发送人企业对应的租户，为空时使用配置的租户
+	TenantID string `json:"-"`
+}
+
+// Feishu 把飞书机器人收到的消息转换为处理请求：下载其中的图片、文件与语音，语音经转写后作为文本
+type Feishu struct {
+	cfg         FeishuConfig
+	client      *feishu.Client
+	transcriber Transcriber
+
+	mu   sync.Mutex
+	bots map[string]string // 租户 -> 机器人 open_id
+}
+
+// NewFeishu 创建飞书消息转换器
+func NewFeishu(cfg FeishuConfig, client *feishu.Client, transcriber Transcriber) *Feishu {
+	if cfg.MaxAttachmentBytes <= 0 {
+		cfg.MaxAttachmentBytes = defaultMaxAttachmentBytes
+	}
+	return &Feishu{cfg: cfg, client: client, transcriber: transcriber, bots: make(map[string]string)}
+}
+
+// threadRoot 消息所在话题的根消息：已在话题中时为话题根消息，群聊中的新消息以自身为根开启话题，私聊不开启话题
+func (msg FeishuMessage) threadRoot() string {
+	if msg.RootID != "" {
+		return msg.RootID
+	}
+	if msg.ChatType == "group" {
+		return msg.MessageID
+	}
+	return ""
+}
+
+// tenant 消息所属租户
+func (f *Feishu) tenant(msg FeishuMessage) string {
+	if msg.TenantID != "" {
+		return msg.TenantID
+	}
+	return f.cfg.TenantID
+}
+
+// reMention 文本消息中的 @ 占位符，如 @_user_1
+var reMention = regexp.MustCompile(`@_user_\d+\s*`)
+
+// messageContent 各类消息 content 中用到的字段
+type messageContent struct {
+	Text     string `json:"text"`
+	ImageKey string `json:"image_key"`
+	FileKey  string `json:"file_key"`
+	FileName string `json:"file_name"`
+	Title    string `json:"title"`
+	// Content 富文本（post）的段落
+	Content [][]struct {
+		Tag      string `json:"tag"`
+		Text     string `json:"text"`
+		Href     string `json:"href"`
+		ImageKey string `json:"image_key"`
+		UserID   string `json:"user_id"` // at：提及的占位符，如 @_user_1
+	} `json:"content"`
+}
+
+// Request 把消息转换为处理请求：文本直接作为指令（去掉 @机器人，其他 @ 的人加入联系人）；图片、文件作为附件；
+// 语音转写后，简短的作为指令，较长的录音整理成纪要文档；发送人作为请求人，"发到这个群"指当前会话。
+// 群聊中的消息以话题为会话（见 model.ASRRequest.SessionID），话题中的成员共享上下文
+func (f *Feishu) Request(ctx context.Context, msg FeishuMessage) (model.ASRRequest, error) {
+	req := model.ASRRequest{
+		UserID:   msg.SenderOpenID,
+		TenantID: f.tenant(msg),
+		Context: map[string]string{
+			"feishu_open_id":    msg.SenderOpenID,
+			"feishu_chat_id":    msg.ChatID,
+			"feishu_message_id": msg.MessageID,
+		},
+	}
+	if root := msg.threadRoot(); root != "" {
+		req.Context["feishu_root_id"] = root
+	}
+	var c messageContent
+	if err := json.Unmarshal([]byte(msg.Content), &c); err != nil {
+		return req, fmt.Errorf("%w: %s message content: %v", model.ErrInvalidParams, msg.MessageType, err)
+	}
+	ctx = f.client.WithTenant(ctx, req.Tenant())
+	switch msg.MessageType {
+	case "text":
+		req.Text, req.Contacts = resolveMentions(c.Text, msg.Mentions, f.mentionedBot(ctx, msg, req.Tenant()))
+	case "image":
+		a, err := f.download(ctx, msg.MessageID, c.ImageKey, "image", "image.png")
+		if err != nil {
+			return req, err
+		}
+		req.Attachments = append(req.Attachments, a)
+		req.Text = "识别这张图片并按图片内容处理"
+	case "file", "media":
+		a, err := f.download(ctx, msg.MessageID, c.FileKey, "file", c.FileName)
+		if err != nil {
+			return req, err
+		}
+		req.Attachments = append(req.Attachments, a)
+		req.Text = fmt.Sprintf("把文件「%s」导入为文档并总结要点", a.Name)
+	case "audio":
+		text, err := f.transcribe(ctx, msg.MessageID, c.FileKey)
+		if err != nil {
+			return req, err
+		}
+		req.Text = text
+		if len([]rune(text)) > voiceCommandRunes {
+			req.Text = "把这段录音整理成会议纪要文档：\n" + text
+		}
+	case "post":
+		var lines []string
+		if c.Title != "" {
+			lines = append(lines, c.Title)
+		}
+		for _, para := range c.Content {
+			var line strings.Builder
+			for _, el := range para {
+				switch el.Tag {
+				case "at":
+					line.WriteString(el.UserID)
+				case "text", "a":
+					line.WriteString(el.Text)
+					if el.Href != "" {
+						line.WriteString(" " + el.Href)
+					}
+				case "img":
+					a, err := f.download(ctx, msg.MessageID, el.ImageKey, "image", fmt.Sprintf("image%d.png", len(req.Attachments)+1))
+					if err != nil {
+						return req, err
+					}
+					req.Attachments = append(req.Attachments, a)
+				}
+			}
+			if s := strings.TrimSpace(line.String()); s != "" {
+				lines = append(lines, s)
+			}
+		}
+		req.Text, req.Contacts = resolveMentions(strings.Join(lines, "\n"), msg.Mentions, f.mentionedBot(ctx, msg, req.Tenant()))
+		if req.Text == "" && len(req.Attachments) > 0 {
+			req.Text = "识别图片并按图片内容处理"
+		}
+	default:
+		return req, fmt.Errorf("%w: unsupported message type %q", model.ErrInvalidParams, msg.MessageType)
+	}
+	if req.Text == "" {
+		return req, fmt.Errorf("%w: empty message", model.ErrInvalidParams)
+	}
+	if name := f.requesterName(ctx, msg.SenderOpenID); name != "" {
+		req.Context["user_name"] = name
+	}
+	return req, nil
+}
+
+// mentionedBot 消息 @ 了人时查询机器人的 open_id，以区分 @机器人 与 @其他人
+func (f *Feishu) mentionedBot(ctx context.Context, msg FeishuMessage, tenant string) string {
+	if len(msg.Mentions) == 0 {
+		return ""
+	}
+	return f.botOpenID(ctx, tenant)
+}
+
+// download 下载消息中的资源作为附件；name 为空时使用响应中的文件名
+func (f *Feishu) download(ctx context.Context, messageID, key, resourceType, name string) (model.Attachment, error) {
+	if key == "" {
+		return model.Attachment{}, fmt.Errorf("%w: %s resource key missing", model.ErrInvalidParams, resourceType)
+	}
+	token, err := f.client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	res, err := f.client.GetMessageResource(ctx, token, messageID, key, resourceType, f.cfg.MaxAttachmentBytes)
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	if name == "" {
+		name = res.Name
+	}
+	if name == "" {
+		name = key
+	}
+	return model.Attachment{Name: name, MimeType: res.MimeType, Data: res.Data}, nil
+}
+
+// transcribe 下载语音消息（opus）并转写为文本
+func (f *Feishu) transcribe(ctx context.Context, messageID, fileKey string) (string, error) {
+	if f.transcriber == nil {
+		return "", fmt.Errorf("%w: audio message not supported", model.ErrInvalidParams)
+	}
+	a, err := f.download(ctx, messageID, fileKey, "file", "audio.opus")
+	if err != nil {
+		return "", err
+	}
+	text, err := f.transcriber.Transcribe(ctx, a.Name, a.Data)
+	if err != nil {
+		return "", fmt.Errorf("transcribe audio: %w", err)
+	}
+	return strings.TrimSpace(text), nil
+}
+
+// Reply 把处理结果以文本回复到原消息：结果说明与各动作的链接；群聊与话题中的消息回复到话题中
+func (f *Feishu) Reply(ctx context.Context, msg FeishuMessage, resp model.ASRResponse) error {
+	lines := []string{resp.Message}
+	for _, a := range resp.Actions {
+		if a.URL != "" {
+			lines = append(lines, a.URL)
+		}
+	}
+	text := strings.TrimSpace(strings.Join(lines, "\n"))
+	if text == "" {
+		return nil
+	}
+	ctx = f.client.WithTenant(ctx, model.ASRRequest{TenantID: f.tenant(msg)}.Tenant())
+	token, err := f.client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	content, _ := json.Marshal(map[string]string{"text": text})
+	_, err = f.client.ReplyMessage(ctx, token, msg.MessageID, "text", string(content), msg.threadRoot() != "")
+	return err
+}
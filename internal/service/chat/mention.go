@@ -0,0 +1,81 @@
+package chat
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// FeishuMention 消息中的 @ 提及：文本中以 Key（如 @_user_1）占位
+type FeishuMention struct {
+	Key string `json:"key"`
+	ID  struct {
+		OpenID string `json:"open_id"`
+	} `json:"id"`
+	Name string `json:"name"`
+}
+
+// resolveMentions 处理消息中的 @：去掉 @机器人，其他被 @ 的人替换为姓名并加入联系人（发消息时按 open_id 发送）；
+// 不知道机器人的 open_id 时，把开头的 @ 视为唤起机器人
+func resolveMentions(text string, mentions []FeishuMention, botOpenID string) (string, []model.Contact) {
+	var contacts []model.Contact
+	leading := true
+	for _, m := range mentions {
+		if m.Key == "" {
+			continue
+		}
+		isBot := botOpenID != "" && m.ID.OpenID == botOpenID
+		if botOpenID == "" && leading && strings.HasPrefix(strings.TrimSpace(text), m.Key) {
+			isBot = true
+		} else {
+			leading = false
+		}
+		if isBot {
+			text = strings.ReplaceAll(text, m.Key, "")
+			continue
+		}
+		text = strings.ReplaceAll(text, m.Key, m.Name)
+		if m.Name != "" && m.ID.OpenID != "" {
+			contacts = append(contacts, model.Contact{Name: m.Name, OpenID: m.ID.OpenID})
+		}
+	}
+	return strings.TrimSpace(reMention.ReplaceAllString(text, "")), contacts
+}
+
+// botOpenID 机器人在租户所在企业中的 open_id，成功后按租户缓存；获取失败时返回空
+func (f *Feishu) botOpenID(ctx context.Context, tenant string) string {
+	f.mu.Lock()
+	id, ok := f.bots[tenant]
+	f.mu.Unlock()
+	if ok {
+		return id
+	}
+	token, err := f.client.GetTenantAccessToken(ctx)
+	if err == nil {
+		id, err = f.client.GetBotOpenID(ctx, token)
+	}
+	if err != nil {
+		log.Printf("feishu bot info of tenant %s: %v", tenant, err)
+		return ""
+	}
+	f.mu.Lock()
+	f.bots[tenant] = id
+	f.mu.Unlock()
+	return id
+}
+
+// requesterName 发送人姓名，作为 Context["user_name"]（文档作者、话题中的发言人）；获取失败时为空
+func (f *Feishu) requesterName(ctx context.Context, openID string) string {
+	token, err := f.client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return ""
+	}
+	name, err := f.client.GetUserName(ctx, token, openID)
+	if err != nil {
+		log.Printf("feishu get name of %s: %v", openID, err)
+		return ""
+	}
+	return name
+}
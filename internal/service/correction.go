@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/model"
+	servicellm "sayso-agent/internal/service/llm"
+)
+
+// 自动纠正：用户紧接着说"不对，我是说发给李四"时，由大模型对照上一个任务的计划重新生成受影响的参数，
+// 只重新执行改动的动作，并补偿做错的部分（撤回发错的消息、修改写错的文档标题）
+
+// correctionWindow 只更正该时间内创建的任务
+const correctionWindow = 10 * time.Minute
+
+// reCorrection 以更正语气开头的输入
+var reCorrection = regexp.MustCompile(`^(?:不对|不是|错了|搞错了|说错了|我是说|我说的是|应该是|我的意思是)`)
+
+func isCorrection(text string) bool { return reCorrection.MatchString(strings.TrimSpace(text)) }
+
+// correctTask 按更正重新执行原任务中受影响的动作；原任务没有可对照的计划时 ok 为 false，按普通请求处理
+func (s *ASRService) correctTask(ctx context.Context, old model.TaskRecord, req model.ASRRequest) (model.ASRResponse, bool, error) {
+	// 动作按计划顺序逐条追加，数量一致时 Actions[i] 即 Plan[i] 的结果
+	if len(old.Plan) == 0 || len(old.Actions) != len(old.Plan) {
+		return model.ASRResponse{}, false, nil
+	}
+	rec := s.startTask(ctx, req, model.TaskSourceCorrection)
+	rec.CorrectionOf = old.ID
+	resp := model.ASRResponse{TaskID: rec.ID}
+
+	corrected, err := s.llm.Correct(ctx, old.Text, old.Plan, req.Text)
+	if err != nil {
+		resp.Message = fmt.Sprintf("大模型处理失败: %v", err)
+		s.finishTask(ctx, rec, resp, err)
+		return resp, true, err
+	}
+	s.markCorrected(ctx, old, req)
+
+	pending, notes := correctionPlan(old, corrected)
+	if len(pending) == 0 {
+		resp.Success = true
+		resp.Message = "没有需要更正的内容"
+		s.finishTask(ctx, rec, resp, nil)
+		return resp, true, nil
+	}
+
+	if old.Request != nil {
+		// 沿用原请求的租户、会话与上下文，只替换文本
+		orig := *old.Request
+		orig.Text = req.Text
+		req = orig
+	}
+	rec.Request = &req
+	rec.Intent = "更正：" + old.Intent
+	rec.Plan = pending
+	rec.Pending = pending
+	// 未重新执行的动作的输出仍可被引用
+	rec.Placeholders = maps.Clone(old.Placeholders)
+	if rec.Placeholders == nil {
+		rec.Placeholders = make(map[string]string)
+	}
+	resp, err = s.resume(ctx, &rec, resp, &req)
+	if err == nil && len(notes) > 0 {
+		resp.Message += "\n" + strings.Join(notes, "；")
+	}
+	s.finishTask(ctx, rec, resp, err)
+	return resp, true, err
+}
+
+// markCorrected 给原任务记一条差评，更正内容即用户说明的正确做法；用户已主动反馈时不覆盖
+func (s *ASRService) markCorrected(ctx context.Context, old model.TaskRecord, req model.ASRRequest) {
+	if old.Feedback != nil {
+		return
+	}
+	old.Feedback = &model.TaskFeedback{Rating: model.FeedbackDown, Correction: req.Text, UserID: req.UserID, CreatedAt: time.Now()}
+	s.saveTask(ctx, old)
+}
+
+// correctionPlan 对照原计划与更正后的动作，生成补偿与重新执行的动作列表；
+// notes 为无法自动撤销的动作说明
+func correctionPlan(old model.TaskRecord, corrected []servicellm.CorrectedAction) (pending []model.ActionSpec, notes []string) {
+	kept := make(map[int]bool, len(corrected))
+	for _, c := range corrected {
+		if c.Index > 0 {
+			kept[c.Index] = true
+		}
+	}
+	// 不再需要的动作只做补偿
+	for i := range old.Plan {
+		if !kept[i+1] {
+			comp, note := compensation(old.Plan[i], old.Actions[i])
+			pending = append(pending, comp...)
+			notes = appendNote(notes, note)
+		}
+	}
+
+	rerun := false
+	for _, c := range corrected {
+		if c.Index == 0 {
+			pending = append(pending, c.Spec)
+			continue
+		}
+		orig, done := old.Plan[c.Index-1], old.Actions[c.Index-1]
+		c.Spec.TaskID, c.Spec.Group = orig.TaskID, orig.Group
+		// 前面有动作重新执行后，引用其输出的动作也要跟着重新执行
+		unchanged := c.Spec.Type == orig.Type && sameParams(c.Spec.Params, orig.Params)
+		if unchanged && !(rerun && hasPlaceholder(orig.Params)) {
+			continue
+		}
+		if rename, ok := renameSpec(orig, c.Spec, done); ok {
+			pending = append(pending, rename)
+			continue
+		}
+		comp, note := compensation(orig, done)
+		pending = append(pending, comp...)
+		pending = append(pending, c.Spec)
+		notes = appendNote(notes, note)
+		rerun = true
+	}
+	return pending, notes
+}
+
+// compensation 撤销已执行动作的补偿动作：消息撤回；其他动作无法自动撤销时返回说明
+func compensation(orig model.ActionSpec, done model.ActionSummary) ([]model.ActionSpec, string) {
+	if orig.Type != model.ActionTypeSendMessage {
+		return nil, fmt.Sprintf("「%s」无法自动撤销，请手动处理", orDefault(done.Target, orig.Type))
+	}
+	recipients := done.Outputs["recipients"]
+	var specs []model.ActionSpec
+	recall := func(platform, ids, chats string) {
+		if ids == "" {
+			return
+		}
+		specs = append(specs, model.ActionSpec{
+			Type:   model.ActionTypeRecallMessage,
+			Params: map[string]any{"platform": platform, "message_ids": ids, "chat_ids": chats, "recipients": recipients},
+		})
+	}
+	switch done.Type {
+	case "message":
+		// 多个平台合并的摘要，按平台分别撤回
+		for _, p := range []string{"feishu", "slack", "discord"} {
+			recall(p, done.Outputs[p+"_message.message_ids"], done.Outputs[p+"_message.chat_ids"])
+		}
+	case "slack_message":
+		recall("slack", done.Outputs["message_ids"], done.Outputs["chat_ids"])
+	case "discord_message":
+		recall("discord", done.Outputs["message_ids"], done.Outputs["chat_ids"])
+	default:
+		recall("feishu", done.Outputs["message_ids"], done.Outputs["chat_ids"])
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Sprintf("发给%s的消息未找到消息 ID，无法撤回", orDefault(recipients, done.Target))
+	}
+	return specs, ""
+}
+
+// renameSpec 创建文档只改了标题时，修改原文档标题而不是新建一份
+func renameSpec(orig, corrected model.ActionSpec, done model.ActionSummary) (model.ActionSpec, bool) {
+	docID := done.Outputs["doc_id"]
+	if orig.Type != model.ActionTypeCreateDoc || corrected.Type != orig.Type || docID == "" {
+		return model.ActionSpec{}, false
+	}
+	title, _ := corrected.Params["title"].(string)
+	if strings.TrimSpace(title) == "" || !sameParams(withoutKey(corrected.Params, "title"), withoutKey(orig.Params, "title")) {
+		return model.ActionSpec{}, false
+	}
+	return model.ActionSpec{
+		TaskID: orig.TaskID,
+		Group:  orig.Group,
+		Type:   model.ActionTypeRenameDoc,
+		Params: map[string]any{"doc_id": docID, "title": title, "old_title": done.Target},
+	}, true
+}
+
+// sameParams 按 JSON 比较参数（大模型输出与存储往返后的数值类型一致）
+func sameParams(a, b map[string]any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+func hasPlaceholder(params map[string]any) bool {
+	b, _ := json.Marshal(params)
+	return strings.Contains(string(b), "{{")
+}
+
+func withoutKey(m map[string]any, key string) map[string]any {
+	out := maps.Clone(m)
+	delete(out, key)
+	return out
+}
+
+func appendNote(notes []string, note string) []string {
+	if note == "" {
+		return notes
+	}
+	return append(notes, note)
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+// Package deadline 请求级时限：把一个请求的总时限分配到规划、参数提取、执行三个阶段，
+// 前一阶段没用完的时间顺延给后续阶段，超时时报告是哪个阶段超出。
+package deadline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Phase 处理阶段
+type Phase int
+
+const (
+	Planning   Phase = iota // 规划：识别图片附件、匹配工作流、拆分任务
+	Extraction              // 参数提取：按技能调用大模型提取动作参数
+	Execution               // 执行：调用飞书、Slack 等外部接口
+)
+
+var phaseNames = [...]string{"planning", "extraction", "execution"}
+
+func (p Phase) String() string { return phaseNames[p] }
+
+// Label 中文阶段名，用于回复用户
+func (p Phase) Label() string {
+	return [...]string{"规划", "参数提取", "执行"}[p]
+}
+
+// Shares 各阶段的时间份额（相对权重），全为 0 时使用默认 30/30/40
+type Shares [3]int
+
+// DefaultShares 默认份额
+var DefaultShares = Shares{30, 30, 40}
+
+// Budget 一个请求的时限
+type Budget struct {
+	total  time.Duration
+	end    time.Time
+	shares Shares
+}
+
+// ExceededError 某阶段超出分配的时限
+type ExceededError struct {
+	Phase  Phase
+	Budget time.Duration // 该阶段分配到的时间
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s phase exceeded its %s budget", e.Phase, e.Budget.Round(time.Millisecond))
+}
+
+// Unwrap 使 errors.Is(err, context.DeadlineExceeded) 成立
+func (e *ExceededError) Unwrap() error { return context.DeadlineExceeded }
+
+type budgetKey struct{}
+type phaseKey struct{}
+
+type phaseInfo struct {
+	phase  Phase
+	budget time.Duration
+}
+
+// Start 为请求设置总时限并放入 ctx；total <= 0 时不限制，返回原 ctx
+func Start(ctx context.Context, total time.Duration, shares Shares) (context.Context, context.CancelFunc) {
+	if total <= 0 {
+		return ctx, func() {}
+	}
+	if shares == (Shares{}) {
+		shares = DefaultShares
+	}
+	b := &Budget{total: total, end: time.Now().Add(total), shares: shares}
+	ctx, cancel := context.WithDeadline(ctx, b.end)
+	return context.WithValue(ctx, budgetKey{}, b), cancel
+}
+
+// Enter 进入某阶段：该阶段可用剩余时间中的一份（按本阶段及之后阶段的份额分配），最后一个阶段用完全部剩余时间；
+// ctx 中没有时限时原样返回
+func Enter(ctx context.Context, phase Phase) (context.Context, context.CancelFunc) {
+	b, ok := ctx.Value(budgetKey{}).(*Budget)
+	if !ok {
+		return ctx, func() {}
+	}
+	remaining := time.Until(b.end)
+	rest := 0
+	for _, s := range b.shares[phase:] {
+		rest += s
+	}
+	budget := remaining
+	if rest > 0 {
+		budget = remaining * time.Duration(b.shares[phase]) / time.Duration(rest)
+	}
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	return context.WithValue(ctx, phaseKey{}, phaseInfo{phase: phase, budget: budget}), cancel
+}
+
+// Check 阶段结束后检查：阶段 ctx 已超时且 err 因此产生时，返回标明阶段的 *ExceededError（保留原错误信息），否则原样返回
+func Check(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	info, ok := ctx.Value(phaseKey{}).(phaseInfo)
+	if !ok {
+		return err
+	}
+	var exceeded *ExceededError
+	if errors.As(err, &exceeded) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", &ExceededError{Phase: info.phase, Budget: info.budget}, err)
+}
@@ -0,0 +1,291 @@
+// Package directory 通讯录同步：定期把飞书通讯录与 Slack 成员列表合并进跨平台身份映射，
+// 按姓名、邮箱解析接收方时直接查本地映射，不必每次调用搜索接口
+package directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/client/slack"
+	"sayso-agent/internal/model"
+)
+
+// Store 身份映射存储（由 store.IdentityStore 实现）
+type Store interface {
+	Save(ctx context.Context, u model.UserIdentity) error
+	Lookup(ctx context.Context, tenantID, key string) (model.UserIdentity, error)
+	List(ctx context.Context, tenantID string) ([]model.UserIdentity, error)
+}
+
+// Syncer 通讯录同步；飞书每次拉取全量后只写入有变化的条目，离职或已不在通讯录中的账号从映射中移除，
+// Slack 只处理上次同步后更新过的成员（按 updated 时间）
+type Syncer struct {
+	feishu *feishu.Client // 可选
+	slack  *slack.Client  // 可选
+	store  Store
+
+	mu         sync.Mutex
+	slackSince map[string]time.Time // 租户 -> 已同步的 Slack 成员最新更新时间
+}
+
+// NewSyncer 创建通讯录同步；feishuClient、slackClient 为 nil 时不同步对应平台
+func NewSyncer(feishuClient *feishu.Client, slackClient *slack.Client, store Store) *Syncer {
+	return &Syncer{feishu: feishuClient, slack: slackClient, store: store, slackSince: make(map[string]time.Time)}
+}
+
+// account 一个平台账号
+type account struct {
+	platform string
+	id       string
+	name     string
+	aliases  []string
+	email    string
+	phone    string
+	title    string
+	// employmentType 人员类型，只有飞书通讯录提供
+	employmentType string
+}
+
+// feishuEmployeeTypes 飞书通讯录的人员类型
+var feishuEmployeeTypes = map[int]string{
+	1: model.EmploymentRegular,
+	2: model.EmploymentIntern,
+	3: model.EmploymentOutsourced,
+	4: model.EmploymentLabor,
+	5: model.EmploymentConsultant,
+}
+
+// Run 同步各租户（商店应用为每个安装的企业，否则为默认租户）的通讯录，汇总各平台的错误
+func (s *Syncer) Run(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tenants := []string{""}
+	if s.feishu != nil && s.feishu.Marketplace() {
+		tenants = s.feishu.Tenants()
+	}
+	var errs []error
+	for _, tenant := range tenants {
+		tenantID := tenant
+		if tenantID == "" {
+			tenantID = model.DefaultTenant
+		}
+		if s.feishu != nil {
+			if err := s.syncFeishu(s.feishu.WithTenant(ctx, tenant), tenantID); err != nil {
+				errs = append(errs, fmt.Errorf("feishu tenant %q: %w", tenantID, err))
+			}
+		}
+		if s.slack != nil {
+			if err := s.syncSlack(ctx, tenantID); err != nil {
+				errs = append(errs, fmt.Errorf("slack tenant %q: %w", tenantID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Syncer) syncFeishu(ctx context.Context, tenantID string) error {
+	token, err := s.feishu.GetTenantAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	employees, err := s.feishu.ListEmployees(ctx, token)
+	if err != nil {
+		return err
+	}
+	active := make(map[string]bool, len(employees))
+	var updated, removed int
+	for _, e := range employees {
+		if e.Resigned {
+			continue
+		}
+		active[e.OpenID] = true
+		changed, err := s.merge(ctx, tenantID, account{
+			platform: "feishu",
+			id:       e.OpenID,
+			name:     e.Name,
+			aliases:  []string{e.EnName, e.Nickname},
+			email:    e.Email,
+			phone:    e.Mobile,
+			title:    e.Title,
+
+			employmentType: feishuEmployeeTypes[e.Type],
+		})
+		if err != nil {
+			return err
+		}
+		if changed {
+			updated++
+		}
+	}
+	// 离职或已不在通讯录中的飞书账号从映射中移除（手工维护的条目保留）
+	list, err := s.store.List(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, u := range list {
+		if u.FeishuOpenID == "" || active[u.FeishuOpenID] || u.Source == model.IdentitySourceManual {
+			continue
+		}
+		u.FeishuOpenID, u.UpdatedAt = "", time.Now()
+		if err := s.store.Save(ctx, u); err != nil {
+			return err
+		}
+		removed++
+	}
+	log.Printf("directory sync feishu tenant=%s employees=%d updated=%d removed=%d", tenantID, len(employees), updated, removed)
+	return nil
+}
+
+func (s *Syncer) syncSlack(ctx context.Context, tenantID string) error {
+	users, err := s.slack.ForTenant(tenantID).ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	since := s.slackSince[tenantID]
+	latest := since
+	var updated, removed int
+	for _, u := range users {
+		if !u.UpdatedAt.After(since) {
+			continue
+		}
+		if u.UpdatedAt.After(latest) {
+			latest = u.UpdatedAt
+		}
+		if u.Deleted {
+			ok, err := s.remove(ctx, tenantID, u.ID)
+			if err != nil {
+				return err
+			}
+			if ok {
+				removed++
+			}
+			continue
+		}
+		changed, err := s.merge(ctx, tenantID, account{
+			platform: "slack",
+			id:       u.ID,
+			name:     firstNonEmpty(u.RealName, u.DisplayName, u.Name),
+			aliases:  []string{u.DisplayName, u.Name},
+			email:    u.Email,
+			phone:    u.Phone,
+			title:    u.Title,
+		})
+		if err != nil {
+			return err
+		}
+		if changed {
+			updated++
+		}
+	}
+	s.slackSince[tenantID] = latest
+	log.Printf("directory sync slack tenant=%s users=%d updated=%d removed=%d", tenantID, len(users), updated, removed)
+	return nil
+}
+
+// merge 把账号合并进身份映射，返回是否有变化：依次按该平台 ID、邮箱、姓名找到已有条目（按姓名合并要求该条目还没有
+// 该平台的账号且邮箱不冲突，避免把同名的两个人合成一个），找不到时新建；已有的邮箱、手机号不覆盖
+func (s *Syncer) merge(ctx context.Context, tenantID string, a account) (bool, error) {
+	u, ok := s.find(ctx, tenantID, a)
+	if !ok {
+		u = model.UserIdentity{TenantID: tenantID, Name: s.uniqueName(ctx, tenantID, a), Source: a.platform}
+	}
+	before := u
+	before.Aliases = slices.Clone(u.Aliases)
+	setPlatformID(&u, a.platform, a.id)
+	if u.Email == "" {
+		u.Email = a.email
+	}
+	if u.Phone == "" {
+		u.Phone = a.phone
+	}
+	// 职位、人员类型以飞书通讯录为准，Slack 的职位只在为空时补充
+	if a.title != "" && (u.Title == "" || a.platform == "feishu") {
+		u.Title = a.title
+	}
+	if a.employmentType != "" {
+		u.EmploymentType = a.employmentType
+	}
+	for _, alias := range append([]string{a.name}, a.aliases...) {
+		alias = strings.TrimSpace(alias)
+		if alias == "" || strings.EqualFold(alias, u.Name) || slices.ContainsFunc(u.Aliases, func(v string) bool { return strings.EqualFold(v, alias) }) {
+			continue
+		}
+		u.Aliases = append(u.Aliases, alias)
+	}
+	if ok && sameIdentity(before, u) {
+		return false, nil
+	}
+	u.UpdatedAt = time.Now()
+	return true, s.store.Save(ctx, u)
+}
+
+func (s *Syncer) find(ctx context.Context, tenantID string, a account) (model.UserIdentity, bool) {
+	if u, err := s.store.Lookup(ctx, tenantID, a.id); err == nil && u.PlatformID(a.platform) == a.id {
+		return u, true
+	}
+	if a.email != "" {
+		if u, err := s.store.Lookup(ctx, tenantID, a.email); err == nil && strings.EqualFold(u.Email, a.email) {
+			return u, true
+		}
+	}
+	if a.name != "" {
+		u, err := s.store.Lookup(ctx, tenantID, a.name)
+		if err == nil && u.Name == a.name && u.PlatformID(a.platform) == "" && (u.Email == "" || a.email == "" || strings.EqualFold(u.Email, a.email)) {
+			return u, true
+		}
+	}
+	return model.UserIdentity{}, false
+}
+
+// uniqueName 新条目的姓名；已有同名的其他人时附上邮箱或账号 ID 区分
+func (s *Syncer) uniqueName(ctx context.Context, tenantID string, a account) string {
+	name := firstNonEmpty(a.name, a.email, a.id)
+	if u, err := s.store.Lookup(ctx, tenantID, name); err != nil || u.Name != name {
+		return name
+	}
+	return fmt.Sprintf("%s（%s）", name, firstNonEmpty(a.email, a.id))
+}
+
+// remove 移除 Slack 账号，返回是否有条目被修改
+func (s *Syncer) remove(ctx context.Context, tenantID, id string) (bool, error) {
+	u, err := s.store.Lookup(ctx, tenantID, id)
+	if err != nil || u.SlackUserID != id {
+		return false, nil
+	}
+	u.SlackUserID, u.UpdatedAt = "", time.Now()
+	return true, s.store.Save(ctx, u)
+}
+
+func setPlatformID(u *model.UserIdentity, platform, id string) {
+	switch platform {
+	case "feishu":
+		u.FeishuOpenID = id
+	case "slack":
+		u.SlackUserID = id
+	case "discord":
+		u.DiscordUserID = id
+	}
+}
+
+// sameIdentity 除更新时间外是否一致
+func sameIdentity(a, b model.UserIdentity) bool {
+	a.UpdatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/service/executor"
+)
+
+// ErrNotTaskRequester 操作人不是任务的请求人（如别人点击了转发出去的预览卡片）
+var ErrNotTaskRequester = errors.New("operator is not the task requester")
+
+// ReviewDraft 处理草稿预览卡片的按钮回调：「发送」确认并继续执行任务，「修改」保持待确认，等待用户说出修改意见；
+// operator 为点击按钮的飞书 open_id，只有任务的请求人可以操作
+func (s *ASRService) ReviewDraft(ctx context.Context, taskID, operator, decision string) (model.ASRResponse, error) {
+	rec, err := s.loadTask(ctx, taskID, model.TaskStatusAwaitingConfirmation, ErrNotAwaitingConfirmation)
+	if err != nil {
+		return model.ASRResponse{TaskID: taskID}, err
+	}
+	if !isRequester(rec, operator) {
+		return model.ASRResponse{TaskID: taskID}, fmt.Errorf("task %s: %w", taskID, ErrNotTaskRequester)
+	}
+	switch decision {
+	case executor.DraftDecisionSend:
+		return s.Confirm(ctx, taskID)
+	case executor.DraftDecisionEdit:
+		return model.ASRResponse{TaskID: taskID, NeedConfirmation: true, Message: "请直接说出修改意见，如「把第二段删掉，语气再轻一点」"}, nil
+	}
+	return model.ASRResponse{TaskID: taskID}, fmt.Errorf("%w: unknown draft decision %q", model.ErrInvalidParams, decision)
+}
+
+// isRequester operator 是否为任务请求人：与请求中的飞书 open_id 或用户 ID 一致
+func isRequester(rec model.TaskRecord, operator string) bool {
+	if operator == "" {
+		return false
+	}
+	if rec.Request != nil && rec.Request.Context["feishu_open_id"] == operator {
+		return true
+	}
+	return rec.UserID == operator
+}
+
+// reDraftRevision 修改草稿的意见：删改段落语句、调整语气与篇幅等
+var reDraftRevision = regexp.MustCompile(`删|去掉|加上|补充|改成|改为|换成|语气|口吻|措辞|短一点|长一点|简短|简洁|详细|正式|委婉|客气|第.{1,2}[段句]|开头|结尾|再.{0,4}(?:一点|一些)`)
+
+func isDraftRevision(text string) bool { return reDraftRevision.MatchString(strings.TrimSpace(text)) }
+
+// pendingDraft 任务暂停在带生成草稿的 create_doc / send_message 上（计划超限待确认的除外）
+func pendingDraft(rec model.TaskRecord) bool {
+	if rec.LimitCheck == limitCheckAwaiting || len(rec.Pending) == 0 {
+		return false
+	}
+	spec := rec.Pending[0]
+	if spec.Type != model.ActionTypeCreateDoc && spec.Type != model.ActionTypeSendMessage {
+		return false
+	}
+	draft, _ := spec.Params["draft"].(map[string]any)
+	text, _ := draft["text"].(string)
+	return text != ""
+}
+
+// reviseDraft 按修改意见改写待审阅的草稿并继续任务：改写后的草稿重新走审阅（再次发送预览卡片），确认后才发出
+func (s *ASRService) reviseDraft(ctx context.Context, rec model.TaskRecord, req model.ASRRequest) (model.ASRResponse, error) {
+	spec, err := s.llm.Redraft(ctx, rec.Pending[0], req.Text)
+	if err != nil {
+		// 草稿保持不变，任务仍待确认
+		return model.ASRResponse{TaskID: rec.ID, NeedConfirmation: true, Message: fmt.Sprintf("修改草稿失败: %v", err)}, err
+	}
+	spec.Confirmed = false
+	rec.Pending[0] = spec
+	// 修改期间任务可能已被确认或取消
+	swapped, err := s.tasks.Transition(ctx, rec.ID, model.TaskStatusAwaitingConfirmation, model.TaskStatusRunning)
+	if err != nil {
+		return model.ASRResponse{TaskID: rec.ID}, err
+	}
+	if !swapped {
+		return model.ASRResponse{TaskID: rec.ID}, fmt.Errorf("task %s: %w", rec.ID, ErrNotAwaitingConfirmation)
+	}
+	return s.continueTask(ctx, rec)
+}
@@ -0,0 +1,254 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// AliasBook 联系人分组表，按名字查找跨平台成员；同名分组可按租户分别配置
+type AliasBook struct {
+	aliases map[string][]model.Alias
+}
+
+// NewAliasBook 创建联系人分组表
+func NewAliasBook(aliases []model.Alias) *AliasBook {
+	m := make(map[string][]model.Alias, len(aliases))
+	for _, a := range aliases {
+		if a.Name != "" {
+			m[a.Name] = append(m[a.Name], a)
+		}
+	}
+	return &AliasBook{aliases: m}
+}
+
+// Lookup 按名字查找租户可用的分组
+func (b *AliasBook) Lookup(tenant, name string) (model.Alias, bool) {
+	if b == nil {
+		return model.Alias{}, false
+	}
+	for _, a := range b.aliases[name] {
+		if a.VisibleTo(tenant) {
+			return a, true
+		}
+	}
+	return model.Alias{}, false
+}
+
+// members 分组展开后的全部成员：成员 ID 为租户可用的另一分组名时递归展开，循环引用的分组只展开一次
+func (b *AliasBook) members(tenant string, alias model.Alias, seen map[string]bool) []model.AliasMember {
+	if seen[alias.Name] {
+		return nil
+	}
+	seen[alias.Name] = true
+	var out []model.AliasMember
+	for _, m := range alias.Members {
+		if nested, ok := b.Lookup(tenant, m.ID); ok {
+			out = append(out, b.members(tenant, nested, seen)...)
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// Members 租户可用的分组 name 展开（含嵌套分组）后的全部成员
+func (b *AliasBook) Members(tenant, name string) ([]model.AliasMember, bool) {
+	alias, ok := b.Lookup(tenant, name)
+	if !ok {
+		return nil, false
+	}
+	return b.members(tenant, alias, make(map[string]bool)), true
+}
+
+// expandTargets 展开 targets 中租户可用的分组名（含嵌套分组），按平台归类，跳过命中 exclude 的成员；非分组目标归入 defaultPlatform。
+// 返回的平台顺序与首次出现顺序一致，便于摘要稳定。
+func (b *AliasBook) expandTargets(tenant string, targets []string, defaultPlatform string, exclude []string) (platforms []string, byPlatform map[string][]string, expanded bool) {
+	byPlatform = make(map[string][]string)
+	add := func(platform, id string) {
+		if _, ok := byPlatform[platform]; !ok {
+			platforms = append(platforms, platform)
+		}
+		byPlatform[platform] = append(byPlatform[platform], id)
+	}
+	for _, t := range targets {
+		members, ok := b.Members(tenant, t)
+		if !ok {
+			add(defaultPlatform, t)
+			continue
+		}
+		expanded = true
+		for _, m := range members {
+			if m.ID == "" || memberExcluded(m, exclude) {
+				continue
+			}
+			platform := m.Platform
+			if platform == "" {
+				platform = defaultPlatform
+			}
+			add(platform, m.ID)
+		}
+	}
+	return platforms, byPlatform, expanded
+}
+
+// executeSendMessage 发送消息：先展开联系人分组，再按平台分发到对应执行器
+func (e *Executor) executeSendMessage(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	params := model.ParseSendMessageParams(spec.Params)
+	pinned, err := e.pinRecipients(ctx, spec, req)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	summary, err := e.sendMessageTo(ctx, model.ParseSendMessageParams(pinned.Params), pinned, req)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	// 用户说出的接收人（分组名、人名），用于组织回复
+	if len(params.Targets) > 0 {
+		if summary.Outputs == nil {
+			summary.Outputs = make(map[string]string)
+		}
+		summary.Outputs["recipients"] = strings.Join(params.Targets, "、")
+	}
+	return summary, nil
+}
+
+// platformContent 正文含占位符时换成按 platform 转义的版本（见 model.ParamContentByPlatform）；
+// 分组展开、平台改投后实际平台可能与规划时不同
+func platformContent(spec model.ActionSpec, platform string) model.ActionSpec {
+	byPlatform, _ := spec.Params[model.ParamContentByPlatform].(map[string]any)
+	if platform == "" {
+		platform = "feishu"
+	}
+	content, ok := byPlatform[platform]
+	if !ok {
+		return spec
+	}
+	params := make(map[string]any, len(spec.Params))
+	for k, v := range spec.Params {
+		params[k] = v
+	}
+	params["content"] = content
+	delete(params, model.ParamContentByPlatform)
+	spec.Params = params
+	return spec
+}
+
+// sendMessageTo 展开联系人分组，按平台分别发送后合并摘要
+func (e *Executor) sendMessageTo(ctx context.Context, params model.SendMessageParams, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	platforms, byPlatform, expanded := e.aliases.expandTargets(tenantOf(req), params.Targets, params.Platform, params.Exclude)
+	if !expanded {
+		return e.sendMessageOn(ctx, params.Platform, spec, req)
+	}
+	if len(platforms) == 0 {
+		return model.ActionSummary{}, fmt.Errorf("send_message: %w: 排除「%s」后没有接收方", model.ErrInvalidParams, strings.Join(params.Exclude, "、"))
+	}
+
+	var summaries []model.ActionSummary
+	for _, platform := range platforms {
+		sub := spec
+		sub.Params = make(map[string]any, len(spec.Params))
+		for k, v := range spec.Params {
+			sub.Params[k] = v
+		}
+		targets := make([]any, 0, len(byPlatform[platform]))
+		for _, t := range byPlatform[platform] {
+			targets = append(targets, t)
+		}
+		sub.Params["platform"] = platform
+		sub.Params["targets"] = targets
+		sub.Params["target_type"] = "batch"
+		summary, err := e.sendMessageOn(ctx, platform, sub, req)
+		if err != nil {
+			return model.ActionSummary{}, fmt.Errorf("send_message to %s: %w", platform, err)
+		}
+		summaries = append(summaries, summary)
+	}
+	return mergeSendSummaries(summaries), nil
+}
+
+// sendMessageOn 按平台上限处理超长正文后逐条发送，多条时合并为一个摘要；语音消息合成后整条发送
+func (e *Executor) sendMessageOn(ctx context.Context, platform string, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	spec = platformContent(spec, platform)
+	if model.ParseSendMessageParams(spec.Params).MessageType == "voice" {
+		return e.sendVoice(ctx, platform, spec, req)
+	}
+	specs, err := e.fitMessage(ctx, platform, spec, req)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	if len(specs) == 1 {
+		return e.dispatchMessage(ctx, platform, specs[0], req)
+	}
+	var last model.ActionSummary
+	var msgIDs, chatIDs []string
+	for i, part := range specs {
+		summary, err := e.dispatchMessage(ctx, platform, part, req)
+		if err != nil {
+			return model.ActionSummary{}, fmt.Errorf("send part %d/%d: %w", i+1, len(specs), err)
+		}
+		if id := summary.Outputs["message_ids"]; id != "" {
+			msgIDs = append(msgIDs, id)
+			chatIDs = append(chatIDs, summary.Outputs["chat_ids"])
+		}
+		last = summary
+	}
+	if len(msgIDs) > 0 {
+		last.Outputs["message_ids"] = strings.Join(msgIDs, ",")
+		last.Outputs["chat_ids"] = strings.Join(chatIDs, ",")
+	}
+	note := fmt.Sprintf("内容过长，分 %d 条发送", len(specs))
+	if last.Note != "" {
+		note = last.Note + "；" + note
+	}
+	last.Note = note
+	return last, nil
+}
+
+// dispatchMessage 按 platform 路由到对应 app 执行器
+func (e *Executor) dispatchMessage(ctx context.Context, platform string, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	switch platform {
+	case "feishu":
+		return e.feishu.ExecuteSendMessage(ctx, spec, req)
+	case "slack":
+		return e.slack.ExecuteSendMessage(ctx, spec, req)
+	case "discord":
+		return e.discord.ExecuteSendMessage(ctx, spec, req)
+	default:
+		return model.ActionSummary{}, fmt.Errorf("send_message: unsupported platform: %s", platform)
+	}
+}
+
+// mergeSendSummaries 合并多个平台的发送摘要
+func mergeSendSummaries(summaries []model.ActionSummary) model.ActionSummary {
+	if len(summaries) == 1 {
+		return summaries[0]
+	}
+	var targets, notes []string
+	outputs := make(map[string]string)
+	for _, s := range summaries {
+		targets = append(targets, fmt.Sprintf("%s: %s", s.Type, s.Target))
+		if s.Note != "" {
+			notes = append(notes, fmt.Sprintf("%s: %s", s.Type, s.Note))
+		}
+		// 先发送的平台优先，如 {{message_id}} 指向第一条成功的消息
+		for k, v := range s.Outputs {
+			if _, ok := outputs[k]; !ok {
+				outputs[k] = v
+			}
+		}
+		// 按平台保留各自的消息 ID，纠正时逐平台撤回
+		if ids := s.Outputs["message_ids"]; ids != "" {
+			outputs[s.Type+".message_ids"] = ids
+			outputs[s.Type+".chat_ids"] = s.Outputs["chat_ids"]
+		}
+	}
+	return model.ActionSummary{
+		Type:    "message",
+		Target:  strings.Join(targets, "; "),
+		Note:    strings.Join(notes, "; "),
+		Outputs: outputs,
+	}
+}
@@ -0,0 +1,80 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"sayso-agent/internal/client/discord"
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/client/slack"
+	"sayso-agent/internal/model"
+)
+
+// sendRecorder 模拟飞书、Slack、Discord 的发消息接口，按主机记下请求体
+type sendRecorder struct {
+	mu     sync.Mutex
+	bodies map[string][]string
+}
+
+func (s *sendRecorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	var b []byte
+	if r.Body != nil {
+		b, _ = io.ReadAll(r.Body)
+	}
+	body := `{"code":0,"ok":true,"id":"1","ts":"1.0","channel":{"id":"D1"},"data":{"message_id":"om_1"}}`
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/tenant_access_token/internal"):
+		body = `{"code":0,"tenant_access_token":"t-test","expire":7200}`
+	case strings.HasSuffix(r.URL.Path, "/users/@me/channels"):
+		body = `{"id":"dm1"}`
+	default:
+		s.mu.Lock()
+		s.bodies[r.URL.Host] = append(s.bodies[r.URL.Host], string(b))
+		s.mu.Unlock()
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header), Request: r}, nil
+}
+
+func TestSendMessageEscapesPerPlatformAfterAliasExpansion(t *testing.T) {
+	rec := &sendRecorder{bodies: make(map[string][]string)}
+	feishuCfg := feishu.Config{AppID: "cli_test", AppSecret: "secret", Region: "feishu", Enabled: true, Transport: rec}
+	slackCfg := slack.Config{BotToken: "xoxb-test", Enabled: true, Transport: rec}
+	discordCfg := discord.Config{BotToken: "bot-test", Enabled: true, Transport: rec}
+	e := &Executor{
+		aliases: NewAliasBook([]model.Alias{{Name: "项目组", Members: []model.AliasMember{
+			{Platform: "feishu", ID: "ou_pm"},
+			{Platform: "slack", ID: "C0OPS"},
+			{Platform: "discord", ID: "42"},
+		}}}),
+		feishu:  &FeishuExecutor{Client: feishu.NewClient(feishuCfg), Cfg: feishuCfg},
+		slack:   &SlackExecutor{Client: slack.NewClient(slackCfg), Cfg: slackCfg},
+		discord: &DiscordExecutor{Client: discord.NewClient(discordCfg), Cfg: discordCfg},
+	}
+	// 占位符替换时平台为空，按飞书转义 content；content_by_platform 为各平台分别转义的版本（见 service.applyPlaceholders）
+	spec := model.ActionSpec{Type: model.ActionTypeSendMessage, Params: map[string]any{
+		"message_type": "text",
+		"targets":      []any{"项目组"},
+		"content":      map[string]any{"text": "标题：<!channel> @everyone"},
+		model.ParamContentByPlatform: map[string]any{
+			"feishu":  map[string]any{"text": "标题：<!channel> @everyone"},
+			"slack":   map[string]any{"text": "标题：&lt;!channel&gt; @everyone"},
+			"discord": map[string]any{"text": "标题：<!channel> @​everyone"},
+		},
+	}}
+
+	if _, err := e.executeSendMessage(context.Background(), spec, nil); err != nil {
+		t.Fatalf("executeSendMessage: %v", err)
+	}
+	slackBodies := strings.Join(rec.bodies["slack.com"], "\n")
+	if slackBodies == "" || strings.Contains(slackBodies, "<!channel>") || !strings.Contains(slackBodies, `lt;!channel`) {
+		t.Fatalf("slack bodies = %s, want escaped <!channel>", slackBodies)
+	}
+	discordBodies := strings.Join(rec.bodies["discord.com"], "\n")
+	if discordBodies == "" || strings.Contains(discordBodies, " @everyone") {
+		t.Fatalf("discord bodies = %s, want neutralised @everyone", discordBodies)
+	}
+}
@@ -0,0 +1,24 @@
+package executor
+
+import (
+	"context"
+	"log"
+)
+
+// ArtifactStore 保存导出文件、合成的语音、图表等产物并返回临时链接（由 store.Artifacts 实现）
+type ArtifactStore interface {
+	Save(ctx context.Context, kind, name, contentType string, data []byte) (string, error)
+}
+
+// saveArtifact 保存产物并返回链接，链接写入动作摘要与回复；未配置对象存储时返回空，保存失败只记日志，不影响动作结果
+func (e *Executor) saveArtifact(ctx context.Context, kind, name, contentType string, data []byte) string {
+	if e.artifacts == nil || len(data) == 0 {
+		return ""
+	}
+	link, err := e.artifacts.Save(ctx, kind, name, contentType, data)
+	if err != nil {
+		log.Printf("save %s artifact %s: %v", kind, name, err)
+		return ""
+	}
+	return link
+}
@@ -0,0 +1,216 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"sayso-agent/internal/model"
+)
+
+const (
+	// bulkMaxItems 单次批量创建的文档数上限
+	bulkMaxItems = 50
+	// bulkParallel 批量创建的并发数，避免触发飞书接口限流
+	bulkParallel = 4
+	// bulkItemPlaceholder 标题、正文与模板中表示当前项的占位符
+	bulkItemPlaceholder = "{{item}}"
+)
+
+// ExecuteBulkCreateDoc 按列表批量创建文档（"为每个子项目建一个立项文档"），返回标题与链接汇总表
+// params: source(items|contacts|table), items, table, column, title（含 {{item}}）, template（模板文档链接或名称）,
+// content, folder_name, folder_token
+func (e *FeishuExecutor) ExecuteBulkCreateDoc(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	items, err := e.bulkItems(ctx, token, spec, req)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_bulk_create_doc: %w", err)
+	}
+
+	titlePattern, _ := spec.Params["title"].(string)
+	if !strings.Contains(titlePattern, bulkItemPlaceholder) {
+		titlePattern = strings.TrimSpace(bulkItemPlaceholder + titlePattern)
+	}
+	content, _ := spec.Params["content"].(string)
+	if tmpl, _ := spec.Params["template"].(string); tmpl != "" {
+		if content, err = e.templateContent(ctx, token, tmpl); err != nil {
+			return model.ActionSummary{}, fmt.Errorf("feishu_bulk_create_doc: template %q: %w", tmpl, err)
+		}
+	}
+
+	// 目录只解析一次（按第一份文档的标题匹配），各文档存放在同一目录
+	folderToken, _ := spec.Params["folder_token"].(string)
+	folderName, _ := spec.Params["folder_name"].(string)
+	if folderToken == "" {
+		folderToken, folderName = e.resolveDocFolder(ctx, token, tenantOf(req), "", folderName, strings.ReplaceAll(titlePattern, bulkItemPlaceholder, items[0]))
+	}
+
+	summaries := make([]model.ActionSummary, len(items))
+	errs := make([]error, len(items))
+	sem := make(chan struct{}, bulkParallel)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		go func(i int, item string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if errs[i] = ctx.Err(); errs[i] != nil {
+				return
+			}
+
+			params := map[string]any{
+				"title":        strings.ReplaceAll(titlePattern, bulkItemPlaceholder, item),
+				"content":      strings.ReplaceAll(content, bulkItemPlaceholder, item),
+				"folder_token": folderToken,
+				"on_duplicate": spec.Params["on_duplicate"],
+			}
+			summaries[i], errs[i] = e.ExecuteCreateDoc(ctx, model.ActionSpec{Type: model.ActionTypeCreateDoc, Params: params, Confirmed: spec.Confirmed}, req)
+			if errors.Is(errs[i], model.ErrConfirmationRequired) {
+				errs[i] = errors.New(summaries[i].Note)
+			}
+		}(i, item)
+	}
+	wg.Wait()
+
+	return bulkSummary(items, summaries, errs, folderName)
+}
+
+// bulkSummary 汇总各文档的创建结果：全部失败时返回错误，部分失败记为未确认生效
+func bulkSummary(items []string, summaries []model.ActionSummary, errs []error, folderName string) (model.ActionSummary, error) {
+	var titles, urls, ids, rows, failed []string
+	rows = append(rows, "| 标题 | 链接 |", "| --- | --- |")
+	for i, s := range summaries {
+		if errs[i] != nil {
+			failed = append(failed, fmt.Sprintf("%s（%v）", items[i], errs[i]))
+			continue
+		}
+		titles = append(titles, s.Target)
+		urls = append(urls, s.URL)
+		ids = append(ids, s.ID)
+		rows = append(rows, fmt.Sprintf("| %s | %s |", s.Target, s.URL))
+	}
+	if len(titles) == 0 {
+		return model.ActionSummary{}, fmt.Errorf("feishu_bulk_create_doc: all %d documents failed: %s", len(items), strings.Join(failed, "；"))
+	}
+
+	summary := model.ActionSummary{Type: "feishu_bulk_doc", Target: fmt.Sprintf("%d", len(titles))}
+	summary.Outputs = map[string]string{
+		"count":    summary.Target,
+		"titles":   strings.Join(titles, ","),
+		"doc_urls": strings.Join(urls, ","),
+		"doc_ids":  strings.Join(ids, ","),
+		"table":    strings.Join(rows, "\n"),
+	}
+	if folderName != "" {
+		summary.Outputs["folder_name"] = folderName
+	}
+	summary.Note = summary.Outputs["table"]
+	if len(failed) > 0 {
+		summary.Outputs["failed"] = strings.Join(failed, "；")
+		summary.Unverified = fmt.Sprintf("%d 份文档创建失败：%s", len(failed), summary.Outputs["failed"])
+	}
+	return summary, nil
+}
+
+// bulkItems 批量创建的列表：用户列出的项、请求附带的联系人或表格中的一列，去重后最多 bulkMaxItems 项
+func (e *FeishuExecutor) bulkItems(ctx context.Context, token string, spec model.ActionSpec, req *model.ASRRequest) ([]string, error) {
+	var raw []string
+	source, _ := spec.Params["source"].(string)
+	switch source {
+	case "items", "":
+		raw = model.StringList(spec.Params["items"])
+	case "contacts":
+		if req != nil {
+			for _, c := range req.Contacts {
+				raw = append(raw, c.Name)
+			}
+		}
+	case "table":
+		tableParam, _ := spec.Params["table"].(string)
+		column, _ := spec.Params["column"].(string)
+		table, err := e.findTable(tableParam)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", model.ErrInvalidParams, err)
+		}
+		if raw, err = e.tableColumn(ctx, token, table.URL, column); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported source: %s", model.ErrInvalidParams, source)
+	}
+
+	seen := make(map[string]bool)
+	var items []string
+	for _, item := range raw {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: empty list (source=%s)", model.ErrInvalidParams, source)
+	}
+	if len(items) > bulkMaxItems {
+		return nil, fmt.Errorf("%w: too many items: %d > %d", model.ErrLimitExceeded, len(items), bulkMaxItems)
+	}
+	return items, nil
+}
+
+// tableColumn 读取表格中表头为 column 的一列；column 为空时取第一列
+func (e *FeishuExecutor) tableColumn(ctx context.Context, token, tableURL, column string) ([]string, error) {
+	data, err := e.readTable(ctx, token, tableURL)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(data, "\n")
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	index := 0
+	if column != "" {
+		index = -1
+		for i, h := range strings.Split(lines[0], "\t") {
+			if strings.TrimSpace(h) == column {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("%w: column %q not found", model.ErrInvalidParams, column)
+		}
+	}
+	var values []string
+	for _, line := range lines[1:] {
+		if cells := strings.Split(line, "\t"); index < len(cells) {
+			values = append(values, cells[index])
+		}
+	}
+	return values, nil
+}
+
+// templateContent 读取模板文档的正文；模板可用链接或文档名称指定
+func (e *FeishuExecutor) templateContent(ctx context.Context, token, template string) (string, error) {
+	params := map[string]any{"doc_name": template}
+	if strings.Contains(template, "/") {
+		params = map[string]any{"doc_url": template}
+	}
+	doc, err := e.resolveDoc(ctx, token, model.ActionSpec{Params: params})
+	if err != nil {
+		return "", err
+	}
+	if doc.Type != "docx" {
+		return "", fmt.Errorf("%w: template must be a docx document, got %s", model.ErrInvalidParams, doc.Type)
+	}
+	return e.Client.GetDocRawContent(ctx, token, doc.Token)
+}
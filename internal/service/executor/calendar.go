@@ -0,0 +1,268 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// 找会议时间的范围：工作日 9:00-18:00，按半小时对齐
+const (
+	meetingSlotStep    = 30 * time.Minute
+	workdayStartHour   = 9
+	workdayEndHour     = 18
+	scheduleSearchDays = 5 // 未指定日期范围时向后查找的天数
+	maxSlotSuggestions = 3
+)
+
+// attendee 日程参与人
+type attendee struct {
+	ID     string
+	IDType string // open_id | user_id
+	Name   string
+}
+
+// slotCheck 候选时段及其中有冲突的参与人
+type slotCheck struct {
+	Slot feishu.TimeRange
+	Busy []string
+}
+
+// ExecuteScheduleMeeting 查询参与人（含请求人）忙闲，在都空闲的时间创建日程；
+// 指定时间有冲突或找不到都空闲的时间时，给出备选时间并返回 ErrConfirmationRequired，确认后按第一个备选时间创建
+// params: title, attendees, duration_minutes, start_time("2006-01-02 15:04"，用户指定时间时), date_from, date_to("2006-01-02"), description
+func (e *FeishuExecutor) ExecuteScheduleMeeting(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	title, _ := spec.Params["title"].(string)
+	if title == "" {
+		title = "会议"
+	}
+	duration := 30 * time.Minute
+	if n, ok := spec.Params["duration_minutes"].(float64); ok && n > 0 {
+		duration = time.Duration(n) * time.Minute
+	}
+	now := time.Now()
+	var requested *feishu.TimeRange
+	if s, _ := spec.Params["start_time"].(string); s != "" {
+		start, err := time.ParseInLocation("2006-01-02 15:04", s, now.Location())
+		if err != nil {
+			return model.ActionSummary{}, fmt.Errorf("feishu_schedule_meeting: %w: invalid start_time %q", model.ErrInvalidParams, s)
+		}
+		requested = &feishu.TimeRange{Start: start, End: start.Add(duration)}
+	}
+	from, to := scheduleWindow(spec, requested, now)
+
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	attendees, err := e.resolveAttendees(ctx, token, spec, req)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_schedule_meeting: %w", err)
+	}
+	busy := make(map[string][]feishu.TimeRange)
+	for _, a := range attendees {
+		ranges, err := e.Client.FreeBusy(ctx, token, a.ID, a.IDType, from, to)
+		if err != nil {
+			return model.ActionSummary{}, fmt.Errorf("freebusy of %s: %w", a.Name, err)
+		}
+		busy[a.Name] = ranges
+	}
+
+	var chosen *feishu.TimeRange
+	var conflict []string
+	if requested != nil {
+		conflict = busyAttendees(*requested, attendees, busy)
+		if len(conflict) == 0 {
+			chosen = requested
+		}
+	}
+	var suggestions []slotCheck
+	if chosen == nil {
+		suggestions = suggestSlots(candidateSlots(from, to, duration, now), attendees, busy)
+		if len(suggestions) == 0 {
+			return model.ActionSummary{}, fmt.Errorf("feishu_schedule_meeting: %w: no working hours between %s and %s", model.ErrInvalidParams, from.Format("01-02"), to.Format("01-02"))
+		}
+		if requested == nil && len(suggestions[0].Busy) == 0 {
+			chosen = &suggestions[0].Slot
+		}
+	}
+
+	summary := model.ActionSummary{Type: "feishu_calendar_event", Target: title}
+	if chosen == nil && !spec.Confirmed {
+		var note []string
+		if requested != nil {
+			note = append(note, fmt.Sprintf("%s 在 %s 有安排", strings.Join(conflict, "、"), formatSlot(*requested)))
+		} else {
+			note = append(note, fmt.Sprintf("%s 至 %s 没有所有人都空闲的时间", from.Format("01-02"), to.Format("01-02")))
+		}
+		var options []string
+		for _, s := range suggestions {
+			option := formatSlot(s.Slot)
+			if len(s.Busy) == 0 {
+				option += "（都有空）"
+			} else {
+				option += "（" + strings.Join(s.Busy, "、") + " 有冲突）"
+			}
+			options = append(options, option)
+		}
+		note = append(note, "可选时间："+strings.Join(options, "；"), "确认则约在 "+formatSlot(suggestions[0].Slot)+"，也可以换个时间重新说")
+		summary.Note = strings.Join(note, "。")
+		return summary, model.ErrConfirmationRequired
+	}
+	if chosen == nil {
+		chosen = &suggestions[0].Slot
+	}
+
+	calendarID, err := e.Client.PrimaryCalendarID(ctx, token)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	description, _ := spec.Params["description"].(string)
+	eventID, link, err := e.Client.CreateEvent(ctx, token, calendarID, feishu.CalendarEvent{Summary: title, Description: description, Start: chosen.Start, End: chosen.End})
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	byType := make(map[string][]string)
+	var names []string
+	for _, a := range attendees {
+		byType[a.IDType] = append(byType[a.IDType], a.ID)
+		names = append(names, a.Name)
+	}
+	var failed []string
+	for idType, ids := range byType {
+		if err := e.Client.AddEventAttendees(ctx, token, calendarID, eventID, idType, ids); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+
+	summary.ID, summary.URL = eventID, link
+	summary.Note = fmt.Sprintf("已约在 %s，参与人：%s", formatSlot(*chosen), strings.Join(names, "、"))
+	if len(failed) > 0 {
+		summary.Note += "；添加参与人失败: " + strings.Join(failed, "；")
+	}
+	summary.Outputs = map[string]string{"event_id": eventID, "event_url": link, "start_time": chosen.Start.Format("2006-01-02 15:04")}
+	return summary, nil
+}
+
+// resolveAttendees 请求人（有 open_id 时）与 attendees 参数中的人，按 ID 去重
+func (e *FeishuExecutor) resolveAttendees(ctx context.Context, token string, spec model.ActionSpec, req *model.ASRRequest) ([]attendee, error) {
+	var result []attendee
+	seen := make(map[string]bool)
+	if requester := requesterID(req); isOpenID(requester) {
+		name := authorOf(req)
+		if name == "" {
+			name = "你"
+		}
+		result = append(result, attendee{ID: requester, IDType: "open_id", Name: name})
+		seen[requester] = true
+	}
+	names, _ := spec.Params["attendees"].([]any)
+	for _, n := range names {
+		who, _ := n.(string)
+		if who == "" {
+			continue
+		}
+		member, name, err := e.resolveMember(ctx, token, who)
+		if err != nil {
+			return nil, err
+		}
+		a := attendee{ID: member.MemberID, Name: name}
+		switch member.MemberType {
+		case "openid":
+			a.IDType = "open_id"
+		case "userid":
+			a.IDType = "user_id"
+		default:
+			return nil, fmt.Errorf("%w: cannot check calendar of %s", model.ErrInvalidParams, who)
+		}
+		if !seen[a.ID] {
+			seen[a.ID] = true
+			result = append(result, a)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w: no attendees", model.ErrInvalidParams)
+	}
+	return result, nil
+}
+
+// scheduleWindow 查找范围：date_from/date_to（含当天），指定了时间时从该天开始，默认从今天起 scheduleSearchDays 天
+func scheduleWindow(spec model.ActionSpec, requested *feishu.TimeRange, now time.Time) (time.Time, time.Time) {
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()) }
+	from := day(now)
+	if requested != nil {
+		from = day(requested.Start)
+	}
+	if s, _ := spec.Params["date_from"].(string); s != "" {
+		if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+			from = t
+		}
+	}
+	to := from.AddDate(0, 0, scheduleSearchDays)
+	if s, _ := spec.Params["date_to"].(string); s != "" {
+		if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil && !t.Before(from) {
+			to = t.AddDate(0, 0, 1)
+		}
+	}
+	if from.Before(now) {
+		from = now
+	}
+	return from, to
+}
+
+// candidateSlots 范围内工作日工作时间的候选时段，按时间先后
+func candidateSlots(from, to time.Time, duration time.Duration, now time.Time) []feishu.TimeRange {
+	var slots []feishu.TimeRange
+	for d := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location()); d.Before(to); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			continue
+		}
+		dayEnd := d.Add(workdayEndHour * time.Hour)
+		for start := d.Add(workdayStartHour * time.Hour); !start.Add(duration).After(dayEnd); start = start.Add(meetingSlotStep) {
+			if start.Before(from) || start.Before(now) || !start.Before(to) {
+				continue
+			}
+			slots = append(slots, feishu.TimeRange{Start: start, End: start.Add(duration)})
+		}
+	}
+	return slots
+}
+
+// busyAttendees 在时段内有安排的参与人
+func busyAttendees(slot feishu.TimeRange, attendees []attendee, busy map[string][]feishu.TimeRange) []string {
+	var names []string
+	for _, a := range attendees {
+		for _, b := range busy[a.Name] {
+			if b.Start.Before(slot.End) && slot.Start.Before(b.End) {
+				names = append(names, a.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// suggestSlots 优先返回所有人都空闲的时段，没有时返回冲突人数最少的时段，最多 maxSlotSuggestions 个
+func suggestSlots(slots []feishu.TimeRange, attendees []attendee, busy map[string][]feishu.TimeRange) []slotCheck {
+	checks := make([]slotCheck, 0, len(slots))
+	for _, s := range slots {
+		checks = append(checks, slotCheck{Slot: s, Busy: busyAttendees(s, attendees, busy)})
+	}
+	sort.SliceStable(checks, func(i, j int) bool { return len(checks[i].Busy) < len(checks[j].Busy) })
+	if len(checks) > maxSlotSuggestions {
+		checks = checks[:maxSlotSuggestions]
+	}
+	return checks
+}
+
+func formatSlot(s feishu.TimeRange) string {
+	return fmt.Sprintf("%s %s-%s", s.Start.Format("01-02"), s.Start.Format("15:04"), s.End.Format("15:04"))
+}
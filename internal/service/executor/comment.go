@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// ExecuteCommentDoc 在文档上添加评论；指定 quote 时在评论中引用原文（开放平台不支持创建划词评论）
+// params: doc_url 或 doc_name, content, quote
+func (e *FeishuExecutor) ExecuteCommentDoc(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	content, _ := spec.Params["content"].(string)
+	if strings.TrimSpace(content) == "" {
+		return model.ActionSummary{}, fmt.Errorf("feishu_comment_doc: %w: content is required", model.ErrInvalidParams)
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	doc, err := e.resolveDoc(ctx, token, spec)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_comment_doc: %w", err)
+	}
+
+	var note string
+	if quote, _ := spec.Params["quote"].(string); quote != "" {
+		matched := quote
+		if doc.Type == "docx" {
+			if raw, err := e.Client.GetDocRawContent(ctx, token, doc.Token); err == nil {
+				matched = matchQuote(raw, quote)
+			}
+		}
+		if matched != "" {
+			content = fmt.Sprintf("「%s」%s", matched, content)
+		} else {
+			note = "文中未找到「" + quote + "」，已作为全文评论"
+		}
+	}
+	commentID, err := e.Client.CreateComment(ctx, token, doc.Token, doc.Type, content)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+
+	target := doc.Name
+	if target == "" {
+		target = doc.URL
+	}
+	summary := model.ActionSummary{Type: "feishu_comment", Target: target, ID: commentID, URL: doc.URL, Note: note}
+	summary.Outputs = map[string]string{"comment_id": commentID, "doc_id": doc.Token, "doc_url": doc.URL}
+	return summary, nil
+}
+
+// quoteLimit 评论中引用原文的最大字符数
+const quoteLimit = 50
+
+// matchQuote 在文档正文中查找 quote 所在的行，返回用于引用的原文；找不到时返回空
+func matchQuote(raw, quote string) string {
+	for _, line := range strings.Split(raw, "\n") {
+		if !strings.Contains(line, quote) {
+			continue
+		}
+		line = strings.TrimSpace(line)
+		if r := []rune(line); len(r) > quoteLimit {
+			return quote
+		}
+		return line
+	}
+	return ""
+}
@@ -0,0 +1,184 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// 联系人固定：请求的 Contacts 给出了名字对应的飞书 ID 时，发消息一律使用这些 ID，不采用大模型猜测的目标。
+// 严格模式下只发给 Contacts 中的联系人、联系人分组或通讯录中姓名完全一致的用户，拒绝其他目标（含大模型编造的 ID）；
+// 只有飞书能按通讯录核实，其他平台的目标须出现在 Contacts 中或已由身份映射、平台查找核实（见 PlatformFallbackHook）
+
+// selfNames 指代请求人自己的目标，固定为 Context 中的 feishu_open_id
+var selfNames = []string{"我", "自己", "我自己", "me", "myself"}
+
+// currentChatNames 指代请求所在群聊的目标（"发到这个群"），固定为 Context 中的 feishu_chat_id
+var currentChatNames = []string{"这个群", "这个群聊", "本群", "此群", "当前群", "群里", "this group", "this chat", "here"}
+
+// pinnedContacts 请求中明确给出的名字与飞书 ID
+type pinnedContacts struct {
+	byName map[string]string // 规范化名字 -> 发送用 ID（open_id 优先，其次邮箱）
+	known  map[string]bool   // Contacts 中出现过的名字与 ID
+}
+
+// pinContacts 从请求的 Contacts 与 Context 中收集联系人
+func pinContacts(req *model.ASRRequest) pinnedContacts {
+	p := pinnedContacts{byName: make(map[string]string), known: make(map[string]bool)}
+	if req == nil {
+		return p
+	}
+	for _, c := range req.Contacts {
+		name := normalizeContact(c.Name)
+		for _, id := range []string{c.OpenID, c.UserID, c.Email, c.Phone} {
+			if id != "" {
+				p.known[normalizeContact(id)] = true
+			}
+		}
+		if name == "" {
+			continue
+		}
+		p.known[name] = true
+		if id := firstNonEmpty(c.OpenID, c.Email); id != "" {
+			p.byName[name] = id
+		}
+	}
+	if id := req.Context["feishu_open_id"]; id != "" {
+		p.known[normalizeContact(id)] = true
+		for _, name := range selfNames {
+			p.byName[name] = id
+		}
+	}
+	if id := req.Context["slack_channel"]; id != "" {
+		p.known[normalizeContact(id)] = true
+	}
+	if id := req.Context["feishu_chat_id"]; id != "" {
+		p.known[normalizeContact(id)] = true
+		for _, name := range currentChatNames {
+			p.byName[name] = id
+		}
+	}
+	return p
+}
+
+// pin 返回名字对应的固定 ID，没有时原样返回
+func (p pinnedContacts) pin(target string) string {
+	if id, ok := p.byName[normalizeContact(target)]; ok {
+		return id
+	}
+	return target
+}
+
+// strictRecipients 本次请求是否使用严格模式：配置开启，或 Context["strict_recipients"] 为 "true"
+func (e *Executor) strictRecipients(req *model.ASRRequest) bool {
+	return e.feishu.Cfg.StrictRecipients || (req != nil && req.Context["strict_recipients"] == "true")
+}
+
+// strictKey 严格模式标记，由 Execute 写入，发送时按姓名查找用户须完全一致
+type strictKey struct{}
+
+func withStrictRecipients(ctx context.Context) context.Context {
+	return context.WithValue(ctx, strictKey{}, true)
+}
+
+func strictFrom(ctx context.Context) bool {
+	strict, _ := ctx.Value(strictKey{}).(bool)
+	return strict
+}
+
+// pinRecipients 把飞书消息目标中的联系人名字替换为固定 ID；严格模式下拒绝不在 Contacts 中的 ID，
+// 其余名字交给通讯录按姓名精确查找（见 FeishuExecutor.sendToTarget）。其他平台不替换，严格模式下按 checkUnverifiable 拒绝
+func (e *Executor) pinRecipients(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSpec, error) {
+	params := model.ParseSendMessageParams(spec.Params)
+	contacts := pinContacts(req)
+	strict := e.strictRecipients(req)
+	if params.Platform != "" && params.Platform != "feishu" {
+		if strict {
+			return spec, e.checkUnverifiable(ctx, params, contacts, req)
+		}
+		return spec, nil
+	}
+	targets := make([]any, 0, len(params.Targets))
+	changed := false
+	for _, t := range params.Targets {
+		if _, ok := e.aliases.Lookup(tenantOf(req), t); ok {
+			targets = append(targets, t)
+			continue
+		}
+		pinned := contacts.pin(t)
+		if pinned != t {
+			changed = true
+		} else if strict && looksLikeID(t) && !contacts.known[normalizeContact(t)] {
+			return spec, fmt.Errorf("send_message: %w: %s 不在请求的联系人中", model.ErrRecipientNotAllowed, t)
+		}
+		targets = append(targets, pinned)
+	}
+	if !changed {
+		return spec, nil
+	}
+	out := spec
+	out.Params = make(map[string]any, len(spec.Params))
+	for k, v := range spec.Params {
+		out.Params[k] = v
+	}
+	out.Params["targets"] = targets
+	return out, nil
+}
+
+// checkUnverifiable 非飞书平台没有可按姓名精确查找的通讯录，严格模式下目标须是联系人分组、出现在 Contacts 中
+// 或已核实，否则拒绝
+func (e *Executor) checkUnverifiable(ctx context.Context, params model.SendMessageParams, contacts pinnedContacts, req *model.ASRRequest) error {
+	verified := verifiedFrom(ctx)
+	for _, t := range params.Targets {
+		if _, ok := e.aliases.Lookup(tenantOf(req), t); ok {
+			continue
+		}
+		if !contacts.known[normalizeContact(t)] && !verified[t] {
+			return fmt.Errorf("send_message: %w: %s 不在请求的联系人中，%s 无法按通讯录核实", model.ErrRecipientNotAllowed, t, params.Platform)
+		}
+	}
+	return nil
+}
+
+// verifiedKey 身份映射或平台查找核实过的发送目标，由 PlatformFallbackHook 写入
+type verifiedKey struct{}
+
+func withVerified(ctx context.Context, targets []string) context.Context {
+	if len(targets) == 0 {
+		return ctx
+	}
+	prev := verifiedFrom(ctx)
+	verified := make(map[string]bool, len(prev)+len(targets))
+	for t := range prev {
+		verified[t] = true
+	}
+	for _, t := range targets {
+		verified[t] = true
+	}
+	return context.WithValue(ctx, verifiedKey{}, verified)
+}
+
+func verifiedFrom(ctx context.Context) map[string]bool {
+	verified, _ := ctx.Value(verifiedKey{}).(map[string]bool)
+	return verified
+}
+
+// looksLikeID 目标是飞书 ID 或邮箱而不是名字
+func looksLikeID(target string) bool {
+	return isOpenID(target) || isChatID(target) || strings.HasPrefix(target, "on_") || isEmail(target)
+}
+
+func normalizeContact(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
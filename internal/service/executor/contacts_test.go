@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+func TestPinRecipientsStrictOnEveryPlatform(t *testing.T) {
+	e := &Executor{
+		aliases: NewAliasBook([]model.Alias{{Name: "值班", Members: []model.AliasMember{{Platform: "slack", ID: "U0ONCALL"}}}}),
+		feishu:  &FeishuExecutor{Cfg: feishu.Config{StrictRecipients: true}},
+	}
+	req := &model.ASRRequest{
+		Contacts: []model.Contact{{Name: "张三", OpenID: "ou_zhang", Phone: "+8613800138000"}},
+		Context:  map[string]string{"slack_channel": "C0HERE"},
+	}
+	verified := withVerified(context.Background(), []string{"U0MAPPED"})
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		platform string
+		target   string
+		strict   bool
+		wantErr  bool
+	}{
+		{name: "slack id invented by llm", platform: "slack", target: "U0GUESS", strict: true, wantErr: true},
+		{name: "slack name not in contacts", platform: "slack", target: "李四", strict: true, wantErr: true},
+		{name: "discord id invented by llm", platform: "discord", target: "123456789", strict: true, wantErr: true},
+		{name: "sms number not in contacts", platform: "sms", target: "+8613900139000", strict: true, wantErr: true},
+		{name: "slack contact name", platform: "slack", target: "张三", strict: true},
+		{name: "slack current channel", platform: "slack", target: "C0HERE", strict: true},
+		{name: "slack alias", platform: "slack", target: "值班", strict: true},
+		{name: "slack id from identity mapping", ctx: verified, platform: "slack", target: "U0MAPPED", strict: true},
+		{name: "sms contact phone", platform: "sms", target: "+8613800138000", strict: true},
+		{name: "feishu id invented by llm", platform: "feishu", target: "ou_guess", strict: true, wantErr: true},
+		{name: "slack not strict", platform: "slack", target: "U0GUESS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e.feishu.Cfg.StrictRecipients = tt.strict
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			spec := model.ActionSpec{Type: model.ActionTypeSendMessage, Params: map[string]any{"platform": tt.platform, "targets": []any{tt.target}}}
+			_, err := e.pinRecipients(ctx, spec, req)
+			if tt.wantErr != errors.Is(err, model.ErrRecipientNotAllowed) {
+				t.Fatalf("pinRecipients(%s %s) err = %v, wantErr %v", tt.platform, tt.target, err, tt.wantErr)
+			}
+		})
+	}
+}
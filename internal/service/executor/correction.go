@@ -0,0 +1,144 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// 纠正补偿：用户说"不对，我是说……"后，撤回发错的消息、修改标题写错的文档。
+// 这两种动作只由纠正流程生成，参数来自原动作的输出
+
+// executeRecallMessage 撤回消息，按 platform 路由
+// params: platform(feishu|slack|discord), message_ids（逗号分隔，Slack 为消息 ts）, chat_ids（与 message_ids 一一对应，Slack、Discord 必填）, recipients（用于回复）
+func (e *Executor) executeRecallMessage(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	switch platform, _ := spec.Params["platform"].(string); platform {
+	case "slack":
+		return e.slack.ExecuteRecallMessage(ctx, spec, req)
+	case "discord":
+		return e.discord.ExecuteRecallMessage(ctx, spec, req)
+	}
+	return e.feishu.ExecuteRecallMessage(ctx, spec, req)
+}
+
+// recallParams 解析撤回参数；chat_ids 可缺省（飞书不需要）
+func recallParams(spec model.ActionSpec) (msgIDs, chatIDs []string, err error) {
+	ids, _ := spec.Params["message_ids"].(string)
+	chats, _ := spec.Params["chat_ids"].(string)
+	for _, id := range strings.Split(ids, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			msgIDs = append(msgIDs, id)
+		}
+	}
+	if len(msgIDs) == 0 {
+		return nil, nil, fmt.Errorf("recall_message: %w: message_ids is required", model.ErrInvalidParams)
+	}
+	if chats != "" {
+		chatIDs = strings.Split(chats, ",")
+	}
+	return msgIDs, chatIDs, nil
+}
+
+// recallSummary 撤回结果摘要；部分失败时记在 Unverified，不算整体失败
+func recallSummary(typ string, spec model.ActionSpec, recalled []string, failed []string) model.ActionSummary {
+	recipients, _ := spec.Params["recipients"].(string)
+	summary := model.ActionSummary{Type: typ, Target: recipients}
+	summary.Outputs = map[string]string{"message_ids": strings.Join(recalled, ",")}
+	if recipients != "" {
+		summary.Outputs["recipients"] = recipients
+	}
+	if len(failed) > 0 {
+		summary.Unverified = "部分消息撤回失败：" + strings.Join(failed, "；")
+	}
+	return summary
+}
+
+// ExecuteRecallMessage 撤回飞书消息；超过撤回时限的消息无法撤回
+func (e *FeishuExecutor) ExecuteRecallMessage(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	msgIDs, _, err := recallParams(spec)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	var recalled, failed []string
+	for _, id := range msgIDs {
+		if err := e.Client.RecallMessage(ctx, token, id); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		recalled = append(recalled, id)
+	}
+	if len(recalled) == 0 {
+		return model.ActionSummary{}, fmt.Errorf("recall_message: %s", strings.Join(failed, "；"))
+	}
+	return recallSummary("feishu_recall", spec, recalled, failed), nil
+}
+
+// ExecuteRecallMessage 删除 Slack 消息；chat_id 可带工作区，如 "C0123@emea"
+func (e *SlackExecutor) ExecuteRecallMessage(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrSlackDisabled
+	}
+	msgIDs, chatIDs, err := recallParams(spec)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	if len(chatIDs) != len(msgIDs) {
+		return model.ActionSummary{}, fmt.Errorf("recall_message: %w: chat_ids must match message_ids", model.ErrInvalidParams)
+	}
+	base, err := e.workspaceClient(req)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	var recalled, failed []string
+	for i, ts := range msgIDs {
+		client, channelID, err := resolveTarget(base, strings.TrimSpace(chatIDs[i]))
+		if err == nil {
+			err = client.DeleteMessage(ctx, channelID, ts)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", ts, err))
+			continue
+		}
+		recalled = append(recalled, ts)
+	}
+	if len(recalled) == 0 {
+		return model.ActionSummary{}, fmt.Errorf("recall_message: %s", strings.Join(failed, "；"))
+	}
+	return recallSummary("slack_delete", spec, recalled, failed), nil
+}
+
+// ExecuteRenameDoc 修改飞书文档标题
+// params: doc_id, title, old_title
+func (e *FeishuExecutor) ExecuteRenameDoc(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	docID, _ := spec.Params["doc_id"].(string)
+	title, _ := spec.Params["title"].(string)
+	if docID == "" || strings.TrimSpace(title) == "" {
+		return model.ActionSummary{}, fmt.Errorf("feishu_rename_doc: %w: doc_id and title are required", model.ErrInvalidParams)
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	if err := e.Client.RenameDoc(ctx, token, docID, title); err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_rename_doc: %w", err)
+	}
+	oldTitle, _ := spec.Params["old_title"].(string)
+	summary := model.ActionSummary{Type: "feishu_rename", Target: title, ID: docID}
+	summary.Outputs = map[string]string{"doc_id": docID, "old_title": oldTitle}
+	if summary.URL = e.Client.DocURL("docx", docID); summary.URL != "" {
+		summary.Outputs["doc_url"] = summary.URL
+	}
+	return summary, nil
+}
@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/client/discord"
+	"sayso-agent/internal/model"
+)
+
+// DiscordExecutor Discord 相关动作执行器
+type DiscordExecutor struct {
+	Client *discord.Client
+	Cfg    discord.Config
+}
+
+// NewDiscordExecutor 创建 Discord 执行器
+func NewDiscordExecutor(client *discord.Client, cfg discord.Config) *DiscordExecutor {
+	return &DiscordExecutor{Client: client, Cfg: cfg}
+}
+
+// ExecuteSendMessage 发送消息到频道或用户私聊；频道写为 "#general"，可带服务器，如 "#general@My Server"，用户为用户 ID
+func (e *DiscordExecutor) ExecuteSendMessage(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrDiscordDisabled
+	}
+	params := model.ParseSendMessageParams(spec.Params)
+	if len(params.Targets) == 0 {
+		return model.ActionSummary{}, fmt.Errorf("send_message: targets is required")
+	}
+	msg := e.buildDiscordMessage(params)
+
+	var results []model.SendResult
+	switch params.TargetType {
+	case "user":
+		results = append(results, e.sendToUser(ctx, params.Targets[0], msg))
+	case "batch":
+		for _, target := range params.Targets {
+			// 联系人分组中可能混有频道，频道直接发送，用户先打开私聊
+			if isDiscordChannel(target) {
+				results = append(results, e.sendToChannel(ctx, target, msg))
+				continue
+			}
+			results = append(results, e.sendToUser(ctx, target, msg))
+		}
+	default:
+		// chat 及未指定时按频道处理
+		results = append(results, e.sendToChannel(ctx, params.Targets[0], msg))
+	}
+	return e.buildSendMessageSummary(results), nil
+}
+
+// buildDiscordMessage 根据消息类型构建消息：卡片类消息渲染为 Embed 与链接按钮，并关闭正文链接预览
+func (e *DiscordExecutor) buildDiscordMessage(params model.SendMessageParams) discord.Message {
+	var msg discord.Message
+	switch params.MessageType {
+	case "rich_text", "link_card":
+		msg.Embeds, msg.Components = discord.RenderCard(messageCard(params))
+	default:
+		msg.Content = params.Content.Text
+		if params.Content.URL != "" && !strings.Contains(msg.Content, params.Content.URL) {
+			msg.Content += "\n" + params.Content.URL
+		}
+	}
+	if params.Unfurl != nil && !*params.Unfurl {
+		msg.SuppressEmbeds()
+	}
+	return msg
+}
+
+// sendToUser 打开私聊后发送
+func (e *DiscordExecutor) sendToUser(ctx context.Context, target string, msg discord.Message) model.SendResult {
+	userID, _ := discord.ParseTarget(target)
+	channelID, err := e.Client.OpenDM(ctx, userID)
+	if err != nil {
+		return model.SendResult{TargetID: target, Error: err.Error()}
+	}
+	return e.post(ctx, target, channelID, msg)
+}
+
+// sendToChannel 解析频道名后发送
+func (e *DiscordExecutor) sendToChannel(ctx context.Context, target string, msg discord.Message) model.SendResult {
+	id, guild := discord.ParseTarget(target)
+	channelID, err := e.Client.ChannelID(ctx, id, guild)
+	if err != nil {
+		return model.SendResult{TargetID: target, Error: err.Error()}
+	}
+	return e.post(ctx, target, channelID, msg)
+}
+
+func (e *DiscordExecutor) post(ctx context.Context, target, channelID string, msg discord.Message) model.SendResult {
+	result, err := e.Client.PostMessage(ctx, channelID, msg)
+	if err != nil {
+		return model.SendResult{TargetID: target, Error: err.Error()}
+	}
+	return model.SendResult{TargetID: target, Success: true, MsgID: result.ID, ChatID: result.ChannelID}
+}
+
+// buildSendMessageSummary 构建发送消息摘要
+func (e *DiscordExecutor) buildSendMessageSummary(results []model.SendResult) model.ActionSummary {
+	summary := model.ActionSummary{
+		Type:    "discord_message",
+		Outputs: sendResultOutputs(results),
+	}
+	if len(results) == 1 {
+		summary.Target = results[0].TargetID
+		if results[0].Success {
+			summary.ID = results[0].MsgID
+		} else {
+			summary.Note = results[0].Error
+		}
+		return summary
+	}
+	successCount := 0
+	var failedTargets []string
+	for _, r := range results {
+		if r.Success {
+			successCount++
+		} else {
+			failedTargets = append(failedTargets, r.TargetID)
+		}
+	}
+	summary.Target = fmt.Sprintf("%d/%d targets", successCount, len(results))
+	if len(failedTargets) > 0 {
+		summary.Note = fmt.Sprintf("failed: %s", strings.Join(failedTargets, ", "))
+	}
+	return summary
+}
+
+// ExecuteRecallMessage 删除 Bot 发出的 Discord 消息，chat_ids 为消息所在频道
+func (e *DiscordExecutor) ExecuteRecallMessage(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrDiscordDisabled
+	}
+	msgIDs, chatIDs, err := recallParams(spec)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	if len(chatIDs) != len(msgIDs) {
+		return model.ActionSummary{}, fmt.Errorf("recall_message: %w: chat_ids must match message_ids", model.ErrInvalidParams)
+	}
+	var recalled, failed []string
+	for i, id := range msgIDs {
+		if err := e.Client.DeleteMessage(ctx, strings.TrimSpace(chatIDs[i]), id); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		recalled = append(recalled, id)
+	}
+	if len(recalled) == 0 {
+		return model.ActionSummary{}, fmt.Errorf("recall_message: %s", strings.Join(failed, "；"))
+	}
+	return recallSummary("discord_delete", spec, recalled, failed), nil
+}
+
+// isDiscordChannel 判断是否是频道（"#名称" 或 "<#ID>"），忽略 "@服务器" 后缀
+func isDiscordChannel(target string) bool {
+	t := strings.TrimSpace(target)
+	return strings.HasPrefix(t, "#") || strings.HasPrefix(t, "<#")
+}
@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/trace"
+)
+
+// 草稿预览卡片按钮回传的 decision
+const (
+	DraftDecisionSend = "send"
+	DraftDecisionEdit = "edit"
+)
+
+// defaultDraftReviewChars 未配置时需要审阅的草稿最小字数
+const defaultDraftReviewChars = 200
+
+// DraftReview 生成内容的审阅：撰写阶段生成的正文（文档正文、消息内容）不少于 MinChars 字时，
+// 先以带「发送」「修改」按钮的预览卡片发给请求人，点击「发送」或回复「确认」后才真正发送/创建
+type DraftReview struct {
+	MinChars int
+}
+
+// DraftReviewHook 拦截需审阅的草稿：请求人有飞书 open_id 时发送预览卡片，否则在待确认说明中附上草稿全文
+func (e *Executor) DraftReviewHook(review DraftReview) Hook {
+	if review.MinChars <= 0 {
+		review.MinChars = defaultDraftReviewChars
+	}
+	return Hook{
+		Name: "draft_review",
+		BeforeAction: func(ctx context.Context, spec *model.ActionSpec, req *model.ASRRequest) (context.Context, error) {
+			if !e.needsDraftReview(review, *spec, req) {
+				return ctx, nil
+			}
+			if e.canPreviewDraft(req) {
+				if err := e.feishu.sendDraftPreview(ctx, *spec, requesterID(req), trace.From(ctx).TaskID()); err != nil {
+					return ctx, fmt.Errorf("%s: send draft preview: %w", spec.Type, err)
+				}
+			}
+			return ctx, model.ErrConfirmationRequired
+		},
+		AfterAction: func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, summary *model.ActionSummary, err error) {
+			if !errors.Is(err, model.ErrConfirmationRequired) || summary.Note != "" || !e.needsDraftReview(review, spec, req) {
+				return
+			}
+			draft := draftText(spec.Params)
+			verb := "发送"
+			if spec.Type == model.ActionTypeCreateDoc {
+				verb = "创建文档"
+			}
+			summary.Type, summary.Target = "draft_preview", draftTarget(spec)
+			if e.canPreviewDraft(req) {
+				summary.Note = fmt.Sprintf("草稿（%d 字）已发给你预览，点击卡片上的「发送」或回复「确认」后%s，回复「取消」放弃", utf8.RuneCountInString(draft), verb)
+				return
+			}
+			summary.Note = fmt.Sprintf("请确认草稿：\n%s\n\n回复「确认」后%s，回复「取消」放弃", draft, verb)
+		},
+	}
+}
+
+// needsDraftReview 未确认、非沙箱的 create_doc / send_message 带有不少于 MinChars 字（或改写过）的生成草稿
+func (e *Executor) needsDraftReview(review DraftReview, spec model.ActionSpec, req *model.ASRRequest) bool {
+	if spec.Confirmed || e.Sandboxed(req) {
+		return false
+	}
+	if spec.Type != model.ActionTypeCreateDoc && spec.Type != model.ActionTypeSendMessage {
+		return false
+	}
+	// 按修改意见改写过的草稿不论长短都再次审阅
+	draft, _ := spec.Params["draft"].(map[string]any)
+	if revised, _ := draft["revised"].(bool); revised {
+		return true
+	}
+	return utf8.RuneCountInString(draftText(spec.Params)) >= review.MinChars
+}
+
+// canPreviewDraft 能否把预览卡片发给请求人（飞书已启用且知道请求人的 open_id）
+func (e *Executor) canPreviewDraft(req *model.ASRRequest) bool {
+	return e.feishu.Cfg.Enabled && isOpenID(requesterID(req))
+}
+
+// draftTarget 草稿的去向：消息为接收方，文档为标题
+func draftTarget(spec model.ActionSpec) string {
+	if spec.Type == model.ActionTypeSendMessage {
+		return strings.Join(model.ParseSendMessageParams(spec.Params).Targets, "、")
+	}
+	title, _ := spec.Params["title"].(string)
+	return title
+}
+
+// draftPreviewCard 草稿预览卡片：草稿全文与去向，按钮回传任务 ID 与 decision
+func draftPreviewCard(spec model.ActionSpec, taskID string) model.Card {
+	field := model.CardField{Name: "发送给", Value: draftTarget(spec)}
+	if spec.Type == model.ActionTypeCreateDoc {
+		field.Name = "文档标题"
+	}
+	if field.Value == "" {
+		field.Value = "-"
+	}
+	return model.Card{
+		Title: "草稿预览",
+		Icon:  "📝",
+		Color: "blue",
+		Sections: []model.CardSection{
+			{Fields: []model.CardField{field}},
+			{Text: draftText(spec.Params)},
+			{Text: "确认无误后点击「发送」；需要调整时点击「修改」，再直接说出修改意见"},
+		},
+		Buttons: []model.CardButton{
+			{Text: "发送", Primary: true, Value: map[string]string{"task_id": taskID, "decision": DraftDecisionSend}},
+			{Text: "修改", Value: map[string]string{"task_id": taskID, "decision": DraftDecisionEdit}},
+		},
+	}
+}
+
+// sendDraftPreview 把草稿预览卡片私信给请求人；请求来自飞书话题时发到话题中，同一话题的成员都能看到（仍只有请求人能操作）
+func (e *FeishuExecutor) sendDraftPreview(ctx context.Context, spec model.ActionSpec, openID, taskID string) error {
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	if root := threadFrom(ctx).FeishuRootID; root != "" {
+		_, err := e.Client.ReplyMessage(ctx, token, root, "interactive", feishu.RenderCard(draftPreviewCard(spec, taskID)), true)
+		return err
+	}
+	res := e.Client.SendMessage(ctx, token, feishu.SendMessageRequest{
+		ReceiveID:     openID,
+		ReceiveIDType: "open_id",
+		MsgType:       "interactive",
+		Content:       feishu.RenderCard(draftPreviewCard(spec, taskID)),
+	})
+	return res.Error
+}
@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// 目标目录中已有近期同名文档时的处理策略
+const (
+	duplicateAsk    = "ask"    // 暂停并询问用户，确认后仍新建
+	duplicateReuse  = "reuse"  // 直接复用已有文档
+	duplicateAppend = "append" // 将正文追加到已有文档
+	duplicateNew    = "new"    // 忽略重名，照常新建
+)
+
+// duplicateWindow 只把该时间内创建的同名文档视为重复（如重试产生的多份「周报 2024-05-20」）
+const duplicateWindow = 7 * 24 * time.Hour
+
+// duplicatePolicy 取参数 on_duplicate，其次配置，默认 ask
+func (e *FeishuExecutor) duplicatePolicy(spec model.ActionSpec) string {
+	param, _ := spec.Params["on_duplicate"].(string)
+	for _, p := range []string{param, e.Cfg.DuplicatePolicy} {
+		switch p {
+		case duplicateAsk, duplicateReuse, duplicateAppend, duplicateNew:
+			return p
+		}
+	}
+	return duplicateAsk
+}
+
+// findDuplicateDoc 在目标目录中查找近期创建的同名（忽略大小写、空白与标点）文档；列目录失败时视为没有重复
+func (e *FeishuExecutor) findDuplicateDoc(ctx context.Context, token, folderToken, title string) (feishu.FolderInfo, bool) {
+	want := normalizeTitle(title)
+	if want == "" {
+		return feishu.FolderInfo{}, false
+	}
+	if folderToken == "" {
+		root, err := e.Client.GetRootFolderToken(ctx, token)
+		if err != nil {
+			return feishu.FolderInfo{}, false
+		}
+		folderToken = root
+	}
+	files, err := e.Client.ListFolderChildren(ctx, token, folderToken)
+	if err != nil {
+		return feishu.FolderInfo{}, false
+	}
+	var found feishu.FolderInfo
+	for _, f := range files {
+		if f.Type != "docx" || normalizeTitle(f.Name) != want {
+			continue
+		}
+		// 列目录接口未返回创建时间时由文档元数据补上，否则无法按时间窗口判断
+		if f.CreatedAt.IsZero() {
+			if meta, err := e.Client.GetDocMeta(ctx, token, f.Token, f.Type); err == nil {
+				f.CreatedAt = meta.CreatedAt
+			}
+		}
+		if !f.CreatedAt.IsZero() && time.Since(f.CreatedAt) > duplicateWindow {
+			continue
+		}
+		if found.Token == "" || f.CreatedAt.After(found.CreatedAt) {
+			found = f
+		}
+	}
+	return found, found.Token != ""
+}
+
+// handleDuplicateDoc 按策略处理已存在的同名文档
+func (e *FeishuExecutor) handleDuplicateDoc(ctx context.Context, token string, dup feishu.FolderInfo, policy, folderToken, content string) (model.ActionSummary, error) {
+	summary := model.ActionSummary{Type: "feishu_doc", Target: dup.Name, ID: dup.Token, URL: dup.URL}
+	if summary.URL == "" {
+		summary.URL = e.Client.DocURL("docx", dup.Token)
+	}
+	summary.Outputs = map[string]string{"doc_id": dup.Token, "folder_token": folderToken}
+	if summary.URL != "" {
+		summary.Outputs["doc_url"] = summary.URL
+	}
+	created := ""
+	if !dup.CreatedAt.IsZero() {
+		created = dup.CreatedAt.Format("01-02 15:04") + " 创建的"
+		summary.Outputs["doc_created_at"] = dup.CreatedAt.Format(time.RFC3339)
+	}
+	if doc := e.lookupLinkedDoc(ctx, token, summary.URL); doc != nil && doc.Owner != "" {
+		created = doc.Owner + " " + created
+		summary.Outputs["doc_owner"] = doc.Owner
+	}
+
+	switch policy {
+	case duplicateReuse:
+		summary.Note = fmt.Sprintf("已有%s同名文档，直接复用", created)
+		return summary, nil
+	case duplicateAppend:
+		if err := e.Client.AppendDocBlocks(ctx, token, dup.Token, feishu.TextToBlocks(content)); err != nil {
+			return model.ActionSummary{}, err
+		}
+		summary.Note = fmt.Sprintf("已有%s同名文档，内容已追加到该文档", created)
+		return summary, nil
+	default:
+		summary.Note = fmt.Sprintf("目录中已有%s同名文档「%s」%s，确认后仍会新建一份；如需复用或追加到该文档请取消后重新说明", created, dup.Name, summary.URL)
+		return summary, model.ErrConfirmationRequired
+	}
+}
+
+// normalizeTitle 归一化标题用于重名比较：转小写，只保留字母与数字
+func normalizeTitle(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -3,45 +3,215 @@ package executor
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"sayso-agent/internal/client/discord"
 	"sayso-agent/internal/client/feishu"
 	"sayso-agent/internal/client/slack"
+	"sayso-agent/internal/client/sms"
 	"sayso-agent/internal/model"
+	"sayso-agent/internal/service/chart"
 )
 
-// Executor 根据大模型返回的动作规格，将具体执行委托给各 app 的执行器（飞书、Slack 等）
+// Executor 根据大模型返回的动作规格，将具体执行委托给各 app 的执行器（飞书、Slack、Discord 等）
 type Executor struct {
-	feishu *FeishuExecutor
-	slack  *SlackExecutor
+	feishu  *FeishuExecutor
+	slack   *SlackExecutor
+	discord *DiscordExecutor
+	sms     *SMSExecutor
+	aliases *AliasBook
+	kb      KnowledgeBase
+	plugins PluginRunner // 可选，外部插件技能
+	scripts ScriptRunner // 可选，租户脚本
+	speaker Speaker      // 可选，语音消息的语音合成
+	sandbox bool         // 沙箱模式：所有动作只返回模拟结果，不调用外部 API
+	hooks   []Hook       // 动作执行前后的钩子，见 Use
+
+	// artifacts 可选，保存导出文件、语音、图表并返回临时链接
+	artifacts ArtifactStore
+	// charts 表格问答的图表绘制器，为 nil 时使用内置字体
+	charts *chart.Renderer
+}
+
+// PluginRunner 执行外部插件技能（由 plugin.Registry 实现）
+type PluginRunner interface {
+	Execute(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error)
 }
 
-// NewExecutor 创建执行器，组装各 app 的执行器；folderMatcher 为可选（llm.FolderMatcher 等实现 FolderMatcher 接口）
-func NewExecutor(feishuClient *feishu.Client, slackClient *slack.Client, feishuCfg feishu.Config, slackCfg slack.Config, folderMatcher FolderMatcher) *Executor {
+// ScriptRunner 执行租户脚本（由 script.Engine 实现），脚本中的宿主函数通过 host 执行
+type ScriptRunner interface {
+	Run(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, host func(context.Context, model.ActionSpec, *model.ASRRequest) (model.ActionSummary, error)) (model.ActionSummary, error)
+}
+
+// Deps 执行器依赖；可选依赖为 nil 时对应功能不可用
+type Deps struct {
+	Feishu     *feishu.Client
+	FeishuCfg  feishu.Config
+	Slack      *slack.Client
+	SlackCfg   slack.Config
+	Discord    *discord.Client
+	DiscordCfg discord.Config
+	SMS        *sms.Client
+	SMSCfg     sms.Config
+
+	// FolderMatcher 按内容匹配目标文件夹（llm.FolderMatcher）
+	FolderMatcher FolderMatcher
+	// FolderRules 租户的文件夹归档规则（store.FolderRuleStore）
+	FolderRules FolderRuleSource
+	// Summarizer 妙记纪要生成（llm.MinutesSummarizer）
+	Summarizer NotesSummarizer
+	// Titler 文档标题生成（llm.Titler）
+	Titler DocTitler
+	// Analyst 表格问答（llm.TableAnalyst）
+	Analyst TableAnalyst
+	// Speaker 语音消息的语音合成（tts.Client）
+	Speaker Speaker
+	// KB 知识库问答
+	KB KnowledgeBase
+	// Aliases 配置中的联系人分组，send_message 的目标命中分组名时按成员所在平台分发
+	Aliases []model.Alias
+	// Plugins 外部插件（plugin.Registry），执行 plugin.<技能名> 动作
+	Plugins PluginRunner
+	// Scripts 租户脚本（script.Engine），执行 script.<脚本名> 动作
+	Scripts ScriptRunner
+	// Artifacts 产物存储（store.Artifacts），导出文件、语音、图表的链接写入动作摘要
+	Artifacts ArtifactStore
+	// Charts 图表绘制器，为 nil 时使用内置字体
+	Charts *chart.Renderer
+	// Sandbox 为 true 时不产生任何外部副作用
+	Sandbox bool
+}
+
+// NewExecutor 按 deps 创建执行器，组装各 app 的执行器
+func NewExecutor(deps Deps) *Executor {
 	return &Executor{
-		feishu: NewFeishuExecutor(feishuClient, feishuCfg, folderMatcher),
-		slack:  NewSlackExecutor(slackClient, slackCfg),
+		feishu:  NewFeishuExecutor(deps.Feishu, deps.FeishuCfg, deps.FolderMatcher, deps.FolderRules, deps.Summarizer, deps.Titler, deps.Analyst),
+		slack:   NewSlackExecutor(deps.Slack, deps.SlackCfg),
+		discord: NewDiscordExecutor(deps.Discord, deps.DiscordCfg),
+		sms:     NewSMSExecutor(deps.SMS, deps.SMSCfg),
+		speaker: deps.Speaker,
+		kb:      deps.KB,
+		aliases: NewAliasBook(deps.Aliases),
+		plugins: deps.Plugins,
+		scripts: deps.Scripts,
+		sandbox: deps.Sandbox,
+
+		artifacts: deps.Artifacts,
+		charts:    deps.Charts,
 	}
 }
 
-// Execute 执行单条动作，按 type 路由到对应 app 执行器
+// Execute 经过钩子执行单条动作，按 type 路由到对应 app 执行器；沙箱模式下返回模拟结果
 func (e *Executor) Execute(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if req != nil {
+		// 商店应用：飞书接口使用请求租户所在企业的 token
+		ctx = e.feishu.Client.WithTenant(ctx, req.Tenant())
+	}
+	if e.strictRecipients(req) {
+		ctx = withStrictRecipients(ctx)
+	}
+	ctx = withThread(ctx, req)
+	return e.Intercept(ctx, spec, req, e.dispatch)
+}
+
+// dispatch 按 type 路由到对应 app 执行器
+func (e *Executor) dispatch(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if e.Sandboxed(req) {
+		return e.executeSandbox(spec)
+	}
 	switch spec.Type {
 	case model.ActionTypeCreateDoc:
 		return e.feishu.ExecuteCreateDoc(ctx, spec, req)
+	case model.ActionTypeBulkCreateDoc:
+		return e.feishu.ExecuteBulkCreateDoc(ctx, spec, req)
 	case model.ActionTypeCreateFolder:
 		return e.feishu.ExecuteCreateFolder(ctx, spec, req)
+	case model.ActionTypeFolderTree:
+		return e.feishu.ExecuteCreateFolderTree(ctx, spec, req)
+	case model.ActionTypeMinutesNotes:
+		return e.feishu.ExecuteMinutesNotes(ctx, spec, req)
+	case model.ActionTypeReviewPerms:
+		return e.feishu.ExecuteReviewPermissions(ctx, spec, req)
+	case model.ActionTypeTransferOwner:
+		return e.feishu.ExecuteTransferOwner(ctx, spec, req)
+	case model.ActionTypeCommentDoc:
+		return e.feishu.ExecuteCommentDoc(ctx, spec, req)
+	case model.ActionTypeImportFile:
+		return e.feishu.ExecuteImportFile(ctx, spec, req)
+	case model.ActionTypeScheduleMeet:
+		return e.feishu.ExecuteScheduleMeeting(ctx, spec, req)
+	case model.ActionTypeSetStatus:
+		// 按 platform 设置个人状态
+		return e.executeSetStatus(ctx, spec, req)
+	case model.ActionTypeAddReaction:
+		// 按 platform 给消息添加表情回应
+		return e.executeAddReaction(ctx, spec, req)
+	case model.ActionTypeQueryApproval:
+		return e.feishu.ExecuteQueryApproval(ctx, spec, req)
+	case model.ActionTypeQueryTasks:
+		return e.feishu.ExecuteQueryTasks(ctx, spec, req)
+	case model.ActionTypeQueryTable:
+		// 回答附带图表时以图片发送
+		return e.executeQueryTable(ctx, spec, req)
+	case model.ActionTypeExportDoc:
+		// 导出后按 platform 以附件发送
+		return e.executeExportDoc(ctx, spec, req)
 	case model.ActionTypeSendMessage:
-		// 统一消息发送，根据 platform 路由
-		platform, _ := spec.Params["platform"].(string)
-		switch platform {
-		case "feishu":
-			return e.feishu.ExecuteSendMessage(ctx, spec, req)
-		case "slack":
-			return e.slack.ExecuteSendMessage(ctx, spec, req)
-		default:
-			return model.ActionSummary{}, fmt.Errorf("send_message: unsupported platform: %s", platform)
-		}
+		// 统一消息发送，展开联系人分组后根据 platform 路由
+		return e.executeSendMessage(ctx, spec, req)
+	case model.ActionTypeSendSMS:
+		return e.sms.ExecuteSendSMS(ctx, spec, req)
+	case model.ActionTypeRecallMessage:
+		// 纠正时撤回发错的消息，按 platform 路由
+		return e.executeRecallMessage(ctx, spec, req)
+	case model.ActionTypeRenameDoc:
+		return e.feishu.ExecuteRenameDoc(ctx, spec, req)
+	case model.ActionTypeRenameFile:
+		return e.feishu.ExecuteRenameFile(ctx, spec, req)
+	case model.ActionTypeCreateTicket:
+		return e.feishu.ExecuteCreateTicket(ctx, spec, req)
+	case model.ActionTypeAskKB:
+		// 检索知识库回答，指定接收方时以消息发送
+		return e.executeAskKB(ctx, spec, req)
 	default:
+		if e.plugins != nil && strings.HasPrefix(spec.Type, model.ActionTypePluginPrefix) {
+			// 外部插件技能，转发给插件执行
+			return e.plugins.Execute(ctx, spec, req)
+		}
+		if e.scripts != nil && strings.HasPrefix(spec.Type, model.ActionTypeScriptPrefix) {
+			// 租户脚本，宿主函数（发消息、建文档）同样经过钩子
+			return e.scripts.Run(ctx, spec, req, e.Execute)
+		}
 		return model.ActionSummary{}, fmt.Errorf("%w: %s", model.ErrActionNotSupport, spec.Type)
 	}
 }
+
+// messageCard send_message 的卡片布局，飞书与 Slack 共用，由各平台客户端渲染
+func messageCard(params model.SendMessageParams) model.Card {
+	return model.LinkCard(params.Content.Title, params.Content.Text, params.Content.URL, params.Content.Description)
+}
+
+// sendResultOutputs 从发送结果中提取输出变量：message_id/chat_id 取首个成功结果，message_ids 为全部成功消息
+func sendResultOutputs(results []model.SendResult) map[string]string {
+	outputs := make(map[string]string)
+	var msgIDs, chatIDs []string
+	for _, r := range results {
+		if !r.Success {
+			continue
+		}
+		if _, ok := outputs["message_id"]; !ok {
+			outputs["message_id"] = r.MsgID
+			if r.ChatID != "" {
+				outputs["chat_id"] = r.ChatID
+			}
+		}
+		msgIDs = append(msgIDs, r.MsgID)
+		chatIDs = append(chatIDs, r.ChatID)
+	}
+	if len(msgIDs) > 0 {
+		outputs["message_ids"] = strings.Join(msgIDs, ",")
+		// 与 message_ids 一一对应，撤回 Slack 消息时需要
+		outputs["chat_ids"] = strings.Join(chatIDs, ",")
+	}
+	return outputs
+}
@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// exportFormats 各文档类型支持的导出格式，首个为默认
+var exportFormats = map[string][]string{
+	"docx":    {"pdf", "docx"},
+	"doc":     {"pdf", "docx"},
+	"sheet":   {"xlsx", "csv"},
+	"bitable": {"xlsx", "csv"},
+}
+
+// executeExportDoc 导出飞书文档并以附件发给 targets（未指定时发给请求人）
+// params: doc_url 或 doc_name, format(pdf|docx|xlsx|csv), platform(feishu|slack), targets
+func (e *Executor) executeExportDoc(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	// 邮件发送导出文件暂不支持（没有出站邮件通道），直接拒绝而不是改发到飞书
+	if model.ParseSendMessageParams(spec.Params).Platform == "email" {
+		return model.ActionSummary{}, fmt.Errorf("export_doc: %w: 暂不支持通过邮件发送导出文件，可发到飞书或 Slack", model.ErrInvalidParams)
+	}
+	doc, file, err := e.feishu.ExportDoc(ctx, spec)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	summary := model.ActionSummary{Type: "feishu_export", Target: file.Name, ID: doc.Token, URL: doc.URL}
+	// 导出的文件另存一份到对象存储，下载链接随回复给出
+	fileURL := e.saveArtifact(ctx, "exports", file.Name, "", file.Data)
+
+	params := model.ParseSendMessageParams(spec.Params)
+	targets := params.Targets
+	if len(targets) == 0 {
+		if requester := requesterID(req); requester != "" {
+			targets = []string{requester}
+		}
+	}
+	if len(targets) == 0 {
+		summary.Note = "已导出，未指定接收人"
+		summary.Outputs = map[string]string{"file_name": file.Name}
+		if fileURL != "" {
+			summary.Note = "已导出，下载链接：" + fileURL
+			summary.Outputs["file_url"] = fileURL
+		}
+		return summary, nil
+	}
+
+	var results []model.SendResult
+	switch params.Platform {
+	case "slack":
+		results = e.slack.SendFile(ctx, file, targets, req)
+	default:
+		results = e.feishu.SendFile(ctx, file, targets)
+	}
+	summary.Outputs = sendResultOutputs(results)
+	summary.Outputs["file_name"] = file.Name
+	if fileURL != "" {
+		summary.Outputs["file_url"] = fileURL
+	}
+
+	var failed []string
+	for _, r := range results {
+		if !r.Success {
+			failed = append(failed, fmt.Sprintf("%s（%s）", r.TargetID, r.Error))
+		}
+	}
+	summary.Note = fmt.Sprintf("已发送 %d/%d", len(results)-len(failed), len(results))
+	if len(failed) > 0 {
+		summary.Note += "，失败：" + strings.Join(failed, "；")
+	}
+	if len(failed) == len(results) {
+		return summary, fmt.Errorf("export_doc: send file failed: %s", strings.Join(failed, "；"))
+	}
+	return summary, nil
+}
+
+// ExportDoc 定位并导出文档，返回文档与导出的文件
+func (e *FeishuExecutor) ExportDoc(ctx context.Context, spec model.ActionSpec) (docRef, feishu.ExportedFile, error) {
+	if !e.Cfg.Enabled {
+		return docRef{}, feishu.ExportedFile{}, model.ErrFeishuDisabled
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return docRef{}, feishu.ExportedFile{}, err
+	}
+	doc, err := e.resolveDoc(ctx, token, spec)
+	if err != nil {
+		return docRef{}, feishu.ExportedFile{}, fmt.Errorf("export_doc: %w", err)
+	}
+	formats, ok := exportFormats[doc.Type]
+	if !ok {
+		return docRef{}, feishu.ExportedFile{}, fmt.Errorf("export_doc: %w: %s documents cannot be exported", model.ErrInvalidParams, doc.Type)
+	}
+	format, _ := spec.Params["format"].(string)
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if format == "word" {
+		format = "docx"
+	}
+	if format == "" {
+		format = formats[0]
+	}
+	if !slices.Contains(formats, format) {
+		return docRef{}, feishu.ExportedFile{}, fmt.Errorf("export_doc: %w: %s cannot be exported as %s", model.ErrInvalidParams, doc.Type, format)
+	}
+
+	file, err := e.Client.ExportFile(ctx, token, doc.Token, doc.Type, format)
+	if err != nil {
+		return docRef{}, feishu.ExportedFile{}, err
+	}
+	if file.Name == "" {
+		file.Name = doc.Name
+	}
+	if file.Name == "" {
+		file.Name = doc.Token
+	}
+	if !strings.HasSuffix(strings.ToLower(file.Name), "."+format) {
+		file.Name += "." + format
+	}
+	file.Ext = format
+	return doc, file, nil
+}
+
+// SendFile 上传文件后以文件消息发给各目标（用户名、open_id、邮箱或群 ID）
+func (e *FeishuExecutor) SendFile(ctx context.Context, file feishu.ExportedFile, targets []string) []model.SendResult {
+	fail := func(err error) []model.SendResult {
+		results := make([]model.SendResult, len(targets))
+		for i, t := range targets {
+			results[i] = model.SendResult{TargetID: t, Error: err.Error()}
+		}
+		return results
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	fileKey, err := e.Client.UploadIMFile(ctx, token, file.Name, file.Ext, file.Data)
+	if err != nil {
+		return fail(err)
+	}
+	content := feishu.BuildFileContent(fileKey)
+	var results []model.SendResult
+	for _, target := range targets {
+		results = append(results, e.sendToTarget(ctx, token, target, "user", "file", content))
+	}
+	return results
+}
@@ -0,0 +1,26 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"sayso-agent/internal/model"
+)
+
+func TestExportDocRejectsEmail(t *testing.T) {
+	// feishu 为空：邮件发送应在导出之前拒绝
+	e := &Executor{}
+	spec := model.ActionSpec{Type: model.ActionTypeExportDoc, Params: map[string]any{
+		"doc_url":  "https://example.feishu.cn/docx/abc",
+		"platform": "email",
+		"targets":  []any{"alice@example.com"},
+	}}
+	_, err := e.executeExportDoc(context.Background(), spec, &model.ASRRequest{})
+	if !errors.Is(err, model.ErrInvalidParams) {
+		t.Fatalf("err = %v, want ErrInvalidParams", err)
+	}
+	if from, _ := e.needsFallback(spec, &model.ASRRequest{}); from != "" {
+		t.Fatalf("needsFallback from = %q, want no fallback for email", from)
+	}
+}
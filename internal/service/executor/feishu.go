@@ -2,8 +2,11 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"sayso-agent/internal/client/feishu"
 	"sayso-agent/internal/model"
@@ -13,7 +16,11 @@ import (
 type FeishuExecutor struct {
 	Client        *feishu.Client
 	Cfg           feishu.Config
-	FolderMatcher FolderMatcher // 可选，用于按标题智能选目录
+	FolderMatcher FolderMatcher    // 可选，用于按标题智能选目录
+	FolderRules   FolderRuleSource // 可选，租户配置的归档规则，先于 FolderMatcher
+	Summarizer    NotesSummarizer  // 可选，用于妙记整理纪要
+	Titler        DocTitler        // 可选，为未给出标题的文档生成标题
+	Analyst       TableAnalyst     // 可选，用于表格数据问答
 }
 
 // FolderMatcher 目录匹配器（由 llm.FolderMatcher 等实现，避免循环依赖）
@@ -21,13 +28,18 @@ type FolderMatcher interface {
 	MatchFolder(ctx context.Context, title string, folders []feishu.FolderInfo) (token, name string, err error)
 }
 
+// FolderRuleSource 归档规则来源（由 store.FolderRuleStore 等实现）
+type FolderRuleSource interface {
+	List(ctx context.Context, tenantID string) ([]model.FolderRule, error)
+}
+
 // NewFeishuExecutor 创建飞书执行器
-func NewFeishuExecutor(client *feishu.Client, cfg feishu.Config, folderMatcher FolderMatcher) *FeishuExecutor {
-	return &FeishuExecutor{Client: client, Cfg: cfg, FolderMatcher: folderMatcher}
+func NewFeishuExecutor(client *feishu.Client, cfg feishu.Config, folderMatcher FolderMatcher, folderRules FolderRuleSource, summarizer NotesSummarizer, titler DocTitler, analyst TableAnalyst) *FeishuExecutor {
+	return &FeishuExecutor{Client: client, Cfg: cfg, FolderMatcher: folderMatcher, FolderRules: folderRules, Summarizer: summarizer, Titler: titler, Analyst: analyst}
 }
 
 // ExecuteCreateDoc 创建飞书云文档
-func (e *FeishuExecutor) ExecuteCreateDoc(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+func (e *FeishuExecutor) ExecuteCreateDoc(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
 	if !e.Cfg.Enabled {
 		return model.ActionSummary{}, model.ErrFeishuDisabled
 	}
@@ -39,18 +51,122 @@ func (e *FeishuExecutor) ExecuteCreateDoc(ctx context.Context, spec model.Action
 	folderNameParam, _ := spec.Params["folder_name"].(string)
 	title, _ := spec.Params["title"].(string)
 	content, _ := spec.Params["content"].(string)
-	if title == "" {
-		title = "未命名文档"
+	title = e.docTitle(ctx, title, content, req)
+
+	explicitFolder := folderToken != "" || folderNameParam != ""
+	folderToken, folderName := e.resolveDocFolder(ctx, token, tenantOf(req), folderToken, folderNameParam, title)
+
+	// 先确认能写入目标目录，避免匹配完目录后才创建失败
+	var folderNote string
+	if denied := e.folderWriteDenied(ctx, token, folderToken, folderName); denied != "" {
+		if explicitFolder && !spec.Confirmed {
+			summary := model.ActionSummary{Type: "feishu_doc", Target: title}
+			summary.Note = denied + "，确认后将改存到「我的空间」；如需存到该目录请先给应用开通编辑权限"
+			return summary, model.ErrConfirmationRequired
+		}
+		rootToken, err := e.Client.GetRootFolderToken(ctx, token)
+		if err != nil {
+			return model.ActionSummary{}, fmt.Errorf("%s，改存「我的空间」失败: %w", denied, err)
+		}
+		folderToken, folderName = rootToken, "我的空间"
+		folderNote = denied + "，已改存到「我的空间」"
 	}
 
-	var folderName string
-	var folders []feishu.FolderInfo
-	if folderToken == "" {
-		folders, _ = e.Client.GetFolderTree(ctx, token, 2)
+	// 找不到的协作者先请用户确认，避免「给张三编辑权限」悄悄落空
+	collaborators, unresolved := e.resolveDocCollaborators(ctx, token, spec)
+	if len(unresolved) > 0 && e.Cfg.ClarifyCollaborators && !spec.Confirmed {
+		summary := model.ActionSummary{Type: "feishu_doc", Target: title}
+		summary.Note = fmt.Sprintf("找不到用户「%s」，确认后仍会创建文档「%s」但不添加这些协作者；如需添加请取消后说明全名或邮箱", strings.Join(unresolved, "、"), title)
+		return summary, model.ErrConfirmationRequired
+	}
+
+	// 用户确认「仍新建」后不再查重
+	if policy := e.duplicatePolicy(spec); policy != duplicateNew && !spec.Confirmed {
+		if dup, ok := e.findDuplicateDoc(ctx, token, folderToken, title); ok {
+			return e.handleDuplicateDoc(ctx, token, dup, policy, folderToken, content)
+		}
+	}
+
+	fileToken, err := e.Client.CreateDoc(ctx, token, folderToken, title, content)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	var notes []string
+	if content != "" {
+		if err := e.Client.AppendDocBlocks(ctx, token, fileToken, feishu.TextToBlocks(content)); err != nil {
+			notes = append(notes, fmt.Sprintf("正文写入失败: %v", err))
+		}
+	}
+	added, failed := e.addDocCollaborators(ctx, token, fileToken, collaborators)
+	for _, name := range unresolved {
+		failed = append(failed, name+"（未找到该用户）")
+	}
+
+	summary := model.ActionSummary{Type: "feishu_doc", Target: title, ID: fileToken}
+	summary.Outputs = map[string]string{"doc_id": fileToken, "folder_token": folderToken}
+	if len(added) > 0 {
+		var ids, names []string
+		for _, c := range added {
+			ids = append(ids, c.Member.MemberID)
+			names = append(names, c.Name)
+		}
+		summary.Outputs["collaborators"] = strings.Join(ids, ",")
+		summary.Outputs["collaborators_added"] = strings.Join(names, "、")
+		notes = append(notes, "已添加协作者："+summary.Outputs["collaborators_added"])
+	}
+	if len(failed) > 0 {
+		// 文档已创建，协作者未全部添加：不算失败，但要让用户知道
+		summary.Outputs["collaborators_failed"] = strings.Join(failed, "、")
+		summary.Unverified = "协作者添加失败：" + summary.Outputs["collaborators_failed"]
+	}
+	if summary.URL = e.Client.DocURL("docx", fileToken); summary.URL != "" {
+		summary.Outputs["doc_url"] = summary.URL
+	}
+	if folderName != "" {
+		summary.Outputs["folder_name"] = folderName
+	}
+	if folderNote != "" {
+		notes = append([]string{folderNote}, notes...)
+	} else if folderName != "" {
+		notes = append([]string{fmt.Sprintf("已存放至「%s」目录", folderName)}, notes...)
+	}
+	summary.Note = strings.Join(notes, "；")
+	return summary, nil
+}
+
+// folderWriteDenied 检查应用能否在目录中新建文档，不能时返回原因；根目录或检查接口出错时视为可写（不阻塞创建）
+func (e *FeishuExecutor) folderWriteDenied(ctx context.Context, token, folderToken, folderName string) string {
+	if folderToken == "" || folderName == "我的空间" {
+		return ""
+	}
+	ok, err := e.Client.CheckMemberPermission(ctx, token, folderToken, "folder", "edit")
+	if err != nil {
+		log.Printf("check folder %s permission: %v", folderToken, err)
+		return ""
+	}
+	if ok {
+		return ""
 	}
-	if folderToken == "" && folderNameParam != "" && len(folders) > 0 {
+	name := folderName
+	if name == "" {
+		name = folderToken
+	}
+	return fmt.Sprintf("应用没有「%s」目录的编辑权限", name)
+}
+
+// resolveDocFolder 确定文档存放目录：显式 token > 按名称匹配 > 租户归档规则 > 智能匹配 > 根目录
+func (e *FeishuExecutor) resolveDocFolder(ctx context.Context, token, tenantID, folderToken, folderNameParam, title string) (string, string) {
+	if folderToken != "" {
+		return folderToken, ""
+	}
+	var folderName string
+	folders, _ := e.Client.GetFolderTree(ctx, token, 2)
+	if folderNameParam != "" && len(folders) > 0 {
 		folderToken, folderName = matchFolderByName(folderNameParam, folders)
 	}
+	if folderToken == "" {
+		folderToken, folderName = e.matchFolderRule(ctx, tenantID, title, folders)
+	}
 	if folderToken == "" && e.FolderMatcher != nil && len(folders) > 0 {
 		folderToken, folderName, _ = e.FolderMatcher.MatchFolder(ctx, title, folders)
 	}
@@ -61,21 +177,84 @@ func (e *FeishuExecutor) ExecuteCreateDoc(ctx context.Context, spec model.Action
 			folderName = "我的空间"
 		}
 	}
+	return folderToken, folderName
+}
 
-	fileToken, err := e.Client.CreateDoc(ctx, token, folderToken, title, content)
+// matchFolderRule 按租户归档规则匹配目录：标题包含规则任一关键词即命中，靠前的规则优先
+func (e *FeishuExecutor) matchFolderRule(ctx context.Context, tenantID, title string, folders []feishu.FolderInfo) (string, string) {
+	if e.FolderRules == nil || title == "" {
+		return "", ""
+	}
+	rules, err := e.FolderRules.List(ctx, tenantID)
 	if err != nil {
-		return model.ActionSummary{}, err
+		return "", ""
 	}
-	e.addDocCollaborators(ctx, token, fileToken, spec)
+	lowerTitle := strings.ToLower(title)
+	for _, rule := range rules {
+		for _, kw := range rule.Keywords {
+			if kw == "" || !strings.Contains(lowerTitle, strings.ToLower(kw)) {
+				continue
+			}
+			if rule.FolderToken != "" {
+				return rule.FolderToken, rule.FolderName
+			}
+			if token, name := matchFolderByName(rule.FolderName, folders); token != "" {
+				return token, name
+			}
+		}
+	}
+	return "", ""
+}
 
-	summary := model.ActionSummary{Type: "feishu_doc", Target: title, ID: fileToken}
-	if e.Cfg.Domain != "" {
-		summary.URL = fmt.Sprintf("https://%s/docx/%s", e.Cfg.Domain, fileToken)
+// tenantOf 请求所属租户，req 为空时为默认租户
+func tenantOf(req *model.ASRRequest) string {
+	if req == nil {
+		return model.DefaultTenant
 	}
-	if folderName != "" {
-		summary.Note = fmt.Sprintf("已存放至「%s」目录", folderName)
+	return req.Tenant()
+}
+
+// docRef 已定位的云文档
+type docRef struct {
+	Token string
+	Type  string // docx, sheet, bitable 等
+	URL   string
+	Name  string
+}
+
+// docSearchDepth 按名称查找文档时遍历的目录深度
+const docSearchDepth = 3
+
+// resolveDoc 定位动作要操作的文档：doc_url 优先，否则按 doc_name 在云空间中查找（完全同名优先）
+func (e *FeishuExecutor) resolveDoc(ctx context.Context, token string, spec model.ActionSpec) (docRef, error) {
+	docURL, _ := spec.Params["doc_url"].(string)
+	if docURL != "" {
+		docToken, docType, err := feishu.ParseDocURL(docURL)
+		if err != nil {
+			return docRef{}, fmt.Errorf("%w: %v", model.ErrInvalidParams, err)
+		}
+		return docRef{Token: docToken, Type: docType, URL: docURL}, nil
 	}
-	return summary, nil
+	docName, _ := spec.Params["doc_name"].(string)
+	if docName == "" {
+		return docRef{}, fmt.Errorf("%w: doc_url or doc_name is required", model.ErrInvalidParams)
+	}
+	files, err := e.Client.FindFilesByName(ctx, token, docName, docSearchDepth)
+	if err != nil {
+		return docRef{}, err
+	}
+	if len(files) == 0 {
+		return docRef{}, fmt.Errorf("document %q not found", docName)
+	}
+	found := files[0]
+	for _, f := range files {
+		if f.Name == docName {
+			found = f
+			break
+		}
+	}
+	ref := docRef{Token: found.Token, Type: found.Type, Name: found.Name, URL: e.Client.DocURL(feishu.DocPath(found.Type), found.Token)}
+	return ref, nil
 }
 
 // ExecuteCreateFolder 创建飞书云空间文件夹
@@ -91,41 +270,59 @@ func (e *FeishuExecutor) ExecuteCreateFolder(ctx context.Context, spec model.Act
 	if name == "" {
 		return model.ActionSummary{}, fmt.Errorf("feishu_create_folder: name is required")
 	}
-	folderToken, _ := spec.Params["folder_token"].(string)
-	folderNameParam, _ := spec.Params["folder_name"].(string)
-	var parentName string
-	if folderToken == "" {
-		folders, _ := e.Client.GetFolderTree(ctx, token, 2)
-		if folderNameParam != "" && len(folders) > 0 {
-			folderToken, parentName = matchFolderByName(folderNameParam, folders)
-		}
-		if folderToken == "" {
-			rootToken, err := e.Client.GetRootFolderToken(ctx, token)
-			if err != nil {
-				return model.ActionSummary{}, fmt.Errorf("feishu create folder: get root folder: %w", err)
-			}
-			folderToken = rootToken
-			parentName = "我的空间"
-		}
+	folderToken, parentName, err := e.resolveParentFolder(ctx, token, spec)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu create folder: %w", err)
 	}
 	newFolderToken, err := e.Client.CreateFolder(ctx, token, folderToken, name)
 	if err != nil {
 		return model.ActionSummary{}, err
 	}
 	summary := model.ActionSummary{Type: "feishu_folder", Target: name, ID: newFolderToken}
-	if e.Cfg.Domain != "" {
-		summary.URL = fmt.Sprintf("https://%s/drive/folder/%s", e.Cfg.Domain, newFolderToken)
+	summary.Outputs = map[string]string{"folder_id": newFolderToken}
+	if summary.URL = e.Client.DocURL("drive/folder", newFolderToken); summary.URL != "" {
+		summary.Outputs["folder_url"] = summary.URL
 	}
 	if parentName != "" {
+		summary.Outputs["parent_name"] = parentName
 		summary.Note = fmt.Sprintf("已创建在「%s」下", parentName)
 	}
 	return summary, nil
 }
 
-func (e *FeishuExecutor) addDocCollaborators(ctx context.Context, accessToken, docToken string, spec model.ActionSpec) {
+// resolveParentFolder 新建文件夹的上级目录：folder_token > 按 folder_name 匹配 > 「我的空间」
+func (e *FeishuExecutor) resolveParentFolder(ctx context.Context, token string, spec model.ActionSpec) (string, string, error) {
+	folderToken, _ := spec.Params["folder_token"].(string)
+	if folderToken != "" {
+		return folderToken, "", nil
+	}
+	var parentName string
+	folderNameParam, _ := spec.Params["folder_name"].(string)
+	folders, _ := e.Client.GetFolderTree(ctx, token, 2)
+	if folderNameParam != "" && len(folders) > 0 {
+		folderToken, parentName = matchFolderByName(folderNameParam, folders)
+	}
+	if folderToken == "" {
+		rootToken, err := e.Client.GetRootFolderToken(ctx, token)
+		if err != nil {
+			return "", "", fmt.Errorf("get root folder: %w", err)
+		}
+		folderToken, parentName = rootToken, "我的空间"
+	}
+	return folderToken, parentName, nil
+}
+
+// docCollaborator 已解析的协作者；Name 为用户原话中的名字或 ID
+type docCollaborator struct {
+	Name   string
+	Member feishu.Collaborator
+}
+
+// resolveDocCollaborators 解析 params.collaborators：非 open_id 的按名字搜索用户，找不到的放入 unresolved
+func (e *FeishuExecutor) resolveDocCollaborators(ctx context.Context, accessToken string, spec model.ActionSpec) (resolved []docCollaborator, unresolved []string) {
 	collaborators, ok := spec.Params["collaborators"].([]any)
 	if !ok {
-		return
+		return nil, nil
 	}
 	for _, c := range collaborators {
 		collab, ok := c.(map[string]any)
@@ -144,24 +341,31 @@ func (e *FeishuExecutor) addDocCollaborators(ctx context.Context, accessToken, d
 		if memberID == "" {
 			continue
 		}
-		resolvedID := memberID
-		resolvedType := memberType
+		member := feishu.Collaborator{MemberType: memberType, MemberID: memberID, Perm: perm}
 		// 如果不是 open_id 格式，尝试按名字搜索
 		if !isOpenID(memberID) {
 			user, err := e.Client.SearchUserByName(ctx, accessToken, memberID)
-			if err == nil && user != nil && user.UserID != "" {
-				resolvedID = user.UserID
-				resolvedType = "userid"
-			} else {
+			if err != nil || user == nil || user.UserID == "" {
+				unresolved = append(unresolved, memberID)
 				continue
 			}
+			member.MemberID, member.MemberType = user.UserID, "userid"
+		}
+		resolved = append(resolved, docCollaborator{Name: memberID, Member: member})
+	}
+	return resolved, unresolved
+}
+
+// addDocCollaborators 逐个添加协作者，返回添加成功的与失败的（附原因）
+func (e *FeishuExecutor) addDocCollaborators(ctx context.Context, accessToken, docToken string, collaborators []docCollaborator) (added []docCollaborator, failed []string) {
+	for _, c := range collaborators {
+		if err := e.Client.AddCollaborator(ctx, accessToken, docToken, "docx", c.Member); err != nil {
+			failed = append(failed, fmt.Sprintf("%s（%v）", c.Name, err))
+			continue
 		}
-		_ = e.Client.AddCollaborator(ctx, accessToken, docToken, "docx", feishu.Collaborator{
-			MemberType: resolvedType,
-			MemberID:   resolvedID,
-			Perm:       perm,
-		})
+		added = append(added, c)
 	}
+	return added, failed
 }
 
 func isOpenID(id string) bool {
@@ -194,8 +398,11 @@ func (e *FeishuExecutor) ExecuteSendMessage(ctx context.Context, spec model.Acti
 
 	params := model.ParseSendMessageParams(spec.Params)
 
+	// 链接为云文档时读取元数据，用于分享卡片与回复
+	doc := e.lookupLinkedDoc(ctx, token, params.Content.URL)
+
 	// 构建消息内容
-	msgType, content := e.buildFeishuMessage(params)
+	msgType, content := e.buildFeishuMessage(ctx, token, params, doc)
 
 	var results []model.SendResult
 
@@ -230,11 +437,13 @@ func (e *FeishuExecutor) ExecuteSendMessage(ctx context.Context, spec model.Acti
 		}
 	}
 
-	return e.buildSendMessageSummary(results, params), nil
+	summary := e.buildSendMessageSummary(results, params)
+	doc.addOutputs(&summary)
+	return summary, nil
 }
 
-// buildFeishuMessage 根据消息类型构建飞书消息内容
-func (e *FeishuExecutor) buildFeishuMessage(params model.SendMessageParams) (msgType, content string) {
+// buildFeishuMessage 根据消息类型构建飞书消息内容；链接卡片指向云文档时构建带标题、所有者的分享卡片
+func (e *FeishuExecutor) buildFeishuMessage(ctx context.Context, token string, params model.SendMessageParams, doc *linkedDoc) (msgType, content string) {
 	switch params.MessageType {
 	case "rich_text", "post":
 		msgType = "post"
@@ -242,20 +451,88 @@ func (e *FeishuExecutor) buildFeishuMessage(params model.SendMessageParams) (msg
 
 	case "link_card", "interactive":
 		msgType = "interactive"
-		content = feishu.BuildInteractiveCard(
-			params.Content.Title,
-			params.Content.Text,
-			params.Content.URL,
-			params.Content.Description,
-		)
+		if doc != nil {
+			content = e.docShareCard(ctx, token, params, doc)
+			break
+		}
+		content = feishu.RenderCard(messageCard(params))
 
 	default: // text
+		// 含链接时以富文本发送，每个链接单独可点击，不会连带紧挨着的中文标点
+		if feishu.HasURL(params.Content.Text) {
+			msgType = "post"
+			content = feishu.BuildPostContent("", params.Content.Text, "")
+			break
+		}
 		msgType = "text"
 		content = feishu.BuildTextContent(params.Content.Text)
 	}
 	return msgType, content
 }
 
+// linkedDoc 消息链接指向的云文档
+type linkedDoc struct {
+	Meta  feishu.DocMeta
+	Owner string // 所有者姓名，查不到时为空
+}
+
+// lookupLinkedDoc 链接为云文档时读取元数据与所有者姓名；不是云文档或读取失败时返回 nil
+func (e *FeishuExecutor) lookupLinkedDoc(ctx context.Context, token, link string) *linkedDoc {
+	if !strings.Contains(link, "/") {
+		return nil
+	}
+	docToken, docType, err := feishu.ParseDocURL(link)
+	if err != nil {
+		return nil
+	}
+	meta, err := e.Client.GetDocMeta(ctx, token, docToken, docType)
+	if err != nil {
+		log.Printf("linked doc: get meta %s failed: %v", docToken, err)
+		return nil
+	}
+	if meta.URL == "" {
+		meta.URL = link
+	}
+	doc := &linkedDoc{Meta: meta}
+	if meta.OwnerID != "" {
+		doc.Owner, _ = e.Client.GetUserName(ctx, token, meta.OwnerID)
+	}
+	return doc
+}
+
+// addOutputs 把文档的标题、所有者与创建时间写入动作输出，供回复说明"该文档由王五创建于上周"
+func (d *linkedDoc) addOutputs(summary *model.ActionSummary) {
+	if d == nil {
+		return
+	}
+	if summary.Outputs == nil {
+		summary.Outputs = make(map[string]string)
+	}
+	summary.Outputs["doc_title"] = d.Meta.Title
+	if d.Owner != "" {
+		summary.Outputs["doc_owner"] = d.Owner
+	}
+	if !d.Meta.CreatedAt.IsZero() {
+		summary.Outputs["doc_created_at"] = d.Meta.CreatedAt.Format(time.RFC3339)
+	}
+	if !d.Meta.ModifiedAt.IsZero() {
+		summary.Outputs["doc_modified_at"] = d.Meta.ModifiedAt.Format(time.RFC3339)
+	}
+}
+
+// docShareCard 构建云文档分享卡片，附带浏览统计（读取失败时不显示）
+func (e *FeishuExecutor) docShareCard(ctx context.Context, token string, params model.SendMessageParams, doc *linkedDoc) string {
+	var stats *feishu.DocStats
+	if s, err := e.Client.GetDocStatistics(ctx, token, doc.Meta.Token, doc.Meta.Type); err == nil {
+		stats = &s
+	}
+	text := params.Content.Text
+	if params.Content.Description != "" {
+		text = strings.TrimSpace(text + "\n" + params.Content.Description)
+	}
+	return feishu.BuildDocShareCard(doc.Meta, doc.Owner, text, stats)
+}
+
 // sendToTarget 发送消息到指定目标
 func (e *FeishuExecutor) sendToTarget(ctx context.Context, token, target, targetType, msgType, content string) model.SendResult {
 	receiveIDType := "open_id"
@@ -271,9 +548,15 @@ func (e *FeishuExecutor) sendToTarget(ctx context.Context, token, target, target
 			receiveIDType = "open_id"
 		} else if isChatID(target) {
 			receiveIDType = "chat_id"
+		} else if isEmail(target) {
+			receiveIDType = "email"
 		} else {
 			// 可能是用户名，尝试搜索
 			user, err := e.Client.SearchUserByName(ctx, token, target)
+			// 严格模式下不接受通讯录的模糊匹配
+			if err == nil && user != nil && strictFrom(ctx) && user.Name != target {
+				err = fmt.Errorf("%w: 通讯录中没有姓名为 %s 的用户", model.ErrRecipientNotAllowed, target)
+			}
 			if err == nil && user != nil {
 				if user.OpenID != "" {
 					resolvedTarget = user.OpenID
@@ -282,6 +565,8 @@ func (e *FeishuExecutor) sendToTarget(ctx context.Context, token, target, target
 					resolvedTarget = user.UserID
 					receiveIDType = "user_id"
 				}
+			} else if errors.Is(err, model.ErrRecipientNotAllowed) {
+				return model.SendResult{TargetID: target, Error: err.Error()}
 			} else {
 				return model.SendResult{
 					TargetID: target,
@@ -292,6 +577,16 @@ func (e *FeishuExecutor) sendToTarget(ctx context.Context, token, target, target
 		}
 	}
 
+	if receiveIDType == "chat_id" {
+		// 发往请求所在的会话时回复到话题中
+		if root := threadFrom(ctx).feishuRoot(resolvedTarget); root != "" {
+			msgID, err := e.Client.ReplyMessage(ctx, token, root, msgType, content, true)
+			if err != nil {
+				return model.SendResult{TargetID: target, Error: err.Error()}
+			}
+			return model.SendResult{TargetID: target, Success: true, MsgID: msgID, ChatID: resolvedTarget}
+		}
+	}
 	result := e.Client.SendMessage(ctx, token, feishu.SendMessageRequest{
 		ReceiveID:     resolvedTarget,
 		ReceiveIDType: receiveIDType,
@@ -311,6 +606,7 @@ func (e *FeishuExecutor) sendToTarget(ctx context.Context, token, target, target
 		TargetID: target,
 		Success:  true,
 		MsgID:    result.MessageID,
+		ChatID:   result.ChatID,
 	}
 }
 
@@ -327,7 +623,8 @@ func (e *FeishuExecutor) buildSendMessageSummary(results []model.SendResult, _ m
 	}
 
 	summary := model.ActionSummary{
-		Type: "feishu_message",
+		Type:    "feishu_message",
+		Outputs: sendResultOutputs(results),
 	}
 
 	if len(results) == 1 {
@@ -351,3 +648,9 @@ func (e *FeishuExecutor) buildSendMessageSummary(results []model.SendResult, _ m
 func isChatID(id string) bool {
 	return len(id) > 3 && id[:3] == "oc_"
 }
+
+// isEmail 判断是否是邮箱（飞书支持以 email 作为 receive_id_type 发送私聊）
+func isEmail(id string) bool {
+	at := strings.Index(id, "@")
+	return at > 0 && strings.Contains(id[at+1:], ".")
+}
@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+const (
+	// folderTreeMaxFolders 一次最多创建的文件夹数
+	folderTreeMaxFolders = 30
+	// folderTreeMaxDepth 目录结构的最大层数（不含根目录）
+	folderTreeMaxDepth = 3
+)
+
+// ExecuteCreateFolderTree 一次创建多层目录结构（"给新项目建标准目录：需求/设计/会议纪要/发布"）
+// params: root（顶层目录名，可选）, folder_name / folder_token（上级目录）, template（配置的目录模板名）,
+// folders（大模型给出的目录路径，如 ["需求", "设计/原型"]；指定 template 时忽略）
+func (e *FeishuExecutor) ExecuteCreateFolderTree(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	paths, templateName, err := e.folderTreePaths(spec)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_create_folder_tree: %w", err)
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	parentToken, parentName, err := e.resolveParentFolder(ctx, token, spec)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_create_folder_tree: %w", err)
+	}
+
+	root, _ := spec.Params["root"].(string)
+	root = strings.TrimSpace(root)
+	summary := model.ActionSummary{Type: "feishu_folder_tree", Target: root}
+	if root != "" {
+		if parentToken, err = e.Client.CreateFolder(ctx, token, parentToken, root); err != nil {
+			return model.ActionSummary{}, err
+		}
+		summary.ID = parentToken
+	}
+
+	// 按路径逐层创建，上级创建失败时跳过其下的目录
+	created := map[string]string{"": parentToken}
+	var done, failed []string
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			failed = append(failed, fmt.Sprintf("%s（%v）", path, err))
+			continue
+		}
+		parent, name := "", path
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			parent, name = path[:i], path[i+1:]
+		}
+		parentID, ok := created[parent]
+		if !ok {
+			failed = append(failed, fmt.Sprintf("%s（上级目录未创建）", path))
+			continue
+		}
+		id, err := e.Client.CreateFolder(ctx, token, parentID, name)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s（%v）", path, err))
+			continue
+		}
+		created[path] = id
+		done = append(done, path)
+	}
+	if len(done) == 0 {
+		return model.ActionSummary{}, fmt.Errorf("feishu_create_folder_tree: no folder created: %s", strings.Join(failed, "；"))
+	}
+
+	if summary.Target == "" {
+		summary.Target = templateName
+	}
+	if summary.Target == "" {
+		summary.Target = strings.Join(topLevel(done), "/")
+	}
+	summary.Outputs = map[string]string{"folders": strings.Join(done, ",")}
+	if summary.ID != "" {
+		summary.Outputs["folder_id"] = summary.ID
+		if summary.URL = e.Client.DocURL("drive/folder", summary.ID); summary.URL != "" {
+			summary.Outputs["folder_url"] = summary.URL
+		}
+	}
+	var urls []string
+	for _, path := range done {
+		urls = append(urls, e.Client.DocURL("drive/folder", created[path]))
+	}
+	summary.Outputs["folder_urls"] = strings.Join(urls, ",")
+	if parentName != "" {
+		summary.Outputs["parent_name"] = parentName
+	}
+	summary.Note = fmt.Sprintf("已创建 %d 个目录：%s", len(done), strings.Join(done, "、"))
+	if len(failed) > 0 {
+		summary.Outputs["failed"] = strings.Join(failed, "；")
+		summary.Unverified = "部分目录创建失败：" + summary.Outputs["failed"]
+	}
+	return summary, nil
+}
+
+// folderTreePaths 要创建的目录路径（上级在前），返回使用的模板名；模板优先于 folders
+func (e *FeishuExecutor) folderTreePaths(spec model.ActionSpec) ([]string, string, error) {
+	raw := model.StringList(spec.Params["folders"])
+	name, _ := spec.Params["template"].(string)
+	if name != "" || len(raw) == 0 {
+		tmplName, tmpl, err := e.folderTemplate(name)
+		if err != nil && len(raw) == 0 {
+			return nil, "", err
+		}
+		if err == nil {
+			raw, name = tmpl, tmplName
+		}
+	}
+	paths, err := normalizeFolderPaths(raw)
+	return paths, name, err
+}
+
+// folderTemplate 按名称查找配置的目录模板（完全一致优先，其次包含）；只配置了一个模板且未指定时用该模板
+func (e *FeishuExecutor) folderTemplate(name string) (string, []string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" && len(e.Cfg.FolderTemplates) == 1 {
+		for n, paths := range e.Cfg.FolderTemplates {
+			return n, paths, nil
+		}
+	}
+	if paths, ok := e.Cfg.FolderTemplates[name]; ok && name != "" {
+		return name, paths, nil
+	}
+	names := make([]string, 0, len(e.Cfg.FolderTemplates))
+	for n := range e.Cfg.FolderTemplates {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if name != "" && (strings.Contains(n, name) || strings.Contains(name, n)) {
+			return n, e.Cfg.FolderTemplates[n], nil
+		}
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("%w: folders or template is required (templates: %s)", model.ErrInvalidParams, strings.Join(names, "、"))
+	}
+	return "", nil, fmt.Errorf("%w: folder template %q not configured (templates: %s)", model.ErrInvalidParams, name, strings.Join(names, "、"))
+}
+
+// normalizeFolderPaths 规范化目录路径：去掉多余的斜杠与空白、补上隐含的上级目录、去重并按层级排序，
+// 超出层数或数量上限时返回错误
+func normalizeFolderPaths(raw []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, p := range raw {
+		var parts []string
+		for _, part := range strings.Split(p, "/") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) > folderTreeMaxDepth {
+			return nil, fmt.Errorf("%w: %q is deeper than %d levels", model.ErrInvalidParams, p, folderTreeMaxDepth)
+		}
+		for i := range parts {
+			path := strings.Join(parts[:i+1], "/")
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("%w: no folders to create", model.ErrInvalidParams)
+	}
+	if len(paths) > folderTreeMaxFolders {
+		return nil, fmt.Errorf("%w: too many folders: %d > %d", model.ErrLimitExceeded, len(paths), folderTreeMaxFolders)
+	}
+	// 稳定排序：上级目录先于下级目录，同层保持给出的顺序
+	sort.SliceStable(paths, func(i, j int) bool {
+		return strings.Count(paths[i], "/") < strings.Count(paths[j], "/")
+	})
+	return paths, nil
+}
+
+// topLevel 路径中的第一层目录
+func topLevel(paths []string) []string {
+	var out []string
+	for _, p := range paths {
+		if !strings.Contains(p, "/") {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,156 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// 分组排除："发给产品部除了实习生"时 send_message 带 exclude，展开联系人分组时跳过命中的成员。
+// 排除条件可以是人员类型（实习生、外包等）、职位中的词（如"经理"）或成员的姓名、ID；
+// 成员信息优先取分组配置，未填写时从身份映射（飞书通讯录同步的职位、人员类型）中查找
+
+// memberExcluded 成员是否命中任一排除条件
+func memberExcluded(m model.AliasMember, exclude []string) bool {
+	for _, x := range exclude {
+		x = strings.TrimSpace(x)
+		switch {
+		case x == "":
+		case x == m.ID, m.Name != "" && strings.EqualFold(x, m.Name):
+			return true
+		case m.EmploymentType != "" && model.NormalizeEmploymentType(x) == model.NormalizeEmploymentType(m.EmploymentType):
+			return true
+		case m.Title != "" && strings.Contains(strings.ToLower(m.Title), strings.ToLower(x)):
+			return true
+		}
+	}
+	return false
+}
+
+// exclusionPreview 按排除条件展开分组后的接收方
+type exclusionPreview struct {
+	groups   []string // 涉及的联系人分组
+	terms    []string // 用户说出的排除条件
+	kept     []string // 最终接收方（展示名）
+	excluded []string // 被排除的成员（展示名）
+}
+
+// GroupExclusionHook send_message 带排除条件且目标含联系人分组时，按身份映射补全成员的姓名、职位与人员类型后筛选，
+// 把命中的成员 ID 写入 exclude（执行时据此跳过）；未确认时返回 ErrConfirmationRequired，摘要中列出最终接收方与被排除的成员。
+// 排除后没有接收方时直接失败。identities 可选
+func (e *Executor) GroupExclusionHook(identities IdentityDirectory) Hook {
+	return Hook{
+		Name: "group_exclusion",
+		BeforeAction: func(ctx context.Context, spec *model.ActionSpec, req *model.ASRRequest) (context.Context, error) {
+			preview, ok := e.applyExclusions(ctx, identities, spec, req)
+			if !ok {
+				return ctx, nil
+			}
+			if len(preview.kept) == 0 {
+				return ctx, fmt.Errorf("%s: %w: 「%s」排除%s后没有接收方", spec.Type, model.ErrInvalidParams,
+					strings.Join(preview.groups, "、"), strings.Join(preview.terms, "、"))
+			}
+			if !spec.Confirmed {
+				return ctx, model.ErrConfirmationRequired
+			}
+			return ctx, nil
+		},
+		AfterAction: func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, summary *model.ActionSummary, err error) {
+			// 钩子拦截时动作未执行，由钩子补上待确认说明
+			if !errors.Is(err, model.ErrConfirmationRequired) || summary.Note != "" {
+				return
+			}
+			preview, ok := e.applyExclusions(ctx, identities, &spec, req)
+			if !ok || len(preview.kept) == 0 {
+				return
+			}
+			summary.Type, summary.Target = "message", strings.Join(preview.groups, "、")
+			if len(preview.excluded) == 0 {
+				summary.Note = fmt.Sprintf("「%s」中没有符合「%s」的成员，将发送给全部 %d 人：%s，确认发送吗？",
+					summary.Target, strings.Join(preview.terms, "、"), len(preview.kept), strings.Join(preview.kept, "、"))
+				return
+			}
+			summary.Note = fmt.Sprintf("将发送给 %d 人：%s（已排除「%s」%d 人：%s），确认发送吗？",
+				len(preview.kept), strings.Join(preview.kept, "、"), strings.Join(preview.terms, "、"), len(preview.excluded), strings.Join(preview.excluded, "、"))
+		},
+	}
+}
+
+// applyExclusions 展开 spec 中的联系人分组并按排除条件筛选，命中的成员 ID 追加到 exclude；
+// 不是带排除条件的分组发送（或沙箱模式）时 ok 为 false
+func (e *Executor) applyExclusions(ctx context.Context, identities IdentityDirectory, spec *model.ActionSpec, req *model.ASRRequest) (preview exclusionPreview, ok bool) {
+	if spec.Type != model.ActionTypeSendMessage || e.Sandboxed(req) {
+		return preview, false
+	}
+	params := model.ParseSendMessageParams(spec.Params)
+	if len(params.Exclude) == 0 {
+		return preview, false
+	}
+	exclude := slices.Clone(params.Exclude)
+	seen := make(map[string]bool)
+	for _, t := range params.Targets {
+		members, found := e.aliases.Members(tenantOf(req), t)
+		if !found {
+			preview.kept = append(preview.kept, t)
+			continue
+		}
+		preview.groups = append(preview.groups, t)
+		for _, m := range members {
+			if m.ID == "" || seen[m.ID] {
+				continue
+			}
+			seen[m.ID] = true
+			m = memberInfo(ctx, identities, m, req)
+			name := firstNonEmpty(m.Name, m.ID)
+			if !memberExcluded(m, params.Exclude) {
+				preview.kept = append(preview.kept, name)
+				continue
+			}
+			preview.excluded = append(preview.excluded, name)
+			if !slices.Contains(exclude, m.ID) {
+				exclude = append(exclude, m.ID)
+			}
+		}
+	}
+	if len(preview.groups) == 0 {
+		return exclusionPreview{}, false
+	}
+	// 确认后重新执行时 exclude 中已有上次写入的成员 ID，展示时只保留用户说出的条件
+	for _, x := range params.Exclude {
+		if !seen[x] {
+			preview.terms = append(preview.terms, x)
+		}
+	}
+	if len(exclude) > len(params.Exclude) {
+		values := make([]any, len(exclude))
+		for i, x := range exclude {
+			values[i] = x
+		}
+		out := make(map[string]any, len(spec.Params))
+		for k, v := range spec.Params {
+			out[k] = v
+		}
+		out["exclude"] = values
+		spec.Params = out
+	}
+	return preview, true
+}
+
+// memberInfo 分组成员未填写的姓名、职位、人员类型从身份映射中按成员 ID 补全
+func memberInfo(ctx context.Context, identities IdentityDirectory, m model.AliasMember, req *model.ASRRequest) model.AliasMember {
+	if identities == nil || (m.Name != "" && m.Title != "" && m.EmploymentType != "") {
+		return m
+	}
+	u, err := identities.Lookup(ctx, tenantOf(req), m.ID)
+	if err != nil {
+		return m
+	}
+	m.Name = firstNonEmpty(m.Name, u.Name)
+	m.Title = firstNonEmpty(m.Title, u.Title)
+	m.EmploymentType = firstNonEmpty(m.EmploymentType, u.EmploymentType)
+	return m
+}
@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/trace"
+)
+
+// Handler 执行单条动作
+type Handler func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error)
+
+// Hook 动作执行前后的扩展点，用于指标、策略检查、审计等横切逻辑；字段均可选
+type Hook struct {
+	Name string
+	// BeforeAction 在动作执行前按注册顺序调用，可修改 spec，返回的 ctx 传给后续钩子与动作（如记录开始时间）；
+	// 返回错误时动作不执行，该错误作为动作结果
+	BeforeAction func(ctx context.Context, spec *model.ActionSpec, req *model.ASRRequest) (context.Context, error)
+	// AfterAction 在动作执行后（含被 BeforeAction 拦截）按注册的逆序调用，可补充 summary
+	AfterAction func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, summary *model.ActionSummary, err error)
+}
+
+// Use 注册钩子，须在开始处理请求前调用
+func (e *Executor) Use(hooks ...Hook) {
+	e.hooks = append(e.hooks, hooks...)
+}
+
+// Intercept 经过已注册的钩子执行 run；Execute 与服务层自行处理的动作（如 query_status）都由此执行
+func (e *Executor) Intercept(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, run Handler) (model.ActionSummary, error) {
+	var summary model.ActionSummary
+	var err error
+	for _, h := range e.hooks {
+		if h.BeforeAction == nil {
+			continue
+		}
+		var next context.Context
+		if next, err = h.BeforeAction(ctx, &spec, req); err != nil {
+			break
+		}
+		if next != nil {
+			ctx = next
+		}
+	}
+	if err == nil {
+		end := trace.Start(ctx, "action."+spec.TaskID+"."+spec.Type)
+		summary, err = run(ctx, spec, req)
+		end(err)
+	}
+	for i := len(e.hooks) - 1; i >= 0; i-- {
+		if h := e.hooks[i]; h.AfterAction != nil {
+			h.AfterAction(ctx, spec, req, &summary, err)
+		}
+	}
+	return summary, err
+}
+
+// actionStartKey ActionLogHook 在 ctx 中记录动作开始时间
+type actionStartKey struct{}
+
+// ActionLogHook 记录每个动作的类型、请求人、耗时与结果
+func ActionLogHook() Hook {
+	return Hook{
+		Name: "action_log",
+		BeforeAction: func(ctx context.Context, _ *model.ActionSpec, _ *model.ASRRequest) (context.Context, error) {
+			return context.WithValue(ctx, actionStartKey{}, time.Now()), nil
+		},
+		AfterAction: func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, summary *model.ActionSummary, err error) {
+			var elapsed time.Duration
+			if start, ok := ctx.Value(actionStartKey{}).(time.Time); ok {
+				elapsed = time.Since(start).Round(time.Millisecond)
+			}
+			var user string
+			if req != nil {
+				user = req.UserID
+			}
+			if err != nil {
+				log.Printf("action %s %s user=%s %v failed: %v", spec.TaskID, spec.Type, user, elapsed, err)
+				return
+			}
+			if summary.Unverified != "" {
+				log.Printf("action %s %s user=%s %v unverified target=%q: %s", spec.TaskID, spec.Type, user, elapsed, summary.Target, summary.Unverified)
+				return
+			}
+			if old := summary.Outputs["old_title"]; old != "" {
+				// 改名记下原名称，便于追查
+				log.Printf("action %s %s user=%s %v ok target=%q old=%q", spec.TaskID, spec.Type, user, elapsed, summary.Target, old)
+				return
+			}
+			log.Printf("action %s %s user=%s %v ok target=%q", spec.TaskID, spec.Type, user, elapsed, summary.Target)
+		},
+	}
+}
+
+// DenyActionsHook 拒绝执行 types 中的动作类型（如本环境禁止 feishu_transfer_owner），作为技能开关之外的兜底
+func DenyActionsHook(types []string) Hook {
+	denied := make(map[string]bool, len(types))
+	for _, t := range types {
+		denied[t] = true
+	}
+	return Hook{
+		Name: "deny_actions",
+		BeforeAction: func(ctx context.Context, spec *model.ActionSpec, _ *model.ASRRequest) (context.Context, error) {
+			if denied[spec.Type] {
+				return ctx, fmt.Errorf("%w: %s is denied by policy", model.ErrActionNotSupport, spec.Type)
+			}
+			return ctx, nil
+		},
+	}
+}
@@ -0,0 +1,227 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// fileHTTPClient 下载导入源文件用。链接来自用户或大模型，只允许 http/https，
+// 并在建立连接时（而非解析域名时）拒绝内网、回环与链路本地地址，DNS 重绑定与重定向也无法绕过；
+// 不走环境变量中的代理，否则检查的将是代理地址
+var fileHTTPClient = &http.Client{
+	Timeout: 60 * time.Second,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 10 * time.Second, Control: publicAddressOnly}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return checkDownloadURL(req.URL)
+	},
+}
+
+// errBlockedAddress 下载地址为内网、回环或链路本地地址
+var errBlockedAddress = errors.New("address not allowed")
+
+// publicAddressOnly net.Dialer.Control：只允许连接公网地址
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %w: %s", model.ErrInvalidParams, errBlockedAddress, host)
+	}
+	return nil
+}
+
+// cgnatNet 运营商级 NAT 共享地址（100.64.0.0/10），云厂商常用于内部服务
+var cgnatNet = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || cgnatNet.Contains(ip))
+}
+
+// checkDownloadURL 只允许 http/https 链接
+func checkDownloadURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: unsupported url %q", model.ErrInvalidParams, u.Redacted())
+	}
+	return nil
+}
+
+// importTypes 源文件扩展名到默认导入类型，未列出的扩展名按普通文件上传
+var importTypes = map[string]string{
+	"docx":     "docx",
+	"doc":      "docx",
+	"txt":      "docx",
+	"md":       "docx",
+	"markdown": "docx",
+	"html":     "docx",
+	"xlsx":     "sheet",
+	"xls":      "sheet",
+	"csv":      "sheet",
+}
+
+// ExecuteImportFile 将链接或附件中的文件存入云空间；可转为在线文档的默认导入为文档/表格
+// params: source(链接、附件名或序号), file_name, folder_name, import_as(docx|sheet|bitable|file)
+func (e *FeishuExecutor) ExecuteImportFile(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	source, _ := spec.Params["source"].(string)
+	name, data, err := loadImportSource(ctx, source, req)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("import_file: %w", err)
+	}
+	if fileName, _ := spec.Params["file_name"].(string); fileName != "" {
+		if path.Ext(fileName) == "" {
+			fileName += path.Ext(name)
+		}
+		name = fileName
+	}
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+	importAs, _ := spec.Params["import_as"].(string)
+	if importAs == "" {
+		importAs = importTypes[ext]
+	}
+
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	folderNameParam, _ := spec.Params["folder_name"].(string)
+	folderToken, folderName := e.resolveDocFolder(ctx, token, tenantOf(req), "", folderNameParam, name)
+
+	summary := model.ActionSummary{Target: name}
+	summary.Outputs = map[string]string{"folder_token": folderToken}
+	switch importAs {
+	case "docx", "sheet", "bitable":
+		title := strings.TrimSuffix(name, path.Ext(name))
+		doc, err := e.Client.ImportFile(ctx, token, folderToken, title, ext, importAs, data)
+		if err != nil {
+			return model.ActionSummary{}, err
+		}
+		summary.Type = "feishu_import"
+		summary.ID = doc.Token
+		summary.URL = doc.URL
+		summary.Outputs["doc_id"] = doc.Token
+		summary.Outputs["doc_url"] = doc.URL
+	default:
+		fileToken, err := e.Client.UploadFile(ctx, token, folderToken, name, data)
+		if err != nil {
+			return model.ActionSummary{}, err
+		}
+		summary.Type = "feishu_upload"
+		summary.ID = fileToken
+		summary.URL = e.Client.DocURL("file", fileToken)
+		summary.Outputs["file_token"] = fileToken
+		summary.Outputs["file_url"] = summary.URL
+	}
+	if folderName != "" {
+		summary.Outputs["folder_name"] = folderName
+		summary.Note = fmt.Sprintf("已存放至「%s」目录", folderName)
+	}
+	return summary, nil
+}
+
+// loadImportSource 读取导入源：http(s) 链接直接下载，否则按名称或序号匹配请求附件（只有一个附件时可留空）
+func loadImportSource(ctx context.Context, source string, req *model.ASRRequest) (string, []byte, error) {
+	source = strings.TrimSpace(source)
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return downloadFile(ctx, source)
+	}
+	var attachments []model.Attachment
+	if req != nil {
+		attachments = req.Attachments
+	}
+	att, ok := findAttachment(attachments, source)
+	if !ok {
+		return "", nil, fmt.Errorf("%w: source %q is neither a url nor an attachment", model.ErrInvalidParams, source)
+	}
+	if len(att.Data) > 0 {
+		return att.Name, att.Data, nil
+	}
+	if att.URL == "" {
+		return "", nil, fmt.Errorf("%w: attachment %q has no content", model.ErrInvalidParams, att.Name)
+	}
+	name, data, err := downloadFile(ctx, att.URL)
+	if att.Name != "" {
+		name = att.Name
+	}
+	return name, data, err
+}
+
+// findAttachment 按名称或从 1 开始的序号查找附件
+func findAttachment(attachments []model.Attachment, source string) (model.Attachment, bool) {
+	if source == "" && len(attachments) == 1 {
+		return attachments[0], true
+	}
+	for _, a := range attachments {
+		if a.Name == source {
+			return a, true
+		}
+	}
+	if i, err := strconv.Atoi(source); err == nil && i >= 1 && i <= len(attachments) {
+		return attachments[i-1], true
+	}
+	return model.Attachment{}, false
+}
+
+// downloadFile 下载文件，文件名取 Content-Disposition，其次取链接路径
+func downloadFile(ctx context.Context, rawURL string) (string, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", model.ErrInvalidParams, err)
+	}
+	if err := checkDownloadURL(req.URL); err != nil {
+		return "", nil, err
+	}
+	resp, err := fileHTTPClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("download %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", nil, fmt.Errorf("download %s: http status %d", rawURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, feishu.UploadFileMaxSize+1))
+	if err != nil {
+		return "", nil, fmt.Errorf("download %s: %w", rawURL, err)
+	}
+	if len(data) > feishu.UploadFileMaxSize {
+		return "", nil, fmt.Errorf("download %s: file exceeds %d bytes", rawURL, feishu.UploadFileMaxSize)
+	}
+
+	var name string
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		name = params["filename"]
+	}
+	if name == "" {
+		if u, err := url.Parse(rawURL); err == nil {
+			name, _ = url.PathUnescape(path.Base(u.Path))
+		}
+	}
+	if name == "" || name == "/" || name == "." {
+		name = "file"
+	}
+	return name, data, nil
+}
@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sayso-agent/internal/model"
+)
+
+func TestDownloadFileRefusesInternalURLs(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte("secret"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "loopback", url: srv.URL + "/file.txt"},
+		{name: "localhost", url: "http://localhost:" + portOf(t, srv.URL) + "/file.txt"},
+		{name: "metadata", url: "http://169.254.169.254/latest/meta-data/"},
+		{name: "private", url: "http://10.0.0.1/file.txt"},
+		{name: "file scheme", url: "file:///etc/passwd"},
+		{name: "ftp scheme", url: "ftp://example.com/file.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := downloadFile(context.Background(), tt.url)
+			if !errors.Is(err, model.ErrInvalidParams) {
+				t.Fatalf("downloadFile(%q) err = %v, want ErrInvalidParams", tt.url, err)
+			}
+		})
+	}
+	if hits != 0 {
+		t.Errorf("internal server was requested %d times", hits)
+	}
+}
+
+// 公网地址放行后跳转到内网地址同样被拒绝
+func TestDownloadFileRefusesRedirectToInternal(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/", nil)
+	if err := fileHTTPClient.CheckRedirect(req, nil); err != nil {
+		t.Fatalf("redirect check for http url: %v", err)
+	}
+	if err := publicAddressOnly("tcp4", "127.0.0.1:80", nil); !errors.Is(err, errBlockedAddress) {
+		t.Errorf("dial 127.0.0.1 err = %v, want errBlockedAddress", err)
+	}
+	req, _ = http.NewRequest(http.MethodGet, "file:///etc/passwd", nil)
+	if err := fileHTTPClient.CheckRedirect(req, nil); !errors.Is(err, model.ErrInvalidParams) {
+		t.Errorf("redirect to file scheme err = %v, want ErrInvalidParams", err)
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"93.184.216.34", true},
+		{"2606:4700::1111", true},
+	}
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func portOf(t *testing.T, rawURL string) string {
+	t.Helper()
+	_, port, err := net.SplitHostPort(rawURL[len("http://"):])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return port
+}
@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// KnowledgeBase 知识库问答（由 kb.KnowledgeBase 实现）
+type KnowledgeBase interface {
+	Ask(ctx context.Context, question string) (model.KBAnswer, error)
+}
+
+// executeAskKB 检索知识库回答问题；未指定 targets 时回答只进入回复，否则连同引用文档链接以消息发给 targets
+// params: question, platform, target_type, targets
+func (e *Executor) executeAskKB(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if e.kb == nil {
+		return model.ActionSummary{}, fmt.Errorf("%w: knowledge base not configured", model.ErrActionNotSupport)
+	}
+	question, _ := spec.Params["question"].(string)
+	answer, err := e.kb.Ask(ctx, question)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("ask_kb: %w", err)
+	}
+	var titles, urls []string
+	for _, s := range answer.Sources {
+		titles = append(titles, s.Title)
+		urls = append(urls, s.URL)
+	}
+	summary := model.ActionSummary{Type: "kb_answer", Target: question, Note: answer.Text}
+	summary.Outputs = map[string]string{"answer": answer.Text, "sources": strings.Join(titles, ","), "source_urls": strings.Join(urls, ",")}
+	if len(answer.Sources) > 0 {
+		summary.URL = answer.Sources[0].URL
+		// 回复中说明出处，链接在 actions 中返回
+		summary.Outputs["answer"] = fmt.Sprintf("%s（出处：《%s》）", answer.Text, strings.Join(titles, "》《"))
+	}
+
+	params := model.ParseSendMessageParams(spec.Params)
+	if len(params.Targets) == 0 {
+		return summary, nil
+	}
+	// 发给他人时经过完整的钩子（接收方策略等），与直接发消息一致
+	text := answer.Text
+	for _, s := range answer.Sources {
+		text += fmt.Sprintf("\n《%s》%s", s.Title, s.URL)
+	}
+	send := model.ActionSpec{Type: model.ActionTypeSendMessage, Confirmed: spec.Confirmed, Params: map[string]any{
+		"platform":     params.Platform,
+		"message_type": "text",
+		"target_type":  params.TargetType,
+		"targets":      spec.Params["targets"],
+		"content":      map[string]any{"text": text},
+	}}
+	sent, err := e.Execute(ctx, send, req)
+	for k, v := range sent.Outputs {
+		summary.Outputs[k] = v
+	}
+	if errors.Is(err, model.ErrConfirmationRequired) {
+		summary.Note = sent.Note
+		return summary, err
+	}
+	if err != nil {
+		return summary, fmt.Errorf("ask_kb: send answer: %w", err)
+	}
+	if sent.Note != "" {
+		summary.Note += "；" + sent.Note
+	}
+	return summary, nil
+}
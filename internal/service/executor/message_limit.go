@@ -0,0 +1,197 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"sayso-agent/internal/model"
+)
+
+// 超长消息的处理方式
+const (
+	overflowSplit    = "split"    // 拆成多条，带 (1/3) 序号
+	overflowTruncate = "truncate" // 截断并以省略号结尾
+	overflowDoc      = "doc"      // 全文写入飞书文档，消息只发预览和链接
+)
+
+// 各平台单条消息的默认字符上限：飞书文本约 150KB，按中文保守取 10000 字；Slack text 上限 40000，
+// Block Kit 的 section 文本上限 3000；Discord 正文上限 2000，Embed 描述上限 4096
+const (
+	defaultFeishuMaxChars  = 10000
+	defaultSlackMaxChars   = 40000
+	slackBlockMaxChars     = 3000
+	defaultDiscordMaxChars = 2000
+	discordEmbedMaxChars   = 4096
+)
+
+// docPreviewChars 转为文档时消息中保留的预览长度
+const docPreviewChars = 200
+
+// messageLimit 返回平台当前消息类型的字符上限及超长处理方式
+func (e *Executor) messageLimit(platform, messageType string) (int, string) {
+	switch platform {
+	case "feishu":
+		return positiveOr(e.feishu.Cfg.MaxMessageChars, defaultFeishuMaxChars), e.feishu.Cfg.MessageOverflow
+	case "slack":
+		limit := positiveOr(e.slack.Cfg.MaxMessageChars, defaultSlackMaxChars)
+		if messageType != "" && messageType != "text" && limit > slackBlockMaxChars {
+			limit = slackBlockMaxChars
+		}
+		return limit, e.slack.Cfg.MessageOverflow
+	case "discord":
+		// 上限为平台硬限制，配置只能调小；卡片类消息的正文在 Embed 描述中
+		hard := defaultDiscordMaxChars
+		if messageType != "" && messageType != "text" {
+			hard = discordEmbedMaxChars
+		}
+		return min(positiveOr(e.discord.Cfg.MaxMessageChars, hard), hard), e.discord.Cfg.MessageOverflow
+	}
+	return 0, ""
+}
+
+// fitMessage 按平台上限处理超长正文，返回依次发送的消息；未超长时原样返回
+func (e *Executor) fitMessage(ctx context.Context, platform string, spec model.ActionSpec, req *model.ASRRequest) ([]model.ActionSpec, error) {
+	params := model.ParseSendMessageParams(spec.Params)
+	limit, overflow := e.messageLimit(platform, params.MessageType)
+	text := params.Content.Text
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return []model.ActionSpec{spec}, nil
+	}
+
+	switch overflow {
+	case overflowTruncate:
+		return []model.ActionSpec{withMessageText(spec, truncateRunes(text, limit-1)+"…", "")}, nil
+	case overflowDoc:
+		if e.feishu.Client != nil && e.feishu.Cfg.Enabled {
+			return e.messageAsDoc(ctx, spec, params, req)
+		}
+		// 飞书未启用时无法建文档，退化为拆分
+	}
+
+	// 预留 "(10/10) " 序号的长度
+	parts := splitMessage(text, limit-8)
+	specs := make([]model.ActionSpec, len(parts))
+	for i, part := range parts {
+		part = fmt.Sprintf("(%d/%d) %s", i+1, len(parts), part)
+		if i < len(parts)-1 {
+			// 链接卡片等只保留在最后一条，前面的部分以纯文本发送
+			specs[i] = withMessageText(spec, part, "text")
+		} else {
+			specs[i] = withMessageText(spec, part, "")
+		}
+	}
+	return specs, nil
+}
+
+// messageAsDoc 将全文写入飞书文档，返回带预览和文档链接的消息
+func (e *Executor) messageAsDoc(ctx context.Context, spec model.ActionSpec, params model.SendMessageParams, req *model.ASRRequest) ([]model.ActionSpec, error) {
+	title := params.Content.Title
+	if title == "" {
+		title = truncateRunes(firstLine(params.Content.Text), 30)
+	}
+	doc, err := e.feishu.ExecuteCreateDoc(ctx, model.ActionSpec{
+		Type:      model.ActionTypeCreateDoc,
+		Params:    map[string]any{"title": title, "content": params.Content.Text},
+		Confirmed: true,
+	}, req)
+	if err != nil {
+		return nil, fmt.Errorf("long message to doc: %w", err)
+	}
+	preview := truncateRunes(params.Content.Text, docPreviewChars) + "…"
+	out := withMessageText(spec, preview, "link_card")
+	content := out.Params["content"].(map[string]any)
+	content["title"] = title
+	content["url"] = doc.URL
+	content["description"] = "全文过长，已转为文档"
+	return []model.ActionSpec{out}, nil
+}
+
+// withMessageText 复制 spec 并替换正文；messageType 非空时同时替换消息类型
+func withMessageText(spec model.ActionSpec, text, messageType string) model.ActionSpec {
+	params := make(map[string]any, len(spec.Params))
+	for k, v := range spec.Params {
+		params[k] = v
+	}
+	content := make(map[string]any)
+	if old, ok := spec.Params["content"].(map[string]any); ok {
+		for k, v := range old {
+			content[k] = v
+		}
+	}
+	content["text"] = text
+	params["content"] = content
+	if messageType != "" {
+		params["message_type"] = messageType
+	}
+	spec.Params = params
+	return spec
+}
+
+// splitMessage 按字符数拆分正文，优先在段落、换行、句末处断开
+func splitMessage(text string, limit int) []string {
+	if limit <= 0 {
+		limit = 1
+	}
+	var parts []string
+	rest := []rune(text)
+	for len(rest) > limit {
+		cut := splitPoint(rest[:limit])
+		parts = append(parts, strings.TrimSpace(string(rest[:cut])))
+		rest = rest[cut:]
+	}
+	if s := strings.TrimSpace(string(rest)); s != "" || len(parts) == 0 {
+		parts = append(parts, s)
+	}
+	return parts
+}
+
+// splitPoint 在窗口后半段寻找最靠后的断点，找不到时硬切
+func splitPoint(window []rune) int {
+	min := len(window) / 2
+	for _, seps := range []string{"\n\n", "\n", "。！？.!?；;", " "} {
+		for i := len(window) - 1; i >= min; i-- {
+			if seps == "\n\n" {
+				if i > 0 && window[i] == '\n' && window[i-1] == '\n' {
+					return i + 1
+				}
+				continue
+			}
+			if strings.ContainsRune(seps, window[i]) {
+				return i + 1
+			}
+		}
+	}
+	return len(window)
+}
+
+// truncateRunes 截取前 n 个字符
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if n < 0 {
+		n = 0
+	}
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// firstLine 返回第一行非空文本
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// positiveOr 返回 v，v 不大于 0 时返回 def
+func positiveOr(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,138 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// NotesSummarizer 会议纪要整理器（由 llm.MinutesSummarizer 等实现，避免循环依赖）
+type NotesSummarizer interface {
+	SummarizeMeeting(ctx context.Context, title, transcript string) (model.MeetingNotes, error)
+}
+
+// ExecuteMinutesNotes 将妙记整理为纪要文档，并为每条待办创建飞书任务
+func (e *FeishuExecutor) ExecuteMinutesNotes(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	if e.Summarizer == nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_minutes_notes: summarizer not configured")
+	}
+	minuteURL, _ := spec.Params["minute_url"].(string)
+	minuteToken, err := feishu.ParseMinuteToken(minuteURL)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_minutes_notes: %w: %v", model.ErrInvalidParams, err)
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+
+	info, err := e.Client.GetMinute(ctx, token, minuteToken)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	transcript, err := e.Client.GetMinuteTranscript(ctx, token, minuteToken)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	notes, err := e.Summarizer.SummarizeMeeting(ctx, info.Title, transcript)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("summarize minutes: %w", err)
+	}
+
+	title, _ := spec.Params["title"].(string)
+	if title == "" {
+		title = notes.Title
+	}
+	if title == "" {
+		title = info.Title + " 会议纪要"
+	}
+	title = renderTitle(e.Cfg.TitleTemplate, title, authorOf(req), time.Now())
+	folderNameParam, _ := spec.Params["folder_name"].(string)
+	folderToken, folderName := e.resolveDocFolder(ctx, token, tenantOf(req), "", folderNameParam, title)
+	docID, err := e.Client.CreateDoc(ctx, token, folderToken, title, "")
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+
+	var noteParts []string
+	if err := e.Client.AppendDocBlocks(ctx, token, docID, meetingNotesBlocks(notes, info)); err != nil {
+		noteParts = append(noteParts, fmt.Sprintf("纪要写入失败: %v", err))
+	}
+
+	taskIDs, failed := e.createTodoTasks(ctx, token, title, notes.Todos)
+	if len(notes.Todos) > 0 {
+		note := fmt.Sprintf("已创建 %d/%d 个待办任务", len(taskIDs), len(notes.Todos))
+		if len(failed) > 0 {
+			note += "，失败：" + strings.Join(failed, "、")
+		}
+		noteParts = append(noteParts, note)
+	}
+	if folderName != "" {
+		noteParts = append([]string{fmt.Sprintf("已存放至「%s」目录", folderName)}, noteParts...)
+	}
+
+	summary := model.ActionSummary{Type: "feishu_minutes_notes", Target: title, ID: docID, Note: strings.Join(noteParts, "；")}
+	summary.Outputs = map[string]string{"doc_id": docID, "task_ids": strings.Join(taskIDs, ",")}
+	if summary.URL = e.Client.DocURL("docx", docID); summary.URL != "" {
+		summary.Outputs["doc_url"] = summary.URL
+	}
+	return summary, nil
+}
+
+// createTodoTasks 为每条待办创建飞书任务，返回成功的任务 ID 与失败项说明
+func (e *FeishuExecutor) createTodoTasks(ctx context.Context, token, title string, todos []model.ActionItem) ([]string, []string) {
+	var taskIDs, failed []string
+	for _, todo := range todos {
+		summary := todo.Content
+		if todo.Owner != "" {
+			summary = fmt.Sprintf("[%s] %s", todo.Owner, todo.Content)
+		}
+		task, err := e.Client.CreateTask(ctx, token, summary, "来自会议："+title)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s（%v）", todo.Content, err))
+			continue
+		}
+		taskIDs = append(taskIDs, task.GUID)
+	}
+	return taskIDs, failed
+}
+
+// meetingNotesBlocks 将结构化纪要排版为文档块
+func meetingNotesBlocks(notes model.MeetingNotes, info feishu.MinuteInfo) []feishu.DocBlock {
+	var blocks []feishu.DocBlock
+	if info.URL != "" {
+		blocks = append(blocks, feishu.DocBlock{Kind: "text", Text: "妙记链接：" + info.URL})
+	}
+	if notes.Summary != "" {
+		blocks = append(blocks, feishu.DocBlock{Kind: "heading2", Text: "会议摘要"}, feishu.DocBlock{Kind: "text", Text: notes.Summary})
+	}
+	section := func(heading string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		blocks = append(blocks, feishu.DocBlock{Kind: "heading2", Text: heading})
+		for _, item := range items {
+			blocks = append(blocks, feishu.DocBlock{Kind: "bullet", Text: item})
+		}
+	}
+	section("讨论要点", notes.Topics)
+	section("会议结论", notes.Decisions)
+	if len(notes.Todos) > 0 {
+		blocks = append(blocks, feishu.DocBlock{Kind: "heading2", Text: "待办事项"})
+		for _, todo := range notes.Todos {
+			text := todo.Content
+			if todo.Owner != "" {
+				text = fmt.Sprintf("%s（负责人：%s）", todo.Content, todo.Owner)
+			}
+			blocks = append(blocks, feishu.DocBlock{Kind: "todo", Text: text})
+		}
+	}
+	return blocks
+}
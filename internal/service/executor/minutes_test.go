@@ -0,0 +1,50 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// feishuTaskStub 模拟飞书建任务接口，summary 含 reject 的任务返回业务错误
+type feishuTaskStub struct {
+	reject string
+}
+
+func (s *feishuTaskStub) RoundTrip(r *http.Request) (*http.Response, error) {
+	body := `{"code":99991663,"msg":"not stubbed"}`
+	if strings.HasSuffix(r.URL.Path, "/task/v2/tasks") {
+		var req struct {
+			Summary string `json:"summary"`
+		}
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &req)
+		body = `{"code":0,"data":{"task":{"guid":"task_ok"}}}`
+		if strings.Contains(req.Summary, s.reject) {
+			body = `{"code":1470400,"msg":"invalid summary"}`
+		}
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header), Request: r}, nil
+}
+
+func TestCreateTodoTasksReportsFailures(t *testing.T) {
+	cfg := feishu.Config{AppID: "cli_test", AppSecret: "secret", Region: "feishu", Enabled: true, Transport: &feishuTaskStub{reject: "预算"}}
+	e := &FeishuExecutor{Client: feishu.NewClient(cfg), Cfg: cfg}
+
+	ids, failed := e.createTodoTasks(context.Background(), "t-test", "周会纪要", []model.ActionItem{
+		{Owner: "张三", Content: "整理需求"},
+		{Owner: "李四", Content: "提交预算"},
+	})
+	if len(ids) != 1 || ids[0] != "task_ok" {
+		t.Fatalf("task ids = %v, want [task_ok]", ids)
+	}
+	if len(failed) != 1 || !strings.Contains(failed[0], "提交预算") || !strings.Contains(failed[0], "1470400") {
+		t.Fatalf("failed = %v, want the rejected todo with its error", failed)
+	}
+}
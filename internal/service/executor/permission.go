@@ -0,0 +1,292 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// ExecuteReviewPermissions 审查文档权限：列出协作者与公共设置，按参数收紧过宽的访问，并把报告发给请求人
+// params: doc_url, close_external(bool), link_share(closed|tenant_readable|...，须比当前更严), remove_members([名字或 ID]，须确认)
+func (e *FeishuExecutor) ExecuteReviewPermissions(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	docURL, _ := spec.Params["doc_url"].(string)
+	docToken, docType, err := feishu.ParseDocURL(docURL)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("review_doc_permissions: %w: %v", model.ErrInvalidParams, err)
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+
+	members, err := e.Client.ListPermissionMembers(ctx, token, docToken, docType)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	public, err := e.Client.GetPublicPermission(ctx, token, docToken, docType)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+
+	var report []string
+	report = append(report, fmt.Sprintf("文档权限报告：%s", docURL))
+	report = append(report, fmt.Sprintf("对外访问：%s；链接分享：%s", describeExternalAccess(public.ExternalAccessEntity), describeLinkShare(public.LinkShareEntity)))
+	report = append(report, fmt.Sprintf("协作者（%d）：", len(members)))
+	for _, m := range members {
+		report = append(report, fmt.Sprintf("- %s（%s）：%s", memberLabel(m), m.Type, m.Perm))
+	}
+	if risks := permissionRisks(public); len(risks) > 0 {
+		report = append(report, "风险："+strings.Join(risks, "；"))
+	}
+
+	// 收紧公共设置：link_share 只接受比当前更严的取值，避免「收紧」变成对外开放
+	linkShare, _ := spec.Params["link_share"].(string)
+	if linkShare != "" && linkShare != public.LinkShareEntity && !stricterLinkShare(linkShare, public.LinkShareEntity) {
+		return model.ActionSummary{}, fmt.Errorf("review_doc_permissions: %w: link_share %q is not stricter than %q", model.ErrInvalidParams, linkShare, public.LinkShareEntity)
+	}
+	var changes []string
+	var update feishu.PublicPermission
+	if closeExternal, _ := spec.Params["close_external"].(bool); closeExternal {
+		if public.ExternalAccessEntity != "closed" {
+			update.ExternalAccessEntity = "closed"
+			changes = append(changes, "关闭对外访问")
+		}
+		if strings.HasPrefix(public.LinkShareEntity, "anyone_") {
+			update.LinkShareEntity = "tenant_readable"
+			changes = append(changes, "链接分享改为组织内可阅读")
+		}
+	}
+	if linkShare != "" && linkShare != public.LinkShareEntity {
+		update.LinkShareEntity = linkShare
+		changes = append(changes, "链接分享改为"+describeLinkShare(linkShare))
+	}
+
+	// 移除协作者须经用户确认，与转移所有者一致
+	var remove []feishu.PermissionMember
+	var failed []string
+	if names, ok := spec.Params["remove_members"].([]any); ok {
+		for _, n := range names {
+			name, _ := n.(string)
+			m, found := findMember(members, name)
+			if !found {
+				failed = append(failed, name+"（不是协作者）")
+				continue
+			}
+			remove = append(remove, m)
+		}
+	}
+	if len(remove) > 0 && !spec.Confirmed {
+		labels := make([]string, len(remove))
+		for i, m := range remove {
+			labels[i] = memberLabel(m)
+		}
+		summary := model.ActionSummary{Type: "feishu_permission_review", Target: docURL, ID: docToken, URL: docURL}
+		summary.Note = fmt.Sprintf("将移除协作者 %s", strings.Join(labels, "、"))
+		if len(changes) > 0 {
+			summary.Note += "，并" + strings.Join(changes, "、")
+		}
+		summary.Note += "，请确认"
+		return summary, model.ErrConfirmationRequired
+	}
+
+	if update != (feishu.PublicPermission{}) {
+		if err := e.Client.UpdatePublicPermission(ctx, token, docToken, docType, update); err != nil {
+			return model.ActionSummary{}, err
+		}
+	}
+	for _, m := range remove {
+		if err := e.Client.RemovePermissionMember(ctx, token, docToken, docType, m); err != nil {
+			failed = append(failed, fmt.Sprintf("%s（%v）", memberLabel(m), err))
+			continue
+		}
+		changes = append(changes, "移除协作者 "+memberLabel(m))
+	}
+
+	if len(changes) > 0 {
+		report = append(report, "已调整："+strings.Join(changes, "；"))
+	}
+	if len(failed) > 0 {
+		report = append(report, "未能移除："+strings.Join(failed, "；"))
+	}
+
+	summary := model.ActionSummary{Type: "feishu_permission_review", Target: docURL, ID: docToken}
+	summary.Note = strings.Join(changes, "；")
+	if summary.Note == "" {
+		summary.Note = "未做调整"
+	}
+	if requester := requesterID(req); requester != "" {
+		result := e.sendToTarget(ctx, token, requester, "user", "text", feishu.BuildTextContent(strings.Join(report, "\n")))
+		if !result.Success {
+			summary.Note += "；报告发送失败: " + result.Error
+		}
+	}
+	return summary, nil
+}
+
+// ExecuteTransferOwner 转移文档所有者；未确认时只解析新所有者并返回 ErrConfirmationRequired，由调用方向用户确认
+// params: doc_url, new_owner(名字、open_id 或邮箱), remove_old_owner(bool)
+func (e *FeishuExecutor) ExecuteTransferOwner(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	docURL, _ := spec.Params["doc_url"].(string)
+	docToken, docType, err := feishu.ParseDocURL(docURL)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_transfer_owner: %w: %v", model.ErrInvalidParams, err)
+	}
+	newOwner, _ := spec.Params["new_owner"].(string)
+	if newOwner == "" {
+		return model.ActionSummary{}, fmt.Errorf("feishu_transfer_owner: %w: new_owner is required", model.ErrInvalidParams)
+	}
+	removeOld, _ := spec.Params["remove_old_owner"].(bool)
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	member, name, err := e.resolveMember(ctx, token, newOwner)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_transfer_owner: %w", err)
+	}
+
+	summary := model.ActionSummary{Type: "feishu_transfer_owner", Target: docURL, ID: docToken, URL: docURL}
+	if !spec.Confirmed {
+		summary.Note = fmt.Sprintf("将把文档所有者转给 %s", name)
+		if removeOld {
+			summary.Note += "，并移除原所有者的权限"
+		}
+		summary.Note += "，请确认"
+		return summary, model.ErrConfirmationRequired
+	}
+	if err := e.Client.TransferOwner(ctx, token, docToken, docType, member, feishu.TransferOwnerOptions{RemoveOldOwner: removeOld}); err != nil {
+		return model.ActionSummary{}, err
+	}
+	summary.Note = "所有者已转给 " + name
+	summary.Outputs = map[string]string{"owner_id": member.MemberID}
+	return summary, nil
+}
+
+// resolveMember 将名字、open_id 或邮箱解析为权限成员，同时返回便于展示的名字
+func (e *FeishuExecutor) resolveMember(ctx context.Context, token, who string) (feishu.PermissionMember, string, error) {
+	switch {
+	case isOpenID(who):
+		return feishu.PermissionMember{MemberType: "openid", MemberID: who}, who, nil
+	case isEmail(who):
+		return feishu.PermissionMember{MemberType: "email", MemberID: who}, who, nil
+	}
+	user, err := e.Client.SearchUserByName(ctx, token, who)
+	if err != nil {
+		return feishu.PermissionMember{}, "", fmt.Errorf("search user %q: %w", who, err)
+	}
+	if user == nil || (user.UserID == "" && user.OpenID == "") {
+		return feishu.PermissionMember{}, "", fmt.Errorf("user %q not found", who)
+	}
+	name := user.Name
+	if name == "" {
+		name = who
+	}
+	if user.UserID != "" {
+		return feishu.PermissionMember{MemberType: "userid", MemberID: user.UserID}, name, nil
+	}
+	return feishu.PermissionMember{MemberType: "openid", MemberID: user.OpenID}, name, nil
+}
+
+// requesterID 请求人的飞书 ID：优先 Context 中的 feishu_open_id，其次 UserID
+func requesterID(req *model.ASRRequest) string {
+	if req == nil {
+		return ""
+	}
+	if id := req.Context["feishu_open_id"]; id != "" {
+		return id
+	}
+	return req.UserID
+}
+
+// permissionRisks 列出公共设置中的过宽访问
+func permissionRisks(p feishu.PublicPermission) []string {
+	var risks []string
+	if p.ExternalAccessEntity == "open" {
+		risks = append(risks, "允许组织外访问")
+	}
+	if strings.HasPrefix(p.LinkShareEntity, "anyone_") {
+		risks = append(risks, "互联网上获得链接的人可访问")
+	}
+	if p.LinkShareEntity == "tenant_editable" || p.LinkShareEntity == "anyone_editable" {
+		risks = append(risks, "获得链接的人可编辑")
+	}
+	return risks
+}
+
+func findMember(members []feishu.PermissionMember, name string) (feishu.PermissionMember, bool) {
+	for _, m := range members {
+		if m.MemberID == name || (m.Name != "" && m.Name == name) {
+			return m, true
+		}
+	}
+	return feishu.PermissionMember{}, false
+}
+
+func memberLabel(m feishu.PermissionMember) string {
+	if m.Name != "" {
+		return m.Name
+	}
+	return m.MemberID
+}
+
+// linkShareScopes 链接分享的可见范围与权限等级，数值越大越宽
+var linkShareScopes = map[string][2]int{
+	"closed":          {0, 0},
+	"tenant_readable": {1, 1},
+	"tenant_editable": {1, 2},
+	"anyone_readable": {2, 1},
+	"anyone_editable": {2, 2},
+}
+
+// stricterLinkShare next 的范围与权限都不宽于 current 时为 true；未知取值一律不接受
+func stricterLinkShare(next, current string) bool {
+	n, ok := linkShareScopes[next]
+	if !ok {
+		return false
+	}
+	c, ok := linkShareScopes[current]
+	if !ok {
+		// 当前取值未知时只允许关闭
+		return next == "closed"
+	}
+	return n[0] <= c[0] && n[1] <= c[1]
+}
+
+func describeExternalAccess(v string) string {
+	switch v {
+	case "open":
+		return "允许"
+	case "closed":
+		return "关闭"
+	case "allow_shared_partner_tenant":
+		return "仅关联组织"
+	default:
+		return "未知"
+	}
+}
+
+func describeLinkShare(v string) string {
+	switch v {
+	case "closed":
+		return "关闭"
+	case "tenant_readable":
+		return "组织内可阅读"
+	case "tenant_editable":
+		return "组织内可编辑"
+	case "anyone_readable":
+		return "互联网可阅读"
+	case "anyone_editable":
+		return "互联网可编辑"
+	default:
+		return "未知"
+	}
+}
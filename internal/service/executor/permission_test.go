@@ -0,0 +1,76 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// feishuPermStub 模拟文档权限接口：公共设置为 tenant_readable，协作者只有张三；记下写操作
+type feishuPermStub struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (s *feishuPermStub) RoundTrip(r *http.Request) (*http.Response, error) {
+	body := `{"code":99991663,"msg":"not stubbed"}`
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/tenant_access_token/internal"):
+		body = `{"code":0,"tenant_access_token":"t-test","expire":7200}`
+	case strings.HasSuffix(r.URL.Path, "/members") && r.Method == http.MethodGet:
+		body = `{"code":0,"data":{"items":[{"member_type":"openid","member_id":"ou_zhang","perm":"edit","type":"user","name":"张三"}]}}`
+	case strings.HasSuffix(r.URL.Path, "/public") && r.Method == http.MethodGet:
+		body = `{"code":0,"data":{"permission_public":{"external_access_entity":"closed","link_share_entity":"tenant_readable"}}}`
+	case r.Method == http.MethodPatch || r.Method == http.MethodDelete:
+		s.mu.Lock()
+		s.writes = append(s.writes, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+		body = `{"code":0}`
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header), Request: r}, nil
+}
+
+func TestReviewPermissionsOnlyTightens(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]any
+		confirmed bool
+		wantErr   error
+		wantWrite int
+	}{
+		{name: "widen to internet", params: map[string]any{"link_share": "anyone_editable"}, wantErr: model.ErrInvalidParams},
+		{name: "widen to tenant editable", params: map[string]any{"link_share": "tenant_editable"}, wantErr: model.ErrInvalidParams},
+		{name: "unknown value", params: map[string]any{"link_share": "public"}, wantErr: model.ErrInvalidParams},
+		{name: "close link", params: map[string]any{"link_share": "closed"}, wantWrite: 1},
+		{name: "remove unconfirmed", params: map[string]any{"remove_members": []any{"张三"}}, wantErr: model.ErrConfirmationRequired},
+		{name: "remove confirmed", params: map[string]any{"remove_members": []any{"张三"}}, confirmed: true, wantWrite: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &feishuPermStub{}
+			cfg := feishu.Config{AppID: "cli_test", AppSecret: "secret", Region: "feishu", Enabled: true, Transport: stub}
+			e := &FeishuExecutor{Client: feishu.NewClient(cfg), Cfg: cfg}
+			tt.params["doc_url"] = "https://acme.feishu.cn/docx/doxcnPerm"
+			spec := model.ActionSpec{Type: model.ActionTypeReviewPerms, Params: tt.params, Confirmed: tt.confirmed}
+
+			_, err := e.ExecuteReviewPermissions(context.Background(), spec, nil)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ExecuteReviewPermissions: %v", err)
+			}
+			if len(stub.writes) != tt.wantWrite {
+				t.Fatalf("writes = %v, want %d", stub.writes, tt.wantWrite)
+			}
+		})
+	}
+}
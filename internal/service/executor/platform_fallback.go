@@ -0,0 +1,291 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// 平台降级：大模型选择的平台未启用（如 Slack 关闭时仍选了 platform=slack）时，不直接失败：
+// 接收方在已启用的平台上能找到时改从该平台发送，找不到时请用户确认改用哪个平台。
+// 配置了身份映射时，"张三"按映射换成所选平台上的账号；所选平台上没有其账号时改投到有账号的平台
+
+// IdentityDirectory 跨平台身份映射（由 store.IdentityStore 实现）
+type IdentityDirectory interface {
+	Lookup(ctx context.Context, tenantID, key string) (model.UserIdentity, error)
+}
+
+// platformNames 平台的展示名，顺序即改投时尝试的顺序
+var platformNames = []struct{ id, name string }{
+	{"feishu", "飞书"},
+	{"slack", "Slack"},
+	{"discord", "Discord"},
+}
+
+func platformName(platform string) string {
+	for _, p := range platformNames {
+		if p.id == platform {
+			return p.name
+		}
+	}
+	return platform
+}
+
+// platformEnabled 平台是否已启用
+func (e *Executor) platformEnabled(platform string) bool {
+	switch platform {
+	case "feishu":
+		return e.feishu.Cfg.Enabled
+	case "slack":
+		return e.slack.Cfg.Enabled
+	case "discord":
+		return e.discord.Cfg.Enabled
+	}
+	return false
+}
+
+// fallbackPlatforms 可改投的已启用平台；导出文件只能发到飞书或 Slack
+func (e *Executor) fallbackPlatforms(actionType, from string) []string {
+	var out []string
+	for _, p := range platformNames {
+		if p.id == from || !e.platformEnabled(p.id) {
+			continue
+		}
+		if actionType == model.ActionTypeExportDoc && p.id == "discord" {
+			continue
+		}
+		out = append(out, p.id)
+	}
+	return out
+}
+
+// reroutedKey PlatformFallbackHook 在 ctx 中记录改投说明
+type reroutedKey struct{}
+
+// PlatformFallbackHook send_message、export_doc 指定的平台未启用时改投到已启用的平台：全部接收方都能在某个平台找到时
+// 直接改从该平台发送并在摘要中说明；都找不到时返回 ErrConfirmationRequired 列出可用平台，确认后改用首个可用平台按名字查找发送。
+// identities 可选，指定的平台已启用时把接收方换成映射中该平台的账号，有接收方在该平台没有账号时同样尝试改投
+func (e *Executor) PlatformFallbackHook(identities IdentityDirectory) Hook {
+	return Hook{
+		Name: "platform_fallback",
+		BeforeAction: func(ctx context.Context, spec *model.ActionSpec, req *model.ASRRequest) (context.Context, error) {
+			params := model.ParseSendMessageParams(spec.Params)
+			if e.platformEnabled(params.Platform) {
+				return e.mapIdentities(ctx, identities, spec, req), nil
+			}
+			from, alternatives := e.needsFallback(*spec, req)
+			if len(alternatives) == 0 {
+				return ctx, nil
+			}
+			for _, platform := range alternatives {
+				if found, ok := e.findRecipients(ctx, identities, platform, params.Targets, req); ok {
+					rerouteSpec(spec, platform, found)
+					ctx = withVerified(ctx, found)
+					return withRerouted(ctx, "%s 未启用，已改用%s发送", platformName(from), platformName(platform)), nil
+				}
+			}
+			if !spec.Confirmed {
+				return ctx, model.ErrConfirmationRequired
+			}
+			// 用户已确认改用首个可用平台，由该平台按名字查找接收方
+			rerouteSpec(spec, alternatives[0], params.Targets)
+			return withRerouted(ctx, "%s 未启用，已改用%s发送", platformName(from), platformName(alternatives[0])), nil
+		},
+		AfterAction: func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, summary *model.ActionSummary, err error) {
+			if note, ok := ctx.Value(reroutedKey{}).(string); ok && err == nil {
+				summary.Note = strings.TrimPrefix(summary.Note+"；"+note, "；")
+				return
+			}
+			// 钩子拦截时动作未执行，由钩子补上待确认说明
+			if !errors.Is(err, model.ErrConfirmationRequired) || summary.Note != "" {
+				return
+			}
+			if from, alternatives := e.needsFallback(spec, req); len(alternatives) > 0 {
+				names := make([]string, len(alternatives))
+				for i, p := range alternatives {
+					names[i] = platformName(p)
+				}
+				targets := strings.Join(model.ParseSendMessageParams(spec.Params).Targets, "、")
+				summary.Type, summary.Target = "message", targets
+				summary.Note = fmt.Sprintf("%s 未启用，在%s中也没有找到「%s」。确认后改用%s按名字查找发送；也可以取消后说明对方在%s上的账号",
+					platformName(from), strings.Join(names, "、"), targets, names[0], strings.Join(names, "或"))
+			}
+		},
+	}
+}
+
+func withRerouted(ctx context.Context, format string, args ...any) context.Context {
+	return context.WithValue(ctx, reroutedKey{}, fmt.Sprintf(format, args...))
+}
+
+// mapIdentities 平台已启用时按身份映射把接收方换成该平台的账号；有接收方在映射中但该平台没有其账号时，
+// 改投到全部接收方都能找到的其他已启用平台，找不到时仍按原平台发送
+func (e *Executor) mapIdentities(ctx context.Context, identities IdentityDirectory, spec *model.ActionSpec, req *model.ASRRequest) context.Context {
+	if identities == nil || e.Sandboxed(req) || (spec.Type != model.ActionTypeSendMessage && spec.Type != model.ActionTypeExportDoc) {
+		return ctx
+	}
+	params := model.ParseSendMessageParams(spec.Params)
+	targets := make([]string, 0, len(params.Targets))
+	var changed bool
+	var missing, verified []string
+	for _, t := range params.Targets {
+		if _, ok := e.aliases.Lookup(tenantOf(req), t); ok {
+			targets = append(targets, t)
+			continue
+		}
+		u, err := identities.Lookup(ctx, tenantOf(req), t)
+		if err != nil {
+			targets = append(targets, t)
+			continue
+		}
+		id := e.identityTarget(u, params.Platform, req)
+		if id == "" {
+			missing = append(missing, t)
+			continue
+		}
+		changed = changed || id != t
+		targets = append(targets, id)
+		verified = append(verified, id)
+	}
+	if len(missing) > 0 {
+		for _, platform := range e.fallbackPlatforms(spec.Type, params.Platform) {
+			if found, ok := e.findRecipients(ctx, identities, platform, params.Targets, req); ok {
+				rerouteSpec(spec, platform, found)
+				return withRerouted(withVerified(ctx, found), "「%s」没有%s账号，已改用%s发送", strings.Join(missing, "、"), platformName(params.Platform), platformName(platform))
+			}
+		}
+		return ctx
+	}
+	if changed {
+		rerouteSpec(spec, params.Platform, targets)
+	}
+	return withVerified(ctx, verified)
+}
+
+// identityTarget 身份在平台上的发送目标：平台账号，飞书另接受邮箱；严格模式下飞书改用姓名，由通讯录按姓名精确查找
+func (e *Executor) identityTarget(u model.UserIdentity, platform string, req *model.ASRRequest) string {
+	id := u.PlatformID(platform)
+	if platform == "feishu" {
+		id = firstNonEmpty(id, u.Email)
+		if id != "" && e.strictRecipients(req) {
+			return u.Name
+		}
+	}
+	return id
+}
+
+// needsFallback 动作指定的平台未启用且有接收方时，返回原平台与可改投的平台；无需改投时 alternatives 为空
+func (e *Executor) needsFallback(spec model.ActionSpec, req *model.ASRRequest) (from string, alternatives []string) {
+	if spec.Type != model.ActionTypeSendMessage && spec.Type != model.ActionTypeExportDoc {
+		return "", nil
+	}
+	if e.Sandboxed(req) {
+		return "", nil
+	}
+	params := model.ParseSendMessageParams(spec.Params)
+	if params.Platform == "" || len(params.Targets) == 0 || e.platformEnabled(params.Platform) {
+		return "", nil
+	}
+	// 邮件不是消息平台，不改发到其他平台
+	if params.Platform == "email" {
+		return "", nil
+	}
+	return params.Platform, e.fallbackPlatforms(spec.Type, params.Platform)
+}
+
+// findRecipients 在 platform 上查找全部接收方，返回该平台上的发送目标；有任一找不到时 ok 为 false。
+// 联系人分组原样保留（成员按各自平台发送），身份映射中有该平台账号的换成该账号；飞书另接受请求 Contacts 中的联系人、飞书 ID、邮箱与通讯录中姓名完全一致的用户，
+// Slack 接受存在的频道（"#general"）与请求所在频道（"这个群"）
+func (e *Executor) findRecipients(ctx context.Context, identities IdentityDirectory, platform string, targets []string, req *model.ASRRequest) ([]string, bool) {
+	contacts := pinContacts(req)
+	var token string
+	out := make([]string, 0, len(targets))
+	for _, t := range targets {
+		if _, ok := e.aliases.Lookup(tenantOf(req), t); ok {
+			out = append(out, t)
+			continue
+		}
+		if identities != nil {
+			if u, err := identities.Lookup(ctx, tenantOf(req), t); err == nil {
+				if id := e.identityTarget(u, platform, req); id != "" {
+					out = append(out, id)
+					continue
+				}
+			}
+		}
+		switch platform {
+		case "feishu":
+			if pinned := contacts.pin(t); pinned != t || isOpenID(t) || isChatID(t) || isEmail(t) {
+				out = append(out, pinned)
+				continue
+			}
+			if token == "" {
+				var err error
+				if token, err = e.feishu.Client.GetTenantAccessToken(ctx); err != nil {
+					return nil, false
+				}
+			}
+			user, err := e.feishu.Client.SearchUserByName(ctx, token, t)
+			if err != nil || user == nil || user.Name != t {
+				return nil, false
+			}
+			// 保留名字，严格模式下发送时同样按姓名精确查找
+			out = append(out, t)
+		case "slack":
+			if channel := slackCurrentChannel(t, req); channel != "" {
+				out = append(out, channel)
+				continue
+			}
+			client, err := e.slack.workspaceClient(req)
+			if err != nil || !strings.HasPrefix(t, "#") {
+				return nil, false
+			}
+			if _, err := client.ChannelID(ctx, t); err != nil {
+				return nil, false
+			}
+			out = append(out, t)
+		default:
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+// slackCurrentChannel 目标指代请求所在的会话且请求来自 Slack 时返回该频道
+func slackCurrentChannel(target string, req *model.ASRRequest) string {
+	if req == nil || req.Context["slack_channel"] == "" {
+		return ""
+	}
+	if slices.Contains(currentChatNames, normalizeContact(target)) {
+		return req.Context["slack_channel"]
+	}
+	return ""
+}
+
+// rerouteSpec 改写动作的平台与接收方；单个接收方时目标类型按新平台重新判断
+func rerouteSpec(spec *model.ActionSpec, platform string, targets []string) {
+	params := make(map[string]any, len(spec.Params))
+	for k, v := range spec.Params {
+		params[k] = v
+	}
+	list := make([]any, len(targets))
+	for i, t := range targets {
+		list[i] = t
+	}
+	params["platform"] = platform
+	params["targets"] = list
+	if len(targets) > 1 {
+		params["target_type"] = "batch"
+	} else if r := (recipient{id: targets[0], platform: platform}); r.isChat() {
+		params["target_type"] = "chat"
+	} else {
+		params["target_type"] = "user"
+	}
+	spec.Params = params
+	// 原平台上固定的接收方 ID 随之作废
+	spec.TargetUserID, spec.TargetChatID = "", ""
+}
@@ -0,0 +1,221 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// approvalLookback 查询审批时回看的发起时间范围
+const approvalLookback = 90 * 24 * time.Hour
+
+// taskScanLimit 查询待办时最多读取的任务数
+const taskScanLimit = 500
+
+// ExecuteQueryApproval 查询请求人发起的审批走到哪一步了；只读，结果作为回复
+// params: keyword(审批名称关键词，如"报销"), pending_only(bool), limit
+func (e *FeishuExecutor) ExecuteQueryApproval(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	requester := requesterID(req)
+	if requester == "" {
+		return model.ActionSummary{}, fmt.Errorf("feishu_query_approval: %w: unknown requester", model.ErrInvalidParams)
+	}
+	idType := "user_id"
+	if isOpenID(requester) {
+		idType = "open_id"
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	instances, err := e.Client.QueryApprovalInstances(ctx, token, requester, idType, time.Now().Add(-approvalLookback), 100)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].StartTime.After(instances[j].StartTime) })
+
+	keyword, _ := spec.Params["keyword"].(string)
+	pendingOnly, _ := spec.Params["pending_only"].(bool)
+	limit := 3
+	if n, ok := spec.Params["limit"].(float64); ok && n > 0 {
+		limit = int(n)
+	}
+	var lines []string
+	for _, inst := range instances {
+		if keyword != "" && !strings.Contains(inst.ApprovalName, keyword) && !strings.Contains(inst.Title, keyword) {
+			continue
+		}
+		if pendingOnly && inst.Status != "PENDING" {
+			continue
+		}
+		lines = append(lines, e.describeApproval(ctx, token, inst))
+		if len(lines) == limit {
+			break
+		}
+	}
+
+	summary := model.ActionSummary{Type: "feishu_approval_query", Target: keyword, Note: "没有找到相关的审批"}
+	if len(lines) > 0 {
+		summary.Note = strings.Join(lines, "\n")
+	}
+	summary.Outputs = map[string]string{"answer": summary.Note}
+	return summary, nil
+}
+
+// describeApproval 审批进度说明；审批中的实例补充当前节点与待处理人
+func (e *FeishuExecutor) describeApproval(ctx context.Context, token string, inst feishu.ApprovalInstance) string {
+	name := inst.ApprovalName
+	if inst.Title != "" {
+		name = inst.Title
+	}
+	line := fmt.Sprintf("「%s」%s 提交，%s", name, inst.StartTime.Format("01-02"), approvalStatusText(inst.Status))
+	if inst.Status != "PENDING" {
+		return line
+	}
+	tasks, err := e.Client.GetApprovalTasks(ctx, token, inst.Code)
+	if err != nil {
+		return line
+	}
+	var nodes, approvers []string
+	for _, t := range tasks {
+		if t.Status != "PENDING" {
+			continue
+		}
+		if t.NodeName != "" && !slices.Contains(nodes, t.NodeName) {
+			nodes = append(nodes, t.NodeName)
+		}
+		who := t.OpenID
+		if who != "" {
+			if n, err := e.Client.GetUserName(ctx, token, who); err == nil && n != "" {
+				who = n
+			}
+		} else {
+			who = t.UserID
+		}
+		if who != "" && !slices.Contains(approvers, who) {
+			approvers = append(approvers, who)
+		}
+	}
+	if len(nodes) > 0 {
+		line += "，当前在「" + strings.Join(nodes, "、") + "」"
+	}
+	if len(approvers) > 0 {
+		line += "，等待 " + strings.Join(approvers, "、") + " 处理"
+	}
+	return line
+}
+
+func approvalStatusText(status string) string {
+	switch status {
+	case "PENDING":
+		return "审批中"
+	case "APPROVED":
+		return "已通过"
+	case "REJECTED":
+		return "已拒绝"
+	case "CANCELED":
+		return "已撤回"
+	case "DELETED":
+		return "已删除"
+	default:
+		return status
+	}
+}
+
+// ExecuteQueryTasks 列出请求人的未完成飞书任务；只读，结果作为回复。
+// 应用读取的是租户内的全部任务，请求人没有飞书 open_id（Slack、邮件、定时工作流等）时无法按成员筛选，直接拒绝
+// params: period(today|week|overdue|all), keyword
+func (e *FeishuExecutor) ExecuteQueryTasks(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	requester := requesterID(req)
+	if !isOpenID(requester) {
+		return model.ActionSummary{}, fmt.Errorf("feishu_query_tasks: %w: requester has no feishu open_id", model.ErrInvalidParams)
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	tasks, err := e.Client.ListTasks(ctx, token, taskScanLimit)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+
+	now := time.Now()
+	period, _ := spec.Params["period"].(string)
+	keyword, _ := spec.Params["keyword"].(string)
+	matched := openTasksOf(tasks, requester, period, keyword, now)
+
+	summary := model.ActionSummary{Type: "feishu_task_query", Target: period, Note: "没有未完成的待办"}
+	if len(matched) > 0 {
+		lines := []string{fmt.Sprintf("共 %d 项待办：", len(matched))}
+		for _, t := range matched {
+			lines = append(lines, "- "+t.Summary+dueText(t.Due, now))
+		}
+		summary.Note = strings.Join(lines, "\n")
+	}
+	summary.Outputs = map[string]string{"answer": summary.Note}
+	return summary, nil
+}
+
+// openTasksOf requester 参与的未完成任务，按关键词与时间范围筛选；有截止时间的按截止时间排前面
+func openTasksOf(tasks []feishu.TaskItem, requester, period, keyword string, now time.Time) []feishu.TaskItem {
+	var matched []feishu.TaskItem
+	for _, t := range tasks {
+		if t.Completed || (keyword != "" && !strings.Contains(t.Summary, keyword)) {
+			continue
+		}
+		if requester == "" || !slices.Contains(t.MemberIDs, requester) {
+			continue
+		}
+		if taskInPeriod(t, period, now) {
+			matched = append(matched, t)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i].Due, matched[j].Due
+		if a.IsZero() != b.IsZero() {
+			return !a.IsZero()
+		}
+		return a.Before(b)
+	})
+	return matched
+}
+
+// taskInPeriod today 含已逾期，week 为本周日之前，overdue 只含已逾期，其余不限
+func taskInPeriod(t feishu.TaskItem, period string, now time.Time) bool {
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	switch period {
+	case "today":
+		return !t.Due.IsZero() && t.Due.Before(endOfDay)
+	case "week":
+		daysLeft := (7 - int(now.Weekday())) % 7
+		return !t.Due.IsZero() && t.Due.Before(endOfDay.AddDate(0, 0, daysLeft))
+	case "overdue":
+		return !t.Due.IsZero() && t.Due.Before(now)
+	default:
+		return true
+	}
+}
+
+func dueText(due, now time.Time) string {
+	switch {
+	case due.IsZero():
+		return ""
+	case due.Before(now):
+		return fmt.Sprintf("（已逾期，%s 截止）", due.Format("01-02 15:04"))
+	case due.YearDay() == now.YearDay() && due.Year() == now.Year():
+		return fmt.Sprintf("（今天 %s 截止）", due.Format("15:04"))
+	default:
+		return fmt.Sprintf("（%s 截止）", due.Format("01-02 15:04"))
+	}
+}
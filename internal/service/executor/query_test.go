@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+func TestExecuteQueryTasksRequiresOpenID(t *testing.T) {
+	// Client 为空：请求人无 open_id 时应在调用飞书之前拒绝
+	e := &FeishuExecutor{Cfg: feishu.Config{Enabled: true}}
+	spec := model.ActionSpec{Type: model.ActionTypeQueryTasks}
+	tests := []struct {
+		name string
+		req  *model.ASRRequest
+	}{
+		{name: "nil request", req: nil},
+		{name: "empty requester", req: &model.ASRRequest{}},
+		{name: "slack user", req: &model.ASRRequest{UserID: "U024BE7LH"}},
+		{name: "email sender", req: &model.ASRRequest{UserID: "alice@example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := e.ExecuteQueryTasks(context.Background(), spec, tt.req)
+			if !errors.Is(err, model.ErrInvalidParams) {
+				t.Fatalf("err = %v, want ErrInvalidParams", err)
+			}
+		})
+	}
+}
+
+func TestOpenTasksOf(t *testing.T) {
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.Local)
+	tasks := []feishu.TaskItem{
+		{GUID: "1", Summary: "写周报", MemberIDs: []string{"ou_alice"}},
+		{GUID: "2", Summary: "评审方案", MemberIDs: []string{"ou_bob"}},
+		{GUID: "3", Summary: "发布", MemberIDs: []string{"ou_alice", "ou_bob"}, Due: now.Add(time.Hour)},
+		{GUID: "4", Summary: "归档", MemberIDs: []string{"ou_alice"}, Completed: true},
+		{GUID: "5", Summary: "无人负责"},
+	}
+	tests := []struct {
+		name      string
+		requester string
+		period    string
+		keyword   string
+		want      []string
+	}{
+		{name: "own tasks only", requester: "ou_alice", want: []string{"3", "1"}},
+		{name: "other member", requester: "ou_bob", want: []string{"3", "2"}},
+		{name: "no requester", requester: "", want: nil},
+		{name: "today", requester: "ou_alice", period: "today", want: []string{"3"}},
+		{name: "keyword", requester: "ou_alice", keyword: "周报", want: []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, item := range openTasksOf(tasks, tt.requester, tt.period, tt.keyword, now) {
+				got = append(got, item.GUID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
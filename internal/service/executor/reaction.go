@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// reactionEmojis 常用表情在各平台的名称；aliases 为用户口头说法
+var reactionEmojis = []struct {
+	emoji   string
+	feishu  string // 飞书 emoji_type
+	slack   string // Slack 表情名（不带冒号）
+	aliases []string
+}{
+	{"👍", "THUMBSUP", "thumbsup", []string{"赞", "点赞", "大拇指", "+1", "like"}},
+	{"👌", "OK", "ok_hand", []string{"ok", "好的"}},
+	{"✅", "DONE", "white_check_mark", []string{"完成", "搞定", "收到", "done", "check"}},
+	{"❤", "HEART", "heart", []string{"爱心", "比心", "love"}},
+	{"👏", "APPLAUSE", "clap", []string{"鼓掌"}},
+	{"💪", "MUSCLE", "muscle", []string{"加油"}},
+	{"🙏", "THANKS", "pray", []string{"谢谢", "感谢", "thanks"}},
+	{"😄", "SMILE", "smile", []string{"笑", "微笑"}},
+	{"➕", "JIAYI", "heavy_plus_sign", []string{"加一"}},
+	{"🎉", "PARTY", "tada", []string{"庆祝", "撒花"}},
+}
+
+// reactionName 返回表情在 platform 上的名称，未收录时原样使用（去掉冒号，飞书转大写）；为空时默认点赞
+func reactionName(platform, emoji string) string {
+	s := strings.TrimSpace(strings.ReplaceAll(emoji, "\uFE0F", ""))
+	if s == "" {
+		s = "👍"
+	}
+	s = strings.Trim(s, ":")
+	for _, r := range reactionEmojis {
+		match := s == r.emoji || strings.EqualFold(s, r.feishu) || strings.EqualFold(s, r.slack)
+		for _, a := range r.aliases {
+			match = match || strings.EqualFold(s, a)
+		}
+		if match {
+			if platform == "slack" {
+				return r.slack
+			}
+			return r.feishu
+		}
+	}
+	if platform == "slack" {
+		return strings.ToLower(s)
+	}
+	return strings.ToUpper(s)
+}
+
+// executeAddReaction 给消息添加表情回应，按 platform 路由
+// params: platform(feishu|slack), message_id（Slack 为消息 ts）, chat_id（Slack 必填）, emoji
+func (e *Executor) executeAddReaction(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if platform, _ := spec.Params["platform"].(string); platform == "slack" {
+		return e.slack.ExecuteAddReaction(ctx, spec, req)
+	}
+	return e.feishu.ExecuteAddReaction(ctx, spec, req)
+}
+
+// ExecuteAddReaction 给飞书消息添加表情回复
+func (e *FeishuExecutor) ExecuteAddReaction(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	messageID, _ := spec.Params["message_id"].(string)
+	if messageID == "" {
+		return model.ActionSummary{}, fmt.Errorf("add_reaction: %w: message_id is required", model.ErrInvalidParams)
+	}
+	emoji, _ := spec.Params["emoji"].(string)
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	name := reactionName("feishu", emoji)
+	reactionID, err := e.Client.AddReaction(ctx, token, messageID, name)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("add_reaction: %w", err)
+	}
+	summary := model.ActionSummary{Type: "feishu_reaction", Target: messageID, ID: reactionID, Note: name}
+	summary.Outputs = map[string]string{"reaction_id": reactionID, "message_id": messageID}
+	return summary, nil
+}
+
+// ExecuteAddReaction 给 Slack 消息添加表情回应；chat_id 可带工作区，如 "C0123@emea"
+func (e *SlackExecutor) ExecuteAddReaction(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrSlackDisabled
+	}
+	ts, _ := spec.Params["message_id"].(string)
+	channel, _ := spec.Params["chat_id"].(string)
+	if ts == "" || channel == "" {
+		return model.ActionSummary{}, fmt.Errorf("add_reaction: %w: message_id and chat_id are required", model.ErrInvalidParams)
+	}
+	client, err := e.workspaceClient(req)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	client, channelID, err := resolveTarget(client, channel)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	emoji, _ := spec.Params["emoji"].(string)
+	name := reactionName("slack", emoji)
+	if err := client.AddReaction(ctx, channelID, ts, name); err != nil {
+		return model.ActionSummary{}, fmt.Errorf("add_reaction: %w", err)
+	}
+	summary := model.ActionSummary{Type: "slack_reaction", Target: channel, ID: ts, Note: ":" + name + ":"}
+	summary.Outputs = map[string]string{"message_id": ts, "chat_id": channelID}
+	return summary, nil
+}
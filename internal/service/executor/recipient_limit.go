@@ -0,0 +1,60 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sayso-agent/internal/model"
+)
+
+// RecipientLimitHook 单个动作的接收方上限（limits.max_recipients），按展开联系人分组、跳过排除成员后的实际接收方计数：
+// 计划中只能看到分组名，一个分组可能有上百人。超出时 reject 为 true 直接返回 ErrLimitExceeded，否则须用户确认后才发送
+func (e *Executor) RecipientLimitHook(limit int, reject bool) Hook {
+	return Hook{
+		Name: "recipient_limit",
+		BeforeAction: func(ctx context.Context, spec *model.ActionSpec, req *model.ASRRequest) (context.Context, error) {
+			n := e.recipientCount(*spec, req)
+			if n <= limit {
+				return ctx, nil
+			}
+			if reject {
+				return ctx, fmt.Errorf("%s: %w: 共 %d 个接收方，超过上限 %d 个", spec.Type, model.ErrLimitExceeded, n, limit)
+			}
+			if !spec.Confirmed {
+				return ctx, model.ErrConfirmationRequired
+			}
+			return ctx, nil
+		},
+		AfterAction: func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, summary *model.ActionSummary, err error) {
+			// 钩子拦截时动作未执行，由钩子补上待确认说明
+			if !errors.Is(err, model.ErrConfirmationRequired) || summary.Note != "" {
+				return
+			}
+			if n := e.recipientCount(spec, req); n > limit {
+				summary.Type = "message"
+				summary.Note = fmt.Sprintf("将发送给 %d 个接收方，超过单次上限 %d 个，确认发送吗？", n, limit)
+			}
+		},
+	}
+}
+
+// recipientCount 消息类动作展开联系人分组后的接收方数（同一平台的重复目标只计一次），其他动作为 0
+func (e *Executor) recipientCount(spec model.ActionSpec, req *model.ASRRequest) int {
+	if spec.Type != model.ActionTypeSendMessage && spec.Type != model.ActionTypeExportDoc && spec.Type != model.ActionTypeSendSMS {
+		return 0
+	}
+	params := model.ParseSendMessageParams(spec.Params)
+	_, byPlatform, _ := e.aliases.expandTargets(tenantOf(req), params.Targets, params.Platform, params.Exclude)
+	n := 0
+	for _, ids := range byPlatform {
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				n++
+			}
+		}
+	}
+	return n
+}
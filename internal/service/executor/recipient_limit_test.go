@@ -0,0 +1,64 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"sayso-agent/internal/model"
+)
+
+func TestRecipientLimitHookCountsExpandedGroups(t *testing.T) {
+	var members []model.AliasMember
+	for i := 0; i < 30; i++ {
+		members = append(members, model.AliasMember{Platform: "feishu", ID: fmt.Sprintf("ou_%02d", i)})
+	}
+	members = append(members, model.AliasMember{Platform: "feishu", ID: "ou_intern", EmploymentType: model.EmploymentIntern})
+
+	tests := []struct {
+		name      string
+		targets   []any
+		exclude   []any
+		reject    bool
+		confirmed bool
+		wantErr   error
+		wantRun   bool
+	}{
+		{name: "single group over limit", targets: []any{"全员"}, wantErr: model.ErrConfirmationRequired},
+		{name: "confirmed", targets: []any{"全员"}, confirmed: true, wantRun: true},
+		{name: "reject", targets: []any{"全员"}, reject: true, confirmed: true, wantErr: model.ErrLimitExceeded},
+		{name: "duplicates counted once", targets: []any{"ou_01", "ou_01", "ou_02"}, wantRun: true},
+		{name: "exclusions applied", targets: []any{"小组"}, exclude: []any{"实习生"}, wantRun: true},
+		{name: "direct targets under limit", targets: []any{"ou_a", "ou_b"}, wantRun: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Executor{aliases: NewAliasBook([]model.Alias{
+				{Name: "全员", Members: members},
+				// 4 人中排除实习生后为 3 人，不超过上限
+				{Name: "小组", Members: []model.AliasMember{members[0], members[1], members[2], members[30]}},
+			})}
+			e.Use(e.RecipientLimitHook(3, tt.reject))
+			params := map[string]any{"platform": "feishu", "targets": tt.targets, "content": map[string]any{"text": "hi"}}
+			if tt.exclude != nil {
+				params["exclude"] = tt.exclude
+			}
+			spec := model.ActionSpec{Type: model.ActionTypeSendMessage, Params: params, Confirmed: tt.confirmed}
+			ran := false
+			summary, err := e.Intercept(context.Background(), spec, nil, func(context.Context, model.ActionSpec, *model.ASRRequest) (model.ActionSummary, error) {
+				ran = true
+				return model.ActionSummary{}, nil
+			})
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if ran != tt.wantRun {
+				t.Errorf("action ran = %v, want %v", ran, tt.wantRun)
+			}
+			if errors.Is(err, model.ErrConfirmationRequired) && summary.Note == "" {
+				t.Error("confirmation note missing")
+			}
+		})
+	}
+}
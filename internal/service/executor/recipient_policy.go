@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sayso-agent/internal/client/slack"
+	"sayso-agent/internal/model"
+)
+
+// RecipientPolicy 消息接收方策略，作用于 send_message、export_doc 与 send_sms 的全部目标（含请求租户的联系人分组逐级展开后的成员）；
+// 名单中可写 ID 或用户说出的名字（如 "#all-hands"、"全员群"），Slack 目标忽略 "@工作区" 后缀
+type RecipientPolicy struct {
+	// AllowedChats 非空时，只有名单中的群聊/频道可直接发送，其他群聊须用户确认
+	AllowedChats []string
+	// ConfirmChats 始终须用户确认才发送的群聊/频道（如全员群）
+	ConfirmChats []string
+	// BlockedUsers 禁止发送的用户
+	BlockedUsers []string
+	// BlockedDomains 禁止发送的邮箱域名（如外部合作方），目标为邮箱时检查
+	BlockedDomains []string
+}
+
+// RecipientPolicyHook 发送前检查接收方：命中禁止名单的直接拒绝，须确认的群聊在用户确认后才发送
+func (e *Executor) RecipientPolicyHook(policy RecipientPolicy) Hook {
+	return Hook{
+		Name: "recipient_policy",
+		BeforeAction: func(ctx context.Context, spec *model.ActionSpec, req *model.ASRRequest) (context.Context, error) {
+			_, err := e.checkRecipients(policy, *spec, req)
+			return ctx, err
+		},
+		AfterAction: func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, summary *model.ActionSummary, err error) {
+			// 钩子拦截时动作未执行，由钩子补上待确认说明
+			if errors.Is(err, model.ErrConfirmationRequired) && summary.Note == "" {
+				if chats, _ := e.checkRecipients(policy, spec, req); len(chats) > 0 {
+					summary.Type, summary.Target = "message", strings.Join(chats, "、")
+					summary.Note = fmt.Sprintf("将发送到群聊「%s」，该群需确认后才能发送，确认发送吗？", summary.Target)
+				}
+			}
+		},
+	}
+}
+
+// checkRecipients 返回须确认的群聊；有禁止的接收方时返回 ErrRecipientNotAllowed，须确认时返回 ErrConfirmationRequired
+func (e *Executor) checkRecipients(policy RecipientPolicy, spec model.ActionSpec, req *model.ASRRequest) ([]string, error) {
+	if spec.Type != model.ActionTypeSendMessage && spec.Type != model.ActionTypeExportDoc && spec.Type != model.ActionTypeSendSMS {
+		return nil, nil
+	}
+	params := model.ParseSendMessageParams(spec.Params)
+	contacts := pinContacts(req)
+	// 用户说出的目标（含分组名本身）与实际发送的全部成员都要检查；成员按发送时同样的方式展开（租户内、含嵌套分组、排除后）
+	targets := make([]recipient, 0, len(params.Targets))
+	for _, t := range params.Targets {
+		targets = append(targets, recipient{id: t, platform: params.Platform, chat: params.TargetType == "chat"})
+	}
+	if platforms, byPlatform, expanded := e.aliases.expandTargets(tenantOf(req), params.Targets, params.Platform, params.Exclude); expanded {
+		for _, platform := range platforms {
+			for _, id := range byPlatform[platform] {
+				targets = append(targets, recipient{id: id, platform: platform})
+			}
+		}
+	}
+	var confirm []string
+	for _, r := range targets {
+		r.chat = r.chat || r.isChat()
+		names := []string{r.key()}
+		if pinned := contacts.pin(r.id); pinned != r.id {
+			names = append(names, pinned)
+		}
+		switch {
+		case !r.chat && (matchAny(policy.BlockedUsers, names...) || blockedDomain(policy.BlockedDomains, names...)):
+			return nil, fmt.Errorf("%s: %w: %s 在禁止发送名单中", spec.Type, model.ErrRecipientNotAllowed, r.id)
+		case r.chat && !spec.Confirmed && !slices.Contains(confirm, r.id) && (matchAny(policy.ConfirmChats, names...) ||
+			len(policy.AllowedChats) > 0 && !matchAny(policy.AllowedChats, names...)):
+			confirm = append(confirm, r.id)
+		}
+	}
+	if len(confirm) > 0 {
+		return confirm, model.ErrConfirmationRequired
+	}
+	return nil, nil
+}
+
+// recipient 一个消息目标
+type recipient struct {
+	id       string
+	platform string
+	chat     bool
+}
+
+// isChat 目标是否为群聊/频道
+func (r recipient) isChat() bool {
+	if r.platform == "slack" {
+		return isSlackChannel(r.id)
+	}
+	return isChatID(r.id)
+}
+
+// key 用于与名单比较的目标：Slack 去掉工作区后缀
+func (r recipient) key() string {
+	if r.platform == "slack" && !isEmail(r.id) {
+		id, _ := slack.ParseTarget(r.id)
+		return id
+	}
+	return r.id
+}
+
+func matchAny(list []string, names ...string) bool {
+	for _, n := range names {
+		if slices.ContainsFunc(list, func(s string) bool { return strings.EqualFold(strings.TrimSpace(s), n) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockedDomain 邮箱目标的域名（含子域名）是否在禁止名单中
+func blockedDomain(domains []string, names ...string) bool {
+	for _, n := range names {
+		if !isEmail(n) {
+			continue
+		}
+		host := strings.ToLower(n[strings.LastIndex(n, "@")+1:])
+		for _, d := range domains {
+			d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+			if d != "" && (host == d || strings.HasSuffix(host, "."+d)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+
+	"sayso-agent/internal/model"
+)
+
+func TestCheckRecipients(t *testing.T) {
+	e := &Executor{aliases: NewAliasBook([]model.Alias{
+		{Name: "产品部", Members: []model.AliasMember{
+			{Platform: "feishu", ID: "ou_pm"},
+			{Platform: "feishu", ID: "partner@vendor.com"},
+		}},
+		{Name: "外包组", Members: []model.AliasMember{{Platform: "feishu", ID: "ou_blocked", EmploymentType: model.EmploymentIntern}}},
+		// 嵌套分组：研发中心 -> 产品部、外包组；只有租户 acme 可用
+		{Name: "研发中心", Tenants: []string{"acme"}, Members: []model.AliasMember{{ID: "产品部"}, {ID: "外包组"}, {Platform: "feishu", ID: "ou_dev"}}},
+		{Name: "公告", Members: []model.AliasMember{{Platform: "feishu", ID: "oc_allhands"}}},
+		// 循环引用只展开一次
+		{Name: "甲", Members: []model.AliasMember{{ID: "乙"}, {Platform: "feishu", ID: "ou_a"}}},
+		{Name: "乙", Members: []model.AliasMember{{ID: "甲"}, {Platform: "feishu", ID: "ou_blocked"}}},
+	})}
+	policy := RecipientPolicy{
+		ConfirmChats:   []string{"oc_allhands"},
+		BlockedUsers:   []string{"ou_blocked"},
+		BlockedDomains: []string{"vendor.com"},
+	}
+	acme := &model.ASRRequest{TenantID: "acme"}
+	other := &model.ASRRequest{TenantID: "other"}
+
+	tests := []struct {
+		name        string
+		targets     []any
+		exclude     []any
+		targetType  string
+		req         *model.ASRRequest
+		confirmed   bool
+		wantErr     error
+		wantConfirm []string
+	}{
+		{name: "allow direct user", targets: []any{"ou_dev"}, req: acme},
+		{name: "block direct user", targets: []any{"ou_blocked"}, req: acme, wantErr: model.ErrRecipientNotAllowed},
+		{name: "block domain in group", targets: []any{"产品部"}, req: acme, wantErr: model.ErrRecipientNotAllowed},
+		{name: "block user in nested group", targets: []any{"研发中心"}, exclude: []any{"partner@vendor.com"}, req: acme, wantErr: model.ErrRecipientNotAllowed},
+		{name: "nested group allowed after exclusion", targets: []any{"研发中心"}, exclude: []any{"partner@vendor.com", "实习生"}, req: acme},
+		{name: "group of another tenant not expanded", targets: []any{"研发中心"}, req: other},
+		{name: "cyclic groups", targets: []any{"甲"}, req: acme, wantErr: model.ErrRecipientNotAllowed},
+		{name: "confirm chat in group", targets: []any{"公告"}, req: acme, wantErr: model.ErrConfirmationRequired, wantConfirm: []string{"oc_allhands"}},
+		{name: "confirm direct chat once", targets: []any{"oc_allhands", "公告"}, targetType: "chat", req: acme, wantErr: model.ErrConfirmationRequired, wantConfirm: []string{"oc_allhands"}},
+		{name: "confirmed chat", targets: []any{"公告"}, req: acme, confirmed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]any{"platform": "feishu", "targets": tt.targets, "target_type": tt.targetType}
+			if tt.exclude != nil {
+				params["exclude"] = tt.exclude
+			}
+			spec := model.ActionSpec{Type: model.ActionTypeSendMessage, Params: params, Confirmed: tt.confirmed}
+			confirm, err := e.checkRecipients(policy, spec, tt.req)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if len(confirm) != len(tt.wantConfirm) || (len(confirm) > 0 && confirm[0] != tt.wantConfirm[0]) {
+				t.Errorf("confirm = %v, want %v", confirm, tt.wantConfirm)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// ExecuteRenameFile 修改云文档名称（"把刚才那个文档改名为《Q3 规划》"），支持文档、电子表格与多维表格
+// params: doc_url 或 doc_name（按名称查找）, title（新名称）
+func (e *FeishuExecutor) ExecuteRenameFile(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	title, _ := spec.Params["title"].(string)
+	title = strings.Trim(strings.TrimSpace(title), "《》")
+	if title == "" {
+		return model.ActionSummary{}, fmt.Errorf("feishu_rename_file: %w: title is required", model.ErrInvalidParams)
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	doc, err := e.resolveDoc(ctx, token, spec)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_rename_file: %w", err)
+	}
+	switch doc.Type {
+	case "docx", "sheet", "bitable":
+	default:
+		return model.ActionSummary{}, fmt.Errorf("feishu_rename_file: %w: 飞书开放接口不支持修改 %s 的名称", model.ErrActionNotSupport, doc.Type)
+	}
+	oldTitle := doc.Name
+	if meta, err := e.Client.GetDocMeta(ctx, token, doc.Token, doc.Type); err == nil {
+		oldTitle = meta.Title
+	}
+
+	summary := model.ActionSummary{Type: "feishu_rename", Target: title, ID: doc.Token, URL: doc.URL}
+	if summary.URL == "" {
+		summary.URL = e.Client.DocURL(feishu.DocPath(doc.Type), doc.Token)
+	}
+	summary.Outputs = map[string]string{"doc_id": doc.Token, "doc_type": doc.Type, "doc_url": summary.URL, "old_title": oldTitle}
+	if oldTitle == title {
+		summary.Note = "名称未变化"
+		return summary, nil
+	}
+	if err := e.Client.RenameFile(ctx, token, doc.Token, doc.Type, title); err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_rename_file: %w", err)
+	}
+	return summary, nil
+}
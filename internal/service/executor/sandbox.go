@@ -0,0 +1,178 @@
+package executor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/model"
+)
+
+// sandboxDomain 未配置飞书域名时沙箱链接使用的域名
+const sandboxDomain = "sandbox.feishu.cn"
+
+// Sandboxed 请求是否以沙箱模式执行：配置开启，或请求 Context["sandbox"] 为 "true"
+func (e *Executor) Sandboxed(req *model.ASRRequest) bool {
+	return e.sandbox || (req != nil && req.Context["sandbox"] == "true")
+}
+
+// executeSandbox 沙箱模式：不调用外部 API，只记录日志并返回与真实执行结构一致的模拟结果（链接、消息 ID 等），
+// 后续动作的占位符照常可用；转移所有者、发短信仍需确认，以便演示完整流程
+func (e *Executor) executeSandbox(spec model.ActionSpec) (model.ActionSummary, error) {
+	// 参数里可能有正文、手机号等敏感内容，只记动作类型与目标数
+	log.Printf("sandbox: %s targets=%d", spec.Type, len(model.StringList(spec.Params["targets"])))
+	domain := e.feishu.Cfg.Domain
+	if domain == "" {
+		domain = sandboxDomain
+	}
+	title, _ := spec.Params["title"].(string)
+	summary := model.ActionSummary{Type: "sandbox_" + spec.Type, Target: title, Note: "沙箱模式，未实际执行"}
+
+	switch spec.Type {
+	case model.ActionTypeCreateDoc, model.ActionTypeMinutesNotes:
+		token := fakeID("doxcn")
+		summary.Type, summary.ID = "feishu_doc", token
+		summary.URL = fmt.Sprintf("https://%s/docx/%s", domain, token)
+		summary.Outputs = map[string]string{"doc_id": token, "doc_url": summary.URL}
+	case model.ActionTypeBulkCreateDoc:
+		pattern := title
+		if !strings.Contains(pattern, bulkItemPlaceholder) {
+			pattern = bulkItemPlaceholder + pattern
+		}
+		items := model.StringList(spec.Params["items"])
+		if len(items) == 0 {
+			items = []string{"示例"}
+		}
+		errs := make([]error, len(items))
+		summaries := make([]model.ActionSummary, len(items))
+		for i, item := range items {
+			token := fakeID("doxcn")
+			summaries[i] = model.ActionSummary{Target: strings.ReplaceAll(pattern, bulkItemPlaceholder, item), ID: token, URL: fmt.Sprintf("https://%s/docx/%s", domain, token)}
+		}
+		bulk, _ := bulkSummary(items, summaries, errs, "")
+		bulk.Note += "\n" + summary.Note
+		return bulk, nil
+	case model.ActionTypeCreateFolder:
+		token := fakeID("fldcn")
+		name, _ := spec.Params["name"].(string)
+		summary.Type, summary.Target, summary.ID = "feishu_folder", name, token
+		summary.URL = fmt.Sprintf("https://%s/drive/folder/%s", domain, token)
+		summary.Outputs = map[string]string{"folder_id": token, "folder_url": summary.URL}
+	case model.ActionTypeFolderTree:
+		paths, name, err := e.feishu.folderTreePaths(spec)
+		if err != nil {
+			return model.ActionSummary{}, fmt.Errorf("feishu_create_folder_tree: %w", err)
+		}
+		root, _ := spec.Params["root"].(string)
+		summary.Type, summary.Target = "feishu_folder_tree", root
+		if root == "" {
+			summary.Target = name
+		}
+		summary.Outputs = map[string]string{"folders": strings.Join(paths, ",")}
+		if root != "" {
+			summary.ID = fakeID("fldcn")
+			summary.URL = fmt.Sprintf("https://%s/drive/folder/%s", domain, summary.ID)
+			summary.Outputs["folder_id"], summary.Outputs["folder_url"] = summary.ID, summary.URL
+		}
+	case model.ActionTypeImportFile:
+		token := fakeID("boxcn")
+		summary.Type, summary.ID = "feishu_file", token
+		summary.URL = fmt.Sprintf("https://%s/file/%s", domain, token)
+		summary.Outputs = map[string]string{"file_token": token, "file_url": summary.URL}
+	case model.ActionTypeTransferOwner:
+		if !spec.Confirmed {
+			docURL, _ := spec.Params["doc_url"].(string)
+			owner, _ := spec.Params["new_owner"].(string)
+			summary.Note = fmt.Sprintf("（沙箱）确认把 %s 的所有者转给 %s 吗？", docURL, owner)
+			return summary, model.ErrConfirmationRequired
+		}
+	case model.ActionTypeSendSMS:
+		channel, _ := spec.Params["channel"].(string)
+		if channel == "" {
+			channel = "sms"
+		}
+		targets := model.StringList(spec.Params["targets"])
+		id := fakeID("SM")
+		summary.Type, summary.ID, summary.Target = channel+"_message", id, strings.Join(targets, "、")
+		if !spec.Confirmed {
+			summary.Note = fmt.Sprintf("（沙箱）确认给 %s 发送短信吗？", summary.Target)
+			return summary, model.ErrConfirmationRequired
+		}
+		summary.Outputs = map[string]string{"message_id": id, "message_ids": id, "recipients": summary.Target}
+	case model.ActionTypeCommentDoc:
+		id := fakeID("")
+		summary.ID = id
+		summary.Outputs = map[string]string{"comment_id": id}
+	case model.ActionTypeScheduleMeet:
+		id := fakeID("")
+		start := time.Now().Add(time.Hour).Truncate(30 * time.Minute)
+		summary.Type, summary.ID = "feishu_calendar_event", id
+		summary.URL = fmt.Sprintf("https://applink.%s/client/calendar/event/detail?eventId=%s", domain, id)
+		summary.Outputs = map[string]string{"event_id": id, "event_url": summary.URL, "start_time": start.Format("2006-01-02 15:04")}
+	case model.ActionTypeAddReaction:
+		messageID, _ := spec.Params["message_id"].(string)
+		summary.Target = messageID
+		summary.Outputs = map[string]string{"reaction_id": fakeID(""), "message_id": messageID}
+	case model.ActionTypeRecallMessage:
+		recipients, _ := spec.Params["recipients"].(string)
+		ids, _ := spec.Params["message_ids"].(string)
+		summary.Type, summary.Target = "feishu_recall", recipients
+		if platform, _ := spec.Params["platform"].(string); platform == "slack" || platform == "discord" {
+			summary.Type = platform + "_delete"
+		}
+		summary.Outputs = map[string]string{"message_ids": ids, "recipients": recipients}
+	case model.ActionTypeRenameFile:
+		docURL, _ := spec.Params["doc_url"].(string)
+		docName, _ := spec.Params["doc_name"].(string)
+		summary.Type, summary.URL = "feishu_rename", docURL
+		summary.Outputs = map[string]string{"doc_url": docURL, "old_title": docName}
+	case model.ActionTypeRenameDoc:
+		docID, _ := spec.Params["doc_id"].(string)
+		oldTitle, _ := spec.Params["old_title"].(string)
+		summary.Type, summary.ID = "feishu_rename", docID
+		summary.URL = fmt.Sprintf("https://%s/docx/%s", domain, docID)
+		summary.Outputs = map[string]string{"doc_id": docID, "doc_url": summary.URL, "old_title": oldTitle}
+	case model.ActionTypeCreateTicket:
+		id := fakeID("")
+		category, _ := spec.Params["category"].(string)
+		summary.Type, summary.ID = "feishu_ticket", id
+		summary.Outputs = map[string]string{"ticket_id": id, "chat_id": fakeID("oc_"), "category": category}
+	case model.ActionTypeQueryTable, model.ActionTypeQueryApproval, model.ActionTypeQueryTasks, model.ActionTypeAskKB:
+		summary.Outputs = map[string]string{"answer": "（沙箱）查询类动作未访问真实数据"}
+	case model.ActionTypeSendMessage, model.ActionTypeExportDoc:
+		params := model.ParseSendMessageParams(spec.Params)
+		var results []model.SendResult
+		for _, t := range params.Targets {
+			results = append(results, model.SendResult{TargetID: t, Success: true, MsgID: fakeID("om_")})
+		}
+		platform := params.Platform
+		if platform == "" {
+			platform = "feishu"
+		}
+		summary.Type = platform + "_message"
+		summary.Target = strings.Join(params.Targets, ",")
+		summary.Outputs = sendResultOutputs(results)
+	}
+	if draft := draftText(spec.Params); draft != "" && summary.Outputs != nil {
+		// 撰写阶段生成的正文随预览返回，便于在沙箱中检查措辞
+		summary.Outputs["draft"] = draft
+	}
+	return summary, nil
+}
+
+// draftText 撰写阶段生成的草稿（params.draft.text），没有时为空
+func draftText(params map[string]any) string {
+	d, _ := params["draft"].(map[string]any)
+	text, _ := d["text"].(string)
+	return text
+}
+
+// fakeID 沙箱中使用的随机 ID
+func fakeID(prefix string) string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}
@@ -0,0 +1,35 @@
+package executor
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"sayso-agent/internal/model"
+)
+
+func TestSandboxLogOmitsParams(t *testing.T) {
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(prev)
+
+	e := &Executor{feishu: &FeishuExecutor{}}
+	spec := model.ActionSpec{Type: model.ActionTypeSendSMS, Confirmed: true, Params: map[string]any{
+		"targets": []any{"13800138000", "13900139000"},
+		"content": "您的验证码是 483921",
+	}}
+	if _, err := e.executeSandbox(spec); err != nil {
+		t.Fatalf("executeSandbox: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "sandbox: "+model.ActionTypeSendSMS+" targets=2") {
+		t.Fatalf("log = %q, want action type and target count", out)
+	}
+	for _, secret := range []string{"13800138000", "483921"} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("log = %q, leaks %q", out, secret)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"sayso-agent/internal/client/feishu"
 	"sayso-agent/internal/client/slack"
 	"sayso-agent/internal/model"
 )
@@ -20,16 +21,20 @@ func NewSlackExecutor(client *slack.Client, cfg slack.Config) *SlackExecutor {
 	return &SlackExecutor{Client: client, Cfg: cfg}
 }
 
-// ExecuteSendMessage 统一发送消息（支持用户、频道、批量）
-func (e *SlackExecutor) ExecuteSendMessage(ctx context.Context, spec model.ActionSpec, _ *model.ASRRequest) (model.ActionSummary, error) {
+// ExecuteSendMessage 统一发送消息（支持用户、频道、批量）；目标可带工作区，如 "#general@emea"
+func (e *SlackExecutor) ExecuteSendMessage(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
 	if !e.Cfg.Enabled {
 		return model.ActionSummary{}, model.ErrSlackDisabled
 	}
+	client, err := e.workspaceClient(req)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
 
 	params := model.ParseSendMessageParams(spec.Params)
 
 	// 构建消息内容
-	text, blocks := e.buildSlackMessage(params)
+	msg := e.buildSlackMessage(params)
 
 	var results []model.SendResult
 
@@ -38,26 +43,31 @@ func (e *SlackExecutor) ExecuteSendMessage(ctx context.Context, spec model.Actio
 		if len(params.Targets) == 0 {
 			return model.ActionSummary{}, fmt.Errorf("send_message: targets is required for user type")
 		}
-		result := e.sendToUser(ctx, params.Targets[0], text, blocks)
+		result := e.sendToUser(ctx, client, params.Targets[0], msg)
 		results = append(results, result)
 
 	case "chat":
 		if len(params.Targets) == 0 {
 			return model.ActionSummary{}, fmt.Errorf("send_message: targets is required for chat type")
 		}
-		result := e.sendToChannel(ctx, params.Targets[0], text, blocks)
+		result := e.sendToChannel(ctx, client, params.Targets[0], msg)
 		results = append(results, result)
 
 	case "batch":
 		for _, target := range params.Targets {
-			result := e.sendToUser(ctx, target, text, blocks)
+			// 联系人分组中可能混有频道，频道直接发送，用户先打开私聊
+			if isSlackChannel(target) {
+				results = append(results, e.sendToChannel(ctx, client, target, msg))
+				continue
+			}
+			result := e.sendToUser(ctx, client, target, msg)
 			results = append(results, result)
 		}
 
 	default:
 		// 默认按频道处理
 		if len(params.Targets) > 0 {
-			result := e.sendToChannel(ctx, params.Targets[0], text, blocks)
+			result := e.sendToChannel(ctx, client, params.Targets[0], msg)
 			results = append(results, result)
 		} else {
 			return model.ActionSummary{}, fmt.Errorf("send_message: targets is required")
@@ -67,29 +77,54 @@ func (e *SlackExecutor) ExecuteSendMessage(ctx context.Context, spec model.Actio
 	return e.buildSendMessageSummary(results), nil
 }
 
-// buildSlackMessage 根据消息类型构建 Slack 消息内容
-func (e *SlackExecutor) buildSlackMessage(params model.SendMessageParams) (text string, blocks []slack.Block) {
-	text = params.Content.Text
+// buildSlackMessage 根据消息类型构建 Slack 消息内容；卡片已带链接按钮，默认不再展开链接预览，避免同一链接出现两次
+func (e *SlackExecutor) buildSlackMessage(params model.SendMessageParams) slack.Message {
+	msg := slack.Message{Text: params.Content.Text}
 
 	switch params.MessageType {
 	case "rich_text", "link_card":
-		blocks = slack.BuildRichTextBlocks(
-			params.Content.Title,
-			params.Content.Text,
-			params.Content.URL,
-			params.Content.Description,
-		)
+		msg.Blocks = slack.RenderBlocks(messageCard(params))
+		if params.Content.URL != "" {
+			off := false
+			msg.UnfurlLinks, msg.UnfurlMedia = &off, &off
+		}
 	default:
 		// text 类型不需要 blocks
 	}
+	if params.Unfurl != nil {
+		msg.UnfurlLinks, msg.UnfurlMedia = params.Unfurl, params.Unfurl
+	}
 
-	return text, blocks
+	return msg
+}
+
+// workspaceClient 请求默认使用的工作区：Context["slack_team_id"] 优先，其次为租户的默认工作区
+func (e *SlackExecutor) workspaceClient(req *model.ASRRequest) (*slack.Client, error) {
+	if req == nil {
+		return e.Client, nil
+	}
+	if team := req.Context["slack_team_id"]; team != "" {
+		return e.Client.In(team)
+	}
+	return e.Client.ForTenant(req.Tenant()), nil
+}
+
+// resolveTarget 目标带 "@工作区" 时切换到该工作区，返回客户端与去掉后缀的目标
+func resolveTarget(client *slack.Client, target string) (*slack.Client, string, error) {
+	id, workspace := slack.ParseTarget(target)
+	c, err := client.In(workspace)
+	return c, id, err
 }
 
 // sendToUser 发送私聊消息给用户
-func (e *SlackExecutor) sendToUser(ctx context.Context, userID, text string, blocks []slack.Block) model.SendResult {
+func (e *SlackExecutor) sendToUser(ctx context.Context, client *slack.Client, target string, msg slack.Message) model.SendResult {
+	userID := target
+	client, id, err := resolveTarget(client, target)
+	if err != nil {
+		return model.SendResult{TargetID: userID, Error: err.Error()}
+	}
 	// 先打开私聊会话
-	channelID, err := e.Client.OpenConversation(ctx, userID)
+	channelID, err := client.OpenConversation(ctx, id)
 	if err != nil {
 		return model.SendResult{
 			TargetID: userID,
@@ -99,7 +134,7 @@ func (e *SlackExecutor) sendToUser(ctx context.Context, userID, text string, blo
 	}
 
 	// 发送消息
-	result, err := e.Client.SendMessageWithBlocks(ctx, channelID, text, blocks)
+	result, err := client.PostMessage(ctx, channelID, msg)
 	if err != nil {
 		return model.SendResult{
 			TargetID: userID,
@@ -112,12 +147,20 @@ func (e *SlackExecutor) sendToUser(ctx context.Context, userID, text string, blo
 		TargetID: userID,
 		Success:  true,
 		MsgID:    result.Timestamp,
+		ChatID:   result.Channel,
 	}
 }
 
 // sendToChannel 发送消息到频道
-func (e *SlackExecutor) sendToChannel(ctx context.Context, channel, text string, blocks []slack.Block) model.SendResult {
-	result, err := e.Client.SendMessageWithBlocks(ctx, channel, text, blocks)
+func (e *SlackExecutor) sendToChannel(ctx context.Context, client *slack.Client, target string, msg slack.Message) model.SendResult {
+	channel := target
+	client, id, err := resolveTarget(client, target)
+	if err != nil {
+		return model.SendResult{TargetID: channel, Error: err.Error()}
+	}
+	// 发往请求所在的频道时回复到 thread 中
+	msg.ThreadTS = threadFrom(ctx).slackThread(id)
+	result, err := client.PostMessage(ctx, id, msg)
 	if err != nil {
 		return model.SendResult{
 			TargetID: channel,
@@ -130,6 +173,7 @@ func (e *SlackExecutor) sendToChannel(ctx context.Context, channel, text string,
 		TargetID: channel,
 		Success:  true,
 		MsgID:    result.Timestamp,
+		ChatID:   result.Channel,
 	}
 }
 
@@ -146,7 +190,8 @@ func (e *SlackExecutor) buildSendMessageSummary(results []model.SendResult) mode
 	}
 
 	summary := model.ActionSummary{
-		Type: "slack_message",
+		Type:    "slack_message",
+		Outputs: sendResultOutputs(results),
 	}
 
 	if len(results) == 1 {
@@ -165,3 +210,79 @@ func (e *SlackExecutor) buildSendMessageSummary(results []model.SendResult) mode
 
 	return summary
 }
+
+// isSlackChannel 判断是否是频道（#名称，或 C/G 开头的频道 ID），忽略 "@工作区" 后缀
+func isSlackChannel(target string) bool {
+	id, _ := slack.ParseTarget(target)
+	if strings.HasPrefix(id, "#") {
+		return true
+	}
+	return len(id) > 1 && (id[0] == 'C' || id[0] == 'G') && strings.ToUpper(id) == id
+}
+
+// SendFile 上传文件并分享给各目标：频道直接分享，用户先打开私聊
+func (e *SlackExecutor) SendFile(ctx context.Context, file feishu.ExportedFile, targets []string, req *model.ASRRequest) []model.SendResult {
+	var results []model.SendResult
+	for _, target := range targets {
+		if !e.Cfg.Enabled {
+			results = append(results, model.SendResult{TargetID: target, Error: model.ErrSlackDisabled.Error()})
+			continue
+		}
+		client, err := e.workspaceClient(req)
+		if err == nil {
+			client, _, err = resolveTarget(client, target)
+		}
+		if err != nil {
+			results = append(results, model.SendResult{TargetID: target, Error: err.Error()})
+			continue
+		}
+		channelID, _ := slack.ParseTarget(target)
+		if isSlackChannel(target) {
+			// 文件上传只接受频道 ID，"#general" 按频道列表解析
+			if channelID, err = client.ChannelID(ctx, channelID); err != nil {
+				results = append(results, model.SendResult{TargetID: target, Error: err.Error()})
+				continue
+			}
+		} else {
+			id, err := client.OpenConversation(ctx, channelID)
+			if err != nil {
+				results = append(results, model.SendResult{TargetID: target, Error: fmt.Sprintf("open conversation failed: %s", err.Error())})
+				continue
+			}
+			channelID = id
+		}
+		fileID, err := client.UploadFile(ctx, channelID, file.Name, file.Name, file.Data)
+		if err != nil {
+			results = append(results, model.SendResult{TargetID: target, Error: err.Error()})
+			continue
+		}
+		results = append(results, model.SendResult{TargetID: target, Success: true, MsgID: fileID, ChatID: channelID})
+	}
+	return results
+}
+
+// SendImage 上传图片并分享给各目标，caption 非空时先发一条文字
+func (e *SlackExecutor) SendImage(ctx context.Context, name string, data []byte, caption string, targets []string, req *model.ASRRequest) []model.SendResult {
+	var results []model.SendResult
+	for _, target := range targets {
+		result := model.SendResult{TargetID: target, Success: true}
+		if caption != "" {
+			client, err := e.workspaceClient(req)
+			switch {
+			case !e.Cfg.Enabled:
+				result = model.SendResult{TargetID: target, Error: model.ErrSlackDisabled.Error()}
+			case err != nil:
+				result = model.SendResult{TargetID: target, Error: err.Error()}
+			case isSlackChannel(target):
+				result = e.sendToChannel(ctx, client, target, slack.Message{Text: caption})
+			default:
+				result = e.sendToUser(ctx, client, target, slack.Message{Text: caption})
+			}
+		}
+		if result.Success && data != nil {
+			result = e.SendFile(ctx, feishu.ExportedFile{Name: name, Ext: "png", Data: data}, []string{target}, req)[0]
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,181 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"sayso-agent/internal/client/sms"
+	"sayso-agent/internal/model"
+)
+
+// 短信/WhatsApp 会发到聊天平台之外、按条计费且无法撤回，因此比 send_message 更严格：
+// 号码只取自请求 Contacts 中的 phone 或用户亲口说出的号码（不采用大模型给出的号码），
+// 所有接收人解析通过才发送，默认每次发送前请用户确认接收人与内容
+
+// 单次发送的默认上限
+const (
+	defaultSMSMaxRecipients = 5
+	defaultSMSMaxChars      = 500
+)
+
+// SMSExecutor 短信/WhatsApp 动作执行器
+type SMSExecutor struct {
+	Client *sms.Client
+	Cfg    sms.Config
+}
+
+// NewSMSExecutor 创建短信执行器
+func NewSMSExecutor(client *sms.Client, cfg sms.Config) *SMSExecutor {
+	return &SMSExecutor{Client: client, Cfg: cfg}
+}
+
+// smsRecipient 解析后的接收人
+type smsRecipient struct {
+	target string // 用户说的名字或号码
+	phone  string // E.164 号码
+}
+
+// ExecuteSendSMS 发送短信或 WhatsApp 消息
+// params: channel(sms|whatsapp), targets（联系人名字或号码）, text
+func (e *SMSExecutor) ExecuteSendSMS(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrSMSDisabled
+	}
+	channel, _ := spec.Params["channel"].(string)
+	if channel == "" {
+		channel = sms.ChannelSMS
+	}
+	if channel != sms.ChannelSMS && channel != sms.ChannelWhatsApp {
+		return model.ActionSummary{}, fmt.Errorf("send_sms: %w: unknown channel %q", model.ErrInvalidParams, channel)
+	}
+	text, _ := spec.Params["text"].(string)
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return model.ActionSummary{}, fmt.Errorf("send_sms: %w: text is required", model.ErrInvalidParams)
+	}
+	if limit := positiveOr(e.Cfg.MaxChars, defaultSMSMaxChars); utf8.RuneCountInString(text) > limit {
+		return model.ActionSummary{}, fmt.Errorf("send_sms: %w: text exceeds %d characters", model.ErrInvalidParams, limit)
+	}
+	targets := model.StringList(spec.Params["targets"])
+	if len(targets) == 0 {
+		return model.ActionSummary{}, fmt.Errorf("send_sms: %w: targets is required", model.ErrInvalidParams)
+	}
+	if limit := positiveOr(e.Cfg.MaxRecipients, defaultSMSMaxRecipients); len(targets) > limit {
+		return model.ActionSummary{}, fmt.Errorf("send_sms: %w: %d recipients exceeds the limit of %d", model.ErrLimitExceeded, len(targets), limit)
+	}
+
+	recipients := make([]smsRecipient, 0, len(targets))
+	for _, t := range targets {
+		phone, err := e.resolvePhone(t, req)
+		if err != nil {
+			return model.ActionSummary{}, fmt.Errorf("send_sms: %w", err)
+		}
+		recipients = append(recipients, smsRecipient{target: t, phone: phone})
+	}
+
+	summary := model.ActionSummary{Type: channel + "_message", Target: strings.Join(targets, "、")}
+	if !spec.Confirmed && !e.Cfg.SkipConfirm {
+		var who []string
+		for _, r := range recipients {
+			who = append(who, fmt.Sprintf("%s（%s）", r.target, sms.MaskPhone(r.phone)))
+		}
+		kind := "短信"
+		if channel == sms.ChannelWhatsApp {
+			kind = " WhatsApp 消息"
+		}
+		summary.Note = fmt.Sprintf("将给 %s 发送%s：「%s」，请确认", strings.Join(who, "、"), kind, text)
+		return summary, model.ErrConfirmationRequired
+	}
+
+	var sent, failed []string
+	for _, r := range recipients {
+		result, err := e.Client.Send(ctx, channel, r.phone, text)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", r.target, err))
+			continue
+		}
+		sent = append(sent, result.SID)
+	}
+	if len(sent) == 0 {
+		return model.ActionSummary{}, fmt.Errorf("send_sms: %s", strings.Join(failed, "；"))
+	}
+	summary.ID = sent[0]
+	summary.Outputs = map[string]string{"message_id": sent[0], "message_ids": strings.Join(sent, ","), "recipients": summary.Target}
+	if len(failed) > 0 {
+		summary.Note = "failed: " + strings.Join(failed, "；")
+	}
+	return summary, nil
+}
+
+// resolvePhone 把接收人解析为号码：名字按 Contacts 查找 phone；号码须出现在 Contacts 或请求原文中，
+// 并符合配置的号码前缀
+func (e *SMSExecutor) resolvePhone(target string, req *model.ASRRequest) (string, error) {
+	var phone string
+	if req != nil {
+		name := normalizeContact(target)
+		for _, c := range req.Contacts {
+			if normalizeContact(c.Name) != name {
+				continue
+			}
+			if c.Phone == "" {
+				return "", fmt.Errorf("%w: 联系人 %s 没有手机号", model.ErrRecipientNotAllowed, target)
+			}
+			p, ok := sms.NormalizePhone(c.Phone, e.Cfg.DefaultCountryCode)
+			if !ok {
+				return "", fmt.Errorf("%w: 联系人 %s 的手机号 %q 无效", model.ErrInvalidParams, target, c.Phone)
+			}
+			phone = p
+			break
+		}
+	}
+	if phone == "" {
+		p, ok := sms.NormalizePhone(target, e.Cfg.DefaultCountryCode)
+		if !ok {
+			return "", fmt.Errorf("%w: 请求的联系人中没有 %s 的手机号", model.ErrRecipientNotAllowed, target)
+		}
+		if !e.knownPhone(p, req) {
+			return "", fmt.Errorf("%w: %s 不在请求的联系人中，也不是用户说出的号码", model.ErrRecipientNotAllowed, target)
+		}
+		phone = p
+	}
+	if len(e.Cfg.AllowedPrefixes) > 0 && !hasAnyPrefix(phone, e.Cfg.AllowedPrefixes) {
+		return "", fmt.Errorf("%w: %s 不在允许的号码范围（%s）内", model.ErrRecipientNotAllowed, sms.MaskPhone(phone), strings.Join(e.Cfg.AllowedPrefixes, "、"))
+	}
+	return phone, nil
+}
+
+// knownPhone 号码是否来自 Contacts 或请求原文（按数字比较，忽略格式与国家码写法）
+func (e *SMSExecutor) knownPhone(phone string, req *model.ASRRequest) bool {
+	if req == nil {
+		return false
+	}
+	for _, c := range req.Contacts {
+		if p, ok := sms.NormalizePhone(c.Phone, e.Cfg.DefaultCountryCode); ok && p == phone {
+			return true
+		}
+	}
+	digits := onlyDigits(req.Text)
+	national := strings.TrimPrefix(phone, "+"+strings.TrimPrefix(e.Cfg.DefaultCountryCode, "+"))
+	return strings.Contains(digits, strings.TrimPrefix(phone, "+")) || (national != phone && len(national) >= 7 && strings.Contains(digits, national))
+}
+
+func onlyDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if p = strings.TrimSpace(p); p != "" && strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// executeSetStatus 设置请求人的个人状态，按 platform 路由
+// params: platform(feishu|slack), status, until("2006-01-02 15:04"), clear(bool)
+func (e *Executor) executeSetStatus(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if platform, _ := spec.Params["platform"].(string); platform == "slack" {
+		// Slack 的 users.profile.set / dnd.setSnooze 只接受用户 token，需先支持用户 OAuth 授权
+		return model.ActionSummary{}, fmt.Errorf("%w: slack status requires user authorization", model.ErrActionNotSupport)
+	}
+	return e.feishu.ExecuteSetStatus(ctx, spec, req)
+}
+
+// ExecuteSetStatus 为请求人开启租户配置的系统状态（请假、出差等），到 until 自动失效；clear 为 true 时关闭该状态
+func (e *FeishuExecutor) ExecuteSetStatus(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	requester := requesterID(req)
+	if !isOpenID(requester) {
+		return model.ActionSummary{}, fmt.Errorf("set_status: %w: requester open_id unknown", model.ErrInvalidParams)
+	}
+	name, _ := spec.Params["status"].(string)
+	clear, _ := spec.Params["clear"].(bool)
+	now := time.Now()
+	until := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 0, 0, now.Location())
+	if s, _ := spec.Params["until"].(string); s != "" {
+		t, err := time.ParseInLocation("2006-01-02 15:04", s, now.Location())
+		if err != nil {
+			return model.ActionSummary{}, fmt.Errorf("set_status: %w: invalid until %q", model.ErrInvalidParams, s)
+		}
+		until = t
+	}
+	if !clear && !until.After(now) {
+		return model.ActionSummary{}, fmt.Errorf("set_status: %w: until %s is in the past", model.ErrInvalidParams, until.Format("01-02 15:04"))
+	}
+
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	statuses, err := e.Client.ListSystemStatuses(ctx, token)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	status, ok := findSystemStatus(statuses, name)
+	if !ok {
+		var titles []string
+		for _, s := range statuses {
+			titles = append(titles, s.Title)
+		}
+		return model.ActionSummary{}, fmt.Errorf("set_status: %w: status %q not configured, available: %s", model.ErrInvalidParams, name, strings.Join(titles, "、"))
+	}
+
+	summary := model.ActionSummary{Type: "feishu_status", Target: status.Title, ID: status.ID}
+	if clear {
+		if err := e.Client.CloseSystemStatus(ctx, token, status.ID, requester); err != nil {
+			return model.ActionSummary{}, err
+		}
+		summary.Note = fmt.Sprintf("已取消「%s」状态", status.Title)
+		return summary, nil
+	}
+	if err := e.Client.OpenSystemStatus(ctx, token, status.ID, requester, until); err != nil {
+		return model.ActionSummary{}, err
+	}
+	summary.Note = fmt.Sprintf("状态已设为「%s」，%s 自动恢复", status.Title, until.Format("01-02 15:04"))
+	return summary, nil
+}
+
+// findSystemStatus 按名称匹配系统状态：先精确匹配，再互相包含（如"请假"匹配"请假中"）
+func findSystemStatus(statuses []feishu.SystemStatus, name string) (feishu.SystemStatus, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return feishu.SystemStatus{}, false
+	}
+	for _, s := range statuses {
+		if s.Title == name {
+			return s, true
+		}
+	}
+	for _, s := range statuses {
+		if strings.Contains(s.Title, name) || strings.Contains(name, s.Title) {
+			return s, true
+		}
+	}
+	return feishu.SystemStatus{}, false
+}
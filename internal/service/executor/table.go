@@ -0,0 +1,310 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// tableRowLimit 单次查询读取的最大行数
+const tableRowLimit = 1000
+
+// TableAnalyst 表格数据问答（由 llm.TableAnalyst 等实现，避免循环依赖）
+type TableAnalyst interface {
+	AnswerTableQuestion(ctx context.Context, question, description, table string) (model.TableAnswer, error)
+}
+
+// executeQueryTable 查询表格并回答；回答附带图表数据时绘制为图片，与回答一起发给 targets，
+// 未指定 targets 时回答只进入回复，图片发给请求人
+// params: table, question, platform(feishu|slack), targets
+func (e *Executor) executeQueryTable(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	source, answer, err := e.feishu.QueryTable(ctx, spec)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	summary := model.ActionSummary{Type: "feishu_table_query", Target: source.Name, URL: source.URL, Note: answer.Text}
+	summary.Outputs = map[string]string{"answer": answer.Text}
+
+	var image []byte
+	legend := ""
+	name := "chart.png"
+	if answer.Chart != nil {
+		if answer.Chart.Title != "" {
+			name = answer.Chart.Title + ".png"
+		}
+		if image, err = e.charts.Render(*answer.Chart); err != nil {
+			summary.Note += "；图表生成失败: " + err.Error()
+		} else if l := e.charts.Legend(*answer.Chart); l != "" {
+			legend = "图中序号：" + l
+		}
+	}
+	chartURL := e.saveArtifact(ctx, "charts", name, "image/png", image)
+	if chartURL != "" {
+		summary.Outputs["chart_url"] = chartURL
+	}
+
+	params := model.ParseSendMessageParams(spec.Params)
+	targets, targetType := params.Targets, params.TargetType
+	text := answer.Text + "\n" + legend
+	if len(targets) == 0 {
+		requester := requesterID(req)
+		if image == nil || requester == "" {
+			if chartURL != "" {
+				summary.Note += "\n图表：" + chartURL
+			}
+			return summary, nil
+		}
+		targets, targetType, text = []string{requester}, "user", legend
+	}
+
+	var results []model.SendResult
+	switch params.Platform {
+	case "slack":
+		results = e.slack.SendImage(ctx, name, image, strings.TrimSpace(text), targets, req)
+	default:
+		results = e.feishu.SendImage(ctx, name, image, strings.TrimSpace(text), targets, targetType)
+	}
+	for k, v := range sendResultOutputs(results) {
+		summary.Outputs[k] = v
+	}
+	var failed []string
+	for _, r := range results {
+		if !r.Success {
+			failed = append(failed, fmt.Sprintf("%s（%s）", r.TargetID, r.Error))
+		}
+	}
+	if len(failed) > 0 {
+		summary.Note += "；发送失败：" + strings.Join(failed, "；")
+	}
+	if len(params.Targets) > 0 && len(failed) == len(results) {
+		return summary, fmt.Errorf("feishu_query_table: send answer failed: %s", strings.Join(failed, "；"))
+	}
+	return summary, nil
+}
+
+// QueryTable 读取配置的电子表格/多维表格，由大模型按问题统计汇总；只读
+func (e *FeishuExecutor) QueryTable(ctx context.Context, spec model.ActionSpec) (feishu.TableSource, model.TableAnswer, error) {
+	if !e.Cfg.Enabled {
+		return feishu.TableSource{}, model.TableAnswer{}, model.ErrFeishuDisabled
+	}
+	if e.Analyst == nil {
+		return feishu.TableSource{}, model.TableAnswer{}, fmt.Errorf("feishu_query_table: analyst not configured")
+	}
+	question, _ := spec.Params["question"].(string)
+	if question == "" {
+		return feishu.TableSource{}, model.TableAnswer{}, fmt.Errorf("feishu_query_table: %w: question is required", model.ErrInvalidParams)
+	}
+	tableParam, _ := spec.Params["table"].(string)
+	source, err := e.findTable(tableParam)
+	if err != nil {
+		return feishu.TableSource{}, model.TableAnswer{}, fmt.Errorf("feishu_query_table: %w: %v", model.ErrInvalidParams, err)
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return feishu.TableSource{}, model.TableAnswer{}, err
+	}
+	data, err := e.readTable(ctx, token, source.URL)
+	if err != nil {
+		return feishu.TableSource{}, model.TableAnswer{}, err
+	}
+	answer, err := e.Analyst.AnswerTableQuestion(ctx, question, source.Description, data)
+	if err != nil {
+		return feishu.TableSource{}, model.TableAnswer{}, fmt.Errorf("answer table question: %w", err)
+	}
+	return source, answer, nil
+}
+
+// SendImage 上传图片后发给各目标，caption 非空时先发一条文字；targetType 为 chat 时目标按群 ID 发送，否则按用户识别
+func (e *FeishuExecutor) SendImage(ctx context.Context, name string, data []byte, caption string, targets []string, targetType string) []model.SendResult {
+	fail := func(err error) []model.SendResult {
+		results := make([]model.SendResult, len(targets))
+		for i, t := range targets {
+			results[i] = model.SendResult{TargetID: t, Error: err.Error()}
+		}
+		return results
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	imageKey := ""
+	if data != nil {
+		if imageKey, err = e.Client.UploadIMImage(ctx, token, name, data); err != nil {
+			return fail(err)
+		}
+	}
+	if targetType != "chat" {
+		targetType = "user"
+	}
+	var results []model.SendResult
+	for _, target := range targets {
+		result := model.SendResult{TargetID: target, Success: true}
+		if caption != "" {
+			result = e.sendToTarget(ctx, token, target, targetType, "text", feishu.BuildTextContent(caption))
+		}
+		if result.Success && imageKey != "" {
+			result = e.sendToTarget(ctx, token, target, targetType, "image", feishu.BuildImageContent(imageKey))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// findTable 按名称在配置的数据表中查找；传入表格链接时只接受配置中的表格（同一文档，链接带子表时子表也须一致），
+// 不在配置中的表格一律拒绝；只配置了一张表且未指定时用该表
+func (e *FeishuExecutor) findTable(name string) (feishu.TableSource, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		if len(e.Cfg.Tables) == 1 {
+			return e.Cfg.Tables[0], nil
+		}
+		return feishu.TableSource{}, fmt.Errorf("table is required")
+	}
+	if strings.Contains(name, "/") {
+		token, _, subID, err := feishu.ParseTableURL(name)
+		if err != nil {
+			return feishu.TableSource{}, err
+		}
+		for _, t := range e.Cfg.Tables {
+			tToken, _, tSubID, err := feishu.ParseTableURL(t.URL)
+			if err == nil && tToken == token && (subID == "" || subID == tSubID) {
+				return t, nil
+			}
+		}
+		return feishu.TableSource{}, fmt.Errorf("table %q not configured", name)
+	}
+	for _, t := range e.Cfg.Tables {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	for _, t := range e.Cfg.Tables {
+		if strings.Contains(t.Name, name) || strings.Contains(name, t.Name) {
+			return t, nil
+		}
+	}
+	return feishu.TableSource{}, fmt.Errorf("table %q not configured", name)
+}
+
+// readTable 读取表格为制表符分隔的文本，首行为表头
+func (e *FeishuExecutor) readTable(ctx context.Context, token, tableURL string) (string, error) {
+	tableToken, tableType, subID, err := feishu.ParseTableURL(tableURL)
+	if err != nil {
+		return "", fmt.Errorf("feishu_query_table: %w: %v", model.ErrInvalidParams, err)
+	}
+	if tableType == "sheet" {
+		if subID == "" {
+			sheets, err := e.Client.ListSheets(ctx, token, tableToken)
+			if err != nil {
+				return "", err
+			}
+			if len(sheets) == 0 {
+				return "", fmt.Errorf("feishu_query_table: spreadsheet has no sheets")
+			}
+			subID = sheets[0].SheetID
+		}
+		rows, err := e.Client.ReadSheetValues(ctx, token, tableToken, subID)
+		if err != nil {
+			return "", err
+		}
+		if len(rows) > tableRowLimit+1 {
+			rows = rows[:tableRowLimit+1]
+		}
+		return formatRows(rows), nil
+	}
+
+	if subID == "" {
+		tables, err := e.Client.ListBitableTables(ctx, token, tableToken)
+		if err != nil {
+			return "", err
+		}
+		if len(tables) == 0 {
+			return "", fmt.Errorf("feishu_query_table: bitable has no tables")
+		}
+		subID = tables[0].TableID
+	}
+	records, err := e.Client.ListBitableRecords(ctx, token, tableToken, subID, tableRowLimit)
+	if err != nil {
+		return "", err
+	}
+	return formatRecords(records), nil
+}
+
+// formatRows 将单元格行拼为文本，跳过空行
+func formatRows(rows [][]any) string {
+	var lines []string
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		empty := true
+		for i, v := range row {
+			cells[i] = cellText(v)
+			if cells[i] != "" {
+				empty = false
+			}
+		}
+		if !empty {
+			lines = append(lines, strings.Join(cells, "\t"))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatRecords 将多维表格记录拼为文本，表头为所有记录字段名的并集
+func formatRecords(records []map[string]any) string {
+	seen := make(map[string]bool)
+	var fields []string
+	for _, r := range records {
+		for k := range r {
+			if !seen[k] {
+				seen[k] = true
+				fields = append(fields, k)
+			}
+		}
+	}
+	sort.Strings(fields)
+	lines := []string{strings.Join(fields, "\t")}
+	for _, r := range records {
+		cells := make([]string, len(fields))
+		for i, f := range fields {
+			cells[i] = cellText(r[f])
+		}
+		lines = append(lines, strings.Join(cells, "\t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cellText 单元格值转文本；多维表格的人员、链接等复合字段取其中的文本
+func cellText(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.ReplaceAll(strings.ReplaceAll(x, "\t", " "), "\n", " ")
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []any:
+		parts := make([]string, 0, len(x))
+		for _, item := range x {
+			if t := cellText(item); t != "" {
+				parts = append(parts, t)
+			}
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		for _, k := range []string{"text", "name", "link", "en_name"} {
+			if s, ok := x[k].(string); ok && s != "" {
+				return cellText(s)
+			}
+		}
+		b, _ := json.Marshal(x)
+		return string(b)
+	default:
+		return fmt.Sprint(x)
+	}
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"sayso-agent/internal/client/feishu"
+)
+
+func TestFindTableAllowlist(t *testing.T) {
+	e := &FeishuExecutor{Cfg: feishu.Config{Tables: []feishu.TableSource{
+		{Name: "销售台账", URL: "https://acme.feishu.cn/base/bascSales?table=tblOrders"},
+		{Name: "预算", URL: "https://acme.feishu.cn/sheets/shtBudget"},
+	}}}
+	tests := []struct {
+		name  string
+		table string
+		want  string // 命中的配置表名，空表示应拒绝
+	}{
+		{name: "by name", table: "销售台账", want: "销售台账"},
+		{name: "partial name", table: "销售", want: "销售台账"},
+		{name: "configured url", table: "https://acme.feishu.cn/base/bascSales?table=tblOrders", want: "销售台账"},
+		{name: "configured doc without sub table", table: "https://acme.feishu.cn/base/bascSales", want: "销售台账"},
+		{name: "configured sheet url", table: "https://acme.feishu.cn/sheets/shtBudget", want: "预算"},
+		{name: "other sub table of configured doc", table: "https://acme.feishu.cn/base/bascSales?table=tblSalary", want: ""},
+		{name: "unconfigured url", table: "https://acme.feishu.cn/base/bascHR?table=tblSalary", want: ""},
+		{name: "unconfigured sheet", table: "https://acme.feishu.cn/sheets/shtPayroll", want: ""},
+		{name: "not a table url", table: "http://169.254.169.254/latest", want: ""},
+		{name: "unknown name", table: "工资表", want: ""},
+		{name: "ambiguous empty", table: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.findTable(tt.table)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("findTable(%q) = %+v, want error", tt.table, got)
+				}
+				return
+			}
+			if err != nil || got.Name != tt.want {
+				t.Fatalf("findTable(%q) = %+v, %v; want %s", tt.table, got, err, tt.want)
+			}
+		})
+	}
+}
+
+// feishuIMStub 模拟飞书鉴权、图片上传与发消息接口，记下发消息的 receive_id_type；其他接口返回错误
+type feishuIMStub struct {
+	mu    sync.Mutex
+	types []string
+}
+
+func (s *feishuIMStub) RoundTrip(r *http.Request) (*http.Response, error) {
+	body := `{"code":99991663,"msg":"not stubbed"}`
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/tenant_access_token/internal"):
+		body = `{"code":0,"tenant_access_token":"t-test","expire":7200}`
+	case strings.HasSuffix(r.URL.Path, "/im/v1/images"):
+		body = `{"code":0,"data":{"image_key":"img_1"}}`
+	case strings.HasSuffix(r.URL.Path, "/im/v1/messages"):
+		s.mu.Lock()
+		s.types = append(s.types, r.URL.Query().Get("receive_id_type"))
+		s.mu.Unlock()
+		body = `{"code":0,"data":{"message_id":"om_1","chat_id":"oc_1"}}`
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header), Request: r}, nil
+}
+
+func TestFeishuSendImageTargetType(t *testing.T) {
+	stub := &feishuIMStub{}
+	cfg := feishu.Config{AppID: "cli_test", AppSecret: "secret", Region: "feishu", Enabled: true, Transport: stub}
+	e := &FeishuExecutor{Client: feishu.NewClient(cfg), Cfg: cfg}
+
+	// 群 ID 不带 oc_ 前缀时只有按 chat 发送才能送达
+	results := e.SendImage(context.Background(), "chart.png", []byte("png"), "销售额", []string{"team-weekly"}, "chat")
+	if len(results) != 1 || !results[0].Success {
+		t.Fatalf("results = %+v, want success", results)
+	}
+	if len(stub.types) != 2 || stub.types[0] != "chat_id" || stub.types[1] != "chat_id" {
+		t.Errorf("receive_id_type = %v, want chat_id for caption and image", stub.types)
+	}
+}
@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"context"
+
+	"sayso-agent/internal/model"
+)
+
+// chatThread 请求所在的话题：发往请求来源会话（飞书群聊、Slack 频道）的消息回复到话题中，而不是另起一条
+type chatThread struct {
+	FeishuChatID  string
+	FeishuRootID  string
+	SlackChannel  string
+	SlackThreadTS string
+}
+
+// threadKey 请求所在话题，由 Execute 写入
+type threadKey struct{}
+
+func withThread(ctx context.Context, req *model.ASRRequest) context.Context {
+	if req == nil || req.ThreadID() == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, threadKey{}, chatThread{
+		FeishuChatID:  req.Context["feishu_chat_id"],
+		FeishuRootID:  req.Context["feishu_root_id"],
+		SlackChannel:  req.Context["slack_channel"],
+		SlackThreadTS: req.Context["slack_thread_ts"],
+	})
+}
+
+func threadFrom(ctx context.Context) chatThread {
+	t, _ := ctx.Value(threadKey{}).(chatThread)
+	return t
+}
+
+// feishuRoot 发往飞书会话 chatID 的消息应回复到的话题根消息，不在该会话的话题中时为空
+func (t chatThread) feishuRoot(chatID string) string {
+	if t.FeishuRootID == "" || t.FeishuChatID == "" || t.FeishuChatID != chatID {
+		return ""
+	}
+	return t.FeishuRootID
+}
+
+// slackThread 发往 Slack 频道 channel 的消息应回复到的 thread_ts，不在该频道的 thread 中时为空
+func (t chatThread) slackThread(channel string) string {
+	if t.SlackThreadTS == "" || t.SlackChannel != channel {
+		return ""
+	}
+	return t.SlackThreadTS
+}
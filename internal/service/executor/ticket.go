@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/model"
+)
+
+// ExecuteCreateTicket 以请求人身份在飞书服务台提交 IT 工单，问题描述与截图发到工单会话，返回工单号
+// params: title, description, category, screenshots（附件名或序号，省略时附上请求中的全部图片附件）
+func (e *FeishuExecutor) ExecuteCreateTicket(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if !e.Cfg.Enabled {
+		return model.ActionSummary{}, model.ErrFeishuDisabled
+	}
+	if !e.Client.HelpdeskEnabled() {
+		return model.ActionSummary{}, fmt.Errorf("feishu_create_ticket: %w: helpdesk not configured", model.ErrActionNotSupport)
+	}
+	requester := requesterID(req)
+	if !isOpenID(requester) {
+		return model.ActionSummary{}, fmt.Errorf("feishu_create_ticket: %w: requester open_id unknown", model.ErrInvalidParams)
+	}
+	title, _ := spec.Params["title"].(string)
+	description, _ := spec.Params["description"].(string)
+	title, description = strings.TrimSpace(title), strings.TrimSpace(description)
+	if title == "" && description == "" {
+		return model.ActionSummary{}, fmt.Errorf("feishu_create_ticket: %w: title or description is required", model.ErrInvalidParams)
+	}
+	if title == "" {
+		title = firstLine(description)
+	}
+	screenshots, err := ticketScreenshots(spec, req)
+	if err != nil {
+		return model.ActionSummary{}, fmt.Errorf("feishu_create_ticket: %w", err)
+	}
+
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	// 分类为大模型从描述中提取的名称，按服务台实际配置的分类归一；匹配不到时不设分类，由客服处理
+	var category string
+	if name, _ := spec.Params["category"].(string); strings.TrimSpace(name) != "" {
+		categories, err := e.Client.ListHelpdeskCategories(ctx, token)
+		if err != nil {
+			return model.ActionSummary{}, err
+		}
+		category, _ = findHelpdeskCategory(categories, name)
+	}
+
+	info := title
+	if category != "" {
+		info = fmt.Sprintf("[%s] %s", category, title)
+	}
+	chatID, err := e.Client.StartHelpdeskService(ctx, token, requester, info)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+	ticket, err := e.Client.FindTicketByChat(ctx, token, requester, chatID)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+
+	summary := model.ActionSummary{
+		Type:    "feishu_ticket",
+		Target:  title,
+		ID:      ticket.ID,
+		Outputs: map[string]string{"ticket_id": ticket.ID, "chat_id": chatID, "category": category},
+	}
+	// 工单已建好，后续的分类、描述与截图失败只记录在 Note 中
+	var notes []string
+	if category != "" {
+		if err := e.Client.TagTicket(ctx, token, ticket.ID, []string{category}); err != nil {
+			notes = append(notes, "设置分类失败: "+err.Error())
+		}
+	}
+	var imageKeys []string
+	for _, a := range screenshots {
+		name, data, err := loadImportSource(ctx, a.Name, req)
+		if err == nil {
+			var key string
+			if key, err = e.Client.UploadIMImage(ctx, token, name, data); err == nil {
+				imageKeys = append(imageKeys, key)
+				continue
+			}
+		}
+		notes = append(notes, fmt.Sprintf("截图 %s 上传失败: %v", a.Name, err))
+	}
+	if description != "" || len(imageKeys) > 0 {
+		content := feishu.BuildTicketPostContent(title, description, imageKeys)
+		if err := e.Client.SendTicketMessage(ctx, token, ticket.ID, "post", content); err != nil {
+			notes = append(notes, "问题描述发送失败: "+err.Error())
+		}
+	}
+	summary.Outputs["screenshots"] = fmt.Sprint(len(imageKeys))
+	summary.Note = strings.Join(notes, "；")
+	return summary, nil
+}
+
+// ticketScreenshots 工单要附上的截图：按 screenshots 参数查找附件，省略时取请求中的全部图片附件
+func ticketScreenshots(spec model.ActionSpec, req *model.ASRRequest) ([]model.Attachment, error) {
+	if req == nil {
+		return nil, nil
+	}
+	names := model.StringList(spec.Params["screenshots"])
+	if len(names) == 0 {
+		var images []model.Attachment
+		for _, a := range req.Attachments {
+			if strings.HasPrefix(a.MimeType, "image/") {
+				images = append(images, a)
+			}
+		}
+		return images, nil
+	}
+	var out []model.Attachment
+	for _, n := range names {
+		a, ok := findAttachment(req.Attachments, n)
+		if !ok {
+			return nil, fmt.Errorf("%w: screenshot %q is not an attachment", model.ErrInvalidParams, n)
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// findHelpdeskCategory 按名称匹配服务台分类（含子分类）：先精确匹配，再互相包含（如"网络"匹配"网络问题"）
+func findHelpdeskCategory(categories []feishu.HelpdeskCategory, name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	var all []feishu.HelpdeskCategory
+	var walk func([]feishu.HelpdeskCategory)
+	walk = func(cs []feishu.HelpdeskCategory) {
+		for _, c := range cs {
+			all = append(all, c)
+			walk(c.Children)
+		}
+	}
+	walk(categories)
+	for _, c := range all {
+		if strings.EqualFold(c.Name, name) {
+			return c.Name, true
+		}
+	}
+	for _, c := range all {
+		if c.Name != "" && (strings.Contains(c.Name, name) || strings.Contains(name, c.Name)) {
+			return c.Name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,53 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/model"
+)
+
+// DocTitler 文档标题生成器（由 llm.Titler 等实现，避免循环依赖）
+type DocTitler interface {
+	GenerateTitle(ctx context.Context, content string) (string, error)
+}
+
+// docTitle 确定文档标题：未给出标题时由正文生成（无正文时用创建时间），再套用配置的标题模板
+func (e *FeishuExecutor) docTitle(ctx context.Context, title, content string, req *model.ASRRequest) string {
+	now := time.Now()
+	if title == "" && content != "" && e.Titler != nil {
+		title, _ = e.Titler.GenerateTitle(ctx, content)
+	}
+	if title == "" {
+		title = "新建文档 " + now.Format("2006-01-02 15:04")
+	}
+	return renderTitle(e.Cfg.TitleTemplate, title, authorOf(req), now)
+}
+
+// renderTitle 套用标题模板，支持 {{title}} {{date}} {{time}} {{author}}；变量为空时去掉首尾多余的分隔符
+func renderTitle(tmpl, title, author string, now time.Time) string {
+	if tmpl == "" {
+		return title
+	}
+	out := strings.NewReplacer(
+		"{{title}}", title,
+		"{{date}}", now.Format("2006-01-02"),
+		"{{time}}", now.Format("15:04"),
+		"{{author}}", author,
+	).Replace(tmpl)
+	out = strings.Join(strings.Fields(out), " ")
+	out = strings.Trim(out, " -_|/·")
+	if out == "" {
+		return title
+	}
+	return out
+}
+
+// authorOf 请求人名称，取自 Context["user_name"]
+func authorOf(req *model.ASRRequest) string {
+	if req == nil {
+		return ""
+	}
+	return req.Context["user_name"]
+}
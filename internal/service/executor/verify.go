@@ -0,0 +1,165 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// verifyMessageLimit 每个动作最多核验的消息数（批量发送时只抽查前几条）
+const verifyMessageLimit = 5
+
+// VerifyHook 执行后核验结果：create_doc 查询文档元信息并确认协作者已生效，send_message 确认发出的消息可查到；
+// 核验不通过不影响动作结果，原因记在 summary.Unverified，作为「已执行但未确认」单独告知用户
+func (e *Executor) VerifyHook() Hook {
+	return Hook{
+		Name: "verify_results",
+		AfterAction: func(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, summary *model.ActionSummary, err error) {
+			if err != nil || summary.Unverified != "" || e.Sandboxed(req) {
+				return
+			}
+			var problems []string
+			switch spec.Type {
+			case model.ActionTypeCreateDoc:
+				problems = e.feishu.verifyDoc(ctx, *summary)
+			case model.ActionTypeSendMessage:
+				problems = e.verifyMessages(ctx, *summary, req)
+			}
+			summary.Unverified = strings.Join(problems, "；")
+		},
+	}
+}
+
+// verifyDoc 确认文档存在，且 Outputs["collaborators"] 中的成员都在协作者列表里
+func (e *FeishuExecutor) verifyDoc(ctx context.Context, summary model.ActionSummary) []string {
+	if summary.ID == "" || !e.Cfg.Enabled {
+		return nil
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return []string{fmt.Sprintf("无法核验文档：%v", err)}
+	}
+	if _, err := e.Client.GetDocMeta(ctx, token, summary.ID, "docx"); err != nil {
+		return []string{fmt.Sprintf("文档创建后查询不到：%v", err)}
+	}
+	expected := summary.Outputs["collaborators"]
+	if expected == "" {
+		return nil
+	}
+	members, err := e.Client.ListPermissionMembers(ctx, token, summary.ID, "docx")
+	if err != nil {
+		return []string{fmt.Sprintf("无法核验协作者：%v", err)}
+	}
+	var ids []string
+	for _, m := range members {
+		ids = append(ids, m.MemberID)
+	}
+	var missing []string
+	for _, id := range strings.Split(expected, ",") {
+		if !slices.Contains(ids, id) {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return []string{"协作者未生效：" + strings.Join(missing, "、")}
+	}
+	return nil
+}
+
+// verifyMessages 确认发出的消息可查到：飞书消息（om_ 开头）按 ID 查询，Slack 消息按各自所在的会话与 ts 查询
+func (e *Executor) verifyMessages(ctx context.Context, summary model.ActionSummary, req *model.ASRRequest) []string {
+	sent := sentMessages(summary)
+	if len(sent) > verifyMessageLimit {
+		sent = sent[:verifyMessageLimit]
+	}
+	var problems []string
+	for _, m := range sent {
+		var err error
+		switch {
+		case strings.HasPrefix(m.id, "om_"):
+			err = e.feishu.verifyMessage(ctx, m.id)
+		case m.typ == "slack_message" && m.chat == "":
+			err = fmt.Errorf("缺少所在会话")
+		case m.typ == "slack_message":
+			err = e.slack.verifyMessage(ctx, m.chat, m.id, req)
+		default:
+			continue
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("消息 %s 发送后查询不到：%v", m.id, err))
+		}
+	}
+	return problems
+}
+
+// sentMessage 发出的一条消息：所属平台的摘要类型、消息 ID 与所在会话
+type sentMessage struct {
+	typ, id, chat string
+}
+
+// sentMessages 按 message_ids 与一一对应的 chat_ids 列出发出的消息；
+// 分组跨平台发送的摘要（type 为 message）按平台取 <type>.message_ids 与 <type>.chat_ids
+func sentMessages(summary model.ActionSummary) []sentMessage {
+	if summary.Type != "message" {
+		return pairMessages(summary.Type, summary.Outputs["message_ids"], summary.Outputs["chat_ids"])
+	}
+	var types []string
+	for k := range summary.Outputs {
+		if typ, ok := strings.CutSuffix(k, ".message_ids"); ok {
+			types = append(types, typ)
+		}
+	}
+	slices.Sort(types)
+	var sent []sentMessage
+	for _, typ := range types {
+		sent = append(sent, pairMessages(typ, summary.Outputs[typ+".message_ids"], summary.Outputs[typ+".chat_ids"])...)
+	}
+	return sent
+}
+
+func pairMessages(typ, ids, chats string) []sentMessage {
+	if ids == "" {
+		return nil
+	}
+	var chatIDs []string
+	if chats != "" {
+		chatIDs = strings.Split(chats, ",")
+	}
+	var sent []sentMessage
+	for i, id := range strings.Split(ids, ",") {
+		m := sentMessage{typ: typ, id: id}
+		if i < len(chatIDs) {
+			m.chat = chatIDs[i]
+		}
+		sent = append(sent, m)
+	}
+	return sent
+}
+
+// verifyMessage 确认飞书消息存在且未撤回
+func (e *FeishuExecutor) verifyMessage(ctx context.Context, messageID string) error {
+	if !e.Cfg.Enabled {
+		return nil
+	}
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = e.Client.GetMessage(ctx, token, messageID)
+	return err
+}
+
+// verifyMessage 确认 Slack 频道中存在该消息
+func (e *SlackExecutor) verifyMessage(ctx context.Context, channel, ts string, req *model.ASRRequest) error {
+	if !e.Cfg.Enabled {
+		return nil
+	}
+	client, err := e.workspaceClient(req)
+	if err != nil {
+		return err
+	}
+	return client.GetMessage(ctx, channel, ts)
+}
@@ -0,0 +1,93 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"sayso-agent/internal/client/slack"
+	"sayso-agent/internal/model"
+)
+
+// slackHistoryStub 模拟 conversations.history，只有 found 中「会话/ts」对应的消息能查到
+type slackHistoryStub struct {
+	found   map[string]bool // channel/ts
+	queries []string
+}
+
+func (s *slackHistoryStub) RoundTrip(r *http.Request) (*http.Response, error) {
+	b, _ := io.ReadAll(r.Body)
+	form, _ := url.ParseQuery(string(b))
+	key := form.Get("channel") + "/" + form.Get("latest")
+	s.queries = append(s.queries, key)
+	body := `{"ok":true,"messages":[]}`
+	if s.found[key] {
+		body = fmt.Sprintf(`{"ok":true,"messages":[{"ts":%q}]}`, form.Get("latest"))
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: make(http.Header), Request: r}, nil
+}
+
+func TestVerifyMessagesChecksEachSlackChannel(t *testing.T) {
+	stub := &slackHistoryStub{found: map[string]bool{"C1/1700000000.000100": true, "D2/1700000000.000300": true}}
+	cfg := slack.Config{BotToken: "xoxb-test", Enabled: true, Transport: stub}
+	e := &Executor{slack: &SlackExecutor{Client: slack.NewClient(cfg), Cfg: cfg}}
+
+	tests := []struct {
+		name    string
+		summary model.ActionSummary
+		queries []string
+		missing []string
+	}{
+		{
+			name: "each ts in its own channel",
+			summary: model.ActionSummary{Type: "slack_message", Outputs: map[string]string{
+				"message_id":  "1700000000.000100",
+				"chat_id":     "C1",
+				"message_ids": "1700000000.000100,1700000000.000200,1700000000.000300",
+				"chat_ids":    "C1,C9,D2",
+			}},
+			queries: []string{"C1/1700000000.000100", "C9/1700000000.000200", "D2/1700000000.000300"},
+			missing: []string{"1700000000.000200"},
+		},
+		{
+			name: "grouped send across platforms",
+			summary: model.ActionSummary{Type: "message", Outputs: map[string]string{
+				"slack_message.message_ids":   "1700000000.000100,1700000000.000300",
+				"slack_message.chat_ids":      "C1,D2",
+				"discord_message.message_ids": "1200000000000000001",
+				"discord_message.chat_ids":    "900000000000000001",
+			}},
+			queries: []string{"C1/1700000000.000100", "D2/1700000000.000300"},
+		},
+		{
+			name: "missing channel",
+			summary: model.ActionSummary{Type: "slack_message", Outputs: map[string]string{
+				"message_ids": "1700000000.000100,1700000000.000300",
+				"chat_ids":    "C1",
+			}},
+			queries: []string{"C1/1700000000.000100"},
+			missing: []string{"1700000000.000300"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub.queries = nil
+			problems := e.verifyMessages(context.Background(), tt.summary, nil)
+			if strings.Join(stub.queries, " ") != strings.Join(tt.queries, " ") {
+				t.Errorf("queries = %v, want %v", stub.queries, tt.queries)
+			}
+			if len(problems) != len(tt.missing) {
+				t.Fatalf("problems = %v, want %d", problems, len(tt.missing))
+			}
+			for i, id := range tt.missing {
+				if !strings.Contains(problems[i], id) {
+					t.Errorf("problem %q, want message %s", problems[i], id)
+				}
+			}
+		})
+	}
+}
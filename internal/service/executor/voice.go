@@ -0,0 +1,102 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/client/tts"
+	"sayso-agent/internal/model"
+)
+
+// Speaker 语音合成（由 tts.Client 实现），用于 message_type 为 voice 的消息
+type Speaker interface {
+	Synthesize(ctx context.Context, text string) (tts.Audio, error)
+}
+
+// voiceMaxChars 合成语音的正文上限，超出时按文字发送
+const voiceMaxChars = 4096
+
+// sendVoice 把正文合成为语音后发送：飞书为语音消息，Slack 为音频文件；
+// 未启用语音合成、平台不支持或合成失败时按文字发送并在摘要中说明
+func (e *Executor) sendVoice(ctx context.Context, platform string, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	params := model.ParseSendMessageParams(spec.Params)
+	text := params.Content.Text
+	if text == "" {
+		return model.ActionSummary{}, fmt.Errorf("send_message: %w: content.text is required for voice", model.ErrInvalidParams)
+	}
+	if len(params.Targets) == 0 {
+		return model.ActionSummary{}, fmt.Errorf("send_message: targets is required")
+	}
+	var fallback string
+	switch {
+	case e.speaker == nil:
+		fallback = "未启用语音合成，已按文字发送"
+	case platform != "feishu" && platform != "slack":
+		fallback = fmt.Sprintf("%s 暂不支持语音消息，已按文字发送", platform)
+	case utf8.RuneCountInString(text) > voiceMaxChars:
+		fallback = "内容过长，已按文字发送"
+	}
+	var audio tts.Audio
+	if fallback == "" {
+		var err error
+		if audio, err = e.speaker.Synthesize(ctx, text); err != nil {
+			fallback = fmt.Sprintf("语音合成失败（%v），已按文字发送", err)
+		}
+	}
+	if fallback != "" {
+		summary, err := e.sendMessageOn(ctx, platform, withMessageText(spec, text, "text"), req)
+		if err != nil {
+			return model.ActionSummary{}, err
+		}
+		summary.Note = joinNotes(summary.Note, fallback)
+		return summary, nil
+	}
+
+	var summary model.ActionSummary
+	if platform == "slack" {
+		file := feishu.ExportedFile{Name: "voice.ogg", Ext: "ogg", Data: audio.Data}
+		summary = e.slack.buildSendMessageSummary(e.slack.SendFile(ctx, file, params.Targets, req))
+	} else {
+		if !e.feishu.Cfg.Enabled {
+			return model.ActionSummary{}, model.ErrFeishuDisabled
+		}
+		summary = e.feishu.buildSendMessageSummary(e.feishu.SendAudio(ctx, audio, params.Targets), params)
+	}
+	if link := e.saveArtifact(ctx, "audio", "voice.ogg", "audio/ogg", audio.Data); link != "" {
+		if summary.Outputs == nil {
+			summary.Outputs = make(map[string]string)
+		}
+		summary.Outputs["audio_url"] = link
+	}
+	return summary, nil
+}
+
+// SendAudio 上传语音后以语音消息发给各目标（用户名、open_id、邮箱或群 ID）
+func (e *FeishuExecutor) SendAudio(ctx context.Context, audio tts.Audio, targets []string) []model.SendResult {
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err == nil {
+		var fileKey string
+		if fileKey, err = e.Client.UploadIMAudio(ctx, token, "voice.opus", audio.Data, audio.Duration); err == nil {
+			content := feishu.BuildAudioContent(fileKey)
+			results := make([]model.SendResult, 0, len(targets))
+			for _, target := range targets {
+				results = append(results, e.sendToTarget(ctx, token, target, "user", "audio", content))
+			}
+			return results
+		}
+	}
+	results := make([]model.SendResult, len(targets))
+	for i, t := range targets {
+		results[i] = model.SendResult{TargetID: t, Error: err.Error()}
+	}
+	return results
+}
+
+func joinNotes(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "；" + b
+}
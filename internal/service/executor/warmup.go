@@ -0,0 +1,110 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Warmup 预热：换取各飞书企业的 tenant_access_token、加载目录树、查询常用联系人、加载 Slack 频道列表，
+// 结果进入客户端缓存，使当天第一个请求不必承担数秒的冷启动延迟；失败只记录日志
+func (e *Executor) Warmup(ctx context.Context, contacts []string) {
+	if e.sandbox {
+		return
+	}
+	start := time.Now()
+	if e.feishu.Cfg.Enabled {
+		tenants := []string{""}
+		if e.feishu.Client.Marketplace() {
+			tenants = e.feishu.Client.Tenants()
+		}
+		for _, tenant := range tenants {
+			e.feishu.warmup(e.feishu.Client.WithTenant(ctx, tenant), tenant, contacts)
+		}
+	}
+	if e.slack.Cfg.Enabled {
+		for _, client := range e.slack.Client.WorkspaceClients() {
+			if _, err := client.Channels(ctx); err != nil {
+				log.Printf("warmup slack channels: %v", err)
+			}
+		}
+	}
+	log.Printf("warmup done in %s", time.Since(start).Round(time.Millisecond))
+}
+
+func (e *FeishuExecutor) warmup(ctx context.Context, tenant string, contacts []string) {
+	token, err := e.Client.GetTenantAccessToken(ctx)
+	if err != nil {
+		log.Printf("warmup feishu token (tenant %q): %v", tenant, err)
+		return
+	}
+	// 与 create_doc、create_folder 的目录匹配使用相同深度
+	if _, err := e.Client.GetFolderTree(ctx, token, 2); err != nil {
+		log.Printf("warmup feishu folder tree (tenant %q): %v", tenant, err)
+	}
+	for _, name := range contacts {
+		if _, err := e.Client.SearchUserByName(ctx, token, name); err != nil {
+			log.Printf("warmup feishu contact %s (tenant %q): %v", name, tenant, err)
+		}
+	}
+}
+
+// RefreshFolderTrees 跳过缓存重新加载各飞书企业的目录树（后台维护任务）
+func (e *Executor) RefreshFolderTrees(ctx context.Context) error {
+	return e.eachFeishuTenant(ctx, func(ctx context.Context, token string) error {
+		_, err := e.feishu.Client.RefreshFolderTree(ctx, token, 2)
+		return err
+	})
+}
+
+// RefreshContacts 跳过缓存重新查询常用联系人（后台维护任务）
+func (e *Executor) RefreshContacts(ctx context.Context, names []string) error {
+	return e.eachFeishuTenant(ctx, func(ctx context.Context, token string) error {
+		var errs []error
+		for _, name := range names {
+			if _, err := e.feishu.Client.RefreshUserByName(ctx, token, name); err != nil {
+				errs = append(errs, fmt.Errorf("contact %s: %w", name, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// RefreshChannels 跳过缓存重新拉取各 Slack 工作区的频道列表（后台维护任务）
+func (e *Executor) RefreshChannels(ctx context.Context) error {
+	if e.sandbox || !e.slack.Cfg.Enabled {
+		return nil
+	}
+	var errs []error
+	for _, client := range e.slack.Client.WorkspaceClients() {
+		if _, err := client.RefreshChannels(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// eachFeishuTenant 对每个飞书企业（自建应用只有一个）换取 token 后调用 fn，汇总各企业的错误
+func (e *Executor) eachFeishuTenant(ctx context.Context, fn func(ctx context.Context, token string) error) error {
+	if e.sandbox || !e.feishu.Cfg.Enabled {
+		return nil
+	}
+	tenants := []string{""}
+	if e.feishu.Client.Marketplace() {
+		tenants = e.feishu.Client.Tenants()
+	}
+	var errs []error
+	for _, tenant := range tenants {
+		tctx := e.feishu.Client.WithTenant(ctx, tenant)
+		token, err := e.feishu.Client.GetTenantAccessToken(tctx)
+		if err == nil {
+			err = fn(tctx, token)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %q: %w", tenant, err))
+		}
+	}
+	return errors.Join(errs...)
+}
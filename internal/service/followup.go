@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/store"
+)
+
+var (
+	// ErrNotAwaitingConfirmation 任务不处于待确认状态
+	ErrNotAwaitingConfirmation = errors.New("task is not awaiting confirmation")
+	// ErrNotRetryable 任务未失败，或失败时尚未生成动作（如大模型调用失败），无法只重试失败部分
+	ErrNotRetryable = errors.New("task has no failed actions to retry")
+)
+
+// 语音回复只作用于该时间内创建的任务
+const (
+	confirmWindow = 10 * time.Minute
+	retryWindow   = time.Hour
+)
+
+// Confirm 确认待确认任务的首个动作（计划超出安全上限时为确认整个计划），并继续执行剩余动作
+func (s *ASRService) Confirm(ctx context.Context, taskID string) (model.ASRResponse, error) {
+	rec, err := s.claimTask(ctx, taskID, model.TaskStatusAwaitingConfirmation, ErrNotAwaitingConfirmation)
+	if err != nil {
+		return model.ASRResponse{TaskID: taskID}, err
+	}
+	if rec.LimitCheck == limitCheckAwaiting {
+		rec.LimitCheck = limitCheckConfirmed
+	} else {
+		rec.Pending[0].Confirmed = true
+	}
+	return s.continueTask(ctx, rec)
+}
+
+// Cancel 取消待确认任务，剩余动作不再执行
+func (s *ASRService) Cancel(ctx context.Context, taskID string) (model.ASRResponse, error) {
+	rec, err := s.claimTask(ctx, taskID, model.TaskStatusAwaitingConfirmation, ErrNotAwaitingConfirmation)
+	if err != nil {
+		return model.ASRResponse{TaskID: taskID}, err
+	}
+	rec.Pending = nil
+	resp := model.ASRResponse{TaskID: rec.ID, Success: true, Message: "已取消", Actions: rec.Actions}
+	s.finishTask(ctx, rec, resp, nil)
+	return resp, nil
+}
+
+// Retry 重新执行失败任务中失败的动作及其后的动作；已成功的动作不再执行，其输出仍可通过占位符引用
+func (s *ASRService) Retry(ctx context.Context, taskID string) (model.ASRResponse, error) {
+	rec, err := s.claimTask(ctx, taskID, model.TaskStatusFailed, ErrNotRetryable)
+	if err != nil {
+		return model.ASRResponse{TaskID: taskID}, err
+	}
+	rec.Error = ""
+	return s.continueTask(ctx, rec)
+}
+
+// continueTask 从 rec.Pending 继续执行并更新任务记录
+func (s *ASRService) continueTask(ctx context.Context, rec model.TaskRecord) (model.ASRResponse, error) {
+	ctx = withMeters(ctx)
+	rec.Status = model.TaskStatusRunning
+	s.saveTask(ctx, rec)
+
+	var req model.ASRRequest
+	if rec.Request != nil {
+		req = *rec.Request
+	}
+	// 确认、重试后继续执行的动作沿用原请求的大模型参数（创建任务时已检查范围）
+	ctx = withLLMOptions(ctx, req.LLM)
+	resp := model.ASRResponse{TaskID: rec.ID}
+	resp, err := s.resume(ctx, &rec, resp, &req)
+	s.finishTask(ctx, rec, resp, err)
+	return resp, err
+}
+
+// loadTask 读取处于 status 状态且有未执行动作的任务，否则返回 wrongState
+func (s *ASRService) loadTask(ctx context.Context, taskID, status string, wrongState error) (model.TaskRecord, error) {
+	if s.tasks == nil {
+		return model.TaskRecord{}, fmt.Errorf("task store not configured")
+	}
+	rec, err := s.tasks.Get(ctx, taskID)
+	if err != nil {
+		return model.TaskRecord{}, err
+	}
+	if rec.Status != status || len(rec.Pending) == 0 {
+		return model.TaskRecord{}, fmt.Errorf("task %s: %w", taskID, wrongState)
+	}
+	return rec, nil
+}
+
+// claimTask 同 loadTask，并把任务状态从 status 原子地改为执行中：同一任务并发的确认、取消、重试只有一个成功，
+// 其余返回 wrongState，动作不会被重复执行
+func (s *ASRService) claimTask(ctx context.Context, taskID, status string, wrongState error) (model.TaskRecord, error) {
+	rec, err := s.loadTask(ctx, taskID, status, wrongState)
+	if err != nil {
+		return model.TaskRecord{}, err
+	}
+	swapped, err := s.tasks.Transition(ctx, taskID, status, model.TaskStatusRunning)
+	if err != nil {
+		return model.TaskRecord{}, err
+	}
+	if !swapped {
+		return model.TaskRecord{}, fmt.Errorf("task %s: %w", taskID, wrongState)
+	}
+	rec.Status = model.TaskStatusRunning
+	return rec, nil
+}
+
+// followUp 处理「确认」「取消」「重试」等简短回复：作用于该用户最近一个待确认/失败的任务；
+// 「不对，我是说……」作用于最近一个成功的任务
+// ok 为 false 表示不是此类回复（或没有可作用的任务），按普通请求处理
+func (s *ASRService) followUp(ctx context.Context, req model.ASRRequest) (model.ASRResponse, bool, error) {
+	if s.tasks == nil || req.UserID == "" {
+		return model.ASRResponse{}, false, nil
+	}
+	// 有等待审阅的草稿时，「把第二段删掉」等修改意见作用于该草稿，不重新规划
+	if isDraftRevision(req.Text) {
+		if rec, ok := s.latestTask(ctx, req, model.TaskStatusAwaitingConfirmation, confirmWindow); ok && pendingDraft(rec) {
+			resp, err := s.reviseDraft(ctx, rec, req)
+			return resp, true, err
+		}
+	}
+	switch {
+	case isConfirmReply(req.Text):
+		if rec, ok := s.latestTask(ctx, req, model.TaskStatusAwaitingConfirmation, confirmWindow); ok {
+			resp, err := s.Confirm(ctx, rec.ID)
+			return resp, true, err
+		}
+	case isCancelReply(req.Text):
+		if rec, ok := s.latestTask(ctx, req, model.TaskStatusAwaitingConfirmation, confirmWindow); ok {
+			resp, err := s.Cancel(ctx, rec.ID)
+			return resp, true, err
+		}
+	case isRetryReply(req.Text):
+		if rec, ok := s.latestTask(ctx, req, model.TaskStatusFailed, retryWindow); ok {
+			resp, err := s.Retry(ctx, rec.ID)
+			return resp, true, err
+		}
+	case isCorrection(req.Text):
+		if rec, ok := s.latestTask(ctx, req, model.TaskStatusSucceeded, correctionWindow); ok {
+			return s.correctTask(ctx, rec, req)
+		}
+	}
+	return model.ASRResponse{}, false, nil
+}
+
+// latestTask 该用户在 window 内最近一个处于 status 状态的任务；在话题中时只看该话题内的任务
+func (s *ASRService) latestTask(ctx context.Context, req model.ASRRequest, status string, window time.Duration) (model.TaskRecord, bool) {
+	filter := store.TaskFilter{
+		TenantID: req.Tenant(),
+		UserID:   req.UserID,
+		Status:   status,
+		Limit:    1,
+	}
+	if req.ThreadID() != "" {
+		filter.Session = req.SessionID()
+	}
+	list, err := s.tasks.List(ctx, filter)
+	if err != nil || len(list) == 0 || time.Since(list[0].CreatedAt) > window {
+		return model.TaskRecord{}, false
+	}
+	return list[0], true
+}
+
+var (
+	confirmReplies = []string{"确认", "确定", "是的", "好的", "执行", "yes", "ok", "confirm"}
+	cancelReplies  = []string{"取消", "算了", "不用了", "不要", "no", "cancel"}
+	retryReplies   = []string{"重试", "重试一下", "再试一次", "再试一下", "重试刚才失败的那个", "重试刚才那个", "retry"}
+)
+
+func isConfirmReply(text string) bool { return matchReply(text, confirmReplies) }
+func isCancelReply(text string) bool  { return matchReply(text, cancelReplies) }
+func isRetryReply(text string) bool   { return matchReply(text, retryReplies) }
+
+// matchReply 去掉标点后整句与回复词一致（避免把「确认一下文档权限」当作确认）
+func matchReply(text string, replies []string) bool {
+	t := strings.ToLower(strings.TrimSpace(strings.TrimRight(strings.TrimSpace(text), "。.!！~")))
+	for _, r := range replies {
+		if t == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/service/executor"
+	"sayso-agent/internal/store"
+)
+
+// countingExecutor 记录动作执行次数
+type countingExecutor struct{ runs atomic.Int32 }
+
+func (e *countingExecutor) Execute(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	e.runs.Add(1)
+	time.Sleep(10 * time.Millisecond) // 放大并发窗口
+	return model.ActionSummary{Type: spec.Type}, nil
+}
+
+func (e *countingExecutor) Intercept(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, run executor.Handler) (model.ActionSummary, error) {
+	return run(ctx, spec, req)
+}
+
+func (e *countingExecutor) Sandboxed(*model.ASRRequest) bool { return false }
+
+// 同一任务并发的确认、取消、重试只有一个生效，动作只执行一次
+func TestConcurrentFollowUpsRunActionOnce(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		call   func(s *ASRService, id string) (model.ASRResponse, error)
+		wrong  error
+	}{
+		{name: "confirm", status: model.TaskStatusAwaitingConfirmation, call: func(s *ASRService, id string) (model.ASRResponse, error) { return s.Confirm(context.Background(), id) }, wrong: ErrNotAwaitingConfirmation},
+		{name: "retry", status: model.TaskStatusFailed, call: func(s *ASRService, id string) (model.ASRResponse, error) { return s.Retry(context.Background(), id) }, wrong: ErrNotRetryable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := store.NewMemoryTaskStore(10)
+			exec := &countingExecutor{}
+			s := NewASRService(nil, exec, tasks, SessionConfig{}, Limits{})
+			rec := model.TaskRecord{
+				ID:           "1",
+				Status:       tt.status,
+				Pending:      []model.ActionSpec{{Type: model.ActionTypeTransferOwner, Params: map[string]any{"doc": "周报"}}},
+				Placeholders: map[string]string{},
+				Request:      &model.ASRRequest{UserID: "ou_alice"},
+				CreatedAt:    time.Now(),
+			}
+			if err := tasks.Save(context.Background(), rec); err != nil {
+				t.Fatal(err)
+			}
+
+			const callers = 8
+			var wg sync.WaitGroup
+			var ok, rejected atomic.Int32
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := tt.call(s, rec.ID)
+					switch {
+					case err == nil:
+						ok.Add(1)
+					case errors.Is(err, tt.wrong):
+						rejected.Add(1)
+					default:
+						t.Errorf("unexpected error: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+			if ok.Load() != 1 || rejected.Load() != callers-1 {
+				t.Errorf("succeeded = %d, rejected = %d; want 1 and %d", ok.Load(), rejected.Load(), callers-1)
+			}
+			if n := exec.runs.Load(); n != 1 {
+				t.Errorf("action ran %d times, want 1", n)
+			}
+			if got, _ := tasks.Get(context.Background(), rec.ID); got.Status != model.TaskStatusSucceeded {
+				t.Errorf("status = %s, want succeeded", got.Status)
+			}
+		})
+	}
+}
+
+// 确认与取消同时到达时只有一个生效
+func TestConfirmAfterCancelIsRejected(t *testing.T) {
+	tasks := store.NewMemoryTaskStore(10)
+	exec := &countingExecutor{}
+	s := NewASRService(nil, exec, tasks, SessionConfig{}, Limits{})
+	rec := model.TaskRecord{
+		ID:        "1",
+		Status:    model.TaskStatusAwaitingConfirmation,
+		Pending:   []model.ActionSpec{{Type: model.ActionTypeTransferOwner}},
+		CreatedAt: time.Now(),
+	}
+	if err := tasks.Save(context.Background(), rec); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Cancel(context.Background(), rec.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Confirm(context.Background(), rec.ID); !errors.Is(err, ErrNotAwaitingConfirmation) {
+		t.Errorf("confirm after cancel err = %v, want ErrNotAwaitingConfirmation", err)
+	}
+	if exec.runs.Load() != 0 {
+		t.Error("cancelled action was executed")
+	}
+}
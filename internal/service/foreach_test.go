@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/service/executor"
+)
+
+// failingExecutor 对 fail 中的任务返回错误，其余成功
+type failingExecutor struct{ fail map[string]bool }
+
+func (e *failingExecutor) Execute(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest) (model.ActionSummary, error) {
+	if e.fail[spec.TaskID] {
+		return model.ActionSummary{}, errors.New("user not found")
+	}
+	return model.ActionSummary{Type: "feishu_message", Target: spec.TaskID, Outputs: map[string]string{"message_id": "om_" + spec.TaskID}}, nil
+}
+
+func (e *failingExecutor) Intercept(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, run executor.Handler) (model.ActionSummary, error) {
+	return run(ctx, spec, req)
+}
+
+func (e *failingExecutor) Sandboxed(*model.ASRRequest) bool { return false }
+
+func foreachPlan(n int) []model.ActionSpec {
+	var plan []model.ActionSpec
+	for i := 1; i <= n; i++ {
+		plan = append(plan, model.ActionSpec{TaskID: fmt.Sprintf("task_1_%d", i), Group: "task_1", Type: model.ActionTypeSendMessage})
+	}
+	return append(plan, model.ActionSpec{TaskID: "task_2", Type: model.ActionTypeSendMessage})
+}
+
+func TestResumeAggregatesForEachItems(t *testing.T) {
+	tests := []struct {
+		name          string
+		fail          map[string]bool
+		wantErr       bool
+		wantSucceeded string
+		wantFailed    string
+	}{
+		{name: "all succeed", wantSucceeded: "3", wantFailed: "0"},
+		{name: "one item fails", fail: map[string]bool{"task_1_2": true}, wantSucceeded: "2", wantFailed: "1"},
+		{name: "all items fail", fail: map[string]bool{"task_1_1": true, "task_1_2": true, "task_1_3": true}, wantErr: true, wantSucceeded: "0", wantFailed: "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewASRService(nil, &failingExecutor{fail: tt.fail}, nil, SessionConfig{}, Limits{})
+			rec := &model.TaskRecord{ID: "1", Pending: foreachPlan(3), Placeholders: map[string]string{}}
+			resp, err := s.resume(context.Background(), rec, model.ASRResponse{}, &model.ASRRequest{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resume error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var total *model.ActionSummary
+			for i := range rec.Actions {
+				if rec.Actions[i].Type == "foreach" {
+					total = &rec.Actions[i]
+				}
+			}
+			if total == nil {
+				t.Fatalf("no foreach summary in %+v", rec.Actions)
+			}
+			if total.Target != "task_1" || total.Outputs["total"] != "3" || total.Outputs["succeeded"] != tt.wantSucceeded || total.Outputs["failed"] != tt.wantFailed {
+				t.Errorf("foreach summary = %+v, want succeeded %s failed %s of 3", total, tt.wantSucceeded, tt.wantFailed)
+			}
+			if tt.wantErr {
+				if len(rec.Pending) != 1 || rec.Pending[0].TaskID != "task_2" {
+					t.Errorf("pending = %+v, want task_2 left", rec.Pending)
+				}
+				return
+			}
+			// 单项失败不中断其余项与后续任务
+			if len(rec.Pending) != 0 || !resp.Success {
+				t.Errorf("pending = %d, success = %v; want all run", len(rec.Pending), resp.Success)
+			}
+			if tt.wantFailed != "0" {
+				if !strings.Contains(total.Note, "task_1_2（user not found）") {
+					t.Errorf("note = %q, want failed item and reason", total.Note)
+				}
+				if !strings.Contains(resp.Message, "1/3 项执行失败") {
+					t.Errorf("message = %q, want failure count", resp.Message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	clientllm "sayso-agent/internal/client/llm"
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/service/deadline"
+)
+
+// Limits 安全上限，0 表示不限制
+type Limits struct {
+	MaxTasks         int  // 单次请求最多执行的动作数（循环任务按展开后计）
+	MaxDocs          int  // 单次请求最多新建的文档数
+	ActionsPerMinute int  // 每个用户每分钟最多执行的动作数，超出直接拒绝
+	RejectOversized  bool // 计划超出上限时直接拒绝；默认暂停并请用户确认
+	// Deadline 单个请求的总时限（请求可用 deadline_ms 覆盖），按 DeadlineShares 分配给规划、参数提取、执行三个阶段
+	Deadline       time.Duration
+	DeadlineShares deadline.Shares
+	// LLM 请求可覆盖的大模型参数范围
+	LLM LLMBounds
+}
+
+// LLMBounds 请求级大模型参数（请求的 llm 字段）的允许范围；Enabled 为 false 时拒绝带 llm 字段的请求
+type LLMBounds struct {
+	Enabled bool
+	// Models 可指定的模型，为空时不允许指定模型
+	Models         []string
+	MinTemperature float64
+	MaxTemperature float64
+	// MaxTokens max_tokens 的上限，0 表示不限制
+	MaxTokens int
+}
+
+// check 检查请求的大模型参数是否在允许范围内
+func (b LLMBounds) check(o *model.LLMOptions) error {
+	if o == nil {
+		return nil
+	}
+	if !b.Enabled {
+		return fmt.Errorf("%w: llm overrides are not enabled", model.ErrInvalidParams)
+	}
+	if o.Model != "" && !slices.Contains(b.Models, o.Model) {
+		return fmt.Errorf("%w: llm.model %q is not allowed (allowed: %s)", model.ErrInvalidParams, o.Model, strings.Join(b.Models, ", "))
+	}
+	if t := o.Temperature; t != nil && (*t < b.MinTemperature || *t > b.MaxTemperature) {
+		return fmt.Errorf("%w: llm.temperature must be between %v and %v, got %v", model.ErrInvalidParams, b.MinTemperature, b.MaxTemperature, *t)
+	}
+	if o.MaxTokens < 0 || (b.MaxTokens > 0 && o.MaxTokens > b.MaxTokens) {
+		return fmt.Errorf("%w: llm.max_tokens must be between 0 and %d, got %d", model.ErrInvalidParams, b.MaxTokens, o.MaxTokens)
+	}
+	return nil
+}
+
+// withLLMOptions 把请求的大模型参数挂到 ctx 上，作用于本次处理的全部对话调用
+func withLLMOptions(ctx context.Context, o *model.LLMOptions) context.Context {
+	if o == nil {
+		return ctx
+	}
+	return clientllm.WithOptions(ctx, clientllm.Options{Model: o.Model, Temperature: o.Temperature, MaxTokens: o.MaxTokens})
+}
+
+// 任务记录中的上限检查状态
+const (
+	limitCheckAwaiting  = "awaiting"  // 计划超出上限，等待用户确认
+	limitCheckConfirmed = "confirmed" // 用户已确认超限的计划
+)
+
+// planViolations 列出计划超出的上限；接收方上限须按展开联系人分组后的人数计，由执行器的 recipient_limit 钩子检查
+func (l Limits) planViolations(actions []model.ActionSpec) []string {
+	var violations []string
+	if l.MaxTasks > 0 && len(actions) > l.MaxTasks {
+		violations = append(violations, fmt.Sprintf("共 %d 个动作，超过单次上限 %d 个", len(actions), l.MaxTasks))
+	}
+	docs := 0
+	for _, a := range actions {
+		switch a.Type {
+		case model.ActionTypeCreateDoc, model.ActionTypeMinutesNotes:
+			docs++
+		case model.ActionTypeBulkCreateDoc:
+			// 来源为联系人或表格时计划中看不到项数，按 1 计（执行时另有数量上限）
+			docs += max(1, len(model.StringList(a.Params["items"])))
+		}
+	}
+	if l.MaxDocs > 0 && docs > l.MaxDocs {
+		violations = append(violations, fmt.Sprintf("将新建 %d 份文档，超过单次上限 %d 份", docs, l.MaxDocs))
+	}
+	return violations
+}
+
+// actionQuota 按用户统计最近一分钟内执行的动作数；每分钟清理一次一分钟内没有动作的用户
+type actionQuota struct {
+	mu        sync.Mutex
+	perMinute int
+	hits      map[string][]time.Time
+	swept     time.Time // 上次清理时间
+}
+
+func newActionQuota(perMinute int) *actionQuota {
+	return &actionQuota{perMinute: perMinute, hits: make(map[string][]time.Time)}
+}
+
+// allow 记录一次动作，超出每分钟上限时返回 false（不计入）
+func (q *actionQuota) allow(user string, now time.Time) bool {
+	if q.perMinute <= 0 || user == "" {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if now.Sub(q.swept) >= time.Minute {
+		q.sweep(now)
+	}
+	recent := q.hits[user][:0]
+	for _, t := range q.hits[user] {
+		if now.Sub(t) < time.Minute {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= q.perMinute {
+		q.hits[user] = recent
+		return false
+	}
+	q.hits[user] = append(recent, now)
+	return true
+}
+
+// sweep 删除最近一分钟内没有动作的用户，避免 hits 随用户数无限增长
+func (q *actionQuota) sweep(now time.Time) {
+	for user, times := range q.hits {
+		if len(times) == 0 || now.Sub(times[len(times)-1]) >= time.Minute {
+			delete(q.hits, user)
+		}
+	}
+	q.swept = now
+}
+
+// checkPlan 检查待执行的计划是否超出上限：超出时按配置拒绝，或暂停等待确认（paused 为 true）
+func (s *ASRService) checkPlan(rec *model.TaskRecord, resp *model.ASRResponse) (paused bool, err error) {
+	if rec.LimitCheck == limitCheckConfirmed {
+		return false, nil
+	}
+	violations := s.limits.planViolations(rec.Pending)
+	if len(violations) == 0 {
+		return false, nil
+	}
+	reason := strings.Join(violations, "；")
+	if s.limits.RejectOversized {
+		resp.Message = "请求超出安全上限，未执行：" + reason
+		return false, fmt.Errorf("%w: %s", model.ErrLimitExceeded, reason)
+	}
+	rec.LimitCheck = limitCheckAwaiting
+	resp.NeedConfirmation = true
+	resp.Message = "请求规模较大：" + reason + "。确认后继续执行，或回复「取消」"
+	return true, nil
+}
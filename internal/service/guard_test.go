@@ -0,0 +1,41 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestActionQuotaAllow(t *testing.T) {
+	q := newActionQuota(2)
+	now := time.Now()
+	if !q.allow("acme/ou_a", now) || !q.allow("acme/ou_a", now.Add(time.Second)) {
+		t.Fatal("first two actions should be allowed")
+	}
+	if q.allow("acme/ou_a", now.Add(2*time.Second)) {
+		t.Error("third action within a minute should be refused")
+	}
+	if !q.allow("acme/ou_b", now.Add(2*time.Second)) {
+		t.Error("quota should be per user")
+	}
+	if !q.allow("acme/ou_a", now.Add(61*time.Second)) {
+		t.Error("action after a minute should be allowed")
+	}
+}
+
+// 一分钟内没有动作的用户会被清理，hits 不随历史用户数增长
+func TestActionQuotaEvictsIdleUsers(t *testing.T) {
+	q := newActionQuota(5)
+	now := time.Now()
+	for i := 0; i < 1000; i++ {
+		q.allow(fmt.Sprintf("acme/ou_%d", i), now)
+	}
+	q.allow("acme/ou_active", now.Add(50*time.Second))
+	q.allow("acme/ou_late", now.Add(2*time.Minute))
+	if n := len(q.hits); n != 1 {
+		t.Errorf("len(hits) = %d after idle users expired, want 1", n)
+	}
+	if _, ok := q.hits["acme/ou_late"]; !ok {
+		t.Error("current user evicted")
+	}
+}
@@ -0,0 +1,35 @@
+package kb
+
+import "strings"
+
+// splitChunks 按段落切分文档：相邻段落合并到不超过 limit 个字符，超长段落按 limit 硬切
+func splitChunks(text string, limit int) []string {
+	var chunks []string
+	var cur []rune
+	flush := func() {
+		if s := strings.TrimSpace(string(cur)); s != "" {
+			chunks = append(chunks, s)
+		}
+		cur = cur[:0]
+	}
+	for _, para := range strings.Split(text, "\n") {
+		p := []rune(strings.TrimSpace(para))
+		if len(p) == 0 {
+			continue
+		}
+		if len(cur) > 0 && len(cur)+1+len(p) > limit {
+			flush()
+		}
+		for len(p) > limit {
+			flush()
+			chunks = append(chunks, string(p[:limit]))
+			p = p[limit:]
+		}
+		if len(cur) > 0 {
+			cur = append(cur, '\n')
+		}
+		cur = append(cur, p...)
+	}
+	flush()
+	return chunks
+}
@@ -0,0 +1,226 @@
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sayso-agent/internal/client/feishu"
+	clientllm "sayso-agent/internal/client/llm"
+	"sayso-agent/internal/model"
+	servicellm "sayso-agent/internal/service/llm"
+	"sayso-agent/internal/store"
+)
+
+// 知识库检索（RAG）：把配置的飞书知识空间、云空间目录或单篇文档切分为片段并向量化，保存在向量存储的 kb 集合中；
+// 提问时按向量相似度取出最相关的片段交给大模型回答，回答中标注引用的文档
+
+// 默认参数
+const (
+	defaultChunkChars = 600
+	defaultTopK       = 5
+	embedBatchSize    = 16
+	folderMaxDepth    = 3
+	collection        = "kb"
+)
+
+// Source 索引来源：知识空间（/wiki/space/<id>）、知识库节点（/wiki/<token>，含子节点）、云空间目录或单篇文档链接
+type Source struct {
+	Name string
+	URL  string
+}
+
+// Config 知识库配置
+type Config struct {
+	Sources []Source
+	// ChunkChars 每个片段的字符数上限，0 使用默认 600；TopK 每次检索的片段数，0 使用默认 5
+	ChunkChars int
+	TopK       int
+	// MinScore 片段与问题的最低相似度（余弦），低于该值的片段不用于回答
+	MinScore float64
+}
+
+// Chunk 文档片段
+type Chunk struct {
+	DocToken string
+	Title    string
+	URL      string
+	Text     string
+}
+
+// Status 索引状态
+type Status struct {
+	Docs      int       `json:"docs"`
+	Chunks    int       `json:"chunks"`
+	IndexedAt time.Time `json:"indexed_at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// KnowledgeBase 知识库：建索引、检索并回答
+type KnowledgeBase struct {
+	cfg     Config
+	feishu  *feishu.Client
+	llm     *clientllm.Client
+	vectors store.VectorStore
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// New 创建知识库，片段向量保存在 vectors 中；需调用 Reindex（由后台维护任务定期调用）建立索引后才能回答
+func New(cfg Config, feishuClient *feishu.Client, llmClient *clientllm.Client, vectors store.VectorStore) *KnowledgeBase {
+	return &KnowledgeBase{cfg: cfg, feishu: feishuClient, llm: llmClient, vectors: vectors}
+}
+
+// Status 返回当前索引状态
+func (k *KnowledgeBase) Status() Status {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.status
+}
+
+// Reindex 重新读取全部来源并建立索引；失败时保留旧索引
+func (k *KnowledgeBase) Reindex(ctx context.Context) error {
+	docs, err := k.collectDocs(ctx)
+	if err != nil {
+		k.setError(err)
+		return err
+	}
+	var records []store.VectorRecord
+	for _, d := range docs {
+		for i, text := range splitChunks(d.text, positiveOr(k.cfg.ChunkChars, defaultChunkChars)) {
+			records = append(records, store.VectorRecord{
+				ID:       d.token + "#" + strconv.Itoa(i),
+				Text:     text,
+				Metadata: map[string]string{"doc_token": d.token, "title": d.title, "url": d.url},
+			})
+		}
+	}
+	for i := 0; i < len(records); i += embedBatchSize {
+		batch := records[i:min(i+embedBatchSize, len(records))]
+		inputs := make([]string, len(batch))
+		for j, r := range batch {
+			// 片段带上文档标题，便于只在标题中出现的主题被检索到
+			inputs[j] = r.Metadata["title"] + "\n" + r.Text
+		}
+		vectors, err := k.llm.Embed(ctx, inputs)
+		if err != nil {
+			err = fmt.Errorf("kb embed: %w", err)
+			k.setError(err)
+			return err
+		}
+		for j := range batch {
+			batch[j].Vector = vectors[j]
+		}
+	}
+	if err := k.vectors.Replace(ctx, collection, records); err != nil {
+		err = fmt.Errorf("kb store: %w", err)
+		k.setError(err)
+		return err
+	}
+	k.mu.Lock()
+	k.status = Status{Docs: len(docs), Chunks: len(records), IndexedAt: time.Now()}
+	k.mu.Unlock()
+	log.Printf("kb: indexed %d docs, %d chunks", len(docs), len(records))
+	return nil
+}
+
+func (k *KnowledgeBase) setError(err error) {
+	k.mu.Lock()
+	k.status.Error = err.Error()
+	k.mu.Unlock()
+}
+
+// Search 返回与问题最相关的片段（按相似度从高到低）
+func (k *KnowledgeBase) Search(ctx context.Context, question string) ([]Chunk, error) {
+	vectors, err := k.llm.Embed(ctx, []string{question})
+	if err != nil {
+		return nil, fmt.Errorf("kb embed question: %w", err)
+	}
+	hits, err := k.vectors.Search(ctx, collection, vectors[0], positiveOr(k.cfg.TopK, defaultTopK))
+	if err != nil {
+		return nil, fmt.Errorf("kb search: %w", err)
+	}
+	var out []Chunk
+	for _, h := range hits {
+		if h.Score < k.cfg.MinScore {
+			continue
+		}
+		out = append(out, Chunk{DocToken: h.Metadata["doc_token"], Title: h.Metadata["title"], URL: h.Metadata["url"], Text: h.Text})
+	}
+	return out, nil
+}
+
+const answerPrompt = `你是公司内部知识库助手，只根据下面给出的资料片段回答员工的问题：
+- 回答简洁准确，适合直接念给用户听，关键数字、条件照原文给出
+- 每个结论后用 [序号] 标注所依据的片段，如"差旅住宿标准为一线城市每晚 500 元 [2]"
+- 资料中没有相关内容时如实说明"知识库中没有找到相关规定"，不要根据常识编造
+
+返回 JSON：
+{"answer":"回答正文","citations":[2]}
+
+citations 为回答实际引用的片段序号。只返回 JSON。`
+
+// Ask 检索相关片段并由大模型回答，Sources 为回答引用的文档
+func (k *KnowledgeBase) Ask(ctx context.Context, question string) (model.KBAnswer, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return model.KBAnswer{}, fmt.Errorf("%w: question is required", model.ErrInvalidParams)
+	}
+	if k.Status().IndexedAt.IsZero() {
+		return model.KBAnswer{}, fmt.Errorf("knowledge base is not indexed yet")
+	}
+	chunks, err := k.Search(ctx, question)
+	if err != nil {
+		return model.KBAnswer{}, err
+	}
+	if len(chunks) == 0 {
+		return model.KBAnswer{Text: "知识库中没有找到相关内容"}, nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "问题：%s\n\n资料片段：\n", question)
+	for i, c := range chunks {
+		fmt.Fprintf(&b, "[%d] 《%s》\n%s\n\n", i+1, c.Title, c.Text)
+	}
+	raw, err := k.llm.Chat(ctx, answerPrompt, b.String())
+	if err != nil {
+		return model.KBAnswer{}, err
+	}
+	var out struct {
+		Answer    string `json:"answer"`
+		Citations []int  `json:"citations"`
+	}
+	if err := json.Unmarshal([]byte(servicellm.ExtractJSON(raw)), &out); err != nil {
+		// 未按 JSON 返回时整段作为回答，引用全部片段
+		out.Answer = strings.TrimSpace(raw)
+		for i := range chunks {
+			out.Citations = append(out.Citations, i+1)
+		}
+	}
+	if out.Answer == "" {
+		return model.KBAnswer{}, fmt.Errorf("empty answer")
+	}
+	answer := model.KBAnswer{Text: out.Answer}
+	seen := make(map[string]bool)
+	for _, n := range out.Citations {
+		if n < 1 || n > len(chunks) || seen[chunks[n-1].DocToken] {
+			continue
+		}
+		c := chunks[n-1]
+		seen[c.DocToken] = true
+		answer.Sources = append(answer.Sources, model.KBSource{Title: c.Title, URL: c.URL})
+	}
+	return answer, nil
+}
+
+func positiveOr(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
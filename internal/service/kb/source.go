@@ -0,0 +1,153 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"sayso-agent/internal/client/feishu"
+)
+
+// document 读取到的一篇文档
+type document struct {
+	token string
+	title string
+	url   string
+	text  string
+}
+
+// docRef 待读取的文档
+type docRef struct {
+	token string
+	title string
+	url   string
+}
+
+// collectDocs 展开全部来源并读取文档正文；单篇文档读取失败只记录日志，来源本身无法访问时返回错误
+func (k *KnowledgeBase) collectDocs(ctx context.Context) ([]document, error) {
+	token, err := k.feishu.GetTenantAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var refs []docRef
+	for _, src := range k.cfg.Sources {
+		found, err := k.expandSource(ctx, token, src)
+		if err != nil {
+			return nil, fmt.Errorf("kb source %q: %w", src.Name, err)
+		}
+		refs = append(refs, found...)
+	}
+	seen := make(map[string]bool)
+	var docs []document
+	for _, r := range refs {
+		if seen[r.token] {
+			continue
+		}
+		seen[r.token] = true
+		text, err := k.feishu.GetDocRawContent(ctx, token, r.token)
+		if err != nil {
+			log.Printf("kb: read doc %s (%s) failed: %v", r.title, r.token, err)
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		docs = append(docs, document{token: r.token, title: r.title, url: r.url, text: text})
+	}
+	return docs, nil
+}
+
+// expandSource 把来源展开为文档列表；只索引新版文档（docx）
+func (k *KnowledgeBase) expandSource(ctx context.Context, token string, src Source) ([]docRef, error) {
+	if spaceID, ok := wikiSpaceID(src.URL); ok {
+		return k.wikiDocs(ctx, token, spaceID, "")
+	}
+	docToken, docType, err := feishu.ParseDocURL(src.URL)
+	if err != nil {
+		return nil, err
+	}
+	switch docType {
+	case "wiki":
+		node, err := k.feishu.GetWikiNode(ctx, token, docToken)
+		if err != nil {
+			return nil, err
+		}
+		refs := k.wikiRef(node)
+		if node.HasChild {
+			children, err := k.wikiDocs(ctx, token, node.SpaceID, node.NodeToken)
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, children...)
+		}
+		return refs, nil
+	case "folder":
+		var refs []docRef
+		k.folderDocs(ctx, token, docToken, 0, &refs)
+		return refs, nil
+	case "docx":
+		return []docRef{{token: docToken, title: src.Name, url: src.URL}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported source type %s (only wiki, folder and docx)", docType)
+	}
+}
+
+// wikiDocs 递归列出知识空间中某节点下的文档
+func (k *KnowledgeBase) wikiDocs(ctx context.Context, token, spaceID, parent string) ([]docRef, error) {
+	nodes, err := k.feishu.ListWikiNodes(ctx, token, spaceID, parent)
+	if err != nil {
+		return nil, err
+	}
+	var refs []docRef
+	for _, n := range nodes {
+		refs = append(refs, k.wikiRef(n)...)
+		if n.HasChild {
+			children, err := k.wikiDocs(ctx, token, spaceID, n.NodeToken)
+			if err != nil {
+				return nil, err
+			}
+			refs = append(refs, children...)
+		}
+	}
+	return refs, nil
+}
+
+func (k *KnowledgeBase) wikiRef(n feishu.WikiNode) []docRef {
+	if n.ObjType != "docx" {
+		return nil
+	}
+	return []docRef{{token: n.ObjToken, title: n.Title, url: k.feishu.DocURL("wiki", n.NodeToken)}}
+}
+
+// folderDocs 递归列出云空间目录下的文档，最多 folderMaxDepth 层；子目录读取失败时跳过
+func (k *KnowledgeBase) folderDocs(ctx context.Context, token, folderToken string, depth int, refs *[]docRef) {
+	files, err := k.feishu.ListFolderChildren(ctx, token, folderToken)
+	if err != nil {
+		log.Printf("kb: list folder %s failed: %v", folderToken, err)
+		return
+	}
+	for _, f := range files {
+		switch {
+		case f.Type == "docx":
+			url := f.URL
+			if url == "" {
+				url = k.feishu.DocURL("docx", f.Token)
+			}
+			*refs = append(*refs, docRef{token: f.Token, title: f.Name, url: url})
+		case f.Type == "folder" && depth+1 < folderMaxDepth:
+			k.folderDocs(ctx, token, f.Token, depth+1, refs)
+		}
+	}
+}
+
+// wikiSpaceID 解析知识空间链接 https://xxx.feishu.cn/wiki/space/7xxx
+func wikiSpaceID(rawURL string) (string, bool) {
+	_, rest, ok := strings.Cut(rawURL, "/wiki/space/")
+	if !ok {
+		return "", false
+	}
+	id, _, _ := strings.Cut(rest, "?")
+	id = strings.Trim(id, "/")
+	return id, id != ""
+}
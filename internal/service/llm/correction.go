@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+const correctionPrompt = `用户刚才的指令已经执行，现在用户更正了其中的部分内容（如"不对，我是说发给李四"）。
+根据原指令、已执行的动作列表（带序号）和更正内容，给出更正后的完整动作列表，返回 JSON：
+{"actions":[{"index":1,"type":"动作类型","params":{...}}]}
+
+要求：
+- index 为对应的原动作序号；更正后新增的动作 index 为 0
+- 未受更正影响的动作原样保留（type、params 完全不变）
+- 受影响的动作只修改更正涉及的参数，其余参数保持原值
+- 用户要求不再执行的动作直接省略
+- params 中的 {{...}} 占位符原样保留
+
+只返回 JSON。`
+
+// CorrectedAction 更正后的动作；Index 为对应的原动作序号（从 1 开始），0 表示新增
+type CorrectedAction struct {
+	Index int
+	Spec  model.ActionSpec
+}
+
+// Correct 根据用户的更正重新生成原计划中受影响的动作参数
+func (s *Service) Correct(ctx context.Context, original string, plan []model.ActionSpec, correction string) ([]CorrectedAction, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "原指令：%s\n\n已执行的动作：", original)
+	for i, spec := range plan {
+		params, _ := json.Marshal(spec.Params)
+		fmt.Fprintf(&b, "\n%d. %s %s", i+1, spec.Type, params)
+	}
+	fmt.Fprintf(&b, "\n\n更正：%s", correction)
+
+	raw, err := s.client.Chat(ctx, correctionPrompt, b.String())
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Actions []struct {
+			Index  int            `json:"index"`
+			Type   string         `json:"type"`
+			Params map[string]any `json:"params"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(ExtractJSON(raw)), &out); err != nil {
+		return nil, fmt.Errorf("parse correction: %w", err)
+	}
+	actions := make([]CorrectedAction, 0, len(out.Actions))
+	for _, a := range out.Actions {
+		if a.Index < 0 || a.Index > len(plan) {
+			return nil, fmt.Errorf("parse correction: unknown action index %d", a.Index)
+		}
+		if a.Type == "" {
+			return nil, fmt.Errorf("parse correction: action %d has no type", a.Index)
+		}
+		actions = append(actions, CorrectedAction{Index: a.Index, Spec: model.ActionSpec{Type: a.Type, Params: a.Params}})
+	}
+	return actions, nil
+}
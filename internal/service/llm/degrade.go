@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	clientllm "sayso-agent/internal/client/llm"
+)
+
+// 延迟降级：规划、参数提取的耗时持续超出延迟目标时，改用较快的备用模型，相同输入直接复用近期的计划，
+// 以守住时限；用了降级手段的结果标记为 Degraded
+
+// maxCachedPlans 计划缓存的条目上限，超出时先清理过期条目，仍超出则清空
+const maxCachedPlans = 1000
+
+// LatencyGuard 判断当前是否需要降级（由 slo.Tracker 实现）
+type LatencyGuard interface {
+	Degraded() bool
+}
+
+// Degradation 降级配置
+type Degradation struct {
+	// Guard 是否降级的判断
+	Guard LatencyGuard
+	// FallbackModel 降级时使用的对话模型，为空时不换模型；请求指定了模型时不换
+	FallbackModel string
+	// PlanCacheTTL 规划结果缓存时长，降级时相同租户、相同规划输入（含会话上下文）直接使用缓存的计划；0 不缓存
+	PlanCacheTTL time.Duration
+}
+
+// planCache 近期的规划结果，按租户与规划输入索引；保存 JSON 以免执行时修改缓存的计划
+type planCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedPlan
+}
+
+type cachedPlan struct {
+	plan []byte
+	at   time.Time
+}
+
+func newPlanCache(ttl time.Duration) *planCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &planCache{ttl: ttl, entries: make(map[string]cachedPlan)}
+}
+
+func (c *planCache) get(tenant, input string) *TaskPlan {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	e, ok := c.entries[tenant+"\x00"+input]
+	c.mu.Unlock()
+	if !ok || time.Since(e.at) > c.ttl {
+		return nil
+	}
+	var plan TaskPlan
+	if err := json.Unmarshal(e.plan, &plan); err != nil {
+		return nil
+	}
+	return &plan
+}
+
+func (c *planCache) put(tenant, input string, plan *TaskPlan) {
+	if c == nil || len(plan.Tasks) == 0 {
+		return
+	}
+	b, err := json.Marshal(plan)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedPlans {
+		for k, e := range c.entries {
+			if now.Sub(e.at) > c.ttl {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCachedPlans {
+			clear(c.entries)
+		}
+	}
+	c.entries[tenant+"\x00"+input] = cachedPlan{plan: b, at: now}
+}
+
+// degrading 当前是否应降级
+func (s *Service) degrading() bool {
+	return s.degrade != nil && s.degrade.Guard != nil && s.degrade.Guard.Degraded()
+}
+
+// withFallbackModel 换用备用模型；未配置备用模型或请求已指定模型时返回 false
+func (s *Service) withFallbackModel(ctx context.Context) (context.Context, bool) {
+	o := clientllm.OptionsFrom(ctx)
+	if s.degrade.FallbackModel == "" || o.Model != "" {
+		return ctx, false
+	}
+	o.Model = s.degrade.FallbackModel
+	return clientllm.WithOptions(ctx, o), true
+}
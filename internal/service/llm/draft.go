@@ -0,0 +1,198 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"sayso-agent/internal/model"
+)
+
+// draftPrompt 撰写正文的生成 Prompt，与参数提取分开调用；{{kind}} {{tone}} {{length}} 由 draftInstruction 填充
+const draftPrompt = `你是写作助手。按要求撰写{{kind}}正文：
+- 语气：{{tone}}
+- 篇幅：{{length}}
+- 只根据要求中给出的事实撰写，缺少的具体信息（日期、金额、姓名）用"【待补充】"标出，不要编造
+- 不要 Markdown 标题，不要解释写作思路
+
+只返回正文。`
+
+// draftTones 语气参数对应的写作要求，未识别的取值原样使用
+var draftTones = map[string]string{
+	"formal":     "正式、礼貌，用书面语",
+	"casual":     "轻松、口语化",
+	"friendly":   "友好、亲切",
+	"apologetic": "诚恳致歉，先道歉再说明原因与补救措施",
+	"concise":    "直截了当，不加客套",
+}
+
+// draftLengths 篇幅参数对应的写作要求
+var draftLengths = map[string]string{
+	"short":  "简短，3 句话以内",
+	"medium": "适中，100-300 字",
+	"long":   "详细，500 字左右，分段",
+}
+
+// draftSpec 提取阶段给出的撰写要求（params.draft）
+type draftSpec struct {
+	Brief  string
+	Tone   string
+	Length string
+}
+
+// parseDraft 读取动作参数中的撰写要求；没有 draft 或 brief 为空时返回 false
+func parseDraft(params map[string]any) (draftSpec, bool) {
+	m, ok := params["draft"].(map[string]any)
+	if !ok {
+		return draftSpec{}, false
+	}
+	var d draftSpec
+	d.Brief, _ = m["brief"].(string)
+	d.Tone, _ = m["tone"].(string)
+	d.Length, _ = m["length"].(string)
+	return d, strings.TrimSpace(d.Brief) != ""
+}
+
+// redraftPrompt 按修改意见改写草稿的生成 Prompt，占位符同 draftPrompt
+const redraftPrompt = `你是写作助手。根据修改意见修改{{kind}}草稿：
+- 只按修改意见改动，其余内容尽量保持原样
+- 语气：{{tone}}；篇幅：{{length}}；修改意见另有要求时以修改意见为准
+- 只根据原始要求与草稿中的事实撰写，缺少的具体信息用"【待补充】"标出，不要编造
+- 不要解释改了什么
+
+只返回修改后的正文。`
+
+// draftInstruction 按动作类型、语气与篇幅生成撰写 Prompt
+func draftInstruction(actionType string, d draftSpec) string {
+	return fillDraftPrompt(draftPrompt, actionType, d)
+}
+
+// fillDraftPrompt 按动作类型、语气与篇幅填充撰写/改写 Prompt
+func fillDraftPrompt(prompt, actionType string, d draftSpec) string {
+	kind, length := "文档", "适中，100-300 字"
+	if actionType == model.ActionTypeSendMessage {
+		kind, length = "消息", "简短，3 句话以内"
+	}
+	tone := "自然、得体"
+	if d.Tone != "" {
+		if tone = draftTones[d.Tone]; tone == "" {
+			tone = d.Tone
+		}
+	}
+	if d.Length != "" {
+		if length = draftLengths[d.Length]; length == "" {
+			length = d.Length
+		}
+	}
+	return strings.NewReplacer("{{kind}}", kind, "{{tone}}", tone, "{{length}}", length).Replace(prompt)
+}
+
+// draftActions 撰写阶段：对带有撰写要求的 create_doc、send_message 动作调用生成 Prompt，
+// 把草稿写入正文（文档 content、消息 content.text）；各动作并行生成，任一失败即返回错误
+func (s *Service) draftActions(ctx context.Context, results map[string]*TaskResult) error {
+	var actions []*model.ActionSpec
+	for _, r := range results {
+		if r.Action != nil {
+			actions = append(actions, r.Action)
+		}
+		actions = append(actions, r.Items...)
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for _, action := range actions {
+		if action.Type != model.ActionTypeCreateDoc && action.Type != model.ActionTypeSendMessage {
+			continue
+		}
+		d, ok := parseDraft(action.Params)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(action *model.ActionSpec) {
+			defer wg.Done()
+			text, err := s.draft(ctx, action.Type, d)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("draft %s: %w", action.Type, err)
+				}
+				return
+			}
+			applyDraft(action, text)
+		}(action)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// draft 调用生成 Prompt 撰写正文
+func (s *Service) draft(ctx context.Context, actionType string, d draftSpec) (string, error) {
+	raw, err := s.client.Chat(ctx, draftInstruction(actionType, d), d.Brief)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(raw)
+	if text == "" {
+		return "", fmt.Errorf("empty draft")
+	}
+	return text, nil
+}
+
+// Redraft 按修改意见（如"把第二段删掉，语气再轻一点"）改写待发送动作中的草稿，以原始要求与当前草稿为上下文；
+// 返回更新了正文的动作，草稿标记为已修改（params.draft.revised）
+func (s *Service) Redraft(ctx context.Context, spec model.ActionSpec, revision string) (model.ActionSpec, error) {
+	d, _ := parseDraft(spec.Params)
+	m, _ := spec.Params["draft"].(map[string]any)
+	current, _ := m["text"].(string)
+	if current == "" {
+		return spec, fmt.Errorf("%w: %s has no draft to revise", model.ErrInvalidParams, spec.Type)
+	}
+	input := fmt.Sprintf("原始要求：%s\n\n当前草稿：\n%s\n\n修改意见：%s", d.Brief, current, revision)
+	raw, err := s.client.Chat(ctx, fillDraftPrompt(redraftPrompt, spec.Type, d), input)
+	if err != nil {
+		return spec, err
+	}
+	text := strings.TrimSpace(raw)
+	if text == "" {
+		return spec, fmt.Errorf("redraft %s: empty draft", spec.Type)
+	}
+	applyDraft(&spec, text)
+	spec.Params["draft"].(map[string]any)["revised"] = true
+	return spec, nil
+}
+
+// applyDraft 把草稿写入动作正文；params.draft 保留撰写要求并附上草稿，沙箱预览与任务记录可据此展示
+func applyDraft(action *model.ActionSpec, text string) {
+	// 参数 map 可能与快速路径、循环任务的其他项共享，写入前复制
+	params := make(map[string]any, len(action.Params)+1)
+	for k, v := range action.Params {
+		params[k] = v
+	}
+	draft := map[string]any{}
+	for k, v := range action.Params["draft"].(map[string]any) {
+		draft[k] = v
+	}
+	draft["text"] = text
+	params["draft"] = draft
+	if action.Type == model.ActionTypeSendMessage {
+		content := map[string]any{}
+		if c, ok := params["content"].(map[string]any); ok {
+			for k, v := range c {
+				content[k] = v
+			}
+		}
+		content["text"] = text
+		params["content"] = content
+		if t, _ := params["message_type"].(string); t == "" {
+			params["message_type"] = "text"
+		}
+	} else {
+		params["content"] = text
+	}
+	action.Params = params
+}
@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// fastPathPrompt 单次提取：简单指令直接给出技能与动作参数，与规划并行调用；
+// 规划结果只有一个任务且技能一致时采用，省去第二轮参数提取
+const fastPathPrompt = `如果用户输入只需要执行下面某一个技能的一次操作（不依赖其他任务、不对多个对象分别执行），按该技能的格式提取参数，返回 JSON：
+{"skill":"技能名","platform":"feishu|slack","action":{按该技能格式的 JSON}}
+否则返回 {"skill":""}。
+
+输入可能以"最近对话"开头，只处理"当前输入"，指代从最近对话中确定。
+
+{{skill_prompts}}
+
+只返回 JSON。`
+
+// fastResult 单次提取的结果
+type fastResult struct {
+	Skill    SkillType        `json:"skill"`
+	Platform string           `json:"platform"`
+	Action   model.ActionSpec `json:"action"`
+}
+
+// startFastPath 与规划并行发起单次提取；未启用（含灰度未覆盖该租户）、请求带附件（需先识别图片）
+// 或租户没有可用的快速技能时返回 nil
+func (s *Service) startFastPath(ctx context.Context, req model.ASRRequest) <-chan *fastResult {
+	if len(s.fastPath) == 0 || len(req.Attachments) > 0 || !s.skills.Feature(req.Tenant(), "fast_path") {
+		return nil
+	}
+	var sections []string
+	for _, skill := range s.fastPath {
+		prompt, ok := skillPrompts[skill]
+		if !ok || !s.skills.Enabled(req.Tenant(), skill) {
+			continue
+		}
+		sections = append(sections, "### "+string(skill)+"\n"+prompt+s.skillPromptExtras(skill))
+	}
+	if len(sections) == 0 {
+		return nil
+	}
+	prompt := strings.Replace(fastPathPrompt, "{{skill_prompts}}", strings.Join(sections, "\n\n"), 1)
+	ch := make(chan *fastResult, 1)
+	go func() {
+		raw, err := s.client.Chat(ctx, prompt, plannerInput(req))
+		if err != nil {
+			ch <- nil
+			return
+		}
+		var r fastResult
+		if err := json.Unmarshal([]byte(ExtractJSON(raw)), &r); err != nil || r.Skill == "" {
+			ch <- nil
+			return
+		}
+		ch <- &r
+	}()
+	return ch
+}
+
+// useFastPath 规划只有一个普通任务、且单次提取给出了同一技能与平台时，直接作为该任务的参数提取结果；
+// 单次提取尚未返回时等待至 ctx 结束（不超过原本第二轮提取的耗时）
+func (s *Service) useFastPath(ctx context.Context, fast <-chan *fastResult, plan *TaskPlan) (map[string]*TaskResult, bool) {
+	if fast == nil || len(plan.Tasks) != 1 {
+		return nil, false
+	}
+	task := &plan.Tasks[0]
+	if task.ForEach != nil || len(task.DependsOn) > 0 {
+		return nil, false
+	}
+	var r *fastResult
+	select {
+	case r = <-fast:
+	case <-ctx.Done():
+		return nil, false
+	}
+	if r == nil || r.Skill != task.Skill || (r.Platform != "" && task.Platform != "" && r.Platform != task.Platform) {
+		return nil, false
+	}
+	action := r.Action
+	fillPlatform(&action, task)
+	return map[string]*TaskResult{task.ID: {TaskID: task.ID, Action: &action, Outputs: make(map[string]string)}}, true
+}
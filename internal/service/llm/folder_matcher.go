@@ -8,16 +8,92 @@ import (
 
 	"sayso-agent/internal/client/feishu"
 	clientllm "sayso-agent/internal/client/llm"
+	"sayso-agent/internal/store"
+)
+
+// 目录较多时先按向量相似度筛出候选再交给大模型，避免提示词过长
+const (
+	folderCollection         = "folders"
+	folderShortlistThreshold = 30
+	folderShortlistSize      = 20
+	folderEmbedBatchSize     = 64
 )
 
 // FolderMatcher 智能目录匹配服务（依赖大模型）
 type FolderMatcher struct {
-	client *clientllm.Client
+	client  *clientllm.Client
+	vectors store.VectorStore
+}
+
+// NewFolderMatcher 创建目录匹配服务；vectors 为 nil 时不做向量筛选，始终把全部目录交给大模型
+func NewFolderMatcher(client *clientllm.Client, vectors store.VectorStore) *FolderMatcher {
+	return &FolderMatcher{client: client, vectors: vectors}
+}
+
+// IndexFolders 把目录名称向量化写入 folders 集合（全量替换），由后台任务定期调用
+func (m *FolderMatcher) IndexFolders(ctx context.Context, folders []feishu.FolderInfo) error {
+	if m.vectors == nil {
+		return nil
+	}
+	records := make([]store.VectorRecord, len(folders))
+	for i, f := range folders {
+		records[i] = store.VectorRecord{ID: f.Token, Text: f.Name, Metadata: map[string]string{"parent_token": f.ParentToken}}
+	}
+	for i := 0; i < len(records); i += folderEmbedBatchSize {
+		batch := records[i:min(i+folderEmbedBatchSize, len(records))]
+		inputs := make([]string, len(batch))
+		for j, r := range batch {
+			inputs[j] = r.Text
+		}
+		vectors, err := m.client.Embed(ctx, inputs)
+		if err != nil {
+			return fmt.Errorf("embed folders: %w", err)
+		}
+		for j := range batch {
+			batch[j].Vector = vectors[j]
+		}
+	}
+	return m.vectors.Replace(ctx, folderCollection, records)
 }
 
-// NewFolderMatcher 创建目录匹配服务
-func NewFolderMatcher(client *clientllm.Client) *FolderMatcher {
-	return &FolderMatcher{client: client}
+// shortlist 从 folders 中取与标题最相似的若干目录；索引为空或检索失败时返回 nil，由调用方使用全部目录
+func (m *FolderMatcher) shortlist(ctx context.Context, docTitle string, folders []feishu.FolderInfo) []feishu.FolderInfo {
+	if m.vectors == nil || len(folders) <= folderShortlistThreshold {
+		return nil
+	}
+	vectors, err := m.client.Embed(ctx, []string{docTitle})
+	if err != nil {
+		return nil
+	}
+	// 索引可能来自其他租户或较旧的目录树，多取一些再按本次目录过滤
+	hits, err := m.vectors.Search(ctx, folderCollection, vectors[0], folderShortlistSize*2)
+	if err != nil || len(hits) == 0 {
+		return nil
+	}
+	byToken := make(map[string]feishu.FolderInfo, len(folders))
+	for _, f := range folders {
+		byToken[f.Token] = f
+	}
+	var out []feishu.FolderInfo
+	for _, h := range hits {
+		if f, ok := byToken[h.ID]; ok && len(out) < folderShortlistSize {
+			out = append(out, f)
+			delete(byToken, h.ID)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	// 根目录始终保留，作为没有合适目录时的兜底选项
+	for _, f := range folders {
+		if f.Name == "我的空间" || f.ParentToken == "" {
+			if _, ok := byToken[f.Token]; ok {
+				out = append(out, f)
+			}
+			break
+		}
+	}
+	return out
 }
 
 // folderMatchResult LLM 返回的匹配结果
@@ -47,9 +123,13 @@ func (m *FolderMatcher) MatchFolder(ctx context.Context, docTitle string, folder
 		return folders[0].Token, folders[0].Name, nil
 	}
 
+	candidates := folders
+	if short := m.shortlist(ctx, docTitle, folders); short != nil {
+		candidates = short
+	}
 	var folderList strings.Builder
 	var rootToken, rootName string
-	for i, f := range folders {
+	for i, f := range candidates {
 		if f.Name == "我的空间" || f.ParentToken == "" {
 			rootToken = f.Token
 			rootName = f.Name
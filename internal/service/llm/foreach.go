@@ -0,0 +1,159 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"sayso-agent/internal/model"
+)
+
+const (
+	// foreachMaxItems 单个循环任务最多展开的项数，防止一句话触发大量动作
+	foreachMaxItems = 50
+	// foreachParallel 循环任务参数提取的默认并发数
+	foreachParallel = 4
+)
+
+// ForEach 循环任务：对列表中每一项分别执行同一技能
+type ForEach struct {
+	Source      string   `json:"source"`                 // contacts | items
+	Items       []string `json:"items,omitempty"`        // source=items 时的列表
+	MaxParallel int      `json:"max_parallel,omitempty"` // 并发上限，默认 foreachParallel
+}
+
+// resolveItems 解析循环列表
+func (f *ForEach) resolveItems(req model.ASRRequest) ([]string, error) {
+	var items []string
+	switch f.Source {
+	case "contacts":
+		for _, c := range req.Contacts {
+			if c.Name != "" {
+				items = append(items, c.Name)
+			}
+		}
+	case "items", "":
+		items = f.Items
+	default:
+		return nil, fmt.Errorf("foreach: unsupported source: %s", f.Source)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("foreach: empty list (source=%s)", f.Source)
+	}
+	if len(items) > foreachMaxItems {
+		return nil, fmt.Errorf("foreach: too many items: %d > %d", len(items), foreachMaxItems)
+	}
+	return items, nil
+}
+
+// foreachItemID 循环任务第 i 项的任务 ID，如 task_2_1
+func foreachItemID(taskID string, i int) string {
+	return fmt.Sprintf("%s_%d", taskID, i+1)
+}
+
+// bareOutputRE 匹配不带任务前缀的输出占位符，如 {{doc_url}}
+var bareOutputRE = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+// executeItem 为循环任务的一项提取参数；各项在独立的 goroutine 中执行，panic 记为该项失败
+func (s *Service) executeItem(ctx context.Context, task *TaskSpec, depResults map[string]*TaskResult, tenant string) (result *TaskResult) {
+	defer s.recoverTask(ctx, task, &result)
+	return s.executeTask(ctx, task, depResults, tenant)
+}
+
+// executeForEach 按项展开循环任务，有界并发地为每一项提取参数；各项输入中的 {{task_N.key}} 按依赖任务的结果替换
+func (s *Service) executeForEach(ctx context.Context, task *TaskSpec, tasks []TaskSpec, depResults map[string]*TaskResult, req model.ASRRequest) *TaskResult {
+	result := &TaskResult{TaskID: task.ID, Outputs: make(map[string]string)}
+	items, err := task.ForEach.resolveItems(req)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	// 依赖的循环任务若遍历同一列表，第 N 项的裸占位符改写为指向其第 N 项
+	pairedDep := ""
+	for _, depID := range task.DependsOn {
+		for i := range tasks {
+			if tasks[i].ID == depID && tasks[i].ForEach != nil && tasks[i].ForEach.Source == task.ForEach.Source {
+				pairedDep = depID
+			}
+		}
+	}
+
+	parallel := task.ForEach.MaxParallel
+	if parallel <= 0 || parallel > foreachParallel {
+		parallel = foreachParallel
+	}
+	sem := make(chan struct{}, parallel)
+	actions := make([]*model.ActionSpec, len(items))
+	errs := make([]error, len(items))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		go func(i int, item string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := ctx.Err(); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", item, err)
+				return
+			}
+
+			itemTask := *task
+			itemTask.ID = foreachItemID(task.ID, i)
+			if strings.Contains(task.Input, "{{item}}") {
+				itemTask.Input = strings.ReplaceAll(task.Input, "{{item}}", item)
+			} else {
+				itemTask.Input = fmt.Sprintf("%s（当前对象：%s）", task.Input, item)
+			}
+			r := s.executeItem(ctx, &itemTask, depResults, req.Tenant())
+			if r.Error != nil {
+				errs[i] = fmt.Errorf("%s: %w", item, r.Error)
+				return
+			}
+			if pairedDep != "" {
+				if err := scopeBarePlaceholders(r.Action, foreachItemID(pairedDep, i)); err != nil {
+					errs[i] = fmt.Errorf("%s: %w", item, err)
+					return
+				}
+			}
+			actions[i] = r.Action
+		}(i, item)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			result.Error = fmt.Errorf("foreach: %w", err)
+			return result
+		}
+	}
+	result.Items = actions
+	return result
+}
+
+// scopeBarePlaceholders 把动作参数中的 {{key}} 改写为 {{taskID.key}}
+func scopeBarePlaceholders(action *model.ActionSpec, taskID string) error {
+	if action == nil || action.Params == nil {
+		return nil
+	}
+	raw, err := json.Marshal(action.Params)
+	if err != nil {
+		return err
+	}
+	scoped := bareOutputRE.ReplaceAllStringFunc(string(raw), func(match string) string {
+		key := bareOutputRE.FindStringSubmatch(match)[1]
+		if strings.HasPrefix(key, "last_") {
+			return match
+		}
+		return "{{" + taskID + "." + key + "}}"
+	})
+	var params map[string]any
+	if err := json.Unmarshal([]byte(scoped), &params); err != nil {
+		return err
+	}
+	action.Params = params
+	return nil
+}
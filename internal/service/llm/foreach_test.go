@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	clientllm "sayso-agent/internal/client/llm"
+	"sayso-agent/internal/model"
+)
+
+// recordingTransport 记下每次对话的用户输入，回复固定的 send_message 参数
+type recordingTransport struct {
+	mu     sync.Mutex
+	inputs []string
+}
+
+func (rt *recordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var req clientllm.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	rt.mu.Lock()
+	rt.inputs = append(rt.inputs, req.Messages[len(req.Messages)-1].Content)
+	rt.mu.Unlock()
+	content, _ := json.Marshal(`{"type":"send_message","params":{"message":"hi"}}`)
+	body := `{"choices":[{"message":{"role":"assistant","content":` + string(content) + `}}]}`
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Request:    r,
+	}, nil
+}
+
+func TestExecuteForEachResolvesDependencyOutputs(t *testing.T) {
+	rt := &recordingTransport{}
+	client := clientllm.NewClient(clientllm.Config{BaseURL: "http://llm.test", Model: "m", Transport: rt})
+	s := NewService(Deps{Client: client})
+
+	task := &TaskSpec{
+		ID:        "task_2",
+		Skill:     SkillSendMessage,
+		Input:     "把 {{doc_url}} 发给 {{item}}",
+		DependsOn: []string{"task_1"},
+		ForEach:   &ForEach{Source: "items", Items: []string{"张三", "李四"}},
+	}
+	deps := map[string]*TaskResult{"task_1": {TaskID: "task_1", Outputs: map[string]string{"doc_url": "https://example.feishu.cn/docx/abc"}}}
+	result := s.executeForEach(context.Background(), task, []TaskSpec{{ID: "task_1"}, *task}, deps, model.ASRRequest{})
+	if result.Error != nil {
+		t.Fatalf("executeForEach error = %v", result.Error)
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("items = %d, want 2", len(result.Items))
+	}
+	if len(rt.inputs) != 2 {
+		t.Fatalf("llm calls = %d, want 2", len(rt.inputs))
+	}
+	for _, in := range rt.inputs {
+		if !strings.Contains(in, "https://example.feishu.cn/docx/abc") || strings.Contains(in, "{{doc_url}}") {
+			t.Errorf("item input = %q, want dependency output resolved", in)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package llm
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"sayso-agent/internal/model"
+)
+
+// 规则预解析：最常见的简单指令（"发消息给X说Y"、"创建文档《X》"）用正则直接得到动作，不调用大模型；
+// 只在整句完全命中、目标与内容无歧义时采用，其余情况交给规划器
+
+const (
+	heuristicPrefix = `^(?:请|帮我|麻烦|麻烦你)?\s*`
+	// heuristicSep 目标与消息内容之间的分隔："说"或冒号
+	heuristicSep = `\s*(?:[，,]?\s*说\s*[：:，,]?|[：:])\s*`
+	// heuristicTarget 目标：不含空白、标点与"说"，是否可用由 messageTarget 判断
+	heuristicTarget = `([^\s，,。：:说]{1,20}?)`
+)
+
+var (
+	// 发消息给张三说…… / 发给张三：…… / 通知张三说……
+	reSendTo = regexp.MustCompile(heuristicPrefix + `(?:发(?:一)?[条个]?消息给|发给|通知)` + heuristicTarget + heuristicSep + `(.+)$`)
+	// 给张三发消息说…… / 跟张三发条消息：……
+	reSendVia = regexp.MustCompile(heuristicPrefix + `(?:给|跟|和)` + heuristicTarget + `\s*发(?:一)?[条个]?消息` + heuristicSep + `(.+)$`)
+	// 创建文档《周报》 / 新建一个《周报》文档
+	reCreateDoc = regexp.MustCompile(heuristicPrefix + `(?:创建|新建|建)(?:一[个份篇])?(?:飞书)?(?:文档)?《([^《》]{1,50})》(?:文档)?$`)
+	// 创建文件夹《项目资料》 / 新建一个文件夹「项目资料」
+	reCreateFolder = regexp.MustCompile(heuristicPrefix + `(?:创建|新建|建)(?:一个)?文件夹[《「]([^《》「」/]{1,50})[》」]$`)
+
+	// reHanName 2~3 个汉字的人名
+	reHanName = regexp.MustCompile(`^\p{Han}{2,3}$`)
+	// reSlackChannel Slack 频道，如 #general
+	reSlackChannel = regexp.MustCompile(`^#[\w-]+$`)
+	reFeishuUser   = regexp.MustCompile(`^ou_\w+$`)
+	reFeishuChat   = regexp.MustCompile(`^oc_\w+$`)
+)
+
+// heuristicAmbiguous 出现这些词时可能是多个任务、指代或需要大模型理解的内容，不走规则预解析
+var heuristicAmbiguous = []string{"然后", "接着", "之后", "并且", "同时", "顺便", "再把", "再给", "再发", "每个", "分别", "{{"}
+
+// heuristicPronouns 指代词不能作为消息目标
+var heuristicPronouns = []string{"他", "她", "它", "我", "你", "大家", "所有人", "们", "和", "与", "及", "、"}
+
+// heuristicMaxText 超过该长度的输入交给大模型
+const heuristicMaxText = 200
+
+// heuristicParse 规则预解析，命中时返回完整的动作输出；未启用（含灰度未覆盖该租户）、带附件、命中工作流、
+// 技能未开放或不确定时返回 nil
+func (s *Service) heuristicParse(ctx context.Context, req model.ASRRequest) *model.LLMActionOutput {
+	if !s.heuristics || len(req.Attachments) > 0 || !s.skills.Feature(req.Tenant(), "heuristics") {
+		return nil
+	}
+	text := strings.TrimRight(strings.TrimSpace(req.Text), "。.！!")
+	if text == "" || utf8.RuneCountInString(text) > heuristicMaxText {
+		return nil
+	}
+	for _, w := range heuristicAmbiguous {
+		if strings.Contains(text, w) {
+			return nil
+		}
+	}
+	skill, action, intent := s.matchHeuristic(text)
+	if action == nil || !s.skills.Enabled(req.Tenant(), skill) {
+		return nil
+	}
+	// 命中已保存的工作流时以工作流为准
+	if wf, err := s.matchWorkflow(ctx, req); err != nil || wf != nil {
+		return nil
+	}
+	action.TaskID = "task_1"
+	return &model.LLMActionOutput{Intent: intent, Actions: []model.ActionSpec{*action}}
+}
+
+// matchHeuristic 按规则匹配输入，返回技能、动作与意图摘要；未命中时 action 为 nil
+func (s *Service) matchHeuristic(text string) (SkillType, *model.ActionSpec, string) {
+	for _, re := range []*regexp.Regexp{reSendTo, reSendVia} {
+		m := re.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		platform, targetType, ok := s.messageTarget(m[1])
+		content := strings.TrimSpace(m[2])
+		if !ok || content == "" {
+			return "", nil, ""
+		}
+		return SkillSendMessage, &model.ActionSpec{
+			Type: model.ActionTypeSendMessage,
+			Params: map[string]any{
+				"platform":     platform,
+				"message_type": "text",
+				"content":      map[string]any{"text": content},
+				"target_type":  targetType,
+				"targets":      []any{m[1]},
+			},
+		}, "给" + m[1] + "发消息"
+	}
+	if m := reCreateDoc.FindStringSubmatch(text); m != nil {
+		title := strings.TrimSpace(m[1])
+		return SkillCreateDoc, &model.ActionSpec{
+			Type:   model.ActionTypeCreateDoc,
+			Params: map[string]any{"title": title},
+		}, "创建文档《" + title + "》"
+	}
+	if m := reCreateFolder.FindStringSubmatch(text); m != nil {
+		name := strings.TrimSpace(m[1])
+		return SkillCreateFolder, &model.ActionSpec{
+			Type:   model.ActionTypeCreateFolder,
+			Params: map[string]any{"name": name},
+		}, "创建文件夹《" + name + "》"
+	}
+	return "", nil, ""
+}
+
+// messageTarget 判断消息目标的平台与类型；联系人分组、#频道、ou_/oc_ ID 与 2~3 字人名之外的目标视为不确定
+func (s *Service) messageTarget(target string) (platform, targetType string, ok bool) {
+	switch {
+	case slices.Contains(s.aliases, target):
+		return "feishu", "batch", true
+	case reSlackChannel.MatchString(target):
+		return "slack", "chat", true
+	case reFeishuUser.MatchString(target):
+		return "feishu", "user", true
+	case reFeishuChat.MatchString(target):
+		return "feishu", "chat", true
+	case reHanName.MatchString(target):
+		for _, p := range heuristicPronouns {
+			if strings.Contains(target, p) {
+				return "", "", false
+			}
+		}
+		return "feishu", "user", true
+	}
+	return "", "", false
+}
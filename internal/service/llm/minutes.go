@@ -0,0 +1,49 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	clientllm "sayso-agent/internal/client/llm"
+	"sayso-agent/internal/model"
+)
+
+// transcriptLimit 送入大模型的会议文字记录最大字符数，超出部分截断
+const transcriptLimit = 30000
+
+const meetingNotesPrompt = `你是会议纪要助手。根据会议文字记录整理结构化纪要，返回 JSON：
+{"title":"纪要标题","summary":"3-5 句话的会议摘要","topics":["讨论要点"],"decisions":["会议结论"],"todos":[{"owner":"负责人","content":"待办内容"}]}
+
+规则：
+- 只根据记录内容整理，不要编造
+- 没有明确负责人的待办 owner 留空
+- title 使用"会议主题 会议纪要"格式
+
+只返回 JSON。`
+
+// MinutesSummarizer 会议纪要整理服务（依赖大模型）
+type MinutesSummarizer struct {
+	client *clientllm.Client
+}
+
+// NewMinutesSummarizer 创建会议纪要整理服务
+func NewMinutesSummarizer(client *clientllm.Client) *MinutesSummarizer {
+	return &MinutesSummarizer{client: client}
+}
+
+// SummarizeMeeting 将会议文字记录整理为结构化纪要
+func (m *MinutesSummarizer) SummarizeMeeting(ctx context.Context, title, transcript string) (model.MeetingNotes, error) {
+	if r := []rune(transcript); len(r) > transcriptLimit {
+		transcript = string(r[:transcriptLimit])
+	}
+	raw, err := m.client.Chat(ctx, meetingNotesPrompt, fmt.Sprintf("会议标题：%s\n\n文字记录：\n%s", title, transcript))
+	if err != nil {
+		return model.MeetingNotes{}, err
+	}
+	var notes model.MeetingNotes
+	if err := json.Unmarshal([]byte(ExtractJSON(raw)), &notes); err != nil {
+		return model.MeetingNotes{}, fmt.Errorf("parse meeting notes: %w", err)
+	}
+	return notes, nil
+}
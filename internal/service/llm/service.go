@@ -6,19 +6,85 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	clientllm "sayso-agent/internal/client/llm"
 	"sayso-agent/internal/model"
+	"sayso-agent/internal/panics"
+	"sayso-agent/internal/service/deadline"
+	"sayso-agent/internal/store"
+	"sayso-agent/internal/trace"
 )
 
 // Service 调用大模型并解析为结构化动作
 type Service struct {
-	client *clientllm.Client
+	client     *clientllm.Client
+	aliases    []string            // 可作为发送目标的联系人分组名
+	workflows  store.WorkflowStore // 可选，用户保存的工作流
+	skills     SkillPolicy         // 技能开关，规划时只提供可用的技能
+	plugins    PluginSkills        // 可选，外部插件提供的技能
+	scripts    ScriptSkills        // 可选，租户脚本提供的技能
+	fastPath   []SkillType         // 可走单次提取快速路径的技能，为空时不启用
+	heuristics bool                // 是否启用规则预解析，简单指令不调用大模型
+	shadow     *Shadow             // 可选，影子规划：候选模型/Prompt 与生产规划并行，只记录差异
+	panics     *panics.Reporter    // 可选，上报任务处理中恢复的 panic
+	degrade    *Degradation        // 可选，延迟降级：耗时持续超出目标时改用备用模型、复用缓存的计划
+	plans      *planCache          // 降级时复用的近期计划，未配置缓存时为 nil
 }
 
-// NewService 创建 LLM 服务
-func NewService(client *clientllm.Client) *Service {
-	return &Service{client: client}
+// PluginSkills 外部插件技能来源（由 plugin.Registry 实现），只返回当前健康的插件技能
+type PluginSkills interface {
+	Skills() []model.PluginSkill
+}
+
+// ScriptSkills 租户脚本技能来源（由 script.Engine 实现）
+type ScriptSkills interface {
+	Skills(tenant string) []model.PluginSkill
+}
+
+// Deps LLM 服务依赖；可选依赖为 nil 时对应功能不启用
+type Deps struct {
+	Client *clientllm.Client
+	// Aliases 配置中的联系人分组名，会告知大模型可直接作为发送目标
+	Aliases []string
+	// Workflows 工作流存储，输入命中触发词时直接使用保存的任务
+	Workflows store.WorkflowStore
+	// Skills 按环境/租户的技能开关
+	Skills SkillPolicy
+	// Plugins、Scripts 外部插件技能与租户脚本技能，与内置技能一起提供给规划器
+	Plugins PluginSkills
+	Scripts ScriptSkills
+	// FastPath 可走快速路径的内置技能：与规划并行做一次单次提取，计划只有一个任务时省去第二轮调用
+	FastPath []SkillType
+	// Heuristics 启用规则预解析，"发消息给X说Y"等简单指令完全命中时直接得到动作，不调用大模型
+	Heuristics bool
+	// Shadow 影子规划，按比例用候选模型/Prompt 规划同一输入并记录与生产计划的差异，影子计划不执行
+	Shadow *Shadow
+	// Panics 上报并行任务中恢复的 panic，为 nil 时只写日志
+	Panics *panics.Reporter
+	// Degrade 延迟降级：耗时持续超出目标时改用备用模型、复用缓存的计划
+	Degrade *Degradation
+}
+
+// NewService 按 deps 创建 LLM 服务
+func NewService(deps Deps) *Service {
+	s := &Service{
+		client:     deps.Client,
+		aliases:    deps.Aliases,
+		workflows:  deps.Workflows,
+		skills:     deps.Skills,
+		plugins:    deps.Plugins,
+		scripts:    deps.Scripts,
+		fastPath:   deps.FastPath,
+		heuristics: deps.Heuristics,
+		shadow:     deps.Shadow,
+		panics:     deps.Panics,
+		degrade:    deps.Degrade,
+	}
+	if deps.Degrade != nil {
+		s.plans = newPlanCache(deps.Degrade.PlanCacheTTL)
+	}
+	return s
 }
 
 // ================== 任务规划类型 ==================
@@ -27,18 +93,38 @@ func NewService(client *clientllm.Client) *Service {
 type SkillType string
 
 const (
-	SkillCreateDoc    SkillType = "create_doc"
-	SkillCreateFolder SkillType = "create_folder"
-	SkillSendMessage  SkillType = "send_message"
+	SkillCreateDoc     SkillType = "create_doc"
+	SkillBulkCreateDoc SkillType = "bulk_create_doc"
+	SkillCreateFolder  SkillType = "create_folder"
+	SkillFolderTree    SkillType = "create_folder_tree"
+	SkillSendMessage   SkillType = "send_message"
+	SkillMinutesNotes  SkillType = "summarize_minutes"
+	SkillReviewPerms   SkillType = "review_doc_permissions"
+	SkillTransferOwner SkillType = "transfer_owner"
+	SkillCommentDoc    SkillType = "comment_doc"
+	SkillExportDoc     SkillType = "export_doc"
+	SkillImportFile    SkillType = "import_file"
+	SkillQueryStatus   SkillType = "query_status"
+	SkillQueryTable    SkillType = "query_table"
+	SkillQueryApproval SkillType = "query_approval"
+	SkillQueryTasks    SkillType = "query_tasks"
+	SkillScheduleMeet  SkillType = "schedule_meeting"
+	SkillSetStatus     SkillType = "set_status"
+	SkillAddReaction   SkillType = "add_reaction"
+	SkillRenameFile    SkillType = "rename_file"
+	SkillSendSMS       SkillType = "send_sms"
+	SkillCreateTicket  SkillType = "create_ticket"
+	SkillAskKB         SkillType = "ask_kb"
 )
 
 // TaskSpec 单个任务规格
 type TaskSpec struct {
-	ID        string    `json:"id"`         // 任务ID（如 task_1）
-	Skill     SkillType `json:"skill"`      // 技能类型
-	Platform  string    `json:"platform"`   // 平台：feishu/slack
-	Input     string    `json:"input"`      // 该任务相关的输入描述
-	DependsOn []string  `json:"depends_on"` // 依赖的任务ID（需要等待的任务）
+	ID        string    `json:"id"`                // 任务ID（如 task_1）
+	Skill     SkillType `json:"skill"`             // 技能类型
+	Platform  string    `json:"platform"`          // 平台：feishu/slack/discord
+	Input     string    `json:"input"`             // 该任务相关的输入描述
+	DependsOn []string  `json:"depends_on"`        // 依赖的任务ID（需要等待的任务）
+	ForEach   *ForEach  `json:"foreach,omitempty"` // 可选：对列表中每一项分别执行
 }
 
 // TaskPlan 第一阶段任务规划结果
@@ -51,20 +137,22 @@ type TaskPlan struct {
 type TaskResult struct {
 	TaskID  string
 	Action  *model.ActionSpec
+	Items   []*model.ActionSpec // foreach 任务按项展开后的动作，与 Action 互斥
 	Error   error
 	Outputs map[string]string // 输出变量（如 doc_url, folder_url）
 }
 
 // ================== 第一阶段：任务规划 ==================
 
+// plannerPrompt 规划 Prompt；{{skill_names}} 与 {{skill_list}} 由 plannerPromptFor 按可用技能填充
 const plannerPrompt = `分析用户输入，识别所有要执行的任务，返回 JSON：
 {
   "summary": "整体意图摘要",
   "tasks": [
     {
       "id": "task_1",
-      "skill": "create_doc|create_folder|send_message",
-      "platform": "feishu|slack",
+      "skill": "{{skill_names}}",
+      "platform": "feishu|slack|discord",
       "input": "该任务相关的输入描述",
       "depends_on": []
     }
@@ -72,9 +160,11 @@ const plannerPrompt = `分析用户输入，识别所有要执行的任务，返
 }
 
 技能类型：
-- create_doc: 创建文档
-- create_folder: 创建文件夹
-- send_message: 发送消息
+{{skill_list}}
+
+会话上下文：
+- 输入可能以"最近对话"开头，只为"当前输入"规划任务，历史中已完成的任务不要重复
+- "他/她/这个文档/也发一份"等指代从最近对话中确定，并把具体的人名、链接写进 input（如 "把 https://xxx.feishu.cn/docx/xxx 发给张三"）
 
 平台识别：
 - feishu: 飞书、中文名字、ou_开头的ID、默认
@@ -90,6 +180,8 @@ const plannerPrompt = `分析用户输入，识别所有要执行的任务，返
 2. **引用前置任务结果**：
    - "把链接发给"、"发送链接"、"分享文档" → 依赖 create_doc
    - "发送文件夹链接" → 依赖 create_folder
+   - "把妙记整理成纪要发到群" → send_message 依赖 summarize_minutes（纪要文档链接同样是 {{doc_url}}）
+   - 把查询结果写进文档等其他用途 → 依赖 query_table（需要{{answer}}）
 
 3. **隐含依赖**：创建资源后发送给某人 = 先创建 + 再发送链接
    - "创建文档发给张三" = create_doc + send_message(depends_on create_doc)
@@ -111,23 +203,56 @@ const plannerPrompt = `分析用户输入，识别所有要执行的任务，返
   {"id":"task_2","skill":"send_message","platform":"feishu","input":"把文档链接发给张三（需要{{doc_url}}）","depends_on":["task_1"]}
 ]}
 
+引用前置任务输出：每个任务的输出可用 {{task_N.key}} 引用，如 {{task_1.doc_url}}、{{task_2.folder_url}}、{{task_1.message_id}}；同类资源有多个时必须用这种写法指明来源任务。
+
 示例4 - "创建会议纪要然后发给ou_xxx"（有依赖）：
 {"summary":"创建文档并分享","tasks":[
   {"id":"task_1","skill":"create_doc","platform":"feishu","input":"创建会议纪要","depends_on":[]},
   {"id":"task_2","skill":"send_message","platform":"feishu","input":"把文档链接发给ou_xxx（需要{{doc_url}}）","depends_on":["task_1"]}
 ]}
 
+## 循环任务
+
+对多个对象分别执行同一操作时（"给每个联系人..."、"分别给A、B、C..."），为任务加 foreach，input 中用 {{item}} 表示当前对象：
+- {"source":"contacts"}：遍历请求附带的联系人
+- {"source":"items","items":["A","B"]}：遍历用户列出的对象
+两个 foreach 任务遍历同一列表且有依赖时，第 N 项只引用前置任务第 N 项的结果。
+
+示例5 - "给每个联系人创建一份个性化文档并发给他们"：
+{"summary":"为联系人分别创建并分享文档","tasks":[
+  {"id":"task_1","skill":"create_doc","platform":"feishu","input":"为{{item}}创建个性化文档","depends_on":[],"foreach":{"source":"contacts"}},
+  {"id":"task_2","skill":"send_message","platform":"feishu","input":"把文档链接发给{{item}}（需要{{doc_url}}）","depends_on":["task_1"],"foreach":{"source":"contacts"}}
+]}
+
 只返回 JSON。`
 
 // ================== 第二阶段：各技能专用 Prompt ==================
 
 var skillPrompts = map[SkillType]string{
 	SkillCreateDoc: `提取创建文档参数，返回 JSON：
-{"type":"feishu_create_doc","params":{"title":"标题","content":"内容","folder_name":"目录","collaborators":[{"member_id":"用户名","perm":"edit"}]}}
+{"type":"feishu_create_doc","params":{"title":"标题","content":"内容","folder_name":"目录","collaborators":[{"member_id":"用户名","perm":"edit"}],"on_duplicate":"","draft":{"brief":"","tone":"","length":""}}}
 
 规则：
-- title 必填，如果用户说"今天的日期"则使用实际日期格式如"2024-01-15"
+- title: 用户说了标题就用用户的，如果用户说"今天的日期"则使用实际日期格式如"2024-01-15"；没说标题时留空，由系统根据内容生成
 - perm: full_access(默认)/edit/view
+- on_duplicate: 目录中已有同名文档时的处理。"追加到原来那份/写到已有文档里" → append，"用已有的那份" → reuse，"再新建一份" → new，没说则留空
+- draft: 用户要求撰写正文（"写一份/起草/帮我写"，如"写一篇正式一点的活动复盘"）而没有给出原文时填写，content 留空，由系统单独撰写：
+  - brief: 撰写要求，保留用户给出的对象、事由、要点等全部事实
+  - tone: 语气，formal(正式)/casual(随意)/friendly(亲切)/apologetic(致歉)/concise(简洁)，没说留空
+  - length: 篇幅，short/medium/long，"简短/一两句"为 short，"详细"为 long，没说留空
+  用户给出了原文时不填 draft
+
+只返回 JSON。`,
+
+	SkillBulkCreateDoc: `提取批量创建文档参数，返回 JSON：
+{"type":"feishu_bulk_create_doc","params":{"source":"items","items":["A","B"],"table":"","column":"","title":"{{item}}立项文档","template":"","content":"","folder_name":""}}
+
+规则：
+- source: 用户列出了各项 → items，并把各项写入 items；"给每个联系人" → contacts；"表格里的每个项目" → table，table 为表格名称或链接，column 为列名（未说留空）
+- title: 每份文档的标题，用 {{item}} 表示当前项，如 "{{item}}立项文档"
+- template: 用户说"按某某模板/照着某文档"时为模板文档的链接或名称，否则留空
+- content: 没有模板时的正文，可含 {{item}}；未提及留空
+- folder_name: 存放目录，未提及留空
 
 只返回 JSON。`,
 
@@ -138,15 +263,209 @@ var skillPrompts = map[SkillType]string{
 - name 必填
 - folder_name 可选
 
+只返回 JSON。`,
+
+	SkillFolderTree: `提取创建目录结构参数，返回 JSON：
+{"type":"feishu_create_folder_tree","params":{"root":"顶层目录名","folder_name":"上级目录","template":"","folders":["需求","设计/原型"]}}
+
+规则：
+- root: 用户说了项目名或顶层目录名时填写（如 "给凤凰项目建标准目录" → "凤凰项目"），否则留空，目录直接建在上级目录下
+- template: 用户说"标准目录/按某某模板"时填写模板名（只说"标准目录"时留空并不填 folders，由系统使用配置的模板）
+- folders: 用户列出了目录时原样使用（"需求/设计/会议纪要/发布" 是四个同级目录）；用户只说了项目类型时按常见做法给出 4-8 个目录，下级目录用 / 表示，如 "设计/原型"，最多三层
+- folder_name: 上级目录，未提及留空
+
+只返回 JSON。`,
+
+	SkillRenameFile: `提取文档改名参数，返回 JSON：
+{"type":"feishu_rename_file","params":{"doc_url":"文档链接","doc_name":"","title":"新名称"}}
+
+规则：
+- doc_url: 输入中的文档链接，或前置任务的 {{doc_url}}；"刚才那个文档"取最近对话中创建的文档链接
+- doc_name: 没有链接时为文档原名称，系统按名称查找
+- title 必填：新名称，去掉书名号
+
+只返回 JSON。`,
+
+	SkillAskKB: `提取知识库问答参数，返回 JSON：
+{"type":"ask_kb","params":{"question":"问题","platform":"feishu|slack|discord","target_type":"user|chat|batch","targets":[]}}
+
+规则：
+- question: 用户要问的问题，补全为完整的一句话（如"公司的差旅报销标准是多少"），保留关键限定（城市、职级、时间）
+- targets: 用户要求把答案发给别人或发到群时填写接收方（名字、ID 或联系人分组），否则留空，答案直接回复给用户
+- platform、target_type: 与发送消息相同，只在有 targets 时有意义
+
+只返回 JSON。`,
+
+	SkillCreateTicket: `提取 IT 工单参数，返回 JSON：
+{"type":"feishu_create_ticket","params":{"title":"问题概述","description":"问题描述","category":"分类","screenshots":[]}}
+
+规则：
+- title: 一句话概述问题，如"会议室投影仪无法连接"
+- description: 用户描述的现象、发生时间、地点、已尝试的操作等，整理成通顺的几句话，不要编造细节
+- category: 问题分类，从 网络、账号与权限、电脑与硬件、软件与系统、打印机、会议室设备、邮箱、其他 中选一个最贴切的
+- screenshots: 用户指明的截图附件名或序号；未指明时留空（自动附上全部图片附件）
+
+只返回 JSON。`,
+
+	SkillMinutesNotes: `提取妙记整理参数，返回 JSON：
+{"type":"feishu_minutes_notes","params":{"minute_url":"妙记链接","title":"纪要标题","folder_name":"目录"}}
+
+规则：
+- minute_url 必填，原样使用输入中的妙记链接（形如 https://xxx.feishu.cn/minutes/obcnxxxx）
+- title、folder_name 可选，未提及则留空
+
+只返回 JSON。`,
+
+	SkillReviewPerms: `提取文档权限审查参数，返回 JSON：
+{"type":"review_doc_permissions","params":{"doc_url":"文档链接","close_external":false,"link_share":"","remove_members":[]}}
+
+规则：
+- doc_url 必填：输入中的文档链接，或前置任务的 {{doc_url}}
+- close_external: 用户要求"关掉外部访问/不让外部看"时为 true
+- link_share: 用户要求关闭链接分享时为 "closed"，改为仅组织内可看时为 "tenant_readable"，未提及留空；只能收紧，不能放宽
+- remove_members: 用户要求移除的协作者名字或 ID
+- 只查看权限时其余参数保持默认
+
+只返回 JSON。`,
+
+	SkillTransferOwner: `提取转移文档所有者参数，返回 JSON：
+{"type":"feishu_transfer_owner","params":{"doc_url":"文档链接","new_owner":"新所有者","remove_old_owner":false}}
+
+规则：
+- doc_url 必填：输入中的文档链接，或前置任务的 {{doc_url}}
+- new_owner 必填：新所有者的名字、ou_ 开头的 open_id 或邮箱
+- remove_old_owner: 用户明确要求"原所有者不再保留权限"时为 true
+
+只返回 JSON。`,
+
+	SkillCommentDoc: `提取文档评论参数，返回 JSON：
+{"type":"feishu_comment_doc","params":{"doc_url":"文档链接","doc_name":"文档名称","content":"评论内容","quote":"针对的原文"}}
+
+规则：
+- doc_url、doc_name 二选一：有链接或前置任务的 {{doc_url}} 时用 doc_url，只说了名称（如"周报"）时用 doc_name
+- content 必填：评论内容，去掉引号
+- quote: 用户指明针对文中哪句话/哪段内容时填写，否则留空
+
+只返回 JSON。`,
+
+	SkillExportDoc: `提取文档导出参数，返回 JSON：
+{"type":"export_doc","params":{"doc_url":"文档链接","doc_name":"文档名称","format":"pdf|docx|xlsx|csv","platform":"feishu|slack|email","targets":["接收人"]}}
+
+规则：
+- doc_url、doc_name 二选一：有链接或前置任务的 {{doc_url}} 时用 doc_url，只说了名称时用 doc_name
+- format: PDF 为 pdf，Word 为 docx，Excel 为 xlsx，未提及留空
+- targets: 文件接收人或群（名字、ID、#频道），"发给我"或未提及时留空（发给请求人）
+- platform: 发到 Slack 时为 slack，要求发到邮箱时为 email（暂不支持，执行时会提示），否则 feishu
+
+只返回 JSON。`,
+
+	SkillImportFile: `提取文件导入参数，返回 JSON：
+{"type":"import_file","params":{"source":"链接或附件名","file_name":"","folder_name":"目录","import_as":""}}
+
+规则：
+- source: 输入中的文件链接原样使用；引用附件时填附件名或序号，只有一个附件时可留空
+- file_name: 用户指定了保存名称时填写，否则留空
+- folder_name: 存放目录（如"项目目录"），未提及留空
+- import_as: 用户要求"作为原文件保存/不要转换"时为 "file"，要求转为多维表格时为 "bitable"，否则留空
+
+只返回 JSON。`,
+
+	SkillQueryStatus: `提取任务状态查询参数，返回 JSON：
+{"type":"query_status","params":{"keyword":"","period":"recent|today|morning|afternoon|yesterday","status":"","limit":3}}
+
+规则：
+- keyword: 用于匹配任务的关键词，取用户提到的人名、文档名等（如"发给张三的消息"取 "张三"），没有则留空
+- period: "上午"为 morning，"下午"为 afternoon，"今天"为 today，"昨天"为 yesterday，否则 recent
+- status: 只问失败的任务时为 "failed"，否则留空
+- limit: 问"刚才那个"时为 1，否则 3
+
+只返回 JSON。`,
+
+	SkillQueryTable: `提取表格查询参数，返回 JSON：
+{"type":"feishu_query_table","params":{"table":"表名或表格链接","question":"要回答的问题","platform":"feishu|slack","targets":[]}}
+
+规则：
+- table: 用户提到的表格名称（如"销售台账"）或输入中的表格链接原样使用；没有提到表格时留空
+- question: 完整的问题，保留时间范围与统计口径（如"上周的销售总额是多少"），去掉"发到群里"等发送要求
+- targets: 结果要发给的人或群（名字、ID、#频道），只是问给自己听时留空
+- platform: 发到 Slack 时为 slack，否则 feishu
+
+只返回 JSON。`,
+
+	SkillQueryApproval: `提取审批查询参数，返回 JSON：
+{"type":"feishu_query_approval","params":{"keyword":"","pending_only":false,"limit":3}}
+
+规则：
+- keyword: 审批类型关键词，如"报销""请假""采购"，没有提到则留空
+- pending_only: 只问"还没批完的/在审批中的"时为 true
+- limit: 问"那个/刚提交的"时为 1，否则 3
+
+只返回 JSON。`,
+
+	SkillQueryTasks: `提取待办查询参数，返回 JSON：
+{"type":"feishu_query_tasks","params":{"period":"today|week|overdue|all","keyword":""}}
+
+规则：
+- period: "今天"为 today（含已逾期），"这周/本周"为 week，"逾期/过期"为 overdue，否则 all
+- keyword: 只问某类待办时填写关键词（如"周报"），否则留空
+
+只返回 JSON。`,
+
+	SkillScheduleMeet: `提取约会议参数，返回 JSON：
+{"type":"feishu_schedule_meeting","params":{"title":"会议主题","attendees":["参与人"],"duration_minutes":30,"start_time":"","date_from":"","date_to":"","description":""}}
+
+规则：
+- attendees: 除用户本人外的参与人名字、ou_ 开头的 open_id（用户本人会自动加入）
+- duration_minutes: "一小时"为 60，未提及为 30
+- start_time: 用户指定了具体时间时按下方当前时间换算为 "2024-05-21 15:00"，否则留空（由系统找空闲时间）
+- date_from、date_to: 只给了日期范围时填写（如"明天"两者都为明天，"这周"为今天到本周五），格式 "2024-05-21"，未提及留空
+- title: 会议主题，未提及时用"会议"
+
+只返回 JSON。`,
+
+	SkillSendSMS: `提取短信参数，返回 JSON：
+{"type":"send_sms","params":{"channel":"sms|whatsapp","targets":["接收人"],"text":"短信内容"}}
+
+规则：
+- channel: 用户提到 WhatsApp 时为 whatsapp，否则 sms
+- targets: 使用用户说的名字（如"老王"），或用户明确说出的手机号；不要猜测或编造号码
+- text: 短信正文，简洁完整，可直接发给对方；用户只说了意图（"确认到货"）时写成一句礼貌的话，不加称呼以外的客套
+
+只返回 JSON。`,
+
+	SkillSetStatus: `提取个人状态参数，返回 JSON：
+{"type":"set_status","params":{"platform":"feishu|slack","status":"请假","until":"","clear":false}}
+
+规则：
+- status: 状态名称，如"请假""出差""会议中""专注中"
+- until: 状态结束时间，按下方当前时间换算为 "2024-05-21 18:00"（"下午请假"到当天 18:00，"明天请假"到明天 23:59），未提及留空（当天结束）
+- clear: 用户要求取消/恢复状态时为 true
+- platform: 提到 Slack 时为 slack，否则 feishu
+
+只返回 JSON。`,
+
+	SkillAddReaction: `提取表情回应参数，返回 JSON：
+{"type":"add_reaction","params":{"platform":"feishu|slack","message_id":"消息ID","chat_id":"会话ID","emoji":"👍"}}
+
+规则：
+- message_id、chat_id: 原样使用输入中的消息 ID 与会话 ID（飞书 om_ 开头，Slack 为 1716280000.123456 形式的 ts），或占位符 {{message_id}} {{chat_id}}
+- emoji: 用户要求的表情，"点个赞"为 👍，"回个收到"为 ✅，未提及为 👍
+- platform: 消息所在平台
+
 只返回 JSON。`,
 
 	SkillSendMessage: `提取发送消息参数，返回 JSON：
-{"type":"send_message","params":{"platform":"feishu|slack","message_type":"text|link_card","content":{"text":"消息","url":"链接"},"target_type":"user|chat|batch","targets":["目标"]}}
+{"type":"send_message","params":{"platform":"feishu|slack|discord","message_type":"text|link_card|voice","content":{"text":"消息","url":"链接"},"target_type":"user|chat|batch","targets":["目标"]}}
 
 规则：
-- platform: feishu(默认)/slack
+- platform: feishu(默认)/slack/discord
 - target_type: user(单人)/chat(群)/batch(多人)
+- message_type: 用户要求"发语音""用语音说"时为 voice，content.text 为要朗读的内容（口语化，不含链接）
 - targets: 直接使用用户提供的ID（如ou_xxx）或用户名
+- Slack 有多个工作区时，用户指明工作区的目标写为 "目标@工作区"，如"EMEA 工作区的 #general" → "#general@emea"
+- Discord 频道写为 "#频道名"，用户指明服务器时写为 "#频道名@服务器名"，如"Gopher 社区的 #announcements" → "#announcements@Gopher 社区"
+- 用户明确要求"不要链接预览"时加 "unfurl": false，要求"展开预览"时加 "unfurl": true（Slack、Discord）
+- 用户要求撰写内容（如"写一封正式一点的道歉邮件给客户""帮我起草一段通知发到群里"）而没有给出原话时，加 "draft":{"brief":"撰写要求，保留事由与要点","tone":"formal|casual|friendly|apologetic|concise","length":"short|medium|long"}，content.text 留空，由系统单独撰写；tone、length 没说则留空，用户给出了原话时不加 draft
 
 占位符使用（重要）：
 - 如果任务描述中包含"需要{{doc_url}}"，则：
@@ -154,6 +473,8 @@ var skillPrompts = map[SkillType]string{
   - content.url 设为 "{{doc_url}}"
   - content.text 设为 "请查看文档"
 - 如果包含"需要{{folder_url}}"，则 content.url 设为 "{{folder_url}}"
+- 如果包含"需要{{answer}}"（表格查询结果），则 message_type 为 "text"，content.text 设为 "{{answer}}"
+- 如果包含"需要{{task_N.xxx}}"（指定某个任务的输出），原样使用该占位符
 
 只返回 JSON。`,
 }
@@ -161,11 +482,33 @@ var skillPrompts = map[SkillType]string{
 // ================== 主处理流程 ==================
 
 // Process 两阶段处理：规划 → 并行执行
-func (s *Service) Process(ctx context.Context, userText string) (*model.LLMActionOutput, error) {
+// ctx 中带有请求时限（deadline.Start）时，规划与参数提取各自只能使用分配到的时间
+func (s *Service) Process(ctx context.Context, req model.ASRRequest) (*model.LLMActionOutput, error) {
+	// 简单指令规则预解析命中时不调用大模型
+	if out := s.heuristicParse(ctx, req); out != nil {
+		return out, nil
+	}
+
+	// 耗时持续超出延迟目标时降级：换用备用模型，规划时优先复用缓存的计划
+	degraded := s.degrading()
+	var fallback bool
+	if degraded {
+		ctx, fallback = s.withFallbackModel(ctx)
+	}
+
+	// 简单指令的单次提取与规划并行，不受规划阶段时限约束（等待时计入参数提取阶段）
+	fastCtx, cancelFast := context.WithCancel(ctx)
+	defer cancelFast()
+	fast := s.startFastPath(fastCtx, req)
+
 	// 第一阶段：任务规划
-	plan, err := s.planTasks(ctx, userText)
+	planCtx, cancel := deadline.Enter(ctx, deadline.Planning)
+	defer cancel()
+	endPlan := trace.Start(ctx, "llm.planning")
+	wf, plan, cached, err := s.plan(planCtx, req, degraded)
+	endPlan(err)
 	if err != nil {
-		return nil, fmt.Errorf("plan tasks: %w", err)
+		return nil, deadline.Check(planCtx, err)
 	}
 	if len(plan.Tasks) == 0 {
 		return &model.LLMActionOutput{
@@ -173,33 +516,115 @@ func (s *Service) Process(ctx context.Context, userText string) (*model.LLMActio
 			Reply:  "抱歉，我不太理解您的意思。您可以尝试：创建文档、创建文件夹、发送消息。",
 		}, nil
 	}
+	if disabled := s.disabledSkills(req.Tenant(), plan.Tasks); len(disabled) > 0 {
+		return &model.LLMActionOutput{
+			Intent: plan.Summary,
+			Reply:  "当前未开放以下功能：" + strings.Join(disabled, "、") + "，请联系管理员启用。",
+		}, nil
+	}
 
 	// 第二阶段：按依赖关系执行任务
-	results, err := s.executeTasks(ctx, plan.Tasks)
-	if err != nil {
-		return nil, err
+	extractCtx, cancel := deadline.Enter(ctx, deadline.Extraction)
+	defer cancel()
+	if wf != nil {
+		fast = nil
 	}
+	endExtract := trace.Start(ctx, "llm.extraction")
+	results, ok := s.useFastPath(extractCtx, fast, plan)
+	if !ok {
+		cancelFast()
+		if results, err = s.executeTasks(extractCtx, plan.Tasks, req); err != nil {
+			endExtract(err)
+			return nil, deadline.Check(extractCtx, err)
+		}
+	}
+	// 需要撰写正文的动作单独调用生成 Prompt，计入参数提取阶段的时限
+	if err := s.draftActions(extractCtx, results); err != nil {
+		endExtract(err)
+		return nil, deadline.Check(extractCtx, err)
+	}
+	endExtract(nil)
 
 	// 汇总结果
-	return s.buildOutput(plan, results), nil
+	out := s.buildOutput(plan, results)
+	if wf != nil {
+		out.Workflow = wf.Name
+	}
+	out.Degraded = fallback || cached
+	return out, nil
 }
 
-// planTasks 第一阶段：任务规划
-func (s *Service) planTasks(ctx context.Context, userText string) (*TaskPlan, error) {
-	raw, err := s.client.Chat(ctx, plannerPrompt, userText)
+// plan 第一阶段：识别图片附件后规划任务，命中已保存的工作流时直接使用其任务；
+// degraded 时相同输入优先使用缓存的计划，cached 表示用了缓存
+func (s *Service) plan(ctx context.Context, req model.ASRRequest, degraded bool) (wf *model.Workflow, plan *TaskPlan, cached bool, err error) {
+	// 图片附件（白板照片、截图等）先识别为文本
+	req, err = s.withImageText(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, nil, false, err
 	}
-	raw = ExtractJSON(raw)
-	var plan TaskPlan
-	if err := json.Unmarshal([]byte(raw), &plan); err != nil {
-		return nil, fmt.Errorf("parse plan: %w", err)
+	wf, err = s.matchWorkflow(ctx, req)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("match workflow: %w", err)
+	}
+	input := plannerInput(req)
+	if wf != nil {
+		var vars map[string]string
+		if vars, err = s.extractWorkflowVars(ctx, wf, req.Text); err == nil {
+			plan = planFromWorkflow(wf, vars)
+		}
+	} else if plan = s.cachedPlan(req.Tenant(), input, degraded); plan != nil {
+		cached = true
+	} else if plan, err = s.planTasks(ctx, req.Tenant(), input); err == nil {
+		s.plans.put(req.Tenant(), input, plan)
+		s.runShadow(ctx, req, plan)
+	}
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("plan tasks: %w", err)
+	}
+	return wf, plan, cached, nil
+}
+
+// cachedPlan 降级时取缓存的计划，未降级或未命中时返回 nil
+func (s *Service) cachedPlan(tenant, input string, degraded bool) *TaskPlan {
+	if !degraded {
+		return nil
+	}
+	return s.plans.get(tenant, input)
+}
+
+// plannerInput 规划输入：用户文本，附上最近的会话交互（用于理解指代）与附件列表
+func plannerInput(req model.ASRRequest) string {
+	if len(req.History) == 0 && len(req.Attachments) == 0 {
+		return req.Text
+	}
+	var b strings.Builder
+	if len(req.History) > 0 {
+		b.WriteString("最近对话（从早到晚）：")
+		for _, ex := range req.History {
+			fmt.Fprintf(&b, "\n用户：%s\n助手：%s", ex.UserText, ex.Reply)
+			for _, r := range ex.Resources {
+				fmt.Fprintf(&b, "\n  - %s", r)
+			}
+		}
+		b.WriteString("\n\n当前输入：")
+	}
+	b.WriteString(req.Text)
+	if len(req.Attachments) > 0 {
+		b.WriteString("\n\n附件：")
+		for i, a := range req.Attachments {
+			fmt.Fprintf(&b, "\n%d. %s", i+1, a.Name)
+		}
 	}
-	return &plan, nil
+	return b.String()
+}
+
+// planTasks 第一阶段：任务规划，Prompt 只列出租户可用的技能
+func (s *Service) planTasks(ctx context.Context, tenant, userText string) (*TaskPlan, error) {
+	return planWith(ctx, s.client, s.plannerPromptFor(tenant), userText)
 }
 
 // executeTasks 按依赖关系执行任务（无依赖的并行，有依赖的等待）
-func (s *Service) executeTasks(ctx context.Context, tasks []TaskSpec) (map[string]*TaskResult, error) {
+func (s *Service) executeTasks(ctx context.Context, tasks []TaskSpec, req model.ASRRequest) (map[string]*TaskResult, error) {
 	results := make(map[string]*TaskResult)
 	var mu sync.Mutex
 
@@ -211,6 +636,10 @@ func (s *Service) executeTasks(ctx context.Context, tasks []TaskSpec) (map[strin
 
 	// 循环执行直到所有任务完成
 	for len(pending) > 0 {
+		// 客户端断开或超出时限时不再提取后续任务的参数
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
 		// 找出可执行的任务（依赖已完成）
 		var ready []*TaskSpec
 		for _, task := range pending {
@@ -230,7 +659,7 @@ func (s *Service) executeTasks(ctx context.Context, tasks []TaskSpec) (map[strin
 			wg.Add(1)
 			go func(t *TaskSpec) {
 				defer wg.Done()
-				result := s.executeTask(ctx, t, results)
+				result := s.runTask(ctx, t, tasks, results, req)
 				mu.Lock()
 				results[t.ID] = result
 				delete(pending, t.ID)
@@ -250,6 +679,30 @@ func (s *Service) executeTasks(ctx context.Context, tasks []TaskSpec) (map[strin
 	return results, nil
 }
 
+// runTask 执行一个就绪任务（循环任务按项展开）；处理中的 panic 记为该任务失败
+func (s *Service) runTask(ctx context.Context, task *TaskSpec, tasks []TaskSpec, depResults map[string]*TaskResult, req model.ASRRequest) (result *TaskResult) {
+	defer s.recoverTask(ctx, task, &result)
+	if task.ForEach != nil {
+		return s.executeForEach(ctx, task, tasks, depResults, req)
+	}
+	return s.executeTask(ctx, task, depResults, req.Tenant())
+}
+
+// recoverTask 恢复任务 goroutine 中的 panic：上报调用栈并把 *result 替换为失败结果，须直接 defer 调用。
+// 并行任务不在 gin 的 Recovery 之内，未恢复的 panic 会使整个进程退出
+func (s *Service) recoverTask(ctx context.Context, task *TaskSpec, result **TaskResult) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	s.panics.Capture(ctx, fmt.Sprintf("llm task %s (%s)", task.ID, task.Skill), v)
+	*result = &TaskResult{
+		TaskID:  task.ID,
+		Outputs: make(map[string]string),
+		Error:   fmt.Errorf("%w: %v", model.ErrTaskPanic, v),
+	}
+}
+
 // canExecute 检查任务是否可执行（所有依赖已成功完成）
 func (s *Service) canExecute(task *TaskSpec, results map[string]*TaskResult) bool {
 	for _, depID := range task.DependsOn {
@@ -262,7 +715,7 @@ func (s *Service) canExecute(task *TaskSpec, results map[string]*TaskResult) boo
 }
 
 // executeTask 执行单个任务
-func (s *Service) executeTask(ctx context.Context, task *TaskSpec, depResults map[string]*TaskResult) *TaskResult {
+func (s *Service) executeTask(ctx context.Context, task *TaskSpec, depResults map[string]*TaskResult, tenant string) *TaskResult {
 	result := &TaskResult{
 		TaskID:  task.ID,
 		Outputs: make(map[string]string),
@@ -270,10 +723,21 @@ func (s *Service) executeTask(ctx context.Context, task *TaskSpec, depResults ma
 
 	// 获取技能对应的 prompt
 	prompt, ok := skillPrompts[task.Skill]
+	var plugin model.PluginSkill
+	var isPlugin, isScript bool
+	if !ok {
+		// 不是内置技能时依次查找外部插件技能、租户脚本技能
+		if plugin, isPlugin = s.pluginSkill(task.Skill); isPlugin {
+			prompt, ok = pluginSkillPrompt(plugin, model.ActionTypePluginPrefix), true
+		} else if plugin, isScript = s.scriptSkill(tenant, task.Skill); isScript {
+			prompt, ok = pluginSkillPrompt(plugin, model.ActionTypeScriptPrefix), true
+		}
+	}
 	if !ok {
 		result.Error = fmt.Errorf("未知技能: %s", task.Skill)
 		return result
 	}
+	prompt += s.skillPromptExtras(task.Skill)
 
 	// 替换输入中的占位符（引用依赖任务的输出）
 	input := s.resolvePlaceholders(task.Input, depResults)
@@ -292,15 +756,39 @@ func (s *Service) executeTask(ctx context.Context, task *TaskSpec, depResults ma
 		return result
 	}
 
-	// 补充平台信息（send_message 需要）
+	// 插件与脚本技能的动作类型固定，不依赖大模型输出
+	if isPlugin {
+		action.Type = model.ActionTypePluginPrefix + plugin.Name
+	}
+	if isScript {
+		action.Type = model.ActionTypeScriptPrefix + plugin.Name
+	}
+
+	fillPlatform(&action, task)
+
+	result.Action = &action
+	return result
+}
+
+// skillPromptExtras 内置技能 Prompt 的运行时补充：send_message 附上联系人分组，约会议、设状态附上当前时间
+func (s *Service) skillPromptExtras(skill SkillType) string {
+	switch {
+	case skill == SkillSendMessage && len(s.aliases) > 0:
+		return "\n\n可用联系人分组（用户提到时原样放入 targets，target_type 设为 batch；用户要求排除部分成员时，" +
+			"如\"发给产品部除了实习生\"，加 \"exclude\":[\"实习生\"]，写用户说出的人员类型、职位或人名）：" + strings.Join(s.aliases, "、")
+	case skill == SkillScheduleMeet || skill == SkillSetStatus:
+		return "\n\n当前时间：" + time.Now().Format("2006-01-02 15:04 Monday")
+	}
+	return ""
+}
+
+// fillPlatform 补充平台信息（send_message 需要）
+func fillPlatform(action *model.ActionSpec, task *TaskSpec) {
 	if task.Skill == SkillSendMessage && action.Params != nil {
 		if _, ok := action.Params["platform"]; !ok {
 			action.Params["platform"] = task.Platform
 		}
 	}
-
-	result.Action = &action
-	return result
 }
 
 // resolvePlaceholders 替换占位符为依赖任务的输出
@@ -324,8 +812,20 @@ func (s *Service) buildOutput(plan *TaskPlan, results map[string]*TaskResult) *m
 
 	// 按原始顺序收集 actions
 	for _, task := range plan.Tasks {
-		if result, ok := results[task.ID]; ok && result.Action != nil {
-			out.Actions = append(out.Actions, *result.Action)
+		result, ok := results[task.ID]
+		if !ok {
+			continue
+		}
+		if result.Action != nil {
+			action := *result.Action
+			action.TaskID = task.ID
+			out.Actions = append(out.Actions, action)
+		}
+		for i, item := range result.Items {
+			action := *item
+			action.TaskID = foreachItemID(task.ID, i)
+			action.Group = task.ID
+			out.Actions = append(out.Actions, action)
 		}
 	}
 
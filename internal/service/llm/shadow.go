@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	clientllm "sayso-agent/internal/client/llm"
+	"sayso-agent/internal/model"
+)
+
+// 影子规划：候选模型或候选规划 Prompt 在真实流量上与生产规划并行运行，只记录两份计划的差异，
+// 影子计划不执行，用于在真实的输入分布上评估 Prompt 与模型升级
+
+// Shadow 影子规划配置
+type Shadow struct {
+	// Client 候选模型，为 nil 时使用生产模型（只比较 Prompt）
+	Client *clientllm.Client
+	// Prompt 候选规划 Prompt 模板，与生产模板一样包含 {{skill_names}}、{{skill_list}}；为空时使用生产模板（只比较模型）
+	Prompt string
+	// Percent 参与影子规划的请求比例（0~100）
+	Percent int
+	// Timeout 单次影子规划的超时，不占用请求时限
+	Timeout time.Duration
+}
+
+// shadowRecord 一次影子规划的记录，按行以 JSON 写入日志，便于离线统计
+type shadowRecord struct {
+	Tenant    string   `json:"tenant"`
+	Text      string   `json:"text"`
+	Match     bool     `json:"match"`
+	Diffs     []string `json:"diffs,omitempty"`
+	Prod      []string `json:"prod"`
+	Candidate []string `json:"candidate,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	LatencyMS int64    `json:"latency_ms"`
+}
+
+// runShadow 按比例在后台用候选模型/Prompt 规划同一输入，并记录与生产计划的差异；不阻塞、不影响生产请求
+func (s *Service) runShadow(ctx context.Context, req model.ASRRequest, prod *TaskPlan) {
+	sh := s.shadow
+	if sh == nil || sh.Percent <= 0 || rand.Intn(100) >= sh.Percent {
+		return
+	}
+	tenant, input := req.Tenant(), plannerInput(req)
+	prompt := s.plannerPromptFor(tenant)
+	if sh.Prompt != "" {
+		prompt = s.fillPlannerPrompt(sh.Prompt, tenant)
+	}
+	client := sh.Client
+	if client == nil {
+		client = s.client
+	}
+	go func() {
+		// 请求级的参数覆盖不作用于影子规划，候选模型按自身配置运行
+		ctx, cancel := context.WithTimeout(clientllm.WithOptions(context.WithoutCancel(ctx), clientllm.Options{}), sh.Timeout)
+		defer cancel()
+		start := time.Now()
+		rec := shadowRecord{Tenant: tenant, Text: req.Text, Prod: planSignature(prod)}
+		cand, err := planWith(ctx, client, prompt, input)
+		rec.LatencyMS = time.Since(start).Milliseconds()
+		if err != nil {
+			rec.Error = err.Error()
+		} else {
+			rec.Candidate = planSignature(cand)
+			rec.Diffs = diffPlans(rec.Prod, rec.Candidate)
+			rec.Match = len(rec.Diffs) == 0
+		}
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		_ = enc.Encode(rec)
+		log.Printf("shadow plan: %s", strings.TrimSpace(b.String()))
+	}()
+}
+
+// planWith 用指定的模型与 Prompt 规划任务
+func planWith(ctx context.Context, client *clientllm.Client, prompt, input string) (*TaskPlan, error) {
+	raw, err := client.Chat(ctx, prompt, input)
+	if err != nil {
+		return nil, err
+	}
+	var plan TaskPlan
+	if err := json.Unmarshal([]byte(ExtractJSON(raw)), &plan); err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// planSignature 计划的结构摘要：每个任务为 "技能@平台"，依赖写为任务序号，循环任务加 "foreach"；
+// 忽略任务 ID 与 input 的措辞差异
+func planSignature(plan *TaskPlan) []string {
+	index := make(map[string]int, len(plan.Tasks))
+	for i, t := range plan.Tasks {
+		index[t.ID] = i + 1
+	}
+	sigs := make([]string, 0, len(plan.Tasks))
+	for _, t := range plan.Tasks {
+		sig := string(t.Skill) + "@" + t.Platform
+		if len(t.DependsOn) > 0 {
+			deps := make([]int, 0, len(t.DependsOn))
+			for _, d := range t.DependsOn {
+				deps = append(deps, index[d])
+			}
+			slices.Sort(deps)
+			sig += fmt.Sprintf(" after%v", deps)
+		}
+		if t.ForEach != nil {
+			sig += " foreach"
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs
+}
+
+// diffPlans 逐个任务比较两份计划的结构摘要
+func diffPlans(prod, cand []string) []string {
+	var diffs []string
+	if len(prod) != len(cand) {
+		diffs = append(diffs, fmt.Sprintf("task count %d -> %d", len(prod), len(cand)))
+	}
+	for i := 0; i < max(len(prod), len(cand)); i++ {
+		var p, c string
+		if i < len(prod) {
+			p = prod[i]
+		}
+		if i < len(cand) {
+			c = cand[i]
+		}
+		if p != c {
+			diffs = append(diffs, fmt.Sprintf("task %d: %s -> %s", i+1, orNone(p), orNone(c)))
+		}
+	}
+	return diffs
+}
+
+func orNone(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "(none)"
+	}
+	return s
+}
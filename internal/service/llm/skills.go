@@ -0,0 +1,181 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// skillCatalog 规划器可选的技能及说明，按此顺序写入规划 Prompt
+var skillCatalog = []struct {
+	Skill SkillType
+	Desc  string
+}{
+	{SkillCreateDoc, "创建文档"},
+	{SkillBulkCreateDoc, `按列表批量创建文档（"为每个子项目建一个立项文档"），列表可来自用户所说、表格的某一列或联系人，可按模板文档生成；文档链接汇总为 {{doc_urls}}、标题与链接表为 {{table}}。只建文档时用它，不要用 foreach`},
+	{SkillCreateFolder, "创建文件夹"},
+	{SkillFolderTree, `一次创建多层目录结构（"给新项目建标准目录：需求/设计/会议纪要/发布"），可使用配置的目录模板；顶层目录链接为 {{folder_url}}`},
+	{SkillSendMessage, "发送消息"},
+	{SkillSendSMS, `给不在飞书/Slack 等聊天平台上的人（供应商、客户）发短信或 WhatsApp 消息（"给供应商老王发个短信确认到货"），发送前会请用户确认`},
+	{SkillMinutesNotes, "把飞书妙记（会议录音文字记录）整理成纪要文档并创建待办，input 需包含妙记链接"},
+	{SkillReviewPerms, "查看/收紧文档权限（关闭外部访问、关闭链接分享、移除协作者），报告会发给请求人"},
+	{SkillTransferOwner, "把文档转给某人负责/转移所有者（不是添加协作者），执行前会请用户确认"},
+	{SkillRenameFile, `给文档、表格改名（"把刚才那个文档改名为《Q3 规划》"），input 需包含文档链接或原名称`},
+	{SkillCommentDoc, "在文档里评论（可针对文中某句话），文档可用链接或名称指定"},
+	{SkillExportDoc, "把文档导出为 PDF/Word（表格为 Excel），并以文件发给指定的人或群；platform 为接收方所在平台"},
+	{SkillImportFile, "把链接里的文件或用户上传的附件存到云空间（Word/Excel 等会转为在线文档），input 需包含链接或附件名"},
+	{SkillQueryStatus, `询问之前交代的事情办得怎么样（"消息发出去了吗""文档建好了没"），查询任务记录并回复`},
+	{SkillQueryTable, `查询公司表格数据并统计（"上周的销售总额是多少"），只读；结果要发给别人或发到群时把接收方写进同一任务的 input（回答会连同图表一起发送），不要另建 send_message`},
+	{SkillAskKB, `查询公司制度、流程、规范等内部知识（"我们的报销标准是多少"），从知识库检索后回答并注明出处；答案要发给别人时把接收方写进同一任务的 input，不要另建 send_message`},
+	{SkillQueryApproval, `查询自己发起的飞书审批进度（"我的报销审批到哪一步了"），只读`},
+	{SkillQueryTasks, `查询自己的飞书待办任务（"我今天有哪些待办"），只读`},
+	{SkillScheduleMeet, `约会议/创建日程，先查参与人忙闲找都空的时间（"约一个我和张三都空的时间开会"），会议链接为 {{event_url}}`},
+	{SkillSetStatus, `设置自己的个人状态（"帮我把状态设成下午请假"），到期自动恢复`},
+	{SkillCreateTicket, `向 IT 服务台报修/提交工单（"电脑连不上 VPN，帮我报个修"），用户上传的截图会附在工单中，回复工单号`},
+	{SkillAddReaction, `给消息点表情回应（"给刚才那条消息点个 👍"），input 需包含最近对话中该消息的平台、消息 ID 与会话 ID`},
+}
+
+// SkillPolicy 技能开关：Disabled 为本环境禁用的技能，Tenants 按租户在此基础上增减；
+// Flags 为可选的功能灰度，技能还需 "skill.<技能名>" 对租户开启才可用
+type SkillPolicy struct {
+	Disabled []SkillType
+	Tenants  map[string]TenantSkills
+	Flags    Flags
+}
+
+// Flags 功能灰度开关（由 flags.Set 实现），未配置的功能视为开启
+type Flags interface {
+	Enabled(name, tenant string) bool
+}
+
+// TenantSkills 租户级技能开关：Disabled 额外禁用，Enabled 重新启用本环境禁用的技能
+type TenantSkills struct {
+	Disabled []SkillType
+	Enabled  []SkillType
+}
+
+// Enabled 技能对租户是否可用
+func (p SkillPolicy) Enabled(tenant string, skill SkillType) bool {
+	if !p.Feature(tenant, "skill."+string(skill)) {
+		return false
+	}
+	if t, ok := p.Tenants[tenant]; ok {
+		if slices.Contains(t.Disabled, skill) {
+			return false
+		}
+		if slices.Contains(t.Enabled, skill) {
+			return true
+		}
+	}
+	return !slices.Contains(p.Disabled, skill)
+}
+
+// Feature 功能（如 fast_path）对租户是否开启，未配置灰度时开启
+func (p SkillPolicy) Feature(tenant, name string) bool {
+	return p.Flags == nil || p.Flags.Enabled(name, tenant)
+}
+
+// plannerPromptFor 只包含租户可用技能的规划 Prompt
+func (s *Service) plannerPromptFor(tenant string) string {
+	return s.fillPlannerPrompt(plannerPrompt, tenant)
+}
+
+// fillPlannerPrompt 在规划 Prompt 模板中填入租户可用的技能
+func (s *Service) fillPlannerPrompt(template, tenant string) string {
+	var names, lines []string
+	for _, c := range skillCatalog {
+		if !s.skills.Enabled(tenant, c.Skill) {
+			continue
+		}
+		names = append(names, string(c.Skill))
+		lines = append(lines, "- "+string(c.Skill)+": "+c.Desc)
+	}
+	for _, p := range s.pluginSkills() {
+		if !s.skills.Enabled(tenant, SkillType(p.Name)) {
+			continue
+		}
+		names = append(names, p.Name)
+		lines = append(lines, "- "+p.Name+": "+p.Description)
+	}
+	for _, p := range s.scriptSkills(tenant) {
+		if slices.Contains(names, p.Name) || !s.skills.Enabled(tenant, SkillType(p.Name)) {
+			continue
+		}
+		names = append(names, p.Name)
+		lines = append(lines, "- "+p.Name+": "+p.Description)
+	}
+	prompt := strings.Replace(template, "{{skill_names}}", strings.Join(names, "|"), 1)
+	return strings.Replace(prompt, "{{skill_list}}", strings.Join(lines, "\n"), 1)
+}
+
+// disabledSkills 计划中对租户不可用的技能（工作流或大模型仍可能给出已禁用的技能）
+func (s *Service) disabledSkills(tenant string, tasks []TaskSpec) []string {
+	var disabled []string
+	for _, t := range tasks {
+		if !s.skills.Enabled(tenant, t.Skill) && !slices.Contains(disabled, string(t.Skill)) {
+			disabled = append(disabled, string(t.Skill))
+		}
+	}
+	return disabled
+}
+
+// BuiltinSkills 内置技能名，外部插件不能注册同名技能
+func BuiltinSkills() []string {
+	names := make([]string, 0, len(skillPrompts))
+	for skill := range skillPrompts {
+		names = append(names, string(skill))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// pluginSkills 当前可用的插件技能
+func (s *Service) pluginSkills() []model.PluginSkill {
+	if s.plugins == nil {
+		return nil
+	}
+	return s.plugins.Skills()
+}
+
+// pluginSkill 查找名为 skill 的插件技能
+func (s *Service) pluginSkill(skill SkillType) (model.PluginSkill, bool) {
+	for _, p := range s.pluginSkills() {
+		if p.Name == string(skill) {
+			return p, true
+		}
+	}
+	return model.PluginSkill{}, false
+}
+
+// scriptSkills 租户可用的脚本技能
+func (s *Service) scriptSkills(tenant string) []model.PluginSkill {
+	if s.scripts == nil {
+		return nil
+	}
+	return s.scripts.Skills(tenant)
+}
+
+// scriptSkill 查找租户名为 skill 的脚本技能
+func (s *Service) scriptSkill(tenant string, skill SkillType) (model.PluginSkill, bool) {
+	for _, p := range s.scriptSkills(tenant) {
+		if p.Name == string(skill) {
+			return p, true
+		}
+	}
+	return model.PluginSkill{}, false
+}
+
+// pluginSkillPrompt 按插件或脚本声明的参数 Schema 生成参数提取 Prompt，prefix 为动作类型前缀
+func pluginSkillPrompt(p model.PluginSkill, prefix string) string {
+	schema, _ := json.Marshal(p.Params)
+	return fmt.Sprintf(`提取「%s」的参数，返回 JSON：
+{"type":"%s%s","params":{...}}
+
+params 须符合以下 JSON Schema，无法确定的可选参数省略：
+%s
+
+只返回 JSON。`, p.Description, prefix, p.Name, schema)
+}
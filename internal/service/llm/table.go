@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	clientllm "sayso-agent/internal/client/llm"
+	"sayso-agent/internal/model"
+)
+
+// tableDataLimit 送入大模型的表格数据最大字符数
+const tableDataLimit = 30000
+
+const tablePrompt = `你是数据分析助手，根据用户提供的表格数据回答问题：
+- 表格为制表符分隔，首行为表头
+- 需要求和、计数、平均等时逐行核对后计算，不要估算；"上周""本月"等按给出的今天日期换算
+- 回答简洁，直接给出结论和关键数字（如"上周销售总额 128,500 元，共 36 笔"），可以附一两句说明
+- 数据中没有相关信息时如实说明，不要编造
+- 表格数据被截断时在回答中注明"仅统计了前 N 行"
+
+返回 JSON：
+{"answer":"回答正文","chart":{"kind":"bar|line","title":"图表标题","labels":["类目"],"values":[数值],"unit":"单位"}}
+
+chart 规则：
+- 结果是一组可比较的数值时给出（如按地区/按人汇总、按天/按周的趋势），按时间变化用 line，其余用 bar
+- labels 与 values 一一对应，最多 20 项，values 为纯数字（不带单位和千分位）
+- 只有单个数字或没有数值结果时省略 chart
+
+只返回 JSON。`
+
+// TableAnalyst 基于表格数据回答统计类问题（依赖大模型）
+type TableAnalyst struct {
+	client *clientllm.Client
+}
+
+// NewTableAnalyst 创建表格问答服务
+func NewTableAnalyst(client *clientllm.Client) *TableAnalyst {
+	return &TableAnalyst{client: client}
+}
+
+// AnswerTableQuestion 根据表格数据回答问题，description 为表格内容说明（可为空）；结果为一组数值时附带图表数据
+func (a *TableAnalyst) AnswerTableQuestion(ctx context.Context, question, description, table string) (model.TableAnswer, error) {
+	truncated := false
+	if r := []rune(table); len(r) > tableDataLimit {
+		table = string(r[:tableDataLimit])
+		truncated = true
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "今天：%s\n", time.Now().Format("2006-01-02 Monday"))
+	if description != "" {
+		fmt.Fprintf(&b, "表格说明：%s\n", description)
+	}
+	fmt.Fprintf(&b, "问题：%s\n\n表格数据", question)
+	if truncated {
+		b.WriteString("（已截断）")
+	}
+	fmt.Fprintf(&b, "：\n%s", table)
+
+	raw, err := a.client.Chat(ctx, tablePrompt, b.String())
+	if err != nil {
+		return model.TableAnswer{}, err
+	}
+	var answer model.TableAnswer
+	if err := json.Unmarshal([]byte(ExtractJSON(raw)), &answer); err != nil {
+		// 未按 JSON 返回时整段作为回答
+		answer = model.TableAnswer{Text: strings.TrimSpace(raw)}
+	}
+	if answer.Text == "" {
+		return model.TableAnswer{}, fmt.Errorf("empty answer")
+	}
+	if c := answer.Chart; c != nil && (len(c.Values) < 2 || len(c.Labels) != len(c.Values)) {
+		answer.Chart = nil
+	}
+	return answer, nil
+}
@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/panics"
+)
+
+// panickingPlugins 查找插件技能时 panic，模拟技能处理中的程序错误
+type panickingPlugins struct{}
+
+func (panickingPlugins) Skills() []model.PluginSkill {
+	panic("plugin registry corrupted")
+}
+
+// panickingScripts 查找租户脚本技能时 panic
+type panickingScripts struct{}
+
+func (panickingScripts) Skills(string) []model.PluginSkill {
+	var m map[string][]model.PluginSkill
+	m["tenant"] = nil // 向 nil map 写入，运行时 panic
+	return nil
+}
+
+func TestExecuteTasksRecoversPanic(t *testing.T) {
+	tests := []struct {
+		name       string
+		plugins    PluginSkills
+		scripts    ScriptSkills
+		tasks      []TaskSpec
+		failed     string // 记为失败的任务
+		skipped    []string
+		wantPanics int64
+	}{
+		{
+			name:       "plugin skill",
+			plugins:    panickingPlugins{},
+			tasks:      []TaskSpec{{ID: "task_1", Skill: "broken_plugin"}},
+			failed:     "task_1",
+			wantPanics: 1,
+		},
+		{
+			name:    "dependent task not executed",
+			plugins: panickingPlugins{},
+			tasks: []TaskSpec{
+				{ID: "task_1", Skill: "broken_plugin"},
+				{ID: "task_2", Skill: "broken_plugin", DependsOn: []string{"task_1"}},
+			},
+			failed:     "task_1",
+			skipped:    []string{"task_2"},
+			wantPanics: 1,
+		},
+		{
+			name:       "script skill runtime error",
+			scripts:    panickingScripts{},
+			tasks:      []TaskSpec{{ID: "task_1", Skill: "broken_script"}},
+			failed:     "task_1",
+			wantPanics: 1,
+		},
+		{
+			name:    "foreach items",
+			plugins: panickingPlugins{},
+			tasks: []TaskSpec{{
+				ID:      "task_1",
+				Skill:   "broken_plugin",
+				ForEach: &ForEach{Source: "items", Items: []string{"a", "b", "c"}},
+			}},
+			failed:     "task_1",
+			wantPanics: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reporter, err := panics.NewReporter(nil, "", nil)
+			if err != nil {
+				t.Fatalf("NewReporter: %v", err)
+			}
+			s := NewService(Deps{Plugins: tt.plugins, Scripts: tt.scripts, Panics: reporter})
+
+			results, err := s.executeTasks(context.Background(), tt.tasks, model.ASRRequest{})
+			if !errors.Is(err, model.ErrTaskPanic) {
+				t.Fatalf("executeTasks error = %v, want ErrTaskPanic", err)
+			}
+			r := results[tt.failed]
+			if r == nil || !errors.Is(r.Error, model.ErrTaskPanic) {
+				t.Fatalf("results[%s] = %+v, want ErrTaskPanic", tt.failed, r)
+			}
+			if r.TaskID != tt.failed || r.Outputs == nil {
+				t.Errorf("results[%s] = %+v, want TaskID and non-nil Outputs", tt.failed, r)
+			}
+			for _, id := range tt.skipped {
+				if _, ok := results[id]; ok {
+					t.Errorf("task %s executed after its dependency panicked", id)
+				}
+			}
+			if got := reporter.Count(); got != tt.wantPanics {
+				t.Errorf("reporter.Count() = %d, want %d", got, tt.wantPanics)
+			}
+		})
+	}
+}
+
+func TestExecuteTasksRecoversPanicWithoutReporter(t *testing.T) {
+	s := NewService(Deps{Plugins: panickingPlugins{}})
+	_, err := s.executeTasks(context.Background(), []TaskSpec{{ID: "task_1", Skill: "broken_plugin"}}, model.ASRRequest{})
+	if !errors.Is(err, model.ErrTaskPanic) {
+		t.Fatalf("executeTasks error = %v, want ErrTaskPanic", err)
+	}
+}
@@ -0,0 +1,44 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	clientllm "sayso-agent/internal/client/llm"
+)
+
+// titleContentLimit 生成标题时送入大模型的正文最大字符数
+const titleContentLimit = 3000
+
+const titlePrompt = `根据文档正文拟一个简洁的标题：
+- 不超过 20 个字，概括正文主题（如"Q3 市场活动复盘"）
+- 不要加日期、书名号、引号或标点结尾
+
+只返回标题。`
+
+// Titler 为未命名的文档生成标题（依赖大模型）
+type Titler struct {
+	client *clientllm.Client
+}
+
+// NewTitler 创建标题生成服务
+func NewTitler(client *clientllm.Client) *Titler {
+	return &Titler{client: client}
+}
+
+// GenerateTitle 根据正文生成标题
+func (t *Titler) GenerateTitle(ctx context.Context, content string) (string, error) {
+	if r := []rune(content); len(r) > titleContentLimit {
+		content = string(r[:titleContentLimit])
+	}
+	raw, err := t.client.Chat(ctx, titlePrompt, content)
+	if err != nil {
+		return "", err
+	}
+	title := strings.Trim(strings.TrimSpace(raw), "\"'“”《》「」。")
+	if title == "" {
+		return "", fmt.Errorf("empty title")
+	}
+	return title, nil
+}
@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+
+	clientllm "sayso-agent/internal/client/llm"
+	"sayso-agent/internal/model"
+)
+
+// 单次请求识别的图片数量与单张大小上限
+const (
+	maxImages      = 4
+	imageSizeLimit = 5 << 20
+)
+
+const imagePrompt = `识别图片中的内容（白板、截图、照片、表格等），输出纯文本：
+- 逐字转写图中文字，保持原有顺序与层级，列表项用 - 开头，多张图片按顺序分段
+- 图中文字很少时，用一两句话描述与用户需求相关的画面信息
+- 看不清的内容标注为 [无法辨认]，不要编造
+- 只输出识别结果，不要解释`
+
+// withImageText 识别请求中的图片附件，将识别结果追加到请求文本，供规划与各技能使用
+func (s *Service) withImageText(ctx context.Context, req model.ASRRequest) (model.ASRRequest, error) {
+	var images []clientllm.Image
+	for _, a := range req.Attachments {
+		if !isImage(a) {
+			continue
+		}
+		if len(images) == maxImages {
+			break
+		}
+		switch {
+		case len(a.Data) > imageSizeLimit:
+			return req, fmt.Errorf("image %s exceeds %d bytes", a.Name, imageSizeLimit)
+		case len(a.Data) > 0:
+			images = append(images, clientllm.Image{URL: "data:" + imageMimeType(a) + ";base64," + base64.StdEncoding.EncodeToString(a.Data)})
+		case a.URL != "":
+			images = append(images, clientllm.Image{URL: a.URL})
+		}
+	}
+	if len(images) == 0 {
+		return req, nil
+	}
+	text, err := s.client.ChatWithImages(ctx, imagePrompt, req.Text, images)
+	if err != nil {
+		return req, fmt.Errorf("read images: %w", err)
+	}
+	req.Text = fmt.Sprintf("%s\n\n图片内容：\n%s", req.Text, strings.TrimSpace(text))
+	return req, nil
+}
+
+var imageExts = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".bmp": true}
+
+func isImage(a model.Attachment) bool {
+	if a.MimeType != "" {
+		return strings.HasPrefix(a.MimeType, "image/")
+	}
+	return imageExts[strings.ToLower(path.Ext(a.Name))]
+}
+
+func imageMimeType(a model.Attachment) string {
+	if a.MimeType != "" {
+		return a.MimeType
+	}
+	if t := mime.TypeByExtension(strings.ToLower(path.Ext(a.Name))); t != "" {
+		return t
+	}
+	return "image/png"
+}
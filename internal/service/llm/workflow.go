@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+const workflowVarsPrompt = `从用户输入中提取以下变量的值，返回 JSON 对象，键为变量名，值为字符串；无法确定的变量值为空字符串。
+
+变量：%s
+
+只返回 JSON。`
+
+// ValidateWorkflow 校验工作流定义：技能已注册、任务 ID 唯一、依赖存在
+func ValidateWorkflow(wf model.Workflow) error {
+	if wf.Name == "" {
+		return fmt.Errorf("workflow: name is required")
+	}
+	if len(wf.Triggers) == 0 {
+		return fmt.Errorf("workflow: at least one trigger is required")
+	}
+	if len(wf.Tasks) == 0 {
+		return fmt.Errorf("workflow: at least one task is required")
+	}
+	ids := make(map[string]bool, len(wf.Tasks))
+	for _, t := range wf.Tasks {
+		if t.ID == "" {
+			return fmt.Errorf("workflow: task id is required")
+		}
+		if ids[t.ID] {
+			return fmt.Errorf("workflow: duplicate task id: %s", t.ID)
+		}
+		ids[t.ID] = true
+		if _, ok := skillPrompts[SkillType(t.Skill)]; !ok {
+			return fmt.Errorf("workflow: task %s: unknown skill: %s", t.ID, t.Skill)
+		}
+	}
+	for _, t := range wf.Tasks {
+		for _, dep := range t.DependsOn {
+			if !ids[dep] {
+				return fmt.Errorf("workflow: task %s depends on unknown task: %s", t.ID, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// matchWorkflow 查找输入命中触发词的工作流；多个命中时取触发词最长的
+func (s *Service) matchWorkflow(ctx context.Context, req model.ASRRequest) (*model.Workflow, error) {
+	if s.workflows == nil {
+		return nil, nil
+	}
+	list, err := s.workflows.List(ctx, req.Tenant())
+	if err != nil {
+		return nil, err
+	}
+	var best *model.Workflow
+	bestLen := 0
+	for i := range list {
+		for _, trigger := range list[i].Triggers {
+			if trigger != "" && strings.Contains(req.Text, trigger) && len(trigger) > bestLen {
+				best = &list[i]
+				bestLen = len(trigger)
+			}
+		}
+	}
+	return best, nil
+}
+
+// extractWorkflowVars 由大模型从本次输入中提取工作流变量值
+func (s *Service) extractWorkflowVars(ctx context.Context, wf *model.Workflow, userText string) (map[string]string, error) {
+	vars := make(map[string]string)
+	if len(wf.Variables) == 0 {
+		return vars, nil
+	}
+	raw, err := s.client.Chat(ctx, fmt.Sprintf(workflowVarsPrompt, strings.Join(wf.Variables, "、")), userText)
+	if err != nil {
+		return nil, fmt.Errorf("fill workflow variables: %w", err)
+	}
+	if err := json.Unmarshal([]byte(ExtractJSON(raw)), &vars); err != nil {
+		return nil, fmt.Errorf("parse workflow variables: %w", err)
+	}
+	return vars, nil
+}
+
+// ProcessWorkflow 以给定变量直接运行工作流（不经过任务规划与变量提取），用于定时触发
+func (s *Service) ProcessWorkflow(ctx context.Context, wf *model.Workflow, vars map[string]string, req model.ASRRequest) (*model.LLMActionOutput, error) {
+	plan := planFromWorkflow(wf, vars)
+	results, err := s.executeTasks(ctx, plan.Tasks, req)
+	if err != nil {
+		return nil, err
+	}
+	out := s.buildOutput(plan, results)
+	out.Workflow = wf.Name
+	return out, nil
+}
+
+// planFromWorkflow 将工作流展开为任务规划，任务 input 中的 {{变量名}} 替换为 vars 中的值
+func planFromWorkflow(wf *model.Workflow, vars map[string]string) *TaskPlan {
+	plan := &TaskPlan{Summary: fmt.Sprintf("执行工作流「%s」", wf.Name)}
+	for _, t := range wf.Tasks {
+		input := t.Input
+		for _, name := range wf.Variables {
+			input = strings.ReplaceAll(input, "{{"+name+"}}", vars[name])
+		}
+		platform := t.Platform
+		if platform == "" {
+			platform = "feishu"
+		}
+		plan.Tasks = append(plan.Tasks, TaskSpec{
+			ID:        t.ID,
+			Skill:     SkillType(t.Skill),
+			Platform:  platform,
+			Input:     input,
+			DependsOn: t.DependsOn,
+		})
+	}
+	return plan
+}
@@ -0,0 +1,247 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"sayso-agent/internal/store"
+)
+
+// 后台维护：按间隔刷新目录树、联系人、频道列表缓存与向量索引。
+// 间隔带随机抖动，避免多副本同时请求外部 API；写共享存储的任务（如 SQL/Qdrant 中的向量索引）只在选出的主副本上运行
+
+// 默认参数
+const (
+	defaultLeaseTTL = 30 * time.Second
+	defaultTimeout  = 10 * time.Minute
+	leaseName       = "maintenance"
+)
+
+// Job 维护任务：启动时运行一次，Interval 大于 0 时按间隔（带抖动）重复运行
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// LeaderOnly 只在主副本上运行（结果写入各副本共享的存储）；刷新进程内缓存的任务每个副本都要运行
+	LeaderOnly bool
+	// Timeout 单次运行的超时，0 为默认 10 分钟
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Config 维护守护进程配置
+type Config struct {
+	// Jitter 间隔的随机抖动比例（0-1），如 0.1 表示实际间隔在 ±10% 内浮动
+	Jitter float64
+	// LeaseTTL 主副本租约的有效期，0 为默认 30 秒；每 1/3 TTL 续约一次
+	LeaseTTL time.Duration
+	// OnRun 每次运行结束后回调（非主副本跳过时不回调），如连续失败时告警；nil 表示不回调
+	OnRun func(ctx context.Context, job string, err error)
+}
+
+// JobStatus 任务运行状态
+type JobStatus struct {
+	Name       string    `json:"name"`
+	Interval   string    `json:"interval"`
+	LeaderOnly bool      `json:"leader_only"`
+	Running    bool      `json:"running"`
+	Runs       int       `json:"runs"`
+	Failures   int       `json:"failures"`
+	Skipped    int       `json:"skipped"` // 非主副本跳过的次数
+	LastRun    time.Time `json:"last_run"`
+	LastTook   string    `json:"last_took,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+	NextRun    time.Time `json:"next_run"`
+}
+
+// Status 守护进程状态
+type Status struct {
+	Holder string      `json:"holder"`
+	Leader bool        `json:"leader"`
+	Jobs   []JobStatus `json:"jobs"`
+}
+
+// Daemon 维护守护进程
+type Daemon struct {
+	cfg    Config
+	leases store.LeaseStore
+	holder string
+
+	mu     sync.RWMutex
+	leader bool
+	jobs   []*job
+}
+
+type job struct {
+	Job
+	status JobStatus
+}
+
+// New 创建维护守护进程；leases 为 nil 时视为单副本，本副本始终为主
+func New(cfg Config, leases store.LeaseStore) *Daemon {
+	host, _ := os.Hostname()
+	return &Daemon{cfg: cfg, leases: leases, holder: fmt.Sprintf("%s-%d-%04x", host, os.Getpid(), rand.Intn(1<<16)), leader: leases == nil}
+}
+
+// Add 注册任务，须在 Run 之前调用
+func (d *Daemon) Add(j Job) {
+	interval := "once"
+	if j.Interval > 0 {
+		interval = j.Interval.String()
+	}
+	d.jobs = append(d.jobs, &job{Job: j, status: JobStatus{Name: j.Name, Interval: interval, LeaderOnly: j.LeaderOnly}})
+}
+
+// Run 选主后启动各任务，直到 ctx 取消；取消时释放租约
+func (d *Daemon) Run(ctx context.Context) {
+	if d.leases != nil {
+		// 先确定主副本身份，启动时的首次运行才能正确跳过
+		d.renewLease(ctx)
+		go d.electLoop(ctx)
+	}
+	var wg sync.WaitGroup
+	for _, j := range d.jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			d.loop(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+}
+
+// Status 返回主副本身份与各任务状态
+func (d *Daemon) Status() Status {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	st := Status{Holder: d.holder, Leader: d.leader, Jobs: make([]JobStatus, len(d.jobs))}
+	for i, j := range d.jobs {
+		st.Jobs[i] = j.status
+	}
+	return st
+}
+
+func (d *Daemon) isLeader() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.leader
+}
+
+// electLoop 定期续约；失去租约的副本停止运行 LeaderOnly 任务，直到重新获取
+func (d *Daemon) electLoop(ctx context.Context) {
+	ttl := d.leaseTTL()
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if d.isLeader() {
+				// ctx 已取消，释放使用独立的短超时
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := d.leases.Release(releaseCtx, leaseName, d.holder); err != nil {
+					log.Printf("maintenance: release lease: %v", err)
+				}
+				cancel()
+			}
+			return
+		case <-ticker.C:
+			d.renewLease(ctx)
+		}
+	}
+}
+
+func (d *Daemon) renewLease(ctx context.Context) {
+	ok, err := d.leases.Acquire(ctx, leaseName, d.holder, d.leaseTTL())
+	if err != nil {
+		// 无法确认租约时按非主处理，宁可少跑也不重复写共享存储
+		log.Printf("maintenance: acquire lease: %v", err)
+		ok = false
+	}
+	d.mu.Lock()
+	if ok != d.leader {
+		log.Printf("maintenance: %s leader=%v", d.holder, ok)
+	}
+	d.leader = ok
+	d.mu.Unlock()
+}
+
+func (d *Daemon) leaseTTL() time.Duration {
+	if d.cfg.LeaseTTL > 0 {
+		return d.cfg.LeaseTTL
+	}
+	return defaultLeaseTTL
+}
+
+// loop 启动时运行一次，之后按带抖动的间隔运行
+func (d *Daemon) loop(ctx context.Context, j *job) {
+	for {
+		d.runOnce(ctx, j)
+		if j.Interval <= 0 {
+			return
+		}
+		wait := d.jittered(j.Interval)
+		d.mu.Lock()
+		j.status.NextRun = time.Now().Add(wait)
+		d.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// jittered 在 interval 基础上随机浮动 ±Jitter
+func (d *Daemon) jittered(interval time.Duration) time.Duration {
+	if d.cfg.Jitter <= 0 {
+		return interval
+	}
+	delta := (rand.Float64()*2 - 1) * d.cfg.Jitter * float64(interval)
+	return interval + time.Duration(delta)
+}
+
+func (d *Daemon) runOnce(ctx context.Context, j *job) {
+	if j.LeaderOnly && !d.isLeader() {
+		d.mu.Lock()
+		j.status.Skipped++
+		d.mu.Unlock()
+		return
+	}
+	timeout := j.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	d.mu.Lock()
+	j.status.Running = true
+	d.mu.Unlock()
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	start := time.Now()
+	err := j.Run(runCtx)
+	took := time.Since(start).Round(time.Millisecond)
+	cancel()
+
+	d.mu.Lock()
+	j.status.Running = false
+	j.status.Runs++
+	j.status.LastRun = start
+	j.status.LastTook = took.String()
+	j.status.LastError = ""
+	if err != nil {
+		j.status.Failures++
+		j.status.LastError = err.Error()
+	}
+	d.mu.Unlock()
+	if d.cfg.OnRun != nil {
+		d.cfg.OnRun(ctx, j.Name, err)
+	}
+	if err != nil {
+		log.Printf("maintenance: %s failed after %s: %v", j.Name, took, err)
+		return
+	}
+	log.Printf("maintenance: %s done in %s", j.Name, took)
+}
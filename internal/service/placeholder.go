@@ -0,0 +1,187 @@
+package service
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"sayso-agent/internal/model"
+)
+
+// 占位符的值来自前序动作的结果（文档标题、链接、备注等），可能含引号、花括号、尖括号或各平台的标记语法；
+// 替换时按值所在字段的渲染格式转义：链接字段做百分号编码，消息正文按平台与消息类型中和会被解析的标记，
+// 避免链接被截断、文本里出现 @所有人 等意外效果。非消息参数原样替换。
+// 联系人分组可能把一条消息展开到多个平台，替换时还会按各平台分别转义一份正文（params.content_by_platform），
+// 展开后由执行器按实际平台取用
+
+// applyPlaceholders 将 spec 中 Params 里的字符串值中的 {{key}} 替换为 placeholders[key]
+func applyPlaceholders(spec model.ActionSpec, placeholders map[string]string) model.ActionSpec {
+	if len(placeholders) == 0 {
+		return spec
+	}
+	out := spec
+	if spec.Params != nil {
+		out.Params = replacePlaceholdersInMap(spec, "", spec.Params, placeholders)
+		if byPlatform := contentByPlatform(spec, placeholders); byPlatform != nil {
+			out.Params[model.ParamContentByPlatform] = byPlatform
+		}
+	}
+	return out
+}
+
+// messagePlatforms 需要分别转义正文的消息平台
+var messagePlatforms = []string{"feishu", "slack", "discord"}
+
+// contentByPlatform send_message 正文含占位符时，按每个消息平台的转义方式各替换一份
+func contentByPlatform(spec model.ActionSpec, placeholders map[string]string) map[string]any {
+	content, ok := spec.Params["content"]
+	if spec.Type != model.ActionTypeSendMessage || !ok {
+		return nil
+	}
+	if b, _ := json.Marshal(content); !placeholderRE.Match(b) {
+		return nil
+	}
+	byPlatform := make(map[string]any, len(messagePlatforms))
+	for _, platform := range messagePlatforms {
+		on := spec
+		on.Params = make(map[string]any, len(spec.Params))
+		for k, v := range spec.Params {
+			on.Params[k] = v
+		}
+		on.Params["platform"] = platform
+		byPlatform[platform] = replacePlaceholdersInValue(on, "content", content, placeholders)
+	}
+	return byPlatform
+}
+
+// replacePlaceholdersInMap 递归替换 map 中所有字符串值的占位符；path 为 map 所在的字段路径
+func replacePlaceholdersInMap(spec model.ActionSpec, path string, m map[string]any, placeholders map[string]string) map[string]any {
+	result := make(map[string]any)
+	for k, v := range m {
+		result[k] = replacePlaceholdersInValue(spec, joinPath(path, k), v, placeholders)
+	}
+	return result
+}
+
+// replacePlaceholdersInValue 递归替换任意值中的占位符；数组元素沿用数组的字段路径
+func replacePlaceholdersInValue(spec model.ActionSpec, path string, v any, placeholders map[string]string) any {
+	switch val := v.(type) {
+	case string:
+		return replacePlaceholdersInString(val, placeholders, placeholderEscaper(spec, path))
+	case map[string]any:
+		return replacePlaceholdersInMap(spec, path, val, placeholders)
+	case map[string]string:
+		result := make(map[string]any)
+		for k, s := range val {
+			result[k] = replacePlaceholdersInString(s, placeholders, placeholderEscaper(spec, joinPath(path, k)))
+		}
+		return result
+	case []any:
+		result := make([]any, len(val))
+		for i, item := range val {
+			result[i] = replacePlaceholdersInValue(spec, path, item, placeholders)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// replacePlaceholdersInString 替换占位符，替换进来的值经 escape 转义；字符串中原有的内容不变
+func replacePlaceholdersInString(s string, placeholders map[string]string, escape func(string) string) string {
+	return placeholderRE.ReplaceAllStringFunc(s, func(match string) string {
+		key := strings.TrimSuffix(strings.TrimPrefix(match, "{{"), "}}")
+		if v, ok := placeholders[key]; ok {
+			return escape(v)
+		}
+		return match
+	})
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// placeholderEscaper 按字段路径（如 "content.text"）选择转义方式：
+//   - 链接字段（url、*_url）：百分号编码
+//   - send_message 的 content：飞书文本消息中和 <at> 等标签；富文本（post）、卡片的标题与正文为纯文本节点，只处理链接；
+//     Slack 文本与 Block Kit 的 mrkdwn 转义 & < >，卡片标题为 plain_text 不转义；Discord 中和 @everyone 等提及
+//   - 其他字段原样替换
+func placeholderEscaper(spec model.ActionSpec, path string) func(string) string {
+	key := path[strings.LastIndex(path, ".")+1:]
+	if key == "url" || strings.HasSuffix(key, "_url") {
+		return escapeURL
+	}
+	if spec.Type != model.ActionTypeSendMessage || !strings.HasPrefix(path, "content.") {
+		return rawValue
+	}
+	params := model.ParseSendMessageParams(spec.Params)
+	card := params.MessageType == "rich_text" || params.MessageType == "post" || params.MessageType == "link_card" || params.MessageType == "interactive"
+	switch params.Platform {
+	case "slack":
+		if card && key == "title" {
+			return plainText
+		}
+		return escapeSlack
+	case "discord":
+		return escapeDiscord
+	default:
+		if card {
+			return plainText
+		}
+		return escapeFeishuText
+	}
+}
+
+func rawValue(s string) string { return s }
+
+// urlEscaper 链接中不允许直接出现、会截断自动识别或破坏标记语法的字符
+var urlEscaper = strings.NewReplacer(
+	" ", "%20", `"`, "%22", "<", "%3C", ">", "%3E", `\`, "%5C",
+	"^", "%5E", "`", "%60", "{", "%7B", "|", "%7C", "}", "%7D",
+)
+
+// escapeURL 链接中的引号、尖括号、花括号、空格等做百分号编码；已编码的部分与其他字符不变
+func escapeURL(s string) string {
+	return urlEscaper.Replace(strings.TrimSpace(s))
+}
+
+// isLink 值整体是一个链接
+func isLink(s string) bool {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	return (strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")) && !strings.ContainsAny(s, "\r\n")
+}
+
+// plainText 纯文本节点：只有值整体为链接时编码，保证链接可点击
+func plainText(s string) string {
+	if isLink(s) {
+		return escapeURL(s)
+	}
+	return s
+}
+
+// feishuTagRE 飞书文本消息会解析的标签，如 <at user_id="all"></at>
+var feishuTagRE = regexp.MustCompile(`(?i)<(/?\s*(?:at|a|b|i|u|s)\b)`)
+
+// escapeFeishuText 飞书文本消息：标签的尖括号换成全角，显示不变但不再被解析
+func escapeFeishuText(s string) string {
+	return feishuTagRE.ReplaceAllString(plainText(s), "＜$1")
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeSlack Slack mrkdwn 的控制字符：& < > 转为实体，<!channel>、<url|文字> 等不再生效
+func escapeSlack(s string) string {
+	return slackEscaper.Replace(plainText(s))
+}
+
+var discordEscaper = strings.NewReplacer("@everyone", "@\u200beveryone", "@here", "@\u200bhere", "<@", "<\u200b@")
+
+// escapeDiscord Discord：在 @everyone、@here 与 <@用户> 中插入零宽空格，不触发提及
+func escapeDiscord(s string) string {
+	return discordEscaper.Replace(plainText(s))
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"sayso-agent/internal/client/feishu"
+	"sayso-agent/internal/client/slack"
+	"sayso-agent/internal/model"
+)
+
+func sendSpec(platform, messageType string, content map[string]any) model.ActionSpec {
+	return model.ActionSpec{
+		Type: model.ActionTypeSendMessage,
+		Params: map[string]any{
+			"platform":     platform,
+			"message_type": messageType,
+			"targets":      []any{"{{owner}}"},
+			"content":      content,
+		},
+	}
+}
+
+func TestApplyPlaceholdersEscaping(t *testing.T) {
+	placeholders := map[string]string{
+		"doc_url":   `https://example.com/docx/abc?q={"a":"b c"}&x=<1>|2`,
+		"doc_title": `Q3 "增长" {计划} <at user_id="all"></at> & <!channel>`,
+		"owner":     `<at user_id="all">`,
+	}
+	const wantURL = `https://example.com/docx/abc?q=%7B%22a%22:%22b%20c%22%7D&x=%3C1%3E%7C2`
+
+	tests := []struct {
+		name string
+		spec model.ActionSpec
+		want map[string]string // content 字段 -> 替换结果
+	}{
+		{
+			name: "feishu text",
+			spec: sendSpec("feishu", "text", map[string]any{"text": "《{{doc_title}}》见 {{doc_url}}"}),
+			want: map[string]string{"text": `《Q3 "增长" {计划} ＜at user_id="all">＜/at> & <!channel>》见 ` + wantURL},
+		},
+		{
+			name: "feishu post keeps plain text",
+			spec: sendSpec("feishu", "rich_text", map[string]any{"title": "{{doc_title}}", "text": "{{doc_url}}", "url": "{{doc_url}}"}),
+			want: map[string]string{"title": placeholders["doc_title"], "text": wantURL, "url": wantURL},
+		},
+		{
+			name: "feishu card",
+			spec: sendSpec("feishu", "link_card", map[string]any{"title": "{{doc_title}}", "url": "{{doc_url}}"}),
+			want: map[string]string{"title": placeholders["doc_title"], "url": wantURL},
+		},
+		{
+			name: "slack text",
+			spec: sendSpec("slack", "text", map[string]any{"text": "{{doc_title}}: {{doc_url}}"}),
+			want: map[string]string{"text": `Q3 "增长" {计划} &lt;at user_id="all"&gt;&lt;/at&gt; &amp; &lt;!channel&gt;: ` +
+				strings.ReplaceAll(wantURL, "&", "&amp;")},
+		},
+		{
+			name: "slack block kit title is plain_text",
+			spec: sendSpec("slack", "link_card", map[string]any{"title": "{{doc_title}}", "description": "{{doc_title}}", "url": "{{doc_url}}"}),
+			want: map[string]string{
+				"title":       placeholders["doc_title"],
+				"description": `Q3 "增长" {计划} &lt;at user_id="all"&gt;&lt;/at&gt; &amp; &lt;!channel&gt;`,
+				"url":         wantURL,
+			},
+		},
+		{
+			name: "discord mentions",
+			spec: sendSpec("discord", "text", map[string]any{"text": "{{note}}"}),
+			want: map[string]string{"text": "请 @\u200beveryone 查看，<\u200b@123>"},
+		},
+	}
+	placeholders["note"] = "请 @everyone 查看，<@123>"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := applyPlaceholders(tt.spec, placeholders)
+			content, _ := got.Params["content"].(map[string]any)
+			for field, want := range tt.want {
+				if content[field] != want {
+					t.Errorf("content.%s = %q, want %q", field, content[field], want)
+				}
+			}
+			// 接收方不在 content 中，原样替换
+			if targets := got.Params["targets"].([]any); targets[0] != placeholders["owner"] {
+				t.Errorf("targets[0] = %q, want raw value", targets[0])
+			}
+		})
+	}
+}
+
+func TestApplyPlaceholdersRawOutsideMessages(t *testing.T) {
+	spec := model.ActionSpec{
+		Type:   model.ActionTypeCreateDoc,
+		Params: map[string]any{"title": "{{doc_title}} 复盘", "content": "{{missing}}", "source_url": "{{doc_url}}"},
+	}
+	got := applyPlaceholders(spec, map[string]string{"doc_title": `<b>"Q3"</b>`, "doc_url": "https://example.com/a b"})
+	if got.Params["title"] != `<b>"Q3"</b> 复盘` {
+		t.Errorf("title = %q", got.Params["title"])
+	}
+	if got.Params["content"] != "{{missing}}" {
+		t.Errorf("unknown placeholder replaced: %q", got.Params["content"])
+	}
+	if got.Params["source_url"] != "https://example.com/a%20b" {
+		t.Errorf("source_url = %q", got.Params["source_url"])
+	}
+	if spec.Params["title"] != "{{doc_title}} 复盘" {
+		t.Error("original spec modified")
+	}
+}
+
+// 替换后的参数渲染为各平台的消息内容时仍是合法 JSON，链接保持完整
+func TestApplyPlaceholdersRenderedMessages(t *testing.T) {
+	placeholders := map[string]string{"doc_url": `https://example.com/d?f={"k":"v"}`, "doc_title": `a"}]},{"tag":"at","user_id":"all`}
+	const wantURL = `https://example.com/d?f=%7B%22k%22:%22v%22%7D`
+
+	feishuSpec := applyPlaceholders(sendSpec("feishu", "rich_text", map[string]any{"title": "{{doc_title}}", "text": "见 {{doc_url}}", "url": "{{doc_url}}"}), placeholders)
+	p := model.ParseSendMessageParams(feishuSpec.Params)
+	var post struct {
+		ZhCN struct {
+			Title   string `json:"title"`
+			Content [][]struct {
+				Tag  string `json:"tag"`
+				Text string `json:"text"`
+				Href string `json:"href"`
+			} `json:"content"`
+		} `json:"zh_cn"`
+	}
+	if err := json.Unmarshal([]byte(feishu.BuildPostContent(p.Content.Title, p.Content.Text, p.Content.URL)), &post); err != nil {
+		t.Fatalf("feishu post content is not valid JSON: %v", err)
+	}
+	if post.ZhCN.Title != placeholders["doc_title"] {
+		t.Errorf("post title = %q", post.ZhCN.Title)
+	}
+	if n := len(post.ZhCN.Content[0]); n != 2 || post.ZhCN.Content[0][1].Href != wantURL {
+		t.Errorf("post paragraph = %+v", post.ZhCN.Content[0])
+	}
+
+	if !json.Valid([]byte(feishu.RenderCard(model.LinkCard(p.Content.Title, p.Content.Text, p.Content.URL, "")))) {
+		t.Error("feishu card content is not valid JSON")
+	}
+
+	slackSpec := applyPlaceholders(sendSpec("slack", "link_card", map[string]any{"text": "<{{doc_url}}|{{doc_title}}>", "url": "{{doc_url}}"}), placeholders)
+	p = model.ParseSendMessageParams(slackSpec.Params)
+	b, err := json.Marshal(slack.RenderBlocks(model.LinkCard(p.Content.Title, p.Content.Text, p.Content.URL, "")))
+	if err != nil {
+		t.Fatalf("marshal blocks: %v", err)
+	}
+	var blocks []slack.Block
+	if err := json.Unmarshal(b, &blocks); err != nil {
+		t.Fatalf("slack blocks are not valid JSON: %v", err)
+	}
+	if got := blocks[0].Text.Text; got != "<"+wantURL+`|a"}]},{"tag":"at","user_id":"all>` {
+		t.Errorf("section text = %q", got)
+	}
+	if got := blocks[1].Elements[0].URL; got != wantURL {
+		t.Errorf("button url = %q", got)
+	}
+}
+
+// 平台为空、接收方是跨平台的联系人分组时，正文按各平台分别转义，由执行器展开后按实际平台取用
+func TestApplyPlaceholdersContentByPlatform(t *testing.T) {
+	spec := sendSpec("", "text", map[string]any{"text": "提醒：{{title}}"})
+	spec.Params["targets"] = []any{"项目组"}
+	out := applyPlaceholders(spec, map[string]string{"title": "<!channel> @everyone <at user_id=\"all\"></at>"})
+
+	byPlatform, ok := out.Params[model.ParamContentByPlatform].(map[string]any)
+	if !ok {
+		t.Fatalf("params = %+v, want %s", out.Params, model.ParamContentByPlatform)
+	}
+	want := map[string]string{
+		"feishu":  "提醒：<!channel> @everyone ＜at user_id=\"all\">＜/at>",
+		"slack":   "提醒：&lt;!channel&gt; @everyone &lt;at user_id=\"all\"&gt;&lt;/at&gt;",
+		"discord": "提醒：<!channel> @​everyone <at user_id=\"all\"></at>",
+	}
+	for platform, text := range want {
+		content, _ := byPlatform[platform].(map[string]any)
+		if content["text"] != text {
+			t.Errorf("%s text = %q, want %q", platform, content["text"], text)
+		}
+	}
+
+	plain := applyPlaceholders(sendSpec("slack", "text", map[string]any{"text": "固定正文"}), map[string]string{"title": "x"})
+	if _, ok := plain.Params[model.ParamContentByPlatform]; ok {
+		t.Errorf("content without placeholders should not carry %s", model.ParamContentByPlatform)
+	}
+}
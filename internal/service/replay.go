@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"sayso-agent/internal/model"
+)
+
+// 重放：按历史任务的计划重新执行一遍（如"把上周那份通知再发一遍给新同事"），不再调用大模型；
+// 可替换接收方、标题或按 task_id 覆盖任意参数，结果记为新任务
+
+// ErrNotReplayable 任务没有可重放的计划（如大模型调用失败、规则未产出动作）
+var ErrNotReplayable = errors.New("task has no plan to replay")
+
+// ReplayOverrides 重放时替换的参数，均为空时按原计划执行
+type ReplayOverrides struct {
+	// Targets 替换消息类动作（参数含 targets）的接收方
+	Targets []string `json:"targets"`
+	// Title 替换建文档、建日程等动作（参数含 title）的标题
+	Title string `json:"title"`
+	// Params 按动作 task_id 覆盖参数，如 {"task_2": {"channel": "#general"}}
+	Params map[string]map[string]any `json:"params"`
+}
+
+// Replay 按任务 taskID 的计划重新执行，沿用原请求的租户、会话与上下文；占位符按本次执行的结果重新替换
+func (s *ASRService) Replay(ctx context.Context, taskID string, o ReplayOverrides) (model.ASRResponse, error) {
+	if s.tasks == nil {
+		return model.ASRResponse{TaskID: taskID}, fmt.Errorf("task store not configured")
+	}
+	old, err := s.tasks.Get(ctx, taskID)
+	if err != nil {
+		return model.ASRResponse{TaskID: taskID}, err
+	}
+	if len(old.Plan) == 0 {
+		return model.ASRResponse{TaskID: taskID}, fmt.Errorf("task %s: %w", taskID, ErrNotReplayable)
+	}
+	plan, err := replayPlan(old.Plan, o)
+	if err != nil {
+		// 替换参数无效，未创建任务
+		return model.ASRResponse{}, err
+	}
+
+	ctx = withMeters(ctx)
+	req := model.ASRRequest{UserID: old.UserID, TenantID: old.TenantID, Text: old.Text}
+	if old.Request != nil {
+		req = *old.Request
+	}
+	ctx = withLLMOptions(ctx, req.LLM)
+	rec := s.startTask(ctx, req, model.TaskSourceReplay)
+	rec.ReplayOf = old.ID
+	rec.Request = &req
+	rec.Intent = "重放：" + old.Intent
+	rec.Plan = plan
+	rec.Pending = plan
+	rec.Placeholders = make(map[string]string)
+	resp, err := s.resume(ctx, &rec, model.ASRResponse{TaskID: rec.ID}, &req)
+	s.finishTask(ctx, rec, resp, err)
+	return resp, err
+}
+
+// replayPlan 复制原计划并应用替换；替换项没有可作用的动作时返回 ErrInvalidParams
+func replayPlan(plan []model.ActionSpec, o ReplayOverrides) ([]model.ActionSpec, error) {
+	out := make([]model.ActionSpec, len(plan))
+	var targeted, titled bool
+	matched := make(map[string]bool, len(o.Params))
+	for i, spec := range plan {
+		spec.Params = maps.Clone(spec.Params)
+		if spec.Params == nil {
+			spec.Params = make(map[string]any)
+		}
+		if _, ok := spec.Params["targets"]; ok && len(o.Targets) > 0 {
+			targets := make([]any, len(o.Targets))
+			for j, t := range o.Targets {
+				targets[j] = t
+			}
+			spec.Params["targets"] = targets
+			// 原计划中固定的接收方 ID 随之作废
+			spec.TargetUserID, spec.TargetChatID = "", ""
+			targeted = true
+		}
+		if _, ok := spec.Params["title"]; ok && o.Title != "" {
+			spec.Params["title"] = o.Title
+			titled = true
+		}
+		for _, id := range []string{spec.TaskID, spec.Group} {
+			if p, ok := o.Params[id]; ok && id != "" {
+				maps.Copy(spec.Params, p)
+				matched[id] = true
+			}
+		}
+		out[i] = spec
+	}
+	if len(o.Targets) > 0 && !targeted {
+		return nil, fmt.Errorf("%w: no action in the plan has recipients to replace", model.ErrInvalidParams)
+	}
+	if o.Title != "" && !titled {
+		return nil, fmt.Errorf("%w: no action in the plan has a title to replace", model.ErrInvalidParams)
+	}
+	for id := range o.Params {
+		if !matched[id] {
+			return nil, fmt.Errorf("%w: no action %s in the plan", model.ErrInvalidParams, id)
+		}
+	}
+	return out, nil
+}
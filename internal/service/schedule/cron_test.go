@@ -0,0 +1,64 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronMatch(t *testing.T) {
+	// 2024-05-17 是周五
+	fri := time.Date(2024, 5, 17, 17, 0, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{name: "every minute", expr: "* * * * *", at: fri, want: true},
+		{name: "friday 17:00", expr: "0 17 * * 5", at: fri, want: true},
+		{name: "friday 17:00 wrong minute", expr: "0 17 * * 5", at: fri.Add(time.Minute), want: false},
+		{name: "weekdays range", expr: "0 17 * * 1-5", at: fri, want: true},
+		{name: "weekend list", expr: "0 17 * * 0,6", at: fri, want: false},
+		{name: "sunday as 7", expr: "0 17 * * 7", at: fri.AddDate(0, 0, 2), want: true},
+		{name: "step minutes", expr: "*/15 * * * *", at: fri.Add(45 * time.Minute), want: true},
+		{name: "step minutes miss", expr: "*/15 * * * *", at: fri.Add(10 * time.Minute), want: false},
+		{name: "dom or dow", expr: "0 17 1 * 5", at: fri, want: true},
+		{name: "month mismatch", expr: "0 17 * 6 *", at: fri, want: false},
+		// 日字段为 */2 时视为不受限，须同时满足周（17 日在 */2 的 1、3、5… 中）
+		{name: "dom step with dow", expr: "0 17 */2 * 1", at: fri, want: false},
+		{name: "dom step with dow match", expr: "0 17 */2 * 5", at: fri, want: true},
+		{name: "full dow range with dom", expr: "0 17 1 * 0-6", at: fri, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) error: %v", tt.expr, err)
+			}
+			if got := c.Match(tt.at); got != tt.want {
+				t.Errorf("Match(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	c, err := ParseCron("30 9 * * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 周五之后的下一个周一 09:30
+	from := time.Date(2024, 5, 17, 17, 0, 0, 0, time.Local)
+	want := time.Date(2024, 5, 20, 9, 30, 0, 0, time.Local)
+	if got := c.Next(from); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
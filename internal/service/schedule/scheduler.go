@@ -0,0 +1,131 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/panics"
+	"sayso-agent/internal/service/alert"
+	"sayso-agent/internal/store"
+)
+
+// runTimeout 单次定时运行的超时时间
+const runTimeout = 5 * time.Minute
+
+// claimTTL 认领一次触发的租约时长：租约按工作流命名、持有者带触发分钟，各副本在同一分钟内先到先得，
+// 下一分钟前过期，任一副本都可认领下一次触发；须小于一分钟并大于各副本 tick 的时间差
+const claimTTL = 50 * time.Second
+
+// Runner 执行工作流（由 service.ASRService 实现，运行记录写入任务存储）
+type Runner interface {
+	RunWorkflow(ctx context.Context, wf model.Workflow, userID string, vars map[string]string) (model.ASRResponse, error)
+}
+
+// Scheduler 按 cron 定时运行已保存的工作流，连续失败时向运维频道告警；多副本时每次触发经租约只由一个副本运行
+type Scheduler struct {
+	workflows store.WorkflowStore
+	runner    Runner
+	monitor   *alert.Monitor
+	leases    store.LeaseStore
+	panics    *panics.Reporter
+	holder    string
+
+	mu    sync.Mutex
+	crons map[string]*Cron // cron 表达式 -> 解析结果
+}
+
+// NewScheduler 创建定时调度器；leases 为 nil 时视为单副本，每次触发都在本副本运行
+func NewScheduler(workflows store.WorkflowStore, runner Runner, monitor *alert.Monitor, leases store.LeaseStore, reporter *panics.Reporter) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		workflows: workflows,
+		runner:    runner,
+		monitor:   monitor,
+		leases:    leases,
+		panics:    reporter,
+		holder:    fmt.Sprintf("%s-%d-%04x", host, os.Getpid(), rand.Intn(1<<16)),
+		crons:     make(map[string]*Cron),
+	}
+}
+
+// Run 每分钟检查一次到期的工作流，直到 ctx 取消
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next.Sub(now)):
+			s.tick(ctx, next)
+		}
+	}
+}
+
+// tick 运行在 t 这一分钟到期的工作流
+func (s *Scheduler) tick(ctx context.Context, t time.Time) {
+	list, err := s.workflows.ListScheduled(ctx)
+	if err != nil {
+		log.Printf("scheduler: list workflows: %v", err)
+		return
+	}
+	for _, wf := range list {
+		c, err := s.cron(wf.Schedule.Cron)
+		if err != nil {
+			log.Printf("scheduler: workflow %s/%s: %v", wf.TenantID, wf.Name, err)
+			continue
+		}
+		if c.Match(t) && s.claim(ctx, wf, t) {
+			go s.run(ctx, wf)
+		}
+	}
+}
+
+// claim 认领工作流在 t 这一分钟的触发；其他副本已认领或租约存储出错时跳过
+func (s *Scheduler) claim(ctx context.Context, wf model.Workflow, t time.Time) bool {
+	if s.leases == nil {
+		return true
+	}
+	name := fmt.Sprintf("schedule:%s/%s", wf.TenantID, wf.Name)
+	ok, err := s.leases.Acquire(ctx, name, s.holder+"@"+t.UTC().Format("200601021504"), claimTTL)
+	if err != nil {
+		log.Printf("scheduler: claim %s: %v", name, err)
+		return false
+	}
+	return ok
+}
+
+// run 运行单个工作流；panic 只影响本次运行
+func (s *Scheduler) run(ctx context.Context, wf model.Workflow) {
+	defer s.panics.Recover(ctx, fmt.Sprintf("scheduled workflow %s/%s", wf.TenantID, wf.Name))
+	ctx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+	resp, err := s.runner.RunWorkflow(ctx, wf, wf.Schedule.UserID, wf.Schedule.Vars)
+	name := fmt.Sprintf("定时工作流「%s/%s」", wf.TenantID, wf.Name)
+	if err != nil {
+		s.monitor.Job(ctx, name, fmt.Errorf("task %s: %w", resp.TaskID, err))
+		return
+	}
+	s.monitor.Job(ctx, name, nil)
+	log.Printf("scheduler: workflow %s/%s done, task=%s", wf.TenantID, wf.Name, resp.TaskID)
+}
+
+func (s *Scheduler) cron(expr string) (*Cron, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.crons[expr]; ok {
+		return c, nil
+	}
+	c, err := ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	s.crons[expr] = c
+	return c, nil
+}
@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/service/alert"
+	"sayso-agent/internal/store"
+)
+
+// countingRunner 记录运行次数；panic 为 true 时每次运行都 panic
+type countingRunner struct {
+	runs  atomic.Int32
+	panic bool
+	wg    sync.WaitGroup
+}
+
+func (r *countingRunner) RunWorkflow(context.Context, model.Workflow, string, map[string]string) (model.ASRResponse, error) {
+	defer r.wg.Done()
+	r.runs.Add(1)
+	if r.panic {
+		panic("bad workflow")
+	}
+	return model.ASRResponse{TaskID: "t1"}, nil
+}
+
+func scheduledStore(t *testing.T) store.WorkflowStore {
+	t.Helper()
+	workflows := store.NewMemoryWorkflowStore()
+	wf := model.Workflow{TenantID: "acme", Name: "周报", Schedule: &model.WorkflowSchedule{Cron: "* * * * *", UserID: "ou_a"}}
+	if _, err := workflows.Save(context.Background(), wf); err != nil {
+		t.Fatalf("save workflow: %v", err)
+	}
+	return workflows
+}
+
+func TestSchedulerClaimsEachFireOnce(t *testing.T) {
+	workflows := scheduledStore(t)
+	leases := store.NewMemoryLeaseStore()
+	runner := &countingRunner{}
+	monitor := alert.NewMonitor(alert.MonitorConfig{}, nil)
+	replicas := []*Scheduler{
+		NewScheduler(workflows, runner, monitor, leases, nil),
+		NewScheduler(workflows, runner, monitor, leases, nil),
+	}
+	at := time.Date(2024, 5, 17, 17, 0, 0, 0, time.UTC)
+
+	runner.wg.Add(1)
+	for _, s := range replicas {
+		s.tick(context.Background(), at)
+	}
+	runner.wg.Wait()
+	if got := runner.runs.Load(); got != 1 {
+		t.Fatalf("runs = %d, want 1 across replicas", got)
+	}
+}
+
+func TestSchedulerRecoversPanickingRun(t *testing.T) {
+	runner := &countingRunner{panic: true}
+	s := NewScheduler(scheduledStore(t), runner, alert.NewMonitor(alert.MonitorConfig{}, nil), nil, nil)
+	runner.wg.Add(1)
+	// 未恢复的 panic 会使测试进程退出
+	s.run(context.Background(), model.Workflow{TenantID: "acme", Name: "周报", Schedule: &model.WorkflowSchedule{Cron: "* * * * *"}})
+	runner.wg.Wait()
+	if got := runner.runs.Load(); got != 1 {
+		t.Fatalf("runs = %d, want 1", got)
+	}
+}
@@ -0,0 +1,238 @@
+// Package slo 延迟目标跟踪：按阶段（规划、参数提取、执行、合计）统计耗时达标情况，
+// 计算短、长两个窗口的预算消耗速率（burn rate）；两个窗口都消耗过快时判定需要降级（见 llm.Degradation）。
+package slo
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/trace"
+)
+
+// 未配置时的默认值
+const (
+	defaultObjective   = 0.95
+	defaultShortWindow = 5 * time.Minute
+	defaultLongWindow  = time.Hour
+	// minSamples 短窗口内样本数达到此值才判定降级，避免个别慢请求触发
+	minSamples = 10
+)
+
+// 阶段名，与 deadline.Phase 一致，另有合计
+const (
+	PhasePlanning   = "planning"
+	PhaseExtraction = "extraction"
+	PhaseExecution  = "execution"
+	PhaseTotal      = "total"
+)
+
+var phases = []string{PhasePlanning, PhaseExtraction, PhaseExecution, PhaseTotal}
+
+// Config 各阶段的延迟目标；目标为 0 的阶段不统计
+type Config struct {
+	// Objective 耗时不超过目标的请求比例（0-1），0 为 0.95
+	Objective  float64
+	Planning   time.Duration
+	Extraction time.Duration
+	Execution  time.Duration
+	Total      time.Duration
+	// ShortWindow、LongWindow 计算消耗速率的两个窗口，0 为 5 分钟、1 小时
+	ShortWindow time.Duration
+	LongWindow  time.Duration
+	// DegradeBurnRate 规划或参数提取在两个窗口的消耗速率都达到此值时降级，0 不降级
+	DegradeBurnRate float64
+}
+
+// Tracker 延迟目标跟踪，按分钟分桶保存长窗口内的样本；并发安全
+type Tracker struct {
+	cfg     Config
+	targets map[string]time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]map[int64]*bucket // 阶段 -> 分钟 -> 样本数
+}
+
+type bucket struct {
+	total int
+	slow  int
+}
+
+// NewTracker 创建延迟目标跟踪
+func NewTracker(cfg Config) *Tracker {
+	if cfg.Objective <= 0 || cfg.Objective >= 1 {
+		cfg.Objective = defaultObjective
+	}
+	if cfg.ShortWindow <= 0 {
+		cfg.ShortWindow = defaultShortWindow
+	}
+	if cfg.LongWindow < cfg.ShortWindow {
+		cfg.LongWindow = max(defaultLongWindow, cfg.ShortWindow)
+	}
+	targets := map[string]time.Duration{
+		PhasePlanning:   cfg.Planning,
+		PhaseExtraction: cfg.Extraction,
+		PhaseExecution:  cfg.Execution,
+		PhaseTotal:      cfg.Total,
+	}
+	return &Tracker{cfg: cfg, targets: targets, buckets: make(map[string]map[int64]*bucket)}
+}
+
+// ObserveTask 从 ctx 中本次处理的阶段耗时（trace）记录样本：规划、参数提取取对应阶段，执行为各动作耗时之和，
+// 合计为三者之和；确认后继续执行只有执行阶段。没有 trace（如定时任务）时不记录
+func (t *Tracker) ObserveTask(ctx context.Context, rec model.TaskRecord) {
+	spans := trace.From(ctx).Spans()
+	if len(spans) == 0 {
+		return
+	}
+	durations := make(map[string]time.Duration)
+	for _, span := range spans {
+		switch {
+		case span.Name == "llm.planning":
+			durations[PhasePlanning] += span.Duration
+		case span.Name == "llm.extraction":
+			durations[PhaseExtraction] += span.Duration
+		case strings.HasPrefix(span.Name, "action."):
+			durations[PhaseExecution] += span.Duration
+		default:
+			continue
+		}
+		durations[PhaseTotal] += span.Duration
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for phase, d := range durations {
+		t.add(phase, d, now)
+	}
+}
+
+// add 记入一个样本并清理长窗口之外的桶
+func (t *Tracker) add(phase string, d time.Duration, now time.Time) {
+	target := t.targets[phase]
+	if target <= 0 {
+		return
+	}
+	m := t.buckets[phase]
+	if m == nil {
+		m = make(map[int64]*bucket)
+		t.buckets[phase] = m
+	}
+	minute := now.Unix() / 60
+	b := m[minute]
+	if b == nil {
+		b = &bucket{}
+		m[minute] = b
+		oldest := now.Add(-t.cfg.LongWindow).Unix() / 60
+		for k := range m {
+			if k < oldest {
+				delete(m, k)
+			}
+		}
+	}
+	b.total++
+	if d > target {
+		b.slow++
+	}
+}
+
+// window 窗口内的样本数与超时数
+func (t *Tracker) window(phase string, window time.Duration, now time.Time) (total, slow int) {
+	since := now.Add(-window).Unix() / 60
+	for minute, b := range t.buckets[phase] {
+		if minute > since {
+			total += b.total
+			slow += b.slow
+		}
+	}
+	return total, slow
+}
+
+// burnRate 消耗速率：超时比例与允许超时比例（1-Objective）之比，1 表示恰好在窗口内用完预算
+func (t *Tracker) burnRate(total, slow int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(slow) / float64(total) / (1 - t.cfg.Objective)
+}
+
+// Degraded 规划或参数提取在短、长两个窗口的消耗速率都达到 DegradeBurnRate 时返回 true（实现 llm.LatencyGuard）
+func (t *Tracker) Degraded() bool {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.degraded(now)
+}
+
+func (t *Tracker) degraded(now time.Time) bool {
+	if t.cfg.DegradeBurnRate <= 0 {
+		return false
+	}
+	for _, phase := range []string{PhasePlanning, PhaseExtraction} {
+		total, slow := t.window(phase, t.cfg.ShortWindow, now)
+		if total < minSamples || t.burnRate(total, slow) < t.cfg.DegradeBurnRate {
+			continue
+		}
+		if total, slow := t.window(phase, t.cfg.LongWindow, now); t.burnRate(total, slow) >= t.cfg.DegradeBurnRate {
+			return true
+		}
+	}
+	return false
+}
+
+// Status 延迟目标的当前状态
+type Status struct {
+	Objective float64 `json:"objective"`
+	// Degraded 当前是否降级处理（改用备用模型、复用缓存的计划）
+	Degraded bool          `json:"degraded"`
+	Phases   []PhaseStatus `json:"phases"`
+}
+
+// PhaseStatus 一个阶段在各窗口的达标情况
+type PhaseStatus struct {
+	Phase    string         `json:"phase"`
+	TargetMs int64          `json:"target_ms"`
+	Windows  []WindowStatus `json:"windows"`
+}
+
+// WindowStatus 一个窗口内的样本数、超时数、达标率与消耗速率
+type WindowStatus struct {
+	Window     string  `json:"window"`
+	Requests   int     `json:"requests"`
+	Slow       int     `json:"slow"`
+	Compliance float64 `json:"compliance"`
+	BurnRate   float64 `json:"burn_rate"`
+}
+
+// Status 各阶段在短、长窗口的达标率与消耗速率；未设目标的阶段不列出
+func (t *Tracker) Status() Status {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := Status{Objective: t.cfg.Objective, Degraded: t.degraded(now), Phases: []PhaseStatus{}}
+	for _, phase := range phases {
+		target := t.targets[phase]
+		if target <= 0 {
+			continue
+		}
+		ps := PhaseStatus{Phase: phase, TargetMs: target.Milliseconds()}
+		for _, w := range []time.Duration{t.cfg.ShortWindow, t.cfg.LongWindow} {
+			total, slow := t.window(phase, w, now)
+			ws := WindowStatus{Window: w.String(), Requests: total, Slow: slow, Compliance: 1, BurnRate: round(t.burnRate(total, slow))}
+			if total > 0 {
+				ws.Compliance = round(float64(total-slow) / float64(total))
+			}
+			ps.Windows = append(ps.Windows, ws)
+		}
+		st.Phases = append(st.Phases, ps)
+	}
+	return st
+}
+
+// round 保留 4 位小数
+func round(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
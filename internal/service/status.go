@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/model"
+	"sayso-agent/internal/store"
+)
+
+// statusQueryScan 查询状态时最多扫描的任务记录数
+const statusQueryScan = 50
+
+// queryStatus 在任务记录中查找请求人近期的任务，生成可读的状态说明
+// params: keyword, period(recent|today|morning|afternoon|yesterday), status(failed|succeeded|...), limit
+func (s *ASRService) queryStatus(ctx context.Context, spec model.ActionSpec, req *model.ASRRequest, currentID string) (model.ActionSummary, error) {
+	if s.tasks == nil {
+		return model.ActionSummary{}, fmt.Errorf("query_status: task store not configured")
+	}
+	filter := store.TaskFilter{TenantID: req.Tenant(), UserID: req.UserID, Limit: statusQueryScan}
+	if filter.UserID == "" {
+		filter.Session = req.SessionID()
+	}
+	if filter.UserID == "" && filter.Session == "" {
+		return model.ActionSummary{}, fmt.Errorf("query_status: %w: unknown requester", model.ErrInvalidParams)
+	}
+	filter.Status, _ = spec.Params["status"].(string)
+	list, err := s.tasks.List(ctx, filter)
+	if err != nil {
+		return model.ActionSummary{}, err
+	}
+
+	keyword, _ := spec.Params["keyword"].(string)
+	period, _ := spec.Params["period"].(string)
+	from, to := periodRange(period, time.Now())
+	limit := 3
+	if n, ok := spec.Params["limit"].(float64); ok && n > 0 {
+		limit = int(n)
+	}
+	var lines []string
+	for _, rec := range list {
+		if rec.ID == currentID || rec.CreatedAt.Before(from) || !rec.CreatedAt.Before(to) || !taskMatches(rec, keyword) {
+			continue
+		}
+		lines = append(lines, describeTask(rec))
+		if len(lines) == limit {
+			break
+		}
+	}
+
+	summary := model.ActionSummary{Type: "query_status", Target: keyword}
+	if len(lines) == 0 {
+		summary.Note = "没有找到相关的任务记录"
+	} else {
+		summary.Note = strings.Join(lines, "\n")
+	}
+	summary.Outputs = map[string]string{"answer": summary.Note}
+	return summary, nil
+}
+
+// periodRange 时间段对应的起止时间，未识别的按最近 24 小时
+func periodRange(period string, now time.Time) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case "today":
+		return today, now.Add(time.Second)
+	case "morning":
+		return today, today.Add(12 * time.Hour)
+	case "afternoon":
+		return today.Add(12 * time.Hour), now.Add(time.Second)
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today
+	default:
+		return now.Add(-24 * time.Hour), now.Add(time.Second)
+	}
+}
+
+// taskMatches 关键词出现在请求文本或动作目标中
+func taskMatches(rec model.TaskRecord, keyword string) bool {
+	if keyword == "" || strings.Contains(rec.Text, keyword) {
+		return true
+	}
+	for _, a := range rec.Actions {
+		if strings.Contains(a.Target, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeTask 生成单条任务的状态说明
+func describeTask(rec model.TaskRecord) string {
+	text := rec.Text
+	if r := []rune(text); len(r) > 30 {
+		text = string(r[:30]) + "…"
+	}
+	line := fmt.Sprintf("%s「%s」%s", rec.CreatedAt.Format("01-02 15:04"), text, taskStatusText(rec.Status))
+	var done []string
+	for _, a := range rec.Actions {
+		// 循环任务失败的单项由循环汇总列出
+		if a.Error != "" {
+			continue
+		}
+		item := a.Target
+		if a.Note != "" {
+			item += "（" + a.Note + "）"
+		}
+		if a.Unverified != "" {
+			item += "（未确认生效：" + a.Unverified + "）"
+		}
+		done = append(done, item)
+	}
+	if len(done) > 0 {
+		line += "；已完成：" + strings.Join(done, "、")
+	}
+	switch rec.Status {
+	case model.TaskStatusFailed:
+		line += "；失败原因：" + rec.Error
+		if len(rec.Pending) > 0 {
+			line += "。可以说「重试刚才失败的那个」只重新执行失败的步骤"
+		}
+	case model.TaskStatusAwaitingConfirmation:
+		line += "；" + rec.Message + "，回复「确认」继续"
+	}
+	return line
+}
+
+func taskStatusText(status string) string {
+	switch status {
+	case model.TaskStatusSucceeded:
+		return "已完成"
+	case model.TaskStatusFailed:
+		return "失败"
+	case model.TaskStatusRunning:
+		return "执行中"
+	case model.TaskStatusAwaitingConfirmation:
+		return "等待确认"
+	default:
+		return status
+	}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"sayso-agent/internal/model"
+)
+
+// 回复组织：把已执行的动作组成一段连贯、适合语音播报的回复，
+// 如"已创建《周报》并发送给张三，文档在「工作文档」目录"；不含链接，链接在 actions 中返回
+
+// phrasebook 一种语言的回复用语
+type phrasebook struct {
+	done       string                // 没有可描述的动作时
+	did        func([]string) string // 把若干动作短语连成一句
+	doc        string                // 创建文档，%s 为标题
+	bulkDocs   string                // 批量创建文档，%s 为份数
+	folder     string                // 创建文件夹
+	folderTree string                // 创建目录结构，依次为名称、目录数
+	upload     string                // 上传/导入文件
+	sent       string                // 发送消息，%s 为接收人
+	sendFailed string                // 发送失败
+	texted     string                // 发送短信/WhatsApp，依次为接收人、渠道
+	meeting    string                // 预约会议
+	ticket     string                // 提交工单，依次为问题、工单号
+	comment    string                // 评论文档
+	export     string                // 导出文档
+	minutes    string                // 整理妙记
+	transfer   string                // 转移所有者
+	perms      string                // 权限审查
+	status     string                // 设置状态
+	reaction   string                // 表情回复
+	recalled   string                // 撤回消息，%s 为接收人
+	renamed    string                // 修改文档标题，依次为原标题、新标题
+	docBy      string                // 分享的文档的来历，依次为所有者、相对时间
+	docAt      string                // 分享的文档的创建时间（所有者未知时），%s 为相对时间
+	generic    string                // 其他动作（插件、脚本），%s 为动作目标
+	located    func(kind, folder string) string
+	relDay     func(t, now time.Time) string
+	unverified string // 已执行但未确认生效，%s 为明细
+	partial    string // 循环任务部分项失败，依次为失败数、总数
+	item       string // 明细条目，动作目标与原因
+	sep        string // 明细分隔符
+	comma      string // 分句分隔符
+	stop       string // 句末标点
+	kinds      map[string]string
+}
+
+var phrasebooks = map[string]phrasebook{
+	"zh": {
+		done:       "处理完成",
+		did:        joinZH,
+		doc:        "创建《%s》",
+		bulkDocs:   "批量创建 %s 份文档",
+		folder:     "创建文件夹「%s」",
+		folderTree: "创建目录结构「%s」（%d 个目录）",
+		upload:     "上传「%s」",
+		sent:       "发送给%s",
+		sendFailed: "发送给%s失败",
+		texted:     "给%s发送%s",
+		meeting:    "预约会议「%s」",
+		ticket:     "提交工单「%s」（工单号 %s）",
+		comment:    "评论《%s》",
+		export:     "导出《%s》",
+		minutes:    "整理会议纪要《%s》",
+		transfer:   "转移文档所有者",
+		perms:      "检查文档权限",
+		status:     "设置状态「%s」",
+		reaction:   "添加表情回复",
+		recalled:   "撤回发给%s的消息",
+		renamed:    "把《%s》改名为《%s》",
+		docBy:      "该文档由%s创建于%s",
+		docAt:      "该文档创建于%s",
+		generic:    "完成「%s」",
+		located: func(kind, folder string) string {
+			return fmt.Sprintf("%s在「%s」目录", kind, folder)
+		},
+		relDay:     relDayZH,
+		unverified: "以下动作已执行，但未能确认完全生效：%s",
+		partial:    "%s/%s 项执行失败",
+		item:       "「%s」%s",
+		sep:        "；",
+		comma:      "，",
+		kinds:      map[string]string{"doc": "文档", "folder": "文件夹", "file": "文件", "sms": "短信", "whatsapp": " WhatsApp 消息"},
+	},
+	"en": {
+		done:       "Done.",
+		did:        joinEN,
+		doc:        "created \"%s\"",
+		bulkDocs:   "created %s documents",
+		folder:     "created the folder \"%s\"",
+		folderTree: "created the folder structure \"%s\" (%d folders)",
+		upload:     "uploaded \"%s\"",
+		sent:       "sent a message to %s",
+		sendFailed: "could not send to %s",
+		texted:     "sent %[2]s to %[1]s",
+		meeting:    "scheduled \"%s\"",
+		ticket:     "filed the ticket \"%s\" (ticket %s)",
+		comment:    "commented on \"%s\"",
+		export:     "exported \"%s\"",
+		minutes:    "wrote up the meeting notes \"%s\"",
+		transfer:   "transferred the document owner",
+		perms:      "reviewed the document permissions",
+		status:     "set your status to \"%s\"",
+		reaction:   "added a reaction",
+		recalled:   "recalled the message to %s",
+		renamed:    "renamed \"%s\" to \"%s\"",
+		docBy:      "the document was created by %s %s",
+		docAt:      "the document was created %s",
+		generic:    "completed \"%s\"",
+		located: func(kind, folder string) string {
+			return fmt.Sprintf("the %s is in the \"%s\" folder", kind, folder)
+		},
+		relDay:     relDayEN,
+		unverified: "These actions ran but could not be fully confirmed: %s",
+		partial:    "%s of %s items failed",
+		item:       "\"%s\" %s",
+		sep:        "; ",
+		comma:      ", ",
+		stop:       ".",
+		kinds:      map[string]string{"doc": "document", "folder": "folder", "file": "file", "sms": "a text message", "whatsapp": "a WhatsApp message"},
+	},
+}
+
+// phrasebookFor 按请求语言选择用语，未知语言使用中文
+func phrasebookFor(locale string) phrasebook {
+	if strings.HasPrefix(strings.ToLower(locale), "en") {
+		return phrasebooks["en"]
+	}
+	return phrasebooks["zh"]
+}
+
+// renderSummary 根据已执行的动作组成回复
+func renderSummary(actions []model.ActionSummary, locale string) string {
+	pb := phrasebookFor(locale)
+	var clauses, failures, places []string
+	for _, a := range actions {
+		// 循环任务失败的单项由循环汇总计入
+		if a.Error != "" {
+			continue
+		}
+		target := a.Target
+		switch a.Type {
+		case "feishu_doc", "feishu_import":
+			clauses = append(clauses, fmt.Sprintf(pb.doc, target))
+			if folder := a.Outputs["folder_name"]; folder != "" {
+				places = append(places, pb.located(pb.kinds["doc"], folder))
+			}
+		case "feishu_bulk_doc":
+			clauses = append(clauses, fmt.Sprintf(pb.bulkDocs, target))
+			if folder := a.Outputs["folder_name"]; folder != "" {
+				places = append(places, pb.located(pb.kinds["doc"], folder))
+			}
+		case "feishu_folder":
+			clauses = append(clauses, fmt.Sprintf(pb.folder, target))
+			if parent := a.Outputs["parent_name"]; parent != "" {
+				places = append(places, pb.located(pb.kinds["folder"], parent))
+			}
+		case "feishu_folder_tree":
+			clauses = append(clauses, fmt.Sprintf(pb.folderTree, target, len(strings.Split(a.Outputs["folders"], ","))))
+			if parent := a.Outputs["parent_name"]; parent != "" {
+				places = append(places, pb.located(pb.kinds["folder"], parent))
+			}
+		case "feishu_upload", "feishu_file":
+			clauses = append(clauses, fmt.Sprintf(pb.upload, target))
+			if folder := a.Outputs["folder_name"]; folder != "" {
+				places = append(places, pb.located(pb.kinds["file"], folder))
+			}
+		case "feishu_message", "slack_message", "discord_message", "message":
+			recipients := a.Outputs["recipients"]
+			if recipients == "" {
+				recipients = target
+			}
+			if a.Outputs["message_id"] == "" && a.Note != "" {
+				failures = append(failures, fmt.Sprintf(pb.sendFailed, recipients))
+			} else {
+				clauses = append(clauses, fmt.Sprintf(pb.sent, recipients))
+				if origin := docOrigin(pb, a.Outputs, time.Now()); origin != "" && !slices.Contains(places, origin) {
+					places = append(places, origin)
+				}
+			}
+		case "sms_message":
+			clauses = append(clauses, fmt.Sprintf(pb.texted, target, pb.kinds["sms"]))
+		case "whatsapp_message":
+			clauses = append(clauses, fmt.Sprintf(pb.texted, target, pb.kinds["whatsapp"]))
+		case "feishu_calendar_event":
+			clauses = append(clauses, fmt.Sprintf(pb.meeting, target))
+		case "feishu_ticket":
+			clauses = append(clauses, fmt.Sprintf(pb.ticket, target, a.Outputs["ticket_id"]))
+		case "feishu_comment":
+			clauses = append(clauses, fmt.Sprintf(pb.comment, target))
+		case "feishu_export":
+			clauses = append(clauses, fmt.Sprintf(pb.export, target))
+		case "feishu_minutes_notes":
+			clauses = append(clauses, fmt.Sprintf(pb.minutes, target))
+		case "feishu_transfer_owner":
+			clauses = append(clauses, pb.transfer)
+		case "feishu_permission_review":
+			clauses = append(clauses, pb.perms)
+		case "feishu_status":
+			clauses = append(clauses, fmt.Sprintf(pb.status, target))
+		case "feishu_reaction", "slack_reaction":
+			clauses = append(clauses, pb.reaction)
+		case "feishu_recall", "slack_delete", "discord_delete":
+			clauses = append(clauses, fmt.Sprintf(pb.recalled, target))
+		case "foreach":
+			if a.Outputs["failed"] != "0" {
+				failures = append(failures, fmt.Sprintf(pb.partial, a.Outputs["failed"], a.Outputs["total"]))
+			}
+		case "feishu_rename":
+			clauses = append(clauses, fmt.Sprintf(pb.renamed, a.Outputs["old_title"], target))
+		default:
+			if target != "" {
+				clauses = append(clauses, fmt.Sprintf(pb.generic, target))
+			}
+		}
+	}
+	if len(clauses) == 0 && len(failures) == 0 {
+		return pb.done
+	}
+	var parts []string
+	if len(clauses) > 0 {
+		parts = append(parts, pb.did(clauses))
+	}
+	parts = append(parts, places...)
+	parts = append(parts, failures...)
+	return strings.Join(parts, pb.comma) + pb.stop
+}
+
+// docOrigin 分享的文档的来历，如"该文档由王五创建于上周"；没有文档元数据时为空
+func docOrigin(pb phrasebook, outputs map[string]string, now time.Time) string {
+	created, err := time.Parse(time.RFC3339, outputs["doc_created_at"])
+	if err != nil {
+		return ""
+	}
+	when := pb.relDay(created.In(now.Location()), now)
+	if owner := outputs["doc_owner"]; owner != "" {
+		return fmt.Sprintf(pb.docBy, owner, when)
+	}
+	return fmt.Sprintf(pb.docAt, when)
+}
+
+// daysBetween t 到 now 相隔的自然日数
+func daysBetween(t, now time.Time) int {
+	y1, m1, d1 := t.Date()
+	y2, m2, d2 := now.Date()
+	return int(time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC).Sub(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
+
+// relDayZH 相对日期："今天"、"3 天前"、"上周"，更早的写日期
+func relDayZH(t, now time.Time) string {
+	switch days := daysBetween(t, now); {
+	case days <= 0:
+		return "今天"
+	case days == 1:
+		return "昨天"
+	case days < 7:
+		return fmt.Sprintf("%d 天前", days)
+	case days < 14:
+		return "上周"
+	case t.Year() == now.Year():
+		return t.Format("1月2日")
+	default:
+		return t.Format("2006年1月2日")
+	}
+}
+
+// relDayEN 相对日期："today"、"3 days ago"、"last week"，更早的写日期
+func relDayEN(t, now time.Time) string {
+	switch days := daysBetween(t, now); {
+	case days <= 0:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	case days < 7:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 14:
+		return "last week"
+	default:
+		return t.Format("on Jan 2, 2006")
+	}
+}
+
+// unverifiedNote 已执行但未确认生效的动作说明，与执行失败区分开单独列出
+func unverifiedNote(actions []model.ActionSummary, locale string) string {
+	pb := phrasebookFor(locale)
+	var items []string
+	for _, a := range actions {
+		if a.Unverified != "" {
+			items = append(items, fmt.Sprintf(pb.item, a.Target, a.Unverified))
+		}
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(pb.unverified, strings.Join(items, pb.sep))
+}
+
+// joinZH "已创建《周报》、创建文件夹「资料」并发送给张三"
+func joinZH(clauses []string) string {
+	if len(clauses) == 1 {
+		return "已" + clauses[0]
+	}
+	return "已" + strings.Join(clauses[:len(clauses)-1], "、") + "并" + clauses[len(clauses)-1]
+}
+
+// joinEN "Created "Weekly", created the folder "Docs" and sent a message to Alice"
+func joinEN(clauses []string) string {
+	s := clauses[0]
+	if len(clauses) > 1 {
+		s = strings.Join(clauses[:len(clauses)-1], ", ") + " and " + clauses[len(clauses)-1]
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlobStore 对象存储：保存导出的文件、合成的语音、图表等产物，通过带签名的临时链接访问
+type BlobStore interface {
+	// Put 保存对象，key 为以 / 分隔的相对路径
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// SignedURL 对象的临时访问链接，ttl 后失效
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// ErrInvalidSignature 签名链接的签名不符或已过期
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// defaultArtifactTTL 未配置时产物链接的有效期
+const defaultArtifactTTL = 7 * 24 * time.Hour
+
+// Artifacts 按「类别/日期/随机串/文件名」生成对象键保存产物，返回有效期为 ttl 的签名链接
+type Artifacts struct {
+	blobs BlobStore
+	ttl   time.Duration
+}
+
+// NewArtifacts 创建产物存储；ttl 为 0 时链接 7 天有效
+func NewArtifacts(blobs BlobStore, ttl time.Duration) *Artifacts {
+	if ttl <= 0 {
+		ttl = defaultArtifactTTL
+	}
+	return &Artifacts{blobs: blobs, ttl: ttl}
+}
+
+// Save 保存产物并返回签名链接；kind 为类别（如 exports、audio、charts），contentType 为空时按文件名推断
+func (a *Artifacts) Save(ctx context.Context, kind, name, contentType string, data []byte) (string, error) {
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(name))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	// 文件名中的 / 替换掉，避免改变对象层级
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	key := path.Join(kind, time.Now().Format("2006/01/02"), hex.EncodeToString(b), name)
+	if err := a.blobs.Put(ctx, key, contentType, data); err != nil {
+		return "", fmt.Errorf("save artifact %s: %w", key, err)
+	}
+	return a.blobs.SignedURL(ctx, key, a.ttl)
+}
+
+// LocalBlobStore 本地磁盘对象存储，适合单副本部署；签名链接指向本服务的 /blobs/ 路由，以 HMAC 校验
+type LocalBlobStore struct {
+	dir     string
+	baseURL string
+	secret  []byte
+}
+
+// NewLocalBlobStore 创建本地对象存储；baseURL 为签名链接的外部访问地址（如 https://sayso.example.com），secret 为签名密钥
+func NewLocalBlobStore(dir, baseURL string, secret []byte) (*LocalBlobStore, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("local blob store: signing secret required")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("local blob store: %w", err)
+	}
+	return &LocalBlobStore{dir: dir, baseURL: strings.TrimRight(baseURL, "/"), secret: secret}, nil
+}
+
+// file 对象键对应的文件路径；拒绝跳出存储目录的键
+func (s *LocalBlobStore) file(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean != "/"+key {
+		return "", fmt.Errorf("%w: invalid blob key %q", ErrNotFound, key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
+
+func (s *LocalBlobStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	file, err := s.file(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0o640)
+}
+
+func (s *LocalBlobStore) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	q := url.Values{"expires": {expires}, "sig": {s.sign(key, expires)}}
+	return s.baseURL + "/blobs/" + strings.Join(segments, "/") + "?" + q.Encode(), nil
+}
+
+func (s *LocalBlobStore) sign(key, expires string) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Open 校验签名链接的 expires、sig 后返回对象的文件路径与内容类型
+func (s *LocalBlobStore) Open(key, expires, sig string) (string, string, error) {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp || !hmac.Equal([]byte(sig), []byte(s.sign(key, expires))) {
+		return "", "", ErrInvalidSignature
+	}
+	file, err := s.file(key)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := os.Stat(file); err != nil {
+		return "", "", fmt.Errorf("blob %s: %w", key, ErrNotFound)
+	}
+	return file, mime.TypeByExtension(path.Ext(key)), nil
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownKey 密文所用的密钥不在密钥环中（轮换时移除了仍在使用的旧密钥）
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// sealedPrefix 密文格式为 "enc:<key id>:<base64(nonce|ciphertext)>"，不带前缀的值视为明文（启用加密前写入的旧数据）
+const sealedPrefix = "enc:"
+
+// CipherKey 数据加密密钥；Key 为 16/24/32 字节 AES 密钥（通常由 KMS 解密得到）
+type CipherKey struct {
+	ID  string
+	Key []byte
+}
+
+// Cipher AES-GCM 加密，支持多把密钥：新数据用首把密钥加密，旧密钥仅用于解密，
+// 轮换时把新密钥放在首位，旧数据在下次写入时自动改用新密钥
+type Cipher struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewCipher 创建加密器，keys[0] 为当前加密密钥
+func NewCipher(keys []CipherKey) (*Cipher, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("cipher: no keys")
+	}
+	c := &Cipher{primary: keys[0].ID, aeads: make(map[string]cipher.AEAD)}
+	for _, k := range keys {
+		if k.ID == "" || strings.Contains(k.ID, ":") {
+			return nil, fmt.Errorf("cipher: invalid key id %q", k.ID)
+		}
+		if _, dup := c.aeads[k.ID]; dup {
+			return nil, fmt.Errorf("cipher: duplicate key id %q", k.ID)
+		}
+		block, err := aes.NewCipher(k.Key)
+		if err != nil {
+			return nil, fmt.Errorf("cipher: key %s: %w", k.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("cipher: key %s: %w", k.ID, err)
+		}
+		c.aeads[k.ID] = aead
+	}
+	return c, nil
+}
+
+// Seal 用当前密钥加密；空串不加密
+func (c *Cipher) Seal(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	aead := c.aeads[c.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("cipher: nonce: %w", err)
+	}
+	// 以密钥 ID 作为附加数据，防止密文被换到其他密钥名下
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(c.primary))
+	return sealedPrefix + c.primary + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open 解密 Seal 的结果；不带密文前缀的值原样返回
+func (c *Cipher) Open(value string) (string, error) {
+	rest, ok := strings.CutPrefix(value, sealedPrefix)
+	if !ok {
+		return value, nil
+	}
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", fmt.Errorf("cipher: malformed ciphertext")
+	}
+	aead, ok := c.aeads[id]
+	if !ok {
+		return "", fmt.Errorf("cipher: %w %q", ErrUnknownKey, id)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("cipher: malformed ciphertext")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("cipher: decrypt with key %s: %w", id, err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"sayso-agent/internal/model"
+)
+
+// FeishuTenantStore 安装了商店应用的飞书企业，按 tenant_key 唯一
+type FeishuTenantStore interface {
+	Save(ctx context.Context, t model.FeishuTenant) error
+	Get(ctx context.Context, tenantKey string) (model.FeishuTenant, error)
+	Delete(ctx context.Context, tenantKey string) error
+	List(ctx context.Context) ([]model.FeishuTenant, error)
+}
+
+// MemoryFeishuTenantStore 进程内企业安装记录（重启丢失，适合单实例）
+type MemoryFeishuTenantStore struct {
+	mu      sync.RWMutex
+	tenants map[string]model.FeishuTenant // tenant_key -> 安装记录
+}
+
+// NewMemoryFeishuTenantStore 创建进程内企业安装记录存储
+func NewMemoryFeishuTenantStore() *MemoryFeishuTenantStore {
+	return &MemoryFeishuTenantStore{tenants: make(map[string]model.FeishuTenant)}
+}
+
+// Save 新增或覆盖企业记录
+func (s *MemoryFeishuTenantStore) Save(_ context.Context, t model.FeishuTenant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tenants[t.TenantKey] = t
+	return nil
+}
+
+// Get 按 tenant_key 查询
+func (s *MemoryFeishuTenantStore) Get(_ context.Context, tenantKey string) (model.FeishuTenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.tenants[tenantKey]
+	if !ok {
+		return model.FeishuTenant{}, ErrNotFound
+	}
+	return t, nil
+}
+
+// Delete 企业卸载应用后删除
+func (s *MemoryFeishuTenantStore) Delete(_ context.Context, tenantKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tenants, tenantKey)
+	return nil
+}
+
+// List 按安装时间列出全部企业
+func (s *MemoryFeishuTenantStore) List(_ context.Context) ([]model.FeishuTenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]model.FeishuTenant, 0, len(s.tenants))
+	for _, t := range s.tenants {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].InstalledAt.Before(list[j].InstalledAt) })
+	return list, nil
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"sayso-agent/internal/model"
+)
+
+// FolderRuleStore 文档归档规则存储，按租户隔离，List 按添加顺序返回（靠前的规则优先）
+type FolderRuleStore interface {
+	Save(ctx context.Context, rule model.FolderRule) error
+	List(ctx context.Context, tenantID string) ([]model.FolderRule, error)
+	Delete(ctx context.Context, tenantID, name string) error
+}
+
+// MemoryFolderRuleStore 进程内归档规则存储
+type MemoryFolderRuleStore struct {
+	mu    sync.RWMutex
+	rules map[string][]model.FolderRule // tenant -> rules
+}
+
+// NewMemoryFolderRuleStore 创建进程内归档规则存储，rules 为初始规则（如来自配置文件）
+func NewMemoryFolderRuleStore(rules []model.FolderRule) *MemoryFolderRuleStore {
+	s := &MemoryFolderRuleStore{rules: make(map[string][]model.FolderRule)}
+	for _, r := range rules {
+		_ = s.Save(context.Background(), r)
+	}
+	return s
+}
+
+// Save 新增规则，或按名称覆盖已有规则（保留原有顺序）
+func (s *MemoryFolderRuleStore) Save(_ context.Context, rule model.FolderRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rules := s.rules[rule.TenantID]
+	for i, r := range rules {
+		if r.Name == rule.Name {
+			rules[i] = rule
+			return nil
+		}
+	}
+	s.rules[rule.TenantID] = append(rules, rule)
+	return nil
+}
+
+// List 列出租户的规则
+func (s *MemoryFolderRuleStore) List(_ context.Context, tenantID string) ([]model.FolderRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]model.FolderRule(nil), s.rules[tenantID]...), nil
+}
+
+// Delete 删除规则
+func (s *MemoryFolderRuleStore) Delete(_ context.Context, tenantID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rules := s.rules[tenantID]
+	for i, r := range rules {
+		if r.Name == name {
+			s.rules[tenantID] = append(rules[:i:i], rules[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
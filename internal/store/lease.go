@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LeaseStore 租约（多副本选主）：同一时刻一个租约只由一个持有者持有，到期未续约后可被其他副本获取
+type LeaseStore interface {
+	// Acquire 获取或续约，返回是否持有
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Release 主动释放（仅当仍由 holder 持有时）
+	Release(ctx context.Context, name, holder string) error
+}
+
+// MemoryLeaseStore 进程内租约，只适用于单副本（或测试）
+type MemoryLeaseStore struct {
+	mu     sync.Mutex
+	leases map[string]lease
+}
+
+type lease struct {
+	holder   string
+	expireAt time.Time
+}
+
+// NewMemoryLeaseStore 创建进程内租约存储
+func NewMemoryLeaseStore() *MemoryLeaseStore {
+	return &MemoryLeaseStore{leases: make(map[string]lease)}
+}
+
+// Acquire 租约空闲、已过期或已由 holder 持有时获取成功
+func (s *MemoryLeaseStore) Acquire(_ context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if l, ok := s.leases[name]; ok && l.holder != holder && now.Before(l.expireAt) {
+		return false, nil
+	}
+	s.leases[name] = lease{holder: holder, expireAt: now.Add(ttl)}
+	return true, nil
+}
+
+// Release 释放租约
+func (s *MemoryLeaseStore) Release(_ context.Context, name, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.leases[name]; ok && l.holder == holder {
+		delete(s.leases, name)
+	}
+	return nil
+}
+
+// SQL 租约方言
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// SQLLeaseStore 基于 database/sql 的租约（PostgreSQL 或 SQLite，依赖 INSERT ... ON CONFLICT），
+// 各副本连接同一个库；驱动需由调用方链接
+type SQLLeaseStore struct {
+	db      *sql.DB
+	dialect string
+	table   string
+}
+
+// NewSQLLeaseStore 创建 SQL 租约存储并建表；table 为空时使用 sayso_leases
+func NewSQLLeaseStore(ctx context.Context, db *sql.DB, dialect, table string) (*SQLLeaseStore, error) {
+	if table == "" {
+		table = "sayso_leases"
+	}
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("lease store: invalid table name %q", table)
+	}
+	if dialect != DialectPostgres && dialect != DialectSQLite {
+		return nil, fmt.Errorf("lease store: unknown sql dialect %q", dialect)
+	}
+	ddl := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name TEXT PRIMARY KEY, holder TEXT NOT NULL, expires_at BIGINT NOT NULL)", table)
+	if _, err := db.ExecContext(ctx, ddl); err != nil {
+		return nil, fmt.Errorf("lease store: migrate: %w", err)
+	}
+	return &SQLLeaseStore{db: db, dialect: dialect, table: table}, nil
+}
+
+func (s *SQLLeaseStore) placeholder(n int) string {
+	if s.dialect == DialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?" + strconv.Itoa(n)
+}
+
+// Acquire 插入租约，已存在时仅在过期或由 holder 持有时更新；以影响行数判断是否获取成功
+func (s *SQLLeaseStore) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	query := fmt.Sprintf(`INSERT INTO %[1]s (name, holder, expires_at) VALUES (%[2]s, %[3]s, %[4]s)
+ON CONFLICT (name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+WHERE %[1]s.expires_at < %[5]s OR %[1]s.holder = excluded.holder`,
+		s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4))
+	res, err := s.db.ExecContext(ctx, query, name, holder, now.Add(ttl).UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("lease store: acquire: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("lease store: acquire: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Release 删除由 holder 持有的租约，其他副本无需等待过期即可接管
+func (s *SQLLeaseStore) Release(ctx context.Context, name, holder string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE name = %s AND holder = %s", s.table, s.placeholder(1), s.placeholder(2))
+	if _, err := s.db.ExecContext(ctx, query, name, holder); err != nil {
+		return fmt.Errorf("lease store: release: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"sayso-agent/internal/model"
+)
+
+// SealedTaskStore 包装 TaskStore，保护记录中的用户内容：
+// 配置 Cipher 时用户原文、回复、意图、计划与待执行动作的参数、动作结果、占位符、错误信息、原始请求（含附件）
+// 整体加密后写入，反馈说明单独加密（可单独更新），明文只保留租户、用户、状态、时间等用于筛选的字段；
+// 不保留原文时写入前清除用户原文，已结束的任务（不会再继续执行）另清除计划、占位符、回复与动作结果中的内容
+type SealedTaskStore struct {
+	inner             TaskStore
+	cipher            *Cipher
+	retainTranscripts bool
+}
+
+// NewSealedTaskStore 创建加密/脱敏存储；cipher 为 nil 时不加密
+func NewSealedTaskStore(inner TaskStore, cipher *Cipher, retainTranscripts bool) *SealedTaskStore {
+	return &SealedTaskStore{inner: inner, cipher: cipher, retainTranscripts: retainTranscripts}
+}
+
+func (s *SealedTaskStore) Save(ctx context.Context, rec model.TaskRecord) error {
+	if rec.Request != nil {
+		req := *rec.Request
+		req.Attachments = append([]model.Attachment(nil), req.Attachments...)
+		rec.Request = &req
+	}
+	if !s.retainTranscripts {
+		redact(&rec)
+	}
+	if s.cipher != nil {
+		if err := s.seal(&rec); err != nil {
+			return fmt.Errorf("seal task %s: %w", rec.ID, err)
+		}
+	}
+	return s.inner.Save(ctx, rec)
+}
+
+func (s *SealedTaskStore) Get(ctx context.Context, id string) (model.TaskRecord, error) {
+	rec, err := s.inner.Get(ctx, id)
+	if err != nil || s.cipher == nil {
+		return rec, err
+	}
+	if err := s.open(&rec); err != nil {
+		return model.TaskRecord{}, fmt.Errorf("open task %s: %w", id, err)
+	}
+	return rec, nil
+}
+
+// List 无法解密的记录（如密钥已从密钥环移除）跳过并记录日志，不影响其他记录
+func (s *SealedTaskStore) List(ctx context.Context, filter TaskFilter) ([]model.TaskRecord, error) {
+	list, err := s.inner.List(ctx, filter)
+	if err != nil || s.cipher == nil {
+		return list, err
+	}
+	opened := list[:0]
+	for _, rec := range list {
+		if err := s.open(&rec); err != nil {
+			log.Printf("open task %s: %v, skipped", rec.ID, err)
+			continue
+		}
+		opened = append(opened, rec)
+	}
+	return opened, nil
+}
+
+// Transition 状态不加密，直接由内层存储比较并交换
+func (s *SealedTaskStore) Transition(ctx context.Context, id, from, to string) (bool, error) {
+	return s.inner.Transition(ctx, id, from, to)
+}
+
+// SetFeedback 反馈说明单独加密，不重写记录中其他加密内容；不保留原文时已结束任务的说明不写入
+func (s *SealedTaskStore) SetFeedback(ctx context.Context, id string, fb model.TaskFeedback) error {
+	if !s.retainTranscripts {
+		rec, err := s.inner.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if rec.Status == model.TaskStatusSucceeded {
+			fb.Correction = ""
+		}
+	}
+	if s.cipher != nil {
+		sealed, err := s.cipher.Seal(fb.Correction)
+		if err != nil {
+			return fmt.Errorf("seal feedback %s: %w", id, err)
+		}
+		fb.Correction = sealed
+	}
+	return s.inner.SetFeedback(ctx, id, fb)
+}
+
+// redact 不保留原文：清除用户原文；已结束的任务另清除计划、占位符、回复与动作结果中的内容，
+// 待确认、失败待重试与执行中的任务保留继续执行所需的请求、待执行动作与占位符
+func redact(rec *model.TaskRecord) {
+	rec.Text = ""
+	if rec.Request != nil {
+		rec.Request.Text = ""
+	}
+	if rec.Status != model.TaskStatusSucceeded {
+		return
+	}
+	rec.Message, rec.Reply, rec.Intent, rec.Error = "", "", "", ""
+	rec.Plan, rec.Pending, rec.Placeholders = nil, nil, nil
+	rec.Request = nil
+	if rec.Feedback != nil {
+		fb := *rec.Feedback
+		fb.Correction = ""
+		rec.Feedback = &fb
+	}
+	actions := make([]model.ActionSummary, len(rec.Actions))
+	for i, a := range rec.Actions {
+		// 只保留动作类型与生成的资源，不保留备注与输出（可能含消息正文、查询结果）
+		actions[i] = model.ActionSummary{Type: a.Type, ID: a.ID, URL: a.URL}
+	}
+	rec.Actions = actions
+}
+
+// sealedContent 整体加密的用户内容
+type sealedContent struct {
+	Text         string                `json:"text,omitempty"`
+	Message      string                `json:"message,omitempty"`
+	Error        string                `json:"error,omitempty"`
+	Reply        string                `json:"reply,omitempty"`
+	Intent       string                `json:"intent,omitempty"`
+	Actions      []model.ActionSummary `json:"actions,omitempty"`
+	Plan         []model.ActionSpec    `json:"plan,omitempty"`
+	Pending      []model.ActionSpec    `json:"pending,omitempty"`
+	Placeholders map[string]string     `json:"placeholders,omitempty"`
+	Request      *model.ASRRequest     `json:"request,omitempty"`
+	// Correction 旧版本写入的反馈说明，现在的说明在 Feedback.Correction 中单独加密
+	Correction string `json:"correction,omitempty"`
+}
+
+// seal 把用户内容移入 rec.Sealed 并加密，记录中对应字段清空
+func (s *SealedTaskStore) seal(rec *model.TaskRecord) error {
+	c := sealedContent{
+		Text:         rec.Text,
+		Message:      rec.Message,
+		Error:        rec.Error,
+		Reply:        rec.Reply,
+		Intent:       rec.Intent,
+		Actions:      rec.Actions,
+		Plan:         rec.Plan,
+		Pending:      rec.Pending,
+		Placeholders: rec.Placeholders,
+		Request:      rec.Request,
+	}
+	if rec.Feedback != nil {
+		fb := *rec.Feedback
+		sealed, err := s.cipher.Seal(fb.Correction)
+		if err != nil {
+			return err
+		}
+		fb.Correction = sealed
+		rec.Feedback = &fb
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if rec.Sealed, err = s.cipher.Seal(string(b)); err != nil {
+		return err
+	}
+	rec.Text, rec.Message, rec.Error, rec.Reply, rec.Intent = "", "", "", "", ""
+	rec.Actions, rec.Plan, rec.Pending, rec.Placeholders, rec.Request = nil, nil, nil, nil, nil
+	return nil
+}
+
+// open 解密 rec.Sealed 与反馈说明并还原用户内容；内层存储返回的是副本，可直接修改
+func (s *SealedTaskStore) open(rec *model.TaskRecord) error {
+	var c sealedContent
+	if rec.Sealed != "" {
+		plain, err := s.cipher.Open(rec.Sealed)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(plain), &c); err != nil {
+			return fmt.Errorf("decode sealed content: %w", err)
+		}
+		rec.Text, rec.Message, rec.Error, rec.Reply, rec.Intent = c.Text, c.Message, c.Error, c.Reply, c.Intent
+		rec.Actions, rec.Plan, rec.Pending, rec.Placeholders, rec.Request = c.Actions, c.Plan, c.Pending, c.Placeholders, c.Request
+		rec.Sealed = ""
+	}
+	if rec.Feedback != nil {
+		fb := *rec.Feedback
+		if fb.Correction == "" {
+			fb.Correction = c.Correction
+		} else {
+			correction, err := s.cipher.Open(fb.Correction)
+			if err != nil {
+				return fmt.Errorf("open feedback: %w", err)
+			}
+			fb.Correction = correction
+		}
+		rec.Feedback = &fb
+	}
+	return nil
+}
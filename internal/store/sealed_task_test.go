@@ -0,0 +1,208 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"sayso-agent/internal/model"
+)
+
+func testCipher(t *testing.T, id string) *Cipher {
+	t.Helper()
+	c, err := NewCipher([]CipherKey{{ID: id, Key: bytes.Repeat([]byte{id[0]}, 32)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// secret 出现在记录各处的用户内容
+const secret = "工资调整方案"
+
+func sensitiveTask(status string) model.TaskRecord {
+	spec := model.ActionSpec{Type: model.ActionTypeSendMessage, Params: map[string]any{"content": map[string]any{"text": secret}}}
+	return model.TaskRecord{
+		ID:           "1",
+		TenantID:     "acme",
+		UserID:       "ou_alice",
+		Status:       status,
+		Text:         "把" + secret + "发给财务",
+		Message:      "已发送" + secret,
+		Error:        "send " + secret + " failed",
+		Reply:        secret,
+		Intent:       "发送" + secret,
+		Actions:      []model.ActionSummary{{Type: "feishu_im", Target: "财务", Note: secret, Outputs: map[string]string{"answer": secret}}},
+		Plan:         []model.ActionSpec{spec},
+		Pending:      []model.ActionSpec{spec},
+		Placeholders: map[string]string{"doc_title": secret},
+		Request: &model.ASRRequest{
+			Text:        secret,
+			Context:     map[string]string{"note": secret},
+			Attachments: []model.Attachment{{Name: "a.txt", Data: []byte(secret)}},
+		},
+		Feedback:  &model.TaskFeedback{Rating: "down", Correction: secret},
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestSealedTaskStoreSealsAllUserContent(t *testing.T) {
+	inner := NewMemoryTaskStore(10)
+	s := NewSealedTaskStore(inner, testCipher(t, "k1"), true)
+	rec := sensitiveTask(model.TaskStatusAwaitingConfirmation)
+	if err := s.Save(context.Background(), rec); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := inner.Get(context.Background(), rec.ID)
+	b, _ := json.Marshal(raw)
+	if bytes.Contains(b, []byte(secret)) {
+		t.Errorf("stored record contains plaintext: %s", b)
+	}
+	if raw.Status != rec.Status || raw.UserID != rec.UserID || raw.Feedback.Rating != "down" {
+		t.Errorf("filter fields should stay readable: %+v", raw)
+	}
+
+	got, err := s.Get(context.Background(), rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rec) {
+		t.Errorf("opened record differs:\n got %+v\nwant %+v", got, rec)
+	}
+}
+
+func TestSealedTaskStoreRedactsFinishedTasks(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string
+		keepPending bool
+	}{
+		{name: "succeeded", status: model.TaskStatusSucceeded},
+		{name: "awaiting confirmation keeps pending", status: model.TaskStatusAwaitingConfirmation, keepPending: true},
+		{name: "failed keeps pending", status: model.TaskStatusFailed, keepPending: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSealedTaskStore(NewMemoryTaskStore(10), nil, false)
+			if err := s.Save(context.Background(), sensitiveTask(tt.status)); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := s.Get(context.Background(), "1")
+			if got.Text != "" || got.Request != nil && got.Request.Text != "" {
+				t.Errorf("transcript retained: %q", got.Text)
+			}
+			b, _ := json.Marshal(got)
+			if leaked := bytes.Contains(b, []byte(secret)); leaked == !tt.keepPending {
+				t.Errorf("record contains user content = %v, want %v: %s", leaked, tt.keepPending, b)
+			}
+			if tt.keepPending && (len(got.Pending) == 0 || got.Placeholders == nil || got.Request == nil) {
+				t.Error("resumable task lost pending actions")
+			}
+			if !tt.keepPending && (len(got.Actions) != 1 || got.Actions[0].Type != "feishu_im") {
+				t.Errorf("action types should be kept: %+v", got.Actions)
+			}
+		})
+	}
+}
+
+func TestSealedTaskStoreListSkipsUndecryptable(t *testing.T) {
+	inner := NewMemoryTaskStore(10)
+	old := NewSealedTaskStore(inner, testCipher(t, "old"), true)
+	s := NewSealedTaskStore(inner, testCipher(t, "new"), true)
+	bad := sensitiveTask(model.TaskStatusSucceeded)
+	bad.ID, bad.CreatedAt = "bad", time.Now().Add(-time.Minute)
+	if err := old.Save(context.Background(), bad); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(context.Background(), sensitiveTask(model.TaskStatusSucceeded)); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := s.List(context.Background(), TaskFilter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "1" || list[0].Text == "" {
+		t.Errorf("List = %+v, want only the readable record", list)
+	}
+	if _, err := s.Get(context.Background(), "bad"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Get undecryptable err = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestSealedSlackInstallStore(t *testing.T) {
+	inner := NewMemorySlackInstallStore()
+	s := NewSealedSlackInstallStore(inner, testCipher(t, "k1"))
+	in := model.SlackInstallation{TeamID: "T1", BotToken: "xoxb-secret", InstalledAt: time.Now()}
+	if err := s.Save(context.Background(), in); err != nil {
+		t.Fatal(err)
+	}
+	if raw, _ := inner.Get(context.Background(), "T1"); raw.BotToken == in.BotToken {
+		t.Error("bot token stored in plaintext")
+	}
+	if got, err := s.Get(context.Background(), "T1"); err != nil || got.BotToken != in.BotToken {
+		t.Errorf("Get = %q, %v", got.BotToken, err)
+	}
+
+	// 其他密钥写入的记录在 List 中跳过
+	other := NewSealedSlackInstallStore(inner, testCipher(t, "k2"))
+	if err := other.Save(context.Background(), model.SlackInstallation{TeamID: "T2", BotToken: "xoxb-other", InstalledAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	list, err := s.List(context.Background())
+	if err != nil || len(list) != 1 || list[0].BotToken != in.BotToken {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+}
+
+func TestSealedTaskStoreSetFeedback(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemoryTaskStore(10)
+	s := NewSealedTaskStore(inner, testCipher(t, "k1"), true)
+	rec := sensitiveTask(model.TaskStatusAwaitingConfirmation)
+	rec.Feedback = nil
+	if err := s.Save(ctx, rec); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetFeedback(ctx, rec.ID, model.TaskFeedback{Rating: model.FeedbackDown, Correction: secret}); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := inner.Get(ctx, rec.ID)
+	if b, _ := json.Marshal(raw); bytes.Contains(b, []byte(secret)) {
+		t.Errorf("stored record contains plaintext: %s", b)
+	}
+	got, err := s.Get(ctx, rec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Feedback == nil || got.Feedback.Correction != secret {
+		t.Errorf("feedback = %+v, want the correction", got.Feedback)
+	}
+	if got.Status != rec.Status || !reflect.DeepEqual(got.Pending, rec.Pending) {
+		t.Errorf("SetFeedback changed the record: %+v", got)
+	}
+	if err := s.SetFeedback(ctx, "missing", model.TaskFeedback{Rating: model.FeedbackUp}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetFeedback missing err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSealedTaskStoreSetFeedbackRedactsFinished(t *testing.T) {
+	ctx := context.Background()
+	s := NewSealedTaskStore(NewMemoryTaskStore(10), nil, false)
+	if err := s.Save(ctx, model.TaskRecord{ID: "1", Status: model.TaskStatusSucceeded}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetFeedback(ctx, "1", model.TaskFeedback{Rating: model.FeedbackDown, Correction: secret}); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := s.Get(ctx, "1")
+	if got.Feedback == nil || got.Feedback.Rating != model.FeedbackDown || got.Feedback.Correction != "" {
+		t.Errorf("feedback = %+v, want rating without correction", got.Feedback)
+	}
+}
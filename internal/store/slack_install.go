@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"sayso-agent/internal/model"
+)
+
+// SlackInstallStore Slack 工作区安装记录，按 team_id 唯一
+type SlackInstallStore interface {
+	Save(ctx context.Context, in model.SlackInstallation) error
+	Get(ctx context.Context, teamID string) (model.SlackInstallation, error)
+	List(ctx context.Context) ([]model.SlackInstallation, error)
+}
+
+// MemorySlackInstallStore 进程内安装记录（重启丢失，适合单实例）
+type MemorySlackInstallStore struct {
+	mu       sync.RWMutex
+	installs map[string]model.SlackInstallation // team_id -> 安装记录
+}
+
+// NewMemorySlackInstallStore 创建进程内安装记录存储
+func NewMemorySlackInstallStore() *MemorySlackInstallStore {
+	return &MemorySlackInstallStore{installs: make(map[string]model.SlackInstallation)}
+}
+
+// Save 新增或覆盖（重新安装）工作区记录
+func (s *MemorySlackInstallStore) Save(_ context.Context, in model.SlackInstallation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.installs[in.TeamID] = in
+	return nil
+}
+
+// Get 按 team_id 查询
+func (s *MemorySlackInstallStore) Get(_ context.Context, teamID string) (model.SlackInstallation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	in, ok := s.installs[teamID]
+	if !ok {
+		return model.SlackInstallation{}, ErrNotFound
+	}
+	return in, nil
+}
+
+// List 按安装时间列出全部工作区
+func (s *MemorySlackInstallStore) List(_ context.Context) ([]model.SlackInstallation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]model.SlackInstallation, 0, len(s.installs))
+	for _, in := range s.installs {
+		list = append(list, in)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].InstalledAt.Before(list[j].InstalledAt) })
+	return list, nil
+}
+
+// SealedSlackInstallStore 包装 SlackInstallStore，安装记录中的 bot token 加密后写入
+type SealedSlackInstallStore struct {
+	inner  SlackInstallStore
+	cipher *Cipher
+}
+
+// NewSealedSlackInstallStore 创建加密的安装记录存储；cipher 为 nil 时不加密
+func NewSealedSlackInstallStore(inner SlackInstallStore, cipher *Cipher) *SealedSlackInstallStore {
+	return &SealedSlackInstallStore{inner: inner, cipher: cipher}
+}
+
+func (s *SealedSlackInstallStore) Save(ctx context.Context, in model.SlackInstallation) error {
+	if s.cipher != nil {
+		token, err := s.cipher.Seal(in.BotToken)
+		if err != nil {
+			return fmt.Errorf("seal slack installation %s: %w", in.TeamID, err)
+		}
+		in.BotToken = token
+	}
+	return s.inner.Save(ctx, in)
+}
+
+func (s *SealedSlackInstallStore) Get(ctx context.Context, teamID string) (model.SlackInstallation, error) {
+	in, err := s.inner.Get(ctx, teamID)
+	if err != nil || s.cipher == nil {
+		return in, err
+	}
+	if in.BotToken, err = s.cipher.Open(in.BotToken); err != nil {
+		return model.SlackInstallation{}, fmt.Errorf("open slack installation %s: %w", teamID, err)
+	}
+	return in, nil
+}
+
+// List 无法解密的记录跳过并记录日志，不影响其他工作区
+func (s *SealedSlackInstallStore) List(ctx context.Context) ([]model.SlackInstallation, error) {
+	list, err := s.inner.List(ctx)
+	if err != nil || s.cipher == nil {
+		return list, err
+	}
+	opened := list[:0]
+	for _, in := range list {
+		token, err := s.cipher.Open(in.BotToken)
+		if err != nil {
+			log.Printf("open slack installation %s: %v, skipped", in.TeamID, err)
+			continue
+		}
+		in.BotToken = token
+		opened = append(opened, in)
+	}
+	return opened, nil
+}
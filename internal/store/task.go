@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"sayso-agent/internal/model"
+)
+
+// TaskStore 任务记录存储
+type TaskStore interface {
+	Save(ctx context.Context, rec model.TaskRecord) error
+	Get(ctx context.Context, id string) (model.TaskRecord, error)
+	// List 按创建时间倒序返回满足过滤条件的记录
+	List(ctx context.Context, filter TaskFilter) ([]model.TaskRecord, error)
+	// Transition 任务当前状态为 from 时原子地改为 to；swapped 为 false 表示状态已被其他请求改变，
+	// 确认、重试等并发请求据此保证只有一个继续执行
+	Transition(ctx context.Context, id, from, to string) (swapped bool, err error)
+	// SetFeedback 只替换任务的反馈，不覆盖并发写入的状态、待执行动作与结果
+	SetFeedback(ctx context.Context, id string, fb model.TaskFeedback) error
+}
+
+// TaskFilter 任务查询条件，零值字段不参与过滤
+type TaskFilter struct {
+	TenantID string
+	UserID   string
+	Session  string
+	Source   string
+	Workflow string
+	Status   string
+	Feedback string    // 反馈评价 up | down；"any" 表示有任意反馈
+	Since    time.Time // 只返回该时间及之后创建的记录
+	Limit    int
+}
+
+func (f TaskFilter) match(rec model.TaskRecord) bool {
+	return (f.TenantID == "" || rec.TenantID == f.TenantID) &&
+		(f.UserID == "" || rec.UserID == f.UserID) &&
+		(f.Session == "" || rec.SessionID == f.Session) &&
+		(f.Source == "" || rec.Source == f.Source) &&
+		(f.Workflow == "" || rec.Workflow == f.Workflow) &&
+		(f.Status == "" || rec.Status == f.Status) &&
+		(f.Feedback == "" || rec.Feedback != nil && (f.Feedback == "any" || rec.Feedback.Rating == f.Feedback)) &&
+		(f.Since.IsZero() || !rec.CreatedAt.Before(f.Since))
+}
+
+// MemoryTaskStore 进程内任务存储，超过容量时淘汰最早的记录
+type MemoryTaskStore struct {
+	mu       sync.RWMutex
+	capacity int
+	order    []string // 按写入顺序的任务 ID
+	records  map[string]model.TaskRecord
+}
+
+// NewMemoryTaskStore 创建进程内任务存储；capacity<=0 时默认保留 1000 条
+func NewMemoryTaskStore(capacity int) *MemoryTaskStore {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &MemoryTaskStore{capacity: capacity, records: make(map[string]model.TaskRecord)}
+}
+
+// Save 新增或更新任务记录
+func (s *MemoryTaskStore) Save(_ context.Context, rec model.TaskRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[rec.ID]; !ok {
+		s.order = append(s.order, rec.ID)
+		if len(s.order) > s.capacity {
+			delete(s.records, s.order[0])
+			s.order = s.order[1:]
+		}
+	}
+	s.records[rec.ID] = rec
+	return nil
+}
+
+// Get 获取任务记录
+func (s *MemoryTaskStore) Get(_ context.Context, id string) (model.TaskRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, ok := s.records[id]
+	if !ok {
+		return model.TaskRecord{}, ErrNotFound
+	}
+	return rec, nil
+}
+
+// Transition 任务当前状态为 from 时改为 to
+func (s *MemoryTaskStore) Transition(_ context.Context, id, from, to string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[id]
+	if !ok {
+		return false, ErrNotFound
+	}
+	if rec.Status != from {
+		return false, nil
+	}
+	rec.Status = to
+	s.records[id] = rec
+	return true, nil
+}
+
+// SetFeedback 只替换任务的反馈
+func (s *MemoryTaskStore) SetFeedback(_ context.Context, id string, fb model.TaskFeedback) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.records[id]
+	if !ok {
+		return ErrNotFound
+	}
+	rec.Feedback = &fb
+	s.records[id] = rec
+	return nil
+}
+
+// List 按创建时间倒序返回满足过滤条件的记录
+func (s *MemoryTaskStore) List(_ context.Context, filter TaskFilter) ([]model.TaskRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var list []model.TaskRecord
+	for _, rec := range s.records {
+		if filter.match(rec) {
+			list = append(list, rec)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	if filter.Limit > 0 && len(list) > filter.Limit {
+		list = list[:filter.Limit]
+	}
+	return list, nil
+}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sayso-agent/internal/model"
+)
+
+// IdentityStore 跨平台身份映射，按租户隔离，姓名在租户内唯一
+type IdentityStore interface {
+	Save(ctx context.Context, u model.UserIdentity) error
+	// Lookup 按姓名、称呼、平台 ID、邮箱或手机号查找，姓名完全一致的优先
+	Lookup(ctx context.Context, tenantID, key string) (model.UserIdentity, error)
+	List(ctx context.Context, tenantID string) ([]model.UserIdentity, error)
+	Delete(ctx context.Context, tenantID, name string) error
+}
+
+// MemoryIdentityStore 进程内身份映射；按称呼、ID、邮箱、手机号建索引，同步整个通讯录后查找仍不必遍历
+type MemoryIdentityStore struct {
+	mu         sync.RWMutex
+	identities map[string]map[string]model.UserIdentity // tenant -> name -> identity
+	index      map[string]map[string][]string           // tenant -> 小写的查找键 -> 姓名（已排序）
+}
+
+// NewMemoryIdentityStore 创建进程内身份映射，identities 为初始映射（如来自配置文件）
+func NewMemoryIdentityStore(identities []model.UserIdentity) *MemoryIdentityStore {
+	s := &MemoryIdentityStore{identities: make(map[string]map[string]model.UserIdentity), index: make(map[string]map[string][]string)}
+	for _, u := range identities {
+		_ = s.Save(context.Background(), u)
+	}
+	return s
+}
+
+// Save 新增或按姓名覆盖
+func (s *MemoryIdentityStore) Save(_ context.Context, u model.UserIdentity) error {
+	if u.UpdatedAt.IsZero() {
+		u.UpdatedAt = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.identities[u.TenantID]
+	if m == nil {
+		m = make(map[string]model.UserIdentity)
+		s.identities[u.TenantID] = m
+		s.index[u.TenantID] = make(map[string][]string)
+	}
+	if old, ok := m[u.Name]; ok {
+		s.unindex(old)
+	}
+	m[u.Name] = u
+	idx := s.index[u.TenantID]
+	for _, key := range identityKeys(u) {
+		if i, found := slices.BinarySearch(idx[key], u.Name); !found {
+			idx[key] = slices.Insert(idx[key], i, u.Name)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryIdentityStore) unindex(u model.UserIdentity) {
+	idx := s.index[u.TenantID]
+	for _, key := range identityKeys(u) {
+		idx[key] = slices.DeleteFunc(idx[key], func(name string) bool { return name == u.Name })
+		if len(idx[key]) == 0 {
+			delete(idx, key)
+		}
+	}
+}
+
+// identityKeys 可用于查找的键（与 UserIdentity.Matches 一致），小写
+func identityKeys(u model.UserIdentity) []string {
+	var keys []string
+	for _, v := range append([]string{u.Name, u.FeishuOpenID, u.SlackUserID, u.DiscordUserID, u.Email, u.Phone}, u.Aliases...) {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" && !slices.Contains(keys, v) {
+			keys = append(keys, v)
+		}
+	}
+	return keys
+}
+
+func (s *MemoryIdentityStore) Lookup(_ context.Context, tenantID, key string) (model.UserIdentity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m := s.identities[tenantID]
+	if u, ok := m[key]; ok {
+		return u, nil
+	}
+	// 多人共用同一称呼时取姓名排序最前的，结果稳定
+	if names := s.index[tenantID][strings.ToLower(strings.TrimSpace(key))]; len(names) > 0 {
+		return m[names[0]], nil
+	}
+	return model.UserIdentity{}, ErrNotFound
+}
+
+// List 按姓名列出租户的映射
+func (s *MemoryIdentityStore) List(_ context.Context, tenantID string) ([]model.UserIdentity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]model.UserIdentity, 0, len(s.identities[tenantID]))
+	for _, u := range s.identities[tenantID] {
+		list = append(list, u)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func (s *MemoryIdentityStore) Delete(_ context.Context, tenantID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.identities[tenantID][name]
+	if !ok {
+		return ErrNotFound
+	}
+	s.unindex(u)
+	delete(s.identities[tenantID], name)
+	return nil
+}
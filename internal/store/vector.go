@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+)
+
+// VectorRecord 向量记录：ID 在集合内唯一，Text 为原文，Metadata 为检索后需要带回的信息（如文档标题、链接）
+type VectorRecord struct {
+	ID       string
+	Vector   []float32
+	Text     string
+	Metadata map[string]string
+}
+
+// VectorHit 检索结果，Score 为余弦相似度（-1 到 1，越大越相似）
+type VectorHit struct {
+	VectorRecord
+	Score float64
+}
+
+// VectorStore 向量存储，按集合（如 "kb"、"folders"）隔离
+type VectorStore interface {
+	// Replace 用 records 替换集合中的全部记录（重建索引）
+	Replace(ctx context.Context, collection string, records []VectorRecord) error
+	// Search 返回与 vector 最相似的至多 k 条记录，按相似度从高到低
+	Search(ctx context.Context, collection string, vector []float32, k int) ([]VectorHit, error)
+	// Count 集合中的记录数，集合不存在时为 0
+	Count(ctx context.Context, collection string) (int, error)
+}
+
+// MemoryVectorStore 进程内向量存储，暴力计算余弦相似度（重启丢失，适合文档量不大的单实例）
+type MemoryVectorStore struct {
+	mu          sync.RWMutex
+	collections map[string][]VectorRecord // 向量已归一化
+}
+
+// NewMemoryVectorStore 创建进程内向量存储
+func NewMemoryVectorStore() *MemoryVectorStore {
+	return &MemoryVectorStore{collections: make(map[string][]VectorRecord)}
+}
+
+// Replace 替换集合，检索中的请求仍使用旧数据直到替换完成
+func (s *MemoryVectorStore) Replace(_ context.Context, collection string, records []VectorRecord) error {
+	normalized := make([]VectorRecord, len(records))
+	for i, r := range records {
+		r.Vector = normalizeVector(r.Vector)
+		normalized[i] = r
+	}
+	s.mu.Lock()
+	s.collections[collection] = normalized
+	s.mu.Unlock()
+	return nil
+}
+
+// Search 逐条计算相似度后取前 k 条
+func (s *MemoryVectorStore) Search(_ context.Context, collection string, vector []float32, k int) ([]VectorHit, error) {
+	q := normalizeVector(vector)
+	s.mu.RLock()
+	records := s.collections[collection]
+	s.mu.RUnlock()
+	hits := make([]VectorHit, 0, len(records))
+	for _, r := range records {
+		hits = append(hits, VectorHit{VectorRecord: r, Score: dotProduct(q, r.Vector)})
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if k > 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits, nil
+}
+
+// Count 集合中的记录数
+func (s *MemoryVectorStore) Count(_ context.Context, collection string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.collections[collection]), nil
+}
+
+// normalizeVector 归一化为单位向量，之后余弦相似度即点积
+func normalizeVector(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	n := float32(math.Sqrt(sum))
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = x / n
+	}
+	return out
+}
+
+func dotProduct(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var s float64
+	for i := range a {
+		s += float64(a[i]) * float64(b[i])
+	}
+	return s
+}
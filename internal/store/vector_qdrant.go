@@ -0,0 +1,169 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// qdrantBatchSize 单次写入的点数
+const qdrantBatchSize = 256
+
+// QdrantVectorStore 基于 Qdrant REST 接口的向量存储，每个集合对应一个 Qdrant collection（名称加前缀）
+type QdrantVectorStore struct {
+	base   string
+	apiKey string
+	prefix string
+	client *http.Client
+}
+
+// NewQdrantVectorStore 创建 Qdrant 向量存储；prefix 为 collection 名称前缀（多个部署共用一个 Qdrant 时区分），为空时使用 sayso_
+func NewQdrantVectorStore(baseURL, apiKey, prefix string, transport http.RoundTripper) *QdrantVectorStore {
+	if prefix == "" {
+		prefix = "sayso_"
+	}
+	return &QdrantVectorStore{base: strings.TrimRight(baseURL, "/"), apiKey: apiKey, prefix: prefix, client: &http.Client{Transport: transport}}
+}
+
+// qdrantPayload 点的附加数据
+type qdrantPayload struct {
+	ID       string            `json:"id"`
+	Text     string            `json:"text"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Replace 删除并按向量维度重建 collection 后写入；重建期间的检索可能返回空结果
+func (s *QdrantVectorStore) Replace(ctx context.Context, collection string, records []VectorRecord) error {
+	name := s.prefix + collection
+	if err := s.do(ctx, http.MethodDelete, "/collections/"+url.PathEscape(name), nil, nil); err != nil && !isQdrantNotFound(err) {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	create := map[string]any{"vectors": map[string]any{"size": len(records[0].Vector), "distance": "Cosine"}}
+	if err := s.do(ctx, http.MethodPut, "/collections/"+url.PathEscape(name), create, nil); err != nil {
+		return err
+	}
+	for i := 0; i < len(records); i += qdrantBatchSize {
+		batch := records[i:min(i+qdrantBatchSize, len(records))]
+		points := make([]map[string]any, len(batch))
+		for j, r := range batch {
+			points[j] = map[string]any{
+				"id":      qdrantPointID(r.ID),
+				"vector":  r.Vector,
+				"payload": qdrantPayload{ID: r.ID, Text: r.Text, Metadata: r.Metadata},
+			}
+		}
+		path := "/collections/" + url.PathEscape(name) + "/points?wait=true"
+		if err := s.do(ctx, http.MethodPut, path, map[string]any{"points": points}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search 由 Qdrant 检索最相似的 k 个点
+func (s *QdrantVectorStore) Search(ctx context.Context, collection string, vector []float32, k int) ([]VectorHit, error) {
+	var result []struct {
+		Score   float64       `json:"score"`
+		Payload qdrantPayload `json:"payload"`
+	}
+	path := "/collections/" + url.PathEscape(s.prefix+collection) + "/points/search"
+	err := s.do(ctx, http.MethodPost, path, map[string]any{"vector": vector, "limit": k, "with_payload": true}, &result)
+	if isQdrantNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]VectorHit, len(result))
+	for i, r := range result {
+		hits[i] = VectorHit{VectorRecord: VectorRecord{ID: r.Payload.ID, Text: r.Payload.Text, Metadata: r.Payload.Metadata}, Score: r.Score}
+	}
+	return hits, nil
+}
+
+// Count 集合中的点数
+func (s *QdrantVectorStore) Count(ctx context.Context, collection string) (int, error) {
+	var result struct {
+		Count int `json:"count"`
+	}
+	path := "/collections/" + url.PathEscape(s.prefix+collection) + "/points/count"
+	err := s.do(ctx, http.MethodPost, path, map[string]any{"exact": true}, &result)
+	if isQdrantNotFound(err) {
+		return 0, nil
+	}
+	return result.Count, err
+}
+
+// qdrantError Qdrant 返回的错误
+type qdrantError struct {
+	status int
+	body   string
+}
+
+func (e *qdrantError) Error() string {
+	return fmt.Sprintf("qdrant: http status %d, body: %s", e.status, e.body)
+}
+
+func isQdrantNotFound(err error) bool {
+	qe, ok := err.(*qdrantError)
+	return ok && qe.status == http.StatusNotFound
+}
+
+// do 发送请求，out 非 nil 时解析响应的 result 字段
+func (s *QdrantVectorStore) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.base+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("api-key", s.apiKey)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("qdrant: %w", err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("qdrant: read body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &qdrantError{status: resp.StatusCode, body: string(b)}
+	}
+	if out == nil {
+		return nil
+	}
+	var wrapper struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(b, &wrapper); err != nil {
+		return fmt.Errorf("qdrant: parse response: %w", err)
+	}
+	return json.Unmarshal(wrapper.Result, out)
+}
+
+// qdrantPointID Qdrant 的点 ID 须为整数或 UUID，由记录 ID 的 SHA-1 生成确定的 UUID
+func qdrantPointID(id string) string {
+	h := sha1.Sum([]byte(id))
+	h[6] = (h[6] & 0x0f) | 0x50 // 版本 5
+	h[8] = (h[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SQL 向量存储方言
+const (
+	DialectPgvector  = "pgvector"
+	DialectSQLiteVec = "sqlite-vec"
+)
+
+// SQLVectorStore 基于 database/sql 的向量存储：PostgreSQL + pgvector 扩展，或 SQLite + sqlite-vec 扩展。
+// 数据库驱动需由调用方链接（如在 main 包中 import _ "github.com/jackc/pgx/v5/stdlib"），本包只依赖标准库
+type SQLVectorStore struct {
+	db      *sql.DB
+	dialect string
+	table   string
+}
+
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// NewSQLVectorStore 创建 SQL 向量存储并建表（已存在时跳过）；table 为空时使用 sayso_vectors
+func NewSQLVectorStore(ctx context.Context, db *sql.DB, dialect, table string) (*SQLVectorStore, error) {
+	if table == "" {
+		table = "sayso_vectors"
+	}
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("vector store: invalid table name %q", table)
+	}
+	s := &SQLVectorStore{db: db, dialect: dialect, table: table}
+	var ddl []string
+	switch dialect {
+	case DialectPgvector:
+		ddl = []string{
+			"CREATE EXTENSION IF NOT EXISTS vector",
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	collection TEXT NOT NULL,
+	id TEXT NOT NULL,
+	text TEXT NOT NULL,
+	metadata TEXT NOT NULL,
+	embedding vector NOT NULL,
+	PRIMARY KEY (collection, id))`, table),
+		}
+	case DialectSQLiteVec:
+		ddl = []string{fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	collection TEXT NOT NULL,
+	id TEXT NOT NULL,
+	text TEXT NOT NULL,
+	metadata TEXT NOT NULL,
+	embedding BLOB NOT NULL,
+	PRIMARY KEY (collection, id))`, table)}
+	default:
+		return nil, fmt.Errorf("vector store: unknown sql dialect %q", dialect)
+	}
+	for _, stmt := range ddl {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("vector store: migrate: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// placeholder 第 n 个参数的占位符：PostgreSQL 为 $n，SQLite 为 ?
+func (s *SQLVectorStore) placeholder(n int) string {
+	if s.dialect == DialectPgvector {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// vectorParam 向量参数表达式：两种扩展都接受 "[0.1,0.2]" 形式的文本
+func (s *SQLVectorStore) vectorParam(n int) string {
+	if s.dialect == DialectPgvector {
+		return s.placeholder(n) + "::vector"
+	}
+	return "vec_f32(" + s.placeholder(n) + ")"
+}
+
+// Replace 在一个事务中删除集合的旧记录并写入新记录
+func (s *SQLVectorStore) Replace(ctx context.Context, collection string, records []VectorRecord) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// 提交后回滚不生效
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE collection = %s", s.table, s.placeholder(1)), collection); err != nil {
+		return fmt.Errorf("vector store: delete: %w", err)
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (collection, id, text, metadata, embedding) VALUES (%s, %s, %s, %s, %s)",
+		s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4), s.vectorParam(5))
+	stmt, err := tx.PrepareContext(ctx, insert)
+	if err != nil {
+		return fmt.Errorf("vector store: prepare: %w", err)
+	}
+	defer stmt.Close()
+	for _, r := range records {
+		meta, _ := json.Marshal(r.Metadata)
+		if _, err := stmt.ExecContext(ctx, collection, r.ID, r.Text, string(meta), vectorLiteral(r.Vector)); err != nil {
+			return fmt.Errorf("vector store: insert %s: %w", r.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Search 由数据库按余弦距离排序取前 k 条
+func (s *SQLVectorStore) Search(ctx context.Context, collection string, vector []float32, k int) ([]VectorHit, error) {
+	var query string
+	switch s.dialect {
+	case DialectPgvector:
+		query = fmt.Sprintf("SELECT id, text, metadata, 1 - (embedding <=> %[2]s) AS score FROM %[1]s WHERE collection = $1 ORDER BY embedding <=> %[2]s LIMIT $3",
+			s.table, s.vectorParam(2))
+	default:
+		query = fmt.Sprintf("SELECT id, text, metadata, 1 - vec_distance_cosine(embedding, %s) AS score FROM %s WHERE collection = ? ORDER BY score DESC LIMIT ?",
+			s.vectorParam(1), s.table)
+	}
+	args := []any{collection, vectorLiteral(vector), k}
+	if s.dialect == DialectSQLiteVec {
+		args = []any{vectorLiteral(vector), collection, k}
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("vector store: search: %w", err)
+	}
+	defer rows.Close()
+	var hits []VectorHit
+	for rows.Next() {
+		var h VectorHit
+		var meta string
+		if err := rows.Scan(&h.ID, &h.Text, &meta, &h.Score); err != nil {
+			return nil, fmt.Errorf("vector store: scan: %w", err)
+		}
+		_ = json.Unmarshal([]byte(meta), &h.Metadata)
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+// Count 集合中的记录数
+func (s *SQLVectorStore) Count(ctx context.Context, collection string) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE collection = %s", s.table, s.placeholder(1)), collection).Scan(&n)
+	return n, err
+}
+
+// vectorLiteral 向量的文本形式，如 "[0.1,0.2]"
+func vectorLiteral(v []float32) string {
+	parts := make([]string, len(v))
+	for i, x := range v {
+		parts[i] = strconv.FormatFloat(float64(x), 'g', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
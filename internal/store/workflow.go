@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"sayso-agent/internal/model"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = errors.New("record not found")
+
+// WorkflowStore 工作流存储，按租户隔离
+type WorkflowStore interface {
+	Save(ctx context.Context, wf model.Workflow) (model.Workflow, error)
+	Get(ctx context.Context, tenantID, name string) (model.Workflow, error)
+	List(ctx context.Context, tenantID string) ([]model.Workflow, error)
+	Delete(ctx context.Context, tenantID, name string) error
+	// ListScheduled 列出所有租户下配置了定时运行的工作流
+	ListScheduled(ctx context.Context) ([]model.Workflow, error)
+}
+
+// MemoryWorkflowStore 进程内工作流存储（重启丢失，适合单实例）
+type MemoryWorkflowStore struct {
+	mu        sync.RWMutex
+	workflows map[string]map[string]model.Workflow // tenant -> name -> workflow
+}
+
+// NewMemoryWorkflowStore 创建进程内工作流存储
+func NewMemoryWorkflowStore() *MemoryWorkflowStore {
+	return &MemoryWorkflowStore{workflows: make(map[string]map[string]model.Workflow)}
+}
+
+// Save 新增或覆盖同名工作流
+func (s *MemoryWorkflowStore) Save(_ context.Context, wf model.Workflow) (model.Workflow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tenant := s.workflows[wf.TenantID]
+	if tenant == nil {
+		tenant = make(map[string]model.Workflow)
+		s.workflows[wf.TenantID] = tenant
+	}
+	now := time.Now()
+	if old, ok := tenant[wf.Name]; ok {
+		wf.CreatedAt = old.CreatedAt
+	} else {
+		wf.CreatedAt = now
+	}
+	wf.UpdatedAt = now
+	tenant[wf.Name] = wf
+	return wf, nil
+}
+
+// Get 获取工作流
+func (s *MemoryWorkflowStore) Get(_ context.Context, tenantID, name string) (model.Workflow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	wf, ok := s.workflows[tenantID][name]
+	if !ok {
+		return model.Workflow{}, ErrNotFound
+	}
+	return wf, nil
+}
+
+// List 列出租户下所有工作流（按名称排序）
+func (s *MemoryWorkflowStore) List(_ context.Context, tenantID string) ([]model.Workflow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var list []model.Workflow
+	for _, wf := range s.workflows[tenantID] {
+		list = append(list, wf)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Delete 删除工作流
+func (s *MemoryWorkflowStore) Delete(_ context.Context, tenantID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.workflows[tenantID][name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.workflows[tenantID], name)
+	return nil
+}
+
+// ListScheduled 列出所有租户下配置了定时运行的工作流
+func (s *MemoryWorkflowStore) ListScheduled(_ context.Context) ([]model.Workflow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var list []model.Workflow
+	for _, tenant := range s.workflows {
+		for _, wf := range tenant {
+			if wf.Schedule != nil && wf.Schedule.Cron != "" {
+				list = append(list, wf)
+			}
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,113 @@
+// Package trace 请求级耗时记录：访问日志中间件为每个请求创建 Trace 放入 ctx，
+// 规划、参数提取与每个动作记录各自的耗时，慢请求与失败请求的访问日志据此输出阶段明细
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Span 一个阶段的耗时
+type Span struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"-"`
+	Ms       int64         `json:"ms"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Trace 一个请求的 ID 与各阶段耗时；并发记录安全，nil 时所有方法为空操作
+type Trace struct {
+	requestID string
+
+	mu     sync.Mutex
+	taskID string
+	spans  []Span
+}
+
+type traceKey struct{}
+
+// With 为请求创建 Trace 并放入 ctx；requestID 为空时随机生成
+func With(ctx context.Context, requestID string) (context.Context, *Trace) {
+	if requestID == "" {
+		requestID = newID()
+	}
+	t := &Trace{requestID: requestID}
+	return context.WithValue(ctx, traceKey{}, t), t
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestID 请求 ID（客户端的 X-Request-ID 或随机生成），用于关联访问日志与 panic 报告
+func (t *Trace) RequestID() string {
+	if t == nil {
+		return ""
+	}
+	return t.requestID
+}
+
+// SetTask 记录请求对应的任务记录 ID
+func (t *Trace) SetTask(id string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.taskID = id
+	t.mu.Unlock()
+}
+
+// TaskID 请求对应的任务记录 ID，尚未创建任务时为空
+func (t *Trace) TaskID() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.taskID
+}
+
+// From 取出 ctx 中的 Trace，没有时返回 nil
+func From(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}
+
+// Start 开始记录 ctx 中 Trace 的一个阶段，返回的函数在阶段结束时调用（err 为阶段结果）
+func Start(ctx context.Context, name string) func(err error) {
+	t := From(ctx)
+	if t == nil {
+		return func(error) {}
+	}
+	start := time.Now()
+	return func(err error) { t.Add(name, time.Since(start), err) }
+}
+
+// Add 记录一个阶段
+func (t *Trace) Add(name string, d time.Duration, err error) {
+	if t == nil {
+		return
+	}
+	span := Span{Name: name, Duration: d, Ms: d.Milliseconds()}
+	if err != nil {
+		span.Error = err.Error()
+	}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+}
+
+// Spans 已记录的阶段，按结束先后排列
+func (t *Trace) Spans() []Span {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Span(nil), t.spans...)
+}